@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"internship-project/internal/doctor"
+	"internship-project/pkg/database"
+)
+
+// runDoctor implements the "doctor" command: it checks the database and its schema version,
+// Redis, the Kafka topics, the search index mappings and the HackerNews API, and prints a
+// pass/fail table. It returns the exit status, 1 when a check failed, so deploy pipelines can
+// run it before a rollout.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 5*time.Second, "time allowed to each check")
+	asJSON := flags.Bool("json", false, "print the results as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	connectErr := database.Connect(database.GetDefaultConfig())
+	defer database.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	results := doctor.Run(ctx, doctor.DefaultChecks(connectErr), *timeout)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	} else if err := doctor.Print(os.Stdout, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !doctor.Passed(results) {
+		return 1
+	}
+	return 0
+}
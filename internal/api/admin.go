@@ -0,0 +1,119 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// adminKeyHeader carries the admin key on admin-only endpoints
+const adminKeyHeader = "X-Admin-Key"
+
+// requireAdmin rejects requests that do not carry ADMIN_API_KEY in the X-Admin-Key header.
+// Admin endpoints are disabled while ADMIN_API_KEY is unset.
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminKey := config.GetEnv("ADMIN_API_KEY", "")
+		if adminKey == "" {
+			writeError(w, http.StatusForbidden, "admin endpoints are disabled")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(adminKeyHeader)), []byte(adminKey)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid admin key")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleExplainQuery runs EXPLAIN (ANALYZE, BUFFERS) on a named repository query.
+// The query is built from the same parameters as the list endpoints, so a slow
+// request can be replayed here as-is, e.g. /admin/explain/stories.list?q=rust&min_score=100.
+func (s *Server) handleExplainQuery(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseItemFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	name := r.PathValue("query")
+	repo := postgres.NewDiagnosticsRepository(config.GetEnvDuration("EXPLAIN_STATEMENT_TIMEOUT", 30*time.Second))
+	plan, err := repo.ExplainQuery(r.Context(), name, filter)
+	if err != nil {
+		if errors.Is(err, postgres.ErrUnknownQuery) {
+			writeErrorDetails(w, http.StatusNotFound, "unknown query "+name,
+				map[string][]string{"available": repo.ExplainableQueries()})
+			return
+		}
+		tracing.Logf(r.Context(), "Error explaining query %s: %v", name, err)
+		writeError(w, http.StatusInternalServerError, "failed to explain query")
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// handleDataQualityReport returns the report of the last data-quality job run
+func (s *Server) handleDataQualityReport(w http.ResponseWriter, r *http.Request) {
+	report, err := postgres.NewDataQualityRepository().GetLatestReport(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "data-quality report")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// searchAnalytics is the response of the search analytics endpoint
+type searchAnalytics struct {
+	Since       int64               `json:"since"`
+	Top         []*models.QueryStat `json:"top"`
+	ZeroResults []*models.QueryStat `json:"zero_results"`
+}
+
+// handleSearchAnalytics returns the most frequent search queries and the queries that most often
+// found nothing, since the since parameter (unix seconds, default 7 days ago), optionally for one tenant
+func (s *Server) handleSearchAnalytics(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, err := int64Param(q.Get("since"), "since")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if since == 0 {
+		since = time.Now().Add(-7 * 24 * time.Hour).Unix()
+	}
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	repo := postgres.NewSearchQueryRepository()
+	result := searchAnalytics{Since: since}
+	if result.Top, err = repo.GetTopQueries(r.Context(), q.Get("tenant"), since, limit); err != nil {
+		writeStoreError(w, r, err, "search analytics")
+		return
+	}
+	if result.ZeroResults, err = repo.GetZeroResultQueries(r.Context(), q.Get("tenant"), since, limit); err != nil {
+		writeStoreError(w, r, err, "search analytics")
+		return
+	}
+	if result.Top == nil {
+		result.Top = []*models.QueryStat{}
+	}
+	if result.ZeroResults == nil {
+		result.ZeroResults = []*models.QueryStat{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"internship-project/internal/search"
+	"internship-project/internal/tracing"
+)
+
+// handleGetSearchAnalyzer returns the synonyms and stopwords of the search analyzer
+func (s *Server) handleGetSearchAnalyzer(w http.ResponseWriter, r *http.Request) {
+	analyzer, err := search.LoadAnalyzerConfig()
+	if err != nil {
+		tracing.Logf(r.Context(), "Error loading search analyzer config: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to load search analyzer config")
+		return
+	}
+	writeJSON(w, http.StatusOK, analyzer)
+}
+
+// handleUpdateSearchAnalyzer replaces the synonyms and stopwords of the search analyzer, saves them
+// to their config files and applies them to every index, which are briefly closed to do so
+func (s *Server) handleUpdateSearchAnalyzer(w http.ResponseWriter, r *http.Request) {
+	if s.search == nil {
+		writeError(w, http.StatusServiceUnavailable, "search is not configured")
+		return
+	}
+
+	analyzer := &search.AnalyzerConfig{}
+	if err := json.NewDecoder(r.Body).Decode(analyzer); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if analyzer.Synonyms == nil {
+		analyzer.Synonyms = []string{}
+	}
+	if analyzer.Stopwords == nil {
+		analyzer.Stopwords = []string{}
+	}
+	if err := analyzer.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := search.SaveAnalyzerConfig(analyzer); err != nil {
+		tracing.Logf(r.Context(), "Error saving search analyzer config: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to save search analyzer config")
+		return
+	}
+	if err := s.search.ApplyAnalyzer(r.Context(), analyzer); err != nil {
+		tracing.Logf(r.Context(), "Error applying search analyzer: %v", err)
+		writeError(w, http.StatusBadGateway, "saved, but failed to apply the analyzer to the search indexes")
+		return
+	}
+	writeJSON(w, http.StatusOK, analyzer)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// batchGetRequest lists the items of a bulk fetch, each with an optional type hint
+type batchGetRequest struct {
+	Items []batchGetRef `json:"items"`
+}
+
+// batchGetRef is one requested item; Type, when set, is tried before the item's kind is looked up
+type batchGetRef struct {
+	ID   int    `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+// batchGetItem is a found item with its kind
+type batchGetItem struct {
+	ID   int         `json:"id"`
+	Type string      `json:"type"`
+	Item interface{} `json:"item"`
+}
+
+// batchGetResponse holds the found items in request order and the IDs of the others
+type batchGetResponse struct {
+	Items   []batchGetItem `json:"items"`
+	Missing []int          `json:"missing"`
+}
+
+// batchGetter loads the items of one kind among the IDs of the filter, by ID
+type batchGetter func(ctx context.Context, filter repository.ItemFilter) (map[int]interface{}, error)
+
+// batchGetters returns the loaders of the item kinds served by the bulk fetch
+func batchGetters() map[string]batchGetter {
+	return map[string]batchGetter{
+		"story":   itemsByID(postgres.NewStoryRepository().GetByFilter, func(s *models.Story) int { return s.ID }),
+		"ask":     itemsByID(postgres.NewAskRepository().GetByFilter, func(a *models.Ask) int { return a.ID }),
+		"job":     itemsByID(postgres.NewJobRepository().GetByFilter, func(j *models.Job) int { return j.ID }),
+		"comment": itemsByID(postgres.NewCommentRepository().GetByFilter, func(c *models.Comment) int { return c.ID }),
+		"poll":    itemsByID(postgres.NewPollRepository().GetByFilter, func(p *models.Poll) int { return p.ID }),
+		"pollopt": pollOptionsByID,
+	}
+}
+
+// itemsByID adapts a filtered list query to a batchGetter
+func itemsByID[T any](list func(context.Context, repository.ItemFilter) ([]*T, error), id func(*T) int) batchGetter {
+	return func(ctx context.Context, filter repository.ItemFilter) (map[int]interface{}, error) {
+		items, err := list(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[int]interface{}, len(items))
+		for _, item := range items {
+			found[id(item)] = item
+		}
+		return found, nil
+	}
+}
+
+// pollOptionsByID loads poll options one by one: they have no filtered list query and are rarely
+// requested on their own
+func pollOptionsByID(ctx context.Context, filter repository.ItemFilter) (map[int]interface{}, error) {
+	repo := postgres.NewPollOptionRepository()
+	found := make(map[int]interface{}, len(filter.IDs))
+	for _, id := range filter.IDs {
+		option, err := repo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+		found[id] = option
+	}
+	return found, nil
+}
+
+// handleBatchGetItems returns many items by ID in one request, at most BATCH_GET_MAX_ITEMS. Items
+// are loaded kind by kind: first under their type hints, then, for the IDs without a hint or not
+// found under it, under their stored kind. IDs found under no kind are reported missing.
+func (s *Server) handleBatchGetItems(w http.ResponseWriter, r *http.Request) {
+	var req batchGetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	maxItems := config.GetEnvInt("BATCH_GET_MAX_ITEMS", 1000)
+	if len(req.Items) == 0 {
+		writeError(w, http.StatusBadRequest, "items must list at least one item")
+		return
+	}
+	if len(req.Items) > maxItems {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d items can be fetched at once", maxItems))
+		return
+	}
+
+	getters := batchGetters()
+	hinted := make(map[string][]int)
+	for _, ref := range req.Items {
+		if ref.ID <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid id: %d", ref.ID))
+			return
+		}
+		if ref.Type == "" {
+			continue
+		}
+		if _, ok := getters[ref.Type]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid type %q for item %d", ref.Type, ref.ID))
+			return
+		}
+		hinted[ref.Type] = append(hinted[ref.Type], ref.ID)
+	}
+
+	ctx := r.Context()
+	tenant := tenantFromContext(ctx)
+	found := make(map[int]batchGetItem, len(req.Items))
+	load := func(byKind map[string][]int) error {
+		for kind, ids := range byKind {
+			items, err := getters[kind](ctx, repository.ItemFilter{Tenant: tenant, IDs: ids})
+			if err != nil {
+				return err
+			}
+			for id, item := range items {
+				found[id] = batchGetItem{ID: id, Type: kind, Item: item}
+			}
+		}
+		return nil
+	}
+	if err := load(hinted); err != nil {
+		writeStoreError(w, r, err, "items")
+		return
+	}
+
+	var unresolved []int
+	for _, ref := range req.Items {
+		if _, ok := found[ref.ID]; !ok && bloom.KnownItems().MayContain(ref.ID) {
+			unresolved = append(unresolved, ref.ID)
+		}
+	}
+	if len(unresolved) > 0 {
+		kinds, err := postgres.NewItemRepository().GetKinds(ctx, unresolved)
+		if err != nil {
+			writeStoreError(w, r, err, "items")
+			return
+		}
+		byKind := make(map[string][]int)
+		for id, kind := range kinds {
+			byKind[kind] = append(byKind[kind], id)
+		}
+		if err := load(byKind); err != nil {
+			writeStoreError(w, r, err, "items")
+			return
+		}
+	}
+
+	resp := batchGetResponse{Items: []batchGetItem{}, Missing: []int{}}
+	seen := make(map[int]bool, len(req.Items))
+	for _, ref := range req.Items {
+		if seen[ref.ID] {
+			continue
+		}
+		seen[ref.ID] = true
+		if item, ok := found[ref.ID]; ok {
+			resp.Items = append(resp.Items, item)
+		} else {
+			resp.Missing = append(resp.Missing, ref.ID)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
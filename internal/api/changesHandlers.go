@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// Page sizes of the change log
+const (
+	defaultChangesPageSize = 100
+	maxChangesPageSize     = 1000
+)
+
+// changesResponse is a page of the change log; NextSince is the since of the next poll
+type changesResponse struct {
+	Changes   []*models.SequencedChange `json:"changes"`
+	NextSince int64                     `json:"next_since"`
+	HasMore   bool                      `json:"has_more"`
+}
+
+// handleChanges returns the changes of the tenant's items numbered after ?since=, oldest first,
+// for pollers that cannot consume Kafka; without since it starts at the oldest change kept.
+// Pending changes are numbered first, up to CHANGES_SEQUENCE_BATCH per poll, so a poll sees the
+// writes committed before it. A since older than the pruned part of the log is answered with
+// 410 expired: the poller missed changes and has to resync.
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since int64
+	if v := q.Get("since"); v != "" {
+		var err error
+		if since, err = strconv.ParseInt(v, 10, 64); err != nil || since < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid since: %q", v))
+			return
+		}
+	}
+	limit := defaultChangesPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxChangesPageSize)
+	}
+
+	ctx := r.Context()
+	repo := postgres.NewChangeRepository()
+	if _, err := repo.SequenceChanges(ctx, config.GetEnvInt("CHANGES_SEQUENCE_BATCH", 5000)); err != nil {
+		tracing.Logf(ctx, "Error numbering changes: %v", err)
+	}
+
+	first, last, err := repo.GetChangeLogBounds(ctx)
+	if err != nil {
+		writeStoreError(w, r, err, "changes")
+		return
+	}
+	if !q.Has("since") {
+		since = first - 1
+	} else if since < first-1 {
+		writeErrorDetails(w, http.StatusGone, "changes after since were pruned",
+			map[string]int64{"oldest_seq": first, "latest_seq": last})
+		return
+	}
+
+	changes, err := repo.GetChangesSince(ctx, tenantFromContext(ctx), since, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "changes")
+		return
+	}
+	resp := changesResponse{Changes: changes, NextSince: since, HasMore: len(changes) == limit}
+	if len(changes) > 0 {
+		resp.NextSince = changes[len(changes)-1].Seq
+	} else {
+		// Nothing for the tenant up to the last number handed out: later polls start there
+		resp.NextSince = max(since, last)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
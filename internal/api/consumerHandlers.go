@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/indexing"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/transport"
+)
+
+// consumerGroupStatus is whether a consumer group keeps up with its topic
+type consumerGroupStatus struct {
+	Group      string                   `json:"group"`
+	Topic      string                   `json:"topic"`
+	Lag        int64                    `json:"lag"`
+	KeepingUp  bool                     `json:"keeping_up"`
+	Error      string                   `json:"error,omitempty"`
+	Partitions []transport.PartitionLag `json:"partitions,omitempty"`
+}
+
+// consumersStatus is the response of the consumer status endpoint
+type consumersStatus struct {
+	KeepingUp    bool                               `json:"keeping_up"`
+	LagThreshold int64                              `json:"lag_threshold"`
+	Groups       []consumerGroupStatus              `json:"groups"`
+	Process      []transport.ConsumerPartitionStats `json:"process"`
+}
+
+// handleConsumersStatus reports the lag of the INDEXER_CONSUMER_GROUPS ("group:topic" entries)
+// and whether each stays within CONSUMER_LAG_THRESHOLD messages, along with the statistics of
+// the consumers of this process. Broker lag is only available for Kafka; on other transports a
+// group falls back to the lag its consumers in this process reported.
+func (s *Server) handleConsumersStatus(w http.ResponseWriter, r *http.Request) {
+	threshold := int64(config.GetEnvInt("CONSUMER_LAG_THRESHOLD", 1000))
+	process := transport.ConsumerStats()
+	groups, err := indexerGroupStatus(r.Context(), process)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := consumersStatus{KeepingUp: true, LagThreshold: threshold, Groups: groups, Process: process}
+	for i := range groups {
+		groups[i].KeepingUp = groups[i].Error == "" && groups[i].Lag <= threshold
+		response.KeepingUp = response.KeepingUp && groups[i].KeepingUp
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// indexerGroupStatus loads the lag of every group of INDEXER_CONSUMER_GROUPS concurrently, within
+// CONSUMER_STATUS_TIMEOUT
+func indexerGroupStatus(ctx context.Context, process []transport.ConsumerPartitionStats) ([]consumerGroupStatus, error) {
+	configured, err := indexing.Groups()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]consumerGroupStatus, len(configured))
+	for i, group := range configured {
+		groups[i] = consumerGroupStatus{Group: group.Name, Topic: group.Topic}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.GetEnvDuration("CONSUMER_STATUS_TIMEOUT", 10*time.Second))
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := range groups {
+		wg.Add(1)
+		go func(status *consumerGroupStatus) {
+			defer wg.Done()
+			groupLag(ctx, status, process)
+		}(&groups[i])
+	}
+	wg.Wait()
+	return groups, nil
+}
+
+// groupLag fills the lag of a group from the broker, or from the process statistics when the
+// transport does not report it
+func groupLag(ctx context.Context, status *consumerGroupStatus, process []transport.ConsumerPartitionStats) {
+	if transport.Transport() == "kafka" {
+		partitions, err := transport.KafkaGroupLag(ctx, status.Group, []string{status.Topic})
+		if err != nil {
+			status.Error = err.Error()
+			return
+		}
+		status.Partitions = partitions
+		for _, p := range partitions {
+			status.Lag += p.Lag
+		}
+		return
+	}
+
+	known := false
+	for _, stats := range process {
+		if stats.Group == status.Group && stats.Topic == status.Topic && stats.Lag >= 0 {
+			status.Lag += stats.Lag
+			known = true
+		}
+	}
+	if !known {
+		status.Error = "no lag reported for this group"
+	}
+}
+
+// handleMetrics writes the consumer metrics of this process in the Prometheus text format,
+// followed by the broker lag of the Kafka indexer groups and the table statistics
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := transport.WritePrometheusMetrics(&buf); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if transport.Transport() == "kafka" {
+		groups, err := indexerGroupStatus(r.Context(), nil)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		lags := make(map[string][]transport.PartitionLag, len(groups))
+		for _, group := range groups {
+			if group.Error != "" {
+				log.Printf("Error loading the lag of consumer group %s: %s", group.Group, group.Error)
+				continue
+			}
+			lags[group.Group] = group.Partitions
+		}
+		transport.WritePrometheusGroupLag(&buf, lags)
+	}
+	if stats, err := postgres.NewMaintenanceRepository().GetTableStats(r.Context(),
+		config.GetEnvList("MAINTENANCE_TABLES", postgres.DefaultMaintainedTables)); err != nil {
+		log.Printf("Error loading table statistics: %v", err)
+	} else {
+		writePrometheusTableStats(&buf, stats)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("Error writing metrics: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"internship-project/internal/hnschema"
+	"internship-project/internal/repository/postgres"
+)
+
+// handleListDeadLetters returns the item payloads most recently rejected before persistence,
+// optionally of one item type
+func (s *Server) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind := q.Get("kind")
+	if kind != "" && !slices.Contains(hnschema.Types(), kind) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid kind: %q", kind))
+		return
+	}
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	letters, err := postgres.NewDeadLetterRepository().List(r.Context(), kind, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "dead letters")
+		return
+	}
+	writeJSON(w, http.StatusOK, letters)
+}
+
+// handleGetItemPayload returns the last payload the API served for an item, as kept while
+// ITEM_RAW_PAYLOADS_ENABLED is set
+func (s *Server) handleGetItemPayload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	payload, err := postgres.NewItemPayloadRepository().GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, "item payload")
+		return
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
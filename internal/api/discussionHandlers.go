@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// defaultDiscussionBranches and maxDiscussionBranches bound the branches of a discussion summary
+const (
+	defaultDiscussionBranches = 5
+	maxDiscussionBranches     = 50
+)
+
+// handleDiscussionSummary returns the stats of a story's stored comment tree with its top-level
+// comments that drew the most replies. Threads deeper than DISCUSSION_MAX_DEPTH are cut off.
+func (s *Server) handleDiscussionSummary(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	branches := defaultDiscussionBranches
+	if v := r.URL.Query().Get("branches"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid branches: %q", v))
+			return
+		}
+		branches = min(n, maxDiscussionBranches)
+	}
+
+	if _, err := postgres.NewStoryRepository().GetByID(r.Context(), id); err != nil {
+		writeStoreError(w, r, err, "story")
+		return
+	}
+	comments, err := postgres.NewCommentRepository().GetThread(r.Context(), id, config.GetEnvInt("DISCUSSION_MAX_DEPTH", 100))
+	if err != nil {
+		writeStoreError(w, r, err, "discussion")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.SummarizeDiscussion(id, comments, branches))
+}
+
+// itemThread is the response of the thread endpoint
+type itemThread struct {
+	ID       int               `json:"id"`
+	Comments []*models.Comment `json:"comments"`
+}
+
+// Sibling orders of the thread endpoint
+const (
+	threadSortHN   = "hn"   // HackerNews rank order
+	threadSortBest = "best" // quality rank order of the comment ranking job
+)
+
+// handleItemThread returns the stored comments under an item in rendering order, each with its
+// depth and rank among its siblings. sort=best orders the siblings by quality instead of
+// HackerNews rank. Threads deeper than DISCUSSION_MAX_DEPTH are cut off. When no comment is stored
+// and ITEM_FETCH_FALLBACK is set, the thread is fetched live from the HN API and saved first.
+func (s *Server) handleItemThread(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	order := r.URL.Query().Get("sort")
+	if order != "" && order != threadSortHN && order != threadSortBest {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid sort: %q", order))
+		return
+	}
+
+	ctx := r.Context()
+	repo := postgres.NewCommentRepository()
+	maxDepth := config.GetEnvInt("DISCUSSION_MAX_DEPTH", 100)
+	comments, err := repo.GetThread(ctx, id, maxDepth)
+	if err != nil {
+		writeStoreError(w, r, err, "thread")
+		return
+	}
+	if len(comments) == 0 && s.hnClient != nil && liveFetchEnabled() {
+		if err := s.fetchLiveThread(ctx, id); err != nil && !errors.Is(err, errLiveItemNotFound) {
+			tracing.Logf(ctx, "Error fetching live thread %d: %v", id, err)
+			writeError(w, http.StatusBadGateway, "failed to fetch thread")
+			return
+		}
+		if comments, err = repo.GetThread(ctx, id, maxDepth); err != nil {
+			writeStoreError(w, r, err, "thread")
+			return
+		}
+		w.Header().Set("X-Cache", "LIVE")
+	}
+	if order == threadSortBest {
+		models.SortByQuality(comments)
+	}
+	writeJSON(w, http.StatusOK, itemThread{ID: id, Comments: models.OrderThread(id, comments)})
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// domainRecentStories is how many recent stories a domain summary includes
+const domainRecentStories = 10
+
+// handleGetDomain summarizes the stories linking to a site: the aggregates of the domains job and
+// the tenant's most recent stories from it
+func (s *Server) handleGetDomain(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(strings.ToLower(r.PathValue("domain")), "www.")
+	domain, err := postgres.NewDomainRepository().GetByDomain(r.Context(), name)
+	if err != nil {
+		writeStoreError(w, r, err, "domain")
+		return
+	}
+	stories, err := postgres.NewStoryRepository().GetByFilter(r.Context(), repository.ItemFilter{
+		Tenant: tenantFromContext(r.Context()),
+		Domain: name,
+		Limit:  domainRecentStories,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, "stories")
+		return
+	}
+	if stories == nil {
+		stories = []*models.Story{}
+	}
+	writeJSON(w, http.StatusOK, models.DomainSummary{Domain: domain, RecentStories: stories})
+}
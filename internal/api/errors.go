@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"internship-project/internal/tracing"
+)
+
+// Error codes of the error envelope; clients should branch on these, not on messages
+const (
+	codeInvalidArgument  = "invalid_argument"
+	codeUnauthenticated  = "unauthenticated"
+	codePermissionDenied = "permission_denied"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeExpired          = "expired"
+	codeRateLimited      = "rate_limited"
+	codeQuotaExceeded    = "quota_exceeded" // adding a resource over the quota of the API key
+	codeCanceled         = "canceled"
+	codeInternal         = "internal"
+	codeUpstream         = "upstream_error"
+	codeUnavailable      = "unavailable"
+	codeTimeout          = "timeout"
+)
+
+// statusClientClosedRequest is the non-standard status logged when the client goes away mid-request
+const statusClientClosedRequest = 499
+
+// errorResponse is the body of every error response
+type errorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// codeForStatus maps an HTTP status to its error code
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return codeInvalidArgument
+	case http.StatusUnauthorized:
+		return codeUnauthenticated
+	case http.StatusForbidden:
+		return codePermissionDenied
+	case http.StatusNotFound:
+		return codeNotFound
+	case http.StatusMethodNotAllowed:
+		return codeMethodNotAllowed
+	case http.StatusGone:
+		return codeExpired
+	case http.StatusTooManyRequests:
+		return codeRateLimited
+	case statusClientClosedRequest:
+		return codeCanceled
+	case http.StatusBadGateway:
+		return codeUpstream
+	case http.StatusServiceUnavailable:
+		return codeUnavailable
+	case http.StatusGatewayTimeout:
+		return codeTimeout
+	default:
+		return codeInternal
+	}
+}
+
+// writeError sends the error envelope with the given status code and the request ID set by withRequestID
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorDetails(w, status, message, nil)
+}
+
+// writeErrorDetails is writeError with machine-readable details for the client
+func writeErrorDetails(w http.ResponseWriter, status int, message string, details interface{}) {
+	writeErrorCode(w, status, codeForStatus(status), message, details)
+}
+
+// writeErrorCode is writeErrorDetails with a code more specific than the one of the status
+func writeErrorCode(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	writeJSON(w, status, errorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get(tracing.Header),
+	})
+}
+
+// writeStoreError maps an error of a repository call loading what to its status:
+// missing rows are 404, timeouts 504, cancellations 499 and anything else is logged as a 500
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error, what string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, what+" not found")
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, "timed out loading "+what)
+	case errors.Is(err, context.Canceled):
+		writeError(w, statusClientClosedRequest, "request canceled")
+	default:
+		tracing.Logf(r.Context(), "Error loading %s: %v", what, err)
+		writeError(w, http.StatusInternalServerError, "failed to load "+what)
+	}
+}
+
+// statusOnlyWriter records the status of the mux's plain-text 404/405 replies and drops their body
+type statusOnlyWriter struct {
+	header http.Header
+	status int
+}
+
+func (sw *statusOnlyWriter) Header() http.Header         { return sw.header }
+func (sw *statusOnlyWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (sw *statusOnlyWriter) WriteHeader(status int)      { sw.status = status }
+
+// withRouteErrors answers requests that match no route (404) or no method of a route (405)
+// with the error envelope instead of the mux's plain-text replies
+func withRouteErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		sw := &statusOnlyWriter{header: w.Header(), status: http.StatusNotFound}
+		h.ServeHTTP(sw, r)
+		w.Header().Del("X-Content-Type-Options")
+		if sw.status == http.StatusMethodNotAllowed {
+			writeErrorDetails(w, sw.status, fmt.Sprintf("method %s not allowed for %s", r.Method, r.URL.Path),
+				map[string]string{"allow": w.Header().Get("Allow")})
+			return
+		}
+		writeError(w, sw.status, fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+	})
+}
+
+// withRecovery turns a handler panic into a logged 500 error envelope
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				tracing.Logf(r.Context(), "Panic serving %s %s: %v", r.Method, r.URL.Path, v)
+				writeError(w, http.StatusInternalServerError, "internal error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldSet is the JSON fields a client selected with ?fields=; nil selects them all
+type fieldSet map[string]bool
+
+// jsonFieldIndexes caches the struct field index of every JSON field name, by struct type
+var jsonFieldIndexes sync.Map
+
+// jsonFields returns the struct field index of every JSON field name of a struct type
+func jsonFields(t reflect.Type) map[string]int {
+	if cached, ok := jsonFieldIndexes.Load(t); ok {
+		return cached.(map[string]int)
+	}
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = i
+	}
+	jsonFieldIndexes.Store(t, fields)
+	return fields
+}
+
+// parseFields reads the comma-separated ?fields= list of the JSON field names of T, the item
+// model a response holds
+func parseFields[T any](r *http.Request) (fieldSet, error) {
+	return parseFieldsOf(r, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// parseFieldsOf reads ?fields= for items of struct type t
+func parseFieldsOf(r *http.Request, t reflect.Type) (fieldSet, error) {
+	param := r.URL.Query().Get("fields")
+	if param == "" {
+		return nil, nil
+	}
+	known := jsonFields(t)
+	fields := make(fieldSet)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must name at least one field")
+	}
+	return fields, nil
+}
+
+// selectFields returns the selected fields of an item, a struct or a pointer to one, keyed by
+// their JSON names, so only those are encoded. Selected fields are included even when empty.
+func selectFields(item interface{}, fields fieldSet) map[string]interface{} {
+	v := reflect.Indirect(reflect.ValueOf(item))
+	selected := make(map[string]interface{}, len(fields))
+	if v.Kind() != reflect.Struct {
+		return selected
+	}
+	for name, i := range jsonFields(v.Type()) {
+		if fields[name] {
+			selected[name] = v.Field(i).Interface()
+		}
+	}
+	return selected
+}
+
+// selectJSONFields restricts an encoded JSON object to the selected fields
+func selectJSONFields(body []byte, fields fieldSet) ([]byte, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+	for name := range object {
+		if !fields[name] {
+			delete(object, name)
+		}
+	}
+	return json.Marshal(object)
+}
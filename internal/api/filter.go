@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/spam"
+)
+
+const (
+	defaultPageSize = 30
+	maxPageSize     = 500
+)
+
+// parseItemFilter builds an ItemFilter scoped to the request tenant from the list endpoint query parameters:
+// author, min_score, max_score, min_percentile, start, end, type, domain, q, source, include_spam, exclude_dead_links,
+// limit and offset (or page)
+func parseItemFilter(r *http.Request) (repository.ItemFilter, error) {
+	q := r.URL.Query()
+	filter := repository.ItemFilter{
+		Tenant: tenantFromContext(r.Context()),
+		Author: q.Get("author"),
+		Type:   q.Get("type"),
+		Domain: q.Get("domain"),
+		Query:  q.Get("q"),
+		Source: q.Get("source"),
+		Limit:  defaultPageSize,
+	}
+
+	var err error
+	if filter.MinScore, err = optionalInt(q.Get("min_score"), "min_score"); err != nil {
+		return filter, err
+	}
+	if filter.MaxScore, err = optionalInt(q.Get("max_score"), "max_score"); err != nil {
+		return filter, err
+	}
+	if filter.MinPercentile, err = optionalInt(q.Get("min_percentile"), "min_percentile"); err != nil {
+		return filter, err
+	}
+	if p := filter.MinPercentile; p != nil && (*p < 0 || *p > 100) {
+		return filter, fmt.Errorf("min_percentile must be between 0 and 100")
+	}
+	if filter.Start, err = int64Param(q.Get("start"), "start"); err != nil {
+		return filter, err
+	}
+	if filter.End, err = int64Param(q.Get("end"), "end"); err != nil {
+		return filter, err
+	}
+	if filter.Start > 0 && filter.End > 0 && filter.Start > filter.End {
+		return filter, fmt.Errorf("start must not be after end")
+	}
+
+	// Items flagged as spam are hidden unless explicitly requested
+	includeSpam := false
+	if v := q.Get("include_spam"); v != "" {
+		if includeSpam, err = strconv.ParseBool(v); err != nil {
+			return filter, fmt.Errorf("invalid include_spam: %q", v)
+		}
+	}
+	if !includeSpam {
+		threshold := spam.Threshold()
+		filter.MaxSpamScore = &threshold
+	}
+
+	if v := q.Get("exclude_dead_links"); v != "" {
+		if filter.ExcludeDeadLinks, err = strconv.ParseBool(v); err != nil {
+			return filter, fmt.Errorf("invalid exclude_dead_links: %q", v)
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit: %q", v)
+		}
+		if limit > maxPageSize {
+			limit = maxPageSize
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return filter, fmt.Errorf("invalid offset: %q", v)
+		}
+		filter.Offset = offset
+	} else if v := q.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page <= 0 {
+			return filter, fmt.Errorf("invalid page: %q", v)
+		}
+		filter.Offset = (page - 1) * filter.Limit
+	}
+
+	return filter, nil
+}
+
+func optionalInt(value, name string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return &n, nil
+}
+
+func int64Param(value, name string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return n, nil
+}
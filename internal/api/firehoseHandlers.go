@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"internship-project/internal/config"
+	"internship-project/internal/firehose"
+	"internship-project/internal/tracing"
+)
+
+// firehoseTypes are the item types the firehose can be filtered by
+var firehoseTypes = []string{"story", "ask", "job", "comment", "poll", "pollopt"}
+
+// firehoseWriteTimeout bounds the delivery of one message to a WebSocket client
+const firehoseWriteTimeout = 10 * time.Second
+
+// handleFirehose streams the items saved from now on over a WebSocket, one JSON text message per
+// item, optionally of the ?types= only. The connection is queued FIREHOSE_QUEUE_SIZE items; a
+// client falling further behind is sent a slow_consumer message and disconnected, rather than
+// holding up the pipeline, and can catch up from /api/v1/changes.
+func (s *Server) handleFirehose(w http.ResponseWriter, r *http.Request) {
+	var types []string
+	if v := r.URL.Query().Get("types"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			if !slices.Contains(firehoseTypes, t) {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid type: %q", t))
+				return
+			}
+			types = append(types, t)
+		}
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		writeError(w, http.StatusBadRequest, "expected a WebSocket upgrade request")
+		return
+	}
+
+	server := websocket.Server{
+		// Clients authenticate with headers, not cookies: any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			s.streamFirehose(ws, types)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// streamFirehose writes the events of a new subscription to ws until the client leaves, the
+// server shuts down or the subscription is closed
+func (s *Server) streamFirehose(ws *websocket.Conn, types []string) {
+	defer ws.Close()
+	ctx := ws.Request().Context()
+
+	opts := firehose.Options{
+		QueueSize: config.GetEnvInt("FIREHOSE_QUEUE_SIZE", 256),
+		Policy:    firehose.Disconnect,
+	}
+	if len(types) > 0 {
+		opts.Filter = func(event firehose.Event) bool { return slices.Contains(types, event.Kind) }
+	}
+	sub := s.firehose.Subscribe("websocket", opts)
+	defer sub.Close()
+
+	// The server's read timeout still applies to the hijacked connection; clients send nothing
+	// but control frames, read until they close it
+	ws.SetReadDeadline(time.Time{})
+	left := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, ws)
+		close(left)
+	}()
+
+	for {
+		select {
+		case <-left:
+			return
+		case <-s.closing:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), firehose.ErrSlowSubscriber) {
+					tracing.Logf(ctx, "Disconnecting slow firehose client %s", ws.Request().RemoteAddr)
+					ws.SetWriteDeadline(time.Now().Add(firehoseWriteTimeout))
+					websocket.JSON.Send(ws, errorResponse{Code: "slow_consumer", Message: "fell behind the firehose"})
+				}
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(firehoseWriteTimeout))
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleFirehoseStats describes the subscribers of the firehose with their queues
+func (s *Server) handleFirehoseStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.firehose.Stats())
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// maxSavedSearchLength bounds the saved search queries
+const maxSavedSearchLength = 200
+
+// requireAPIKey rejects requests without an API key: follows and feeds belong to a key,
+// and requests without one all share the default tenant
+func requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) == "" {
+			writeError(w, http.StatusUnauthorized, "an API key is required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleFollow adds the author of a {"author": ...} body to the follows of the API key
+func (s *Server) handleFollow(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Author string `json:"author"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	author := strings.TrimSpace(body.Author)
+	if author == "" {
+		writeError(w, http.StatusBadRequest, "author is required")
+		return
+	}
+
+	follow, err := postgres.NewFollowRepository().Follow(r.Context(), tenantFromContext(r.Context()), author)
+	if err != nil {
+		writeStoreError(w, r, err, "follow")
+		return
+	}
+	writeJSON(w, http.StatusCreated, follow)
+}
+
+// handleListFollows returns the authors followed by the API key
+func (s *Server) handleListFollows(w http.ResponseWriter, r *http.Request) {
+	follows, err := postgres.NewFollowRepository().GetFollows(r.Context(), tenantFromContext(r.Context()))
+	if err != nil {
+		writeStoreError(w, r, err, "follows")
+		return
+	}
+	if follows == nil {
+		follows = []*models.Follow{}
+	}
+	writeJSON(w, http.StatusOK, follows)
+}
+
+// handleUnfollow removes an author from the follows of the API key
+func (s *Server) handleUnfollow(w http.ResponseWriter, r *http.Request) {
+	err := postgres.NewFollowRepository().Unfollow(r.Context(), tenantFromContext(r.Context()), r.PathValue("author"))
+	if err != nil {
+		writeStoreError(w, r, err, "follow")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSaveSearch adds the query of a {"query": ...} body to the saved searches of the API key
+func (s *Server) handleSaveSearch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	query := strings.TrimSpace(body.Query)
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if len(query) > maxSavedSearchLength {
+		writeError(w, http.StatusBadRequest, "query is too long")
+		return
+	}
+
+	// At the quota, saving a query again still returns the saved search
+	quotas, err := tenantQuotas(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "quotas")
+		return
+	}
+	if q := findQuota(quotas, models.QuotaSavedSearches); q.Exceeded() {
+		searches, err := postgres.NewFollowRepository().GetSavedSearches(r.Context(), tenantFromContext(r.Context()))
+		if err != nil {
+			writeStoreError(w, r, err, "saved searches")
+			return
+		}
+		if !slices.ContainsFunc(searches, func(saved *models.SavedSearch) bool { return saved.Query == query }) {
+			writeQuotaExceeded(w, q)
+			return
+		}
+	}
+
+	search, err := postgres.NewFollowRepository().SaveSearch(r.Context(), tenantFromContext(r.Context()), query)
+	if err != nil {
+		writeStoreError(w, r, err, "saved search")
+		return
+	}
+	writeJSON(w, http.StatusCreated, search)
+}
+
+// handleListSavedSearches returns the saved searches of the API key
+func (s *Server) handleListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	searches, err := postgres.NewFollowRepository().GetSavedSearches(r.Context(), tenantFromContext(r.Context()))
+	if err != nil {
+		writeStoreError(w, r, err, "saved searches")
+		return
+	}
+	if searches == nil {
+		searches = []*models.SavedSearch{}
+	}
+	writeJSON(w, http.StatusOK, searches)
+}
+
+// handleDeleteSavedSearch removes one of the saved searches of the API key
+func (s *Server) handleDeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	if err := postgres.NewFollowRepository().DeleteSavedSearch(r.Context(), tenantFromContext(r.Context()), id); err != nil {
+		writeStoreError(w, r, err, "saved search")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFeed returns the items by followed authors and matching saved searches, newest first
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	cursor, limit, err := parseTimelinePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := postgres.NewTimelineRepository().GetFeed(r.Context(), tenantFromContext(r.Context()), cursor, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "feed")
+		return
+	}
+	writeTimelinePage(w, items, limit)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"internship-project/internal/cache"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+	"internship-project/pkg/database"
+)
+
+// listResponse is the paginated envelope returned by every list endpoint
+type listResponse[T any] struct {
+	Items   []T  `json:"items"`
+	Total   int  `json:"total"`
+	Page    int  `json:"page"`
+	Limit   int  `json:"limit"`
+	HasMore bool `json:"has_more"`
+}
+
+// listItems runs a filtered list query together with its count and writes the paginated response,
+// restricted to the ?fields= of the items
+func listItems[T any](
+	w http.ResponseWriter,
+	r *http.Request,
+	name string,
+	list func(ctx context.Context, filter repository.ItemFilter) ([]*T, error),
+	count func(ctx context.Context, filter repository.ItemFilter) (int, error),
+) {
+	filter, err := parseItemFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fields, err := parseFields[T](r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := list(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, r, err, name)
+		return
+	}
+
+	total, err := count(r.Context(), filter)
+	if err != nil {
+		writeStoreError(w, r, err, name)
+		return
+	}
+
+	page := filter.Offset/filter.Limit + 1
+	hasMore := filter.Offset+len(items) < total
+	if fields != nil {
+		selected := make([]map[string]interface{}, len(items))
+		for i, item := range items {
+			selected[i] = selectFields(item, fields)
+		}
+		writeJSON(w, http.StatusOK, listResponse[map[string]interface{}]{
+			Items: selected, Total: total, Page: page, Limit: filter.Limit, HasMore: hasMore,
+		})
+		return
+	}
+	if items == nil {
+		items = []*T{}
+	}
+	writeJSON(w, http.StatusOK, listResponse[*T]{
+		Items:   items,
+		Total:   total,
+		Page:    page,
+		Limit:   filter.Limit,
+		HasMore: hasMore,
+	})
+}
+
+// getItem serves one item by the {id} path value, checking the node-local cache,
+// then the hot item cache in Redis, and finally the database. The caches hold whole items;
+// ?fields= is applied to the response.
+func getItem[T any](
+	w http.ResponseWriter,
+	r *http.Request,
+	local *cache.LocalCache,
+	kind string,
+	get func(ctx context.Context, id int) (*T, error),
+) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	fields, err := parseFields[T](r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key := redis.ItemKey(kind, id)
+	if local != nil {
+		if body, ok := local.Get(key); ok {
+			w.Header().Set("X-Cache", "LOCAL")
+			writeItemJSON(w, r, body, fields)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	var item *T
+	cacheStatus := "HIT"
+
+	var cached T
+	found, err := redis.GetCachedJSON(ctx, key, &cached)
+	if err != nil {
+		tracing.Logf(r.Context(), "Error reading cached %s %d: %v", kind, id, err)
+	}
+	if found {
+		item = &cached
+	} else {
+		cacheStatus = "MISS"
+		item, err = get(ctx, id)
+		if err != nil {
+			writeStoreError(w, r, err, kind)
+			return
+		}
+	}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		tracing.Logf(r.Context(), "Error encoding %s %d: %v", kind, id, err)
+		writeError(w, http.StatusInternalServerError, "failed to encode "+kind)
+		return
+	}
+	if local != nil {
+		local.Set(key, body)
+	}
+	w.Header().Set("X-Cache", cacheStatus)
+	writeItemJSON(w, r, body, fields)
+}
+
+// writeItemJSON writes an encoded item restricted to the selected fields
+func writeItemJSON(w http.ResponseWriter, r *http.Request, body []byte, fields fieldSet) {
+	if fields != nil {
+		selected, err := selectJSONFields(body, fields)
+		if err != nil {
+			tracing.Logf(r.Context(), "Error selecting fields of %s: %v", r.URL.Path, err)
+			writeError(w, http.StatusInternalServerError, "failed to encode item")
+			return
+		}
+		body = selected
+	}
+	writeRawJSON(w, http.StatusOK, body)
+}
+
+// handleHealth reports whether the database is reachable
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if err := database.Health(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleListStories lists stories matching the query filter
+func (s *Server) handleListStories(w http.ResponseWriter, r *http.Request) {
+	repo := postgres.NewStoryRepository()
+	listItems(w, r, "stories", repo.GetByFilter, repo.Count)
+}
+
+// handleListAsks lists asks matching the query filter
+func (s *Server) handleListAsks(w http.ResponseWriter, r *http.Request) {
+	repo := postgres.NewAskRepository()
+	listItems(w, r, "asks", repo.GetByFilter, repo.Count)
+}
+
+// handleListJobs lists jobs matching the query filter
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	repo := postgres.NewJobRepository()
+	listItems(w, r, "jobs", repo.GetByFilter, repo.Count)
+}
+
+// handleListComments lists comments matching the query filter
+func (s *Server) handleListComments(w http.ResponseWriter, r *http.Request) {
+	repo := postgres.NewCommentRepository()
+	listItems(w, r, "comments", repo.GetByFilter, repo.Count)
+}
+
+// handleListPolls lists polls matching the query filter
+func (s *Server) handleListPolls(w http.ResponseWriter, r *http.Request) {
+	repo := postgres.NewPollRepository()
+	listItems(w, r, "polls", repo.GetByFilter, repo.Count)
+}
+
+// handleGetStory returns one story
+func (s *Server) handleGetStory(w http.ResponseWriter, r *http.Request) {
+	getItem(w, r, s.localCache, "story", postgres.NewStoryRepository().GetByID)
+}
+
+// handleGetAsk returns one ask
+func (s *Server) handleGetAsk(w http.ResponseWriter, r *http.Request) {
+	getItem(w, r, s.localCache, "ask", postgres.NewAskRepository().GetByID)
+}
+
+// handleGetJob returns one job
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	getItem(w, r, s.localCache, "job", postgres.NewJobRepository().GetByID)
+}
+
+// handleGetComment returns one comment
+func (s *Server) handleGetComment(w http.ResponseWriter, r *http.Request) {
+	getItem(w, r, s.localCache, "comment", postgres.NewCommentRepository().GetByID)
+}
+
+// handleGetPoll returns one poll
+func (s *Server) handleGetPoll(w http.ResponseWriter, r *http.Request) {
+	getItem(w, r, s.localCache, "poll", postgres.NewPollRepository().GetByID)
+}
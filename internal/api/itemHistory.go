@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// parseAsOf reads the as_of parameter, in unix seconds or RFC 3339
+func parseAsOf(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid as_of: %q (unix seconds or RFC 3339)", value)
+	}
+	return t, nil
+}
+
+// storedItem loads the stored item of the kind
+func storedItem(ctx context.Context, kind string, id int) (interface{}, error) {
+	switch kind {
+	case "story":
+		return postgres.NewStoryRepository().GetByID(ctx, id)
+	case "ask":
+		return postgres.NewAskRepository().GetByID(ctx, id)
+	case "job":
+		return postgres.NewJobRepository().GetByID(ctx, id)
+	case "comment":
+		return postgres.NewCommentRepository().GetByID(ctx, id)
+	case "poll":
+		return postgres.NewPollRepository().GetByID(ctx, id)
+	case "pollopt":
+		return postgres.NewPollOptionRepository().GetByID(ctx, id)
+	}
+	return nil, postgres.ErrUnknownKind
+}
+
+// serveItemAsOf serves a stored item as it was at the as_of time: its current row with the
+// changes logged since then undone. The change log only goes back CHANGES_RETENTION, so older
+// times are refused, as are the times before updates logged without their replaced values, and
+// items posted after as_of are not found.
+func (s *Server) serveItemAsOf(w http.ResponseWriter, r *http.Request, id int, value string) {
+	asOf, err := parseAsOf(value)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	retention := config.GetEnvDuration("CHANGES_RETENTION", 7*24*time.Hour)
+	if asOf.Before(time.Now().Add(-retention)) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("as_of must be within the %v of kept change history", retention))
+		return
+	}
+
+	ctx := r.Context()
+	kind, err := postgres.NewItemRepository().GetKind(ctx, id)
+	if err != nil {
+		writeStoreError(w, r, err, "item")
+		return
+	}
+	item, err := storedItem(ctx, kind, id)
+	if err != nil {
+		writeStoreError(w, r, err, "item")
+		return
+	}
+	fields, err := parseFieldsOf(r, reflect.Indirect(reflect.ValueOf(item)).Type())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if created := reflect.Indirect(reflect.ValueOf(item)).FieldByName("Created_At"); created.IsValid() && created.Int() > asOf.Unix() {
+		writeError(w, http.StatusNotFound, "item not posted yet at as_of")
+		return
+	}
+
+	// Changes logged within the as_of second were already made at as_of
+	revisions, err := postgres.NewChangeRepository().GetItemHistory(ctx, kind, id, asOf.Unix()*1000+999)
+	if err != nil {
+		writeStoreError(w, r, err, "item history")
+		return
+	}
+	if err := models.RewindItem(item, revisions); err != nil {
+		if errors.Is(err, models.ErrHistoryUnavailable) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeStoreError(w, r, err, "item history")
+		return
+	}
+
+	w.Header().Set("X-As-Of", strconv.FormatInt(asOf.Unix(), 10))
+	if fields != nil {
+		writeJSON(w, http.StatusOK, selectFields(item, fields))
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
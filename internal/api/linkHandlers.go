@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// handleDeadLinks lists the tenant's stories whose URL was found dead by the link check job
+func (s *Server) handleDeadLinks(w http.ResponseWriter, r *http.Request) {
+	limit := defaultPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	links, err := postgres.NewStoryRepository().GetDeadLinks(r.Context(), tenantFromContext(r.Context()), limit)
+	if err != nil {
+		writeStoreError(w, r, err, "dead links")
+		return
+	}
+	if links == nil {
+		links = []*models.LinkCheck{}
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+// handleLinkPreview returns the OpenGraph/Twitter card metadata fetched for a story's URL by the
+// link preview job; 404 until it was fetched
+func (s *Server) handleLinkPreview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	preview, err := postgres.NewLinkPreviewRepository().GetByStory(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, "link preview")
+		return
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/config"
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+	"internship-project/internal/transport"
+)
+
+// errLiveItemNotFound reports an ID the HN API has no live item for (missing, deleted or dead)
+var errLiveItemNotFound = errors.New("item not found upstream")
+
+// validatable is the pointer constraint of the item models
+type validatable[T any] interface {
+	*T
+	IsValid() bool
+}
+
+// handleGetAnyItem serves an item of any kind by ID. Items missing from the local store are
+// fetched live from the HN API, saved and announced for indexing when ITEM_FETCH_FALLBACK is set.
+// With as_of, the stored item is served as it was at that time.
+func (s *Server) handleGetAnyItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		s.serveItemAsOf(w, r, id, asOf)
+		return
+	}
+
+	ctx := r.Context()
+	kind, err := "", sql.ErrNoRows
+	if bloom.KnownItems().MayContain(id) {
+		kind, err = postgres.NewItemRepository().GetKind(ctx, id)
+	}
+	if err == nil {
+		s.serveStoredItem(w, r, kind)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		writeStoreError(w, r, err, "item")
+		return
+	}
+	if s.hnClient == nil || !liveFetchEnabled() {
+		writeError(w, http.StatusNotFound, "item not found")
+		return
+	}
+
+	kind, item, err := s.fetchLiveItem(ctx, id)
+	if err != nil {
+		if errors.Is(err, errLiveItemNotFound) {
+			writeError(w, http.StatusNotFound, "item not found")
+			return
+		}
+		tracing.Logf(r.Context(), "Error fetching live item %d: %v", id, err)
+		writeError(w, http.StatusBadGateway, "failed to fetch item")
+		return
+	}
+
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, transport.ItemTopics[kind], []byte(strconv.Itoa(id))); err != nil {
+			tracing.Logf(r.Context(), "Error publishing live %s %d: %v", kind, id, err)
+		}
+	}
+	// The kind, and so the known fields, is only known once the item is fetched
+	fields, err := parseFieldsOf(r, reflect.Indirect(reflect.ValueOf(item)).Type())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("X-Cache", "LIVE")
+	if fields != nil {
+		writeJSON(w, http.StatusOK, selectFields(item, fields))
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// serveStoredItem delegates to the cache-first handler of the item kind
+func (s *Server) serveStoredItem(w http.ResponseWriter, r *http.Request, kind string) {
+	switch kind {
+	case "story":
+		s.handleGetStory(w, r)
+	case "ask":
+		s.handleGetAsk(w, r)
+	case "job":
+		s.handleGetJob(w, r)
+	case "comment":
+		s.handleGetComment(w, r)
+	case "poll":
+		s.handleGetPoll(w, r)
+	case "pollopt":
+		getItem(w, r, s.localCache, "pollopt", postgres.NewPollOptionRepository().GetByID)
+	}
+}
+
+// fetchHNItem fetches and decodes an item from the HN API, or returns errLiveItemNotFound when
+// the API serves null for it
+func (s *Server) fetchHNItem(ctx context.Context, id int) (*models.HNItem, error) {
+	raw, err := s.hnClient.GetHNItem(ctx, id)
+	if errors.Is(err, services.ErrItemGone) {
+		return nil, errLiveItemNotFound
+	}
+	return raw, err
+}
+
+// fetchLiveItem fetches an item from the HN API and upserts it in the table of its kind
+func (s *Server) fetchLiveItem(ctx context.Context, id int) (string, interface{}, error) {
+	raw, err := s.fetchHNItem(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var item interface{}
+	kind := raw.Kind()
+	switch kind {
+	case "story":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewStoryRepository().UpsertBatch)
+	case "ask":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewAskRepository().UpsertBatch)
+	case "job":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewJobRepository().UpsertBatch)
+	case "comment":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewCommentRepository().UpsertBatch)
+	case "poll":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewPollRepository().UpsertBatch)
+	case "pollopt":
+		item, err = storeLiveItem(ctx, s.plugins, raw, postgres.NewPollOptionRepository().UpsertBatch)
+	default:
+		return "", nil, errLiveItemNotFound
+	}
+	if err == nil {
+		bloom.KnownItems().Add(id)
+	}
+	return kind, item, err
+}
+
+// fetchLiveThread fetches the comment tree of an item from the HN API, down to DISCUSSION_MAX_DEPTH
+// levels and THREAD_FETCH_MAX_COMMENTS comments, and saves the comments through the ETL plugins
+func (s *Server) fetchLiveThread(ctx context.Context, id int) error {
+	raw, err := s.fetchHNItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(raw.Kids) == 0 {
+		return nil
+	}
+
+	fetch := services.NewCommentApiService(s.hnClient).FetchMultiple
+	tree, err := services.FetchCommentTrees(ctx, fetch, []services.CommentRoot{{ID: id, Kids: raw.Kids}},
+		services.CommentTreeLimits{
+			MaxDepth: config.GetEnvInt("DISCUSSION_MAX_DEPTH", 100),
+			MaxNodes: config.GetEnvInt("THREAD_FETCH_MAX_COMMENTS", 500),
+		})
+	var multiErr *services.MultiError
+	if errors.As(err, &multiErr) {
+		tracing.Logf(ctx, "Fetched the thread of item %d without %d comments: %v", id, multiErr.Failed(), err)
+	} else if err != nil {
+		return err
+	}
+
+	var comments []*models.Comment
+	for _, comment := range tree.Comments {
+		if !comment.IsValid() {
+			continue
+		}
+		if err := s.plugins.PrePersist(ctx, comment); err != nil {
+			tracing.Logf(ctx, "Dropping live comment rejected by ETL plugin %v", err)
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	if _, err := postgres.NewCommentRepository().UpsertBatch(ctx, comments); err != nil {
+		return fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	ids := make([]int, len(comments))
+	values := make([][]byte, len(comments))
+	for i, comment := range comments {
+		s.plugins.PostPersist(ctx, comment)
+		ids[i] = comment.ID
+		values[i] = []byte(strconv.Itoa(comment.ID))
+	}
+	bloom.KnownItems().Add(ids...)
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, transport.ItemTopics["comment"], values...); err != nil {
+			tracing.Logf(ctx, "Error publishing the live thread of item %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// storeLiveItem converts a fetched item and saves it through the ETL plugins;
+// invalid (deleted or dead) items and items dropped by a plugin are not stored
+func storeLiveItem[T models.Item, PT validatable[T]](
+	ctx context.Context,
+	plugins *etl.Pipeline,
+	raw *models.HNItem,
+	save func(ctx context.Context, items []*T) (repository.UpsertCounts, error),
+) (*T, error) {
+	item := models.ConvertHNItem[T](raw)
+	if !PT(item).IsValid() {
+		return nil, errLiveItemNotFound
+	}
+	if err := plugins.PrePersist(ctx, item); err != nil {
+		tracing.Logf(ctx, "Dropping live item rejected by ETL plugin %v", err)
+		return nil, errLiveItemNotFound
+	}
+	if _, err := save(ctx, []*T{item}); err != nil {
+		return nil, fmt.Errorf("failed to save item: %w", err)
+	}
+	plugins.PostPersist(ctx, item)
+	return item, nil
+}
+
+// liveFetchEnabled reports whether missing items are fetched from the HN API on demand
+func liveFetchEnabled() bool {
+	return config.GetEnvBool("ITEM_FETCH_FALLBACK", false)
+}
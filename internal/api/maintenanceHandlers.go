@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"internship-project/internal/capacity"
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// handleTableStats reports the size, dead rows, estimated bloat and statistics age of the
+// MAINTENANCE_TABLES and their indexes
+func (s *Server) handleTableStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := postgres.NewMaintenanceRepository().GetTableStats(r.Context(),
+		config.GetEnvList("MAINTENANCE_TABLES", postgres.DefaultMaintainedTables))
+	if err != nil {
+		writeStoreError(w, r, err, "table statistics")
+		return
+	}
+	if stats == nil {
+		stats = []*models.TableStats{}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleStorageReport reports the size and daily growth of the database and the STORAGE_TABLES,
+// measured over STORAGE_GROWTH_WINDOW, and the days until the database is projected to fill the
+// STORAGE_THRESHOLDS of a disk of STORAGE_DISK_BYTES
+func (s *Server) handleStorageReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	now := time.Now()
+	current, err := capacity.Measure(ctx, postgres.NewMaintenanceRepository(), postgres.NewStorageRepository(), now)
+	if err != nil {
+		writeStoreError(w, r, err, "storage usage")
+		return
+	}
+	window := config.GetEnvDuration("STORAGE_GROWTH_WINDOW", 7*24*time.Hour)
+	history, err := postgres.NewStorageRepository().GetSnapshots(ctx, capacity.Day(now.Add(-window)))
+	if err != nil {
+		writeStoreError(w, r, err, "storage history")
+		return
+	}
+	tenants, err := postgres.NewTenantRepository().GetAll(ctx)
+	if err != nil {
+		writeStoreError(w, r, err, "tenants")
+		return
+	}
+	writeJSON(w, http.StatusOK, capacity.Build(current, history, capacity.Retentions(tenants),
+		int64(config.GetEnvInt("STORAGE_DISK_BYTES", 0)), capacity.Thresholds()))
+}
+
+// writePrometheusTableStats writes table and index statistics in the Prometheus text exposition format
+func writePrometheusTableStats(w io.Writer, stats []*models.TableStats) error {
+	tableMetrics := []struct {
+		name, help string
+		value      func(t *models.TableStats) float64
+	}{
+		{"table_live_rows", "Live rows of the table and its partitions.",
+			func(t *models.TableStats) float64 { return float64(t.LiveRows) }},
+		{"table_dead_rows", "Dead rows not vacuumed yet.",
+			func(t *models.TableStats) float64 { return float64(t.DeadRows) }},
+		{"table_rows_modified_since_analyze", "Rows written since the planner statistics were gathered.",
+			func(t *models.TableStats) float64 { return float64(t.ModifiedSinceAnalyze) }},
+		{"table_size_bytes", "Size of the table without its indexes.",
+			func(t *models.TableStats) float64 { return float64(t.TableBytes) }},
+		{"table_estimated_bloat_bytes", "Table space estimated to be taken by dead rows.",
+			func(t *models.TableStats) float64 { return float64(t.EstimatedBloatBytes) }},
+		{"table_last_analyzed_timestamp_seconds", "Time the planner statistics were gathered; 0 when never.",
+			func(t *models.TableStats) float64 { return float64(t.LastAnalyzedAt) }},
+	}
+
+	var b strings.Builder
+	for _, m := range tableMetrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, t := range stats {
+			fmt.Fprintf(&b, "%s{table=%s} %s\n", m.name, strconv.Quote(t.Table),
+				strconv.FormatFloat(m.value(t), 'g', -1, 64))
+		}
+	}
+	b.WriteString("# HELP index_size_bytes Size of the index and its partitions.\n# TYPE index_size_bytes gauge\n")
+	for _, t := range stats {
+		for _, index := range t.Indexes {
+			fmt.Fprintf(&b, "index_size_bytes{table=%s,index=%s} %d\n", strconv.Quote(t.Table), strconv.Quote(index.Name), index.Bytes)
+		}
+	}
+	b.WriteString("# HELP index_estimated_bloat_bytes Index space estimated to be taken by entries of dead rows.\n# TYPE index_estimated_bloat_bytes gauge\n")
+	for _, t := range stats {
+		for _, index := range t.Indexes {
+			fmt.Fprintf(&b, "index_estimated_bloat_bytes{table=%s,index=%s} %d\n", strconv.Quote(t.Table), strconv.Quote(index.Name), index.EstimatedBloatBytes)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
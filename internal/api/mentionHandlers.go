@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// mentionsResponse is a page of a user's mentions
+type mentionsResponse struct {
+	Items      []*models.Mention `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// handleUserMentions returns the comments naming a user as @username or replying to their items,
+// newest first. Mentions are recorded by the "mention" ETL plugin.
+func (s *Server) handleUserMentions(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	if username == "" {
+		writeError(w, http.StatusBadRequest, "username is required")
+		return
+	}
+	cursor, limit, err := parseTimelinePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mentions, err := postgres.NewMentionRepository().GetByUsername(r.Context(), tenantFromContext(r.Context()), username, cursor, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "mentions")
+		return
+	}
+	resp := mentionsResponse{Items: mentions}
+	if len(mentions) == limit {
+		last := mentions[len(mentions)-1]
+		resp.NextCursor = encodeTimelineCursor(models.TimelineCursor{Created_At: last.Created_At, ID: last.Comment_ID})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"internship-project/internal/tracing"
+)
+
+// openAPISpec documents every endpoint and the error envelope; keep it in sync with registerRoutes
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// handleOpenAPISpec serves the OpenAPI specification of the API
+func (s *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(openAPISpec); err != nil {
+		tracing.Logf(r.Context(), "Error writing OpenAPI spec: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// quotaDefaults are the limits of the API keys whose record leaves a quota at 0, by resource;
+// a default of 0 means unlimited
+var quotaDefaults = []struct {
+	resource string
+	env      string
+	fallback int
+}{
+	{models.QuotaSavedSearches, "QUOTA_SAVED_SEARCHES", 100},
+	{models.QuotaWatches, "QUOTA_WATCHES", 100},
+	{models.QuotaWebhookEndpoints, "QUOTA_WEBHOOK_ENDPOINTS", 10},
+}
+
+// quotaLimit returns the limit of the resource for the tenant, 0 when unlimited: its own when
+// set in its record, the configured default otherwise
+func quotaLimit(tenant *models.Tenant, resource, env string, fallback int) int {
+	own := 0
+	if tenant != nil {
+		switch resource {
+		case models.QuotaSavedSearches:
+			own = tenant.Max_Saved_Searches
+		case models.QuotaWatches:
+			own = tenant.Max_Watches
+		case models.QuotaWebhookEndpoints:
+			own = tenant.Max_Webhook_Endpoints
+		}
+	}
+	if own < 0 {
+		return 0
+	}
+	if own > 0 {
+		return own
+	}
+	return max(config.GetEnvInt(env, fallback), 0)
+}
+
+// tenantQuotas returns the usage and limit of every limited resource of the API key, in the
+// order of quotaDefaults
+func tenantQuotas(ctx context.Context) ([]models.Quota, error) {
+	usage, err := postgres.NewTenantRepository().GetQuotaUsage(ctx, tenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]int{
+		models.QuotaSavedSearches:    usage.Saved_Searches,
+		models.QuotaWatches:          usage.Watches,
+		models.QuotaWebhookEndpoints: usage.Webhook_Endpoints,
+	}
+	tenant := tenantRecordFromContext(ctx)
+	quotas := make([]models.Quota, len(quotaDefaults))
+	for i, d := range quotaDefaults {
+		quotas[i] = models.Quota{Resource: d.resource, Used: used[d.resource], Limit: quotaLimit(tenant, d.resource, d.env, d.fallback)}
+	}
+	return quotas, nil
+}
+
+// findQuota returns the quota of the resource among quotas
+func findQuota(quotas []models.Quota, resource string) models.Quota {
+	for _, q := range quotas {
+		if q.Resource == resource {
+			return q
+		}
+	}
+	return models.Quota{Resource: resource}
+}
+
+// writeQuotaExceeded rejects a request adding a resource over its quota with a 403 whose details
+// hold the usage and limit
+func writeQuotaExceeded(w http.ResponseWriter, q models.Quota) {
+	writeErrorCode(w, http.StatusForbidden, codeQuotaExceeded,
+		fmt.Sprintf("%s quota exceeded: %d of %d used", q.Resource, q.Used, q.Limit), q)
+}
+
+// handleGetQuotas returns the usage and limits of the API key
+func (s *Server) handleGetQuotas(w http.ResponseWriter, r *http.Request) {
+	quotas, err := tenantQuotas(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "quotas")
+		return
+	}
+	writeJSON(w, http.StatusOK, quotas)
+}
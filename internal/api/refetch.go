@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"internship-project/internal/config"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/tracing"
+	"internship-project/internal/transport"
+)
+
+// refetchRequest selects the items of an admin refetch: explicit IDs, or the stored items of the
+// types (all by default) matching the author and creation time filter
+type refetchRequest struct {
+	IDs    []int    `json:"ids"`
+	Author string   `json:"author"`
+	Start  int64    `json:"start"`
+	End    int64    `json:"end"`
+	Types  []string `json:"types"`
+}
+
+// refetchResult reports the outcome of an admin refetch
+type refetchResult struct {
+	Requested int            `json:"requested"`
+	Refetched []int          `json:"refetched"`
+	Missing   []int          `json:"missing"`          // gone upstream (deleted, dead) or dropped by an ETL plugin
+	Failed    map[int]string `json:"failed,omitempty"` // errors by item ID
+	Reindexed bool           `json:"reindexed"`        // the refetched IDs were published for indexing
+}
+
+// refetchEnabled reports whether admins may force items to be fetched again from the HN API
+func refetchEnabled() bool {
+	return config.GetEnvBool("ITEM_REFETCH_ENABLED", false)
+}
+
+// handleRefetchItems fetches the selected items again from the HN API, upserts them through the
+// ETL plugins, invalidates their cached copies and publishes them for reindexing; it repairs
+// stored items corrupted by a parsing bug. At most ITEM_REFETCH_MAX_ITEMS items are refetched per
+// request, ITEM_REFETCH_CONCURRENCY at a time, or queued as a "refetch" task with ?async=true.
+func (s *Server) handleRefetchItems(w http.ResponseWriter, r *http.Request) {
+	if s.hnClient == nil {
+		writeError(w, http.StatusServiceUnavailable, "item refetch is not enabled")
+		return
+	}
+
+	var req refetchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	maxItems := config.GetEnvInt("ITEM_REFETCH_MAX_ITEMS", 500)
+	ids, err := s.refetchIDs(r.Context(), req, maxItems)
+	if err != nil {
+		var invalid *invalidRefetchError
+		if errors.As(err, &invalid) {
+			writeError(w, http.StatusBadRequest, invalid.Error())
+			return
+		}
+		writeStoreError(w, r, err, "items to refetch")
+		return
+	}
+
+	// With ?async=true the refetch is queued as a task, reported by the task endpoints
+	if r.URL.Query().Get("async") == "true" {
+		if s.tasks == nil {
+			writeError(w, http.StatusServiceUnavailable, "the task queue is not enabled")
+			return
+		}
+		task, err := s.tasks.Enqueue(r.Context(), "refetch", refetchTask{IDs: ids}, 0)
+		if err != nil {
+			writeStoreError(w, r, err, "refetch task")
+			return
+		}
+		writeJSON(w, http.StatusAccepted, task)
+		return
+	}
+
+	result := s.refetch(r.Context(), ids, config.GetEnvInt("ITEM_REFETCH_CONCURRENCY", 8))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// invalidRefetchError reports a refetch request selecting no items or too many
+type invalidRefetchError struct {
+	message string
+}
+
+func (e *invalidRefetchError) Error() string { return e.message }
+
+// refetchIDs resolves the IDs selected by a refetch request, rejecting more than maxItems
+func (s *Server) refetchIDs(ctx context.Context, req refetchRequest, maxItems int) ([]int, error) {
+	if len(req.IDs) > 0 {
+		if req.Author != "" || req.Start != 0 || req.End != 0 || len(req.Types) > 0 {
+			return nil, &invalidRefetchError{"ids cannot be combined with a filter"}
+		}
+		if len(req.IDs) > maxItems {
+			return nil, &invalidRefetchError{fmt.Sprintf("at most %d ids can be refetched at once", maxItems)}
+		}
+		for _, id := range req.IDs {
+			if id <= 0 {
+				return nil, &invalidRefetchError{fmt.Sprintf("invalid id: %d", id)}
+			}
+		}
+		return req.IDs, nil
+	}
+
+	if req.Author == "" && req.Start == 0 && req.End == 0 {
+		return nil, &invalidRefetchError{"ids or an author, start or end filter is required"}
+	}
+	if req.Start > 0 && req.End > 0 && req.Start > req.End {
+		return nil, &invalidRefetchError{"start must not be after end"}
+	}
+	kinds := search.Kinds()
+	if len(req.Types) > 0 {
+		for _, kind := range req.Types {
+			if !slices.Contains(kinds, kind) {
+				return nil, &invalidRefetchError{fmt.Sprintf("invalid types: unknown type %q", kind)}
+			}
+		}
+		kinds = req.Types
+	}
+
+	filter := repository.ItemFilter{Author: req.Author, Start: req.Start, End: req.End}
+	ids, err := postgres.NewItemRepository().GetIDs(ctx, kinds, filter, maxItems+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > maxItems {
+		return nil, &invalidRefetchError{fmt.Sprintf("the filter matches more than %d items; narrow it", maxItems)}
+	}
+	if len(ids) == 0 {
+		return nil, &invalidRefetchError{"the filter matches no stored items"}
+	}
+	return ids, nil
+}
+
+// refetch fetches and upserts the items with up to concurrency requests in flight, then
+// invalidates and republishes the refetched ones
+func (s *Server) refetch(ctx context.Context, ids []int, concurrency int) *refetchResult {
+	result := &refetchResult{Requested: len(ids), Refetched: []int{}, Missing: []int{}, Failed: map[int]string{}}
+	byKind := make(map[string][]int)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(concurrency, 1))
+	for _, id := range ids {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			kind, _, err := s.fetchLiveItem(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, errLiveItemNotFound):
+				result.Missing = append(result.Missing, id)
+			case err != nil:
+				result.Failed[id] = err.Error()
+			default:
+				result.Refetched = append(result.Refetched, id)
+				byKind[kind] = append(byKind[kind], id)
+			}
+		}(id)
+	}
+	wg.Wait()
+	sort.Ints(result.Refetched)
+	sort.Ints(result.Missing)
+
+	var keys []string
+	for kind, kindIDs := range byKind {
+		for _, id := range kindIDs {
+			keys = append(keys, redis.ItemKey(kind, id))
+		}
+	}
+	if err := redis.PublishInvalidation(ctx, keys...); err != nil {
+		tracing.Logf(ctx, "Error invalidating refetched items: %v", err)
+	}
+
+	if s.publisher == nil {
+		return result
+	}
+	result.Reindexed = true
+	for kind, kindIDs := range byKind {
+		topic, ok := transport.ItemTopics[kind]
+		if !ok {
+			continue
+		}
+		values := make([][]byte, len(kindIDs))
+		for i, id := range kindIDs {
+			values[i] = []byte(strconv.Itoa(id))
+		}
+		if err := s.publisher.Publish(ctx, topic, values...); err != nil {
+			tracing.Logf(ctx, "Error publishing refetched %s items: %v", kind, err)
+			result.Reindexed = false
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+const (
+	// defaultRelatedItems and maxRelatedItems bound the limit of the related items endpoint
+	defaultRelatedItems = 10
+	maxRelatedItems     = 50
+
+	// defaultRelatedCacheTTL is how long related items stay in Redis
+	defaultRelatedCacheTTL = time.Hour
+)
+
+// relatedItems is the response of the related items endpoint
+type relatedItems struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"` // "more_like_this" or "heuristic"
+	Items  []*models.Story `json:"items"`
+}
+
+// handleRelatedItems returns stories similar to an item: OpenSearch more_like_this matches when
+// RELATED_OPENSEARCH_URL is set, else (or when it finds nothing) stories sharing its domain or author.
+// Results are cached per item in Redis for RELATED_CACHE_TTL.
+func (s *Server) handleRelatedItems(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	limit := defaultRelatedItems
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxRelatedItems)
+	}
+
+	ctx := r.Context()
+	tenant := tenantFromContext(ctx)
+	cacheKey := fmt.Sprintf("related:%s:%d:%d", tenant, id, limit)
+
+	var cached relatedItems
+	found, err := redis.GetCachedJSON(ctx, cacheKey, &cached)
+	if err != nil {
+		tracing.Logf(ctx, "Error reading related items cache for %d: %v", id, err)
+	}
+	if found {
+		w.Header().Set("X-Cache", "HIT")
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	repo := postgres.NewStoryRepository()
+	result := relatedItems{ID: id}
+	if s.moreLikeThis != nil {
+		ids, err := s.moreLikeThis.Related(ctx, id, limit)
+		if err != nil {
+			tracing.Logf(ctx, "Falling back to heuristics for related items of %d: %v", id, err)
+		} else if len(ids) > 0 {
+			if result.Items, err = repo.GetByIDs(ctx, tenant, ids); err != nil {
+				writeStoreError(w, r, err, "related items")
+				return
+			}
+			result.Method = "more_like_this"
+		}
+	}
+	if len(result.Items) == 0 {
+		if result.Items, err = repo.GetRelated(ctx, tenant, id, limit); err != nil {
+			writeStoreError(w, r, err, "item")
+			return
+		}
+		result.Method = "heuristic"
+	}
+	if result.Items == nil {
+		result.Items = []*models.Story{}
+	}
+
+	ttl := config.GetEnvDuration("RELATED_CACHE_TTL", defaultRelatedCacheTTL)
+	if err := redis.CacheJSON(ctx, cacheKey, result, ttl); err != nil {
+		tracing.Logf(ctx, "Error caching related items for %d: %v", id, err)
+	}
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, http.StatusOK, result)
+}
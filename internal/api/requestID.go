@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"internship-project/internal/loadshed"
+	"internship-project/internal/tracing"
+)
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hands the connection over to a WebSocket handler, recording the switch of protocols
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+// withRequestID tags every request with the caller's X-Request-ID (or traceparent trace-id)
+// or a new ID, echoes it in the response header, stores it in the request context for
+// logging and logs the request once it completes. Durations feed the load-shedding governor.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := tracing.FromHeaders(r.Header)
+		if id == "" {
+			id = tracing.NewID()
+		}
+		w.Header().Set(tracing.Header, id)
+
+		ctx := tracing.WithID(r.Context(), id)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		elapsed := time.Since(start)
+		// A WebSocket lives as long as its client stays: its duration says nothing about latency
+		if rec.status != http.StatusSwitchingProtocols {
+			loadshed.APILatency.Observe(elapsed)
+		}
+
+		tracing.Logf(ctx, "%s %s %d %v", r.Method, r.URL.RequestURI(), rec.status, elapsed.Round(time.Microsecond))
+	})
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/tracing"
+)
+
+// maxSnippetLength bounds the snippet_length of the search endpoint
+const maxSnippetLength = 1000
+
+// searchResponse is the response of the search endpoint
+type searchResponse struct {
+	Query string `json:"query"`
+	*search.Result
+}
+
+// handleSearch runs one full-text search over the indexes of the requested types (all by default),
+// ranking stories above comments and reporting how many documents of each type matched.
+// rank picks the ranking profile: relevance (default), hot, top or new. Unless dedupe is false,
+// stories linked as duplicates are collapsed into the highest-scored one.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if s.search == nil {
+		writeError(w, http.StatusServiceUnavailable, "search is not configured")
+		return
+	}
+
+	filter, err := parseItemFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := search.Request{
+		Query:  filter.Query,
+		Author: filter.Author,
+		Tag:    strings.ToLower(r.URL.Query().Get("tag")),
+		Start:  filter.Start,
+		End:    filter.End,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	if v := r.URL.Query().Get("rank"); v != "" {
+		if !search.ValidRank(v) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid rank: %q", v))
+			return
+		}
+		req.Rank = v
+	}
+	if v := r.URL.Query().Get("types"); v != "" {
+		for _, kind := range strings.Split(v, ",") {
+			if _, err := s.search.Index(kind); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid types: unknown type %q", kind))
+				return
+			}
+			req.Kinds = append(req.Kinds, kind)
+		}
+	}
+
+	if v := r.URL.Query().Get("new_authors"); v != "" {
+		if req.NewAuthors, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid new_authors: %q", v))
+			return
+		}
+	}
+
+	if v := r.URL.Query().Get("snippet_length"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid snippet_length: %q", v))
+			return
+		}
+		req.SnippetLength = min(n, maxSnippetLength)
+	}
+
+	dedupe := true
+	if v := r.URL.Query().Get("dedupe"); v != "" {
+		if dedupe, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid dedupe: %q", v))
+			return
+		}
+	}
+
+	start := time.Now()
+	result, err := s.search.Search(r.Context(), req)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, "search timed out")
+		return
+	case errors.Is(err, context.Canceled):
+		writeError(w, statusClientClosedRequest, "request canceled")
+		return
+	case err != nil:
+		tracing.Logf(r.Context(), "Error searching %q: %v", req.Query, err)
+		writeError(w, http.StatusBadGateway, "search failed")
+		return
+	}
+
+	if dedupe {
+		var storyIDs []int
+		for _, hit := range result.Hits {
+			if hit.Kind == "story" {
+				storyIDs = append(storyIDs, hit.ID)
+			}
+		}
+		if len(storyIDs) > 0 {
+			canonical, err := postgres.NewStoryRepository().GetCanonicalIDs(r.Context(), storyIDs)
+			if err != nil {
+				writeStoreError(w, r, err, "story duplicates")
+				return
+			}
+			result.Hits = search.Collapse(result.Hits, canonical)
+		}
+	}
+	if config.GetEnvBool("SEARCH_ANALYTICS_ENABLED", true) {
+		recordSearch(r, result.Total, time.Since(start))
+	}
+	writeJSON(w, http.StatusOK, searchResponse{Query: req.Query, Result: result})
+}
+
+// searchFilterParams are the search parameters recorded with a query for analytics
+var searchFilterParams = []string{"types", "author", "tag", "start", "end", "rank"}
+
+// recordSearch stores a search request for query analytics. Failures are only logged:
+// analytics must not fail the search.
+func recordSearch(r *http.Request, hits int64, latency time.Duration) {
+	query := r.URL.Query()
+	filters := make(map[string]string)
+	for _, name := range searchFilterParams {
+		if v := query.Get(name); v != "" {
+			filters[name] = v
+		}
+	}
+
+	err := postgres.NewSearchQueryRepository().Record(r.Context(), &models.SearchQuery{
+		Tenant:     tenantFromContext(r.Context()),
+		Query_Key:  models.QueryKey(query.Get("q")),
+		Query:      query.Get("q"),
+		Filters:    filters,
+		Hits:       hits,
+		Latency_Ms: latency.Milliseconds(),
+		Created_At: time.Now().Unix(),
+	})
+	if err != nil {
+		tracing.Logf(r.Context(), "Error recording search query: %v", err)
+	}
+}
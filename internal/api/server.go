@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/cache"
+	"internship-project/internal/config"
+	"internship-project/internal/etl"
+	"internship-project/internal/firehose"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/services"
+	"internship-project/internal/tasks"
+	"internship-project/internal/transport"
+)
+
+// Server exposes the synced HackerNews data over HTTP
+type Server struct {
+	httpServer *http.Server
+	mux        *http.ServeMux
+	localCache *cache.LocalCache // nil when the local cache is disabled
+	stop       context.CancelFunc
+
+	// Items fetched from the HN API: missing items on read when ITEM_FETCH_FALLBACK is set and
+	// admin refetches when ITEM_REFETCH_ENABLED is set; all nil when both are off
+	hnClient  *services.HackerNewsApiClient
+	publisher transport.Publisher
+	plugins   *etl.Pipeline
+
+	moreLikeThis *services.MoreLikeThis // nil when related items use the domain/author heuristics only
+	search       *search.Client         // nil when OPENSEARCH_URL is not set
+	tasks        *tasks.Worker          // nil when TASKS_ENABLED is off
+
+	firehose *firehose.Hub // streamed to WebSocket clients
+	closing  chan struct{} // closed on shutdown, to end the WebSocket streams
+}
+
+// NewServer creates a new API server listening on addr
+func NewServer(addr string) *Server {
+	mux := http.NewServeMux()
+	s := &Server{
+		mux: mux,
+		httpServer: &http.Server{
+			Addr:         addr,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		},
+	}
+	s.moreLikeThis = services.NewMoreLikeThis()
+	s.firehose = firehose.Default()
+	s.closing = make(chan struct{})
+	s.search = search.NewClient()
+	if config.GetEnvBool("LOCAL_CACHE_ENABLED", true) {
+		localCache, err := cache.NewLocalCache()
+		if err != nil {
+			log.Printf("Running without local cache: %v", err)
+		} else {
+			s.localCache = localCache
+		}
+	}
+
+	if liveFetchEnabled() || refetchEnabled() {
+		plugins, err := etl.NewPipelineFromConfig()
+		if err != nil {
+			log.Printf("Live item fetch and refetch disabled: %v", err)
+		} else {
+			s.hnClient = services.NewHackerNewsApiClient()
+			s.hnClient.SetDeadLetterSink(postgres.NewDeadLetterRepository())
+			s.hnClient.SetPayloadSink(postgres.NewItemPayloadRepository())
+			s.plugins = plugins
+		}
+	}
+	if s.hnClient != nil {
+		publisher, err := transport.NewPublisher()
+		if err != nil {
+			log.Printf("Live-fetched items will not be published: %v", err)
+		} else {
+			s.publisher = publisher
+		}
+	}
+
+	s.registerRoutes()
+	s.httpServer.Handler = withRequestID(withRecovery(s.withTenant(withRouteErrors(mux))))
+	return s
+}
+
+// NewDefaultServer creates an API server using the API_ADDR environment variable
+func NewDefaultServer() *Server {
+	return NewServer(config.GetEnv("API_ADDR", ":8080"))
+}
+
+// registerRoutes wires every endpoint to its handler
+func (s *Server) registerRoutes() {
+	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/v1/openapi.yaml", s.handleOpenAPISpec)
+
+	s.mux.HandleFunc("GET /api/v1/stories", s.handleListStories)
+	s.mux.HandleFunc("GET /api/v1/asks", s.handleListAsks)
+	s.mux.HandleFunc("GET /api/v1/jobs", s.handleListJobs)
+	s.mux.HandleFunc("GET /api/v1/comments", s.handleListComments)
+	s.mux.HandleFunc("GET /api/v1/polls", s.handleListPolls)
+
+	s.mux.HandleFunc("GET /api/v1/stories/{id}", s.handleGetStory)
+	s.mux.HandleFunc("GET /api/v1/stories/dead-links", s.handleDeadLinks)
+	s.mux.HandleFunc("GET /api/v1/stories/{id}/discussion-summary", s.handleDiscussionSummary)
+	s.mux.HandleFunc("GET /api/v1/stories/{id}/preview", s.handleLinkPreview)
+	s.mux.HandleFunc("GET /api/v1/asks/{id}", s.handleGetAsk)
+	s.mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleGetJob)
+	s.mux.HandleFunc("GET /api/v1/comments/{id}", s.handleGetComment)
+	s.mux.HandleFunc("GET /api/v1/polls/{id}", s.handleGetPoll)
+	s.mux.HandleFunc("GET /api/v1/items/{id}", s.handleGetAnyItem)
+	s.mux.HandleFunc("GET /api/v1/items/new-authors", s.handleNewAuthors)
+	s.mux.HandleFunc("POST /api/v1/items:batchGet", s.handleBatchGetItems)
+	s.mux.HandleFunc("GET /api/v1/items/{id}/related", s.handleRelatedItems)
+	s.mux.HandleFunc("GET /api/v1/items/{id}/thread", s.handleItemThread)
+
+	s.mux.HandleFunc("GET /api/v1/search", s.handleSearch)
+	s.mux.HandleFunc("GET /api/v1/timeline", s.handleTimeline)
+	s.mux.HandleFunc("GET /api/v1/changes", s.handleChanges)
+	s.mux.HandleFunc("GET /api/v1/firehose", s.handleFirehose)
+	s.mux.HandleFunc("GET /api/v1/tags", s.handleListTags)
+	s.mux.HandleFunc("GET /api/v1/tags/{tag}/items", s.handleTagItems)
+	s.mux.HandleFunc("GET /api/v1/domains/{domain}", s.handleGetDomain)
+	s.mux.HandleFunc("GET /api/v1/feed", requireAPIKey(s.handleFeed))
+	s.mux.HandleFunc("GET /api/v1/follows", requireAPIKey(s.handleListFollows))
+	s.mux.HandleFunc("POST /api/v1/follows", requireAPIKey(s.handleFollow))
+	s.mux.HandleFunc("DELETE /api/v1/follows/{author}", requireAPIKey(s.handleUnfollow))
+	s.mux.HandleFunc("GET /api/v1/saved-searches", requireAPIKey(s.handleListSavedSearches))
+	s.mux.HandleFunc("POST /api/v1/saved-searches", requireAPIKey(s.handleSaveSearch))
+	s.mux.HandleFunc("DELETE /api/v1/saved-searches/{id}", requireAPIKey(s.handleDeleteSavedSearch))
+	s.mux.HandleFunc("GET /api/v1/watches", requireAPIKey(s.handleListWatches))
+	s.mux.HandleFunc("POST /api/v1/watches", requireAPIKey(s.handleWatch))
+	s.mux.HandleFunc("DELETE /api/v1/watches/{id}", requireAPIKey(s.handleDeleteWatch))
+	s.mux.HandleFunc("GET /api/v1/quotas", requireAPIKey(s.handleGetQuotas))
+	s.mux.HandleFunc("GET /api/v1/users/{username}/heatmap", s.handleUserHeatmap)
+	s.mux.HandleFunc("GET /api/v1/users/{username}/mentions", s.handleUserMentions)
+	s.mux.HandleFunc("GET /api/v1/stats/daily", s.handleDailyStats)
+
+	s.mux.HandleFunc("GET /api/v1/admin/explain/{query}", requireAdmin(s.handleExplainQuery))
+	s.mux.HandleFunc("GET /api/v1/admin/vars", requireAdmin(expvar.Handler().ServeHTTP))
+	s.mux.HandleFunc("GET /api/v1/admin/metrics", requireAdmin(s.handleMetrics))
+	s.mux.HandleFunc("GET /api/v1/admin/consumers/status", requireAdmin(s.handleConsumersStatus))
+	s.mux.HandleFunc("GET /api/v1/admin/tables", requireAdmin(s.handleTableStats))
+	s.mux.HandleFunc("GET /api/v1/admin/storage", requireAdmin(s.handleStorageReport))
+	s.mux.HandleFunc("GET /api/v1/admin/data-quality", requireAdmin(s.handleDataQualityReport))
+	s.mux.HandleFunc("GET /api/v1/admin/search-analytics", requireAdmin(s.handleSearchAnalytics))
+	s.mux.HandleFunc("GET /api/v1/admin/search/analyzer", requireAdmin(s.handleGetSearchAnalyzer))
+	s.mux.HandleFunc("PUT /api/v1/admin/search/analyzer", requireAdmin(s.handleUpdateSearchAnalyzer))
+	s.mux.HandleFunc("POST /api/v1/admin/items/refetch", requireAdmin(s.handleRefetchItems))
+	s.mux.HandleFunc("GET /api/v1/admin/tasks", requireAdmin(s.handleListTasks))
+	s.mux.HandleFunc("POST /api/v1/admin/tasks", requireAdmin(s.handleEnqueueTask))
+	s.mux.HandleFunc("GET /api/v1/admin/tasks/{id}", requireAdmin(s.handleGetTask))
+	s.mux.HandleFunc("GET /api/v1/admin/firehose", requireAdmin(s.handleFirehoseStats))
+	s.mux.HandleFunc("GET /api/v1/admin/dead-letters", requireAdmin(s.handleListDeadLetters))
+	s.mux.HandleFunc("GET /api/v1/admin/items/{id}/payload", requireAdmin(s.handleGetItemPayload))
+	s.mux.HandleFunc("GET /api/v1/admin/webhook-deliveries", requireAdmin(s.handleListWebhookDeliveries))
+	s.mux.HandleFunc("GET /api/v1/admin/webhook-deliveries/{id}", requireAdmin(s.handleGetWebhookDelivery))
+	s.mux.HandleFunc("POST /api/v1/admin/webhook-deliveries/{id}/redeliver", requireAdmin(s.handleRedeliverWebhook))
+}
+
+// Start runs the HTTP server in the background
+func (s *Server) Start() error {
+	ctx, stop := context.WithCancel(context.Background())
+	s.stop = stop
+
+	// Keep the local cache and the known items consistent with items upserted by the sync
+	// pipeline on any node
+	if s.localCache != nil || bloom.KnownItems().Enabled() {
+		go redis.SubscribeInvalidations(ctx, s.invalidate)
+	}
+
+	go func() {
+		log.Printf("API server listening on %s", s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// invalidate drops a stale key from the local cache and records the item it names as known
+func (s *Server) invalidate(key string) {
+	if s.localCache != nil {
+		s.localCache.Delete(key)
+	}
+	if _, id, ok := redis.ParseItemKey(key); ok {
+		bloom.KnownItems().Add(id)
+	}
+}
+
+// Shutdown gracefully stops the HTTP server
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stop != nil {
+		s.stop()
+	}
+	close(s.closing)
+	if s.localCache != nil {
+		defer s.localCache.Close()
+	}
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown API server: %w", err)
+	}
+	log.Println("API server stopped")
+
+	if s.publisher != nil {
+		if err := s.publisher.Close(); err != nil {
+			log.Printf("Failed to close event publisher: %v", err)
+		}
+	}
+	return nil
+}
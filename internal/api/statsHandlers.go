@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+const (
+	// defaultHeatmapCacheTTL is how long computed author heatmaps stay in Redis
+	defaultHeatmapCacheTTL = time.Hour
+
+	// defaultDailyStatsDays is the window returned by the daily stats endpoint when from is omitted
+	defaultDailyStatsDays = 30
+
+	// maxDailyStatsDays caps the window of a single daily stats request
+	maxDailyStatsDays = 366
+)
+
+// handleUserHeatmap returns the author's activity by weekday and hour, cached in Redis
+func (s *Server) handleUserHeatmap(w http.ResponseWriter, r *http.Request) {
+	username := r.PathValue("username")
+	if username == "" {
+		writeError(w, http.StatusBadRequest, "username is required")
+		return
+	}
+
+	ctx := r.Context()
+	tenant := tenantFromContext(ctx)
+	cacheKey := "heatmap:" + tenant + ":" + username
+
+	var heatmap models.ActivityHeatmap
+	found, err := redis.GetCachedJSON(ctx, cacheKey, &heatmap)
+	if err != nil {
+		tracing.Logf(r.Context(), "Error reading heatmap cache for %s: %v", username, err)
+	}
+	if found {
+		writeJSON(w, http.StatusOK, heatmap)
+		return
+	}
+
+	result, err := postgres.NewStatsRepository().GetAuthorHeatmap(ctx, tenant, username)
+	if err != nil {
+		writeStoreError(w, r, err, "heatmap")
+		return
+	}
+
+	ttl := config.GetEnvDuration("HEATMAP_CACHE_TTL", defaultHeatmapCacheTTL)
+	if err := redis.CacheJSON(ctx, cacheKey, result, ttl); err != nil {
+		tracing.Logf(r.Context(), "Error caching heatmap for %s: %v", username, err)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleDailyStats returns the precomputed daily aggregates of the tenant between the
+// from and to dates (YYYY-MM-DD, inclusive); to defaults to today and from to 30 days before it
+func (s *Server) handleDailyStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid to: %q", v))
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultDailyStatsDays - 1))
+	if v := q.Get("from"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from: %q", v))
+			return
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+	if to.Sub(from) >= maxDailyStatsDays*24*time.Hour {
+		writeErrorDetails(w, http.StatusBadRequest, fmt.Sprintf("range must not exceed %d days", maxDailyStatsDays),
+			map[string]int{"max_days": maxDailyStatsDays})
+		return
+	}
+
+	ctx := r.Context()
+	days, err := postgres.NewStatsRepository().GetDailyStats(ctx, tenantFromContext(ctx), from, to)
+	if err != nil {
+		writeStoreError(w, r, err, "daily stats")
+		return
+	}
+	if days == nil {
+		days = []*models.DailyStats{}
+	}
+	writeJSON(w, http.StatusOK, days)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"internship-project/internal/repository/postgres"
+)
+
+// defaultTagCount is the number of tags listed when no limit is given
+const defaultTagCount = 100
+
+// handleListTags returns the most used tags with the number of items carrying them
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTagCount
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	tags, err := postgres.NewTagRepository().GetTagCounts(r.Context(), tenantFromContext(r.Context()), limit)
+	if err != nil {
+		writeStoreError(w, r, err, "tags")
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// handleTagItems returns the stories, asks, jobs and polls carrying a tag, newest first
+func (s *Server) handleTagItems(w http.ResponseWriter, r *http.Request) {
+	cursor, limit, err := parseTimelinePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tag := strings.ToLower(r.PathValue("tag"))
+	items, err := postgres.NewTimelineRepository().GetByTag(r.Context(), tenantFromContext(r.Context()), tag, cursor, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "tagged items")
+		return
+	}
+	writeTimelinePage(w, items, limit)
+}
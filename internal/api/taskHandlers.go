@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tasks"
+	"internship-project/pkg/database"
+)
+
+// refetchTask is the payload of a "refetch" task
+type refetchTask struct {
+	IDs []int `json:"ids"`
+}
+
+// RegisterTasks registers the "refetch" task type with the worker when refetches are enabled, and
+// lets admins queue and inspect tasks; call it before Start
+func (s *Server) RegisterTasks(worker *tasks.Worker) {
+	s.tasks = worker
+	if s.hnClient == nil {
+		return
+	}
+	worker.Register("refetch", func(ctx context.Context, payload json.RawMessage) error {
+		var task refetchTask
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return fmt.Errorf("invalid refetch payload: %w", err)
+		}
+		result := s.refetch(ctx, task.IDs, config.GetEnvInt("ITEM_REFETCH_CONCURRENCY", 8))
+		if len(result.Failed) > 0 {
+			failed := make([]string, 0, len(result.Failed))
+			for id, message := range result.Failed {
+				failed = append(failed, fmt.Sprintf("%d: %s", id, message))
+			}
+			slices.Sort(failed)
+			return fmt.Errorf("%d of %d items failed: %s", len(result.Failed), result.Requested, strings.Join(failed, "; "))
+		}
+		return nil
+	})
+}
+
+// handleEnqueueTask queues a task from a body like {"type": "reindex-item", "payload": {"ids": [1]},
+// "delay_seconds": 60}
+func (s *Server) handleEnqueueTask(w http.ResponseWriter, r *http.Request) {
+	if s.tasks == nil {
+		writeError(w, http.StatusServiceUnavailable, "the task queue is not enabled")
+		return
+	}
+	var body struct {
+		Type          string          `json:"type"`
+		Payload       json.RawMessage `json:"payload"`
+		Delay_Seconds int             `json:"delay_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if body.Delay_Seconds < 0 {
+		writeError(w, http.StatusBadRequest, "delay_seconds must not be negative")
+		return
+	}
+	if len(body.Payload) == 0 {
+		body.Payload = json.RawMessage("{}")
+	}
+	task, err := s.tasks.Enqueue(r.Context(), body.Type, body.Payload, time.Duration(body.Delay_Seconds)*time.Second)
+	if errors.Is(err, tasks.ErrUnknownType) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeStoreError(w, r, err, "task")
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// handleListTasks returns the most recent tasks, optionally with one status
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status != "" && !slices.Contains([]string{models.TaskPending, models.TaskRunning, models.TaskDone, models.TaskFailed}, status) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %q", status))
+		return
+	}
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	list, err := postgres.NewTaskRepository().List(r.Context(), status, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "tasks")
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleGetTask returns a task with its status, attempts and last error
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	id, err := database.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	task, err := postgres.NewTaskRepository().GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, "task")
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
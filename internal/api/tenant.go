@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// apiKeyHeader carries the tenant API key on every request
+const apiKeyHeader = "X-API-Key"
+
+type tenantContextKey struct{}
+
+// tenantRecordContextKey holds the tenant of the API key, with its quotas
+type tenantRecordContextKey struct{}
+
+// withTenant resolves the tenant from the API key, enforces its request quota
+// and stores its name in the request context. Requests without a key use the
+// default tenant unless TENANT_AUTH_REQUIRED is set.
+func (s *Server) withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/health" || r.URL.Path == "/api/v1/openapi.yaml" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		apiKey := r.Header.Get(apiKeyHeader)
+		if apiKey == "" {
+			if config.GetEnvBool("TENANT_AUTH_REQUIRED", false) {
+				writeError(w, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, models.DefaultTenant)))
+			return
+		}
+
+		tenant, err := postgres.NewTenantRepository().GetByAPIKey(r.Context(), apiKey)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			tracing.Logf(r.Context(), "Error resolving tenant: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to resolve tenant")
+			return
+		}
+
+		if tenant.Requests_Per_Minute > 0 {
+			window := time.Now().Unix() / 60
+			key := fmt.Sprintf("quota:%s:%d", tenant.Name, window)
+			count, err := redis.IncrementCounter(r.Context(), key, time.Minute)
+			if err != nil {
+				// Quotas are best effort; a Redis outage must not take the API down
+				tracing.Logf(r.Context(), "Error checking quota for tenant %s: %v", tenant.Name, err)
+			} else if count > int64(tenant.Requests_Per_Minute) {
+				retryAfter := 60 - time.Now().Unix()%60
+				w.Header().Set("Retry-After", fmt.Sprint(retryAfter))
+				writeErrorDetails(w, http.StatusTooManyRequests, "request quota exceeded",
+					map[string]int64{"retry_after_seconds": retryAfter, "requests_per_minute": int64(tenant.Requests_Per_Minute)})
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), tenantContextKey{}, tenant.Name)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, tenantRecordContextKey{}, tenant)))
+	})
+}
+
+// tenantRecordFromContext returns the tenant of the API key resolved by withTenant, nil for
+// requests without a key
+func tenantRecordFromContext(ctx context.Context) *models.Tenant {
+	tenant, _ := ctx.Value(tenantRecordContextKey{}).(*models.Tenant)
+	return tenant
+}
+
+// tenantFromContext returns the tenant resolved by withTenant
+func tenantFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(tenantContextKey{}).(string); ok {
+		return name
+	}
+	return models.DefaultTenant
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// timelineResponse is a page of the unified timeline
+type timelineResponse struct {
+	Items      []*models.TimelineItem `json:"items"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// handleTimeline returns stories, asks, jobs and polls merged newest first
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	cursor, limit, err := parseTimelinePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := postgres.NewTimelineRepository().GetTimeline(r.Context(), tenantFromContext(r.Context()), cursor, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "timeline")
+		return
+	}
+	writeTimelinePage(w, items, limit)
+}
+
+// handleNewAuthors returns the stories and comments posted from young accounts or as their
+// author's first submission, newest first
+func (s *Server) handleNewAuthors(w http.ResponseWriter, r *http.Request) {
+	cursor, limit, err := parseTimelinePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := postgres.NewTimelineRepository().GetNewAuthors(r.Context(), tenantFromContext(r.Context()), cursor, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "new author items")
+		return
+	}
+	writeTimelinePage(w, items, limit)
+}
+
+// parseTimelinePage reads the cursor and limit parameters of a timeline-shaped endpoint
+func parseTimelinePage(r *http.Request) (models.TimelineCursor, int, error) {
+	q := r.URL.Query()
+
+	cursor, err := decodeTimelineCursor(q.Get("cursor"))
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return cursor, 0, fmt.Errorf("invalid limit: %q", v)
+		}
+		if limit > maxPageSize {
+			limit = maxPageSize
+		}
+	}
+	return cursor, limit, nil
+}
+
+// writeTimelinePage writes a page of items with the cursor of the next one when the page is full
+func writeTimelinePage(w http.ResponseWriter, items []*models.TimelineItem, limit int) {
+	resp := timelineResponse{Items: items}
+	if resp.Items == nil {
+		resp.Items = []*models.TimelineItem{}
+	}
+	if len(items) == limit {
+		last := items[len(items)-1]
+		resp.NextCursor = encodeTimelineCursor(models.TimelineCursor{Created_At: last.Created_At, ID: last.ID})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// encodeTimelineCursor serializes a cursor into an opaque URL-safe token
+func encodeTimelineCursor(c models.TimelineCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Created_At, c.ID)))
+}
+
+// decodeTimelineCursor parses a token produced by encodeTimelineCursor
+func decodeTimelineCursor(token string) (models.TimelineCursor, error) {
+	var c models.TimelineCursor
+	if token == "" {
+		return c, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	if c.Created_At, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	if c.ID, err = strconv.Atoi(parts[1]); err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+	return c, nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/pkg/database"
+)
+
+// handleWatch adds a watch on a stored item for the API key from a body like
+// {"item_id": 1, "webhook_url": "https://...", "score_delta": 50, "comments_delta": 20, "rank_delta": 5}
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Item_ID        int    `json:"item_id"`
+		Webhook_URL    string `json:"webhook_url"`
+		Score_Delta    int    `json:"score_delta"`
+		Comments_Delta int    `json:"comments_delta"`
+		Rank_Delta     int    `json:"rank_delta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	watch := models.Watch{
+		Item_ID:        body.Item_ID,
+		Webhook_URL:    body.Webhook_URL,
+		Score_Delta:    body.Score_Delta,
+		Comments_Delta: body.Comments_Delta,
+		Rank_Delta:     body.Rank_Delta,
+	}
+	if watch.Item_ID <= 0 {
+		writeError(w, http.StatusBadRequest, "item_id is required")
+		return
+	}
+	if u, err := url.Parse(watch.Webhook_URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeError(w, http.StatusBadRequest, "webhook_url must be an absolute http(s) URL")
+		return
+	}
+	if watch.Score_Delta < 0 || watch.Comments_Delta < 0 || watch.Rank_Delta < 0 {
+		writeError(w, http.StatusBadRequest, "deltas must not be negative")
+		return
+	}
+	if watch.Score_Delta == 0 && watch.Comments_Delta == 0 && watch.Rank_Delta == 0 {
+		writeError(w, http.StatusBadRequest, "at least one of score_delta, comments_delta and rank_delta is required")
+		return
+	}
+
+	quotas, err := tenantQuotas(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "quotas")
+		return
+	}
+	if q := findQuota(quotas, models.QuotaWatches); q.Exceeded() {
+		writeQuotaExceeded(w, q)
+		return
+	}
+	// At the endpoint quota, new watches may still call the webhooks already in use
+	if q := findQuota(quotas, models.QuotaWebhookEndpoints); q.Exceeded() {
+		watches, err := postgres.NewWatchRepository().GetByTenant(r.Context(), tenantFromContext(r.Context()))
+		if err != nil {
+			writeStoreError(w, r, err, "watches")
+			return
+		}
+		if !slices.ContainsFunc(watches, func(other *models.Watch) bool { return other.Webhook_URL == watch.Webhook_URL }) {
+			writeQuotaExceeded(w, q)
+			return
+		}
+	}
+
+	if err := postgres.NewWatchRepository().Create(r.Context(), tenantFromContext(r.Context()), &watch); err != nil {
+		writeStoreError(w, r, err, "item")
+		return
+	}
+	writeJSON(w, http.StatusCreated, watch)
+}
+
+// handleListWatches returns the watches of the API key
+func (s *Server) handleListWatches(w http.ResponseWriter, r *http.Request) {
+	watches, err := postgres.NewWatchRepository().GetByTenant(r.Context(), tenantFromContext(r.Context()))
+	if err != nil {
+		writeStoreError(w, r, err, "watches")
+		return
+	}
+	writeJSON(w, http.StatusOK, watches)
+}
+
+// handleDeleteWatch removes one of the watches of the API key
+func (s *Server) handleDeleteWatch(w http.ResponseWriter, r *http.Request) {
+	id, err := database.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	if err := postgres.NewWatchRepository().Delete(r.Context(), tenantFromContext(r.Context()), id); err != nil {
+		writeStoreError(w, r, err, "watch")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
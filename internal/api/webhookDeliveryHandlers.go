@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/watch"
+	"internship-project/pkg/database"
+)
+
+// handleListWebhookDeliveries returns the most recent webhook deliveries, optionally with one status
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status != "" && !slices.Contains([]string{models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed}, status) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status: %q", status))
+		return
+	}
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit: %q", v))
+			return
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	list, err := postgres.NewWebhookDeliveryRepository().List(r.Context(), status, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "webhook deliveries")
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleGetWebhookDelivery returns a webhook delivery with its payload and last attempt
+func (s *Server) handleGetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := database.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	delivery, err := postgres.NewWebhookDeliveryRepository().GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, r, err, "webhook delivery")
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// handleRedeliverWebhook calls the webhook of a delivery again with the same body and idempotency
+// key. The updated delivery is returned whether or not the webhook accepted it.
+func (s *Server) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := database.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id: "+r.PathValue("id"))
+		return
+	}
+	notifier := watch.NewNotifier(postgres.NewWatchRepository(), postgres.NewWebhookDeliveryRepository())
+	delivery, err := notifier.Redeliver(r.Context(), id)
+	if delivery == nil {
+		writeStoreError(w, r, err, "webhook delivery")
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
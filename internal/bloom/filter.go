@@ -0,0 +1,81 @@
+// Package bloom keeps in-process Bloom filters of item IDs, consulted before the Redis and
+// Postgres existence checks: an ID the filter has never seen is certainly not stored, which
+// saves a round trip for most of the new IDs met by the sync and the backfills.
+package bloom
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Filter is a Bloom filter of integer IDs, safe for concurrent use. It never forgets an ID;
+// its false positive rate grows once it holds more than the capacity it was sized for.
+type Filter struct {
+	words  []atomic.Uint64
+	bits   uint64
+	hashes int
+	added  atomic.Int64
+}
+
+// New sizes a filter for capacity IDs at the given false positive rate (0 < rate < 1)
+func New(capacity int, falsePositiveRate float64) *Filter {
+	capacity = max(capacity, 1)
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+	bits := uint64(math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	bits = max((bits+63)/64*64, 64)
+	hashes := max(int(math.Round(float64(bits)/float64(capacity)*math.Ln2)), 1)
+	return &Filter{words: make([]atomic.Uint64, bits/64), bits: bits, hashes: hashes}
+}
+
+// mix is the splitmix64 finalizer, spreading consecutive IDs over the whole filter
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// positions calls fn with the bit of each hash of id, by double hashing
+func (f *Filter) positions(id int, fn func(word int, mask uint64) bool) {
+	h1 := mix(uint64(id))
+	h2 := mix(h1) | 1
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.bits
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return
+		}
+	}
+}
+
+// Add records the IDs
+func (f *Filter) Add(ids ...int) {
+	for _, id := range ids {
+		f.positions(id, func(word int, mask uint64) bool {
+			f.words[word].Or(mask)
+			return true
+		})
+	}
+	f.added.Add(int64(len(ids)))
+}
+
+// MayContain reports whether id may have been added; false is always right
+func (f *Filter) MayContain(id int) bool {
+	found := true
+	f.positions(id, func(word int, mask uint64) bool {
+		found = f.words[word].Load()&mask != 0
+		return found
+	})
+	return found
+}
+
+// Added returns how many IDs were added, counting the repeated ones each time
+func (f *Filter) Added() int64 {
+	return f.added.Load()
+}
+
+// Bytes returns the memory held by the bits of the filter
+func (f *Filter) Bytes() int {
+	return len(f.words) * 8
+}
@@ -0,0 +1,108 @@
+package bloom
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"internship-project/internal/config"
+)
+
+// ScanFunc returns up to limit stored item IDs greater than afterID, in ascending order
+type ScanFunc func(ctx context.Context, afterID, limit int) ([]int, error)
+
+// Known is a filter of the IDs of the items stored in Postgres, built by Rebuild and kept up to
+// date by Add on every insert. Until it is built, or with KNOWN_ITEMS_ENABLED off, it may contain
+// every ID, so callers fall back to their usual lookups.
+type Known struct {
+	mu       sync.RWMutex
+	filter   *Filter
+	building *Filter // the filter Rebuild is filling, receiving the IDs added meanwhile
+}
+
+var knownItems = NewKnown()
+
+// NewKnown creates an empty, not yet built, filter of known items
+func NewKnown() *Known {
+	return &Known{}
+}
+
+// KnownItems returns the filter of the items stored by this process
+func KnownItems() *Known {
+	return knownItems
+}
+
+// Enabled reports whether the filter is consulted, per KNOWN_ITEMS_ENABLED
+func (k *Known) Enabled() bool {
+	return config.GetEnvBool("KNOWN_ITEMS_ENABLED", true)
+}
+
+// newKnownFilter sizes a filter for KNOWN_ITEMS_CAPACITY IDs at KNOWN_ITEMS_FP_RATE
+func newKnownFilter() *Filter {
+	return New(config.GetEnvInt("KNOWN_ITEMS_CAPACITY", 10_000_000), config.GetEnvFloat("KNOWN_ITEMS_FP_RATE", 0.01))
+}
+
+// MayContain reports whether id may be a stored item; false means it certainly is not
+func (k *Known) MayContain(id int) bool {
+	if !k.Enabled() {
+		return true
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.filter == nil || k.filter.MayContain(id)
+}
+
+// Ready reports whether the filter has been built
+func (k *Known) Ready() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.filter != nil
+}
+
+// Add records the IDs of stored items
+func (k *Known) Add(ids ...int) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.filter != nil {
+		k.filter.Add(ids...)
+	}
+	if k.building != nil {
+		k.building.Add(ids...)
+	}
+}
+
+// Rebuild fills a new filter with every ID returned by scan, KNOWN_ITEMS_SCAN_BATCH at a time,
+// then swaps it in and returns it. The IDs added meanwhile are kept; on error the current filter
+// stays in use.
+func (k *Known) Rebuild(ctx context.Context, scan ScanFunc) (*Filter, error) {
+	filter := newKnownFilter()
+	k.mu.Lock()
+	k.building = filter
+	k.mu.Unlock()
+	defer func() {
+		k.mu.Lock()
+		if k.building == filter {
+			k.building = nil
+		}
+		k.mu.Unlock()
+	}()
+
+	batch := max(config.GetEnvInt("KNOWN_ITEMS_SCAN_BATCH", 10000), 1)
+	afterID := math.MinInt // Lobsters and feed items have negative IDs
+	for {
+		ids, err := scan(ctx, afterID, batch)
+		if err != nil {
+			return nil, err
+		}
+		filter.Add(ids...)
+		if len(ids) < batch {
+			break
+		}
+		afterID = ids[len(ids)-1]
+	}
+
+	k.mu.Lock()
+	k.filter = filter
+	k.mu.Unlock()
+	return filter, nil
+}
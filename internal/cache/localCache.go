@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+
+	"internship-project/internal/config"
+)
+
+// LocalCache is a size-bounded in-process cache of JSON-encoded values.
+// Entries are dropped on invalidation events and expire after a short TTL as a safety net.
+type LocalCache struct {
+	cache *ristretto.Cache[string, []byte]
+	ttl   time.Duration
+}
+
+// NewLocalCache creates a cache holding up to LOCAL_CACHE_MAX_BYTES of values for LOCAL_CACHE_TTL
+func NewLocalCache() (*LocalCache, error) {
+	maxBytes := int64(config.GetEnvInt("LOCAL_CACHE_MAX_BYTES", 64<<20))
+	c, err := ristretto.NewCache(&ristretto.Config[string, []byte]{
+		NumCounters: 100000, // ~10x the expected number of entries
+		MaxCost:     maxBytes,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local cache: %w", err)
+	}
+	return &LocalCache{
+		cache: c,
+		ttl:   config.GetEnvDuration("LOCAL_CACHE_TTL", time.Minute),
+	}, nil
+}
+
+// Get returns the value stored at key
+func (c *LocalCache) Get(key string) ([]byte, bool) {
+	return c.cache.Get(key)
+}
+
+// Set stores value at key, weighted by its size
+func (c *LocalCache) Set(key string, value []byte) {
+	c.cache.SetWithTTL(key, value, int64(len(value)), c.ttl)
+}
+
+// Delete drops the value stored at key
+func (c *LocalCache) Delete(key string) {
+	c.cache.Del(key)
+}
+
+// Close stops the cache background goroutines
+func (c *LocalCache) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,161 @@
+// Package capacity reports the storage taken by the database and its tables and projects when
+// the database will cross the disk thresholds. Growth is measured from the daily snapshots of
+// the table sizes recorded by the storage job. Tables pruned after a retention period stop
+// growing once they hold a full period of rows, so their growth is only projected up to that
+// size rather than forever.
+package capacity
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+)
+
+// projectionDays is how far ahead the thresholds are looked for
+const projectionDays = 3650
+
+// DefaultTables are the tables measured when STORAGE_TABLES is not set: the item tables and the
+// logs growing with them
+var DefaultTables = []string{
+	"stories", "comments", "comments_cold", "asks", "jobs", "polls", "poll_options", "users",
+	"item_change_log", "item_tombstones", "item_payloads", "item_dead_letters", "webhook_deliveries",
+}
+
+// itemTables are the tables expired items are removed from
+var itemTables = []string{"stories", "comments", "comments_cold", "asks", "jobs", "polls", "poll_options"}
+
+// Day returns the UTC midnight of the day of t, in unix seconds
+func Day(t time.Time) int64 {
+	return t.UTC().Truncate(24 * time.Hour).Unix()
+}
+
+// Measure returns the current sizes of the database and the STORAGE_TABLES that exist, stamped
+// with the day of now; the database comes first
+func Measure(ctx context.Context, tables repository.MaintenanceRepository, storage repository.StorageRepository, now time.Time) ([]*models.StorageSnapshot, error) {
+	size, err := storage.GetDatabaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := tables.GetTableStats(ctx, config.GetEnvList("STORAGE_TABLES", DefaultTables))
+	if err != nil {
+		return nil, err
+	}
+	day := Day(now)
+	snapshots := []*models.StorageSnapshot{{Day: day, TableBytes: size}}
+	for _, t := range stats {
+		snapshots = append(snapshots, &models.StorageSnapshot{
+			Table: t.Table, Day: day, Rows: t.LiveRows, TableBytes: t.TableBytes, IndexBytes: t.IndexBytes,
+		})
+	}
+	return snapshots, nil
+}
+
+// Retentions returns the retention periods of the tables pruned by age: the change log after
+// CHANGES_RETENTION, the tombstones after TOMBSTONES_RETENTION and, when ITEM_RETENTION_ENABLED
+// is set and every tenant expires its items, the item tables after the longest tenant retention
+func Retentions(tenants []*models.Tenant) map[string]time.Duration {
+	retentions := map[string]time.Duration{
+		"item_change_log": config.GetEnvDuration("CHANGES_RETENTION", 7*24*time.Hour),
+		"item_tombstones": config.GetEnvDuration("TOMBSTONES_RETENTION", 30*24*time.Hour),
+	}
+	if !config.GetEnvBool("ITEM_RETENTION_ENABLED", false) || len(tenants) == 0 {
+		return retentions
+	}
+	longest := 0
+	for _, tenant := range tenants {
+		if tenant.Retention_Days <= 0 {
+			return retentions
+		}
+		longest = max(longest, tenant.Retention_Days)
+	}
+	for _, table := range itemTables {
+		retentions[table] = time.Duration(longest) * 24 * time.Hour
+	}
+	return retentions
+}
+
+// Thresholds returns the STORAGE_THRESHOLDS, the shares of the disk to project, skipping the ones
+// not between 0 and 1
+func Thresholds() []float64 {
+	var thresholds []float64
+	for _, value := range config.GetEnvList("STORAGE_THRESHOLDS", []string{"0.8", "0.9"}) {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 && f <= 1 {
+			thresholds = append(thresholds, f)
+		}
+	}
+	return thresholds
+}
+
+// Build reports the current sizes, as returned by Measure, with their growth since the oldest
+// snapshot of history before the current day, and projects the thresholds of a disk of
+// diskBytes (none when 0). The database grows at its measured rate, except that the growth of a
+// table with a retention is capped at the size of a full retention period of its current growth.
+func Build(current, history []*models.StorageSnapshot, retentions map[string]time.Duration,
+	diskBytes int64, thresholds []float64) *models.StorageReport {
+	oldest := make(map[string]*models.StorageSnapshot)
+	for _, s := range history {
+		if o, ok := oldest[s.Table]; !ok || s.Day < o.Day {
+			oldest[s.Table] = s
+		}
+	}
+
+	report := &models.StorageReport{DiskBytes: diskBytes, Tables: []*models.TableUsage{}, Thresholds: []*models.StorageThreshold{}}
+	databaseMeasured := false
+	var tableGrowth, boundedGrowth float64
+	type bounded struct{ rate, remaining float64 }
+	var capped []bounded
+	for _, s := range current {
+		usage := &models.TableUsage{Table: s.Table, Rows: s.Rows, TableBytes: s.TableBytes, IndexBytes: s.IndexBytes}
+		if o, ok := oldest[s.Table]; ok && o.Day < s.Day {
+			usage.MeasuredDays = float64(s.Day-o.Day) / (24 * 60 * 60)
+			usage.GrowthRowsPerDay = float64(s.Rows-o.Rows) / usage.MeasuredDays
+			usage.GrowthBytesPerDay = float64(s.Bytes()-o.Bytes()) / usage.MeasuredDays
+		}
+		if s.Table == "" {
+			report.DatabaseBytes = s.TableBytes
+			report.GrowthBytesPerDay = usage.GrowthBytesPerDay
+			databaseMeasured = usage.MeasuredDays > 0
+			continue
+		}
+		if retention := retentions[s.Table]; retention > 0 {
+			usage.RetentionDays = retention.Hours() / 24
+			boundedGrowth += usage.GrowthBytesPerDay
+			rate := max(usage.GrowthBytesPerDay, 0)
+			capped = append(capped, bounded{rate, max(rate*usage.RetentionDays-float64(s.Bytes()), 0)})
+		} else {
+			tableGrowth += usage.GrowthBytesPerDay
+		}
+		report.Tables = append(report.Tables, usage)
+	}
+	if !databaseMeasured {
+		report.GrowthBytesPerDay = tableGrowth + boundedGrowth
+	}
+	// The growth left once the tables with a retention are taken out, which keeps growing
+	unbounded := report.GrowthBytesPerDay - boundedGrowth
+	projected := func(days int) float64 {
+		size := float64(report.DatabaseBytes) + unbounded*float64(days)
+		for _, b := range capped {
+			size += min(b.rate*float64(days), b.remaining)
+		}
+		return size
+	}
+
+	if diskBytes <= 0 {
+		return report
+	}
+	for _, fraction := range thresholds {
+		threshold := &models.StorageThreshold{Fraction: fraction, Bytes: int64(fraction * float64(diskBytes))}
+		for days := 0; days <= projectionDays; days++ {
+			if projected(days) >= float64(threshold.Bytes) {
+				threshold.DaysUntil = &days
+				break
+			}
+		}
+		report.Thresholds = append(report.Thresholds, threshold)
+	}
+	return report
+}
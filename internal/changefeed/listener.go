@@ -0,0 +1,201 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/transport"
+)
+
+// Channel is the notification channel the item table triggers write to
+const Channel = "item_changes"
+
+// pollOverlap is how far the cursors are rewound when switching to polling, to catch rows
+// committed after a later notification by transactions that started earlier
+const pollOverlap = 5 * time.Second
+
+var (
+	// notifiedChanges counts the item changes received as notifications
+	notifiedChanges = expvar.NewInt("change_listener_notified")
+
+	// polledChanges counts the item changes found by the polling fallback
+	polledChanges = expvar.NewInt("change_listener_polled")
+)
+
+// Listener pushes the IDs of changed items into the indexing pipeline as Postgres notifies
+// them, and polls the item tables for changes while the notification connection is down
+type Listener struct {
+	connectionString string
+	changes          repository.ChangeRepository
+	publisher        transport.Publisher
+
+	pollInterval time.Duration
+	batchSize    int
+	minReconnect time.Duration
+	maxReconnect time.Duration
+
+	// cursors holds the last change delivered per kind
+	cursors map[string]models.ItemChange
+}
+
+// NewListener creates a listener configured by CHANGE_LISTENER_POLL_INTERVAL,
+// CHANGE_LISTENER_BATCH_SIZE, CHANGE_LISTENER_MIN_RECONNECT and CHANGE_LISTENER_MAX_RECONNECT
+func NewListener(connectionString string, changes repository.ChangeRepository, publisher transport.Publisher) *Listener {
+	return &Listener{
+		connectionString: connectionString,
+		changes:          changes,
+		publisher:        publisher,
+		pollInterval:     config.GetEnvDuration("CHANGE_LISTENER_POLL_INTERVAL", 30*time.Second),
+		batchSize:        config.GetEnvInt("CHANGE_LISTENER_BATCH_SIZE", 500),
+		minReconnect:     config.GetEnvDuration("CHANGE_LISTENER_MIN_RECONNECT", time.Second),
+		maxReconnect:     config.GetEnvDuration("CHANGE_LISTENER_MAX_RECONNECT", time.Minute),
+	}
+}
+
+// Run listens until ctx is done. Changes made before Run are not delivered.
+func (l *Listener) Run(ctx context.Context) {
+	start := models.ItemChange{Updated_At: time.Now().UnixMilli()}
+	l.cursors = make(map[string]models.ItemChange, len(transport.ItemTopics))
+	for kind := range transport.ItemTopics {
+		l.cursors[kind] = start
+	}
+
+	events := make(chan pq.ListenerEventType, 8)
+	listener := pq.NewListener(l.connectionString, l.minReconnect, l.maxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("Change listener connection event %d: %v", event, err)
+			}
+			select {
+			case events <- event:
+			default:
+			}
+		})
+	defer func() {
+		listener.Close()
+		// the listener goroutine may still be handing over a notification; let it finish
+		go func() {
+			for range listener.Notify {
+			}
+		}()
+	}()
+
+	// Listen blocks until the first connection succeeds and fails once the listener is closed
+	go func() {
+		if err := listener.Listen(Channel); err != nil && ctx.Err() == nil {
+			log.Printf("Change listener stopped listening: %v", err)
+		}
+	}()
+
+	ticker := time.NewTicker(l.pollInterval)
+	defer ticker.Stop()
+	polling := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event := <-events:
+			switch event {
+			case pq.ListenerEventConnected, pq.ListenerEventReconnected:
+				if polling {
+					log.Println("Change listener connected; switching to notifications")
+				}
+				polling = false
+				// catch up on what changed while connecting
+				l.rewind()
+				l.poll(ctx)
+			case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+				if !polling {
+					log.Println("Change listener disconnected; falling back to polling")
+					l.rewind()
+				}
+				polling = true
+			}
+
+		case n := <-listener.Notify:
+			if n != nil {
+				l.handleNotification(ctx, n.Extra)
+			}
+
+		case <-ticker.C:
+			if polling {
+				l.poll(ctx)
+			}
+		}
+	}
+}
+
+// handleNotification publishes the item of a notification payload
+func (l *Listener) handleNotification(ctx context.Context, payload string) {
+	var change models.ItemChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		log.Printf("Change listener ignored malformed notification %q: %v", payload, err)
+		return
+	}
+	if _, ok := l.cursors[change.Kind]; !ok {
+		return
+	}
+	if err := l.publish(ctx, change.Kind, []models.ItemChange{change}); err != nil {
+		log.Printf("Change listener failed to publish %s %d: %v", change.Kind, change.ID, err)
+		return
+	}
+	notifiedChanges.Add(1)
+}
+
+// poll publishes every change recorded after the cursors, in batches
+func (l *Listener) poll(ctx context.Context) {
+	for kind := range l.cursors {
+		for ctx.Err() == nil {
+			changes, err := l.changes.GetUpdatedSince(ctx, kind, l.cursors[kind], l.batchSize)
+			if err != nil {
+				log.Printf("Change listener failed to poll %s changes: %v", kind, err)
+				break
+			}
+			if len(changes) == 0 {
+				break
+			}
+			if err := l.publish(ctx, kind, changes); err != nil {
+				log.Printf("Change listener failed to publish %d %s changes: %v", len(changes), kind, err)
+				break
+			}
+			polledChanges.Add(int64(len(changes)))
+			if len(changes) < l.batchSize {
+				break
+			}
+		}
+	}
+}
+
+// publish sends the changed IDs on the topic of their kind and advances its cursor
+func (l *Listener) publish(ctx context.Context, kind string, changes []models.ItemChange) error {
+	values := make([][]byte, len(changes))
+	for i, change := range changes {
+		values[i] = strconv.AppendInt(nil, int64(change.ID), 10)
+	}
+	if err := l.publisher.Publish(ctx, transport.ItemTopics[kind], values...); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.After(l.cursors[kind]) {
+			l.cursors[kind] = models.ItemChange{Updated_At: change.Updated_At, ID: change.ID}
+		}
+	}
+	return nil
+}
+
+// rewind moves every cursor back by pollOverlap; changes seen twice are simply reindexed
+func (l *Listener) rewind() {
+	for kind, cursor := range l.cursors {
+		l.cursors[kind] = models.ItemChange{Updated_At: cursor.Updated_At - pollOverlap.Milliseconds()}
+	}
+}
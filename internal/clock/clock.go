@@ -0,0 +1,48 @@
+// Package clock is the time source of the sync jobs: the wall clock in production and, in tests,
+// a fake clock that only moves when advanced, so schedules, retention cutoffs and sync stamps can
+// be exercised without waiting. The scheduler runs on the same clock as the jobs it starts.
+package clock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// Clock tells the time and runs the timers and tickers of the scheduler
+type Clock = clockwork.Clock
+
+// Fake is a Clock set by hand; timers waiting on it fire once it is advanced past them
+type Fake = clockwork.FakeClock
+
+// NewFake returns a fake clock reading t
+func NewFake(t time.Time) *Fake {
+	return clockwork.NewFakeClockAt(t)
+}
+
+var (
+	mu      sync.RWMutex
+	current Clock = clockwork.NewRealClock()
+)
+
+// Default returns the clock given to the services when they are created, the wall clock unless
+// replaced with Set
+func Default() Clock {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Set replaces the default clock and returns a function restoring the previous one
+func Set(c Clock) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := current
+	current = c
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		current = previous
+	}
+}
@@ -2,9 +2,15 @@ package config
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // init loads .env file if it exists
@@ -12,15 +18,74 @@ func init() {
 	loadEnvFile()
 }
 
-// loadEnvFile loads environment variables from .env file
+// loadEnvFile loads environment variables from .env file, overlaid with the file of the
+// CONFIG_PROFILE profile
 func loadEnvFile() {
-	file, err := os.Open(".env")
+	values, err := readConfigFiles()
 	if err != nil {
-		// .env file is optional
+		// .env file is optional; a profile that cannot be read fails the startup validation
+		if !errors.Is(err, fs.ErrNotExist) || Profile() != "" {
+			log.Printf("Config files not loaded: %v", err)
+		}
 		return
 	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	for key, value := range values {
+		// Only set if not already set
+		if _, exists := os.LookupEnv(key); !exists {
+			os.Setenv(key, value)
+			fileValues[key] = value
+		}
+	}
+}
+
+// Profile returns the deployment profile set by CONFIG_PROFILE, e.g. "eu", or "" for none
+func Profile() string {
+	return GetEnv(profileKey, "")
+}
+
+// profileFile returns the file of a profile, whose values override those of envFile
+func profileFile(profile string) string {
+	return envFile + "." + profile
+}
+
+// readConfigFiles reads envFile overlaid with the file of the CONFIG_PROFILE profile, taken from
+// the environment or else from envFile. Either file may be missing, not both.
+func readConfigFiles() (map[string]string, error) {
+	values, err := readEnvFile(envFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	profile, ok := os.LookupEnv(profileKey)
+	if !ok {
+		profile = values[profileKey]
+	}
+	if profile == "" {
+		return values, err
+	}
+
+	overrides, profileErr := readEnvFile(profileFile(profile))
+	if profileErr != nil {
+		return nil, fmt.Errorf("config profile %q: %w", profile, profileErr)
+	}
+	if values == nil {
+		values = make(map[string]string)
+	}
+	maps.Copy(values, overrides)
+	return values, nil
+}
+
+// readEnvFile parses KEY=VALUE lines, skipping blank lines and comments
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
+	values := make(map[string]string)
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -35,12 +100,9 @@ func loadEnvFile() {
 
 		key := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
-
-		// Only set if not already set
-		if _, exists := os.LookupEnv(key); !exists {
-			os.Setenv(key, value)
-		}
+		values[key] = value
 	}
+	return values, scanner.Err()
 }
 
 // GetEnv gets environment variable with fallback
@@ -63,3 +125,61 @@ func GetEnvInt(key string, fallback int) int {
 	}
 	return value
 }
+
+// GetEnvBool gets a boolean environment variable ("true", "1", "false", ...) with fallback
+func GetEnvBool(key string, fallback bool) bool {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvDuration gets a duration environment variable (e.g. "90s", "1h") with fallback
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvList gets a comma-separated environment variable as a list, skipping empty entries
+func GetEnvList(key string, fallback []string) []string {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// GetEnvFloat gets a floating point environment variable with fallback
+func GetEnvFloat(key string, fallback float64) float64 {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
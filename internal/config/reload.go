@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// envFile is the optional file loaded at startup and watched for live changes
+const envFile = ".env"
+
+// profileKey selects the deployment profile, whose file overlays envFile
+const profileKey = "CONFIG_PROFILE"
+
+// immutableKeys are only read at startup (connections, listeners, wiring);
+// changing them in a running process has no effect, so reloads reject them
+var immutableKeys = map[string]bool{
+	"DB_HOST": true, "DB_PORT": true, "DB_USER": true, "DB_PASSWORD": true, "DB_NAME": true, "DB_SSLMODE": true, "DB_SCHEMA": true,
+	"KAFKA_BOOTSTRAP_SERVERS": true, "KAFKA_CLIENT_ID": true, "KAFKA_ACKS": true, "KAFKA_TOPICS": true,
+	"REDIS_ADDR": true, "REDIS_PASSWORD": true, "REDIS_DB": true,
+	"NATS_URL": true, "EVENT_TRANSPORT": true, "API_ADDR": true,
+	"LOCAL_CACHE_ENABLED": true, "LOCAL_CACHE_MAX_BYTES": true, "CACHE_INVALIDATION_CHANNEL": true,
+	"ITEM_FETCH_FALLBACK": true, "ITEM_REFETCH_ENABLED": true, "ETL_PLUGINS": true,
+	"LOBSTERS_ENABLED": true, "RSS_FEEDS": true,
+	"HN_API_BASE_URL": true, "HN_API_FIXTURES_MODE": true, "HN_API_FIXTURES_DIR": true,
+	profileKey: true, "SINKS": true,
+}
+
+var (
+	fileMu     sync.Mutex
+	fileValues = map[string]string{} // values applied from envFile; variables set by the real environment are never overridden
+	listeners  []func(changed []string)
+)
+
+// OnChange registers fn to be called with the keys changed by each reload
+func OnChange(fn func(changed []string)) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// Reload re-reads the .env file and the file of the profile, and applies changed settings.
+// Changes to immutable settings are logged and ignored; they need a restart.
+// It returns the keys whose value changed.
+func Reload() ([]string, error) {
+	values, err := readConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	fileMu.Lock()
+	var changed []string
+	for key, value := range values {
+		old, fromFile := fileValues[key]
+		if _, exists := os.LookupEnv(key); exists && !fromFile {
+			continue // set by the environment, which wins over the file
+		}
+		if fromFile && old == value {
+			continue
+		}
+		if immutableKeys[key] {
+			log.Printf("Config reload: ignoring change to %s, it is only read at startup; restart to apply it", key)
+			continue
+		}
+		os.Setenv(key, value)
+		fileValues[key] = value
+		changed = append(changed, key)
+	}
+	for key := range fileValues {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if immutableKeys[key] {
+			log.Printf("Config reload: ignoring removal of %s, it is only read at startup; restart to apply it", key)
+			continue
+		}
+		os.Unsetenv(key)
+		delete(fileValues, key)
+		changed = append(changed, key)
+	}
+	notify := append([]func([]string){}, listeners...)
+	fileMu.Unlock()
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(changed)
+	log.Printf("Config reload: applied changes to %v", changed)
+	for _, fn := range notify {
+		fn(changed)
+	}
+	return changed, nil
+}
+
+// Watch reloads the config files whenever the modification time of one changes, checking every
+// interval until ctx is done
+func Watch(ctx context.Context, interval time.Duration) {
+	lastMod := configModTime()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		modTime := configModTime()
+		if modTime.IsZero() || modTime.Equal(lastMod) {
+			continue
+		}
+		lastMod = modTime
+		if _, err := Reload(); err != nil {
+			log.Printf("Config reload failed: %v", err)
+		}
+	}
+}
+
+// configModTime returns the latest modification time of envFile and the file of the profile,
+// zero when neither exists
+func configModTime() time.Time {
+	files := []string{envFile}
+	if profile := Profile(); profile != "" {
+		files = append(files, profileFile(profile))
+	}
+	var latest time.Time
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
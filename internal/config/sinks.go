@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// The external sinks items leave the database for; SINKS lists the ones a deployment may use
+const (
+	SinkEvents     = "events"     // the event transport of EVENT_TRANSPORT: Kafka, NATS or Redis streams
+	SinkOpenSearch = "opensearch" // the clusters at OPENSEARCH_URL and RELATED_OPENSEARCH_URL
+	SinkSnapshots  = "snapshots"  // the dataset snapshots written under SNAPSHOT_DIR, e.g. a mounted bucket
+)
+
+// Sinks lists every sink name accepted by SINKS
+var Sinks = []string{SinkEvents, SinkOpenSearch, SinkSnapshots}
+
+// sinkEndpoints are the settings an explicitly enabled sink must have, so its data goes to an
+// endpoint chosen for the region rather than a default
+var sinkEndpoints = map[string][]string{
+	SinkOpenSearch: {"OPENSEARCH_URL"},
+	SinkSnapshots:  {"SNAPSHOT_DIR"},
+}
+
+// transportEndpoints are the endpoint settings of each event transport; the in-process memory
+// transport has none
+var transportEndpoints = map[string]string{
+	"kafka":  "KAFKA_BOOTSTRAP_SERVERS",
+	"nats":   "NATS_URL",
+	"redis":  "REDIS_ADDR",
+	"memory": "",
+}
+
+// SinkEnabled reports whether a sink may be initialized and health-checked. With SINKS empty every
+// sink is enabled, each still only used once its endpoint is configured; "none" disables them all.
+func SinkEnabled(name string) bool {
+	sinks := GetEnvList("SINKS", nil)
+	return len(sinks) == 0 || slices.Contains(sinks, name)
+}
+
+// EnabledSinks returns the enabled sinks, in the order of Sinks
+func EnabledSinks() []string {
+	var enabled []string
+	for _, name := range Sinks {
+		if SinkEnabled(name) {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+// ValidateSinks checks the data residency settings at startup: the file of CONFIG_PROFILE must
+// exist, SINKS may only name known sinks, and every sink it names needs its endpoint set
+// explicitly, by the environment or the files, for the deployment not to write to defaults
+func ValidateSinks() error {
+	if profile := Profile(); profile != "" {
+		if _, err := os.Stat(profileFile(profile)); err != nil {
+			return fmt.Errorf("config profile %q: %w", profile, err)
+		}
+	}
+	var problems []string
+	for _, name := range GetEnvList("SINKS", nil) {
+		if name == "none" {
+			continue
+		}
+		if !slices.Contains(Sinks, name) {
+			problems = append(problems, fmt.Sprintf("unknown sink %q", name))
+			continue
+		}
+		endpoints := sinkEndpoints[name]
+		if name == SinkEvents {
+			transport := GetEnv("EVENT_TRANSPORT", "kafka")
+			key, ok := transportEndpoints[transport]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("unknown event transport %q", transport))
+				continue
+			}
+			if key != "" {
+				endpoints = []string{key}
+			}
+		}
+		for _, key := range endpoints {
+			if GetEnv(key, "") == "" {
+				problems = append(problems, fmt.Sprintf("sink %s needs %s", name, key))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid SINKS: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
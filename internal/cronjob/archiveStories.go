@@ -0,0 +1,76 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// archiveStories submits the URLs of up to ARCHIVE_BATCH stories scoring at least ARCHIVE_MIN_SCORE
+// to the Wayback Machine, at most ARCHIVE_RATE saves a second, and stores their snapshot URL so
+// the linked content outlives its site. Failed saves are retried after ARCHIVE_RETRY_AFTER; a rate
+// limited save ends the run and leaves the rest of the batch for the next one.
+func (d *DataSyncService) archiveStories(ctx context.Context) {
+	if !config.GetEnvBool("ARCHIVE_ENABLED", false) {
+		return
+	}
+
+	repo := postgres.NewStoryRepository()
+	retryAfter := config.GetEnvDuration("ARCHIVE_RETRY_AFTER", 24*time.Hour)
+	stories, err := repo.GetStoriesToArchive(ctx, config.GetEnvInt("ARCHIVE_MIN_SCORE", 300),
+		d.clock.Now().Add(-retryAfter).Unix(), config.GetEnvInt("ARCHIVE_BATCH", 20))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading stories to archive: %v", err)
+		return
+	}
+	if len(stories) == 0 {
+		return
+	}
+
+	archiver := services.NewArchiver()
+	rate := max(config.GetEnvFloat("ARCHIVE_RATE", 0.2), 0.001)
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer limiter.Stop()
+
+	submitted := stories[:0]
+	saved := 0
+	for i, story := range stories {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-limiter.C:
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		snapshot, err := archiver.Save(ctx, story.URL)
+		if errors.Is(err, services.ErrArchiveRateLimited) {
+			tracing.Logf(ctx, "Wayback Machine rate limit hit after %d saves", len(submitted))
+			break
+		}
+		if err != nil {
+			tracing.Logf(ctx, "Error archiving story %d: %v", story.StoryID, err)
+		} else {
+			saved++
+		}
+		story.ArchiveURL = snapshot
+		story.RequestedAt = d.clock.Now().Unix()
+		submitted = append(submitted, story)
+	}
+
+	// Snapshots taken before an interruption are still worth keeping
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := repo.UpdateArchives(saveCtx, submitted); err != nil {
+		tracing.Logf(ctx, "Error saving story archives: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Archived %d of %d high-score story links", saved, len(submitted))
+}
@@ -0,0 +1,121 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// frontPageSize is the number of top stories shown on the HackerNews front page
+const frontPageSize = 30
+
+// monitorAsks refreshes the front-page Ask HN posts and their comment threads for live Q&A
+// dashboards. It runs on ASK_MONITOR_INTERVAL while ASK_MONITOR_ENABLED is set, independently
+// of the hourly ask and comment syncs.
+func (d *DataSyncService) monitorAsks(ctx context.Context) {
+	if !config.GetEnvBool("ASK_MONITOR_ENABLED", false) {
+		return
+	}
+
+	ids, err := d.frontPageAskIDs(ctx)
+	if err != nil {
+		tracing.Logf(ctx, "Error finding front-page asks: %v", err)
+		return
+	}
+	if len(ids) == 0 {
+		tracing.Logln(ctx, "No Ask HN posts on the front page")
+		return
+	}
+
+	asks, err := fetchWithRetry(ctx, "asks", ids, d.askService.FetchMultiple)
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching front-page asks: %v", err)
+		return
+	}
+
+	comments := d.fetchAskThreads(ctx, asks)
+
+	asks = prePersistAll(ctx, d, asks)
+	comments = prePersistAll(ctx, d, comments)
+	d.awaitReadCapacity(ctx)
+
+	askCounts, err := postgres.NewAskRepository().UpsertBatch(ctx, asks)
+	if err != nil {
+		tracing.Logf(ctx, "Error saving front-page asks: %v", err)
+		return
+	}
+	postPersistAll(ctx, d, asks)
+	askIDs := itemIDs(asks, func(a *models.Ask) int { return a.ID })
+	d.invalidateItems(ctx, "ask", askIDs)
+
+	commentCounts, err := postgres.NewCommentRepository().UpsertBatch(ctx, comments)
+	if err != nil {
+		tracing.Logf(ctx, "Error saving front-page ask comments: %v", err)
+		return
+	}
+	postPersistAll(ctx, d, comments)
+	d.scoreComments(ctx, comments)
+	commentIDs := itemIDs(comments, func(c *models.Comment) int { return c.ID })
+	d.invalidateItems(ctx, "comment", commentIDs)
+
+	// Push the refreshed threads to the search index right away rather than on the next update sync
+	if err := d.publishItemIDs(ctx, "AsksTopic", askIDs); err != nil {
+		tracing.Logf(ctx, "Error sending front-page asks to the event bus: %v", err)
+	}
+	if err := d.publishItemIDs(ctx, "CommentsTopic", commentIDs); err != nil {
+		tracing.Logf(ctx, "Error sending front-page ask comments to the event bus: %v", err)
+	}
+
+	tracing.Logf(ctx, "Ask monitor refreshed %d asks (%s) and %d comments (%s)",
+		len(asks), askCounts, len(comments), commentCounts)
+}
+
+// frontPageAskIDs returns the Ask HN posts among the front-page top stories, in rank order
+func (d *DataSyncService) frontPageAskIDs(ctx context.Context) ([]int, error) {
+	topIDs, err := d.storyService.FetchTopStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	askIDs, err := d.askService.FetchAskStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	isAsk := make(map[int]bool, len(askIDs))
+	for _, id := range askIDs {
+		isAsk[id] = true
+	}
+
+	frontPage := config.GetEnvInt("ASK_MONITOR_FRONT_PAGE", frontPageSize)
+	var ids []int
+	for i, id := range topIDs {
+		if i >= frontPage {
+			break
+		}
+		if isAsk[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// fetchAskThreads fetches the comments of the asks level by level, down to ASK_MONITOR_DEPTH
+// levels and at most ASK_MONITOR_MAX_COMMENTS comments per ask
+func (d *DataSyncService) fetchAskThreads(ctx context.Context, asks []*models.Ask) []*models.Comment {
+	roots := make([]services.CommentRoot, len(asks))
+	for i, ask := range asks {
+		roots[i] = services.CommentRoot{ID: ask.ID, Kids: ask.Reply_ids}
+	}
+	comments, err := d.fetchCommentTrees(ctx, roots, services.CommentTreeLimits{
+		MaxDepth: config.GetEnvInt("ASK_MONITOR_DEPTH", 2),
+		MaxNodes: config.GetEnvInt("ASK_MONITOR_MAX_COMMENTS", 500),
+	})
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching ask comments: %v", err)
+	}
+	return comments
+}
@@ -0,0 +1,54 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// catchUpOnStartup syncs the items created while the service was down.
+// It compares the newest stored item with HN maxitem and, when CATCHUP_ENABLED is set,
+// fetches the gap (capped at CATCHUP_MAX_ITEMS) with CATCHUP_BATCH_DELAY between batches.
+func (d *DataSyncService) catchUpOnStartup(ctx context.Context) {
+	if !config.GetEnvBool("CATCHUP_ENABLED", false) {
+		return
+	}
+
+	newest, err := postgres.NewStatsRepository().GetNewestItemID(ctx)
+	if err != nil {
+		tracing.Logf(ctx, "Error reading newest stored item, skipping catch-up: %v", err)
+		return
+	}
+
+	maxItem, err := d.apiClient.GetMaxItemID()
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching max item ID, skipping catch-up: %v", err)
+		return
+	}
+
+	if newest == 0 || maxItem <= newest {
+		tracing.Logf(ctx, "No catch-up needed (newest stored item: %d, maxitem: %d)", newest, maxItem)
+		return
+	}
+
+	from := newest + 1
+	maxItems := config.GetEnvInt("CATCHUP_MAX_ITEMS", 50000)
+	if maxItem-from+1 > maxItems {
+		tracing.Logf(ctx, "Catch-up gap of %d items exceeds CATCHUP_MAX_ITEMS, syncing only the newest %d",
+			maxItem-from+1, maxItems)
+		from = maxItem - maxItems + 1
+	}
+
+	tracing.Logf(ctx, "Catching up on items %d-%d before starting the schedule...", from, maxItem)
+	d.syncItemRangeThrottled(ctx, from, maxItem, config.GetEnvDuration("CATCHUP_BATCH_DELAY", 500*time.Millisecond))
+
+	// The gap is covered; keep the updates job from scheduling the same range again
+	checkpoint := maxItemCheckpoint{MaxItem: maxItem, SeenAt: d.clock.Now().Unix()}
+	if err := d.cache.CacheJSON(ctx, maxItemCheckpointKey, checkpoint, 0); err != nil {
+		tracing.Logf(ctx, "Error saving maxitem checkpoint: %v", err)
+	}
+	tracing.Logln(ctx, "Startup catch-up completed")
+}
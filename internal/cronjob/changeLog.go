@@ -0,0 +1,38 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// maintainChangeLog numbers the pending changes of the change log, so they are ready for the
+// pollers of /api/v1/changes even when none polls, and prunes the changes older than
+// CHANGES_RETENTION
+func (d *DataSyncService) maintainChangeLog(ctx context.Context) {
+	repo := postgres.NewChangeRepository()
+	batch := config.GetEnvInt("CHANGES_SEQUENCE_BATCH", 5000)
+	for {
+		numbered, err := repo.SequenceChanges(ctx, batch)
+		if err != nil {
+			tracing.Logf(ctx, "Error numbering changes: %v", err)
+			return
+		}
+		if numbered < batch {
+			break
+		}
+	}
+
+	before := d.clock.Now().Add(-config.GetEnvDuration("CHANGES_RETENTION", 7*24*time.Hour)).UnixMilli()
+	pruned, err := repo.PruneChanges(ctx, before)
+	if err != nil {
+		tracing.Logf(ctx, "Error pruning changes: %v", err)
+		return
+	}
+	if pruned > 0 {
+		tracing.Logf(ctx, "Pruned %d changes from the change log", pruned)
+	}
+}
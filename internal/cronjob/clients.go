@@ -0,0 +1,29 @@
+package cronjob
+
+import (
+	"context"
+	"log"
+
+	"internship-project/internal/redis"
+	"internship-project/internal/transport"
+)
+
+// SetPublisher replaces the event publisher the saved items are announced on, closing the one
+// it replaces; call it before Start
+func (d *DataSyncService) SetPublisher(publisher transport.Publisher) {
+	if err := d.publisher.Close(); err != nil {
+		log.Printf("Failed to close event publisher: %v", err)
+	}
+	d.publisher = publisher
+}
+
+// SetCache replaces the Redis cache of the synced IDs, hot items and checkpoints; call it
+// before Start
+func (d *DataSyncService) SetCache(cache redis.Cache) {
+	d.cache = cache
+}
+
+// SyncUpdates runs the update sync once, outside of its schedule
+func (d *DataSyncService) SyncUpdates(ctx context.Context) {
+	d.syncUpdates(ctx)
+}
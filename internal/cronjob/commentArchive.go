@@ -0,0 +1,38 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// archiveComments moves the comments older than COMMENTS_COLD_AFTER_MONTHS to the cold table,
+// COMMENTS_ARCHIVE_BATCH at a time, so the hot table only holds the recent ones. Zero months
+// disables it.
+func (d *DataSyncService) archiveComments(ctx context.Context) {
+	months := config.GetEnvInt("COMMENTS_COLD_AFTER_MONTHS", 6)
+	if months <= 0 {
+		return
+	}
+	before := d.clock.Now().AddDate(0, -months, 0).Unix()
+	batch := max(config.GetEnvInt("COMMENTS_ARCHIVE_BATCH", 1000), 1)
+
+	repo := postgres.NewCommentArchiveRepository()
+	total := 0
+	for ctx.Err() == nil {
+		moved, err := repo.Archive(ctx, before, batch)
+		if err != nil {
+			tracing.Logf(ctx, "Error archiving comments: %v", err)
+			break
+		}
+		total += moved
+		if moved < batch {
+			break
+		}
+	}
+	if total > 0 {
+		tracing.Logf(ctx, "Archived %d comments older than %d months", total, months)
+	}
+}
@@ -0,0 +1,44 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// commentQualityWeights returns the weights of the comment quality score, COMMENT_QUALITY_*_WEIGHT
+func commentQualityWeights() models.CommentQualityWeights {
+	return models.CommentQualityWeights{
+		Replies: config.GetEnvFloat("COMMENT_QUALITY_REPLIES_WEIGHT", 1),
+		Depth:   config.GetEnvFloat("COMMENT_QUALITY_DEPTH_WEIGHT", 0.2),
+		Karma:   config.GetEnvFloat("COMMENT_QUALITY_KARMA_WEIGHT", 0.5),
+		Length:  config.GetEnvFloat("COMMENT_QUALITY_LENGTH_WEIGHT", 0.3),
+	}
+}
+
+// rankComments scores the comments of the threads that got a comment within COMMENT_QUALITY_WINDOW
+// and stores their rank among their siblings, which the thread endpoint serves as the "best" order.
+// Quieter threads keep their last ranks.
+func (d *DataSyncService) rankComments(ctx context.Context) {
+	repo := postgres.NewCommentRepository()
+	window := config.GetEnvDuration("COMMENT_QUALITY_WINDOW", 48*time.Hour)
+	signals, err := repo.GetQualitySignals(ctx, d.clock.Now().Add(-window).Unix())
+	if err != nil {
+		tracing.Logf(ctx, "Error loading comment quality signals: %v", err)
+		return
+	}
+	if len(signals) == 0 {
+		return
+	}
+
+	qualities := models.RankComments(signals, commentQualityWeights())
+	if err := repo.UpdateQuality(ctx, qualities); err != nil {
+		tracing.Logf(ctx, "Error saving comment quality: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Ranked %d comments by quality", len(qualities))
+}
@@ -0,0 +1,90 @@
+package cronjob
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// resyncComments re-fetches the HackerNews comments created within COMMENT_RESYNC_MAX_AGE, at
+// most COMMENT_RESYNC_BATCH of them, to pick up edits. Only the comments whose text hash differs
+// from the stored one are written, invalidated and sent for reindexing.
+func (d *DataSyncService) resyncComments(ctx context.Context) {
+	if !config.GetEnvBool("COMMENT_RESYNC_ENABLED", false) {
+		return
+	}
+
+	repo := postgres.NewCommentRepository()
+	maxAge := config.GetEnvDuration("COMMENT_RESYNC_MAX_AGE", 24*time.Hour)
+	stored, err := repo.GetTextHashes(ctx, d.clock.Now().Add(-maxAge).Unix(), config.GetEnvInt("COMMENT_RESYNC_BATCH", 500))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading recent comment hashes: %v", err)
+		return
+	}
+	if len(stored) == 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(stored))
+	for id := range stored {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	fetched, err := fetchWithRetry(ctx, "comments", ids, d.commentService.FetchMultiple)
+	if err != nil {
+		tracing.Logf(ctx, "Error re-fetching recent comments: %v", err)
+		return
+	}
+
+	// Deleted comments come back invalid and keep their stored text
+	valid := fetched[:0]
+	for _, comment := range fetched {
+		if comment.IsValid() {
+			valid = append(valid, comment)
+		}
+	}
+
+	// Hash the text as it would be stored, after the ETL plugins rewrote it
+	valid = prePersistAll(ctx, d, valid)
+	var edited []*models.Comment
+	for _, comment := range valid {
+		if textHash(comment.Text) != stored[comment.ID] {
+			edited = append(edited, comment)
+		}
+	}
+	if len(edited) == 0 {
+		tracing.Logf(ctx, "Re-synced %d recent comments, none edited", len(fetched))
+		return
+	}
+
+	d.awaitReadCapacity(ctx)
+	counts, err := repo.UpsertBatch(ctx, edited)
+	if err != nil {
+		tracing.Logf(ctx, "Error saving edited comments: %v", err)
+		return
+	}
+	postPersistAll(ctx, d, edited)
+	d.scoreComments(ctx, edited)
+	editedIDs := itemIDs(edited, func(c *models.Comment) int { return c.ID })
+	d.invalidateItems(ctx, "comment", editedIDs)
+
+	if err := d.publishItemIDs(ctx, "CommentsTopic", editedIDs); err != nil {
+		tracing.Logf(ctx, "Error sending edited comments to the event bus: %v", err)
+	}
+
+	tracing.Logf(ctx, "Re-synced %d recent comments, %d edited (%s)", len(fetched), len(edited), counts)
+}
+
+// textHash returns the MD5 hex digest of a text, as computed by md5() in Postgres
+func textHash(text string) string {
+	sum := md5.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
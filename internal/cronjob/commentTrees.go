@@ -0,0 +1,25 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/models"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// fetchCommentTrees fetches the comments below the roots within the limits, retrying the failed
+// comments of each level once like fetchWithRetry
+func (d *DataSyncService) fetchCommentTrees(ctx context.Context, roots []services.CommentRoot, limits services.CommentTreeLimits) ([]*models.Comment, error) {
+	fetch := func(ctx context.Context, ids []int, opts ...services.FetchOption) ([]*models.Comment, error) {
+		return fetchWithRetry(ctx, "comments", ids, d.commentService.FetchMultiple)
+	}
+	tree, err := services.FetchCommentTrees(ctx, fetch, roots, limits)
+	if tree.Repeated > 0 {
+		tracing.Logf(ctx, "Skipped %d repeated reply IDs in %d comment trees", tree.Repeated, len(roots))
+	}
+	if tree.Truncated {
+		tracing.Logf(ctx, "Comment trees cut at %d levels or %d comments per item", limits.MaxDepth, limits.MaxNodes)
+	}
+	return tree.Comments, err
+}
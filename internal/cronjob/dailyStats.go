@@ -0,0 +1,33 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// refreshDailyStats materializes the aggregates and story score distributions of today and
+// yesterday (UTC). Yesterday is recomputed too so items synced late or updated after midnight are
+// still counted.
+func (d *DataSyncService) refreshDailyStats(ctx context.Context) {
+	tracing.Logln(ctx, "Starting daily stats refresh...")
+
+	repo := postgres.NewStatsRepository()
+	topN := config.GetEnvInt("DAILY_STATS_TOP_N", 10)
+	window := scorePercentileWindow()
+
+	now := d.clock.Now().UTC()
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		if err := repo.RefreshDailyStats(ctx, day, topN); err != nil {
+			tracing.Logf(ctx, "Error refreshing daily stats for %s: %v", day.Format(time.DateOnly), err)
+		}
+		if err := repo.RefreshScorePercentiles(ctx, day, window); err != nil {
+			tracing.Logf(ctx, "Error refreshing score percentiles for %s: %v", day.Format(time.DateOnly), err)
+		}
+	}
+
+	tracing.Logln(ctx, "Daily stats refresh completed")
+}
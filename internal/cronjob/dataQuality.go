@@ -0,0 +1,81 @@
+package cronjob
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/tracing"
+)
+
+// dataQualityAnomalies publishes the anomaly count of each check of the last run on /debug/vars
+var dataQualityAnomalies = expvar.NewMap("data_quality_anomalies")
+
+// checkDataQuality scans the store for anomalies, compares its item counts with the search
+// index when OPENSEARCH_URL is set, and saves the report
+func (d *DataSyncService) checkDataQuality(ctx context.Context) {
+	repo := postgres.NewDataQualityRepository()
+	report := &models.DataQualityReport{Created_At: d.clock.Now().Unix()}
+
+	checks, err := repo.RunChecks(ctx, config.GetEnvInt("DATA_QUALITY_SAMPLE_SIZE", 10))
+	if err != nil {
+		tracing.Logf(ctx, "Error running data-quality checks: %v", err)
+		return
+	}
+	report.Checks = checks
+
+	if client := search.NewClient(); client != nil {
+		report.Checks = append(report.Checks, indexCountChecks(ctx, repo.CountItems, client)...)
+	}
+
+	for _, check := range report.Checks {
+		dataQualityAnomalies.Set(check.Name, intVar(check.Count))
+		if check.Count > 0 {
+			tracing.Logf(ctx, "Data quality: %s: %d (%s), e.g. %v", check.Name, check.Count, check.Detail, check.SampleIDs)
+		}
+	}
+
+	if err := repo.SaveReport(ctx, report); err != nil {
+		tracing.Logf(ctx, "Error saving data-quality report: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Data-quality report %s: %d anomalies across %d checks", report.ID, report.Anomalies(), len(report.Checks))
+}
+
+// indexCountChecks reports, per kind, how many items stored in Postgres are missing from the search index
+func indexCountChecks(ctx context.Context, countItems func(context.Context, string) (int64, error), client *search.Client) []models.DataQualityCheck {
+	var checks []models.DataQualityCheck
+	for _, kind := range search.Kinds() {
+		stored, err := countItems(ctx, kind)
+		if err != nil {
+			tracing.Logf(ctx, "Error counting stored %s items: %v", kind, err)
+			continue
+		}
+		indexed, err := client.Count(ctx, kind)
+		if err != nil {
+			tracing.Logf(ctx, "Error counting indexed %s items: %v", kind, err)
+			continue
+		}
+
+		missing := stored - indexed
+		if missing < 0 {
+			missing = 0
+		}
+		checks = append(checks, models.DataQualityCheck{
+			Name:   "index_missing_" + kind,
+			Count:  missing,
+			Detail: fmt.Sprintf("%d stored, %d indexed", stored, indexed),
+		})
+	}
+	return checks
+}
+
+func intVar(n int64) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(n)
+	return v
+}
@@ -2,16 +2,29 @@ package cronjob
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"internship-project/internal/kafka"
+	"internship-project/internal/bloom"
+	"internship-project/internal/clock"
+	"internship-project/internal/config"
+	"internship-project/internal/etl"
+	"internship-project/internal/loadshed"
 	"internship-project/internal/models"
+	"internship-project/internal/opensearch"
 	"internship-project/internal/redis"
+	"internship-project/internal/repository"
 	"internship-project/internal/repository/postgres"
 	"internship-project/internal/services"
+	"internship-project/internal/spam"
+	"internship-project/internal/tasks"
+	"internship-project/internal/tracing"
+	"internship-project/internal/transport"
 	"internship-project/pkg/database"
 
 	"github.com/go-co-op/gocron/v2"
@@ -28,6 +41,16 @@ type DataSyncService struct {
 	pollService       *services.PollApiService
 	pollOptionService *services.PollOptionApiService
 	updateService     *services.UpdateApiService
+	sources           []sourceJob
+	jobs              []registeredJob
+	spamScorer        *spam.Scorer
+	publisher         transport.Publisher
+	cache             redis.Cache
+	plugins           *etl.Pipeline
+	governor          *loadshed.Governor
+	indexer           *opensearch.IndexerService   // see newInlineIndexer
+	clock             clock.Clock                  // time of the schedule, the cutoffs and the sync stamps
+	tasks             atomic.Pointer[tasks.Worker] // set by RegisterTasks; nil runs repairs in process
 }
 
 // NewDataSyncService creates a new data sync service
@@ -42,12 +65,23 @@ func NewDataSyncService(
 	pollOptionService *services.PollOptionApiService,
 	updateService *services.UpdateApiService,
 ) (*DataSyncService, error) {
-	// Create a single scheduler for all jobs
-	scheduler, err := gocron.NewScheduler()
+	// Create a single scheduler for all jobs, running on the clock of the jobs
+	clk := clock.Default()
+	scheduler, err := gocron.NewScheduler(gocron.WithClock(clk))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scheduler: %w", err)
 	}
 
+	publisher, err := transport.NewPublisher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event publisher: %w", err)
+	}
+
+	plugins, err := etl.NewPipelineFromConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ETL pipeline: %w", err)
+	}
+
 	return &DataSyncService{
 		scheduler:         scheduler,
 		apiClient:         apiClient,
@@ -59,6 +93,16 @@ func NewDataSyncService(
 		pollService:       pollService,
 		pollOptionService: pollOptionService,
 		updateService:     updateService,
+		sources: []sourceJob{
+			{source: services.NewHackerNewsSource(storyService), intervalKey: "HN_SYNC_INTERVAL", interval: 50 * time.Minute},
+		},
+		spamScorer: newSpamScorer(),
+		publisher:  publisher,
+		cache:      redis.NewCache(),
+		plugins:    plugins,
+		governor:   loadshed.NewGovernor(loadshed.APILatency, func() sql.DBStats { return database.GetDB().Stats() }),
+		indexer:    newInlineIndexer(),
+		clock:      clk,
 	}, nil
 }
 
@@ -71,6 +115,17 @@ func (d *DataSyncService) Start() error {
 		log.Printf("Failed to connect to database: %v", err)
 	}
 
+	// Keep the payloads failing schema validation for investigation, and the others for
+	// reparsing when ITEM_RAW_PAYLOADS_ENABLED is set
+	d.apiClient.SetDeadLetterSink(postgres.NewDeadLetterRepository())
+	d.apiClient.SetPayloadSink(postgres.NewItemPayloadRepository())
+
+	// Existence checks stay unfiltered until the known item IDs are loaded
+	go d.tracedRun("load-known-items", d.loadKnownItems)()
+
+	// Fill the gap left by downtime before the regular schedule starts
+	d.tracedRun("catch-up", d.catchUpOnStartup)()
+
 	// Register all jobs
 	if err := d.registerJobs(); err != nil {
 		return fmt.Errorf("failed to register jobs: %w", err)
@@ -89,6 +144,10 @@ func (d *DataSyncService) Stop() error {
 	}
 	log.Println("DataSyncService stopped")
 
+	if err := d.publisher.Close(); err != nil {
+		log.Printf("Failed to close event publisher: %v", err)
+	}
+
 	// shutdown the database connection
 	if err := database.Close(); err != nil {
 		log.Printf("Failed to close database connection: %v", err)
@@ -98,98 +157,227 @@ func (d *DataSyncService) Stop() error {
 	return nil
 }
 
+// scheduledJob is a task run by the scheduler on a fixed interval.
+// The interval is read from intervalKey when set, falling back to interval.
+type scheduledJob struct {
+	name        string
+	intervalKey string
+	interval    time.Duration
+	task        func(ctx context.Context)
+	immediate   bool // Add this flag
+}
+
+// currentInterval returns the configured interval of the job
+func (j scheduledJob) currentInterval() time.Duration {
+	if j.intervalKey == "" {
+		return j.interval
+	}
+	return config.GetEnvDuration(j.intervalKey, j.interval)
+}
+
+// registeredJob is a scheduledJob handed to the scheduler
+type registeredJob struct {
+	scheduledJob
+	job      gocron.Job
+	run      func()
+	interval time.Duration // interval the job is currently scheduled with
+}
+
 // registerJobs sets up all the cron jobs
 func (d *DataSyncService) registerJobs() error {
-	jobs := []struct {
-		name      string
-		interval  time.Duration
-		task      func()
-		immediate bool // Add this flag
-	}{
+	jobs := []scheduledJob{
+		{
+			name:        "sync-asks",
+			intervalKey: "SYNC_ASKS_INTERVAL",
+			interval:    60 * time.Minute,
+			task:        d.syncAsks,
+		},
+		{
+			name:        "sync-jobs",
+			intervalKey: "SYNC_JOBS_INTERVAL",
+			interval:    60 * time.Minute,
+			task:        d.syncJobs,
+		},
+		{
+			name:        "sync-comments",
+			intervalKey: "SYNC_COMMENTS_INTERVAL",
+			interval:    60 * time.Minute,
+			task:        d.syncComments,
+		},
+		{
+			name:        "resync-comments",
+			intervalKey: "COMMENT_RESYNC_INTERVAL",
+			interval:    6 * time.Hour,
+			task:        d.resyncComments,
+		},
+		{
+			name:        "rank-comments",
+			intervalKey: "COMMENT_QUALITY_INTERVAL",
+			interval:    15 * time.Minute,
+			task:        d.rankComments,
+		},
+		{
+			name:        "sync-polls",
+			intervalKey: "SYNC_POLLS_INTERVAL",
+			interval:    2 * time.Hour,
+			task:        d.syncPolls,
+		},
+		{
+			name:        "sync-updates",
+			intervalKey: "SYNC_UPDATES_INTERVAL",
+			interval:    10 * time.Second,
+			task:        d.syncUpdates,
+			immediate:   true,
+		},
+		{
+			name:        "monitor-asks",
+			intervalKey: "ASK_MONITOR_INTERVAL",
+			interval:    2 * time.Minute,
+			task:        d.monitorAsks,
+		},
+		{
+			name:        "check-links",
+			intervalKey: "LINK_CHECK_INTERVAL",
+			interval:    time.Hour,
+			task:        d.checkLinks,
+		},
+		{
+			name:        "archive-stories",
+			intervalKey: "ARCHIVE_INTERVAL",
+			interval:    30 * time.Minute,
+			task:        d.archiveStories,
+		},
+		{
+			name:        "fetch-link-previews",
+			intervalKey: "LINK_PREVIEW_INTERVAL",
+			interval:    15 * time.Minute,
+			task:        d.fetchLinkPreviews,
+		},
+		{
+			name:        "refresh-domains",
+			intervalKey: "DOMAINS_INTERVAL",
+			interval:    time.Hour,
+			task:        d.refreshDomains,
+		},
+		{
+			name:        "maintain-partitions",
+			intervalKey: "PARTITIONS_INTERVAL",
+			interval:    24 * time.Hour,
+			task:        d.maintainPartitions,
+			immediate:   true,
+		},
+		{
+			name:        "maintain-tables",
+			intervalKey: "MAINTENANCE_INTERVAL",
+			interval:    10 * time.Minute,
+			task:        d.maintainTables,
+		},
+		{
+			name:        "record-storage",
+			intervalKey: "STORAGE_INTERVAL",
+			interval:    time.Hour,
+			task:        d.recordStorage,
+			immediate:   true,
+		},
+		{
+			name:        "maintain-change-log",
+			intervalKey: "CHANGES_INTERVAL",
+			interval:    time.Minute,
+			task:        d.maintainChangeLog,
+		},
 		{
-			name:     "sync-stories",
-			interval: 50 * time.Minute,
-			task:     d.syncStories,
+			name:        "maintain-tombstones",
+			intervalKey: "TOMBSTONES_INTERVAL",
+			interval:    5 * time.Minute,
+			task:        d.maintainTombstones,
 		},
 		{
-			name:     "sync-asks",
-			interval: 60 * time.Minute,
-			task:     d.syncAsks,
+			name:        "expire-items",
+			intervalKey: "ITEM_RETENTION_INTERVAL",
+			interval:    24 * time.Hour,
+			task:        d.expireItems,
 		},
 		{
-			name:     "sync-jobs",
-			interval: 60 * time.Minute,
-			task:     d.syncJobs,
+			name:        "snapshot-dataset",
+			intervalKey: "SNAPSHOT_INTERVAL",
+			interval:    7 * 24 * time.Hour,
+			task:        d.snapshotDataset,
 		},
 		{
-			name:     "sync-comments",
-			interval: 60 * time.Minute,
-			task:     d.syncComments,
+			name:        "archive-comments",
+			intervalKey: "COMMENTS_ARCHIVE_INTERVAL",
+			interval:    24 * time.Hour,
+			task:        d.archiveComments,
 		},
 		{
-			name:      "sync-updates",
-			interval:  10 * time.Second,
-			task:      func() { d.syncUpdates() },
-			immediate: true,
+			name:        "check-data-quality",
+			intervalKey: "DATA_QUALITY_INTERVAL",
+			interval:    6 * time.Hour,
+			task:        d.checkDataQuality,
+		},
+		{
+			name:        "link-story-duplicates",
+			intervalKey: "DUPLICATES_INTERVAL",
+			interval:    30 * time.Minute,
+			task:        d.linkStoryDuplicates,
+		},
+		{
+			name:        "evaluate-watch-ranks",
+			intervalKey: "WATCH_RANK_INTERVAL",
+			interval:    5 * time.Minute,
+			task:        d.evaluateWatchRanks,
+		},
+		{
+			name:        "refresh-daily-stats",
+			intervalKey: "DAILY_STATS_INTERVAL",
+			interval:    time.Hour,
+			task:        d.refreshDailyStats,
+			immediate:   true,
 		},
 	}
 
+	for _, src := range d.sources {
+		source := src.source
+		jobs = append(jobs, scheduledJob{
+			name:        "sync-source-" + source.Name(),
+			intervalKey: src.intervalKey,
+			interval:    src.interval,
+			task:        func(ctx context.Context) { d.syncSource(ctx, source) },
+		})
+	}
+
 	for _, job := range jobs {
+		run := d.tracedRun(job.name, job.task)
+
 		// Run immediately
 		if job.immediate {
 			log.Printf("Running job %s immediately...", job.name)
-			go job.task()
+			go run()
 		}
-		_, err := d.scheduler.NewJob(
-			gocron.DurationJob(job.interval),
-			gocron.NewTask(job.task),
+		interval := job.currentInterval()
+		scheduled, err := d.scheduler.NewJob(
+			gocron.DurationJob(interval),
+			gocron.NewTask(run),
 			gocron.WithName(job.name),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create job %s: %w", job.name, err)
 		}
-		log.Printf("Registered job: %s (every %v)", job.name, job.interval)
+		d.jobs = append(d.jobs, registeredJob{scheduledJob: job, job: scheduled, run: run, interval: interval})
+		log.Printf("Registered job: %s (every %v)", job.name, interval)
 	}
 
+	config.OnChange(d.rescheduleJobs)
 	return nil
 }
 
 // Job implementations
-func (d *DataSyncService) syncStories() {
-	log.Println("Starting story sync...")
-
-	// Fetch top stories
-	ctx := context.Background()
-	ids, err := d.storyService.FetchTopStories(ctx)
-	if err != nil {
-		log.Printf("Error fetching top stories: %v", err)
-		return
-	}
+func (d *DataSyncService) syncAsks(ctx context.Context) {
+	tracing.Logln(ctx, "Starting ask sync...")
 
-	stories, err := d.storyService.FetchMultiple(ctx, ids)
-	if err != nil {
-		log.Printf("Error fetching story details: %v", err)
-		return
-	}
-
-	log.Printf("Successfully synced %d stories", len(stories))
-
-	log.Println("Saving stories to the database...")
-
-	r := postgres.NewStoryRepository()
-	r.CreateBatchWithExistingIDs(ctx, stories)
-
-	log.Println("Story sync completed")
-	log.Printf("Total stories synced: %d", len(stories))
-}
-
-func (d *DataSyncService) syncAsks() {
-	log.Println("Starting ask sync...")
-
-	ctx := context.Background()
 	ids, err := d.askService.FetchAskStories(ctx)
 	if err != nil {
-		log.Printf("Error fetching ask stories: %v", err)
+		tracing.Logf(ctx, "Error fetching ask stories: %v", err)
 		return
 	}
 
@@ -197,123 +385,135 @@ func (d *DataSyncService) syncAsks() {
 		ids = ids[:10]
 	}
 
-	asks, err := d.askService.FetchMultiple(ctx, ids)
+	asks, err := fetchWithRetry(ctx, "asks", ids, d.askService.FetchMultiple)
 	if err != nil {
-		log.Printf("Error fetching ask details: %v", err)
+		tracing.Logf(ctx, "Error fetching ask details: %v", err)
 		return
 	}
 
-	log.Printf("Successfully synced %d asks", len(asks))
+	tracing.Logf(ctx, "Successfully synced %d asks", len(asks))
 
-	log.Println("Saving asks to the database...")
+	tracing.Logln(ctx, "Saving asks to the database...")
 
+	asks = prePersistAll(ctx, d, asks)
+	d.awaitReadCapacity(ctx)
 	r := postgres.NewAskRepository()
-	err = r.CreateBatchWithExistingIDs(ctx, asks)
+	counts, err := r.UpsertBatch(ctx, asks)
 	if err != nil {
-		log.Printf("Error saving asks to the database: %v", err)
+		tracing.Logf(ctx, "Error saving asks to the database: %v", err)
 		return
 	}
+	tracing.Logf(ctx, "Saved asks: %s", counts)
+	postPersistAll(ctx, d, asks)
+	d.scoreAsks(ctx, asks)
+	d.invalidateItems(ctx, "ask", itemIDs(asks, func(a *models.Ask) int { return a.ID }))
 
-	log.Println("Ask sync completed")
-	log.Printf("Total asks synced: %d", len(asks))
+	tracing.Logln(ctx, "Ask sync completed")
+	tracing.Logf(ctx, "Total asks synced: %d", len(asks))
 }
 
-func (d *DataSyncService) syncJobs() {
-	log.Println("Starting job sync...")
+func (d *DataSyncService) syncJobs(ctx context.Context) {
+	tracing.Logln(ctx, "Starting job sync...")
 
-	ctx := context.Background()
 	ids, err := d.jobService.FetchJobStories(ctx)
 	if err != nil {
-		log.Printf("Error fetching job stories: %v", err)
+		tracing.Logf(ctx, "Error fetching job stories: %v", err)
 		return
 	}
 
-	jobs, err := d.jobService.FetchMultiple(ctx, ids)
+	jobs, err := fetchWithRetry(ctx, "jobs", ids, d.jobService.FetchMultiple)
 	if err != nil {
-		log.Printf("Error fetching job details: %v", err)
+		tracing.Logf(ctx, "Error fetching job details: %v", err)
 		return
 	}
 
-	log.Printf("Successfully synced %d jobs", len(jobs))
+	tracing.Logf(ctx, "Successfully synced %d jobs", len(jobs))
 
-	log.Println("Saving jobs to the database...")
+	tracing.Logln(ctx, "Saving jobs to the database...")
 
+	jobs = prePersistAll(ctx, d, jobs)
+	d.awaitReadCapacity(ctx)
 	r := postgres.NewJobRepository()
-	err = r.CreateBatchWithExistingIDs(ctx, jobs)
+	counts, err := r.UpsertBatch(ctx, jobs)
 	if err != nil {
-		log.Printf("Error saving jobs to the database: %v", err)
+		tracing.Logf(ctx, "Error saving jobs to the database: %v", err)
 		return
 	}
+	tracing.Logf(ctx, "Saved jobs: %s", counts)
+	postPersistAll(ctx, d, jobs)
+	d.invalidateItems(ctx, "job", itemIDs(jobs, func(j *models.Job) int { return j.ID }))
 
-	log.Println("Job sync completed")
-	log.Printf("Total jobs synced: %d", len(jobs))
+	tracing.Logln(ctx, "Job sync completed")
+	tracing.Logf(ctx, "Total jobs synced: %d", len(jobs))
 }
 
-func (d *DataSyncService) syncComments() {
-	log.Println("Starting comment sync...")
+func (d *DataSyncService) syncComments(ctx context.Context) {
+	tracing.Logln(ctx, "Starting comment sync...")
 
 	// Get some story IDs first
-	ctx := context.Background()
 	storyIDs, err := d.storyService.FetchTopStories(ctx)
 	if err != nil {
-		log.Printf("Error fetching stories for comments: %v", err)
+		tracing.Logf(ctx, "Error fetching stories for comments: %v", err)
 		return
 	}
 
 	// Fetch stories to get comment IDs
-	stories, err := d.storyService.FetchMultiple(ctx, storyIDs)
+	stories, err := fetchWithRetry(ctx, "stories", storyIDs, d.storyService.FetchMultiple)
 	if err != nil {
-		log.Printf("Error fetching story details: %v", err)
+		tracing.Logf(ctx, "Error fetching story details: %v", err)
 		return
 	}
 
-	// Collect comment IDs
-	var commentIDs []int
-	for _, story := range stories {
-		if len(story.Comments_ids) > 0 {
-			limit := 300
-			if len(story.Comments_ids) < limit {
-				limit = len(story.Comments_ids)
-			}
-			commentIDs = append(commentIDs, story.Comments_ids[:limit]...)
-		}
+	// Fetch the comment trees, by default the first 300 top-level comments of each story
+	roots := make([]services.CommentRoot, len(stories))
+	for i, story := range stories {
+		roots[i] = services.CommentRoot{ID: story.ID, Kids: story.Comments_ids}
 	}
-
-	if len(commentIDs) == 0 {
-		log.Println("No comments to sync")
+	comments, err := d.fetchCommentTrees(ctx, roots, services.CommentTreeLimits{
+		MaxDepth: config.GetEnvInt("COMMENT_SYNC_MAX_DEPTH", 1),
+		MaxNodes: config.GetEnvInt("COMMENT_SYNC_MAX_COMMENTS", 300),
+	})
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching comments: %v", err)
 		return
 	}
 
-	comments, err := d.commentService.FetchMultiple(ctx, commentIDs)
-	if err != nil {
-		log.Printf("Error fetching comments: %v", err)
+	if len(comments) == 0 {
+		tracing.Logln(ctx, "No comments to sync")
 		return
 	}
 
 	// Save comments to the database
+	comments = prePersistAll(ctx, d, comments)
+	d.awaitReadCapacity(ctx)
 	r := postgres.NewCommentRepository()
-	err = r.CreateBatchWithExistingIDs(ctx, comments)
+	counts, err := r.UpsertBatch(ctx, comments)
 	if err != nil {
-		log.Printf("Error saving comments to the database: %v", err)
+		tracing.Logf(ctx, "Error saving comments to the database: %v", err)
 		return
 	}
+	tracing.Logf(ctx, "Saved comments: %s", counts)
+	postPersistAll(ctx, d, comments)
+	d.scoreComments(ctx, comments)
+	d.invalidateItems(ctx, "comment", itemIDs(comments, func(c *models.Comment) int { return c.ID }))
+	d.cacheHotComments(ctx, stories, comments)
 
-	log.Printf("Successfully synced %d comments", len(comments))
+	tracing.Logf(ctx, "Successfully synced %d comments", len(comments))
 }
 
-func (d *DataSyncService) syncUpdates() {
-	log.Println("Starting update sync...")
+func (d *DataSyncService) syncUpdates(ctx context.Context) {
+	tracing.Logln(ctx, "Starting update sync...")
 
-	ctx := context.Background()
+	d.detectUpdateGap(ctx)
 
 	update, err := d.updateService.FetchUpdates(ctx)
 	if err != nil {
-		log.Printf("Error fetching updates: %v", err)
+		tracing.Logf(ctx, "Error fetching updates: %v", err)
 		return
 	}
 
-	if len(update.IDs) == 0 {
-		log.Println("No items to sync in updates")
+	if update.IsEmpty() {
+		tracing.Logln(ctx, "No items to sync in updates")
 		return
 	}
 
@@ -324,7 +524,6 @@ func (d *DataSyncService) syncUpdates() {
 	jobRepo := postgres.NewJobRepository()
 	pollRepo := postgres.NewPollRepository()
 	pollOptionRepo := postgres.NewPollOptionRepository()
-	userRepo := postgres.NewUserRepository()
 
 	var mu sync.Mutex
 	var stories []models.Story
@@ -333,7 +532,6 @@ func (d *DataSyncService) syncUpdates() {
 	var jobs []models.Job
 	var polls []models.Poll
 	var pollOptions []models.PollOption
-	var users []models.User
 
 	var storiesIDs []int
 	var asksIDs []int
@@ -341,145 +539,122 @@ func (d *DataSyncService) syncUpdates() {
 	var jobsIDs []int
 	var pollsIDs []int
 	var pollOptionsIDs []int
-	var userIDs []string
 
 	var IDsExistsCount []int
-	var UserExistsCount []string
+	removed := make(map[string][]int) // IDs of deleted and dead items by reason
+	outcomes := newSyncOutcomes()
 
 	itemsRedisKey := "ids"
-	userRedisKey := "user_ids"
-
-	var wg sync.WaitGroup
-	for _, itemID := range update.IDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
 
-			// Skip if itemID exists in redis cache
-			exists, err := redis.IsItemInCache(ctx, itemsRedisKey, itemID)
+	// The feed can list hundreds of IDs: fetch them in chunks to spread the load on the API
+	fetchOptions := services.UpdateFetchOptions()
+	services.ForEachChunked(ctx, d.skipGoneItems(ctx, update.ItemIDs()), fetchOptions, func(ctx context.Context, id int) {
+		// Skip if itemID exists in redis cache; IDs never stored cannot be there
+		if bloom.KnownItems().MayContain(id) {
+			exists, err := d.cache.IsItemInCache(ctx, itemsRedisKey, id)
 			if err != nil {
-				log.Printf("Error checking cache for item %d: %v", id, err)
+				tracing.Logf(ctx, "Error checking cache for item %d: %v", id, err)
 				return
 			}
 
 			if exists {
-				IDsExistsCount = append(IDsExistsCount, itemID)
-				return
-			}
-
-			// Fetch raw item to determine type
-			var rawItem map[string]interface{}
-			err = d.apiClient.GetItem(ctx, id, &rawItem)
-			if err != nil {
-				log.Printf("Error fetching item %d: %v", id, err)
-				return
-			}
-
-			itemType, ok := rawItem["type"].(string)
-			if !ok {
-				log.Printf("Item %d has no valid type", id)
+				mu.Lock()
+				IDsExistsCount = append(IDsExistsCount, id)
+				mu.Unlock()
 				return
 			}
+		}
 
-			log.Printf("Processing item %d of type: %s", id, itemType)
-
-			// Process based on type
-			switch itemType {
-			case "story":
-				var story models.Story
-				if err := d.apiClient.GetItem(ctx, id, &story); err == nil && story.IsValid() {
-					mu.Lock()
-					stories = append(stories, story)
-					storiesIDs = append(storiesIDs, story.ID)
-					mu.Unlock()
-				}
-
-			case "ask":
-				var ask models.Ask
-				if err := d.apiClient.GetItem(ctx, id, &ask); err == nil && ask.IsValid() {
-					mu.Lock()
-					asks = append(asks, ask)
-					asksIDs = append(asksIDs, ask.ID)
-					mu.Unlock()
-				}
+		// Fetch raw item to determine type
+		rawItem, err := d.apiClient.GetHNItem(ctx, id)
+		if errors.Is(err, services.ErrItemGone) {
+			mu.Lock()
+			removed[models.TombstoneGone] = append(removed[models.TombstoneGone], id)
+			mu.Unlock()
+			return
+		}
+		if err != nil {
+			tracing.Logf(ctx, "Error fetching item %d: %v", id, err)
+			return
+		}
 
-			case "comment":
-				var comment models.Comment
-				if err := d.apiClient.GetItem(ctx, id, &comment); err == nil && comment.IsValid() {
-					mu.Lock()
-					comments = append(comments, comment)
-					commentsIDs = append(commentsIDs, comment.ID)
-					mu.Unlock()
-				}
+		if reason := rawItem.TombstoneReason(); reason != "" {
+			mu.Lock()
+			removed[reason] = append(removed[reason], id)
+			mu.Unlock()
+			return
+		}
 
-			case "job":
-				var job models.Job
-				if err := d.apiClient.GetItem(ctx, id, &job); err == nil && job.IsValid() {
-					mu.Lock()
-					jobs = append(jobs, job)
-					jobsIDs = append(jobsIDs, job.ID)
-					mu.Unlock()
-				}
+		itemType := rawItem.Kind()
+		if itemType == "" {
+			tracing.Logf(ctx, "Item %d has no valid type", id)
+			return
+		}
 
-			case "poll":
-				var poll models.Poll
-				if err := d.apiClient.GetItem(ctx, id, &poll); err == nil && poll.IsValid() {
-					mu.Lock()
-					polls = append(polls, poll)
-					pollsIDs = append(pollsIDs, poll.ID)
-					mu.Unlock()
-				}
+		tracing.Logf(ctx, "Processing item %d of type: %s", id, itemType)
 
-			case "pollopt":
-				var pollOption models.PollOption
-				if err := d.apiClient.GetItem(ctx, id, &pollOption); err == nil && pollOption.IsValid() {
-					mu.Lock()
-					pollOptions = append(pollOptions, pollOption)
-					pollOptionsIDs = append(pollOptionsIDs, pollOption.ID)
-					mu.Unlock()
-				}
+		// Process based on type
+		switch itemType {
+		case "story":
+			story := *rawItem.ToStory()
+			if story.IsValid() && d.prePersist(ctx, &story) {
+				mu.Lock()
+				stories = append(stories, story)
+				storiesIDs = append(storiesIDs, story.ID)
+				mu.Unlock()
 			}
-		}(itemID)
-	}
-
-	for _, userID := range update.Profiles {
-		wg.Add(1)
-		go func(id string) {
-			defer wg.Done()
 
-			exists, err := redis.IsUserIDInCache(ctx, userRedisKey, id)
-			if err != nil {
-				log.Printf("Error checking cache for user %s: %v", id, err)
-				return
+		case "ask":
+			ask := *rawItem.ToAsk()
+			if ask.IsValid() && d.prePersist(ctx, &ask) {
+				mu.Lock()
+				asks = append(asks, ask)
+				asksIDs = append(asksIDs, ask.ID)
+				mu.Unlock()
 			}
 
-			if exists {
-				UserExistsCount = append(UserExistsCount, id)
-				return
+		case "comment":
+			comment := *rawItem.ToComment()
+			if comment.IsValid() && d.prePersist(ctx, &comment) {
+				mu.Lock()
+				comments = append(comments, comment)
+				commentsIDs = append(commentsIDs, comment.ID)
+				mu.Unlock()
 			}
 
-			var user models.User
-			err = d.apiClient.Get(ctx, fmt.Sprintf("/user/%s.json", id), &user)
-			if err != nil {
-				log.Printf("Error fetching user %s: %v", id, err)
-				return
+		case "job":
+			job := *rawItem.ToJob()
+			if job.IsValid() && d.prePersist(ctx, &job) {
+				mu.Lock()
+				jobs = append(jobs, job)
+				jobsIDs = append(jobsIDs, job.ID)
+				mu.Unlock()
 			}
 
-			if user.IsValid() {
+		case "poll":
+			poll := *rawItem.ToPoll()
+			if poll.IsValid() && d.prePersist(ctx, &poll) {
 				mu.Lock()
-				users = append(users, user)
-				userIDs = append(userIDs, user.Username)
+				polls = append(polls, poll)
+				pollsIDs = append(pollsIDs, poll.ID)
 				mu.Unlock()
 			}
-		}(userID)
-	}
 
-	log.Printf("%d Items already Exists", len(IDsExistsCount))
-	log.Printf("%d Users already Exists", len(UserExistsCount))
+		case "pollopt":
+			pollOption := *rawItem.ToPollOption()
+			if pollOption.IsValid() && d.prePersist(ctx, &pollOption) {
+				mu.Lock()
+				pollOptions = append(pollOptions, pollOption)
+				pollOptionsIDs = append(pollOptionsIDs, pollOption.ID)
+				mu.Unlock()
+			}
+		}
+	})
 
-	wg.Wait()
+	tracing.Logf(ctx, "%d Items already Exists", len(IDsExistsCount))
 
 	// Save to database concurrently
+	d.awaitReadCapacity(ctx)
 	var saveWg sync.WaitGroup
 
 	// Save stories
@@ -491,16 +666,23 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range stories {
 				storyPtrs[i] = &stories[i]
 			}
-			err = storyRepo.CreateBatchWithExistingIDs(ctx, storyPtrs)
+			counts, err := storyRepo.UpsertBatch(ctx, storyPtrs)
+			outcomes.save("story", storiesIDs, err)
 			if err != nil {
-				log.Printf("Error saving stories: %v", err)
+				tracing.Logf(ctx, "Error saving stories: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("StoriesTopic", storiesIDs); err != nil {
-					log.Printf("Error sending stories to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved stories: %s", counts)
+				postPersistAll(ctx, d, storyPtrs)
+				d.indexSaved(ctx, "stories", func() error { return d.indexer.IndexStories(ctx, storyPtrs) })
+				d.invalidateItems(ctx, "story", storiesIDs)
+				d.scoreStories(ctx, storyPtrs)
+				d.rankStories(ctx, storyPtrs)
+				err = d.publishItemIDs(ctx, "StoriesTopic", storiesIDs)
+				outcomes.record("story", stagePublish, len(storiesIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending stories to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d stories to Kafka", len(stories))
-					redis.CacheID(ctx, itemsRedisKey, storiesIDs)
-					log.Printf("---------------Cached %d stories to Redis---------------", len(stories))
+					tracing.Logf(ctx, "Sent %d stories to the event bus", len(stories))
 				}
 			}
 		}()
@@ -515,16 +697,22 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range asks {
 				askPtrs[i] = &asks[i]
 			}
-			err = askRepo.CreateBatchWithExistingIDs(ctx, askPtrs)
+			counts, err := askRepo.UpsertBatch(ctx, askPtrs)
+			outcomes.save("ask", asksIDs, err)
 			if err != nil {
-				log.Printf("Error saving asks: %v", err)
+				tracing.Logf(ctx, "Error saving asks: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("AsksTopic", asksIDs); err != nil {
-					log.Printf("Error sending asks to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved asks: %s", counts)
+				postPersistAll(ctx, d, askPtrs)
+				d.indexSaved(ctx, "asks", func() error { return d.indexer.IndexAsks(ctx, askPtrs) })
+				d.invalidateItems(ctx, "ask", asksIDs)
+				d.scoreAsks(ctx, askPtrs)
+				err = d.publishItemIDs(ctx, "AsksTopic", asksIDs)
+				outcomes.record("ask", stagePublish, len(asksIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending asks to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d asks to Kafka", len(asks))
-					redis.CacheID(ctx, itemsRedisKey, asksIDs)
-					log.Printf("---------------Cached %d asks to Redis---------------", len(asks))
+					tracing.Logf(ctx, "Sent %d asks to the event bus", len(asks))
 				}
 			}
 		}()
@@ -539,16 +727,22 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range comments {
 				commentPtrs[i] = &comments[i]
 			}
-			err = commentRepo.CreateBatchWithExistingIDs(ctx, commentPtrs)
+			counts, err := commentRepo.UpsertBatch(ctx, commentPtrs)
+			outcomes.save("comment", commentsIDs, err)
 			if err != nil {
-				log.Printf("Error saving comments: %v", err)
+				tracing.Logf(ctx, "Error saving comments: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("CommentsTopic", commentsIDs); err != nil {
-					log.Printf("Error sending comments to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved comments: %s", counts)
+				postPersistAll(ctx, d, commentPtrs)
+				d.indexSaved(ctx, "comments", func() error { return d.indexer.IndexComments(ctx, commentPtrs) })
+				d.invalidateItems(ctx, "comment", commentsIDs)
+				d.scoreComments(ctx, commentPtrs)
+				err = d.publishItemIDs(ctx, "CommentsTopic", commentsIDs)
+				outcomes.record("comment", stagePublish, len(commentsIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending comments to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d comments to Kafka", len(comments))
-					redis.CacheID(ctx, itemsRedisKey, commentsIDs)
-					log.Printf("---------------Cached %d comments to Redis---------------", len(comments))
+					tracing.Logf(ctx, "Sent %d comments to the event bus", len(comments))
 				}
 			}
 		}()
@@ -563,16 +757,21 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range jobs {
 				jobPtrs[i] = &jobs[i]
 			}
-			err = jobRepo.CreateBatchWithExistingIDs(ctx, jobPtrs)
+			counts, err := jobRepo.UpsertBatch(ctx, jobPtrs)
+			outcomes.save("job", jobsIDs, err)
 			if err != nil {
-				log.Printf("Error saving jobs: %v", err)
+				tracing.Logf(ctx, "Error saving jobs: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("JobsTopic", jobsIDs); err != nil {
-					log.Printf("Error sending jobs to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved jobs: %s", counts)
+				postPersistAll(ctx, d, jobPtrs)
+				d.indexSaved(ctx, "jobs", func() error { return d.indexer.IndexJobs(ctx, jobPtrs) })
+				d.invalidateItems(ctx, "job", jobsIDs)
+				err = d.publishItemIDs(ctx, "JobsTopic", jobsIDs)
+				outcomes.record("job", stagePublish, len(jobsIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending jobs to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d jobs to Kafka", len(jobs))
-					redis.CacheID(ctx, itemsRedisKey, jobsIDs)
-					log.Printf("---------------Cached %d jobs to Redis---------------", len(jobs))
+					tracing.Logf(ctx, "Sent %d jobs to the event bus", len(jobs))
 				}
 			}
 		}()
@@ -587,16 +786,21 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range polls {
 				pollPtrs[i] = &polls[i]
 			}
-			err = pollRepo.CreateBatchWithExistingIDs(ctx, pollPtrs)
+			counts, err := pollRepo.UpsertBatch(ctx, pollPtrs)
+			outcomes.save("poll", pollsIDs, err)
 			if err != nil {
-				log.Printf("Error saving polls: %v", err)
+				tracing.Logf(ctx, "Error saving polls: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("PollsTopic", pollsIDs); err != nil {
-					log.Printf("Error sending polls to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved polls: %s", counts)
+				postPersistAll(ctx, d, pollPtrs)
+				d.indexSaved(ctx, "polls", func() error { return d.indexer.IndexPolls(ctx, pollPtrs) })
+				d.invalidateItems(ctx, "poll", pollsIDs)
+				err = d.publishItemIDs(ctx, "PollsTopic", pollsIDs)
+				outcomes.record("poll", stagePublish, len(pollsIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending polls to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d polls to Kafka", len(polls))
-					redis.CacheID(ctx, itemsRedisKey, pollsIDs)
-					log.Printf("---------------Cached %d polls to Redis---------------", len(polls))
+					tracing.Logf(ctx, "Sent %d polls to the event bus", len(polls))
 				}
 			}
 		}()
@@ -611,53 +815,108 @@ func (d *DataSyncService) syncUpdates() {
 			for i := range pollOptions {
 				pollOptionPtrs[i] = &pollOptions[i]
 			}
-			err = pollOptionRepo.CreateBatchWithExistingIDs(ctx, pollOptionPtrs)
+			counts, err := pollOptionRepo.UpsertBatch(ctx, pollOptionPtrs)
+			outcomes.save("pollopt", pollOptionsIDs, err)
 			if err != nil {
-				log.Printf("Error saving poll options: %v", err)
+				tracing.Logf(ctx, "Error saving poll options: %v", err)
 			} else {
-				if err := kafka.NewItemProducer("PollOptionsTopic", pollOptionsIDs); err != nil {
-					log.Printf("Error sending poll options to Kafka: %v", err)
+				tracing.Logf(ctx, "Saved poll options: %s", counts)
+				postPersistAll(ctx, d, pollOptionPtrs)
+				d.invalidateItems(ctx, "pollopt", pollOptionsIDs)
+				err = d.publishItemIDs(ctx, "PollOptionsTopic", pollOptionsIDs)
+				outcomes.record("pollopt", stagePublish, len(pollOptionsIDs), err)
+				if err != nil {
+					tracing.Logf(ctx, "Error sending poll options to the event bus: %v", err)
 				} else {
-					log.Printf("Sent %d poll options to Kafka", len(pollOptions))
-					redis.CacheID(ctx, itemsRedisKey, pollOptionsIDs)
-					log.Printf("---------------Cached %d poll options to Redis---------------", len(pollOptions))
+					tracing.Logf(ctx, "Sent %d poll options to the event bus", len(pollOptions))
 				}
 			}
 		}()
 	}
 
-	// Save users
-	if len(users) > 0 {
-		saveWg.Add(1)
-		go func() {
-			defer saveWg.Done()
-			userPtrs := make([]*models.User, len(users))
-			for i := range users {
-				userPtrs[i] = &users[i]
-			}
-			err = userRepo.CreateBatchWithExistingIDs(ctx, userPtrs)
-			if err != nil {
-				log.Printf("Error saving users: %v", err)
-			} else {
-				if err := kafka.NewUserIDProducer("UsersTopic", userIDs); err != nil {
-					log.Printf("Error sending users to Kafka: %v", err)
-				} else {
-					log.Printf("Sent %d users to Kafka", len(users))
-					redis.CacheUserIDs(ctx, userRedisKey, userIDs)
-					log.Printf("---------------Cached %d users to Redis---------------", len(users))
-				}
-			}
-		}()
-	}
+	// Save the changed profiles
+	var profiles int
+	saveWg.Add(1)
+	go func() {
+		defer saveWg.Done()
+		profiles = d.syncProfiles(ctx, update.Usernames())
+	}()
 
 	saveWg.Wait()
 
-	log.Printf("Update sync completed - Stories: %d, Asks: %d, Comments: %d, Jobs: %d, Polls: %d, Poll Options: %d, Users: %d",
-		len(stories), len(asks), len(comments), len(jobs), len(polls), len(pollOptions), len(users))
+	// The stored items are cached whether or not their events went out, so a failing event bus
+	// does not have them fetched again on every run; the items found cached stay cached
+	saved := outcomes.savedIDs()
+	cached := IDsExistsCount
+	for _, ids := range saved {
+		cached = append(cached, ids...)
+	}
+	if len(cached) > 0 {
+		err := d.cache.CacheID(ctx, itemsRedisKey, cached)
+		if err != nil {
+			tracing.Logf(ctx, "Error caching the IDs of %d items: %v", len(cached), err)
+		}
+		for kind, ids := range saved {
+			outcomes.record(kind, stageCache, len(ids), err)
+		}
+	}
+
+	for reason, ids := range removed {
+		d.removeItems(ctx, ids, reason)
+	}
+
+	tracing.Logf(ctx, "Update sync outcomes: %s", outcomes)
+	tracing.Logf(ctx, "Update sync completed - Stories: %d, Asks: %d, Comments: %d, Jobs: %d, Polls: %d, Poll Options: %d, Users: %d",
+		len(stories), len(asks), len(comments), len(jobs), len(polls), len(pollOptions), profiles)
+}
+
+func (d *DataSyncService) syncItemsFromMaxTo(ctx context.Context, items int, minusMaxItem int) {
+	maxItem, err := d.apiClient.GetMaxItemID()
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching max item ID: %v", err)
+		return
+	}
+
+	maxItem -= minusMaxItem
+	tracing.Logf(ctx, "Max item ID is %d, starting sync from %d to %d", maxItem+minusMaxItem, maxItem-items+1, maxItem)
+	d.syncItemRange(ctx, maxItem-items+1, maxItem)
+}
+
+// syncItemRange fetches and persists every item with from <= ID <= to, newest first
+func (d *DataSyncService) syncItemRange(ctx context.Context, from, to int) {
+	d.syncItemRangeThrottled(ctx, from, to, 0)
+}
+
+// syncItemRangeThrottled is syncItemRange with a pause between batches to limit the API request rate
+func (d *DataSyncService) syncItemRangeThrottled(ctx context.Context, from, to int, batchDelay time.Duration) {
+	if to < from {
+		return
+	}
+	ids := make([]int, 0, to-from+1)
+	for id := to; id >= from; id-- {
+		ids = append(ids, id)
+	}
+	tracing.Logf(ctx, "Starting sync for %d items (%d-%d)...", len(ids), from, to)
+	d.syncItemIDs(ctx, ids, batchDelay)
+}
+
+// syncItemIDs fetches and persists the items in batches of 100, in the order given, pausing
+// batchDelay between batches
+func (d *DataSyncService) syncItemIDs(ctx context.Context, ids []int, batchDelay time.Duration) {
+	d.persistItems(ctx, ids, d.apiClient.GetHNItem, batchDelay)
 }
 
-func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
-	ctx := context.Background()
+// persistItems gets the items with get in batches of 100 and persists them like syncItemIDs
+func (d *DataSyncService) persistItems(
+	ctx context.Context,
+	allIDs []int,
+	get func(ctx context.Context, id int) (*models.HNItem, error),
+	batchDelay time.Duration,
+) {
+	items := len(allIDs)
+	if items == 0 {
+		return
+	}
 
 	// Initialize repositories
 	storyRepo := postgres.NewStoryRepository()
@@ -674,17 +933,7 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 	var jobs []models.Job
 	var polls []models.Poll
 	var pollOptions []models.PollOption
-
-	log.Printf("Starting sync for %d items...", items)
-
-	maxItem, err := d.apiClient.GetMaxItemID()
-	if err != nil {
-		log.Printf("Error fetching max item ID: %v", err)
-		return
-	}
-
-	maxItem -= minusMaxItem
-	log.Printf("Max item ID is %d, starting sync from %d to %d", maxItem+minusMaxItem, maxItem-items+1, maxItem)
+	removed := make(map[string][]int) // IDs of deleted and dead items by reason
 
 	// Process in batches of 100
 	batchSize := 100
@@ -698,82 +947,105 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		var mu sync.Mutex
 
 		// Process batch concurrently
-		for i := batch; i < end; i++ {
+		for _, itemID := range d.skipGoneItems(ctx, allIDs[batch:end]) {
 			wg.Add(1)
 			go func(itemID int) {
 				defer wg.Done()
 
-				var rawItem map[string]interface{}
-				err := d.apiClient.GetItem(ctx, itemID, &rawItem)
+				rawItem, err := get(ctx, itemID)
+				if errors.Is(err, services.ErrItemGone) {
+					mu.Lock()
+					removed[models.TombstoneGone] = append(removed[models.TombstoneGone], itemID)
+					mu.Unlock()
+					return
+				}
 				if err != nil {
 					return
 				}
 
-				itemType, ok := rawItem["type"].(string)
-				if !ok {
+				if reason := rawItem.TombstoneReason(); reason != "" {
+					mu.Lock()
+					removed[reason] = append(removed[reason], itemID)
+					mu.Unlock()
+					return
+				}
+
+				itemType := rawItem.Kind()
+				if itemType == "" {
 					return
 				}
 
 				switch itemType {
 				case "story":
-					var story models.Story
-					if err := d.apiClient.GetItem(ctx, itemID, &story); err == nil && story.IsValid() {
+					story := *rawItem.ToStory()
+					if story.IsValid() && d.prePersist(ctx, &story) {
 						mu.Lock()
 						stories = append(stories, story)
 						mu.Unlock()
 					}
 				case "ask":
-					var ask models.Ask
-					if err := d.apiClient.GetItem(ctx, itemID, &ask); err == nil && ask.IsValid() {
+					ask := *rawItem.ToAsk()
+					if ask.IsValid() && d.prePersist(ctx, &ask) {
 						mu.Lock()
 						asks = append(asks, ask)
 						mu.Unlock()
 					}
 				case "comment":
-					var comment models.Comment
-					if err := d.apiClient.GetItem(ctx, itemID, &comment); err == nil && comment.IsValid() {
+					comment := *rawItem.ToComment()
+					if comment.IsValid() && d.prePersist(ctx, &comment) {
 						mu.Lock()
 						comments = append(comments, comment)
 						mu.Unlock()
 					}
 				case "job":
-					var job models.Job
-					if err := d.apiClient.GetItem(ctx, itemID, &job); err == nil && job.IsValid() {
+					job := *rawItem.ToJob()
+					if job.IsValid() && d.prePersist(ctx, &job) {
 						mu.Lock()
 						jobs = append(jobs, job)
 						mu.Unlock()
 					}
 				case "poll":
-					var poll models.Poll
-					if err := d.apiClient.GetItem(ctx, itemID, &poll); err == nil && poll.IsValid() {
+					poll := *rawItem.ToPoll()
+					if poll.IsValid() && d.prePersist(ctx, &poll) {
 						mu.Lock()
 						polls = append(polls, poll)
 						mu.Unlock()
 					}
 				case "pollopt":
-					var pollOption models.PollOption
-					if err := d.apiClient.GetItem(ctx, itemID, &pollOption); err == nil && pollOption.IsValid() {
+					pollOption := *rawItem.ToPollOption()
+					if pollOption.IsValid() && d.prePersist(ctx, &pollOption) {
 						mu.Lock()
 						pollOptions = append(pollOptions, pollOption)
 						mu.Unlock()
 					}
 				}
-			}(maxItem - i)
+			}(itemID)
 		}
 
 		wg.Wait()
-		log.Printf("Processed batch %d-%d", batch, end)
+		tracing.Logf(ctx, "Processed batch %d-%d", batch, end)
+
+		if batchDelay > 0 && end < items {
+			time.Sleep(batchDelay)
+		}
 	}
 
 	// Save to database
+	d.awaitReadCapacity(ctx)
+	var saved repository.UpsertCounts
 	if len(stories) > 0 {
 		storyPtrs := make([]*models.Story, len(stories))
 		for i := range stories {
 			storyPtrs[i] = &stories[i]
 		}
-		err = storyRepo.CreateBatchWithExistingIDs(ctx, storyPtrs)
+		counts, err := storyRepo.UpsertBatch(ctx, storyPtrs)
 		if err != nil {
-			log.Printf("Error saving stories: %v", err)
+			tracing.Logf(ctx, "Error saving stories: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, storyPtrs)
+			d.rankStories(ctx, storyPtrs)
+			d.invalidateItems(ctx, "story", itemIDs(storyPtrs, func(s *models.Story) int { return s.ID }))
 		}
 	}
 
@@ -782,9 +1054,13 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		for i := range asks {
 			askPtrs[i] = &asks[i]
 		}
-		err = askRepo.CreateBatchWithExistingIDs(ctx, askPtrs)
+		counts, err := askRepo.UpsertBatch(ctx, askPtrs)
 		if err != nil {
-			log.Printf("Error saving asks: %v", err)
+			tracing.Logf(ctx, "Error saving asks: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, askPtrs)
+			d.invalidateItems(ctx, "ask", itemIDs(askPtrs, func(a *models.Ask) int { return a.ID }))
 		}
 	}
 
@@ -793,9 +1069,13 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		for i := range comments {
 			commentPtrs[i] = &comments[i]
 		}
-		err = commentRepo.CreateBatchWithExistingIDs(ctx, commentPtrs)
+		counts, err := commentRepo.UpsertBatch(ctx, commentPtrs)
 		if err != nil {
-			log.Printf("Error saving comments: %v", err)
+			tracing.Logf(ctx, "Error saving comments: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, commentPtrs)
+			d.invalidateItems(ctx, "comment", itemIDs(commentPtrs, func(c *models.Comment) int { return c.ID }))
 		}
 	}
 
@@ -804,9 +1084,13 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		for i := range jobs {
 			jobPtrs[i] = &jobs[i]
 		}
-		err = jobRepo.CreateBatchWithExistingIDs(ctx, jobPtrs)
+		counts, err := jobRepo.UpsertBatch(ctx, jobPtrs)
 		if err != nil {
-			log.Printf("Error saving jobs: %v", err)
+			tracing.Logf(ctx, "Error saving jobs: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, jobPtrs)
+			d.invalidateItems(ctx, "job", itemIDs(jobPtrs, func(j *models.Job) int { return j.ID }))
 		}
 	}
 
@@ -815,9 +1099,13 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		for i := range polls {
 			pollPtrs[i] = &polls[i]
 		}
-		err = pollRepo.CreateBatchWithExistingIDs(ctx, pollPtrs)
+		counts, err := pollRepo.UpsertBatch(ctx, pollPtrs)
 		if err != nil {
-			log.Printf("Error saving polls: %v", err)
+			tracing.Logf(ctx, "Error saving polls: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, pollPtrs)
+			d.invalidateItems(ctx, "poll", itemIDs(pollPtrs, func(p *models.Poll) int { return p.ID }))
 		}
 	}
 
@@ -826,12 +1114,20 @@ func (d *DataSyncService) syncItemsFromMaxTo(items int, minusMaxItem int) {
 		for i := range pollOptions {
 			pollOptionPtrs[i] = &pollOptions[i]
 		}
-		err = pollOptionRepo.CreateBatchWithExistingIDs(ctx, pollOptionPtrs)
+		counts, err := pollOptionRepo.UpsertBatch(ctx, pollOptionPtrs)
 		if err != nil {
-			log.Printf("Error saving poll options: %v", err)
+			tracing.Logf(ctx, "Error saving poll options: %v", err)
+		} else {
+			saved.Add(counts)
+			postPersistAll(ctx, d, pollOptionPtrs)
+			d.invalidateItems(ctx, "pollopt", itemIDs(pollOptionPtrs, func(o *models.PollOption) int { return o.ID }))
 		}
 	}
 
-	log.Printf("Sync completed - Stories: %d, Asks: %d, Comments: %d, Jobs: %d, Polls: %d, Poll Options: %d",
-		len(stories), len(asks), len(comments), len(jobs), len(polls), len(pollOptions))
+	for reason, ids := range removed {
+		d.removeItems(ctx, ids, reason)
+	}
+
+	tracing.Logf(ctx, "Sync completed - Stories: %d, Asks: %d, Comments: %d, Jobs: %d, Polls: %d, Poll Options: %d (%s)",
+		len(stories), len(asks), len(comments), len(jobs), len(polls), len(pollOptions), saved)
 }
@@ -0,0 +1,63 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// refreshDomains recomputes the per-domain story aggregates. With DOMAIN_FAVICONS_ENABLED it then
+// looks up the favicons of up to DOMAIN_FAVICON_BATCH domains not looked up within
+// DOMAIN_FAVICON_MAX_AGE, at most LINK_PREVIEW_RATE sites a second.
+func (d *DataSyncService) refreshDomains(ctx context.Context) {
+	repo := postgres.NewDomainRepository()
+	if err := repo.RefreshStats(ctx); err != nil {
+		tracing.Logf(ctx, "Error refreshing domain stats: %v", err)
+		return
+	}
+	if !config.GetEnvBool("DOMAIN_FAVICONS_ENABLED", false) {
+		return
+	}
+
+	maxAge := config.GetEnvDuration("DOMAIN_FAVICON_MAX_AGE", 30*24*time.Hour)
+	domains, err := repo.GetFaviconsToCheck(ctx, d.clock.Now().Add(-maxAge).Unix(), config.GetEnvInt("DOMAIN_FAVICON_BATCH", 100))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading domains to look up favicons of: %v", err)
+		return
+	}
+
+	previewer := services.NewLinkPreviewer()
+	rate := max(config.GetEnvFloat("LINK_PREVIEW_RATE", 2), 0.01)
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer limiter.Stop()
+
+	found := 0
+	for _, domain := range domains {
+		select {
+		case <-ctx.Done():
+			tracing.Logf(ctx, "Favicon lookup interrupted: %v", ctx.Err())
+			return
+		case <-limiter.C:
+		}
+		// A failed lookup is retried after DOMAIN_FAVICON_MAX_AGE like the others, keeping the
+		// favicon found before
+		favicon, err := previewer.Favicon(ctx, "https://"+domain+"/")
+		if err != nil {
+			tracing.Logf(ctx, "Error looking up the favicon of %s: %v", domain, err)
+		}
+		if err := repo.SetFavicon(ctx, domain, favicon, d.clock.Now().Unix()); err != nil {
+			tracing.Logf(ctx, "Error saving the favicon of %s: %v", domain, err)
+			return
+		}
+		if favicon != "" {
+			found++
+		}
+	}
+	if len(domains) > 0 {
+		tracing.Logf(ctx, "Looked up %d domain favicons, %d found", len(domains), found)
+	}
+}
@@ -0,0 +1,24 @@
+package cronjob
+
+import (
+	"context"
+	"strconv"
+)
+
+// publishItemIDs announces saved items on the event bus, one message per item ID
+func (d *DataSyncService) publishItemIDs(ctx context.Context, topic string, ids []int) error {
+	values := make([][]byte, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.AppendInt(nil, int64(id), 10)
+	}
+	return d.publisher.Publish(ctx, topic, values...)
+}
+
+// publishUserIDs announces saved users on the event bus, one message per username
+func (d *DataSyncService) publishUserIDs(ctx context.Context, topic string, ids []string) error {
+	values := make([][]byte, len(ids))
+	for i, id := range ids {
+		values[i] = []byte(id)
+	}
+	return d.publisher.Publish(ctx, topic, values...)
+}
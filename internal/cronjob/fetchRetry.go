@@ -0,0 +1,50 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// fetchWithRetry fetches ids and retries the failed ones once, but those rejected by schema
+// validation and those the API served null for.
+// Partial failures are logged and metered; only non-item errors are returned.
+func fetchWithRetry[T any](
+	ctx context.Context,
+	kind string,
+	ids []int,
+	fetch func(ctx context.Context, ids []int, opts ...services.FetchOption) ([]*T, error),
+) ([]*T, error) {
+	items, err := fetch(ctx, ids)
+
+	var multiErr *services.MultiError
+	if !errors.As(err, &multiErr) {
+		return items, err
+	}
+
+	// Payloads violating their schema fail the same way until the upstream API changes again,
+	// and null ones are recorded as gone for the syncs to stop asking for them
+	var retry []int
+	for _, id := range multiErr.IDs() {
+		err := multiErr.Errors[id]
+		if !errors.Is(err, services.ErrSchemaViolation) && !errors.Is(err, services.ErrItemGone) {
+			retry = append(retry, id)
+		}
+	}
+	if len(retry) == 0 {
+		tracing.Logf(ctx, "Rejected %d of %d %s failing schema validation or gone: %v", multiErr.Failed(), len(ids), kind, multiErr)
+		return items, nil
+	}
+
+	tracing.Logf(ctx, "Failed to fetch %d of %d %s, retrying %d: %v", multiErr.Failed(), len(ids), kind, len(retry), multiErr)
+	retried, err := fetch(ctx, retry)
+	items = append(items, retried...)
+
+	if errors.As(err, &multiErr) {
+		tracing.Logf(ctx, "%d %s still failing after retry (IDs: %v)", multiErr.Failed(), kind, multiErr.IDs())
+		return items, nil
+	}
+	return items, err
+}
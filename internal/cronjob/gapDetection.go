@@ -0,0 +1,95 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/tracing"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// maxItemCheckpointKey is the Redis key holding the last observed HN maxitem
+const maxItemCheckpointKey = "sync:maxitem"
+
+// maxItemCheckpoint records the HN maxitem seen by an updates run
+type maxItemCheckpoint struct {
+	MaxItem int   `json:"max_item"`
+	SeenAt  int64 `json:"seen_at"`
+}
+
+// detectUpdateGap compares the current maxitem with the one recorded by the previous
+// updates run. If that run is older than UPDATE_GAP_THRESHOLD (the service was down),
+// items created in between never showed up in the updates feed, so a backfill
+// task is scheduled for the missed ID range.
+func (d *DataSyncService) detectUpdateGap(ctx context.Context) {
+	current, err := d.apiClient.GetMaxItemID()
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching max item ID for gap detection: %v", err)
+		return
+	}
+
+	var last maxItemCheckpoint
+	found, err := d.cache.GetCachedJSON(ctx, maxItemCheckpointKey, &last)
+	if err != nil {
+		tracing.Logf(ctx, "Error reading maxitem checkpoint: %v", err)
+		return
+	}
+
+	now := d.clock.Now()
+	checkpoint := maxItemCheckpoint{MaxItem: current, SeenAt: now.Unix()}
+	if err := d.cache.CacheJSON(ctx, maxItemCheckpointKey, checkpoint, 0); err != nil {
+		tracing.Logf(ctx, "Error saving maxitem checkpoint: %v", err)
+	}
+
+	if !found || current <= last.MaxItem {
+		return
+	}
+
+	threshold := config.GetEnvDuration("UPDATE_GAP_THRESHOLD", 5*time.Minute)
+	downtime := now.Sub(time.Unix(last.SeenAt, 0))
+	if downtime < threshold {
+		return
+	}
+
+	from := last.MaxItem + 1
+	maxBackfill := config.GetEnvInt("UPDATE_GAP_MAX_ITEMS", 50000)
+	if current-from+1 > maxBackfill {
+		tracing.Logf(ctx, "Gap of %d items exceeds UPDATE_GAP_MAX_ITEMS, backfilling only the newest %d",
+			current-from+1, maxBackfill)
+		from = current - maxBackfill + 1
+	}
+
+	tracing.Logf(ctx, "Detected update gap after %v of downtime: items %d-%d", downtime.Round(time.Second), from, current)
+	if err := d.scheduleBackfill(ctx, from, current); err != nil {
+		tracing.Logf(ctx, "Error scheduling gap backfill: %v", err)
+	}
+}
+
+// scheduleBackfill queues a "backfill" task for the range when a task worker is registered, so
+// it survives restarts and is retried, and runs syncItemRange as a one-time job otherwise
+func (d *DataSyncService) scheduleBackfill(ctx context.Context, from, to int) error {
+	if worker := d.tasks.Load(); worker != nil {
+		task, err := worker.Enqueue(ctx, "backfill", backfillTask{From: from, To: to}, 0)
+		if err != nil {
+			return fmt.Errorf("failed to queue backfill %d-%d: %w", from, to, err)
+		}
+		tracing.Logf(ctx, "Queued backfill task %s: %d-%d", task.ID, from, to)
+		return nil
+	}
+
+	name := fmt.Sprintf("backfill-%d-%d", from, to)
+	_, err := d.scheduler.NewJob(
+		gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()),
+		gocron.NewTask(d.tracedRun(name, func(ctx context.Context) { d.syncItemRange(ctx, from, to) })),
+		gocron.WithName(name),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job %s: %w", name, err)
+	}
+	log.Printf("Scheduled backfill job: %s", name)
+	return nil
+}
@@ -0,0 +1,30 @@
+package cronjob
+
+import (
+	"context"
+	"slices"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// skipGoneItems leaves out the IDs the API served null for GONE_ITEM_MAX_ATTEMPTS times already.
+// A fresh ID can be served null until its item is published, so it gets a few attempts before
+// the syncs stop asking for it.
+func (d *DataSyncService) skipGoneItems(ctx context.Context, ids []int) []int {
+	attempts := max(config.GetEnvInt("GONE_ITEM_MAX_ATTEMPTS", 3), 1)
+	gone, err := postgres.NewDeadLetterRepository().GetGone(ctx, ids, attempts)
+	if err != nil {
+		tracing.Logf(ctx, "Error loading gone item IDs: %v", err)
+		return ids
+	}
+	if len(gone) == 0 {
+		return ids
+	}
+
+	tracing.Logf(ctx, "Skipping %d item IDs served null %d times already", len(gone), attempts)
+	return slices.DeleteFunc(slices.Clone(ids), func(id int) bool {
+		return slices.Contains(gone, id)
+	})
+}
@@ -0,0 +1,64 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/tracing"
+)
+
+// defaultHotItemTTL outlives the story sync interval so hot items never fall out between runs
+const defaultHotItemTTL = 90 * time.Minute
+
+// cacheHotStories writes the full JSON of freshly synced front-page stories to Redis
+func (d *DataSyncService) cacheHotStories(ctx context.Context, stories []*models.Story) {
+	if len(stories) == 0 {
+		return
+	}
+
+	values := make(map[string]interface{}, len(stories))
+	for _, story := range stories {
+		values[redis.ItemKey("story", story.ID)] = story
+	}
+
+	ttl := config.GetEnvDuration("HOT_ITEM_TTL", defaultHotItemTTL)
+	if err := d.cache.CacheJSONBatch(ctx, values, ttl); err != nil {
+		tracing.Logf(ctx, "Error caching hot stories: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Cached %d hot stories", len(stories))
+}
+
+// cacheHotComments writes the first HOT_COMMENTS_PER_STORY top-level comments of each story to Redis
+func (d *DataSyncService) cacheHotComments(ctx context.Context, stories []*models.Story, comments []*models.Comment) {
+	byID := make(map[int]*models.Comment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	perStory := config.GetEnvInt("HOT_COMMENTS_PER_STORY", 10)
+	values := make(map[string]interface{})
+	for _, story := range stories {
+		for i, id := range story.Comments_ids {
+			if i >= perStory {
+				break
+			}
+			if comment, ok := byID[id]; ok {
+				values[redis.ItemKey("comment", id)] = comment
+			}
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	ttl := config.GetEnvDuration("HOT_ITEM_TTL", defaultHotItemTTL)
+	if err := d.cache.CacheJSONBatch(ctx, values, ttl); err != nil {
+		tracing.Logf(ctx, "Error caching hot comments: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Cached %d hot comments", len(values))
+}
@@ -0,0 +1,31 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/opensearch"
+	"internship-project/internal/tracing"
+)
+
+// newInlineIndexer returns the indexer the sync sends its saved batches to: nil unless
+// OPENSEARCH_INDEXER_ENABLED is set, and nil with INDEXER_CONSUMERS_ENABLED, where the indexer
+// consumers index the published items instead, so they are not indexed twice
+func newInlineIndexer() *opensearch.IndexerService {
+	if config.GetEnvBool("INDEXER_CONSUMERS_ENABLED", false) {
+		return nil
+	}
+	return opensearch.NewIndexerService()
+}
+
+// indexSaved runs index, which sends a saved batch of what to the search indexer, when the
+// indexer is enabled. A failure is only logged: the items are stored and reach the index on
+// their next save or a reindex.
+func (d *DataSyncService) indexSaved(ctx context.Context, what string, index func() error) {
+	if d.indexer == nil {
+		return
+	}
+	if err := index(); err != nil {
+		tracing.Logf(ctx, "Error indexing %s: %v", what, err)
+	}
+}
@@ -0,0 +1,35 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/redis"
+	"internship-project/internal/tracing"
+)
+
+// invalidateItems tells every API node that the cached copies of the upserted items are stale,
+// and records them as known items
+func (d *DataSyncService) invalidateItems(ctx context.Context, kind string, ids []int) {
+	if len(ids) == 0 {
+		return
+	}
+	bloom.KnownItems().Add(ids...)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = redis.ItemKey(kind, id)
+	}
+	if err := d.cache.PublishInvalidation(ctx, keys...); err != nil {
+		tracing.Logf(ctx, "Error invalidating cached %s items: %v", kind, err)
+	}
+}
+
+// itemIDs collects the IDs of a batch of items
+func itemIDs[T any](items []*T, id func(*T) int) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = id(item)
+	}
+	return ids
+}
@@ -0,0 +1,27 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// tracedRun wraps a job task so every run gets its own run ID, carried by the
+// task context and prefixed to its log lines like API request IDs. Finished runs
+// are recorded in job_runs for the status command.
+func (d *DataSyncService) tracedRun(name string, task func(ctx context.Context)) func() {
+	return func() {
+		ctx := tracing.WithID(context.Background(), tracing.NewID())
+		start := d.clock.Now()
+		tracing.Logf(ctx, "Job %s started", name)
+		task(ctx)
+		duration := d.clock.Since(start)
+		tracing.Logf(ctx, "Job %s finished in %v", name, duration.Round(time.Millisecond))
+
+		if err := postgres.NewJobRunRepository().Record(ctx, name, start, duration); err != nil {
+			tracing.Logf(ctx, "Error recording the run of job %s: %v", name, err)
+		}
+	}
+}
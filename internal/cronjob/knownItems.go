@@ -0,0 +1,20 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// loadKnownItems builds the filter of known item IDs from Postgres, so the existence checks of
+// the sync and the API can skip Redis and the database for the IDs never stored
+func (d *DataSyncService) loadKnownItems(ctx context.Context) {
+	filter, err := bloom.KnownItems().Rebuild(ctx, postgres.NewItemRepository().ScanIDs)
+	if err != nil {
+		tracing.Logf(ctx, "Error loading known item IDs, existence checks stay unfiltered: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Loaded %d known item IDs into a %d KB filter", filter.Added(), filter.Bytes()/1024)
+}
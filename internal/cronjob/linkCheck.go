@@ -0,0 +1,74 @@
+package cronjob
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// checkLinks checks up to LINK_CHECK_BATCH story URLs not checked within LINK_CHECK_MAX_AGE,
+// LINK_CHECK_CONCURRENCY at a time, and records which ones are dead. With
+// LINK_CHECK_ARCHIVE set, dead links also get their closest archive.org snapshot.
+func (d *DataSyncService) checkLinks(ctx context.Context) {
+	if !config.GetEnvBool("LINK_CHECK_ENABLED", false) {
+		return
+	}
+
+	repo := postgres.NewStoryRepository()
+	maxAge := config.GetEnvDuration("LINK_CHECK_MAX_AGE", 7*24*time.Hour)
+	links, err := repo.GetLinksToCheck(ctx, d.clock.Now().Add(-maxAge).Unix(), config.GetEnvInt("LINK_CHECK_BATCH", 200))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading story links to check: %v", err)
+		return
+	}
+	if len(links) == 0 {
+		return
+	}
+
+	checker := services.NewLinkChecker()
+	archive := config.GetEnvBool("LINK_CHECK_ARCHIVE", false)
+	sem := make(chan struct{}, max(config.GetEnvInt("LINK_CHECK_CONCURRENCY", 10), 1))
+
+	var wg sync.WaitGroup
+	for _, link := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link *models.LinkCheck) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			link.Status, link.StatusCode = checker.Check(ctx, link.URL)
+			link.CheckedAt = d.clock.Now().Unix()
+			if archive && link.Status == models.LinkDead {
+				snapshot, err := checker.ArchiveSnapshot(ctx, link.URL)
+				if err != nil {
+					tracing.Logf(ctx, "Error looking up archive snapshot of story %d: %v", link.StoryID, err)
+				}
+				link.ArchiveURL = snapshot
+			}
+		}(link)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		tracing.Logf(ctx, "Link check interrupted: %v", ctx.Err())
+		return
+	}
+	if err := repo.UpdateLinkChecks(ctx, links); err != nil {
+		tracing.Logf(ctx, "Error saving link checks: %v", err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, link := range links {
+		counts[link.Status]++
+	}
+	tracing.Logf(ctx, "Checked %d story links: %d alive, %d dead, %d unreachable",
+		len(links), counts[models.LinkAlive], counts[models.LinkDead], counts[models.LinkUnreachable])
+}
@@ -0,0 +1,86 @@
+package cronjob
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// fetchLinkPreviews fetches the preview metadata of up to LINK_PREVIEW_BATCH story URLs never
+// previewed or previewed more than LINK_PREVIEW_MAX_AGE ago (LINK_PREVIEW_RETRY_AFTER when the
+// fetch failed), LINK_PREVIEW_CONCURRENCY at a time and at most LINK_PREVIEW_RATE pages a second
+func (d *DataSyncService) fetchLinkPreviews(ctx context.Context) {
+	if !config.GetEnvBool("LINK_PREVIEW_ENABLED", false) {
+		return
+	}
+
+	repo := postgres.NewLinkPreviewRepository()
+	now := d.clock.Now()
+	maxAge := config.GetEnvDuration("LINK_PREVIEW_MAX_AGE", 30*24*time.Hour)
+	retryAfter := config.GetEnvDuration("LINK_PREVIEW_RETRY_AFTER", 6*time.Hour)
+	links, err := repo.GetLinksToPreview(ctx, now.Add(-maxAge).Unix(), now.Add(-retryAfter).Unix(),
+		config.GetEnvInt("LINK_PREVIEW_BATCH", 100))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading story links to preview: %v", err)
+		return
+	}
+	if len(links) == 0 {
+		return
+	}
+
+	previewer := services.NewLinkPreviewer()
+	rate := max(config.GetEnvFloat("LINK_PREVIEW_RATE", 2), 0.01)
+	limiter := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer limiter.Stop()
+	sem := make(chan struct{}, max(config.GetEnvInt("LINK_PREVIEW_CONCURRENCY", 4), 1))
+
+	var wg sync.WaitGroup
+	previews := make([]*models.LinkPreview, 0, len(links))
+	var mu sync.Mutex
+	for _, link := range links {
+		select {
+		case <-ctx.Done():
+		case <-limiter.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link *models.LinkPreview) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			preview := previewer.Preview(ctx, link.URL)
+			preview.StoryID = link.StoryID
+			if ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			previews = append(previews, preview)
+			mu.Unlock()
+		}(link)
+	}
+	wg.Wait()
+
+	// Previews fetched before an interruption are still worth keeping
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := repo.SavePreviews(saveCtx, previews); err != nil {
+		tracing.Logf(ctx, "Error saving link previews: %v", err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, preview := range previews {
+		counts[preview.Status]++
+	}
+	tracing.Logf(ctx, "Fetched %d story link previews: %d found, %d without metadata, %d failed",
+		len(previews), counts[models.PreviewFound], counts[models.PreviewNone], counts[models.PreviewFailed])
+}
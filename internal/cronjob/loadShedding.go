@@ -0,0 +1,19 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/tracing"
+)
+
+// awaitReadCapacity holds sync persistence back while API latency or database connection
+// use is over the load-shedding thresholds, so traffic spikes keep the read path responsive
+func (d *DataSyncService) awaitReadCapacity(ctx context.Context) {
+	if d.governor == nil || !config.GetEnvBool("LOAD_SHED_ENABLED", true) {
+		return
+	}
+	if waited := d.governor.Wait(ctx); waited > 0 {
+		tracing.Logf(ctx, "Load shedding: resuming writes after %v", waited)
+	}
+}
@@ -0,0 +1,45 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// maintainTables keeps the planner statistics of the MAINTENANCE_TABLES current between autovacuum
+// runs, which lag behind batch loads and never analyze partitioned tables. A table with at least
+// MAINTENANCE_VACUUM_DEAD_RATIO dead rows, and MAINTENANCE_VACUUM_MIN_DEAD_ROWS of them, is
+// vacuumed and analyzed; one with MAINTENANCE_ANALYZE_ROWS rows written since its last ANALYZE
+// is analyzed.
+func (d *DataSyncService) maintainTables(ctx context.Context) {
+	repo := postgres.NewMaintenanceRepository()
+	stats, err := repo.GetTableStats(ctx, config.GetEnvList("MAINTENANCE_TABLES", postgres.DefaultMaintainedTables))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading table statistics: %v", err)
+		return
+	}
+
+	analyzeRows := int64(config.GetEnvInt("MAINTENANCE_ANALYZE_ROWS", 50000))
+	deadRatio := config.GetEnvFloat("MAINTENANCE_VACUUM_DEAD_RATIO", 0.2)
+	minDead := int64(config.GetEnvInt("MAINTENANCE_VACUUM_MIN_DEAD_ROWS", 10000))
+	for _, t := range stats {
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case t.DeadRatio >= deadRatio && t.DeadRows >= minDead:
+			tracing.Logf(ctx, "Vacuuming %s: %d dead rows (%.0f%%), about %d bytes of bloat",
+				t.Table, t.DeadRows, t.DeadRatio*100, t.EstimatedBloatBytes)
+			if err := repo.Vacuum(ctx, t.Table); err != nil {
+				tracing.Logf(ctx, "Error vacuuming %s: %v", t.Table, err)
+			}
+		case t.ModifiedSinceAnalyze >= analyzeRows:
+			tracing.Logf(ctx, "Analyzing %s: %d rows written since its last analyze", t.Table, t.ModifiedSinceAnalyze)
+			if err := repo.Analyze(ctx, t.Table); err != nil {
+				tracing.Logf(ctx, "Error analyzing %s: %v", t.Table, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// partitionedTables are the tables partitioned by month of created_at that new items are written to
+var partitionedTables = []string{"stories", "comments"}
+
+// maintainPartitions creates the partitions of the current month and the PARTITIONS_AHEAD_MONTHS
+// following ones ahead of time, so live writes never wait on a partition being created
+func (d *DataSyncService) maintainPartitions(ctx context.Context) {
+	now := d.clock.Now()
+	ahead := max(config.GetEnvInt("PARTITIONS_AHEAD_MONTHS", 2), 0)
+	repo := postgres.NewPartitionRepository()
+	for _, table := range partitionedTables {
+		created, err := repo.EnsurePartitions(ctx, table, now.Unix(), now.AddDate(0, ahead, 0).Unix())
+		if err != nil {
+			tracing.Logf(ctx, "Error creating the partitions of %s: %v", table, err)
+			continue
+		}
+		if created > 0 {
+			tracing.Logf(ctx, "Created %d partitions of %s", created, table)
+		}
+	}
+}
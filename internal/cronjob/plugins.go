@@ -0,0 +1,40 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/tracing"
+)
+
+// AddPlugin registers an ETL plugin run around every item save; call it before Start
+func (d *DataSyncService) AddPlugin(plugin etl.Plugin) {
+	d.plugins.Register(plugin)
+}
+
+// prePersist runs the PrePersist hooks on one item and reports whether it should be saved
+func (d *DataSyncService) prePersist(ctx context.Context, item interface{}) bool {
+	if err := d.plugins.PrePersist(ctx, item); err != nil {
+		tracing.Logf(ctx, "Dropping item rejected by ETL plugin %v", err)
+		return false
+	}
+	return true
+}
+
+// prePersistAll runs the PrePersist hooks on a batch and returns the items to save
+func prePersistAll[T any](ctx context.Context, d *DataSyncService, items []*T) []*T {
+	kept := items[:0]
+	for _, item := range items {
+		if d.prePersist(ctx, item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// postPersistAll runs the PostPersist hooks on a saved batch
+func postPersistAll[T any](ctx context.Context, d *DataSyncService, items []*T) {
+	for _, item := range items {
+		d.plugins.PostPersist(ctx, item)
+	}
+}
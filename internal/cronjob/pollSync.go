@@ -0,0 +1,117 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// syncPolls looks for polls among the top, new and best stories, since HackerNews has no poll
+// list, and saves them with their options. At most POLL_SYNC_SCAN_LIMIT listed items are
+// fetched per run.
+func (d *DataSyncService) syncPolls(ctx context.Context) {
+	if !config.GetEnvBool("POLL_SYNC_ENABLED", true) {
+		return
+	}
+	tracing.Logln(ctx, "Starting poll sync...")
+
+	ids, err := d.listedStoryIDs(ctx, config.GetEnvInt("POLL_SYNC_SCAN_LIMIT", 500))
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching story lists: %v", err)
+		return
+	}
+
+	// Every listed item converts to a poll; only actual polls are valid
+	candidates, err := fetchWithRetry(ctx, "polls", ids, d.pollService.FetchMultiple)
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching listed items: %v", err)
+		return
+	}
+	var polls []*models.Poll
+	var optionIDs []int
+	for _, poll := range candidates {
+		if poll.IsValid() {
+			polls = append(polls, poll)
+			optionIDs = append(optionIDs, poll.PollOptions...)
+		}
+	}
+	if len(polls) == 0 {
+		tracing.Logf(ctx, "No polls among %d listed items", len(ids))
+		return
+	}
+
+	options, err := fetchWithRetry(ctx, "poll options", optionIDs, d.pollOptionService.FetchMultiple)
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching poll options: %v", err)
+		return
+	}
+	valid := options[:0]
+	for _, option := range options {
+		if option.IsValid() {
+			valid = append(valid, option)
+		}
+	}
+	options = valid
+
+	polls = prePersistAll(ctx, d, polls)
+	options = prePersistAll(ctx, d, options)
+	d.awaitReadCapacity(ctx)
+
+	pollCounts, err := postgres.NewPollRepository().UpsertBatch(ctx, polls)
+	if err != nil {
+		tracing.Logf(ctx, "Error saving polls: %v", err)
+		return
+	}
+	postPersistAll(ctx, d, polls)
+	pollIDs := itemIDs(polls, func(p *models.Poll) int { return p.ID })
+	d.invalidateItems(ctx, "poll", pollIDs)
+
+	optionCounts, err := postgres.NewPollOptionRepository().UpsertBatch(ctx, options)
+	if err != nil {
+		tracing.Logf(ctx, "Error saving poll options: %v", err)
+		return
+	}
+	postPersistAll(ctx, d, options)
+	optionIDs = itemIDs(options, func(o *models.PollOption) int { return o.ID })
+	d.invalidateItems(ctx, "pollopt", optionIDs)
+
+	if err := d.publishItemIDs(ctx, "PollsTopic", pollIDs); err != nil {
+		tracing.Logf(ctx, "Error sending polls to the event bus: %v", err)
+	}
+	if err := d.publishItemIDs(ctx, "PollOptionsTopic", optionIDs); err != nil {
+		tracing.Logf(ctx, "Error sending poll options to the event bus: %v", err)
+	}
+
+	tracing.Logf(ctx, "Poll sync saved %d polls (%s) and %d options (%s)",
+		len(polls), pollCounts, len(options), optionCounts)
+}
+
+// listedStoryIDs returns the distinct IDs of the top, new and best stories lists, in that order,
+// at most limit of them
+func (d *DataSyncService) listedStoryIDs(ctx context.Context, limit int) ([]int, error) {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, list := range []func(context.Context) ([]int, error){
+		d.storyService.FetchTopStories,
+		d.storyService.FetchNewStories,
+		d.storyService.FetchBestStories,
+	} {
+		listed, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range listed {
+			if len(ids) >= limit {
+				return ids, nil
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,95 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// syncProfiles refreshes the profiles listed by the updates feed and returns how many it saved.
+// Profiles fetched within PROFILE_CHECK_INTERVAL are skipped; the others are fetched in one batch
+// spread like the feed's items, and only the new ones and those whose karma, about or
+// submissions changed since the last check are saved and sent to the event bus, and their
+// submissions missing locally are queued for fetch.
+func (d *DataSyncService) syncProfiles(ctx context.Context, usernames []string) int {
+	if len(usernames) == 0 {
+		return 0
+	}
+
+	repo := postgres.NewUserRepository()
+	checks, err := repo.GetKarmaChecks(ctx, usernames)
+	if err != nil {
+		tracing.Logf(ctx, "Error loading profile checks, fetching every profile: %v", err)
+	}
+	now := d.clock.Now()
+	checkedSince := now.Add(-config.GetEnvDuration("PROFILE_CHECK_INTERVAL", 15*time.Minute)).Unix()
+	var due []string
+	for _, username := range usernames {
+		if check, ok := checks[username]; ok && check.Checked_At > checkedSince {
+			continue
+		}
+		due = append(due, username)
+	}
+	if len(due) == 0 {
+		tracing.Logf(ctx, "All %d updated profiles were checked recently", len(usernames))
+		return 0
+	}
+
+	fetched, errs := d.userService.FetchByUsernames(ctx, due, services.WithFetchOptions(services.UpdateFetchOptions()))
+	if len(errs) > 0 {
+		gone := 0
+		for _, err := range errs {
+			if errors.Is(err, services.ErrItemGone) {
+				gone++
+			}
+		}
+		tracing.Logf(ctx, "Failed to fetch %d of %d profiles (%d gone)", len(errs), len(due), gone)
+	}
+
+	var changed []*models.User
+	checked := make([]string, 0, len(fetched))
+	for _, user := range fetched {
+		checked = append(checked, user.Username)
+		if !user.IsValid() {
+			continue
+		}
+		if check, ok := checks[user.Username]; ok && !check.Changed(user) {
+			continue
+		}
+		changed = append(changed, user)
+	}
+	tracing.Logf(ctx, "%d of %d updated profiles skipped as checked recently, %d of %d fetched changed",
+		len(usernames)-len(due), len(usernames), len(changed), len(fetched))
+
+	if len(changed) > 0 {
+		counts, err := repo.UpsertBatch(ctx, changed)
+		if err != nil {
+			tracing.Logf(ctx, "Error saving users: %v", err)
+			return 0
+		}
+		tracing.Logf(ctx, "Saved users: %s", counts)
+		d.indexSaved(ctx, "users", func() error { return d.indexer.IndexUsers(ctx, changed) })
+
+		names := make([]string, len(changed))
+		for i, user := range changed {
+			names[i] = user.Username
+		}
+		if err := d.publishUserIDs(ctx, "UsersTopic", names); err != nil {
+			tracing.Logf(ctx, "Error sending users to the event bus: %v", err)
+		} else {
+			d.cache.CacheUserIDs(ctx, "user_ids", names)
+		}
+		d.reconcileSubmissions(ctx, changed)
+	}
+
+	if err := repo.MarkKarmaChecked(ctx, checked, now.Unix()); err != nil {
+		tracing.Logf(ctx, "Error recording profile checks: %v", err)
+	}
+	return len(changed)
+}
@@ -0,0 +1,32 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// reparseItems decodes the stored payloads of the items again and persists the result like a
+// sync would, to apply a parsing fix without fetching them again. The items without a stored
+// payload (see ITEM_RAW_PAYLOADS_ENABLED) are left as they are.
+func (d *DataSyncService) reparseItems(ctx context.Context, ids []int) error {
+	payloads, err := postgres.NewItemPayloadRepository().GetByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load item payloads: %w", err)
+	}
+	stored := make([]int, 0, len(payloads))
+	for _, id := range ids {
+		if _, ok := payloads[id]; ok {
+			stored = append(stored, id)
+		}
+	}
+	tracing.Logf(ctx, "Reparsing %d of %d items, the others have no stored payload", len(stored), len(ids))
+
+	d.persistItems(ctx, stored, func(ctx context.Context, id int) (*models.HNItem, error) {
+		return d.apiClient.DecodeHNItem(ctx, id, payloads[id].Payload)
+	}, 0)
+	return ctx.Err()
+}
@@ -0,0 +1,40 @@
+package cronjob
+
+import (
+	"log"
+	"slices"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// rescheduleJobs applies changed interval settings to the running jobs
+func (d *DataSyncService) rescheduleJobs(changed []string) {
+	for i := range d.jobs {
+		job := &d.jobs[i]
+		if job.intervalKey == "" || !slices.Contains(changed, job.intervalKey) {
+			continue
+		}
+
+		interval := job.currentInterval()
+		if interval <= 0 {
+			log.Printf("Ignoring invalid %s, keeping job %s every %v", job.intervalKey, job.name, job.interval)
+			continue
+		}
+		if interval == job.interval {
+			continue
+		}
+
+		updated, err := d.scheduler.Update(job.job.ID(),
+			gocron.DurationJob(interval),
+			gocron.NewTask(job.run),
+			gocron.WithName(job.name),
+		)
+		if err != nil {
+			log.Printf("Error rescheduling job %s: %v", job.name, err)
+			continue
+		}
+		log.Printf("Rescheduled job %s: every %v (was %v)", job.name, interval, job.interval)
+		job.job = updated
+		job.interval = interval
+	}
+}
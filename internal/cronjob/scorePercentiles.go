@@ -0,0 +1,51 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// scorePercentileWindow returns the number of days of stories each score distribution covers
+func scorePercentileWindow() int {
+	return config.GetEnvInt("SCORE_PERCENTILE_WINDOW_DAYS", 7)
+}
+
+// rankStories stores the percentile of freshly synced stories in the score distribution of their
+// creation day. Days the stats job has not reached yet use the latest distribution before them,
+// so stories synced just after midnight are still ranked.
+func (d *DataSyncService) rankStories(ctx context.Context, stories []*models.Story) {
+	if len(stories) == 0 {
+		return
+	}
+
+	oldest, newest := stories[0].Created_At, stories[0].Created_At
+	for _, story := range stories[1:] {
+		oldest = min(oldest, story.Created_At)
+		newest = max(newest, story.Created_At)
+	}
+	from := time.Unix(oldest, 0).AddDate(0, 0, -scorePercentileWindow())
+	distributions, err := postgres.NewStatsRepository().GetScorePercentiles(ctx, models.DefaultTenant, from, time.Unix(newest, 0))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading score percentiles: %v", err)
+		return
+	}
+
+	percentiles := make(map[int]int, len(stories))
+	for _, story := range stories {
+		day := time.Unix(story.Created_At, 0).UTC().Format(time.DateOnly)
+		if dist := models.LatestScorePercentiles(distributions, day); dist != nil {
+			percentiles[story.ID] = dist.Percentile(story.Score)
+		}
+	}
+	if len(percentiles) == 0 {
+		return
+	}
+	if err := postgres.NewStoryRepository().UpdateScorePercentiles(ctx, percentiles); err != nil {
+		tracing.Logf(ctx, "Error saving score percentiles: %v", err)
+	}
+}
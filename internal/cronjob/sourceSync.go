@@ -0,0 +1,68 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+	"internship-project/internal/tracing"
+)
+
+// sourceJob is a Source synced on its own interval, read from intervalKey when set
+type sourceJob struct {
+	source      services.Source
+	intervalKey string
+	interval    time.Duration
+}
+
+// AddSource registers a source to be synced every intervalKey (a duration setting that can
+// change at runtime), or every fallback when it is unset; call it before Start
+func (d *DataSyncService) AddSource(source services.Source, intervalKey string, fallback time.Duration) {
+	d.sources = append(d.sources, sourceJob{source: source, intervalKey: intervalKey, interval: fallback})
+}
+
+// syncSource fetches the latest items of a source and persists them
+func (d *DataSyncService) syncSource(ctx context.Context, source services.Source) {
+	tracing.Logf(ctx, "Starting %s sync...", source.Name())
+
+	batch, err := source.FetchLatest(ctx)
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching %s items: %v", source.Name(), err)
+		return
+	}
+
+	batch.Stories = prePersistAll(ctx, d, batch.Stories)
+	batch.Comments = prePersistAll(ctx, d, batch.Comments)
+	d.awaitReadCapacity(ctx)
+
+	if len(batch.Stories) > 0 {
+		counts, err := postgres.NewStoryRepository().UpsertBatch(ctx, batch.Stories)
+		if err != nil {
+			tracing.Logf(ctx, "Error saving %s stories to the database: %v", source.Name(), err)
+			return
+		}
+		tracing.Logf(ctx, "Saved %s stories: %s", source.Name(), counts)
+		postPersistAll(ctx, d, batch.Stories)
+		d.scoreStories(ctx, batch.Stories)
+		d.rankStories(ctx, batch.Stories)
+		d.invalidateItems(ctx, "story", itemIDs(batch.Stories, func(s *models.Story) int { return s.ID }))
+		d.cacheHotStories(ctx, batch.Stories)
+	}
+
+	if len(batch.Comments) > 0 {
+		counts, err := postgres.NewCommentRepository().UpsertBatch(ctx, batch.Comments)
+		if err != nil {
+			tracing.Logf(ctx, "Error saving %s comments to the database: %v", source.Name(), err)
+			return
+		}
+		tracing.Logf(ctx, "Saved %s comments: %s", source.Name(), counts)
+		postPersistAll(ctx, d, batch.Comments)
+		d.scoreComments(ctx, batch.Comments)
+		d.invalidateItems(ctx, "comment", itemIDs(batch.Comments, func(c *models.Comment) int { return c.ID }))
+		d.cacheHotComments(ctx, batch.Stories, batch.Comments)
+	}
+
+	tracing.Logf(ctx, "%s sync completed: %d stories, %d comments", source.Name(), len(batch.Stories), len(batch.Comments))
+}
@@ -0,0 +1,82 @@
+package cronjob
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/spam"
+	"internship-project/internal/tracing"
+)
+
+// newSpamScorer creates the scorer used by the sync jobs, looking up account age in the users table
+func newSpamScorer() *spam.Scorer {
+	return spam.NewScorer(func(ctx context.Context, author string) (int64, bool) {
+		user, err := postgres.NewUserRepository().GetByIDString(ctx, author)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				tracing.Logf(ctx, "Error loading account of %s for spam scoring: %v", author, err)
+			}
+			return 0, false
+		}
+		return user.Created_At, true
+	})
+}
+
+// scoreStories scores freshly saved stories and stores their spam scores
+func (d *DataSyncService) scoreStories(ctx context.Context, stories []*models.Story) {
+	contents := make([]spam.Content, len(stories))
+	for i, story := range stories {
+		contents[i] = spam.Content{ID: story.ID, Author: story.Author, Title: story.Title, URL: story.URL}
+	}
+	d.saveSpamScores(ctx, "stories", contents, postgres.NewStoryRepository().UpdateSpamScores)
+}
+
+// scoreAsks scores freshly saved asks and stores their spam scores
+func (d *DataSyncService) scoreAsks(ctx context.Context, asks []*models.Ask) {
+	contents := make([]spam.Content, len(asks))
+	for i, ask := range asks {
+		contents[i] = spam.Content{ID: ask.ID, Author: ask.Author, Title: ask.Title, Text: ask.Text}
+	}
+	d.saveSpamScores(ctx, "asks", contents, postgres.NewAskRepository().UpdateSpamScores)
+}
+
+// scoreComments scores freshly saved comments and stores their spam scores
+func (d *DataSyncService) scoreComments(ctx context.Context, comments []*models.Comment) {
+	contents := make([]spam.Content, len(comments))
+	for i, comment := range comments {
+		contents[i] = spam.Content{ID: comment.ID, Author: comment.Author, Text: comment.Text}
+	}
+	d.saveSpamScores(ctx, "comments", contents, postgres.NewCommentRepository().UpdateSpamScores)
+}
+
+func (d *DataSyncService) saveSpamScores(
+	ctx context.Context,
+	kind string,
+	contents []spam.Content,
+	update func(ctx context.Context, scores map[int]float64) error,
+) {
+	if len(contents) == 0 {
+		return
+	}
+
+	scores := d.spamScorer.Score(ctx, contents)
+
+	flagged := 0
+	threshold := spam.Threshold()
+	for _, score := range scores {
+		if score >= threshold {
+			flagged++
+		}
+	}
+
+	if err := update(ctx, scores); err != nil {
+		tracing.Logf(ctx, "Error saving spam scores for %s: %v", kind, err)
+		return
+	}
+	if flagged > 0 {
+		tracing.Logf(ctx, "Flagged %d of %d %s as spam", flagged, len(contents), kind)
+	}
+}
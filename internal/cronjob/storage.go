@@ -0,0 +1,33 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/capacity"
+	"internship-project/internal/config"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// recordStorage records the sizes of the database and the STORAGE_TABLES of the day, from which
+// the storage report measures their growth, and prunes the snapshots older than
+// STORAGE_SNAPSHOT_RETENTION
+func (d *DataSyncService) recordStorage(ctx context.Context) {
+	repo := postgres.NewStorageRepository()
+	now := d.clock.Now()
+	snapshots, err := capacity.Measure(ctx, postgres.NewMaintenanceRepository(), repo, now)
+	if err != nil {
+		tracing.Logf(ctx, "Error measuring storage: %v", err)
+		return
+	}
+	if err := repo.SaveSnapshots(ctx, snapshots); err != nil {
+		tracing.Logf(ctx, "Error saving storage snapshots: %v", err)
+		return
+	}
+
+	before := capacity.Day(now.Add(-config.GetEnvDuration("STORAGE_SNAPSHOT_RETENTION", 90*24*time.Hour)))
+	if _, err := repo.PruneSnapshots(ctx, before); err != nil {
+		tracing.Logf(ctx, "Error pruning storage snapshots: %v", err)
+	}
+}
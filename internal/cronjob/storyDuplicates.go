@@ -0,0 +1,32 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// linkStoryDuplicates rebuilds the links between stories created within DUPLICATES_WINDOW that
+// share a canonical URL; search collapses linked stories into the highest-scored one
+func (d *DataSyncService) linkStoryDuplicates(ctx context.Context) {
+	repo := postgres.NewStoryRepository()
+	now := d.clock.Now()
+	since := now.Add(-config.GetEnvDuration("DUPLICATES_WINDOW", 7*24*time.Hour)).Unix()
+
+	stories, err := repo.GetByDateRange(ctx, since, now.Unix())
+	if err != nil {
+		tracing.Logf(ctx, "Error loading stories to link duplicates: %v", err)
+		return
+	}
+
+	duplicates := models.FindDuplicates(stories)
+	if err := repo.ReplaceDuplicates(ctx, since, duplicates); err != nil {
+		tracing.Logf(ctx, "Error saving story duplicates: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Linked %d stories sharing a URL out of %d recent stories", len(duplicates), len(stories))
+}
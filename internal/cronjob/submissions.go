@@ -0,0 +1,102 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/go-co-op/gocron/v2"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+)
+
+// reconcileSubmissions queues the fetch of the submissions of the synced users that are not
+// stored, so their author pages do not list dangling IDs. Only the newest SUBMISSION_CHECK_LIMIT
+// submissions of each user are checked, and at most SUBMISSION_FETCH_MAX missing ones are queued
+// per user. It is on while SUBMISSION_FETCH_ENABLED is set.
+func (d *DataSyncService) reconcileSubmissions(ctx context.Context, users []*models.User) {
+	if !config.GetEnvBool("SUBMISSION_FETCH_ENABLED", true) {
+		return
+	}
+	checkLimit := config.GetEnvInt("SUBMISSION_CHECK_LIMIT", 200)
+	fetchMax := config.GetEnvInt("SUBMISSION_FETCH_MAX", 50)
+	if checkLimit <= 0 || fetchMax <= 0 {
+		return
+	}
+
+	var missing []int
+	for _, user := range users {
+		ids, err := missingSubmissions(ctx, user.Submitted[:min(len(user.Submitted), checkLimit)])
+		if err != nil {
+			tracing.Logf(ctx, "Error checking the submissions of %s: %v", user.Username, err)
+			continue
+		}
+		missing = append(missing, ids[:min(len(ids), fetchMax)]...)
+	}
+	missing = d.skipGoneItems(ctx, missing)
+	if len(missing) == 0 {
+		return
+	}
+
+	if err := d.scheduleItemFetch(ctx, missing); err != nil {
+		tracing.Logf(ctx, "Error scheduling the fetch of missing submissions: %v", err)
+		return
+	}
+	tracing.Logf(ctx, "Queued %d missing submissions of %d users for fetch", len(missing), len(users))
+}
+
+// missingSubmissions returns the IDs among ids that are not stored, in the order given; the IDs
+// the known items filter has never seen are missing without a lookup
+func missingSubmissions(ctx context.Context, ids []int) ([]int, error) {
+	var maybeStored []int
+	for _, id := range ids {
+		if bloom.KnownItems().MayContain(id) {
+			maybeStored = append(maybeStored, id)
+		}
+	}
+	var stored map[int]string
+	if len(maybeStored) > 0 {
+		var err error
+		stored, err = postgres.NewItemRepository().GetKinds(ctx, maybeStored)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var missing []int
+	for _, id := range ids {
+		if _, ok := stored[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// scheduleItemFetch queues a "fetch-items" task for the IDs when a task worker is registered,
+// and fetches them in a one-time job otherwise, like scheduleBackfill
+func (d *DataSyncService) scheduleItemFetch(ctx context.Context, ids []int) error {
+	if worker := d.tasks.Load(); worker != nil {
+		task, err := worker.Enqueue(ctx, "fetch-items", itemsTask{IDs: ids}, 0)
+		if err != nil {
+			return fmt.Errorf("failed to queue the fetch of %d items: %w", len(ids), err)
+		}
+		tracing.Logf(ctx, "Queued fetch-items task %s: %d items", task.ID, len(ids))
+		return nil
+	}
+
+	name := fmt.Sprintf("fetch-items-%d-%d", ids[0], len(ids))
+	_, err := d.scheduler.NewJob(
+		gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()),
+		gocron.NewTask(d.tracedRun(name, func(ctx context.Context) { d.syncItemIDs(ctx, ids, 0) })),
+		gocron.WithName(name),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job %s: %w", name, err)
+	}
+	log.Printf("Scheduled item fetch job: %s", name)
+	return nil
+}
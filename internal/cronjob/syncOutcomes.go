@@ -0,0 +1,99 @@
+package cronjob
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// updateSyncOutcomes counts the items of the update sync by "<kind>.<stage>.<outcome>", the
+// outcome being ok or failed
+var updateSyncOutcomes = expvar.NewMap("update_sync_outcomes")
+
+// Stages a fetched item of the update sync goes through, in order
+const (
+	stageSave    = "save"
+	stagePublish = "publish"
+	stageCache   = "cache"
+)
+
+var syncStages = []string{stageSave, stagePublish, stageCache}
+
+// syncOutcomes tracks how many items of each kind went through each stage of a sync run, and
+// the IDs of the saved ones, which are cached in one batch once every kind is saved
+type syncOutcomes struct {
+	mu     sync.Mutex
+	kinds  []string           // in the order first recorded
+	counts map[string]*[2]int // ok and failed by "<kind>.<stage>"
+	saved  map[string][]int   // IDs by kind
+}
+
+func newSyncOutcomes() *syncOutcomes {
+	return &syncOutcomes{counts: make(map[string]*[2]int), saved: make(map[string][]int)}
+}
+
+// record counts n items of the kind through the stage, as failed when err is set
+func (o *syncOutcomes) record(kind, stage string, n int, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "failed"
+	}
+	updateSyncOutcomes.Add(kind+"."+stage+"."+outcome, int64(n))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.counts[kind+"."+stageSave]; !ok {
+		o.kinds = append(o.kinds, kind)
+		for _, s := range syncStages {
+			o.counts[kind+"."+s] = &[2]int{}
+		}
+	}
+	if err != nil {
+		o.counts[kind+"."+stage][1] += n
+	} else {
+		o.counts[kind+"."+stage][0] += n
+	}
+}
+
+// save records the outcome of saving the items of the kind with the IDs
+func (o *syncOutcomes) save(kind string, ids []int, err error) {
+	o.record(kind, stageSave, len(ids), err)
+	if err == nil {
+		o.mu.Lock()
+		o.saved[kind] = append(o.saved[kind], ids...)
+		o.mu.Unlock()
+	}
+}
+
+// savedIDs returns the IDs of the saved items by kind
+func (o *syncOutcomes) savedIDs() map[string][]int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	saved := make(map[string][]int, len(o.saved))
+	for kind, ids := range o.saved {
+		saved[kind] = ids
+	}
+	return saved
+}
+
+// String sums up the run as "<kind>: <stage> <ok>/<total>, ..." per kind, skipping the stages no
+// item reached
+func (o *syncOutcomes) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.kinds) == 0 {
+		return "no items"
+	}
+	parts := make([]string, 0, len(o.kinds))
+	for _, kind := range o.kinds {
+		var stages []string
+		for _, stage := range syncStages {
+			if c := o.counts[kind+"."+stage]; c[0]+c[1] > 0 {
+				stages = append(stages, fmt.Sprintf("%s %d/%d", stage, c[0], c[0]+c[1]))
+			}
+		}
+		parts = append(parts, kind+": "+strings.Join(stages, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
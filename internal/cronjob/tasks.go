@@ -0,0 +1,158 @@
+package cronjob
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tasks"
+	"internship-project/internal/tracing"
+	"internship-project/internal/transport"
+)
+
+// backfillTask is the payload of a "backfill" task: the item ID range to sync
+type backfillTask struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// itemsTask is the payload of the "fetch-items", "reparse-items", "reindex-item" and "enrich" tasks
+type itemsTask struct {
+	IDs []int `json:"ids"`
+}
+
+// RegisterTasks registers the "backfill", "fetch-items", "reparse-items", "reindex-item" and
+// "enrich" task types with the worker and queues the backfills of missed item ranges and the
+// fetches of missing submissions there from then on
+func (d *DataSyncService) RegisterTasks(worker *tasks.Worker) {
+	worker.Register("backfill", func(ctx context.Context, payload json.RawMessage) error {
+		var task backfillTask
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return fmt.Errorf("invalid backfill payload: %w", err)
+		}
+		if task.From <= 0 || task.To < task.From {
+			return fmt.Errorf("invalid backfill range %d-%d", task.From, task.To)
+		}
+		d.syncItemRange(ctx, task.From, task.To)
+		return ctx.Err()
+	})
+	worker.Register("fetch-items", d.itemsTaskHandler(d.fetchItems))
+	worker.Register("reparse-items", d.itemsTaskHandler(d.reparseItems))
+	worker.Register("reindex-item", d.itemsTaskHandler(d.reindexItems))
+	worker.Register("enrich", d.itemsTaskHandler(d.enrichItems))
+	d.tasks.Store(worker)
+}
+
+// itemsTaskHandler decodes an itemsTask payload for run
+func (d *DataSyncService) itemsTaskHandler(run func(ctx context.Context, ids []int) error) tasks.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var task itemsTask
+		if err := json.Unmarshal(payload, &task); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+		if len(task.IDs) == 0 {
+			return errors.New("ids is required")
+		}
+		return run(ctx, task.IDs)
+	}
+}
+
+// storedKinds groups the IDs of stored items by kind, leaving out the ones not stored; the IDs
+// the known items filter has never seen are left out without a lookup
+func storedKinds(ctx context.Context, ids []int) (map[string][]int, error) {
+	repo := postgres.NewItemRepository()
+	byKind := make(map[string][]int)
+	for _, id := range ids {
+		if !bloom.KnownItems().MayContain(id) {
+			continue
+		}
+		kind, err := repo.GetKind(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		byKind[kind] = append(byKind[kind], id)
+	}
+	return byKind, nil
+}
+
+// fetchItems fetches and persists items from the HN API
+func (d *DataSyncService) fetchItems(ctx context.Context, ids []int) error {
+	d.syncItemIDs(ctx, ids, 0)
+	return ctx.Err()
+}
+
+// reindexItems publishes stored items to the indexing pipeline
+func (d *DataSyncService) reindexItems(ctx context.Context, ids []int) error {
+	byKind, err := storedKinds(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for kind, kindIDs := range byKind {
+		topic, ok := transport.ItemTopics[kind]
+		if !ok {
+			continue
+		}
+		if err := d.publishItemIDs(ctx, topic, kindIDs); err != nil {
+			return fmt.Errorf("failed to publish %s items: %w", kind, err)
+		}
+	}
+	tracing.Logf(ctx, "Sent %d of %d items for reindexing", countIDs(byKind), len(ids))
+	return nil
+}
+
+// enrichItems runs the PostPersist hooks of the ETL plugins again on stored items, to fill in
+// derived data (tags, mentions, watches) for items saved before a plugin was enabled
+func (d *DataSyncService) enrichItems(ctx context.Context, ids []int) error {
+	byKind, err := storedKinds(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for kind, kindIDs := range byKind {
+		for _, id := range kindIDs {
+			item, err := loadStoredItem(ctx, kind, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load %s %d: %w", kind, id, err)
+			}
+			d.plugins.PostPersist(ctx, item)
+		}
+	}
+	tracing.Logf(ctx, "Enriched %d of %d items", countIDs(byKind), len(ids))
+	return nil
+}
+
+// loadStoredItem returns the stored item of a kind as its model
+func loadStoredItem(ctx context.Context, kind string, id int) (interface{}, error) {
+	switch kind {
+	case "story":
+		return postgres.NewStoryRepository().GetByID(ctx, id)
+	case "ask":
+		return postgres.NewAskRepository().GetByID(ctx, id)
+	case "job":
+		return postgres.NewJobRepository().GetByID(ctx, id)
+	case "comment":
+		return postgres.NewCommentRepository().GetByID(ctx, id)
+	case "poll":
+		return postgres.NewPollRepository().GetByID(ctx, id)
+	case "pollopt":
+		return postgres.NewPollOptionRepository().GetByID(ctx, id)
+	}
+	return nil, fmt.Errorf("unknown item kind %q", kind)
+}
+
+func countIDs(byKind map[string][]int) int {
+	n := 0
+	for _, ids := range byKind {
+		n += len(ids)
+	}
+	return n
+}
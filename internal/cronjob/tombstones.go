@@ -0,0 +1,137 @@
+package cronjob
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/tracing"
+	"internship-project/internal/transport"
+)
+
+// removeItems removes the stored items with the IDs, which HackerNews deleted or marked dead,
+// and emits their tombstones. IDs of items never stored are ignored.
+func (d *DataSyncService) removeItems(ctx context.Context, ids []int, reason string) {
+	if len(ids) == 0 {
+		return
+	}
+	repo := postgres.NewTombstoneRepository()
+	tombstones, err := repo.Remove(ctx, ids, reason)
+	if err != nil {
+		tracing.Logf(ctx, "Error removing %d %s items: %v", len(ids), reason, err)
+		return
+	}
+	if len(tombstones) == 0 {
+		return
+	}
+	tracing.Logf(ctx, "Removed %d %s items", len(tombstones), reason)
+	d.emitTombstones(ctx, repo, tombstones)
+}
+
+// emitTombstones publishes the events of the tombstones on the tombstone topic, deletes their
+// search documents and cached copies, and marks them emitted. Tombstones whose search documents
+// could not be deleted stay pending for maintainTombstones to retry; consumers may see their
+// events again.
+func (d *DataSyncService) emitTombstones(ctx context.Context, repo repository.TombstoneRepository, tombstones []*models.Tombstone) {
+	values := make([][]byte, 0, len(tombstones))
+	byKind := make(map[string][]int)
+	for _, t := range tombstones {
+		value, err := json.Marshal(t)
+		if err != nil {
+			tracing.Logf(ctx, "Error encoding the tombstone of item %d: %v", t.ID, err)
+			continue
+		}
+		values = append(values, value)
+		byKind[t.Kind] = append(byKind[t.Kind], t.ID)
+	}
+	if err := d.publisher.Publish(ctx, transport.TombstoneTopic, values...); err != nil {
+		tracing.Logf(ctx, "Error sending %d tombstones to the event bus: %v", len(values), err)
+		return
+	}
+
+	client := search.NewClient()
+	var emitted []int
+	for kind, ids := range byKind {
+		d.invalidateItems(ctx, kind, ids)
+		if client != nil && slices.Contains(search.Kinds(), kind) {
+			if err := client.Delete(ctx, kind, ids); err != nil {
+				tracing.Logf(ctx, "Error deleting %d removed %s documents: %v", len(ids), kind, err)
+				continue
+			}
+		}
+		emitted = append(emitted, ids...)
+	}
+	if err := repo.MarkEmitted(ctx, emitted); err != nil {
+		tracing.Logf(ctx, "Error marking %d tombstones emitted: %v", len(emitted), err)
+	}
+}
+
+// maintainTombstones emits the tombstones left pending by failed emissions, TOMBSTONES_BATCH
+// at a time, and prunes the ones emitted longer than TOMBSTONES_RETENTION ago
+func (d *DataSyncService) maintainTombstones(ctx context.Context) {
+	repo := postgres.NewTombstoneRepository()
+	pending, err := repo.GetPending(ctx, config.GetEnvInt("TOMBSTONES_BATCH", 500))
+	if err != nil {
+		tracing.Logf(ctx, "Error loading pending tombstones: %v", err)
+		return
+	}
+	if len(pending) > 0 {
+		tracing.Logf(ctx, "Retrying %d pending tombstones", len(pending))
+		d.emitTombstones(ctx, repo, pending)
+	}
+
+	before := d.clock.Now().Add(-config.GetEnvDuration("TOMBSTONES_RETENTION", 30*24*time.Hour)).UnixMilli()
+	pruned, err := repo.PruneEmitted(ctx, before)
+	if err != nil {
+		tracing.Logf(ctx, "Error pruning tombstones: %v", err)
+		return
+	}
+	if pruned > 0 {
+		tracing.Logf(ctx, "Pruned %d tombstones", pruned)
+	}
+}
+
+// expireItems removes the items older than the retention of their tenant, ITEM_RETENTION_BATCH
+// per table at a time, and emits their tombstones. Tenants keeping items forever are skipped.
+func (d *DataSyncService) expireItems(ctx context.Context) {
+	if !config.GetEnvBool("ITEM_RETENTION_ENABLED", false) {
+		return
+	}
+
+	tenants, err := postgres.NewTenantRepository().GetAll(ctx)
+	if err != nil {
+		tracing.Logf(ctx, "Error loading tenants: %v", err)
+		return
+	}
+	repo := postgres.NewTombstoneRepository()
+	batch := config.GetEnvInt("ITEM_RETENTION_BATCH", 1000)
+	for _, tenant := range tenants {
+		if tenant.Retention_Days <= 0 {
+			continue
+		}
+		before := d.clock.Now().AddDate(0, 0, -tenant.Retention_Days).Unix()
+		expired := 0
+		for ctx.Err() == nil {
+			d.awaitReadCapacity(ctx)
+			tombstones, err := repo.RemoveExpired(ctx, tenant.Name, before, batch)
+			if err != nil {
+				tracing.Logf(ctx, "Error removing expired items of tenant %s: %v", tenant.Name, err)
+				break
+			}
+			if len(tombstones) == 0 {
+				break
+			}
+			expired += len(tombstones)
+			d.emitTombstones(ctx, repo, tombstones)
+		}
+		if expired > 0 {
+			tracing.Logf(ctx, "Removed %d items of tenant %s older than %d days", expired, tenant.Name, tenant.Retention_Days)
+		}
+	}
+}
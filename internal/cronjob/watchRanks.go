@@ -0,0 +1,27 @@
+package cronjob
+
+import (
+	"context"
+
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tracing"
+	"internship-project/internal/watch"
+)
+
+// evaluateWatchRanks notifies the watches on the rank of items in the HN top stories; score and
+// comment count watches are evaluated as items are saved, by the "watch" ETL plugin
+func (d *DataSyncService) evaluateWatchRanks(ctx context.Context) {
+	ids, err := d.storyService.FetchTopStories(ctx)
+	if err != nil {
+		tracing.Logf(ctx, "Error fetching top stories to evaluate watches: %v", err)
+		return
+	}
+
+	ranks := make(map[int]int, len(ids))
+	for i, id := range ids {
+		ranks[id] = i + 1
+	}
+	if err := watch.NewNotifier(postgres.NewWatchRepository(), postgres.NewWebhookDeliveryRepository()).RanksChanged(ctx, ranks); err != nil {
+		tracing.Logf(ctx, "Error notifying rank watches: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Package doctor runs the self-check of the doctor command: every external dependency of a
+// deployment is checked on its own (the database and its schema version, Redis, the Kafka topics,
+// the search index mappings and the HackerNews API) and the outcome is printed as a pass/fail
+// table, so a deploy pipeline can stop a rollout whose environment is not ready.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/redis"
+	"internship-project/internal/search"
+	"internship-project/internal/services"
+	"internship-project/internal/transport"
+	"internship-project/pkg/database"
+)
+
+// ErrSkipped is returned by a check that does not apply to the deployment, e.g. for a disabled sink
+var ErrSkipped = errors.New("skipped")
+
+// Outcomes of a check
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Check is one dependency check; Run returns a short description of what it found, or an error
+// wrapping ErrSkipped when the dependency is not used
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	Duration_Ms int64  `json:"duration_ms"`
+}
+
+// Run runs the checks one after the other, each bounded by timeout (5s when 0)
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		detail, err := check.Run(checkCtx)
+		cancel()
+
+		result := Result{Name: check.Name, Status: StatusPass, Detail: detail, Duration_Ms: time.Since(start).Milliseconds()}
+		switch {
+		case errors.Is(err, ErrSkipped):
+			result.Status = StatusSkip
+			result.Detail = strings.TrimSuffix(strings.TrimSuffix(err.Error(), ErrSkipped.Error()), ": ")
+		case err != nil:
+			result.Status = StatusFail
+			result.Detail = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Passed reports whether no check failed; skipped checks do not fail the run
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes the results as an aligned table followed by the overall outcome
+func Print(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tTIME\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\n", r.Name, strings.ToUpper(r.Status),
+			time.Duration(r.Duration_Ms)*time.Millisecond, r.Detail)
+	}
+	if Passed(results) {
+		fmt.Fprintln(tw, "\nAll checks passed")
+	} else {
+		fmt.Fprintln(tw, "\nSome checks failed")
+	}
+	return tw.Flush()
+}
+
+// DefaultChecks returns the checks of every dependency, in the order they are run. The database
+// checks use the connection opened by database.Connect; connectErr is its error, if any.
+func DefaultChecks(connectErr error) []Check {
+	return []Check{
+		{Name: "database", Run: func(ctx context.Context) (string, error) { return checkDatabase(ctx, connectErr) }},
+		{Name: "schema", Run: func(ctx context.Context) (string, error) { return checkSchema(ctx, connectErr) }},
+		{Name: "redis", Run: checkRedis},
+		{Name: "kafka", Run: checkKafka},
+		{Name: "opensearch", Run: checkSearch},
+		{Name: "hn-api", Run: checkHNAPI},
+	}
+}
+
+func checkDatabase(ctx context.Context, connectErr error) (string, error) {
+	if connectErr != nil {
+		return "", connectErr
+	}
+	if err := database.GetDB().PingContext(ctx); err != nil {
+		return "", err
+	}
+	cfg := database.GetDefaultConfig()
+	return fmt.Sprintf("%s:%s/%s", cfg.Host, cfg.Port, cfg.DBName), nil
+}
+
+func checkSchema(ctx context.Context, connectErr error) (string, error) {
+	if connectErr != nil {
+		return "", fmt.Errorf("database unreachable: %w", ErrSkipped)
+	}
+	version, err := database.StoredSchemaVersion(ctx)
+	switch {
+	case err != nil:
+		return "", err
+	case version < database.SchemaVersion:
+		return "", fmt.Errorf("schema at version %d, migrations up to %d pending", version, database.SchemaVersion)
+	case version > database.SchemaVersion:
+		return fmt.Sprintf("version %d, newer than the %d of this binary", version, database.SchemaVersion), nil
+	}
+	return fmt.Sprintf("version %d", version), nil
+}
+
+func checkRedis(ctx context.Context) (string, error) {
+	rdb := redis.NewClient()
+	defer rdb.Close()
+	addr := redis.GetRedisConfig().Addr
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return "", fmt.Errorf("redis at %s: %w", addr, err)
+	}
+	return addr, nil
+}
+
+func checkKafka(ctx context.Context) (string, error) {
+	if !config.SinkEnabled(config.SinkEvents) {
+		return "", fmt.Errorf("events sink disabled: %w", ErrSkipped)
+	}
+	if name := transport.Transport(); name != "kafka" {
+		return "", fmt.Errorf("event transport is %s: %w", name, ErrSkipped)
+	}
+	topics, err := transport.CheckKafkaTopics(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(topics.Missing) > 0 {
+		return "", fmt.Errorf("missing topics: %s", strings.Join(topics.Missing, ", "))
+	}
+	return fmt.Sprintf("%d brokers, %d topics", topics.Brokers, topics.Topics), nil
+}
+
+func checkSearch(ctx context.Context) (string, error) {
+	if !config.SinkEnabled(config.SinkOpenSearch) {
+		return "", fmt.Errorf("opensearch sink disabled: %w", ErrSkipped)
+	}
+	client := search.NewClient()
+	if client == nil {
+		return "", fmt.Errorf("OPENSEARCH_URL not set: %w", ErrSkipped)
+	}
+	var problems []string
+	for _, kind := range search.Kinds() {
+		found, err := client.CheckMapping(ctx, kind)
+		if err != nil {
+			return "", err
+		}
+		problems = append(problems, found...)
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("mapping mismatch: %s", strings.Join(problems, "; "))
+	}
+	return fmt.Sprintf("%d indexes mapped", len(search.Kinds())), nil
+}
+
+func checkHNAPI(ctx context.Context) (string, error) {
+	var maxItem int
+	if err := services.NewHackerNewsApiClient().Get(ctx, "/maxitem.json", &maxItem); err != nil {
+		return "", fmt.Errorf("hn api at %s: %w", config.GetEnv("HN_API_BASE_URL", "https://hacker-news.firebaseio.com/v0"), err)
+	}
+	return fmt.Sprintf("max item %d", maxItem), nil
+}
@@ -0,0 +1,181 @@
+package etl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"internship-project/internal/config"
+	"internship-project/internal/expr"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// fieldNamePattern matches the names accepted for computed fields
+var fieldNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,63}$`)
+
+// ItemVariableNames lists the variables computed field expressions can use; an item only binds
+// the ones that apply to its kind (comments have no score, jobs no comments_count)
+var ItemVariableNames = []string{
+	"id", "score", "created_at", "age_hours", "title_length", "text_length",
+	"comments_count", "kids", "depth", "options",
+}
+
+// ComputedField is an operator-defined metric derived from the fields of an item
+type ComputedField struct {
+	Name string
+	Expr *expr.Expr
+}
+
+// ParseComputedField parses a "name = expression" definition, checking that the expression only
+// uses ItemVariableNames
+func ParseComputedField(definition string) (ComputedField, error) {
+	name, source, ok := strings.Cut(definition, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !ok || !fieldNamePattern.MatchString(name) {
+		return ComputedField{}, fmt.Errorf("invalid computed field %q: expected name = expression", definition)
+	}
+	e, err := expr.Parse(strings.TrimSpace(source))
+	if err != nil {
+		return ComputedField{}, fmt.Errorf("computed field %s: %w", name, err)
+	}
+	for _, variable := range e.Variables() {
+		if !slices.Contains(ItemVariableNames, variable) {
+			return ComputedField{}, fmt.Errorf("computed field %s: unknown variable %q", name, variable)
+		}
+	}
+	return ComputedField{Name: name, Expr: e}, nil
+}
+
+// LoadComputedFields reads the computed fields of COMPUTED_FIELDS_FILE, one "name = expression"
+// per line; blank lines and lines starting with # are ignored. A missing file defines none.
+func LoadComputedFields() ([]ComputedField, error) {
+	f, err := os.Open(config.GetEnv("COMPUTED_FIELDS_FILE", "configs/computed_fields.txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fields []ComputedField
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		field, err := ParseComputedField(line)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, scanner.Err()
+}
+
+// ItemVariables returns the kind, ID and expression variables of an item, or ok false for a type
+// that has none
+func ItemVariables(item interface{}, now time.Time) (kind string, id int, vars map[string]float64, ok bool) {
+	vars = make(map[string]float64)
+	var createdAt int64
+	switch it := item.(type) {
+	case *models.Story:
+		kind, id, createdAt = "story", it.ID, it.Created_At
+		vars["score"] = float64(it.Score)
+		vars["comments_count"] = float64(it.Comments_count)
+		vars["kids"] = float64(len(it.Comments_ids))
+		vars["title_length"] = float64(utf8.RuneCountInString(it.Title))
+	case *models.Ask:
+		kind, id, createdAt = "ask", it.ID, it.Created_At
+		vars["score"] = float64(it.Score)
+		vars["comments_count"] = float64(it.Replies_count)
+		vars["kids"] = float64(len(it.Reply_ids))
+		vars["title_length"] = float64(utf8.RuneCountInString(it.Title))
+		vars["text_length"] = float64(utf8.RuneCountInString(it.Text))
+	case *models.Job:
+		kind, id, createdAt = "job", it.ID, it.Created_At
+		vars["score"] = float64(it.Score)
+		vars["title_length"] = float64(utf8.RuneCountInString(it.Title))
+		vars["text_length"] = float64(utf8.RuneCountInString(it.Text))
+	case *models.Poll:
+		kind, id, createdAt = "poll", it.ID, it.Created_At
+		vars["score"] = float64(it.Score)
+		vars["kids"] = float64(len(it.Reply_Ids))
+		vars["options"] = float64(len(it.PollOptions))
+		vars["title_length"] = float64(utf8.RuneCountInString(it.Title))
+	case *models.Comment:
+		kind, id, createdAt = "comment", it.ID, it.Created_At
+		vars["kids"] = float64(len(it.Replies))
+		vars["depth"] = float64(it.Depth)
+		vars["text_length"] = float64(utf8.RuneCountInString(it.Text))
+	case *models.PollOption:
+		kind, id, createdAt = "pollopt", it.ID, it.CreatedAt
+		vars["score"] = float64(it.Votes)
+		vars["text_length"] = float64(utf8.RuneCountInString(it.OptionText))
+	default:
+		return "", 0, nil, false
+	}
+	vars["id"] = float64(id)
+	vars["created_at"] = float64(createdAt)
+	vars["age_hours"] = now.Sub(time.Unix(createdAt, 0)).Hours()
+	return kind, id, vars, true
+}
+
+// Computer stores the computed fields of items once they are saved
+type Computer struct {
+	store  repository.ComputedFieldRepository
+	fields []ComputedField
+	now    func() time.Time
+}
+
+// NewComputer creates a computer storing the values of fields in store
+func NewComputer(store repository.ComputedFieldRepository, fields []ComputedField) *Computer {
+	return &Computer{store: store, fields: fields, now: time.Now}
+}
+
+// newConfiguredComputer builds the "compute" plugin; an invalid COMPUTED_FIELDS_FILE defines no
+// fields rather than stopping the sync
+func newConfiguredComputer() Plugin {
+	fields, err := LoadComputedFields()
+	if err != nil {
+		log.Printf("No computed fields: %v", err)
+		fields = nil
+	}
+	return NewComputer(postgres.NewComputedFieldRepository(), fields)
+}
+
+// Name implements Plugin
+func (c *Computer) Name() string { return "compute" }
+
+// PrePersist implements Plugin
+func (c *Computer) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin. A field whose expression uses a variable the item's kind does
+// not have, or does not evaluate to a finite number, is not stored for the item.
+func (c *Computer) PostPersist(ctx context.Context, item interface{}) error {
+	if len(c.fields) == 0 {
+		return nil
+	}
+	kind, id, vars, ok := ItemVariables(item, c.now())
+	if !ok {
+		return nil
+	}
+	values := make(map[string]float64, len(c.fields))
+	for _, field := range c.fields {
+		if value, err := field.Expr.Eval(vars); err == nil {
+			values[field.Name] = value
+		}
+	}
+	return c.store.ReplaceFields(ctx, kind, id, values)
+}
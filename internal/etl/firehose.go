@@ -0,0 +1,53 @@
+package etl
+
+import (
+	"context"
+
+	"internship-project/internal/firehose"
+	"internship-project/internal/models"
+)
+
+// Firehose publishes every saved item to a firehose hub. Publishing never blocks, so slow
+// subscribers cannot hold up the saves.
+type Firehose struct {
+	hub *firehose.Hub
+}
+
+// NewFirehose creates a plugin publishing to hub
+func NewFirehose(hub *firehose.Hub) *Firehose {
+	return &Firehose{hub: hub}
+}
+
+// newConfiguredFirehose builds the "firehose" plugin, publishing to the default hub
+func newConfiguredFirehose() Plugin {
+	return NewFirehose(firehose.Default())
+}
+
+// Name implements Plugin
+func (f *Firehose) Name() string { return "firehose" }
+
+// PrePersist implements Plugin
+func (f *Firehose) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin
+func (f *Firehose) PostPersist(ctx context.Context, item interface{}) error {
+	event := firehose.Event{Item: item}
+	switch it := item.(type) {
+	case *models.Story:
+		event.Kind, event.ID = "story", it.ID
+	case *models.Ask:
+		event.Kind, event.ID = "ask", it.ID
+	case *models.Job:
+		event.Kind, event.ID = "job", it.ID
+	case *models.Comment:
+		event.Kind, event.ID = "comment", it.ID
+	case *models.Poll:
+		event.Kind, event.ID = "poll", it.ID
+	case *models.PollOption:
+		event.Kind, event.ID = "pollopt", it.ID
+	default:
+		return nil
+	}
+	f.hub.Publish(event)
+	return nil
+}
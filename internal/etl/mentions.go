@@ -0,0 +1,60 @@
+package etl
+
+import (
+	"context"
+	"html"
+	"regexp"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// mentionPattern matches @username where HackerNews usernames are 2 to 15 letters, digits,
+// dashes and underscores; the @ must not follow a word character or a slash, which rules out
+// e-mail addresses and URL paths
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w/@])@([A-Za-z0-9_-]{2,15})\b`)
+
+// ExtractMentions returns the distinct usernames mentioned as @username in a comment text, in
+// order of appearance. The text is HTML as served by HackerNews, with escaped slashes in links.
+func ExtractMentions(text string) []string {
+	seen := make(map[string]bool)
+	usernames := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(html.UnescapeString(text), -1) {
+		if username := match[1]; !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// Mentioner stores the users a comment mentions or replies to once it is saved
+type Mentioner struct {
+	store repository.MentionRepository
+}
+
+// NewMentioner creates a mentioner storing mentions in store
+func NewMentioner(store repository.MentionRepository) *Mentioner {
+	return &Mentioner{store: store}
+}
+
+// newConfiguredMentioner builds the "mention" plugin
+func newConfiguredMentioner() Plugin {
+	return NewMentioner(postgres.NewMentionRepository())
+}
+
+// Name implements Plugin
+func (m *Mentioner) Name() string { return "mention" }
+
+// PrePersist implements Plugin
+func (m *Mentioner) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin
+func (m *Mentioner) PostPersist(ctx context.Context, item interface{}) error {
+	comment, ok := item.(*models.Comment)
+	if !ok {
+		return nil
+	}
+	return m.store.ReplaceMentions(ctx, comment, ExtractMentions(comment.Text))
+}
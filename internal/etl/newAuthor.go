@@ -0,0 +1,52 @@
+package etl
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// NewAuthorFlagger flags the stories and comments posted from accounts younger than its account
+// age, or as their author's first submission, once they are saved
+type NewAuthorFlagger struct {
+	store      repository.ItemRepository
+	accountAge time.Duration
+}
+
+// NewNewAuthorFlagger creates a flagger storing the flags in store
+func NewNewAuthorFlagger(store repository.ItemRepository, accountAge time.Duration) *NewAuthorFlagger {
+	return &NewAuthorFlagger{store: store, accountAge: accountAge}
+}
+
+// newConfiguredNewAuthorFlagger builds the "new-author" plugin; accounts are new for
+// NEW_AUTHOR_DAYS days
+func newConfiguredNewAuthorFlagger() Plugin {
+	days := config.GetEnvInt("NEW_AUTHOR_DAYS", 30)
+	return NewNewAuthorFlagger(postgres.NewItemRepository(), time.Duration(days)*24*time.Hour)
+}
+
+// Name implements Plugin
+func (f *NewAuthorFlagger) Name() string { return "new-author" }
+
+// PrePersist implements Plugin
+func (f *NewAuthorFlagger) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin
+func (f *NewAuthorFlagger) PostPersist(ctx context.Context, item interface{}) error {
+	var kind string
+	var id int
+	switch it := item.(type) {
+	case *models.Story:
+		kind, id = "story", it.ID
+	case *models.Comment:
+		kind, id = "comment", it.ID
+	default:
+		return nil
+	}
+	_, err := f.store.MarkNewAuthor(ctx, kind, id, f.accountAge)
+	return err
+}
@@ -0,0 +1,54 @@
+package etl
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"internship-project/internal/models"
+)
+
+// URLNormalizer lowercases the scheme and host of item URLs and drops tracking
+// query parameters (utm_*), so domain filters and duplicate checks see one form per link
+type URLNormalizer struct{}
+
+// Name implements Plugin
+func (URLNormalizer) Name() string { return "normalize-url" }
+
+// PrePersist implements Plugin
+func (URLNormalizer) PrePersist(ctx context.Context, item interface{}) error {
+	switch it := item.(type) {
+	case *models.Story:
+		it.URL = normalizeURL(it.URL)
+	case *models.Job:
+		it.URL = normalizeURL(it.URL)
+	}
+	return nil
+}
+
+// PostPersist implements Plugin
+func (URLNormalizer) PostPersist(ctx context.Context, item interface{}) error { return nil }
+
+// normalizeURL returns raw unchanged when it is empty or not an absolute URL
+func normalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	query := u.Query()
+	removed := false
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+			removed = true
+		}
+	}
+	if removed {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
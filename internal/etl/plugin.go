@@ -0,0 +1,83 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"internship-project/internal/config"
+)
+
+// Plugin enriches items on their way into the store.
+// Items are pointers to the models (*models.Story, *models.Comment, ...); plugins
+// ignore the types they do not handle. Hooks may run concurrently and must be safe for it.
+type Plugin interface {
+	Name() string
+
+	// PrePersist runs before the item is saved and may modify it; an error drops the item
+	PrePersist(ctx context.Context, item interface{}) error
+
+	// PostPersist runs after the item is saved; errors are logged and do not undo the save
+	PostPersist(ctx context.Context, item interface{}) error
+}
+
+// builtins maps the names accepted by ETL_PLUGINS to their constructors
+var builtins = map[string]func() Plugin{
+	"sanitize":      func() Plugin { return Sanitizer{} },
+	"normalize-url": func() Plugin { return URLNormalizer{} },
+	"tag":           newConfiguredTagger,
+	"watch":         newConfiguredWatcher,
+	"mention":       newConfiguredMentioner,
+	"compute":       newConfiguredComputer,
+	"firehose":      newConfiguredFirehose,
+	"new-author":    newConfiguredNewAuthorFlagger,
+}
+
+// Pipeline runs its plugins in registration order
+type Pipeline struct {
+	plugins []Plugin
+}
+
+// NewPipeline creates a pipeline running the given plugins in order
+func NewPipeline(plugins ...Plugin) *Pipeline {
+	return &Pipeline{plugins: plugins}
+}
+
+// NewPipelineFromConfig builds the pipeline of the built-in plugins listed in ETL_PLUGINS
+func NewPipelineFromConfig() (*Pipeline, error) {
+	p := NewPipeline()
+	for _, name := range config.GetEnvList("ETL_PLUGINS", []string{"sanitize", "normalize-url"}) {
+		newPlugin, ok := builtins[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown ETL plugin %q", name)
+		}
+		p.Register(newPlugin())
+	}
+	return p, nil
+}
+
+// Register appends a plugin; call it at startup, before items flow through the pipeline
+func (p *Pipeline) Register(plugin Plugin) {
+	p.plugins = append(p.plugins, plugin)
+	log.Printf("Registered ETL plugin: %s", plugin.Name())
+}
+
+// PrePersist runs every PrePersist hook and stops at the first error
+func (p *Pipeline) PrePersist(ctx context.Context, item interface{}) error {
+	for _, plugin := range p.plugins {
+		if err := plugin.PrePersist(ctx, item); err != nil {
+			return fmt.Errorf("%s: %w", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
+// PostPersist runs every PostPersist hook, logging failures
+func (p *Pipeline) PostPersist(ctx context.Context, item interface{}) {
+	for _, plugin := range p.plugins {
+		if err := plugin.PostPersist(ctx, item); err != nil {
+			log.Printf("ETL plugin %s failed after persist: %v", plugin.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package etl
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"internship-project/internal/models"
+)
+
+// Sanitizer trims surrounding whitespace and strips control characters from item text fields
+type Sanitizer struct{}
+
+// Name implements Plugin
+func (Sanitizer) Name() string { return "sanitize" }
+
+// PrePersist implements Plugin
+func (Sanitizer) PrePersist(ctx context.Context, item interface{}) error {
+	switch it := item.(type) {
+	case *models.Story:
+		it.Title = sanitizeLine(it.Title)
+		it.URL = sanitizeLine(it.URL)
+	case *models.Ask:
+		it.Title = sanitizeLine(it.Title)
+		it.Text = sanitizeText(it.Text)
+	case *models.Job:
+		it.Title = sanitizeLine(it.Title)
+		it.Text = sanitizeText(it.Text)
+		it.URL = sanitizeLine(it.URL)
+	case *models.Comment:
+		it.Text = sanitizeText(it.Text)
+	case *models.Poll:
+		it.Title = sanitizeLine(it.Title)
+	case *models.PollOption:
+		it.OptionText = sanitizeText(it.OptionText)
+	}
+	return nil
+}
+
+// PostPersist implements Plugin
+func (Sanitizer) PostPersist(ctx context.Context, item interface{}) error { return nil }
+
+// sanitizeLine removes every control character, including line breaks
+func sanitizeLine(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s))
+}
+
+// sanitizeText removes control characters but keeps line breaks and tabs
+func sanitizeText(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s))
+}
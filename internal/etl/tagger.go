@@ -0,0 +1,180 @@
+package etl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+// maxPhraseWords is the length in words of the longest alias a title is matched against
+const maxPhraseWords = 3
+
+// defaultTagAliases maps each built-in tag to the title words and phrases that carry it;
+// ambiguous words such as "go" are left out in favor of unambiguous spellings
+var defaultTagAliases = map[string][]string{
+	"ai":         {"ai", "llm", "llms", "gpt", "chatgpt", "openai", "machine learning", "artificial intelligence", "neural network", "neural networks"},
+	"apple":      {"apple", "macos", "ios", "iphone", "ipad"},
+	"crypto":     {"bitcoin", "ethereum", "blockchain", "cryptocurrency", "crypto"},
+	"docker":     {"docker", "dockerfile"},
+	"go":         {"golang", "go language"},
+	"google":     {"google", "alphabet", "android"},
+	"javascript": {"javascript", "js", "node.js", "nodejs", "deno", "npm"},
+	"kubernetes": {"kubernetes", "k8s"},
+	"linux":      {"linux", "ubuntu", "debian", "fedora"},
+	"microsoft":  {"microsoft", "windows", "azure"},
+	"mysql":      {"mysql", "mariadb"},
+	"postgres":   {"postgres", "postgresql", "psql"},
+	"python":     {"python", "cpython", "pypi"},
+	"redis":      {"redis", "valkey"},
+	"rust":       {"rust", "rustlang"},
+	"security":   {"security", "vulnerability", "vulnerabilities", "exploit", "cve", "malware", "ransomware"},
+	"sqlite":     {"sqlite"},
+	"startups":   {"startup", "startups", "yc", "y combinator"},
+	"typescript": {"typescript"},
+	"wasm":       {"wasm", "webassembly"},
+	"ask-hn":     {"ask hn"},
+	"show-hn":    {"show hn"},
+	"launch-hn":  {"launch hn"},
+}
+
+// Vocabulary maps the normalized words and phrases of titles to the tags they carry
+type Vocabulary map[string]string
+
+// DefaultVocabulary returns the built-in tags plus the ones listed in TAGS_FILE, one per line as
+// "tag: alias, alias"; blank lines and lines starting with # are ignored
+func DefaultVocabulary() (Vocabulary, error) {
+	v := builtinVocabulary()
+	path := config.GetEnv("TAGS_FILE", "configs/tags.txt")
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tag, aliases, _ := strings.Cut(line, ":")
+		v.Add(strings.TrimSpace(tag), append(strings.Split(aliases, ","), tag)...)
+	}
+	return v, scanner.Err()
+}
+
+// builtinVocabulary returns the vocabulary of defaultTagAliases
+func builtinVocabulary() Vocabulary {
+	v := make(Vocabulary)
+	for tag, aliases := range defaultTagAliases {
+		v.Add(tag, aliases...)
+	}
+	return v
+}
+
+// Add maps the aliases, and the tag itself, to the tag
+func (v Vocabulary) Add(tag string, aliases ...string) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return
+	}
+	for _, alias := range append(aliases, tag) {
+		if phrase := strings.Join(titleWords(alias), " "); phrase != "" {
+			v[phrase] = tag
+		}
+	}
+}
+
+// Extract returns the sorted tags whose aliases appear in the title as whole words or phrases
+func (v Vocabulary) Extract(title string) []string {
+	words := titleWords(title)
+	found := make(map[string]bool)
+	for i := range words {
+		for n := 1; n <= maxPhraseWords && i+n <= len(words); n++ {
+			if tag, ok := v[strings.Join(words[i:i+n], " ")]; ok {
+				found[tag] = true
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(found))
+	for tag := range found {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// titleWords lowercases a title and splits it into words, keeping the symbols of names like
+// "c++", "c#" and "node.js" and dropping trailing punctuation
+func titleWords(title string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.-", r)
+	})
+	words := fields[:0]
+	for _, field := range fields {
+		if word := strings.TrimRight(field, ".-"); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Tagger stores the tags found in the titles of stories, asks, jobs and polls once they are saved
+type Tagger struct {
+	store      repository.TagRepository
+	vocabulary Vocabulary
+}
+
+// NewTagger creates a tagger storing the tags of the vocabulary in store
+func NewTagger(store repository.TagRepository, vocabulary Vocabulary) *Tagger {
+	return &Tagger{store: store, vocabulary: vocabulary}
+}
+
+// newConfiguredTagger builds the "tag" plugin; an unreadable TAGS_FILE leaves the built-in tags
+func newConfiguredTagger() Plugin {
+	vocabulary, err := DefaultVocabulary()
+	if err != nil {
+		log.Printf("Using the built-in tags only: %v", err)
+		vocabulary = builtinVocabulary()
+	}
+	return NewTagger(postgres.NewTagRepository(), vocabulary)
+}
+
+// Name implements Plugin
+func (t *Tagger) Name() string { return "tag" }
+
+// PrePersist implements Plugin
+func (t *Tagger) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin
+func (t *Tagger) PostPersist(ctx context.Context, item interface{}) error {
+	var kind, title string
+	var id int
+	switch it := item.(type) {
+	case *models.Story:
+		kind, id, title = "story", it.ID, it.Title
+	case *models.Ask:
+		kind, id, title = "ask", it.ID, it.Title
+	case *models.Job:
+		kind, id, title = "job", it.ID, it.Title
+	case *models.Poll:
+		kind, id, title = "poll", it.ID, it.Title
+	default:
+		return nil
+	}
+	return t.store.ReplaceTags(ctx, kind, id, t.vocabulary.Extract(title))
+}
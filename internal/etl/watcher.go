@@ -0,0 +1,78 @@
+package etl
+
+import (
+	"context"
+	"log"
+
+	"internship-project/internal/config"
+	"internship-project/internal/firehose"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/watch"
+)
+
+// Watcher notifies the watches of stories, asks, jobs and polls whose score or comment count
+// changed once they are saved
+type Watcher struct {
+	notifier *watch.Notifier
+}
+
+// NewWatcher creates a watcher notifying through notifier
+func NewWatcher(notifier *watch.Notifier) *Watcher {
+	return &Watcher{notifier: notifier}
+}
+
+// newConfiguredWatcher builds the "watch" plugin
+func newConfiguredWatcher() Plugin {
+	return NewWatcher(watch.NewNotifier(postgres.NewWatchRepository(), postgres.NewWebhookDeliveryRepository()))
+}
+
+// Name implements Plugin
+func (w *Watcher) Name() string { return "watch" }
+
+// PrePersist implements Plugin
+func (w *Watcher) PrePersist(ctx context.Context, item interface{}) error { return nil }
+
+// PostPersist implements Plugin
+func (w *Watcher) PostPersist(ctx context.Context, item interface{}) error {
+	switch it := item.(type) {
+	case *models.Story:
+		return w.notifier.ItemChanged(ctx, it.ID, it.Score, it.Comments_count)
+	case *models.Ask:
+		return w.notifier.ItemChanged(ctx, it.ID, it.Score, it.Replies_count)
+	case *models.Job:
+		return w.notifier.ItemChanged(ctx, it.ID, it.Score, 0)
+	case *models.Poll:
+		return w.notifier.ItemChanged(ctx, it.ID, it.Score, len(it.Reply_Ids))
+	}
+	return nil
+}
+
+// Run evaluates the watches of the items published to hub until ctx is done, off the
+// persistence path: an alternative to registering the watcher as a plugin, for webhooks slow
+// enough to hold up the saves. Its queue holds WATCH_QUEUE_SIZE items and drops the oldest when
+// full; the watches of a dropped item are evaluated again the next time it is saved.
+func (w *Watcher) Run(ctx context.Context, hub *firehose.Hub) {
+	sub := hub.Subscribe(w.Name(), firehose.Options{
+		QueueSize: config.GetEnvInt("WATCH_QUEUE_SIZE", 1000),
+		Policy:    firehose.DropOldest,
+		Filter: func(event firehose.Event) bool {
+			return event.Kind != "comment" && event.Kind != "pollopt"
+		},
+	})
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := w.PostPersist(ctx, event.Item); err != nil {
+				log.Printf("Watch evaluation of %s %d failed: %v", event.Kind, event.ID, err)
+			}
+		}
+	}
+}
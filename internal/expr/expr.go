@@ -0,0 +1,317 @@
+// Package expr parses and evaluates the arithmetic expressions of the computed fields, such as
+// "comments_count / greatest(score, 1)": numbers, variables, + - * /, parentheses and a few
+// SQL-style functions.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrNotFinite is returned when an expression evaluates to NaN or an infinity, e.g. on a
+// division by zero
+var ErrNotFinite = errors.New("result is not a finite number")
+
+// functions maps the callable function names to their implementation and argument count
+// (-1 for one or more arguments)
+var functions = map[string]struct {
+	arity int
+	call  func(args []float64) float64
+}{
+	"greatest": {-1, func(args []float64) float64 { return foldArgs(args, math.Max) }},
+	"least":    {-1, func(args []float64) float64 { return foldArgs(args, math.Min) }},
+	"abs":      {1, func(args []float64) float64 { return math.Abs(args[0]) }},
+	"sqrt":     {1, func(args []float64) float64 { return math.Sqrt(args[0]) }},
+	"ln":       {1, func(args []float64) float64 { return math.Log(args[0]) }},
+	"log":      {1, func(args []float64) float64 { return math.Log10(args[0]) }},
+	"floor":    {1, func(args []float64) float64 { return math.Floor(args[0]) }},
+	"ceil":     {1, func(args []float64) float64 { return math.Ceil(args[0]) }},
+}
+
+func foldArgs(args []float64, fold func(a, b float64) float64) float64 {
+	result := args[0]
+	for _, arg := range args[1:] {
+		result = fold(result, arg)
+	}
+	return result
+}
+
+// Expr is a parsed expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// node is an expression tree node
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(map[string]float64) (float64, error) { return float64(n), nil }
+
+type variable string
+
+func (v variable) eval(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(v)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", string(v))
+	}
+	return value, nil
+}
+
+type negation struct{ operand node }
+
+func (n negation) eval(vars map[string]float64) (float64, error) {
+	value, err := n.operand.eval(vars)
+	return -value, err
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(vars map[string]float64) (float64, error) {
+	left, err := b.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		return left / right, nil
+	}
+}
+
+type call struct {
+	name string
+	args []node
+}
+
+func (c call) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(c.args))
+	for i, arg := range c.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return functions[c.name].call(args), nil
+}
+
+// Parse parses an expression; identifiers followed by "(" are function calls and the others are
+// variables, bound at evaluation
+func Parse(source string) (*Expr, error) {
+	p := &parser{tokens: tokenize(source)}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q", source, tok)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string { return e.source }
+
+// Eval evaluates the expression with the variables; it fails on an unknown variable and on a
+// result that is not finite
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	value, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, ErrNotFinite
+	}
+	return value, nil
+}
+
+// Variables returns the names of the variables the expression uses
+func (e *Expr) Variables() []string {
+	seen := make(map[string]bool)
+	var names []string
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case variable:
+			if !seen[string(n)] {
+				seen[string(n)] = true
+				names = append(names, string(n))
+			}
+		case negation:
+			walk(n.operand)
+		case binary:
+			walk(n.left)
+			walk(n.right)
+		case call:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return names
+}
+
+// tokenize splits an expression into numbers, identifiers and single-character symbols
+func tokenize(source string) []string {
+	var tokens []string
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, strings.ToLower(string(runes[start:i])))
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+// parser is a recursive descent parser over the tokens
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// parseSum parses terms joined by + and -
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()[0]
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseProduct parses factors joined by * and /
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()[0]
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseFactor parses a number, variable, call, negation or parenthesized expression
+func (p *parser) parseFactor() (node, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, errors.New("unexpected end")
+	case tok == "-":
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negation{operand}, nil
+	case tok == "(":
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing )")
+		}
+		return inner, nil
+	case unicode.IsDigit(rune(tok[0])) || tok[0] == '.':
+		value, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok)
+		}
+		return number(value), nil
+	case unicode.IsLetter(rune(tok[0])) || tok[0] == '_':
+		if p.peek() != "(" {
+			return variable(tok), nil
+		}
+		return p.parseCall(tok)
+	}
+	return nil, fmt.Errorf("unexpected %q", tok)
+}
+
+// parseCall parses the parenthesized arguments of a function call
+func (p *parser) parseCall(name string) (node, error) {
+	fn, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.next() // (
+	var args []node
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if sep := p.next(); sep == ")" {
+			break
+		} else if sep != "," {
+			return nil, fmt.Errorf("expected , or ) in %s()", name)
+		}
+	}
+	if fn.arity > 0 && len(args) != fn.arity {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, fn.arity, len(args))
+	}
+	return call{name: name, args: args}, nil
+}
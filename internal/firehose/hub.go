@@ -0,0 +1,275 @@
+// Package firehose fans the items saved by the persistence pipeline out to in-process
+// subscribers: WebSocket connections, the webhook evaluator, matchers. Publishing never blocks:
+// every subscriber has a bounded queue and a policy for when it is full, so a slow subscriber
+// loses events or its subscription instead of stalling the pipeline or the other subscribers.
+package firehose
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// published counts the events published to every hub
+	published = expvar.NewInt("firehose_published")
+
+	// dropped counts the events dropped by full queues, by subscriber name
+	dropped = expvar.NewMap("firehose_dropped")
+
+	// disconnected counts the subscriptions closed for falling behind, by subscriber name
+	disconnected = expvar.NewMap("firehose_disconnected")
+)
+
+// ErrSlowSubscriber is the Err of a subscription closed because its queue was full
+var ErrSlowSubscriber = errors.New("subscriber fell behind")
+
+// ErrHubClosed is the Err of the subscriptions of a closed hub
+var ErrHubClosed = errors.New("firehose closed")
+
+// Event is an item saved by the persistence pipeline
+type Event struct {
+	Kind string      `json:"type"`
+	ID   int         `json:"id"`
+	Item interface{} `json:"item"`
+	Time int64       `json:"time"` // when it was published (unix milliseconds)
+}
+
+// Policy is what a subscription does with an event its full queue has no room for
+type Policy string
+
+const (
+	// DropNewest drops the event, keeping the queued ones
+	DropNewest Policy = "drop_newest"
+	// DropOldest drops the oldest queued event to make room, for subscribers that only care
+	// about the latest state
+	DropOldest Policy = "drop_oldest"
+	// Disconnect closes the subscription, for subscribers that must not miss events silently
+	Disconnect Policy = "disconnect"
+)
+
+// Options configures a subscription
+type Options struct {
+	QueueSize int    // events queued before the policy applies; 256 when 0
+	Policy    Policy // DropNewest when empty
+	// Filter selects the events queued for the subscriber; all when nil. It runs in the
+	// publisher and must be cheap.
+	Filter func(Event) bool
+}
+
+// Hub delivers the published events to its subscribers. It is safe for concurrent use.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+var defaultHub = NewHub()
+
+// Default returns the hub the persistence pipeline publishes to
+func Default() *Hub {
+	return defaultHub
+}
+
+// Subscribe registers a subscriber; name identifies it in the stats and metrics. The
+// subscription of a closed hub is closed already.
+func (h *Hub) Subscribe(name string, opts Options) *Subscription {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 256
+	}
+	if opts.Policy == "" {
+		opts.Policy = DropNewest
+	}
+	s := &Subscription{
+		hub:     h,
+		name:    name,
+		policy:  opts.Policy,
+		filter:  opts.Filter,
+		events:  make(chan Event, opts.QueueSize),
+		created: time.Now(),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		s.err = ErrHubClosed
+		close(s.events)
+		return s
+	}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Publish queues the event for every subscriber whose filter accepts it, without blocking.
+// Subscribers with a full queue drop an event or are disconnected according to their policy.
+func (h *Hub) Publish(event Event) {
+	if event.Time == 0 {
+		event.Time = time.Now().UnixMilli()
+	}
+	published.Add(1)
+
+	var slow []*Subscription
+	h.mu.RLock()
+	for s := range h.subs {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+		if !s.offer(event) {
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	// Queues are only closed under the write lock, so no send can race with the close
+	for _, s := range slow {
+		h.remove(s, ErrSlowSubscriber)
+	}
+}
+
+// Close closes every subscription and the subscriptions made later
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subs {
+		s.close(ErrHubClosed)
+	}
+}
+
+// remove closes a subscription with err unless it is closed already
+func (h *Hub) remove(s *Subscription, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	if errors.Is(err, ErrSlowSubscriber) {
+		disconnected.Add(s.name, 1)
+	}
+	s.close(err)
+}
+
+// Stats describes the current subscribers
+func (h *Hub) Stats() []Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	stats := make([]Stats, 0, len(h.subs))
+	for s := range h.subs {
+		stats = append(stats, s.Stats())
+	}
+	return stats
+}
+
+// Subscription is the queue of one subscriber
+type Subscription struct {
+	hub     *Hub
+	name    string
+	policy  Policy
+	filter  func(Event) bool
+	events  chan Event
+	created time.Time
+
+	delivered atomic.Int64
+	dropped   atomic.Int64
+	overflow  atomic.Bool // set once a Disconnect subscription has no room left
+
+	// err is set before events is closed, under the hub's write lock
+	err error
+}
+
+// Events returns the queue of the subscription. It is closed when the subscription is, by Close,
+// the hub or the Disconnect policy; Err tells why.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription was closed, once Events is closed: nil after Close,
+// ErrSlowSubscriber after a disconnect, ErrHubClosed after the hub closed
+func (s *Subscription) Err() error {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	return s.err
+}
+
+// Close unsubscribes; the events still queued are discarded
+func (s *Subscription) Close() {
+	s.hub.remove(s, nil)
+}
+
+// close removes the subscription from the hub; call with the hub's write lock held
+func (s *Subscription) close(err error) {
+	delete(s.hub.subs, s)
+	s.err = err
+	close(s.events)
+}
+
+// offer queues an event according to the policy. It returns false when a Disconnect
+// subscription has no room, for the hub to close it.
+func (s *Subscription) offer(event Event) bool {
+	if s.overflow.Load() {
+		return false
+	}
+	select {
+	case s.events <- event:
+		s.delivered.Add(1)
+		return true
+	default:
+	}
+
+	switch s.policy {
+	case Disconnect:
+		s.overflow.Store(true)
+		return false
+	case DropOldest:
+		// Another publisher may refill the queue meanwhile: then this event is dropped instead
+		select {
+		case <-s.events:
+			s.drop()
+		default:
+		}
+		select {
+		case s.events <- event:
+			s.delivered.Add(1)
+			return true
+		default:
+		}
+	}
+	s.drop()
+	return true
+}
+
+func (s *Subscription) drop() {
+	s.dropped.Add(1)
+	dropped.Add(s.name, 1)
+}
+
+// Stats describes a subscriber
+type Stats struct {
+	Name      string    `json:"name"`
+	Policy    Policy    `json:"policy"`
+	Queued    int       `json:"queued"`
+	QueueSize int       `json:"queue_size"`
+	Delivered int64     `json:"delivered"` // events queued since the subscription, consumed or not
+	Dropped   int64     `json:"dropped"`
+	Since     time.Time `json:"since"`
+}
+
+// Stats describes the subscription
+func (s *Subscription) Stats() Stats {
+	return Stats{
+		Name:      s.name,
+		Policy:    s.policy,
+		Queued:    len(s.events),
+		QueueSize: cap(s.events),
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Since:     s.created,
+	}
+}
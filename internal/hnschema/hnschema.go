@@ -0,0 +1,221 @@
+// Package hnschema validates HackerNews API item payloads against the JSON schemas of their
+// types, embedded from schemas/, so drift in the fields served upstream is caught before an item
+// is converted and persisted with missing values. The validator implements the subset of JSON
+// Schema the schemas use: type, const, enum, minimum, required, properties, items and
+// if/then/else.
+package hnschema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema is a JSON schema, or the subset of one the validator understands
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Const      interface{}        `json:"const,omitempty"`
+	Enum       []interface{}      `json:"enum,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	If         *Schema            `json:"if,omitempty"`
+	Then       *Schema            `json:"then,omitempty"`
+	Else       *Schema            `json:"else,omitempty"`
+}
+
+// schemas are the embedded schemas by item type, loaded at init so a broken schema fails fast
+var schemas = mustLoadSchemas()
+
+func mustLoadSchemas() map[string]*Schema {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]*Schema, len(entries))
+	for _, entry := range entries {
+		data, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var schema Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			panic(fmt.Sprintf("invalid schema %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = &schema
+	}
+	return loaded
+}
+
+// Types returns the item types that have a schema
+func Types() []string {
+	types := make([]string, 0, len(schemas))
+	for t := range schemas {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Violation is a value of a payload that does not match its schema; Path is a JSON pointer
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// ValidateItem validates an item payload against the schema of its type and returns the type
+// with the violations, none when the payload matches. A null payload, served for IDs without
+// an item, has no type and no violations.
+func ValidateItem(payload []byte) (string, []Violation) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", []Violation{{Message: "invalid JSON: " + err.Error()}}
+	}
+	if value == nil {
+		return "", nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return "", []Violation{{Message: "expected an object, got " + typeOf(value)}}
+	}
+	itemType, _ := object["type"].(string)
+	schema, ok := schemas[itemType]
+	if !ok {
+		if _, present := object["type"]; !present {
+			return "", []Violation{{Path: "/type", Message: "missing"}}
+		}
+		return itemType, []Violation{{Path: "/type", Message: fmt.Sprintf("unknown item type %v", object["type"])}}
+	}
+	return itemType, Validate(schema, value)
+}
+
+// Validate returns the violations of value, decoded with json.Decoder.UseNumber, against schema
+func Validate(schema *Schema, value interface{}) []Violation {
+	var violations []Violation
+	validate(schema, value, "", &violations)
+	return violations
+}
+
+func validate(schema *Schema, value interface{}, at string, violations *[]Violation) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: at, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Type != "" && !hasType(value, schema.Type) {
+		report("expected %s, got %s", schema.Type, typeOf(value))
+		return
+	}
+	if schema.Const != nil && !equal(value, schema.Const) {
+		report("expected %v, got %v", schema.Const, value)
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(v interface{}) bool { return equal(value, v) }) {
+		report("%v is not one of %v", value, schema.Enum)
+	}
+	if n, ok := value.(json.Number); ok && schema.Minimum != nil {
+		if f, err := n.Float64(); err == nil && f < *schema.Minimum {
+			report("%v is less than %v", n, *schema.Minimum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, Violation{Path: at + "/" + name, Message: "missing"})
+			}
+		}
+		for name, property := range schema.Properties {
+			if field, ok := v[name]; ok {
+				validate(property, field, at+"/"+name, violations)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				validate(schema.Items, item, at+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	}
+
+	if schema.If != nil {
+		if len(Validate(schema.If, value)) == 0 {
+			if schema.Then != nil {
+				validate(schema.Then, value, at, violations)
+			}
+		} else if schema.Else != nil {
+			validate(schema.Else, value, at, violations)
+		}
+	}
+}
+
+// hasType reports whether value is of the JSON schema type
+func hasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return typeOf(value) == schemaType
+}
+
+// typeOf returns the JSON schema type of a decoded value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares a decoded value with a const or enum value of a schema, which were decoded
+// without UseNumber
+func equal(value, expected interface{}) bool {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		e, isNumber := expected.(float64)
+		return err == nil && isNumber && f == e
+	case string, bool, nil:
+		return value == expected
+	}
+	return false
+}
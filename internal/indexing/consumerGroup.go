@@ -0,0 +1,214 @@
+// Package indexing runs the consumer groups of the indexing service: each group reads the IDs the
+// sync publishes on its topic, loads their rows from Postgres and hands them to a sink, such as
+// the OpenSearch indexer.
+package indexing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/opensearch"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/transport"
+)
+
+// DefaultGroups are the consumer groups of the indexing service and the topic each reads, as
+// "group:topic" entries
+var DefaultGroups = []string{
+	"stories_group:StoriesTopic", "asks_group:AsksTopic", "comments_group:CommentsTopic",
+	"jobs_group:JobsTopic", "polls_group:PollsTopic", "poll_options_group:PollOptionsTopic",
+	"users_group:UsersTopic",
+}
+
+// UsersTopic carries the usernames of the saved profiles
+const UsersTopic = "UsersTopic"
+
+// Group is a consumer group and the topic it reads
+type Group struct {
+	Name  string
+	Topic string
+}
+
+// Groups returns the groups of INDEXER_CONSUMER_GROUPS ("group:topic" entries)
+func Groups() ([]Group, error) {
+	var groups []Group
+	for _, entry := range config.GetEnvList("INDEXER_CONSUMER_GROUPS", DefaultGroups) {
+		name, topic, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || topic == "" {
+			return nil, fmt.Errorf("invalid INDEXER_CONSUMER_GROUPS entry %q: expected group:topic", entry)
+		}
+		groups = append(groups, Group{Name: name, Topic: topic})
+	}
+	return groups, nil
+}
+
+// Sink receives the documents of the consumed items, replacing those with the same IDs.
+// *opensearch.IndexerService is one.
+type Sink interface {
+	Index(ctx context.Context, kind string, docs []opensearch.Document) error
+}
+
+// Loader returns the stored row of an item of the kind, users being identified by username;
+// sql.ErrNoRows when it is not stored
+type Loader func(ctx context.Context, kind, id string) (interface{}, error)
+
+// LoadStored loads an item from its Postgres table
+func LoadStored(ctx context.Context, kind, id string) (interface{}, error) {
+	if kind == "user" {
+		return postgres.NewUserRepository().GetByIDString(ctx, id)
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "story":
+		return postgres.NewStoryRepository().GetByID(ctx, n)
+	case "ask":
+		return postgres.NewAskRepository().GetByID(ctx, n)
+	case "job":
+		return postgres.NewJobRepository().GetByID(ctx, n)
+	case "comment":
+		return postgres.NewCommentRepository().GetByID(ctx, n)
+	case "poll":
+		return postgres.NewPollRepository().GetByID(ctx, n)
+	case "pollopt":
+		return postgres.NewPollOptionRepository().GetByID(ctx, n)
+	}
+	return nil, postgres.ErrUnknownKind
+}
+
+// IndexHandler returns the handler of a topic carrying the IDs of items of the kind: it loads
+// the item and sends it to sink. Malformed IDs and items no longer stored are skipped; load and
+// sink errors are returned for the message to be redelivered.
+func IndexHandler(kind string, load Loader, sink Sink) transport.Handler {
+	return func(ctx context.Context, msg transport.Message) error {
+		id := string(msg.Value)
+		if kind != "user" {
+			n, err := strconv.Atoi(id)
+			if err != nil {
+				log.Printf("Skipping malformed %s ID %q on %s", kind, msg.Value, msg.Topic)
+				return nil
+			}
+			id = strconv.Itoa(n)
+		}
+
+		item, err := load(ctx, kind, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load %s %s: %w", kind, id, err)
+		}
+		return sink.Index(ctx, kind, []opensearch.Document{{ID: id, Source: item}})
+	}
+}
+
+// ConsumerGroup runs a consumer of the configured transport per group, each handing the messages
+// of its topic to the handler registered for it
+type ConsumerGroup struct {
+	groups   []Group
+	handlers map[string]transport.Handler // by topic
+	backoff  time.Duration
+}
+
+// NewConsumerGroup creates the consumers of groups. A consumer stopping with an error is
+// restarted after INDEXER_RESTART_BACKOFF.
+func NewConsumerGroup(groups []Group) *ConsumerGroup {
+	return &ConsumerGroup{
+		groups:   groups,
+		handlers: make(map[string]transport.Handler),
+		backoff:  config.GetEnvDuration("INDEXER_RESTART_BACKOFF", 5*time.Second),
+	}
+}
+
+// NewIndexingGroup creates the consumers of INDEXER_CONSUMER_GROUPS, indexing into sink the
+// stored rows of the item and user IDs they read. Topics of kinds without a search index, such as
+// poll options, get no handler.
+func NewIndexingGroup(sink Sink) (*ConsumerGroup, error) {
+	groups, err := Groups()
+	if err != nil {
+		return nil, err
+	}
+	g := NewConsumerGroup(groups)
+	topics := map[string]string{UsersTopic: "user"}
+	for kind, topic := range transport.ItemTopics {
+		topics[topic] = kind
+	}
+	for topic, kind := range topics {
+		if _, err := opensearch.IndexName(kind); err == nil {
+			g.Handle(topic, IndexHandler(kind, LoadStored, sink))
+		}
+	}
+	return g, nil
+}
+
+// Handle registers the handler of the messages of topic
+func (g *ConsumerGroup) Handle(topic string, handler transport.Handler) {
+	g.handlers[topic] = handler
+}
+
+// Run consumes every group whose topic has a handler until ctx is cancelled, then closes the
+// consumers once they are done with their messages in flight; the group is redelivered those
+// whose offsets were not committed. It returns an error only when a consumer cannot be created.
+func (g *ConsumerGroup) Run(ctx context.Context) error {
+	var consumers []transport.Consumer
+	defer func() {
+		for _, consumer := range consumers {
+			if err := consumer.Close(); err != nil {
+				log.Printf("Error closing indexing consumer: %v", err)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for _, group := range g.groups {
+		handler, ok := g.handlers[group.Topic]
+		if !ok {
+			log.Printf("No indexing handler for topic %s, not consuming it as %s", group.Topic, group.Name)
+			continue
+		}
+		consumer, err := transport.NewConsumer(group.Name)
+		if err != nil {
+			return fmt.Errorf("failed to create consumer %s: %w", group.Name, err)
+		}
+		consumers = append(consumers, consumer)
+
+		wg.Add(1)
+		go func(group Group) {
+			defer wg.Done()
+			g.consume(ctx, consumer, group, handler)
+		}(group)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// consume runs a consumer, restarting it after a failure, until ctx is cancelled
+func (g *ConsumerGroup) consume(ctx context.Context, consumer transport.Consumer, group Group, handler transport.Handler) {
+	log.Printf("Indexing consumer %s started on %s", group.Name, group.Topic)
+	for {
+		err := consumer.Consume(ctx, group.Topic, handler)
+		if ctx.Err() != nil {
+			log.Printf("Indexing consumer %s stopped", group.Name)
+			return
+		}
+		log.Printf("Indexing consumer %s failed, restarting in %v: %v", group.Name, g.backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(g.backoff):
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package kafka
 
 import (
+	"time"
+
 	"internship-project/internal/config"
 )
 
@@ -10,6 +12,15 @@ type KafkaConfig struct {
 	ClientID         string `yaml:"client_id"`
 	Acks             string `yaml:"acks"`
 	Topic            string `yaml:"topics"`
+
+	// Producer settings
+	Compression  string        `yaml:"compression"`   // none, gzip, snappy, lz4 or zstd
+	BatchSize    int           `yaml:"batch_size"`    // messages per produce request
+	BatchBytes   int           `yaml:"batch_bytes"`   // bytes per produce request
+	Linger       time.Duration `yaml:"linger"`        // how long a partial batch waits for more messages
+	WriteTimeout time.Duration `yaml:"write_timeout"` // per produce request
+	MaxAttempts  int           `yaml:"max_attempts"`  // produce attempts before a write fails
+	Async        bool          `yaml:"async"`         // return before the broker acknowledges; failures are only logged
 }
 
 // GetKafkaConfig returns the Kafka configuration from environment variables
@@ -18,6 +29,13 @@ func GetKafkaConfig() KafkaConfig {
 		BootstrapServers: config.GetEnv("KAFKA_BOOTSTRAP_SERVERS", "localhost:9092"),
 		ClientID:         config.GetEnv("KAFKA_CLIENT_ID", "my-client"),
 		Acks:             config.GetEnv("KAFKA_ACKS", "all"),
-		Topic:            config.GetEnv("KAFKA_TOPICS", "StoriesTopic,CommentsTopic,AsksTopic,JobsTopic,PollsTopic,PollOptionsTopic,UsersTopic"),
+		Topic:            config.GetEnv("KAFKA_TOPICS", "StoriesTopic,CommentsTopic,AsksTopic,JobsTopic,PollsTopic,PollOptionsTopic,UsersTopic,TombstonesTopic"),
+		Compression:      config.GetEnv("KAFKA_COMPRESSION", "none"),
+		BatchSize:        config.GetEnvInt("KAFKA_BATCH_SIZE", 100),
+		BatchBytes:       config.GetEnvInt("KAFKA_BATCH_BYTES", 1<<20),
+		Linger:           config.GetEnvDuration("KAFKA_LINGER", time.Second),
+		WriteTimeout:     config.GetEnvDuration("KAFKA_WRITE_TIMEOUT", 10*time.Second),
+		MaxAttempts:      config.GetEnvInt("KAFKA_MAX_ATTEMPTS", 10),
+		Async:            config.GetEnvBool("KAFKA_ASYNC", false),
 	}
 }
@@ -0,0 +1,158 @@
+package loadshed
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/tracing"
+)
+
+var (
+	// APILatency holds the durations of recent API requests; the HTTP server feeds it
+	APILatency = NewLatencyWindow(1024, config.GetEnvDuration("LOAD_SHED_WINDOW", 30*time.Second))
+
+	// shedWaits counts the pauses sync persistence took to leave room for API traffic
+	shedWaits = expvar.NewInt("load_shed_waits")
+)
+
+func init() {
+	expvar.Publish("api_latency_p99_ms", expvar.Func(func() interface{} {
+		return APILatency.P99().Milliseconds()
+	}))
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// LatencyWindow keeps the last samples observed within a time window
+type LatencyWindow struct {
+	mu      sync.Mutex
+	samples []latencySample
+	next    int
+	maxAge  time.Duration
+}
+
+// NewLatencyWindow creates a window of at most size samples younger than maxAge
+func NewLatencyWindow(size int, maxAge time.Duration) *LatencyWindow {
+	return &LatencyWindow{samples: make([]latencySample, 0, size), maxAge: maxAge}
+}
+
+// Observe records a duration
+func (w *LatencyWindow) Observe(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sample := latencySample{at: time.Now(), duration: d}
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, sample)
+		return
+	}
+	w.samples[w.next] = sample
+	w.next = (w.next + 1) % len(w.samples)
+}
+
+// P99 returns the 99th percentile of the samples in the window, 0 when there are none
+func (w *LatencyWindow) P99() time.Duration {
+	cutoff := time.Now().Add(-w.maxAge)
+
+	w.mu.Lock()
+	durations := make([]time.Duration, 0, len(w.samples))
+	for _, s := range w.samples {
+		if s.at.After(cutoff) {
+			durations = append(durations, s.duration)
+		}
+	}
+	w.mu.Unlock()
+
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations[(len(durations)*99-1)/100]
+}
+
+// Governor decides when background writes should back off so the read path stays responsive
+type Governor struct {
+	latency      *LatencyWindow
+	dbStats      func() sql.DBStats
+	p99Threshold time.Duration
+	saturation   float64
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	maxWait      time.Duration
+}
+
+// NewGovernor creates a governor watching latency and the connection pool reported by dbStats.
+// It is configured by LOAD_SHED_P99_THRESHOLD, LOAD_SHED_DB_SATURATION (in-use share of the
+// pool), LOAD_SHED_BASE_DELAY and LOAD_SHED_MAX_DELAY (pause bounds) and LOAD_SHED_MAX_WAIT
+// (longest a write is held back before it proceeds anyway).
+func NewGovernor(latency *LatencyWindow, dbStats func() sql.DBStats) *Governor {
+	return &Governor{
+		latency:      latency,
+		dbStats:      dbStats,
+		p99Threshold: config.GetEnvDuration("LOAD_SHED_P99_THRESHOLD", 500*time.Millisecond),
+		saturation:   config.GetEnvFloat("LOAD_SHED_DB_SATURATION", 0.8),
+		baseDelay:    config.GetEnvDuration("LOAD_SHED_BASE_DELAY", time.Second),
+		maxDelay:     config.GetEnvDuration("LOAD_SHED_MAX_DELAY", 30*time.Second),
+		maxWait:      config.GetEnvDuration("LOAD_SHED_MAX_WAIT", 2*time.Minute),
+	}
+}
+
+// Overloaded reports whether a threshold is crossed and which one
+func (g *Governor) Overloaded() (bool, string) {
+	if p99 := g.latency.P99(); g.p99Threshold > 0 && p99 >= g.p99Threshold {
+		return true, fmt.Sprintf("API p99 latency %v >= %v", p99, g.p99Threshold)
+	}
+	if g.dbStats != nil && g.saturation > 0 {
+		stats := g.dbStats()
+		if stats.MaxOpenConnections > 0 {
+			used := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+			if used >= g.saturation {
+				return true, fmt.Sprintf("%d of %d database connections in use", stats.InUse, stats.MaxOpenConnections)
+			}
+		}
+	}
+	return false, ""
+}
+
+// Wait blocks while the governor is overloaded, doubling the pause from the base delay up to
+// the max delay, and returns how long it waited. It gives up after the max wait so writes are
+// only delayed, never dropped, and returns early when ctx is done.
+func (g *Governor) Wait(ctx context.Context) time.Duration {
+	var waited time.Duration
+	if g.baseDelay <= 0 {
+		return waited
+	}
+	delay := g.baseDelay
+	for waited < g.maxWait {
+		overloaded, reason := g.Overloaded()
+		if !overloaded {
+			break
+		}
+		if delay > g.maxWait-waited {
+			delay = g.maxWait - waited
+		}
+		shedWaits.Add(1)
+		tracing.Logf(ctx, "Load shedding: %s, delaying writes by %v", reason, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return waited
+		case <-timer.C:
+		}
+		waited += delay
+		if delay *= 2; delay > g.maxDelay {
+			delay = g.maxDelay
+		}
+	}
+	return waited
+}
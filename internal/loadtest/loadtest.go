@@ -0,0 +1,252 @@
+// Package loadtest drives synthetic items through the sync pipeline at a fixed rate, bypassing
+// the HackerNews API, and measures how the persistence and publishing stages keep up
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"internship-project/internal/models"
+)
+
+const (
+	// Author is the author of every synthetic story, so a run can be cleaned up by author
+	Author = "loadtest"
+	// Source is the source of every synthetic story
+	Source = "loadtest"
+	// IDBase is the first synthetic story ID, far above the HackerNews IDs
+	IDBase = 1_900_000_000
+)
+
+// Options controls a load test run
+type Options struct {
+	Rate      int           // items generated per second
+	Duration  time.Duration // how long items are generated
+	BatchSize int           // items per batch handed to the stages
+	Workers   int           // batches processed concurrently
+	QueueSize int           // batches buffered between the generator and the workers
+}
+
+// Stage processes a batch of synthetic stories
+type Stage func(ctx context.Context, stories []*models.Story) error
+
+// Pipeline holds the stages every batch goes through; Publish may be nil
+type Pipeline struct {
+	Write   Stage
+	Publish Stage
+}
+
+// LatencyStats summarizes the durations of a stage in milliseconds
+type LatencyStats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// Report is the outcome of a load test run
+type Report struct {
+	Rate           int          `json:"rate"`
+	Generated      int          `json:"generated"`
+	Written        int          `json:"written"`
+	Failed         int          `json:"failed"`
+	ElapsedSeconds float64      `json:"elapsed_seconds"`
+	Throughput     float64      `json:"throughput"` // written items per second
+	WriteLatency   LatencyStats `json:"write_latency"`
+	PublishLatency LatencyStats `json:"publish_latency"`
+	MaxQueueDepth  int          `json:"max_queue_depth"` // batches waiting for a worker
+	AvgQueueDepth  float64      `json:"avg_queue_depth"`
+	Stalls         int          `json:"stalls"` // batches the generator had to wait to enqueue
+	Errors         []string     `json:"errors,omitempty"`
+}
+
+// maxReportedErrors bounds the distinct errors kept in a report
+const maxReportedErrors = 10
+
+// String renders the report for the terminal
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "target %d items/s: generated %d, written %d, failed %d in %.1fs (%.1f items/s)\n",
+		r.Rate, r.Generated, r.Written, r.Failed, r.ElapsedSeconds, r.Throughput)
+	fmt.Fprintf(&b, "write latency:   %s\n", r.WriteLatency)
+	if r.PublishLatency.Count > 0 {
+		fmt.Fprintf(&b, "publish latency: %s\n", r.PublishLatency)
+	}
+	fmt.Fprintf(&b, "queue depth: max %d, avg %.1f batches; %d stalls", r.MaxQueueDepth, r.AvgQueueDepth, r.Stalls)
+	for _, err := range r.Errors {
+		fmt.Fprintf(&b, "\nerror: %s", err)
+	}
+	return b.String()
+}
+
+// String renders the latency percentiles
+func (s LatencyStats) String() string {
+	return fmt.Sprintf("%d batches, p50 %.1fms, p95 %.1fms, p99 %.1fms, max %.1fms", s.Count, s.P50, s.P95, s.P99, s.Max)
+}
+
+// Run generates opts.Rate synthetic stories per second for opts.Duration, hands them to the
+// pipeline in batches and waits for the queued batches before reporting. Cancelling ctx stops
+// the generation early.
+func Run(ctx context.Context, opts Options, pipeline Pipeline) (*Report, error) {
+	if opts.Rate <= 0 || opts.Duration <= 0 || pipeline.Write == nil {
+		return nil, fmt.Errorf("a positive rate and duration and a write stage are required")
+	}
+	opts.BatchSize = min(max(opts.BatchSize, 1), opts.Rate)
+	opts.Workers = max(opts.Workers, 1)
+	opts.QueueSize = max(opts.QueueSize, 1)
+
+	r := &run{pipeline: pipeline, queue: make(chan []*models.Story, opts.QueueSize)}
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+
+	start := time.Now()
+	generated, stalls, depths := r.generate(ctx, opts)
+	close(r.queue)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := &Report{
+		Rate:           opts.Rate,
+		Generated:      generated,
+		Written:        r.written,
+		Failed:         r.failed,
+		ElapsedSeconds: elapsed.Seconds(),
+		Throughput:     float64(r.written) / elapsed.Seconds(),
+		WriteLatency:   summarize(r.writeLatencies),
+		PublishLatency: summarize(r.publishLatencies),
+		Stalls:         stalls,
+		Errors:         r.errors,
+	}
+	for _, depth := range depths {
+		report.MaxQueueDepth = max(report.MaxQueueDepth, depth)
+		report.AvgQueueDepth += float64(depth) / float64(len(depths))
+	}
+	return report, nil
+}
+
+// run holds the state shared by the generator and the workers of a load test
+type run struct {
+	pipeline Pipeline
+	queue    chan []*models.Story
+
+	mu               sync.Mutex
+	written, failed  int
+	writeLatencies   []time.Duration
+	publishLatencies []time.Duration
+	errors           []string
+}
+
+// generate enqueues a batch every BatchSize/Rate seconds until the duration is over, sampling
+// the queue depth before each batch
+func (r *run) generate(ctx context.Context, opts Options) (generated, stalls int, depths []int) {
+	interval := time.Duration(float64(time.Second) * float64(opts.BatchSize) / float64(opts.Rate))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(opts.Duration)
+	defer deadline.Stop()
+
+	nextID := IDBase
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().Unix()
+		batch := make([]*models.Story, opts.BatchSize)
+		for i := range batch {
+			batch[i] = syntheticStory(nextID, now)
+			nextID++
+		}
+		depths = append(depths, len(r.queue))
+
+		select {
+		case r.queue <- batch:
+		default:
+			stalls++
+			select {
+			case r.queue <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+		generated += len(batch)
+	}
+}
+
+// work runs the queued batches through the pipeline until the queue is closed
+func (r *run) work(ctx context.Context) {
+	for batch := range r.queue {
+		start := time.Now()
+		err := r.pipeline.Write(ctx, batch)
+		writeLatency := time.Since(start)
+
+		var publishLatency time.Duration
+		if err == nil && r.pipeline.Publish != nil {
+			start = time.Now()
+			err = r.pipeline.Publish(ctx, batch)
+			publishLatency = time.Since(start)
+		}
+
+		r.mu.Lock()
+		r.writeLatencies = append(r.writeLatencies, writeLatency)
+		if publishLatency > 0 {
+			r.publishLatencies = append(r.publishLatencies, publishLatency)
+		}
+		if err != nil {
+			r.failed += len(batch)
+			if len(r.errors) < maxReportedErrors {
+				r.errors = append(r.errors, err.Error())
+			}
+		} else {
+			r.written += len(batch)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// syntheticStory builds a valid story the ETL plugins and repositories accept
+func syntheticStory(id int, now int64) *models.Story {
+	return &models.Story{
+		ID:         id,
+		Type:       "story",
+		Title:      fmt.Sprintf("Load test story %d", id),
+		URL:        fmt.Sprintf("https://loadtest.invalid/%d", id),
+		Score:      id % 500,
+		Author:     Author,
+		Created_At: now,
+		Source:     Source,
+	}
+}
+
+// summarize computes the percentiles of the durations
+func summarize(durations []time.Duration) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	at := func(p int) float64 {
+		return float64(durations[(len(durations)*p-1)/100].Microseconds()) / 1000
+	}
+	return LatencyStats{
+		Count: len(durations),
+		P50:   at(50),
+		P95:   at(95),
+		P99:   at(99),
+		Max:   float64(durations[len(durations)-1].Microseconds()) / 1000,
+	}
+}
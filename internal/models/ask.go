@@ -1,17 +1,18 @@
 package models
 
-// Ask represents a Hacker News ask post with its details.
-// It includes fields for ID, type, title, text, score, author, creation time, and a list of reply IDs.
+// Ask represents an Ask HN post. The HackerNews API serves asks as stories, typed "story";
+// HNItem.ToAsk types them "ask".
 type Ask struct {
-	ID            int    `json:"id" db:"id"`
-	Type          string `json:"type" db:"type"`
-	Title         string `json:"title" db:"title"`
-	Text          string `json:"text" db:"text"`
-	Score         int    `json:"score" db:"score"`
-	Author        string `json:"by" db:"author"`
-	Reply_ids     []int  `json:"kids" db:"reply_ids"`
-	Replies_count int    `json:"descendants" db:"replies_count"`
-	Created_At    int64  `json:"time" db:"created_at"`
+	ID            int        `json:"id" db:"id"`
+	Type          string     `json:"type" db:"type"`
+	Title         string     `json:"title" db:"title"`
+	Text          string     `json:"text" db:"text"`
+	Score         int        `json:"score" db:"score"`
+	Author        string     `json:"by" db:"author"`
+	Reply_ids     []int      `json:"kids" db:"reply_ids"`
+	Replies_count int        `json:"descendants" db:"replies_count"`
+	Created_At    int64      `json:"time" db:"created_at"`
+	Extra         Attributes `json:"extra,omitempty" db:"extra"` // unknown API fields; read by GetByID only
 }
 
 func (a *Ask) IsValid() bool {
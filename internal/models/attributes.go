@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Attributes are the fields of an API item the stored columns have no place for, kept as served
+// in the extra JSONB column of the item tables until they get one
+type Attributes map[string]json.RawMessage
+
+// Value stores the attributes as a JSON object, "{}" when there are none
+func (a Attributes) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return "{}", nil
+	}
+	// Sent as text: lib/pq would encode []byte as bytea
+	b, err := json.Marshal(map[string]json.RawMessage(a))
+	return string(b), err
+}
+
+// Scan reads the attributes from a JSON object; NULL and "{}" leave them nil
+func (a *Attributes) Scan(src interface{}) error {
+	var b []byte
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into Attributes", src)
+	}
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attributes); err != nil {
+		return err
+	}
+	*a = nil
+	if len(attributes) > 0 {
+		*a = attributes
+	}
+	return nil
+}
+
+// hnItemFields are the JSON names of the HNItem fields
+var hnItemFields = jsonFieldNames(reflect.TypeOf(HNItem{}))
+
+// jsonFieldNames returns the JSON names of the fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
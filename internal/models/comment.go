@@ -9,6 +9,15 @@ type Comment struct {
 	Parent     int    `json:"parent" db:"parent_id"`
 	Replies    []int  `json:"kids" db:"reply_ids"`
 	Created_At int64  `json:"time" db:"created_at"`
+	Source     string `json:"source,omitempty" db:"source"`     // feed the comment came from, see SourceHackerNews
+	Depth      int    `json:"depth,omitempty" db:"depth"`       // levels below the root item, 1 for top-level comments
+	Rank       int    `json:"rank,omitempty" db:"sibling_rank"` // 1-based position among the parent's kids, 0 when unknown
+
+	// Quality and Quality_Rank are the score of the comment ranking job and the 1-based position
+	// among the siblings by that score; set by CommentRepository.GetThread, 0 until ranked
+	Quality      float64    `json:"quality,omitempty" db:"quality"`
+	Quality_Rank int        `json:"quality_rank,omitempty" db:"quality_rank"`
+	Extra        Attributes `json:"extra,omitempty" db:"extra"` // unknown API fields; read by GetByID only
 }
 
 func (c *Comment) IsValid() bool {
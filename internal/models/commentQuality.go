@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// CommentSignals are the structural signals a comment is ranked on; HN does not expose comment scores
+type CommentSignals struct {
+	ID      int
+	Parent  int
+	Replies int // direct replies, stored or not
+	Depth   int // levels below the root item, 1 for top-level comments
+	Length  int // characters of the text
+	Karma   int // karma of the author, 0 when their account is not stored
+}
+
+// CommentQualityWeights weighs the signals in the quality score of a comment
+type CommentQualityWeights struct {
+	Replies float64
+	Depth   float64
+	Karma   float64
+	Length  float64
+}
+
+// Score returns the quality score of a comment: its replies, author karma and length on a log
+// scale so no signal drowns the others, minus a penalty growing with depth
+func (w CommentQualityWeights) Score(s CommentSignals) float64 {
+	return w.Replies*math.Log1p(float64(s.Replies)) +
+		w.Karma*math.Log1p(float64(s.Karma)) +
+		w.Length*math.Log1p(float64(s.Length)) -
+		w.Depth*float64(s.Depth)
+}
+
+// CommentQuality is the quality score of a comment and its 1-based rank among its siblings
+type CommentQuality struct {
+	ID    int
+	Score float64
+	Rank  int
+}
+
+// RankComments scores the comments and ranks them among the siblings in signals, best first;
+// ties go to the older comment, the one with the lower ID
+func RankComments(signals []*CommentSignals, weights CommentQualityWeights) []*CommentQuality {
+	siblings := make(map[int][]*CommentQuality)
+	for _, s := range signals {
+		siblings[s.Parent] = append(siblings[s.Parent], &CommentQuality{ID: s.ID, Score: weights.Score(*s)})
+	}
+
+	ranked := make([]*CommentQuality, 0, len(signals))
+	for _, group := range siblings {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Score != group[j].Score {
+				return group[i].Score > group[j].Score
+			}
+			return group[i].ID < group[j].ID
+		})
+		for i, q := range group {
+			q.Rank = i + 1
+		}
+		ranked = append(ranked, group...)
+	}
+	return ranked
+}
+
+// SortByQuality orders comments by their quality rank, unranked ones last, keeping the relative
+// order of equal ranks; OrderThread then renders the siblings best first
+func SortByQuality(comments []*Comment) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		a, b := comments[i].Quality_Rank, comments[j].Quality_Rank
+		return a != 0 && (b == 0 || a < b)
+	})
+}
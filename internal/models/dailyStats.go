@@ -0,0 +1,26 @@
+package models
+
+// TypeStats summarizes the items of one type created on a day
+type TypeStats struct {
+	Type        string   `json:"type" db:"type"`
+	ItemCount   int      `json:"item_count" db:"item_count"`
+	MedianScore *float64 `json:"median_score,omitempty" db:"median_score"` // nil for types without a score
+}
+
+// RankedCount is one entry of a daily top-N list (domain or author)
+type RankedCount struct {
+	Rank      int    `json:"rank" db:"rank"`
+	Name      string `json:"name"`
+	ItemCount int    `json:"item_count" db:"item_count"`
+}
+
+// DailyStats holds the precomputed aggregates of one UTC day for a tenant
+type DailyStats struct {
+	Day        string        `json:"day"` // YYYY-MM-DD
+	Types      []TypeStats   `json:"types"`
+	TopDomains []RankedCount `json:"top_domains"`
+	TopAuthors []RankedCount `json:"top_authors"`
+
+	// ScorePercentiles is the rolling story score distribution ending on the day; nil until computed
+	ScorePercentiles *ScorePercentiles `json:"score_percentiles,omitempty"`
+}
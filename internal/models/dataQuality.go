@@ -0,0 +1,25 @@
+package models
+
+// DataQualityCheck is the result of one anomaly check
+type DataQualityCheck struct {
+	Name      string `json:"name"`
+	Count     int64  `json:"count"`                // anomalous rows (or missing documents for index checks)
+	SampleIDs []int  `json:"sample_ids,omitempty"` // a few offending item IDs
+	Detail    string `json:"detail,omitempty"`
+}
+
+// DataQualityReport gathers the results of a run of the data-quality job
+type DataQualityReport struct {
+	ID         string             `json:"id" db:"id"` // UUIDv7
+	Created_At int64              `json:"created_at" db:"created_at"`
+	Checks     []DataQualityCheck `json:"checks" db:"checks"`
+}
+
+// Anomalies returns the total count of anomalies found by the checks
+func (r *DataQualityReport) Anomalies() int64 {
+	var total int64
+	for _, check := range r.Checks {
+		total += check.Count
+	}
+	return total
+}
@@ -0,0 +1,22 @@
+package models
+
+import "encoding/json"
+
+// Dead letter reasons
+const (
+	DeadLetterSchemaViolation = "schema_violation" // the payload does not match the schema of its type
+	DeadLetterGone            = "gone"             // the API served null for the ID
+)
+
+// DeadLetter is an item payload rejected before persistence, kept as served by the API
+type DeadLetter struct {
+	ID          int64           `json:"id" db:"id"`
+	Item_ID     int             `json:"item_id" db:"item_id"`
+	Kind        string          `json:"kind" db:"kind"` // type of the payload, empty when it has none
+	Reason      string          `json:"reason" db:"reason"`
+	Errors      []string        `json:"errors" db:"errors"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Occurrences int             `json:"occurrences" db:"occurrences"` // times the same payload was rejected
+	Created_At  int64           `json:"created_at" db:"created_at"`
+	Updated_At  int64           `json:"updated_at" db:"updated_at"` // when it was last rejected
+}
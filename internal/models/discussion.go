@@ -0,0 +1,100 @@
+package models
+
+import "sort"
+
+// DiscussionSummary outlines the comment tree of a story for previews
+type DiscussionSummary struct {
+	Story_ID   int                 `json:"story_id"`
+	Comments   int                 `json:"comments"`
+	Commenters int                 `json:"commenters"` // distinct comment authors
+	Max_Depth  int                 `json:"max_depth"`  // top-level comments are at depth 1
+	Branches   []*DiscussionBranch `json:"branches"`
+}
+
+// DiscussionBranch is a top-level comment with the stats of the replies below it
+type DiscussionBranch struct {
+	Comment    *Comment `json:"comment"`
+	Replies    int      `json:"replies"`    // comments below the top-level one, at any depth
+	Depth      int      `json:"depth"`      // levels of the branch, 1 for a comment without replies
+	Commenters int      `json:"commenters"` // distinct authors in the branch
+}
+
+// SummarizeDiscussion computes the summary of the comment tree under a story from its comments,
+// keeping the maxBranches branches with the most replies (the oldest first on ties). Comments
+// whose parent is neither the story nor another of the comments are ignored.
+func SummarizeDiscussion(storyID int, comments []*Comment, maxBranches int) *DiscussionSummary {
+	children := make(map[int][]*Comment)
+	for _, comment := range comments {
+		children[comment.Parent] = append(children[comment.Parent], comment)
+	}
+
+	summary := &DiscussionSummary{Story_ID: storyID, Branches: []*DiscussionBranch{}}
+	authors := make(map[string]bool)
+	for _, top := range children[storyID] {
+		branch := &DiscussionBranch{Comment: top}
+		branchAuthors := make(map[string]bool)
+
+		// walk the branch breadth first, one level at a time
+		level := []*Comment{top}
+		for depth := 1; len(level) > 0; depth++ {
+			branch.Depth = depth
+			var next []*Comment
+			for _, comment := range level {
+				branchAuthors[comment.Author] = true
+				authors[comment.Author] = true
+				next = append(next, children[comment.ID]...)
+			}
+			branch.Replies += len(next)
+			level = next
+		}
+
+		branch.Commenters = len(branchAuthors)
+		summary.Comments += 1 + branch.Replies
+		summary.Max_Depth = max(summary.Max_Depth, branch.Depth)
+		summary.Branches = append(summary.Branches, branch)
+	}
+	summary.Commenters = len(authors)
+
+	sort.SliceStable(summary.Branches, func(i, j int) bool {
+		a, b := summary.Branches[i], summary.Branches[j]
+		if a.Replies != b.Replies {
+			return a.Replies > b.Replies
+		}
+		return a.Comment.Created_At < b.Comment.Created_At
+	})
+	if len(summary.Branches) > maxBranches {
+		summary.Branches = summary.Branches[:maxBranches]
+	}
+	return summary
+}
+
+// OrderThread arranges the comments under rootID depth first, each comment followed by its
+// replies, so they can be rendered top to bottom with their Depth as indentation. Siblings keep
+// their relative order in comments; comments not connected to rootID are dropped.
+func OrderThread(rootID int, comments []*Comment) []*Comment {
+	children := make(map[int][]*Comment)
+	for _, comment := range comments {
+		children[comment.Parent] = append(children[comment.Parent], comment)
+	}
+
+	ordered := make([]*Comment, 0, len(comments))
+	visited := make(map[int]bool, len(comments))
+	// push siblings in reverse so the first one is popped first
+	push := func(stack []*Comment, parent int) []*Comment {
+		replies := children[parent]
+		for i := len(replies) - 1; i >= 0; i-- {
+			if !visited[replies[i].ID] {
+				visited[replies[i].ID] = true
+				stack = append(stack, replies[i])
+			}
+		}
+		return stack
+	}
+	stack := push(nil, rootID)
+	for len(stack) > 0 {
+		comment := stack[len(stack)-1]
+		ordered = append(ordered, comment)
+		stack = push(stack[:len(stack)-1], comment.ID)
+	}
+	return ordered
+}
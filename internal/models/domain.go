@@ -0,0 +1,21 @@
+package models
+
+// Domain aggregates the stories linking to a site, refreshed periodically by the domains job
+type Domain struct {
+	Domain           string  `json:"domain" db:"domain"` // lowercase host without "www."
+	FaviconURL       string  `json:"favicon_url,omitempty" db:"favicon_url"`
+	FirstSeen        int64   `json:"first_seen" db:"first_seen"` // creation time of its oldest story
+	LastSeen         int64   `json:"last_seen" db:"last_seen"`
+	StoryCount       int     `json:"story_count" db:"story_count"`
+	AverageScore     float64 `json:"average_score" db:"average_score"`
+	TopScore         int     `json:"top_score" db:"top_score"`
+	CommentCount     int     `json:"comment_count" db:"comment_count"`
+	RefreshedAt      int64   `json:"refreshed_at" db:"refreshed_at"`
+	FaviconCheckedAt int64   `json:"-" db:"favicon_checked_at"`
+}
+
+// DomainSummary is a domain with its most recent stories
+type DomainSummary struct {
+	*Domain
+	RecentStories []*Story `json:"recent_stories"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// Follow is an author followed by a tenant for its personalized feed
+type Follow struct {
+	Author     string `json:"author" db:"author"`
+	Created_At int64  `json:"created_at" db:"created_at"`
+}
+
+// SavedSearch is a title query whose matches are added to a tenant's feed
+type SavedSearch struct {
+	ID         int    `json:"id" db:"id"`
+	Query      string `json:"query" db:"query"`
+	Created_At int64  `json:"created_at" db:"created_at"`
+}
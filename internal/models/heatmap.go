@@ -0,0 +1,16 @@
+package models
+
+// HeatmapCell counts an author's items posted in one hour of one weekday (UTC).
+// DayOfWeek follows Postgres DOW numbering: 0 = Sunday ... 6 = Saturday.
+type HeatmapCell struct {
+	DayOfWeek int `json:"day_of_week" db:"day_of_week"`
+	Hour      int `json:"hour" db:"hour"`
+	Count     int `json:"count" db:"count"`
+}
+
+// ActivityHeatmap summarizes when an author posts across all item types
+type ActivityHeatmap struct {
+	Author string        `json:"author"`
+	Total  int           `json:"total"`
+	Cells  []HeatmapCell `json:"cells"`
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// HNItem is an item exactly as served by the HackerNews API (/v0/item/{id}.json). The API has no
+// "ask" type: Ask HN posts are stories, usually with a text and no URL. Deleted items only carry
+// their ID, type, time and parent.
+type HNItem struct {
+	ID          int    `json:"id"`
+	Deleted     bool   `json:"deleted,omitempty"`
+	Type        string `json:"type"`
+	By          string `json:"by,omitempty"`
+	Time        int64  `json:"time"`
+	Text        string `json:"text,omitempty"`
+	Dead        bool   `json:"dead,omitempty"`
+	Parent      int    `json:"parent,omitempty"`      // comment or story a comment replies to
+	Poll        int    `json:"poll,omitempty"`        // poll of a poll option
+	Kids        []int  `json:"kids,omitempty"`        // direct replies, in ranked display order
+	URL         string `json:"url,omitempty"`         // empty for text posts
+	Score       int    `json:"score,omitempty"`       // points of a story, job or poll, votes of a poll option
+	Title       string `json:"title,omitempty"`       // title of a story, job or poll
+	Parts       []int  `json:"parts,omitempty"`       // options of a poll
+	Descendants int    `json:"descendants,omitempty"` // total comment count of a story or poll
+
+	// Extra holds the fields the API served that HNItem does not know, kept by the conversions
+	Extra Attributes `json:"-"`
+}
+
+// UnmarshalJSON decodes the item, collecting its unknown fields into Extra
+func (it *HNItem) UnmarshalJSON(data []byte) error {
+	type plain HNItem
+	if err := json.Unmarshal(data, (*plain)(it)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	it.Extra = nil
+	for name, value := range fields {
+		if hnItemFields[name] {
+			continue
+		}
+		if it.Extra == nil {
+			it.Extra = make(Attributes)
+		}
+		it.Extra[name] = value
+	}
+	return nil
+}
+
+// HNUser is a user exactly as served by the HackerNews API (/v0/user/{id}.json)
+type HNUser struct {
+	ID        string `json:"id"` // the case-sensitive username
+	Created   int64  `json:"created"`
+	Karma     int    `json:"karma"`
+	About     string `json:"about,omitempty"`
+	Submitted []int  `json:"submitted,omitempty"`
+}
+
+// askTitlePrefix starts the title of every Ask HN post
+const askTitlePrefix = "ask hn"
+
+// Kind returns the kind of stored item the API item maps to: its type, except for stories
+// without a URL titled "Ask HN: ...", which are asks
+func (it *HNItem) Kind() string {
+	if it.Type == "story" && it.URL == "" && strings.HasPrefix(strings.ToLower(it.Title), askTitlePrefix) {
+		return "ask"
+	}
+	return it.Type
+}
+
+// ToStory converts the item to the stories column layout
+func (it *HNItem) ToStory() *Story {
+	return &Story{
+		ID:             it.ID,
+		Type:           it.Type,
+		Title:          it.Title,
+		URL:            it.URL,
+		Score:          it.Score,
+		Author:         it.By,
+		Created_At:     it.Time,
+		Comments_ids:   it.Kids,
+		Comments_count: it.Descendants,
+		Extra:          it.Extra,
+	}
+}
+
+// ToAsk converts the item to the asks column layout, typing it "ask"
+func (it *HNItem) ToAsk() *Ask {
+	return &Ask{
+		ID:            it.ID,
+		Type:          "ask",
+		Title:         it.Title,
+		Text:          it.Text,
+		Score:         it.Score,
+		Author:        it.By,
+		Reply_ids:     it.Kids,
+		Replies_count: it.Descendants,
+		Created_At:    it.Time,
+		Extra:         it.Extra,
+	}
+}
+
+// ToJob converts the item to the jobs column layout
+func (it *HNItem) ToJob() *Job {
+	return &Job{
+		ID:         it.ID,
+		Type:       it.Type,
+		Title:      it.Title,
+		Text:       it.Text,
+		URL:        it.URL,
+		Score:      it.Score,
+		Author:     it.By,
+		Created_At: it.Time,
+		Extra:      it.Extra,
+	}
+}
+
+// ToComment converts the item to the comments column layout
+func (it *HNItem) ToComment() *Comment {
+	return &Comment{
+		ID:         it.ID,
+		Type:       it.Type,
+		Text:       it.Text,
+		Author:     it.By,
+		Parent:     it.Parent,
+		Replies:    it.Kids,
+		Created_At: it.Time,
+		Extra:      it.Extra,
+	}
+}
+
+// ToPoll converts the item to the polls column layout
+func (it *HNItem) ToPoll() *Poll {
+	return &Poll{
+		ID:          it.ID,
+		Type:        it.Type,
+		Title:       it.Title,
+		Score:       it.Score,
+		Author:      it.By,
+		Created_At:  it.Time,
+		PollOptions: it.Parts,
+		Reply_Ids:   it.Kids,
+		Extra:       it.Extra,
+	}
+}
+
+// ToPollOption converts the item to the poll_options column layout
+func (it *HNItem) ToPollOption() *PollOption {
+	return &PollOption{
+		ID:         it.ID,
+		Type:       it.Type,
+		PollID:     it.Poll,
+		Author:     it.By,
+		OptionText: it.Text,
+		CreatedAt:  it.Time,
+		Votes:      it.Score,
+		Extra:      it.Extra,
+	}
+}
+
+// ConvertHNItem converts the item to the column layout of T, regardless of its kind
+func ConvertHNItem[T Item](it *HNItem) *T {
+	var item interface{}
+	switch interface{}((*T)(nil)).(type) {
+	case *Story:
+		item = it.ToStory()
+	case *Ask:
+		item = it.ToAsk()
+	case *Job:
+		item = it.ToJob()
+	case *Comment:
+		item = it.ToComment()
+	case *Poll:
+		item = it.ToPoll()
+	case *PollOption:
+		item = it.ToPollOption()
+	}
+	return item.(*T)
+}
+
+// ToUser converts the user to the users column layout
+func (u *HNUser) ToUser() *User {
+	return &User{
+		Username:   u.ID,
+		Karma:      u.Karma,
+		About:      u.About,
+		Created_At: u.Created,
+		Submitted:  u.Submitted,
+	}
+}
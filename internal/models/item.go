@@ -0,0 +1,6 @@
+package models
+
+// Item is the set of HackerNews item types served by the /item/{id} endpoint
+type Item interface {
+	Story | Ask | Job | Comment | Poll | PollOption
+}
@@ -0,0 +1,27 @@
+package models
+
+// ItemChange reports that an item row was inserted or updated; it is the payload of the
+// item_changes notification and a row of the polling fallback
+type ItemChange struct {
+	Kind       string `json:"kind"`
+	ID         int    `json:"id" db:"id"`
+	Updated_At int64  `json:"updated_at" db:"updated_at"` // unix milliseconds
+}
+
+// After reports whether c comes after other in (Updated_At, ID) order
+func (c ItemChange) After(other ItemChange) bool {
+	if c.Updated_At != other.Updated_At {
+		return c.Updated_At > other.Updated_At
+	}
+	return c.ID > other.ID
+}
+
+// SequencedChange is an entry of the item change log. Seq numbers the changes in commit order,
+// so a poller resumes after the last one it read.
+type SequencedChange struct {
+	Seq        int64  `json:"seq" db:"seq"`
+	Kind       string `json:"kind" db:"kind"`
+	ID         int    `json:"id" db:"item_id"`
+	Operation  string `json:"op" db:"operation"`          // "insert", "update" or "delete"
+	Updated_At int64  `json:"updated_at" db:"changed_at"` // unix milliseconds
+}
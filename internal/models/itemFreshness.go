@@ -0,0 +1,8 @@
+package models
+
+// ItemFreshness tells how recent the items of a kind are
+type ItemFreshness struct {
+	Kind              string `json:"kind"`
+	Newest_Created_At int64  `json:"newest_created_at"` // unix seconds, 0 without items
+	Last_Updated_At   int64  `json:"last_updated_at"`   // unix milliseconds, 0 without items
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrHistoryUnavailable is returned by RewindItem for an update logged before the change log kept
+// the values updates replace (schema version 39)
+var ErrHistoryUnavailable = errors.New("history not available")
+
+// ItemRevision is a change of an item from the change log, with the values the change replaced
+// by column name; Previous is nil for inserts and deletes, and for the updates logged before the
+// replaced values were kept
+type ItemRevision struct {
+	Operation  string                     `json:"op" db:"operation"`
+	Changed_At int64                      `json:"changed_at" db:"changed_at"` // unix milliseconds
+	Previous   map[string]json.RawMessage `json:"previous,omitempty" db:"previous"`
+}
+
+// RewindItem sets the fields of item, a pointer to an item struct, back to the values they had
+// before the revisions, which must be ordered newest first. Columns without a field of the same
+// db tag, such as tenant, are skipped. An update without its replaced values cannot be undone
+// and fails with ErrHistoryUnavailable.
+func RewindItem(item interface{}, revisions []*ItemRevision) error {
+	v := reflect.ValueOf(item)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot rewind %T", item)
+	}
+	v = v.Elem()
+	fields := make(map[string]int, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		column, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("db"), ",")
+		if column != "" && column != "-" {
+			fields[column] = i
+		}
+	}
+
+	for _, revision := range revisions {
+		if revision.Operation == "update" && revision.Previous == nil {
+			return fmt.Errorf("%w before %s", ErrHistoryUnavailable,
+				time.UnixMilli(revision.Changed_At).UTC().Format(time.RFC3339))
+		}
+		for column, raw := range revision.Previous {
+			i, ok := fields[column]
+			if !ok {
+				continue
+			}
+			// A fresh value, so a NULL column rewinds to the zero value
+			value := reflect.New(v.Field(i).Type())
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				return fmt.Errorf("failed to rewind %s: %w", column, err)
+			}
+			v.Field(i).Set(value.Elem())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package models
+
+import "encoding/json"
+
+// ItemPayload is the last payload the API served for an item, kept as served
+type ItemPayload struct {
+	Item_ID    int             `json:"item_id" db:"item_id"`
+	Kind       string          `json:"kind" db:"kind"` // type of the payload, empty when it has none
+	Payload    json.RawMessage `json:"payload" db:"payload"`
+	Fetched_At int64           `json:"fetched_at" db:"fetched_at"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// TagCount is a tag with the number of items carrying it
+type TagCount struct {
+	Tag   string `json:"tag" db:"tag"`
+	Items int64  `json:"items" db:"items"`
+}
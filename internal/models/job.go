@@ -1,16 +1,16 @@
 package models
 
-// Job represents a Hacker News job posting with its details.
-// It includes fields for ID, type, text, URL, score, author, and creation time.
+// Job represents a Hacker News job posting; see HNItem.ToJob
 type Job struct {
-	ID         int    `json:"id" db:"id"`
-	Type       string `json:"type" db:"type"`
-	Title      string `json:"title" db:"title"`
-	Text       string `json:"text" db:"text"`
-	URL        string `json:"url" db:"url"`
-	Score      int    `json:"score" db:"score"`
-	Author     string `json:"by" db:"author"`
-	Created_At int64  `json:"time" db:"created_at"`
+	ID         int        `json:"id" db:"id"`
+	Type       string     `json:"type" db:"type"`
+	Title      string     `json:"title" db:"title"`
+	Text       string     `json:"text" db:"text"`
+	URL        string     `json:"url" db:"url"`
+	Score      int        `json:"score" db:"score"`
+	Author     string     `json:"by" db:"author"`
+	Created_At int64      `json:"time" db:"created_at"`
+	Extra      Attributes `json:"extra,omitempty" db:"extra"` // unknown API fields; read by GetByID only
 }
 
 func (j *Job) IsValid() bool {
@@ -0,0 +1,10 @@
+package models
+
+// JobRun is the last run of a scheduled job
+type JobRun struct {
+	Name             string `json:"name" db:"name"`
+	Runs             int64  `json:"runs" db:"runs"`                         // runs recorded since the job was first scheduled
+	Last_Started_At  int64  `json:"last_started_at" db:"last_started_at"`   // unix seconds
+	Last_Finished_At int64  `json:"last_finished_at" db:"last_finished_at"` // unix seconds
+	Last_Duration_Ms int64  `json:"last_duration_ms" db:"last_duration_ms"`
+}
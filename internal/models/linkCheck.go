@@ -0,0 +1,28 @@
+package models
+
+// Link statuses recorded by the story URL check
+const (
+	LinkAlive       = "alive"
+	LinkDead        = "dead"        // the server answered 404/410 or the host does not resolve
+	LinkUnreachable = "unreachable" // timeouts, server errors and other failures that may be transient
+)
+
+// LinkCheck is the outcome of checking a story URL
+type LinkCheck struct {
+	StoryID    int    `json:"id" db:"id"`
+	Title      string `json:"title,omitempty" db:"title"`
+	URL        string `json:"url" db:"url"`
+	Status     string `json:"status" db:"link_status"`
+	StatusCode int    `json:"status_code,omitempty" db:"link_status_code"` // 0 when no response was received
+	CheckedAt  int64  `json:"checked_at" db:"link_checked_at"`
+	ArchiveURL string `json:"archive_url,omitempty" db:"archive_url"` // closest archive.org snapshot, if looked up
+}
+
+// StoryArchive is the outcome of submitting a story URL to the Wayback Machine save API
+type StoryArchive struct {
+	StoryID     int    `json:"id" db:"id"`
+	URL         string `json:"url" db:"url"`
+	Score       int    `json:"score" db:"score"`
+	ArchiveURL  string `json:"archive_url,omitempty" db:"archive_url"` // empty when the save failed
+	RequestedAt int64  `json:"requested_at" db:"archive_requested_at"`
+}
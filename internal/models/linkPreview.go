@@ -0,0 +1,20 @@
+package models
+
+// Link preview statuses
+const (
+	PreviewFound  = "found"  // the page had preview metadata
+	PreviewNone   = "none"   // the page was fetched but is not HTML or has no metadata
+	PreviewFailed = "failed" // the page could not be fetched; retried on a later run
+)
+
+// LinkPreview is the OpenGraph/Twitter card metadata of a story URL, for rendering rich previews
+type LinkPreview struct {
+	StoryID     int    `json:"story_id" db:"story_id"`
+	URL         string `json:"url" db:"url"`
+	Status      string `json:"status" db:"status"`
+	Title       string `json:"title,omitempty" db:"title"`
+	Description string `json:"description,omitempty" db:"description"`
+	ImageURL    string `json:"image_url,omitempty" db:"image_url"` // absolute
+	SiteName    string `json:"site_name,omitempty" db:"site_name"`
+	FetchedAt   int64  `json:"fetched_at" db:"fetched_at"`
+}
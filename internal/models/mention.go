@@ -0,0 +1,18 @@
+package models
+
+// Kinds of mention
+const (
+	MentionAt    = "mention" // the comment text names the user as @username
+	MentionReply = "reply"   // the comment replies to an item by the user
+)
+
+// Mention is a comment addressing a user, either by @username or by replying to their item
+type Mention struct {
+	Comment_ID int    `json:"comment_id" db:"comment_id"`
+	Username   string `json:"username" db:"username"` // the mentioned user
+	Kind       string `json:"kind" db:"kind"`
+	Author     string `json:"by" db:"author"` // the author of the comment
+	Parent     int    `json:"parent" db:"parent_id"`
+	Text       string `json:"text" db:"text"`
+	Created_At int64  `json:"time" db:"created_at"`
+}
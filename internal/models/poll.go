@@ -2,14 +2,15 @@ package models
 
 // Poll represents a Hacker News poll
 type Poll struct {
-	ID          int    `json:"id" db:"id"`
-	Type        string `json:"type" db:"type"`
-	Title       string `json:"title" db:"title"`
-	Score       int    `json:"score" db:"score"`
-	Author      string `json:"by" db:"author"`
-	Created_At  int64  `json:"time" db:"created_at"`
-	PollOptions []int  `json:"parts" db:"poll_options"`
-	Reply_Ids   []int  `json:"kids" db:"reply_ids"`
+	ID          int        `json:"id" db:"id"`
+	Type        string     `json:"type" db:"type"`
+	Title       string     `json:"title" db:"title"`
+	Score       int        `json:"score" db:"score"`
+	Author      string     `json:"by" db:"author"`
+	Created_At  int64      `json:"time" db:"created_at"`
+	PollOptions []int      `json:"parts" db:"poll_options"`
+	Reply_Ids   []int      `json:"kids" db:"reply_ids"`
+	Extra       Attributes `json:"extra,omitempty" db:"extra"` // unknown API fields; read by GetByID only
 }
 
 func (p *Poll) IsValid() bool {
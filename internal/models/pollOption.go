@@ -1,15 +1,18 @@
 package models
 
+// PollOption represents an option of a Hacker News poll; the API types it "pollopt" and
+// serves its votes as its score
 type PollOption struct {
-	ID         int    `json:"id" db:"id"`
-	Type       string `json:"type" db:"type"`
-	PollID     int    `json:"poll" db:"poll_id"`
-	Author     string `json:"by" db:"author"`
-	OptionText string `json:"text" db:"option_text"`
-	CreatedAt  int64  `json:"time" db:"created_at"`
-	Votes      int    `json:"score" db:"votes"`
+	ID         int        `json:"id" db:"id"`
+	Type       string     `json:"type" db:"type"`
+	PollID     int        `json:"poll" db:"poll_id"`
+	Author     string     `json:"by" db:"author"`
+	OptionText string     `json:"text" db:"option_text"`
+	CreatedAt  int64      `json:"time" db:"created_at"`
+	Votes      int        `json:"score" db:"votes"`
+	Extra      Attributes `json:"extra,omitempty" db:"extra"` // unknown API fields; read by GetByID only
 }
 
 func (po *PollOption) IsValid() bool {
-	return po.ID > 0 && po.Type == "pollOption" && po.PollID > 0 && po.OptionText != "" && po.CreatedAt > 0
+	return po.ID > 0 && po.Type == "pollopt" && po.PollID > 0 && po.OptionText != "" && po.CreatedAt > 0
 }
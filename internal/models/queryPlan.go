@@ -0,0 +1,9 @@
+package models
+
+// QueryPlan is the EXPLAIN (ANALYZE, BUFFERS) output of a named repository query
+type QueryPlan struct {
+	Query string        `json:"query"`
+	SQL   string        `json:"sql"`
+	Args  []interface{} `json:"args"`
+	Plan  []string      `json:"plan"`
+}
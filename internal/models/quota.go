@@ -0,0 +1,27 @@
+package models
+
+// Resources a tenant holds a limited number of
+const (
+	QuotaSavedSearches    = "saved_searches"
+	QuotaWatches          = "watches"
+	QuotaWebhookEndpoints = "webhook_endpoints" // distinct webhook URLs among the watches
+)
+
+// QuotaUsage is how many of each limited resource a tenant holds
+type QuotaUsage struct {
+	Saved_Searches    int
+	Watches           int
+	Webhook_Endpoints int
+}
+
+// Quota is the usage and limit of a resource of a tenant
+type Quota struct {
+	Resource string `json:"resource"`
+	Used     int    `json:"used"`
+	Limit    int    `json:"limit"` // 0 means unlimited
+}
+
+// Exceeded reports whether one more of the resource goes over the limit
+func (q Quota) Exceeded() bool {
+	return q.Limit > 0 && q.Used >= q.Limit
+}
@@ -0,0 +1,43 @@
+package models
+
+import "sort"
+
+// ScorePercentiles is the rolling story score distribution of a tenant on a day: the stories created
+// in the window of days ending on it
+type ScorePercentiles struct {
+	Day     string    `json:"-"` // YYYY-MM-DD, the last day of the window
+	Stories int       `json:"stories"`
+	P50     float64   `json:"p50"`
+	P90     float64   `json:"p90"`
+	P99     float64   `json:"p99"`
+	Cutoffs []float64 `json:"-"` // scores of the 0th to 100th percentiles, ascending
+}
+
+// NewScorePercentiles creates the distribution of a day from its 101 percentile cutoffs
+func NewScorePercentiles(day string, stories int, cutoffs []float64) *ScorePercentiles {
+	p := &ScorePercentiles{Day: day, Stories: stories, Cutoffs: cutoffs}
+	if len(cutoffs) == 101 {
+		p.P50, p.P90, p.P99 = cutoffs[50], cutoffs[90], cutoffs[99]
+	}
+	return p
+}
+
+// Percentile returns the highest percentile whose cutoff the score reaches, so a story is in the
+// top 1% when its percentile is at least 99. Scores under every cutoff get 0.
+func (p *ScorePercentiles) Percentile(score int) int {
+	reached := sort.Search(len(p.Cutoffs), func(i int) bool { return p.Cutoffs[i] > float64(score) })
+	if reached == 0 {
+		return 0
+	}
+	return reached - 1
+}
+
+// LatestScorePercentiles returns the distribution of day, or else of the latest day before it, among
+// distributions sorted oldest first; nil when every distribution is later
+func LatestScorePercentiles(distributions []*ScorePercentiles, day string) *ScorePercentiles {
+	i := sort.Search(len(distributions), func(i int) bool { return distributions[i].Day > day })
+	if i == 0 {
+		return nil
+	}
+	return distributions[i-1]
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SearchQuery is one search request recorded for query analytics
+type SearchQuery struct {
+	ID         int64             `json:"id" db:"id"`
+	Tenant     string            `json:"tenant" db:"tenant"`
+	Query_Key  string            `json:"query_key" db:"query_key"` // QueryKey of the query text
+	Query      string            `json:"query" db:"query"`
+	Filters    map[string]string `json:"filters,omitempty" db:"filters"`
+	Hits       int64             `json:"hits" db:"hits"`
+	Latency_Ms int64             `json:"latency_ms" db:"latency_ms"`
+	Created_At int64             `json:"created_at" db:"created_at"`
+}
+
+// QueryStat aggregates the recorded searches sharing a query key
+type QueryStat struct {
+	Query          string  `json:"query"` // most recent spelling of the query
+	Count          int64   `json:"count"`
+	Zero_Results   int64   `json:"zero_results"`
+	Avg_Hits       float64 `json:"avg_hits"`
+	Avg_Latency_Ms float64 `json:"avg_latency_ms"`
+}
+
+// QueryKey hashes a query text after lowercasing it and collapsing whitespace, so queries
+// differing only in case or spacing are aggregated together
+func QueryKey(query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
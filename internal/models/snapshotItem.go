@@ -0,0 +1,16 @@
+package models
+
+// SnapshotItem is an item as published in a dataset snapshot: the public fields of every kind
+// in one shape, empty where a kind has none, and the author replaced by a pseudonym
+type SnapshotItem struct {
+	ID          int    `json:"id" db:"id"`
+	Kind        string `json:"kind"`
+	Title       string `json:"title" db:"title"`
+	URL         string `json:"url" db:"url"`
+	Text        string `json:"text" db:"text"`
+	Score       int    `json:"score" db:"score"`
+	Author      string `json:"by" db:"author"`
+	Created_At  int64  `json:"time" db:"created_at"`
+	Parent      int    `json:"parent" db:"parent_id"`
+	Descendants int    `json:"descendants"`
+}
@@ -0,0 +1,5 @@
+package models
+
+// SourceHackerNews is the source of every item synced from the HackerNews API.
+// Items decoded from the API leave Source empty, which the repositories store as this value.
+const SourceHackerNews = "hackernews"
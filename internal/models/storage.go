@@ -0,0 +1,49 @@
+package models
+
+// StorageSnapshot is the size of a table, summed over its partitions, on a day (unix seconds of
+// its UTC midnight). Rows is the planner's live row estimate. Table is empty for the database as
+// a whole, whose size is all in TableBytes.
+type StorageSnapshot struct {
+	Table      string `json:"table" db:"table_name"`
+	Day        int64  `json:"day" db:"day"`
+	Rows       int64  `json:"rows" db:"row_count"`
+	TableBytes int64  `json:"table_bytes" db:"table_bytes"`
+	IndexBytes int64  `json:"index_bytes" db:"index_bytes"`
+}
+
+// Bytes is the size of the table and its indexes
+func (s *StorageSnapshot) Bytes() int64 {
+	return s.TableBytes + s.IndexBytes
+}
+
+// StorageReport is the storage taken by the database and its tables, how fast it grows, and when
+// it is projected to cross the disk thresholds
+type StorageReport struct {
+	DatabaseBytes     int64               `json:"database_bytes"`
+	GrowthBytesPerDay float64             `json:"growth_bytes_per_day"`
+	DiskBytes         int64               `json:"disk_bytes"` // 0 when not configured
+	Tables            []*TableUsage       `json:"tables"`
+	Thresholds        []*StorageThreshold `json:"thresholds"`
+}
+
+// TableUsage is the size and daily growth of a table. The growth is measured since the oldest
+// snapshot of the window, MeasuredDays ago; it is 0 until a day of snapshots is recorded. A
+// table pruned after RetentionDays stops growing once it holds that many days of rows.
+type TableUsage struct {
+	Table             string  `json:"table"`
+	Rows              int64   `json:"rows"`
+	TableBytes        int64   `json:"table_bytes"`
+	IndexBytes        int64   `json:"index_bytes"`
+	GrowthRowsPerDay  float64 `json:"growth_rows_per_day"`
+	GrowthBytesPerDay float64 `json:"growth_bytes_per_day"`
+	MeasuredDays      float64 `json:"measured_days"`
+	RetentionDays     float64 `json:"retention_days,omitempty"`
+}
+
+// StorageThreshold is a share of the disk and the days until the database is projected to take
+// it: 0 once taken, nil when not within the projection horizon
+type StorageThreshold struct {
+	Fraction  float64 `json:"fraction"`
+	Bytes     int64   `json:"bytes"`
+	DaysUntil *int    `json:"days_until"`
+}
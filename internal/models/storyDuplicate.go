@@ -0,0 +1,77 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+// StoryDuplicate links a story to the highest-scored story sharing its canonical URL;
+// that story links to itself
+type StoryDuplicate struct {
+	StoryID      int    `json:"story_id" db:"story_id"`
+	CanonicalID  int    `json:"canonical_id" db:"canonical_id"`
+	CanonicalURL string `json:"canonical_url" db:"canonical_url"`
+}
+
+// trackingParams are query parameters that never change the page a URL points to
+var trackingParams = map[string]bool{"fbclid": true, "gclid": true, "ref": true}
+
+// CanonicalURL normalizes a story URL so submissions of the same page compare equal: the scheme,
+// "www.", default ports, fragment, tracking parameters and trailing slashes are dropped, the host
+// is lowercased and the remaining query parameters are sorted. It returns "" for URLs without a host.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := u.Query()
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	canonical := host + strings.TrimRight(u.EscapedPath(), "/")
+	if len(query) > 0 {
+		// Encode sorts the parameters by name
+		canonical += "?" + query.Encode()
+	}
+	return canonical
+}
+
+// FindDuplicates groups stories by canonical URL and links every story of a group with more than
+// one member to its highest-scored story (the lowest ID on ties)
+func FindDuplicates(stories []*Story) []*StoryDuplicate {
+	groups := make(map[string][]*Story)
+	for _, story := range stories {
+		if canonical := CanonicalURL(story.URL); canonical != "" {
+			groups[canonical] = append(groups[canonical], story)
+		}
+	}
+
+	var duplicates []*StoryDuplicate
+	for canonical, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		best := group[0]
+		for _, story := range group[1:] {
+			if story.Score > best.Score || (story.Score == best.Score && story.ID < best.ID) {
+				best = story
+			}
+		}
+		for _, story := range group {
+			duplicates = append(duplicates, &StoryDuplicate{
+				StoryID:      story.ID,
+				CanonicalID:  best.ID,
+				CanonicalURL: canonical,
+			})
+		}
+	}
+	return duplicates
+}
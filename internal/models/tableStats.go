@@ -0,0 +1,29 @@
+package models
+
+// TableStats is the size and upkeep state of a table, summed over its partitions. Bloat is
+// estimated from the share of dead rows, which take space in the table and its indexes until
+// they are vacuumed.
+type TableStats struct {
+	Table       string `json:"table"`
+	Partitioned bool   `json:"partitioned"`
+	LiveRows    int64  `json:"live_rows"`
+	DeadRows    int64  `json:"dead_rows"`
+	// ModifiedSinceAnalyze is the rows written since the planner statistics were gathered; for a
+	// partitioned table, how far its row estimate is off
+	ModifiedSinceAnalyze int64         `json:"modified_since_analyze"`
+	DeadRatio            float64       `json:"dead_ratio"`
+	TableBytes           int64         `json:"table_bytes"`
+	IndexBytes           int64         `json:"index_bytes"`
+	EstimatedBloatBytes  int64         `json:"estimated_bloat_bytes"`
+	LastAnalyzedAt       int64         `json:"last_analyzed_at"` // 0 when never analyzed
+	LastVacuumedAt       int64         `json:"last_vacuumed_at"` // the oldest among the partitions
+	Indexes              []*IndexStats `json:"indexes"`
+}
+
+// IndexStats is the size and use of an index, summed over its partitions
+type IndexStats struct {
+	Name                string `json:"name"`
+	Bytes               int64  `json:"bytes"`
+	Scans               int64  `json:"scans"`
+	EstimatedBloatBytes int64  `json:"estimated_bloat_bytes"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "encoding/json"
+
+// Task statuses
+const (
+	TaskPending = "pending"
+	TaskRunning = "running" // claimed by a worker until its lease runs out
+	TaskDone    = "done"
+	TaskFailed  = "failed" // failed on its last attempt
+)
+
+// Task is a unit of background work queued for the task worker
+type Task struct {
+	ID          string          `json:"id" db:"id"` // UUIDv7
+	Type        string          `json:"type" db:"type"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      string          `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAfter    int64           `json:"run_after" db:"run_after"` // unix seconds; the lease expiry of a running task
+	LastError   string          `json:"last_error,omitempty" db:"last_error"`
+	Created_At  int64           `json:"created_at" db:"created_at"`
+	Updated_At  int64           `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// DefaultTenant owns every row synced before tenants existed and
+// serves API requests that do not present an API key.
+const DefaultTenant = "default"
+
+// Tenant is an isolated collection of items with its own API key and quotas
+type Tenant struct {
+	Name                  string `json:"name" db:"name"`
+	API_Key               string `json:"-" db:"api_key"`
+	Requests_Per_Minute   int    `json:"requests_per_minute" db:"requests_per_minute"`     // 0 means unlimited
+	Retention_Days        int    `json:"retention_days" db:"retention_days"`               // 0 means keep forever
+	Max_Saved_Searches    int    `json:"max_saved_searches" db:"max_saved_searches"`       // 0 takes the configured default, -1 means unlimited
+	Max_Watches           int    `json:"max_watches" db:"max_watches"`                     // 0 takes the configured default, -1 means unlimited
+	Max_Webhook_Endpoints int    `json:"max_webhook_endpoints" db:"max_webhook_endpoints"` // 0 takes the configured default, -1 means unlimited
+	Created_At            int64  `json:"created_at" db:"created_at"`
+
+	// The synced items the tenant collects: those of any of Sources, if set, whose title holds any
+	// of Keywords, if set. With neither, the tenant collects nothing and the items stay in the
+	// default tenant.
+	Sources  []string `json:"sources" db:"sources"`
+	Keywords []string `json:"keywords" db:"keywords"`
+}
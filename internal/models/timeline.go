@@ -0,0 +1,28 @@
+package models
+
+// TimelineItem is a type-tagged summary of a story, ask, job or poll
+// used by the unified "everything new" timeline. The new-author feed lists comments too,
+// with their text and no title.
+type TimelineItem struct {
+	ID         int    `json:"id" db:"id"`
+	Type       string `json:"type" db:"type"`
+	Title      string `json:"title" db:"title"`
+	URL        string `json:"url,omitempty" db:"url"`
+	Score      int    `json:"score" db:"score"`
+	Author     string `json:"by" db:"author"`
+	Created_At int64  `json:"time" db:"created_at"`
+	Source     string `json:"source" db:"source"`
+	Text       string `json:"text,omitempty" db:"text"`
+}
+
+// TimelineCursor marks the position of the last item returned by a timeline page.
+// The zero value starts from the newest item.
+type TimelineCursor struct {
+	Created_At int64
+	ID         int
+}
+
+// IsZero reports whether the cursor points at the start of the timeline
+func (c TimelineCursor) IsZero() bool {
+	return c.Created_At == 0 && c.ID == 0
+}
@@ -0,0 +1,35 @@
+package models
+
+import "encoding/json"
+
+// Tombstone reasons
+const (
+	TombstoneDeleted   = "deleted"   // HackerNews deleted the item
+	TombstoneDead      = "dead"      // HackerNews marked the item dead (flagged or killed)
+	TombstoneRetention = "retention" // the item outlived the retention of its tenant
+	TombstoneGone      = "gone"      // the API serves null for the item, e.g. after HackerNews purged it
+)
+
+// Tombstone is an item removed from the item tables, with its last stored row. Its event is
+// published on the tombstone topic and its search document deleted once it is emitted.
+type Tombstone struct {
+	ID         int             `json:"id" db:"item_id"`
+	Kind       string          `json:"type" db:"kind"`
+	Tenant     string          `json:"tenant" db:"tenant"`
+	Reason     string          `json:"reason" db:"reason"`
+	Item       json.RawMessage `json:"-" db:"item"`
+	Deleted_At int64           `json:"deleted_at" db:"deleted_at"`           // unix milliseconds
+	Emitted_At int64           `json:"emitted_at,omitempty" db:"emitted_at"` // 0 while pending
+}
+
+// TombstoneReason returns why an item served by the API must be removed from the store rather
+// than saved: TombstoneDeleted or TombstoneDead, or empty when it is live
+func (it *HNItem) TombstoneReason() string {
+	switch {
+	case it.Deleted:
+		return TombstoneDeleted
+	case it.Dead:
+		return TombstoneDead
+	}
+	return ""
+}
@@ -1,12 +1,43 @@
 package models
 
-// Update represents a data update with its details.
+import "slices"
+
+// Update is the changes feed of the HackerNews API (/v0/updates.json): the items and profiles
+// that changed recently. Consecutive polls list many of them again.
 type Update struct {
-	IDs      []int    `json:"items" db:"id"`           // Unique identifier for the update
-	Profiles []string `json:"profiles" db:"profiles" ` // Comma-separated list of profiles
+	IDs      []int    `json:"items" db:"id"`           // IDs of the changed items
+	Profiles []string `json:"profiles" db:"profiles" ` // usernames of the changed profiles
 }
 
 // IsValid checks if the update is valid.
 func (u *Update) IsValid() bool {
 	return len(u.IDs) > 0 && len(u.Profiles) > 0
 }
+
+// IsEmpty reports whether the update lists no item and no profile
+func (u *Update) IsEmpty() bool {
+	return len(u.IDs) == 0 && len(u.Profiles) == 0
+}
+
+// ItemIDs returns the changed item IDs without duplicates, in feed order
+func (u *Update) ItemIDs() []int {
+	return unique(u.IDs)
+}
+
+// Usernames returns the changed profiles without duplicates or empty names, in feed order
+func (u *Update) Usernames() []string {
+	return slices.DeleteFunc(unique(u.Profiles), func(name string) bool { return name == "" })
+}
+
+// unique returns values without duplicates, keeping the first occurrences in order
+func unique[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	out := make([]T, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
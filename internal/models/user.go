@@ -1,7 +1,9 @@
 package models
 
+// User represents a Hacker News user; the API serves the username as its ID and has no numeric
+// one, so ID is only the database key
 type User struct {
-	ID         int    `json:"" db:"id"`
+	ID         int    `json:"-" db:"id"`
 	Username   string `json:"id" db:"username"`
 	Karma      int    `json:"karma" db:"karma"`
 	About      string `json:"about" db:"about"`
@@ -12,3 +14,17 @@ type User struct {
 func (u *User) IsValid() bool {
 	return u.Username != "" && u.About != "" && u.Karma >= 0 && u.Created_At > 0
 }
+
+// KarmaCheck is what the update sync saw of a stored user's profile when it last fetched it
+type KarmaCheck struct {
+	Username    string
+	Karma       int
+	About       string
+	Submissions int
+	Checked_At  int64 // unix seconds; 0 when never checked
+}
+
+// Changed reports whether a fetched profile differs from the stored one
+func (c *KarmaCheck) Changed(u *User) bool {
+	return c.Karma != u.Karma || c.About != u.About || c.Submissions != len(u.Submitted)
+}
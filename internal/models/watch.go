@@ -0,0 +1,67 @@
+package models
+
+// Watch asks for a webhook call when a watched item's score, comment count or top stories rank
+// moves by at least the configured delta from the values of the last notification. A zero delta
+// ignores the field.
+type Watch struct {
+	ID             string     `json:"id" db:"id"` // UUIDv7
+	Kind           string     `json:"type" db:"kind"`
+	Item_ID        int        `json:"item_id" db:"item_id"`
+	Webhook_URL    string     `json:"webhook_url" db:"webhook_url"`
+	Score_Delta    int        `json:"score_delta" db:"score_delta"`
+	Comments_Delta int        `json:"comments_delta" db:"comments_delta"`
+	Rank_Delta     int        `json:"rank_delta" db:"rank_delta"`
+	Last           WatchState `json:"last"` // values at creation or at the last notification
+	Created_At     int64      `json:"created_at" db:"created_at"`
+	Notified_At    int64      `json:"notified_at,omitempty" db:"notified_at"`
+}
+
+// WatchState holds the watched values of an item
+type WatchState struct {
+	Score    int `json:"score" db:"last_score"`
+	Comments int `json:"comments" db:"last_comments"`
+	Rank     int `json:"rank" db:"last_rank"` // position in the HN top stories, 0 when not in them
+}
+
+// WatchChange is a watched value that moved by at least its delta
+type WatchChange struct {
+	Field    string `json:"field"` // "score", "comments" or "rank"
+	Previous int    `json:"previous"`
+	Current  int    `json:"current"`
+}
+
+// WatchEvent is the body of a watch webhook call
+type WatchEvent struct {
+	Idempotency_Key string        `json:"idempotency_key"` // also sent as the Idempotency-Key header
+	Watch_ID        string        `json:"watch_id"`
+	Kind            string        `json:"type"`
+	Item_ID         int           `json:"item_id"`
+	Changes         []WatchChange `json:"changes"`
+	Time            int64         `json:"time"`
+}
+
+// Changes returns the watched values of current that moved by at least their delta from the last
+// notified ones. Entering or leaving the top stories always counts as a rank change.
+func (w *Watch) Changes(current WatchState) []WatchChange {
+	var changes []WatchChange
+	if moved(w.Last.Score, current.Score, w.Score_Delta) {
+		changes = append(changes, WatchChange{Field: "score", Previous: w.Last.Score, Current: current.Score})
+	}
+	if moved(w.Last.Comments, current.Comments, w.Comments_Delta) {
+		changes = append(changes, WatchChange{Field: "comments", Previous: w.Last.Comments, Current: current.Comments})
+	}
+	ranked := w.Last.Rank != current.Rank && (w.Last.Rank == 0 || current.Rank == 0)
+	if w.Rank_Delta > 0 && (ranked || moved(w.Last.Rank, current.Rank, w.Rank_Delta)) {
+		changes = append(changes, WatchChange{Field: "rank", Previous: w.Last.Rank, Current: current.Rank})
+	}
+	return changes
+}
+
+// moved reports whether a value changed by at least delta in either direction; a zero delta never moves
+func moved(previous, current, delta int) bool {
+	if delta <= 0 {
+		return false
+	}
+	diff := current - previous
+	return diff >= delta || -diff >= delta
+}
@@ -0,0 +1,26 @@
+package models
+
+import "encoding/json"
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending" // recorded, not attempted yet
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // the last attempt failed
+)
+
+// WebhookDelivery is a webhook notification with the outcome of its attempts. Every attempt,
+// redeliveries included, sends the same body and idempotency key.
+type WebhookDelivery struct {
+	ID              string          `json:"id" db:"id"` // UUIDv7
+	Idempotency_Key string          `json:"idempotency_key" db:"idempotency_key"`
+	Watch_ID        string          `json:"watch_id" db:"watch_id"`
+	URL             string          `json:"url" db:"url"`
+	Payload         json.RawMessage `json:"payload" db:"payload"`
+	Status          string          `json:"status" db:"status"`
+	Attempts        int             `json:"attempts" db:"attempts"`
+	Response_Code   int             `json:"response_code,omitempty" db:"response_code"` // of the last attempt, 0 without a response
+	Last_Error      string          `json:"last_error,omitempty" db:"last_error"`
+	Created_At      int64           `json:"created_at" db:"created_at"`
+	Updated_At      int64           `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,235 @@
+// Package opensearch indexes the synced items into OpenSearch as they are saved: IndexerService
+// sends them to the _bulk API in batches, one index per item kind, and retries the batches (or the
+// documents of a batch) the cluster could not take.
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+)
+
+// kindIndexes maps the indexed kinds to the default suffix of their index
+var kindIndexes = map[string]string{
+	"story":   "stories",
+	"ask":     "asks",
+	"job":     "jobs",
+	"comment": "comments",
+	"poll":    "polls",
+	"user":    "users",
+}
+
+// IndexName returns the index holding the documents of the kind: OPENSEARCH_INDEX_<KIND> (e.g.
+// OPENSEARCH_INDEX_STORY) when set, OPENSEARCH_INDEX_PREFIX + "stories", "asks", ... otherwise
+func IndexName(kind string) (string, error) {
+	suffix, ok := kindIndexes[kind]
+	if !ok {
+		return "", fmt.Errorf("no search index for item kind %q", kind)
+	}
+	return config.GetEnv("OPENSEARCH_INDEX_"+strings.ToUpper(kind), config.GetEnv("OPENSEARCH_INDEX_PREFIX", "")+suffix), nil
+}
+
+// Document is the source of a document and its ID in the index
+type Document struct {
+	ID     string
+	Source interface{}
+}
+
+// IndexerService bulk-indexes saved items into the cluster at OPENSEARCH_URL
+type IndexerService struct {
+	baseURL     string
+	httpClient  *http.Client
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewIndexerService creates the indexer configured by OPENSEARCH_URL, OPENSEARCH_BULK_SIZE
+// (documents per _bulk request), OPENSEARCH_BULK_MAX_ATTEMPTS and OPENSEARCH_BULK_BACKOFF (the
+// first wait between attempts, doubled on each retry). It returns nil unless
+// OPENSEARCH_INDEXER_ENABLED is set, a URL is configured and the opensearch sink is enabled.
+func NewIndexerService() *IndexerService {
+	baseURL := config.GetEnv("OPENSEARCH_URL", "")
+	if baseURL == "" || !config.SinkEnabled(config.SinkOpenSearch) || !config.GetEnvBool("OPENSEARCH_INDEXER_ENABLED", false) {
+		return nil
+	}
+	return &IndexerService{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("OPENSEARCH_TIMEOUT", 10*time.Second),
+		},
+		batchSize:   max(config.GetEnvInt("OPENSEARCH_BULK_SIZE", 500), 1),
+		maxAttempts: max(config.GetEnvInt("OPENSEARCH_BULK_MAX_ATTEMPTS", 3), 1),
+		backoff:     config.GetEnvDuration("OPENSEARCH_BULK_BACKOFF", time.Second),
+	}
+}
+
+// IndexStories indexes saved stories
+func (s *IndexerService) IndexStories(ctx context.Context, stories []*models.Story) error {
+	return s.Index(ctx, "story", documents(stories, func(story *models.Story) int { return story.ID }))
+}
+
+// IndexAsks indexes saved asks
+func (s *IndexerService) IndexAsks(ctx context.Context, asks []*models.Ask) error {
+	return s.Index(ctx, "ask", documents(asks, func(ask *models.Ask) int { return ask.ID }))
+}
+
+// IndexJobs indexes saved jobs
+func (s *IndexerService) IndexJobs(ctx context.Context, jobs []*models.Job) error {
+	return s.Index(ctx, "job", documents(jobs, func(job *models.Job) int { return job.ID }))
+}
+
+// IndexComments indexes saved comments
+func (s *IndexerService) IndexComments(ctx context.Context, comments []*models.Comment) error {
+	return s.Index(ctx, "comment", documents(comments, func(comment *models.Comment) int { return comment.ID }))
+}
+
+// IndexPolls indexes saved polls
+func (s *IndexerService) IndexPolls(ctx context.Context, polls []*models.Poll) error {
+	return s.Index(ctx, "poll", documents(polls, func(poll *models.Poll) int { return poll.ID }))
+}
+
+// IndexUsers indexes saved profiles, keyed by username
+func (s *IndexerService) IndexUsers(ctx context.Context, users []*models.User) error {
+	docs := make([]Document, len(users))
+	for i, user := range users {
+		docs[i] = Document{ID: user.Username, Source: user}
+	}
+	return s.Index(ctx, "user", docs)
+}
+
+// documents returns the items as documents keyed by their ID
+func documents[T any](items []*T, id func(*T) int) []Document {
+	docs := make([]Document, len(items))
+	for i, item := range items {
+		docs[i] = Document{ID: strconv.Itoa(id(item)), Source: item}
+	}
+	return docs
+}
+
+// Index writes the documents into the index of the kind, replacing those with the same IDs, in
+// batches of OPENSEARCH_BULK_SIZE. Every batch is attempted; the error joins the failures of
+// the batches that still failed after their retries.
+func (s *IndexerService) Index(ctx context.Context, kind string, docs []Document) error {
+	index, err := IndexName(kind)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for start := 0; start < len(docs); start += s.batchSize {
+		batch := docs[start:min(start+s.batchSize, len(docs))]
+		if err := s.indexBatch(ctx, index, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// indexBatch sends a batch to the _bulk API until every document is indexed or the attempts run
+// out. A failed request is sent again whole; a request that went through is sent again with only
+// its documents rejected for a transient reason (a full queue or an unavailable shard), and
+// documents rejected for good, e.g. by the mapping, fail the batch without retry.
+func (s *IndexerService) indexBatch(ctx context.Context, index string, batch []Document) error {
+	backoff := s.backoff
+	var rejected []string
+	for attempt := 1; ; attempt++ {
+		retry, failed, err := s.bulk(ctx, index, batch)
+		rejected = append(rejected, failed...)
+		if err == nil {
+			batch = retry
+		}
+		if len(batch) == 0 && err == nil {
+			break
+		}
+		if attempt >= s.maxAttempts {
+			if err == nil {
+				err = fmt.Errorf("%d documents still rejected", len(batch))
+			}
+			return fmt.Errorf("failed to index %d documents into %s after %d attempts: %w", len(batch), index, attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%s rejected %d documents: %s", index, len(rejected), strings.Join(rejected, "; "))
+	}
+	return nil
+}
+
+// bulkResponse is the part of a _bulk response telling the outcome of every document
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends one _bulk request and returns the documents to send again and the descriptions of
+// those rejected for good; err is set when the request itself failed
+func (s *IndexerService) bulk(ctx context.Context, index string, batch []Document) (retry []Document, rejected []string, err error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range batch {
+		action := map[string]interface{}{"index": map[string]string{"_index": index, "_id": doc.ID}}
+		if err := enc.Encode(action); err != nil {
+			return nil, nil, err
+		}
+		if err := enc.Encode(doc.Source); err != nil {
+			return nil, nil, fmt.Errorf("failed to encode document %s: %w", doc.ID, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/_bulk", &body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bulk request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, nil, fmt.Errorf("bulk request returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil, nil
+	}
+	// The items answer the actions in order
+	for i, item := range result.Items {
+		if i >= len(batch) {
+			break
+		}
+		outcome := item["index"]
+		switch {
+		case outcome.Status < 300:
+		case outcome.Status == http.StatusTooManyRequests || outcome.Status >= 500:
+			retry = append(retry, batch[i])
+		default:
+			rejected = append(rejected, fmt.Sprintf("%s: status %d: %s", batch[i].ID, outcome.Status, outcome.Error))
+		}
+	}
+	return retry, rejected, nil
+}
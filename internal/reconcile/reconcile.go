@@ -0,0 +1,206 @@
+package reconcile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/search"
+	"internship-project/internal/transport"
+)
+
+// Options selects what a reconciliation run compares
+type Options struct {
+	Kinds []string
+	Start int64 // created_at lower bound (unix seconds, inclusive); 0 is open
+	End   int64 // created_at upper bound (unix seconds, inclusive); 0 is open
+
+	// SampleSize is how many random items per kind have their checksums compared; ignored by Full
+	SampleSize int
+	// Full compares every item in the range instead of a sample
+	Full bool
+	// BatchSize is how many documents are fetched per request
+	BatchSize int
+	// Repair republishes missing and stale items so the indexer writes them again
+	Repair bool
+}
+
+// KindReport is the outcome of reconciling one item kind
+type KindReport struct {
+	Kind          string `json:"kind"`
+	DatabaseCount int64  `json:"database_count"`
+	IndexCount    int64  `json:"index_count"`
+	Checked       int    `json:"checked"`
+	Missing       []int  `json:"missing"` // IDs stored in Postgres without a search document
+	Stale         []int  `json:"stale"`   // IDs whose search document differs from the stored row
+	Repaired      int    `json:"repaired"`
+}
+
+// Consistent reports whether the counts match and no checked item was missing or stale
+func (r KindReport) Consistent() bool {
+	return r.DatabaseCount == r.IndexCount && len(r.Missing) == 0 && len(r.Stale) == 0
+}
+
+// String summarizes the report on one line
+func (r KindReport) String() string {
+	return fmt.Sprintf("%s: %d stored, %d indexed, %d checked, %d missing, %d stale, %d repaired",
+		r.Kind, r.DatabaseCount, r.IndexCount, r.Checked, len(r.Missing), len(r.Stale), r.Repaired)
+}
+
+// Reconciler compares the items stored in Postgres with the documents in the search index
+type Reconciler struct {
+	store     repository.DataQualityRepository
+	index     *search.Client
+	publisher transport.Publisher
+}
+
+// NewReconciler creates a reconciler; publisher is only needed for repairs and may be nil
+func NewReconciler(store repository.DataQualityRepository, index *search.Client, publisher transport.Publisher) *Reconciler {
+	return &Reconciler{
+		store:     store,
+		index:     index,
+		publisher: publisher,
+	}
+}
+
+// Run reconciles every kind of the options and returns one report per kind
+func (r *Reconciler) Run(ctx context.Context, opts Options) ([]KindReport, error) {
+	if opts.Repair && r.publisher == nil {
+		return nil, fmt.Errorf("repair requires an event publisher")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	reports := make([]KindReport, 0, len(opts.Kinds))
+	for _, kind := range opts.Kinds {
+		report, err := r.reconcileKind(ctx, kind, opts)
+		if err != nil {
+			return reports, fmt.Errorf("failed to reconcile %s: %w", kind, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// reconcileKind compares the counts of a kind, then the checksums of a sample or of every item
+func (r *Reconciler) reconcileKind(ctx context.Context, kind string, opts Options) (KindReport, error) {
+	report := KindReport{Kind: kind}
+
+	var err error
+	if report.DatabaseCount, err = r.store.CountItemsInRange(ctx, kind, opts.Start, opts.End); err != nil {
+		return report, err
+	}
+	if report.IndexCount, err = r.index.CountRange(ctx, kind, opts.Start, opts.End); err != nil {
+		return report, err
+	}
+
+	if opts.Full {
+		afterID := 0
+		for {
+			ids, err := r.store.GetItemIDs(ctx, kind, opts.Start, opts.End, afterID, opts.BatchSize)
+			if err != nil {
+				return report, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			if err := r.compare(ctx, kind, ids, &report); err != nil {
+				return report, err
+			}
+			afterID = ids[len(ids)-1]
+		}
+	} else if opts.SampleSize > 0 {
+		ids, err := r.store.SampleItemIDs(ctx, kind, opts.Start, opts.End, opts.SampleSize)
+		if err != nil {
+			return report, err
+		}
+		for start := 0; start < len(ids); start += opts.BatchSize {
+			end := min(start+opts.BatchSize, len(ids))
+			if err := r.compare(ctx, kind, ids[start:end], &report); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if opts.Repair {
+		if err := r.repair(ctx, kind, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// compare checks a batch of items against their search documents
+func (r *Reconciler) compare(ctx context.Context, kind string, ids []int, report *KindReport) error {
+	stored, err := r.store.GetItemDocuments(ctx, kind, ids)
+	if err != nil {
+		return err
+	}
+	indexed, err := r.index.MultiGet(ctx, kind, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		row, ok := stored[id]
+		if !ok {
+			// deleted since the IDs were read
+			continue
+		}
+		report.Checked++
+
+		doc, ok := indexed[id]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, id)
+		case Checksum(row, row) != Checksum(doc, row):
+			report.Stale = append(report.Stale, id)
+		}
+	}
+	return nil
+}
+
+// repair republishes the missing and stale items of the report on the topic of their kind
+func (r *Reconciler) repair(ctx context.Context, kind string, report *KindReport) error {
+	topic, ok := transport.ItemTopics[kind]
+	if !ok {
+		return fmt.Errorf("no topic for item kind %q", kind)
+	}
+
+	ids := append(append([]int(nil), report.Missing...), report.Stale...)
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([][]byte, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.AppendInt(nil, int64(id), 10)
+	}
+	if err := r.publisher.Publish(ctx, topic, values...); err != nil {
+		return err
+	}
+	report.Repaired = len(ids)
+	return nil
+}
+
+// Checksum hashes the fields of doc named by the keys of fields, so a search document carrying
+// extra fields still matches the stored row it was built from. Absent fields hash as null.
+func Checksum(doc map[string]interface{}, fields map[string]interface{}) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		value, _ := json.Marshal(doc[name])
+		fmt.Fprintf(h, "%s=%s\n", name, value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
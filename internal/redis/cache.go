@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Cache is the part of Redis the sync pipeline uses: the synced ID lists, the JSON values of
+// the hot items and checkpoints, and the invalidation of cached items
+type Cache interface {
+	CacheID(ctx context.Context, key string, ids []int) error
+	IsItemInCache(ctx context.Context, key string, id int) (bool, error)
+	CacheUserIDs(ctx context.Context, key string, ids []string) error
+	CacheJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	CacheJSONBatch(ctx context.Context, values map[string]interface{}, ttl time.Duration) error
+	GetCachedJSON(ctx context.Context, key string, dest interface{}) (bool, error)
+	PublishInvalidation(ctx context.Context, keys ...string) error
+}
+
+// serverCache is the Cache of the Redis server of REDIS_ADDR
+type serverCache struct{}
+
+// NewCache returns the Cache of the Redis server of REDIS_ADDR
+func NewCache() Cache {
+	return serverCache{}
+}
+
+func (serverCache) CacheID(ctx context.Context, key string, ids []int) error {
+	return CacheID(ctx, key, ids)
+}
+
+func (serverCache) IsItemInCache(ctx context.Context, key string, id int) (bool, error) {
+	return IsItemInCache(ctx, key, id)
+}
+
+func (serverCache) CacheUserIDs(ctx context.Context, key string, ids []string) error {
+	return CacheUserIDs(ctx, key, ids)
+}
+
+func (serverCache) CacheJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return CacheJSON(ctx, key, value, ttl)
+}
+
+func (serverCache) CacheJSONBatch(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	return CacheJSONBatch(ctx, values, ttl)
+}
+
+func (serverCache) GetCachedJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	return GetCachedJSON(ctx, key, dest)
+}
+
+func (serverCache) PublishInvalidation(ctx context.Context, keys ...string) error {
+	return PublishInvalidation(ctx, keys...)
+}
+
+// MemoryCache is a Cache held in the memory of the process, for running the pipeline without a
+// Redis server. Values are kept as JSON, as Redis would; expiry is ignored.
+type MemoryCache struct {
+	mu          sync.Mutex
+	values      map[string][]byte
+	invalidated []string
+}
+
+// NewMemoryCache creates an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{values: make(map[string][]byte)}
+}
+
+// CacheID stores ids as the JSON list at key
+func (c *MemoryCache) CacheID(ctx context.Context, key string, ids []int) error {
+	return c.CacheJSON(ctx, key, ids, 0)
+}
+
+// IsItemInCache reports whether the JSON list at key holds id
+func (c *MemoryCache) IsItemInCache(ctx context.Context, key string, id int) (bool, error) {
+	var ids []int
+	if _, err := c.GetCachedJSON(ctx, key, &ids); err != nil {
+		return false, err
+	}
+	return slices.Contains(ids, id), nil
+}
+
+// CacheUserIDs stores ids as the JSON list at key
+func (c *MemoryCache) CacheUserIDs(ctx context.Context, key string, ids []string) error {
+	return c.CacheJSON(ctx, key, ids, 0)
+}
+
+// CacheJSON stores value as JSON at key
+func (c *MemoryCache) CacheJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.CacheJSONBatch(ctx, map[string]interface{}{key: value}, ttl)
+}
+
+// CacheJSONBatch stores every value as JSON at its key
+func (c *MemoryCache) CacheJSONBatch(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value for %s: %w", key, err)
+		}
+		encoded[key] = valueJSON
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, valueJSON := range encoded {
+		c.values[key] = valueJSON
+	}
+	return nil
+}
+
+// GetCachedJSON decodes the JSON value at key into dest; false when the key does not exist
+func (c *MemoryCache) GetCachedJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	c.mu.Lock()
+	valueJSON, ok := c.values[key]
+	c.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(valueJSON, dest); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached value: %w", err)
+	}
+	return true, nil
+}
+
+// PublishInvalidation deletes the values at keys and records the keys
+func (c *MemoryCache) PublishInvalidation(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	c.invalidated = append(c.invalidated, keys...)
+	return nil
+}
+
+// Invalidated returns the keys invalidated so far, in order
+func (c *MemoryCache) Invalidated() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.invalidated)
+}
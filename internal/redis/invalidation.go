@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"internship-project/internal/config"
+)
+
+// invalidationChannel is the pub/sub channel carrying the cache keys of upserted items
+func invalidationChannel() string {
+	return config.GetEnv("CACHE_INVALIDATION_CHANNEL", "cache:invalidate")
+}
+
+// PublishInvalidation announces that the values cached at keys are stale.
+// The hot item cache entries in Redis itself are deleted as well.
+func PublishInvalidation(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	rdb := NewClient()
+	defer rdb.Close()
+
+	pipe := rdb.Pipeline()
+	pipe.Del(ctx, keys...)
+	for _, key := range keys {
+		pipe.Publish(ctx, invalidationChannel(), key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// SubscribeInvalidations calls invalidate with every key published by PublishInvalidation
+// until ctx is cancelled
+func SubscribeInvalidations(ctx context.Context, invalidate func(key string)) {
+	rdb := NewClient()
+	defer rdb.Close()
+
+	sub := rdb.Subscribe(ctx, invalidationChannel())
+	defer sub.Close()
+
+	log.Printf("Listening for cache invalidations on %s", invalidationChannel())
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			invalidate(msg.Payload)
+		}
+	}
+}
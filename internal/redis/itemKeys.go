@@ -0,0 +1,29 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ItemKey is the cache key holding the full JSON of one item, e.g. "item:story:42"
+func ItemKey(kind string, id int) string {
+	return fmt.Sprintf("item:%s:%d", kind, id)
+}
+
+// ParseItemKey returns the kind and ID of an ItemKey, or false for other keys
+func ParseItemKey(key string) (string, int, bool) {
+	rest, ok := strings.CutPrefix(key, "item:")
+	if !ok {
+		return "", 0, false
+	}
+	kind, idText, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		return "", 0, false
+	}
+	return kind, id, true
+}
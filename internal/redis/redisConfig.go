@@ -2,6 +2,8 @@ package redis
 
 import (
 	"internship-project/internal/config"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // RedisConfig holds the configuration for Redis
@@ -19,3 +21,13 @@ func GetRedisConfig() RedisConfig {
 		DB:       config.GetEnvInt("REDIS_DB", 0),
 	}
 }
+
+// NewClient creates a Redis client from the environment configuration; the caller closes it
+func NewClient() *redis.Client {
+	cfg := GetRedisConfig()
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
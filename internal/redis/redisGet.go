@@ -65,3 +65,23 @@ func IsUserIDInCache(ctx context.Context, key string, targetID string) (bool, er
 
 	return false, nil // ID not found in the list
 }
+
+// GetCachedJSON decodes the JSON value stored at key into dest.
+// It returns false when the key does not exist.
+func GetCachedJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	rdb := NewClient()
+	defer rdb.Close()
+
+	val, err := rdb.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil // Key does not exist
+		}
+		return false, fmt.Errorf("failed to get value from Redis: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(val), dest); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cached value: %w", err)
+	}
+	return true, nil
+}
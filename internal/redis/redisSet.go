@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -50,3 +51,57 @@ func CacheUserIDs(ctx context.Context, key string, ids []string) error {
 	log.Printf("Published %d user IDs to Redis", len(ids))
 	return nil
 }
+
+// CacheJSON stores value as JSON at key, expiring after ttl (0 means no expiry)
+func CacheJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	rdb := NewClient()
+	defer rdb.Close()
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	if err := rdb.Set(ctx, key, string(valueJSON), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set value in Redis: %w", err)
+	}
+	return nil
+}
+
+// IncrementCounter increments the counter at key and returns its new value.
+// The key expires ttl after its first increment, which makes it a fixed-window counter.
+func IncrementCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	rdb := NewClient()
+	defer rdb.Close()
+
+	count, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment counter in Redis: %w", err)
+	}
+	if count == 1 {
+		if err := rdb.Expire(ctx, key, ttl).Err(); err != nil {
+			return count, fmt.Errorf("failed to set counter expiry in Redis: %w", err)
+		}
+	}
+	return count, nil
+}
+
+// CacheJSONBatch stores every value as JSON at its key in one round trip, expiring after ttl
+func CacheJSONBatch(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	rdb := NewClient()
+	defer rdb.Close()
+
+	pipe := rdb.Pipeline()
+	for key, value := range values {
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value for %s: %w", key, err)
+		}
+		pipe.Set(ctx, key, string(valueJSON), ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set values in Redis: %w", err)
+	}
+	return nil
+}
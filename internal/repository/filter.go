@@ -0,0 +1,43 @@
+package repository
+
+import "internship-project/internal/models"
+
+// ItemFilter combines the optional predicates supported by the item list queries.
+// Zero values are ignored, so an empty filter matches every row.
+type ItemFilter struct {
+	Tenant   string // scopes the query to one tenant's rows; empty means all tenants
+	Author   string
+	MinScore *int
+	MaxScore *int
+	Start    int64 // created_at lower bound (unix seconds, inclusive)
+	End      int64 // created_at upper bound (unix seconds, inclusive)
+	Type     string
+	Domain   string // host of the item URL, without scheme or "www."
+	Query    string // case-insensitive match against title and text
+	Source   string // feed the item came from, e.g. "hackernews"
+	IDs      []int  // restricts the query to the items with these IDs; nil matches every ID
+
+	// MinPercentile keeps the stories whose score percentile is at least it; other tables ignore it
+	MinPercentile *int
+
+	// MaxSpamScore excludes items scored at or above it; nil includes flagged items
+	MaxSpamScore *float64
+
+	// ExcludeDeadLinks drops stories whose URL was found dead; tables without link checks ignore it
+	ExcludeDeadLinks bool
+
+	// Extra keeps the items whose extra attributes include all of these, with equal values;
+	// ExtraKey keeps the items with that attribute, whatever its value
+	Extra    models.Attributes
+	ExtraKey string
+
+	Limit  int
+	Offset int
+}
+
+// IsEmpty reports whether the filter has no predicates set
+func (f ItemFilter) IsEmpty() bool {
+	return f.Author == "" && f.MinScore == nil && f.MaxScore == nil && f.MinPercentile == nil &&
+		f.Start == 0 && f.End == 0 && f.Type == "" && f.Domain == "" && f.Query == "" && f.Source == "" &&
+		f.IDs == nil && len(f.Extra) == 0 && f.ExtraKey == ""
+}
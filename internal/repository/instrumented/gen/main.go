@@ -0,0 +1,237 @@
+// Command gen writes the metric decorators of the repository interfaces: for each interface named
+// *Repository it emits a struct forwarding every method to the wrapped repository through observe.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// repositoryPackage is the import path of the package declaring the interfaces
+const repositoryPackage = "internship-project/internal/repository"
+
+// method is an interface method with its embedded interface already resolved
+type method struct {
+	name string
+	typ  *ast.FuncType
+}
+
+func main() {
+	in := flag.String("in", "../repoInterfaces.go", "file declaring the repository interfaces")
+	out := flag.String("out", "repositories_gen.go", "file to write the decorators to")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *in, nil, parser.SkipObjectResolution)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", *in, err)
+	}
+	src, err := generate(file)
+	if err != nil {
+		log.Fatalf("Failed to generate the decorators: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+}
+
+// generate returns the formatted source of the decorators of the interfaces declared in file
+func generate(file *ast.File) ([]byte, error) {
+	interfaces := make(map[string]*ast.InterfaceType)
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				interfaces[ts.Name.Name] = it
+				if strings.HasSuffix(ts.Name.Name, "Repository") {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+
+	imports := map[string]string{"repository": repositoryPackage}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	used := map[string]bool{"context": true, "time": true, "repository": true}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		methods, err := methodsOf(interfaces, name)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&body, "\n// %s records the calls of a repository.%s\ntype %[1]s struct {\n\tnext repository.%[1]s\n}\n", name, name)
+		fmt.Fprintf(&body, "\n// New%s wraps next, or returns it as is when the metrics are disabled\n", name)
+		fmt.Fprintf(&body, "func New%s(next repository.%[1]s) repository.%[1]s {\n\tif !Enabled() {\n\t\treturn next\n\t}\n\treturn &%[1]s{next: next}\n}\n", name)
+		for _, m := range methods {
+			writeMethod(&body, name, m, used)
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by go run ./gen; DO NOT EDIT.\n\npackage instrumented\n\nimport (\n")
+	module := repositoryPackage[:strings.Index(repositoryPackage, "/")+1]
+	var std, local []string
+	for name := range used {
+		path, ok := imports[name]
+		if !ok {
+			path = name
+		}
+		if path[strings.LastIndex(path, "/")+1:] != name {
+			path = name + " " + strconv.Quote(path)
+		} else {
+			path = strconv.Quote(path)
+		}
+		if strings.Contains(path, `"`+module) {
+			local = append(local, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(local)
+	fmt.Fprintf(&src, "\t%s\n\n\t%s\n)\n", strings.Join(std, "\n\t"), strings.Join(local, "\n\t"))
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// methodsOf lists the methods of an interface in declaration order, expanding the embedded ones
+func methodsOf(interfaces map[string]*ast.InterfaceType, name string) ([]method, error) {
+	it, ok := interfaces[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown interface %s", name)
+	}
+	var methods []method
+	for _, field := range it.Methods.List {
+		switch typ := field.Type.(type) {
+		case *ast.FuncType:
+			methods = append(methods, method{name: field.Names[0].Name, typ: typ})
+		case *ast.Ident:
+			embedded, err := methodsOf(interfaces, typ.Name)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, embedded...)
+		default:
+			return nil, fmt.Errorf("unsupported embedded type in %s", name)
+		}
+	}
+	return methods, nil
+}
+
+// writeMethod writes the decorator method forwarding m to the wrapped repository
+func writeMethod(w *bytes.Buffer, repo string, m method, used map[string]bool) {
+	ctxArg := "context.Background()"
+	var params, args []string
+	for i, field := range m.typ.Params.List {
+		typ := typeString(field.Type, used)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+		}
+		for _, n := range names {
+			params = append(params, n.Name+" "+typ)
+			arg := n.Name
+			if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+				arg += "..."
+			}
+			args = append(args, arg)
+			if typ == "context.Context" && ctxArg == "context.Background()" {
+				ctxArg = n.Name
+			}
+		}
+	}
+
+	var results []string
+	returnsError := false
+	if m.typ.Results != nil {
+		for i, field := range m.typ.Results.List {
+			typ := typeString(field.Type, used)
+			for range max(len(field.Names), 1) {
+				results = append(results, typ)
+			}
+			returnsError = typ == "error" && i == len(m.typ.Results.List)-1
+		}
+	}
+
+	signature := strings.Join(results, ", ")
+	errArg := "nil"
+	if returnsError {
+		for i := range results {
+			results[i] = "_ " + results[i]
+		}
+		results[len(results)-1] = "err error"
+		signature = strings.Join(results, ", ")
+		errArg = "&err"
+	}
+	if len(results) > 1 || returnsError {
+		signature = "(" + signature + ")"
+	}
+
+	call := fmt.Sprintf("r.next.%s(%s)", m.name, strings.Join(args, ", "))
+	if len(results) > 0 {
+		call = "return " + call
+	}
+	fmt.Fprintf(w, "\nfunc (r *%s) %s(%s) %s {\n", repo, m.name, strings.Join(params, ", "), signature)
+	fmt.Fprintf(w, "\tdefer observe(%s, %q, time.Now(), %s)\n\t%s\n}\n", ctxArg, repo+"."+m.name, errArg, call)
+}
+
+// typeString prints a type of the repository package from outside of it, qualifying its own
+// identifiers and recording the packages it uses
+func typeString(expr ast.Expr, used map[string]bool) string {
+	expr = qualify(expr)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				used[pkg.Name] = true
+			}
+			return false
+		}
+		return true
+	})
+	return types.ExprString(expr)
+}
+
+// qualify prefixes the identifiers of types declared in the repository package with its name
+func qualify(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(e.Name) == nil {
+			return &ast.SelectorExpr{X: ast.NewIdent("repository"), Sel: e}
+		}
+	case *ast.StarExpr:
+		e.X = qualify(e.X)
+	case *ast.ArrayType:
+		e.Elt = qualify(e.Elt)
+	case *ast.MapType:
+		e.Key, e.Value = qualify(e.Key), qualify(e.Value)
+	case *ast.Ellipsis:
+		e.Elt = qualify(e.Elt)
+	case *ast.ChanType:
+		e.Value = qualify(e.Value)
+	}
+	return expr
+}
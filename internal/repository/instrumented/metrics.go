@@ -0,0 +1,101 @@
+// Package instrumented decorates the repositories so every method call is counted, timed and
+// checked for errors without touching the business code. The totals of each method are published
+// as the repository_calls expvar and calls slower than REPOSITORY_SLOW_CALL_THRESHOLD are logged.
+//
+// The decorators in repositories_gen.go are generated from the interfaces of the repository
+// package; run go generate after changing them.
+package instrumented
+
+//go:generate go run ./gen -in ../repoInterfaces.go -out repositories_gen.go
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/tracing"
+)
+
+var (
+	// repositoryCalls publishes the stats of each method as "Repository.Method"
+	repositoryCalls = expvar.NewMap("repository_calls")
+
+	// methods holds the *methodStats of each method
+	methods sync.Map
+)
+
+// Enabled reports whether the repositories are wrapped in metric decorators
+func Enabled() bool {
+	return config.GetEnvBool("REPOSITORY_METRICS_ENABLED", true)
+}
+
+// methodStats accumulates the calls of a repository method
+type methodStats struct {
+	calls      atomic.Int64
+	errors     atomic.Int64
+	totalNanos atomic.Int64
+	maxNanos   atomic.Int64
+}
+
+// String implements expvar.Var
+func (s *methodStats) String() string {
+	calls, errs := s.calls.Load(), s.errors.Load()
+	total := time.Duration(s.totalNanos.Load())
+	stats := map[string]interface{}{
+		"calls":    calls,
+		"errors":   errs,
+		"total_ms": total.Milliseconds(),
+		"max_ms":   time.Duration(s.maxNanos.Load()).Milliseconds(),
+	}
+	if calls > 0 {
+		stats["avg_ms"] = float64(total.Microseconds()) / float64(calls) / 1000
+		stats["error_rate"] = float64(errs) / float64(calls)
+	}
+	b, _ := json.Marshal(stats)
+	return string(b)
+}
+
+func (s *methodStats) record(d time.Duration, failed bool) {
+	s.calls.Add(1)
+	if failed {
+		s.errors.Add(1)
+	}
+	s.totalNanos.Add(int64(d))
+	for {
+		longest := s.maxNanos.Load()
+		if int64(d) <= longest || s.maxNanos.CompareAndSwap(longest, int64(d)) {
+			return
+		}
+	}
+}
+
+// statsFor returns the stats of a method, publishing them on its first call
+func statsFor(method string) *methodStats {
+	if s, ok := methods.Load(method); ok {
+		return s.(*methodStats)
+	}
+	s, loaded := methods.LoadOrStore(method, &methodStats{})
+	if !loaded {
+		repositoryCalls.Set(method, s.(*methodStats))
+	}
+	return s.(*methodStats)
+}
+
+// observe records a call of method started at start and returning *errp (nil for methods
+// without an error). sql.ErrNoRows is how the repositories report a missing row, so it is
+// not counted as an error.
+func observe(ctx context.Context, method string, start time.Time, errp *error) {
+	d := time.Since(start)
+	failed := errp != nil && *errp != nil && !errors.Is(*errp, sql.ErrNoRows)
+	statsFor(method).record(d, failed)
+
+	if threshold := config.GetEnvDuration("REPOSITORY_SLOW_CALL_THRESHOLD", 500*time.Millisecond); threshold > 0 && d >= threshold {
+		tracing.Logf(ctx, "Slow repository call %s took %v", method, d)
+	}
+}
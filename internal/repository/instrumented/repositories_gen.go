@@ -0,0 +1,1725 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+)
+
+// UserRepository records the calls of a repository.UserRepository
+type UserRepository struct {
+	next repository.UserRepository
+}
+
+// NewUserRepository wraps next, or returns it as is when the metrics are disabled
+func NewUserRepository(next repository.UserRepository) repository.UserRepository {
+	if !Enabled() {
+		return next
+	}
+	return &UserRepository{next: next}
+}
+
+func (r *UserRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "UserRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *UserRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "UserRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User) (err error) {
+	defer observe(ctx, "UserRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, user)
+}
+
+func (r *UserRepository) GetByIDString(ctx context.Context, id string) (_ *models.User, err error) {
+	defer observe(ctx, "UserRepository.GetByIDString", time.Now(), &err)
+	return r.next.GetByIDString(ctx, id)
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) (err error) {
+	defer observe(ctx, "UserRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, user)
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) (err error) {
+	defer observe(ctx, "UserRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *UserRepository) GetAll(ctx context.Context) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *UserRepository) GetRecent(ctx context.Context, limit int) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *UserRepository) GetByMinKarma(ctx context.Context, minKarma int) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetByMinKarma", time.Now(), &err)
+	return r.next.GetByMinKarma(ctx, minKarma)
+}
+
+func (r *UserRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *UserRepository) GetTopByKarma(ctx context.Context, limit int) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetTopByKarma", time.Now(), &err)
+	return r.next.GetTopByKarma(ctx, limit)
+}
+
+func (r *UserRepository) GetByKarmaRange(ctx context.Context, minKarma int, maxKarma int) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetByKarmaRange", time.Now(), &err)
+	return r.next.GetByKarmaRange(ctx, minKarma, maxKarma)
+}
+
+func (r *UserRepository) GetUsersWithSubmissions(ctx context.Context, minSubmissions int) (_ []*models.User, err error) {
+	defer observe(ctx, "UserRepository.GetUsersWithSubmissions", time.Now(), &err)
+	return r.next.GetUsersWithSubmissions(ctx, minSubmissions)
+}
+
+func (r *UserRepository) UpdateKarma(ctx context.Context, id string, karma int) (err error) {
+	defer observe(ctx, "UserRepository.UpdateKarma", time.Now(), &err)
+	return r.next.UpdateKarma(ctx, id, karma)
+}
+
+func (r *UserRepository) UpdateAbout(ctx context.Context, id string, about string) (err error) {
+	defer observe(ctx, "UserRepository.UpdateAbout", time.Now(), &err)
+	return r.next.UpdateAbout(ctx, id, about)
+}
+
+func (r *UserRepository) AddSubmission(ctx context.Context, userID string, itemID int) (err error) {
+	defer observe(ctx, "UserRepository.AddSubmission", time.Now(), &err)
+	return r.next.AddSubmission(ctx, userID, itemID)
+}
+
+func (r *UserRepository) RemoveSubmission(ctx context.Context, userID string, itemID int) (err error) {
+	defer observe(ctx, "UserRepository.RemoveSubmission", time.Now(), &err)
+	return r.next.RemoveSubmission(ctx, userID, itemID)
+}
+
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User) (err error) {
+	defer observe(ctx, "UserRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, users)
+}
+
+func (r *UserRepository) CreateBatchWithExistingIDs(ctx context.Context, users []*models.User) (err error) {
+	defer observe(ctx, "UserRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, users)
+}
+
+func (r *UserRepository) UpsertBatch(ctx context.Context, users []*models.User) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "UserRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, users)
+}
+
+func (r *UserRepository) UpdateKarmaBatch(ctx context.Context, karmaUpdates map[int]int) (err error) {
+	defer observe(ctx, "UserRepository.UpdateKarmaBatch", time.Now(), &err)
+	return r.next.UpdateKarmaBatch(ctx, karmaUpdates)
+}
+
+func (r *UserRepository) GetKarmaChecks(ctx context.Context, usernames []string) (_ map[string]*models.KarmaCheck, err error) {
+	defer observe(ctx, "UserRepository.GetKarmaChecks", time.Now(), &err)
+	return r.next.GetKarmaChecks(ctx, usernames)
+}
+
+func (r *UserRepository) MarkKarmaChecked(ctx context.Context, usernames []string, checkedAt int64) (err error) {
+	defer observe(ctx, "UserRepository.MarkKarmaChecked", time.Now(), &err)
+	return r.next.MarkKarmaChecked(ctx, usernames, checkedAt)
+}
+
+func (r *UserRepository) GetSubmittedIDsByID(ctx context.Context, id string) (_ []int, err error) {
+	defer observe(ctx, "UserRepository.GetSubmittedIDsByID", time.Now(), &err)
+	return r.next.GetSubmittedIDsByID(ctx, id)
+}
+
+func (r *UserRepository) GetSubmissionCount(ctx context.Context, id string) (_ int, err error) {
+	defer observe(ctx, "UserRepository.GetSubmissionCount", time.Now(), &err)
+	return r.next.GetSubmissionCount(ctx, id)
+}
+
+func (r *UserRepository) UserExists(ctx context.Context, id string) (_ bool, err error) {
+	defer observe(ctx, "UserRepository.UserExists", time.Now(), &err)
+	return r.next.UserExists(ctx, id)
+}
+
+func (r *UserRepository) GetUserIDByUsername(ctx context.Context, username string) (_ int, err error) {
+	defer observe(ctx, "UserRepository.GetUserIDByUsername", time.Now(), &err)
+	return r.next.GetUserIDByUsername(ctx, username)
+}
+
+// StoryRepository records the calls of a repository.StoryRepository
+type StoryRepository struct {
+	next repository.StoryRepository
+}
+
+// NewStoryRepository wraps next, or returns it as is when the metrics are disabled
+func NewStoryRepository(next repository.StoryRepository) repository.StoryRepository {
+	if !Enabled() {
+		return next
+	}
+	return &StoryRepository{next: next}
+}
+
+func (r *StoryRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "StoryRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *StoryRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "StoryRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *StoryRepository) Create(ctx context.Context, story *models.Story) (err error) {
+	defer observe(ctx, "StoryRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, story)
+}
+
+func (r *StoryRepository) GetByID(ctx context.Context, id int) (_ *models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *StoryRepository) Update(ctx context.Context, story *models.Story) (err error) {
+	defer observe(ctx, "StoryRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, story)
+}
+
+func (r *StoryRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "StoryRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *StoryRepository) GetAll(ctx context.Context) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *StoryRepository) GetRecent(ctx context.Context, limit int) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *StoryRepository) GetByMinScore(ctx context.Context, minScore int) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByMinScore", time.Now(), &err)
+	return r.next.GetByMinScore(ctx, minScore)
+}
+
+func (r *StoryRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *StoryRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *StoryRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByFilter", time.Now(), &err)
+	return r.next.GetByFilter(ctx, filter)
+}
+
+func (r *StoryRepository) GetByIDs(ctx context.Context, tenant string, ids []int) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetByIDs", time.Now(), &err)
+	return r.next.GetByIDs(ctx, tenant, ids)
+}
+
+func (r *StoryRepository) GetRelated(ctx context.Context, tenant string, id int, limit int) (_ []*models.Story, err error) {
+	defer observe(ctx, "StoryRepository.GetRelated", time.Now(), &err)
+	return r.next.GetRelated(ctx, tenant, id, limit)
+}
+
+func (r *StoryRepository) Count(ctx context.Context, filter repository.ItemFilter) (_ int, err error) {
+	defer observe(ctx, "StoryRepository.Count", time.Now(), &err)
+	return r.next.Count(ctx, filter)
+}
+
+func (r *StoryRepository) UpdateScore(ctx context.Context, id int, score int) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateScore", time.Now(), &err)
+	return r.next.UpdateScore(ctx, id, score)
+}
+
+func (r *StoryRepository) UpdateCommentsCount(ctx context.Context, id int, count int) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateCommentsCount", time.Now(), &err)
+	return r.next.UpdateCommentsCount(ctx, id, count)
+}
+
+func (r *StoryRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateSpamScores", time.Now(), &err)
+	return r.next.UpdateSpamScores(ctx, scores)
+}
+
+func (r *StoryRepository) UpdateScorePercentiles(ctx context.Context, percentiles map[int]int) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateScorePercentiles", time.Now(), &err)
+	return r.next.UpdateScorePercentiles(ctx, percentiles)
+}
+
+func (r *StoryRepository) GetLinksToCheck(ctx context.Context, checkedBefore int64, limit int) (_ []*models.LinkCheck, err error) {
+	defer observe(ctx, "StoryRepository.GetLinksToCheck", time.Now(), &err)
+	return r.next.GetLinksToCheck(ctx, checkedBefore, limit)
+}
+
+func (r *StoryRepository) UpdateLinkChecks(ctx context.Context, checks []*models.LinkCheck) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateLinkChecks", time.Now(), &err)
+	return r.next.UpdateLinkChecks(ctx, checks)
+}
+
+func (r *StoryRepository) GetDeadLinks(ctx context.Context, tenant string, limit int) (_ []*models.LinkCheck, err error) {
+	defer observe(ctx, "StoryRepository.GetDeadLinks", time.Now(), &err)
+	return r.next.GetDeadLinks(ctx, tenant, limit)
+}
+
+func (r *StoryRepository) GetStoriesToArchive(ctx context.Context, minScore int, requestedBefore int64, limit int) (_ []*models.StoryArchive, err error) {
+	defer observe(ctx, "StoryRepository.GetStoriesToArchive", time.Now(), &err)
+	return r.next.GetStoriesToArchive(ctx, minScore, requestedBefore, limit)
+}
+
+func (r *StoryRepository) UpdateArchives(ctx context.Context, archives []*models.StoryArchive) (err error) {
+	defer observe(ctx, "StoryRepository.UpdateArchives", time.Now(), &err)
+	return r.next.UpdateArchives(ctx, archives)
+}
+
+func (r *StoryRepository) ReplaceDuplicates(ctx context.Context, since int64, duplicates []*models.StoryDuplicate) (err error) {
+	defer observe(ctx, "StoryRepository.ReplaceDuplicates", time.Now(), &err)
+	return r.next.ReplaceDuplicates(ctx, since, duplicates)
+}
+
+func (r *StoryRepository) GetCanonicalIDs(ctx context.Context, ids []int) (_ map[int]int, err error) {
+	defer observe(ctx, "StoryRepository.GetCanonicalIDs", time.Now(), &err)
+	return r.next.GetCanonicalIDs(ctx, ids)
+}
+
+func (r *StoryRepository) CreateBatch(ctx context.Context, stories []*models.Story) (err error) {
+	defer observe(ctx, "StoryRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, stories)
+}
+
+func (r *StoryRepository) CreateBatchWithExistingIDs(ctx context.Context, stories []*models.Story) (err error) {
+	defer observe(ctx, "StoryRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, stories)
+}
+
+func (r *StoryRepository) UpsertBatch(ctx context.Context, stories []*models.Story) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "StoryRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, stories)
+}
+
+func (r *StoryRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "StoryRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+// CommentRepository records the calls of a repository.CommentRepository
+type CommentRepository struct {
+	next repository.CommentRepository
+}
+
+// NewCommentRepository wraps next, or returns it as is when the metrics are disabled
+func NewCommentRepository(next repository.CommentRepository) repository.CommentRepository {
+	if !Enabled() {
+		return next
+	}
+	return &CommentRepository{next: next}
+}
+
+func (r *CommentRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "CommentRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *CommentRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "CommentRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) (err error) {
+	defer observe(ctx, "CommentRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, comment)
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id int) (_ *models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *models.Comment) (err error) {
+	defer observe(ctx, "CommentRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, comment)
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "CommentRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *CommentRepository) GetAll(ctx context.Context) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *CommentRepository) GetRecent(ctx context.Context, limit int) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *CommentRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *CommentRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *CommentRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetByFilter", time.Now(), &err)
+	return r.next.GetByFilter(ctx, filter)
+}
+
+func (r *CommentRepository) Count(ctx context.Context, filter repository.ItemFilter) (_ int, err error) {
+	defer observe(ctx, "CommentRepository.Count", time.Now(), &err)
+	return r.next.Count(ctx, filter)
+}
+
+func (r *CommentRepository) GetThread(ctx context.Context, rootID int, maxDepth int) (_ []*models.Comment, err error) {
+	defer observe(ctx, "CommentRepository.GetThread", time.Now(), &err)
+	return r.next.GetThread(ctx, rootID, maxDepth)
+}
+
+func (r *CommentRepository) GetQualitySignals(ctx context.Context, createdSince int64) (_ []*models.CommentSignals, err error) {
+	defer observe(ctx, "CommentRepository.GetQualitySignals", time.Now(), &err)
+	return r.next.GetQualitySignals(ctx, createdSince)
+}
+
+func (r *CommentRepository) GetTextHashes(ctx context.Context, createdSince int64, limit int) (_ map[int]string, err error) {
+	defer observe(ctx, "CommentRepository.GetTextHashes", time.Now(), &err)
+	return r.next.GetTextHashes(ctx, createdSince, limit)
+}
+
+func (r *CommentRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) (err error) {
+	defer observe(ctx, "CommentRepository.UpdateSpamScores", time.Now(), &err)
+	return r.next.UpdateSpamScores(ctx, scores)
+}
+
+func (r *CommentRepository) UpdateQuality(ctx context.Context, qualities []*models.CommentQuality) (err error) {
+	defer observe(ctx, "CommentRepository.UpdateQuality", time.Now(), &err)
+	return r.next.UpdateQuality(ctx, qualities)
+}
+
+func (r *CommentRepository) CreateBatchWithExistingIDs(ctx context.Context, comments []*models.Comment) (err error) {
+	defer observe(ctx, "CommentRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, comments)
+}
+
+func (r *CommentRepository) UpsertBatch(ctx context.Context, comments []*models.Comment) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "CommentRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, comments)
+}
+
+func (r *CommentRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "CommentRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+// AskRepository records the calls of a repository.AskRepository
+type AskRepository struct {
+	next repository.AskRepository
+}
+
+// NewAskRepository wraps next, or returns it as is when the metrics are disabled
+func NewAskRepository(next repository.AskRepository) repository.AskRepository {
+	if !Enabled() {
+		return next
+	}
+	return &AskRepository{next: next}
+}
+
+func (r *AskRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "AskRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *AskRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "AskRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *AskRepository) Create(ctx context.Context, ask *models.Ask) (err error) {
+	defer observe(ctx, "AskRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, ask)
+}
+
+func (r *AskRepository) GetByID(ctx context.Context, id int) (_ *models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *AskRepository) Update(ctx context.Context, ask *models.Ask) (err error) {
+	defer observe(ctx, "AskRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, ask)
+}
+
+func (r *AskRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "AskRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *AskRepository) GetAll(ctx context.Context) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *AskRepository) GetRecent(ctx context.Context, limit int) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *AskRepository) GetByMinScore(ctx context.Context, minScore int) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetByMinScore", time.Now(), &err)
+	return r.next.GetByMinScore(ctx, minScore)
+}
+
+func (r *AskRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *AskRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *AskRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) (_ []*models.Ask, err error) {
+	defer observe(ctx, "AskRepository.GetByFilter", time.Now(), &err)
+	return r.next.GetByFilter(ctx, filter)
+}
+
+func (r *AskRepository) Count(ctx context.Context, filter repository.ItemFilter) (_ int, err error) {
+	defer observe(ctx, "AskRepository.Count", time.Now(), &err)
+	return r.next.Count(ctx, filter)
+}
+
+func (r *AskRepository) UpdateScore(ctx context.Context, id int, score int) (err error) {
+	defer observe(ctx, "AskRepository.UpdateScore", time.Now(), &err)
+	return r.next.UpdateScore(ctx, id, score)
+}
+
+func (r *AskRepository) UpdateRepliesCount(ctx context.Context, id int, count int) (err error) {
+	defer observe(ctx, "AskRepository.UpdateRepliesCount", time.Now(), &err)
+	return r.next.UpdateRepliesCount(ctx, id, count)
+}
+
+func (r *AskRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) (err error) {
+	defer observe(ctx, "AskRepository.UpdateSpamScores", time.Now(), &err)
+	return r.next.UpdateSpamScores(ctx, scores)
+}
+
+func (r *AskRepository) CreateBatch(ctx context.Context, asks []*models.Ask) (err error) {
+	defer observe(ctx, "AskRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, asks)
+}
+
+func (r *AskRepository) CreateBatchWithExistingIDs(ctx context.Context, asks []*models.Ask) (err error) {
+	defer observe(ctx, "AskRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, asks)
+}
+
+func (r *AskRepository) UpsertBatch(ctx context.Context, asks []*models.Ask) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "AskRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, asks)
+}
+
+func (r *AskRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "AskRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+// JobRepository records the calls of a repository.JobRepository
+type JobRepository struct {
+	next repository.JobRepository
+}
+
+// NewJobRepository wraps next, or returns it as is when the metrics are disabled
+func NewJobRepository(next repository.JobRepository) repository.JobRepository {
+	if !Enabled() {
+		return next
+	}
+	return &JobRepository{next: next}
+}
+
+func (r *JobRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "JobRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *JobRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "JobRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *models.Job) (err error) {
+	defer observe(ctx, "JobRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, job)
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, id int) (_ *models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *JobRepository) Update(ctx context.Context, job *models.Job) (err error) {
+	defer observe(ctx, "JobRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, job)
+}
+
+func (r *JobRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "JobRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *JobRepository) GetAll(ctx context.Context) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *JobRepository) GetRecent(ctx context.Context, limit int) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *JobRepository) GetByMinScore(ctx context.Context, minScore int) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetByMinScore", time.Now(), &err)
+	return r.next.GetByMinScore(ctx, minScore)
+}
+
+func (r *JobRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *JobRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *JobRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) (_ []*models.Job, err error) {
+	defer observe(ctx, "JobRepository.GetByFilter", time.Now(), &err)
+	return r.next.GetByFilter(ctx, filter)
+}
+
+func (r *JobRepository) Count(ctx context.Context, filter repository.ItemFilter) (_ int, err error) {
+	defer observe(ctx, "JobRepository.Count", time.Now(), &err)
+	return r.next.Count(ctx, filter)
+}
+
+func (r *JobRepository) UpdateScore(ctx context.Context, id int, score int) (err error) {
+	defer observe(ctx, "JobRepository.UpdateScore", time.Now(), &err)
+	return r.next.UpdateScore(ctx, id, score)
+}
+
+func (r *JobRepository) CreateBatch(ctx context.Context, jobs []*models.Job) (err error) {
+	defer observe(ctx, "JobRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, jobs)
+}
+
+func (r *JobRepository) CreateBatchWithExistingIDs(ctx context.Context, jobs []*models.Job) (err error) {
+	defer observe(ctx, "JobRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, jobs)
+}
+
+func (r *JobRepository) UpsertBatch(ctx context.Context, jobs []*models.Job) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "JobRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, jobs)
+}
+
+func (r *JobRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "JobRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+// PollRepository records the calls of a repository.PollRepository
+type PollRepository struct {
+	next repository.PollRepository
+}
+
+// NewPollRepository wraps next, or returns it as is when the metrics are disabled
+func NewPollRepository(next repository.PollRepository) repository.PollRepository {
+	if !Enabled() {
+		return next
+	}
+	return &PollRepository{next: next}
+}
+
+func (r *PollRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "PollRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *PollRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "PollRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *PollRepository) Create(ctx context.Context, poll *models.Poll) (err error) {
+	defer observe(ctx, "PollRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, poll)
+}
+
+func (r *PollRepository) GetByID(ctx context.Context, id int) (_ *models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *PollRepository) Update(ctx context.Context, poll *models.Poll) (err error) {
+	defer observe(ctx, "PollRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, poll)
+}
+
+func (r *PollRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "PollRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *PollRepository) GetAll(ctx context.Context) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *PollRepository) GetRecent(ctx context.Context, limit int) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *PollRepository) GetByMinScore(ctx context.Context, minScore int) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetByMinScore", time.Now(), &err)
+	return r.next.GetByMinScore(ctx, minScore)
+}
+
+func (r *PollRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *PollRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *PollRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) (_ []*models.Poll, err error) {
+	defer observe(ctx, "PollRepository.GetByFilter", time.Now(), &err)
+	return r.next.GetByFilter(ctx, filter)
+}
+
+func (r *PollRepository) Count(ctx context.Context, filter repository.ItemFilter) (_ int, err error) {
+	defer observe(ctx, "PollRepository.Count", time.Now(), &err)
+	return r.next.Count(ctx, filter)
+}
+
+func (r *PollRepository) UpdateScore(ctx context.Context, id int, score int) (err error) {
+	defer observe(ctx, "PollRepository.UpdateScore", time.Now(), &err)
+	return r.next.UpdateScore(ctx, id, score)
+}
+
+func (r *PollRepository) CreateBatch(ctx context.Context, polls []*models.Poll) (err error) {
+	defer observe(ctx, "PollRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, polls)
+}
+
+func (r *PollRepository) CreateBatchWithExistingIDs(ctx context.Context, polls []*models.Poll) (err error) {
+	defer observe(ctx, "PollRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, polls)
+}
+
+func (r *PollRepository) UpsertBatch(ctx context.Context, polls []*models.Poll) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "PollRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, polls)
+}
+
+func (r *PollRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "PollRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+// PollOptionRepository records the calls of a repository.PollOptionRepository
+type PollOptionRepository struct {
+	next repository.PollOptionRepository
+}
+
+// NewPollOptionRepository wraps next, or returns it as is when the metrics are disabled
+func NewPollOptionRepository(next repository.PollOptionRepository) repository.PollOptionRepository {
+	if !Enabled() {
+		return next
+	}
+	return &PollOptionRepository{next: next}
+}
+
+func (r *PollOptionRepository) Exists(ctx context.Context, id int) (_ bool, err error) {
+	defer observe(ctx, "PollOptionRepository.Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *PollOptionRepository) GetCount(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "PollOptionRepository.GetCount", time.Now(), &err)
+	return r.next.GetCount(ctx)
+}
+
+func (r *PollOptionRepository) Create(ctx context.Context, pollOption *models.PollOption) (err error) {
+	defer observe(ctx, "PollOptionRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, pollOption)
+}
+
+func (r *PollOptionRepository) GetByID(ctx context.Context, id int) (_ *models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *PollOptionRepository) Update(ctx context.Context, pollOption *models.PollOption) (err error) {
+	defer observe(ctx, "PollOptionRepository.Update", time.Now(), &err)
+	return r.next.Update(ctx, pollOption)
+}
+
+func (r *PollOptionRepository) Delete(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "PollOptionRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *PollOptionRepository) GetAll(ctx context.Context) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *PollOptionRepository) GetByPollID(ctx context.Context, pollID int) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetByPollID", time.Now(), &err)
+	return r.next.GetByPollID(ctx, pollID)
+}
+
+func (r *PollOptionRepository) GetRecent(ctx context.Context, limit int) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetRecent", time.Now(), &err)
+	return r.next.GetRecent(ctx, limit)
+}
+
+func (r *PollOptionRepository) GetByAuthor(ctx context.Context, author string) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetByAuthor", time.Now(), &err)
+	return r.next.GetByAuthor(ctx, author)
+}
+
+func (r *PollOptionRepository) GetByDateRange(ctx context.Context, start int64, end int64) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetByDateRange", time.Now(), &err)
+	return r.next.GetByDateRange(ctx, start, end)
+}
+
+func (r *PollOptionRepository) GetVoteCount(ctx context.Context, id int) (_ int, err error) {
+	defer observe(ctx, "PollOptionRepository.GetVoteCount", time.Now(), &err)
+	return r.next.GetVoteCount(ctx, id)
+}
+
+func (r *PollOptionRepository) CountByPollID(ctx context.Context, pollID int) (_ int, err error) {
+	defer observe(ctx, "PollOptionRepository.CountByPollID", time.Now(), &err)
+	return r.next.CountByPollID(ctx, pollID)
+}
+
+func (r *PollOptionRepository) GetTopVoted(ctx context.Context, pollID int, limit int) (_ []*models.PollOption, err error) {
+	defer observe(ctx, "PollOptionRepository.GetTopVoted", time.Now(), &err)
+	return r.next.GetTopVoted(ctx, pollID, limit)
+}
+
+func (r *PollOptionRepository) UpdateVotes(ctx context.Context, id int, votes int) (err error) {
+	defer observe(ctx, "PollOptionRepository.UpdateVotes", time.Now(), &err)
+	return r.next.UpdateVotes(ctx, id, votes)
+}
+
+func (r *PollOptionRepository) IncrementVotes(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "PollOptionRepository.IncrementVotes", time.Now(), &err)
+	return r.next.IncrementVotes(ctx, id)
+}
+
+func (r *PollOptionRepository) DecrementVotes(ctx context.Context, id int) (err error) {
+	defer observe(ctx, "PollOptionRepository.DecrementVotes", time.Now(), &err)
+	return r.next.DecrementVotes(ctx, id)
+}
+
+func (r *PollOptionRepository) CreateBatch(ctx context.Context, pollOptions []*models.PollOption) (err error) {
+	defer observe(ctx, "PollOptionRepository.CreateBatch", time.Now(), &err)
+	return r.next.CreateBatch(ctx, pollOptions)
+}
+
+func (r *PollOptionRepository) CreateBatchWithExistingIDs(ctx context.Context, pollOptions []*models.PollOption) (err error) {
+	defer observe(ctx, "PollOptionRepository.CreateBatchWithExistingIDs", time.Now(), &err)
+	return r.next.CreateBatchWithExistingIDs(ctx, pollOptions)
+}
+
+func (r *PollOptionRepository) UpsertBatch(ctx context.Context, pollOptions []*models.PollOption) (_ repository.UpsertCounts, err error) {
+	defer observe(ctx, "PollOptionRepository.UpsertBatch", time.Now(), &err)
+	return r.next.UpsertBatch(ctx, pollOptions)
+}
+
+func (r *PollOptionRepository) DeleteByAuthor(ctx context.Context, author string) (err error) {
+	defer observe(ctx, "PollOptionRepository.DeleteByAuthor", time.Now(), &err)
+	return r.next.DeleteByAuthor(ctx, author)
+}
+
+func (r *PollOptionRepository) DeleteByPollID(ctx context.Context, pollID int) (err error) {
+	defer observe(ctx, "PollOptionRepository.DeleteByPollID", time.Now(), &err)
+	return r.next.DeleteByPollID(ctx, pollID)
+}
+
+func (r *PollOptionRepository) IncrementVotesBatch(ctx context.Context, deltas map[int]int) (err error) {
+	defer observe(ctx, "PollOptionRepository.IncrementVotesBatch", time.Now(), &err)
+	return r.next.IncrementVotesBatch(ctx, deltas)
+}
+
+// TimelineRepository records the calls of a repository.TimelineRepository
+type TimelineRepository struct {
+	next repository.TimelineRepository
+}
+
+// NewTimelineRepository wraps next, or returns it as is when the metrics are disabled
+func NewTimelineRepository(next repository.TimelineRepository) repository.TimelineRepository {
+	if !Enabled() {
+		return next
+	}
+	return &TimelineRepository{next: next}
+}
+
+func (r *TimelineRepository) GetTimeline(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) (_ []*models.TimelineItem, err error) {
+	defer observe(ctx, "TimelineRepository.GetTimeline", time.Now(), &err)
+	return r.next.GetTimeline(ctx, tenant, cursor, limit)
+}
+
+func (r *TimelineRepository) GetFeed(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) (_ []*models.TimelineItem, err error) {
+	defer observe(ctx, "TimelineRepository.GetFeed", time.Now(), &err)
+	return r.next.GetFeed(ctx, tenant, cursor, limit)
+}
+
+func (r *TimelineRepository) GetByTag(ctx context.Context, tenant string, tag string, cursor models.TimelineCursor, limit int) (_ []*models.TimelineItem, err error) {
+	defer observe(ctx, "TimelineRepository.GetByTag", time.Now(), &err)
+	return r.next.GetByTag(ctx, tenant, tag, cursor, limit)
+}
+
+func (r *TimelineRepository) GetNewAuthors(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) (_ []*models.TimelineItem, err error) {
+	defer observe(ctx, "TimelineRepository.GetNewAuthors", time.Now(), &err)
+	return r.next.GetNewAuthors(ctx, tenant, cursor, limit)
+}
+
+// StatsRepository records the calls of a repository.StatsRepository
+type StatsRepository struct {
+	next repository.StatsRepository
+}
+
+// NewStatsRepository wraps next, or returns it as is when the metrics are disabled
+func NewStatsRepository(next repository.StatsRepository) repository.StatsRepository {
+	if !Enabled() {
+		return next
+	}
+	return &StatsRepository{next: next}
+}
+
+func (r *StatsRepository) GetAuthorHeatmap(ctx context.Context, tenant string, author string) (_ *models.ActivityHeatmap, err error) {
+	defer observe(ctx, "StatsRepository.GetAuthorHeatmap", time.Now(), &err)
+	return r.next.GetAuthorHeatmap(ctx, tenant, author)
+}
+
+func (r *StatsRepository) GetNewestItemID(ctx context.Context) (_ int, err error) {
+	defer observe(ctx, "StatsRepository.GetNewestItemID", time.Now(), &err)
+	return r.next.GetNewestItemID(ctx)
+}
+
+func (r *StatsRepository) GetItemFreshness(ctx context.Context) (_ []*models.ItemFreshness, err error) {
+	defer observe(ctx, "StatsRepository.GetItemFreshness", time.Now(), &err)
+	return r.next.GetItemFreshness(ctx)
+}
+
+func (r *StatsRepository) RefreshDailyStats(ctx context.Context, day time.Time, topN int) (err error) {
+	defer observe(ctx, "StatsRepository.RefreshDailyStats", time.Now(), &err)
+	return r.next.RefreshDailyStats(ctx, day, topN)
+}
+
+func (r *StatsRepository) GetDailyStats(ctx context.Context, tenant string, from time.Time, to time.Time) (_ []*models.DailyStats, err error) {
+	defer observe(ctx, "StatsRepository.GetDailyStats", time.Now(), &err)
+	return r.next.GetDailyStats(ctx, tenant, from, to)
+}
+
+func (r *StatsRepository) RefreshScorePercentiles(ctx context.Context, day time.Time, windowDays int) (err error) {
+	defer observe(ctx, "StatsRepository.RefreshScorePercentiles", time.Now(), &err)
+	return r.next.RefreshScorePercentiles(ctx, day, windowDays)
+}
+
+func (r *StatsRepository) GetScorePercentiles(ctx context.Context, tenant string, from time.Time, to time.Time) (_ []*models.ScorePercentiles, err error) {
+	defer observe(ctx, "StatsRepository.GetScorePercentiles", time.Now(), &err)
+	return r.next.GetScorePercentiles(ctx, tenant, from, to)
+}
+
+// TenantRepository records the calls of a repository.TenantRepository
+type TenantRepository struct {
+	next repository.TenantRepository
+}
+
+// NewTenantRepository wraps next, or returns it as is when the metrics are disabled
+func NewTenantRepository(next repository.TenantRepository) repository.TenantRepository {
+	if !Enabled() {
+		return next
+	}
+	return &TenantRepository{next: next}
+}
+
+func (r *TenantRepository) Create(ctx context.Context, tenant *models.Tenant) (err error) {
+	defer observe(ctx, "TenantRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, tenant)
+}
+
+func (r *TenantRepository) GetByName(ctx context.Context, name string) (_ *models.Tenant, err error) {
+	defer observe(ctx, "TenantRepository.GetByName", time.Now(), &err)
+	return r.next.GetByName(ctx, name)
+}
+
+func (r *TenantRepository) GetByAPIKey(ctx context.Context, apiKey string) (_ *models.Tenant, err error) {
+	defer observe(ctx, "TenantRepository.GetByAPIKey", time.Now(), &err)
+	return r.next.GetByAPIKey(ctx, apiKey)
+}
+
+func (r *TenantRepository) GetAll(ctx context.Context) (_ []*models.Tenant, err error) {
+	defer observe(ctx, "TenantRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+func (r *TenantRepository) Delete(ctx context.Context, name string) (err error) {
+	defer observe(ctx, "TenantRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, name)
+}
+
+func (r *TenantRepository) GetQuotaUsage(ctx context.Context, name string) (_ *models.QuotaUsage, err error) {
+	defer observe(ctx, "TenantRepository.GetQuotaUsage", time.Now(), &err)
+	return r.next.GetQuotaUsage(ctx, name)
+}
+
+// ItemRepository records the calls of a repository.ItemRepository
+type ItemRepository struct {
+	next repository.ItemRepository
+}
+
+// NewItemRepository wraps next, or returns it as is when the metrics are disabled
+func NewItemRepository(next repository.ItemRepository) repository.ItemRepository {
+	if !Enabled() {
+		return next
+	}
+	return &ItemRepository{next: next}
+}
+
+func (r *ItemRepository) GetKind(ctx context.Context, id int) (_ string, err error) {
+	defer observe(ctx, "ItemRepository.GetKind", time.Now(), &err)
+	return r.next.GetKind(ctx, id)
+}
+
+func (r *ItemRepository) GetKinds(ctx context.Context, ids []int) (_ map[int]string, err error) {
+	defer observe(ctx, "ItemRepository.GetKinds", time.Now(), &err)
+	return r.next.GetKinds(ctx, ids)
+}
+
+func (r *ItemRepository) GetIDs(ctx context.Context, kinds []string, filter repository.ItemFilter, limit int) (_ []int, err error) {
+	defer observe(ctx, "ItemRepository.GetIDs", time.Now(), &err)
+	return r.next.GetIDs(ctx, kinds, filter, limit)
+}
+
+func (r *ItemRepository) ScanIDs(ctx context.Context, afterID int, limit int) (_ []int, err error) {
+	defer observe(ctx, "ItemRepository.ScanIDs", time.Now(), &err)
+	return r.next.ScanIDs(ctx, afterID, limit)
+}
+
+func (r *ItemRepository) CountExtraKeys(ctx context.Context, kind string) (_ map[string]int64, err error) {
+	defer observe(ctx, "ItemRepository.CountExtraKeys", time.Now(), &err)
+	return r.next.CountExtraKeys(ctx, kind)
+}
+
+func (r *ItemRepository) MarkNewAuthor(ctx context.Context, kind string, id int, accountAge time.Duration) (_ bool, err error) {
+	defer observe(ctx, "ItemRepository.MarkNewAuthor", time.Now(), &err)
+	return r.next.MarkNewAuthor(ctx, kind, id, accountAge)
+}
+
+// DiagnosticsRepository records the calls of a repository.DiagnosticsRepository
+type DiagnosticsRepository struct {
+	next repository.DiagnosticsRepository
+}
+
+// NewDiagnosticsRepository wraps next, or returns it as is when the metrics are disabled
+func NewDiagnosticsRepository(next repository.DiagnosticsRepository) repository.DiagnosticsRepository {
+	if !Enabled() {
+		return next
+	}
+	return &DiagnosticsRepository{next: next}
+}
+
+func (r *DiagnosticsRepository) ExplainableQueries() []string {
+	defer observe(context.Background(), "DiagnosticsRepository.ExplainableQueries", time.Now(), nil)
+	return r.next.ExplainableQueries()
+}
+
+func (r *DiagnosticsRepository) ExplainQuery(ctx context.Context, name string, filter repository.ItemFilter) (_ *models.QueryPlan, err error) {
+	defer observe(ctx, "DiagnosticsRepository.ExplainQuery", time.Now(), &err)
+	return r.next.ExplainQuery(ctx, name, filter)
+}
+
+// FollowRepository records the calls of a repository.FollowRepository
+type FollowRepository struct {
+	next repository.FollowRepository
+}
+
+// NewFollowRepository wraps next, or returns it as is when the metrics are disabled
+func NewFollowRepository(next repository.FollowRepository) repository.FollowRepository {
+	if !Enabled() {
+		return next
+	}
+	return &FollowRepository{next: next}
+}
+
+func (r *FollowRepository) Follow(ctx context.Context, tenant string, author string) (_ *models.Follow, err error) {
+	defer observe(ctx, "FollowRepository.Follow", time.Now(), &err)
+	return r.next.Follow(ctx, tenant, author)
+}
+
+func (r *FollowRepository) Unfollow(ctx context.Context, tenant string, author string) (err error) {
+	defer observe(ctx, "FollowRepository.Unfollow", time.Now(), &err)
+	return r.next.Unfollow(ctx, tenant, author)
+}
+
+func (r *FollowRepository) GetFollows(ctx context.Context, tenant string) (_ []*models.Follow, err error) {
+	defer observe(ctx, "FollowRepository.GetFollows", time.Now(), &err)
+	return r.next.GetFollows(ctx, tenant)
+}
+
+func (r *FollowRepository) SaveSearch(ctx context.Context, tenant string, query string) (_ *models.SavedSearch, err error) {
+	defer observe(ctx, "FollowRepository.SaveSearch", time.Now(), &err)
+	return r.next.SaveSearch(ctx, tenant, query)
+}
+
+func (r *FollowRepository) DeleteSavedSearch(ctx context.Context, tenant string, id int) (err error) {
+	defer observe(ctx, "FollowRepository.DeleteSavedSearch", time.Now(), &err)
+	return r.next.DeleteSavedSearch(ctx, tenant, id)
+}
+
+func (r *FollowRepository) GetSavedSearches(ctx context.Context, tenant string) (_ []*models.SavedSearch, err error) {
+	defer observe(ctx, "FollowRepository.GetSavedSearches", time.Now(), &err)
+	return r.next.GetSavedSearches(ctx, tenant)
+}
+
+// DataQualityRepository records the calls of a repository.DataQualityRepository
+type DataQualityRepository struct {
+	next repository.DataQualityRepository
+}
+
+// NewDataQualityRepository wraps next, or returns it as is when the metrics are disabled
+func NewDataQualityRepository(next repository.DataQualityRepository) repository.DataQualityRepository {
+	if !Enabled() {
+		return next
+	}
+	return &DataQualityRepository{next: next}
+}
+
+func (r *DataQualityRepository) RunChecks(ctx context.Context, sampleSize int) (_ []models.DataQualityCheck, err error) {
+	defer observe(ctx, "DataQualityRepository.RunChecks", time.Now(), &err)
+	return r.next.RunChecks(ctx, sampleSize)
+}
+
+func (r *DataQualityRepository) CountItems(ctx context.Context, kind string) (_ int64, err error) {
+	defer observe(ctx, "DataQualityRepository.CountItems", time.Now(), &err)
+	return r.next.CountItems(ctx, kind)
+}
+
+func (r *DataQualityRepository) CountItemsInRange(ctx context.Context, kind string, start int64, end int64) (_ int64, err error) {
+	defer observe(ctx, "DataQualityRepository.CountItemsInRange", time.Now(), &err)
+	return r.next.CountItemsInRange(ctx, kind, start, end)
+}
+
+func (r *DataQualityRepository) GetItemIDs(ctx context.Context, kind string, start int64, end int64, afterID int, limit int) (_ []int, err error) {
+	defer observe(ctx, "DataQualityRepository.GetItemIDs", time.Now(), &err)
+	return r.next.GetItemIDs(ctx, kind, start, end, afterID, limit)
+}
+
+func (r *DataQualityRepository) SampleItemIDs(ctx context.Context, kind string, start int64, end int64, n int) (_ []int, err error) {
+	defer observe(ctx, "DataQualityRepository.SampleItemIDs", time.Now(), &err)
+	return r.next.SampleItemIDs(ctx, kind, start, end, n)
+}
+
+func (r *DataQualityRepository) GetItemDocuments(ctx context.Context, kind string, ids []int) (_ map[int]map[string]interface{}, err error) {
+	defer observe(ctx, "DataQualityRepository.GetItemDocuments", time.Now(), &err)
+	return r.next.GetItemDocuments(ctx, kind, ids)
+}
+
+func (r *DataQualityRepository) SaveReport(ctx context.Context, report *models.DataQualityReport) (err error) {
+	defer observe(ctx, "DataQualityRepository.SaveReport", time.Now(), &err)
+	return r.next.SaveReport(ctx, report)
+}
+
+func (r *DataQualityRepository) GetLatestReport(ctx context.Context) (_ *models.DataQualityReport, err error) {
+	defer observe(ctx, "DataQualityRepository.GetLatestReport", time.Now(), &err)
+	return r.next.GetLatestReport(ctx)
+}
+
+// ChangeRepository records the calls of a repository.ChangeRepository
+type ChangeRepository struct {
+	next repository.ChangeRepository
+}
+
+// NewChangeRepository wraps next, or returns it as is when the metrics are disabled
+func NewChangeRepository(next repository.ChangeRepository) repository.ChangeRepository {
+	if !Enabled() {
+		return next
+	}
+	return &ChangeRepository{next: next}
+}
+
+func (r *ChangeRepository) GetUpdatedSince(ctx context.Context, kind string, since models.ItemChange, limit int) (_ []models.ItemChange, err error) {
+	defer observe(ctx, "ChangeRepository.GetUpdatedSince", time.Now(), &err)
+	return r.next.GetUpdatedSince(ctx, kind, since, limit)
+}
+
+func (r *ChangeRepository) SequenceChanges(ctx context.Context, limit int) (_ int, err error) {
+	defer observe(ctx, "ChangeRepository.SequenceChanges", time.Now(), &err)
+	return r.next.SequenceChanges(ctx, limit)
+}
+
+func (r *ChangeRepository) GetChangesSince(ctx context.Context, tenant string, since int64, limit int) (_ []*models.SequencedChange, err error) {
+	defer observe(ctx, "ChangeRepository.GetChangesSince", time.Now(), &err)
+	return r.next.GetChangesSince(ctx, tenant, since, limit)
+}
+
+func (r *ChangeRepository) GetChangeLogBounds(ctx context.Context) (_ int64, _ int64, err error) {
+	defer observe(ctx, "ChangeRepository.GetChangeLogBounds", time.Now(), &err)
+	return r.next.GetChangeLogBounds(ctx)
+}
+
+func (r *ChangeRepository) PruneChanges(ctx context.Context, before int64) (_ int64, err error) {
+	defer observe(ctx, "ChangeRepository.PruneChanges", time.Now(), &err)
+	return r.next.PruneChanges(ctx, before)
+}
+
+func (r *ChangeRepository) GetItemHistory(ctx context.Context, kind string, id int, after int64) (_ []*models.ItemRevision, err error) {
+	defer observe(ctx, "ChangeRepository.GetItemHistory", time.Now(), &err)
+	return r.next.GetItemHistory(ctx, kind, id, after)
+}
+
+// SearchQueryRepository records the calls of a repository.SearchQueryRepository
+type SearchQueryRepository struct {
+	next repository.SearchQueryRepository
+}
+
+// NewSearchQueryRepository wraps next, or returns it as is when the metrics are disabled
+func NewSearchQueryRepository(next repository.SearchQueryRepository) repository.SearchQueryRepository {
+	if !Enabled() {
+		return next
+	}
+	return &SearchQueryRepository{next: next}
+}
+
+func (r *SearchQueryRepository) Record(ctx context.Context, q *models.SearchQuery) (err error) {
+	defer observe(ctx, "SearchQueryRepository.Record", time.Now(), &err)
+	return r.next.Record(ctx, q)
+}
+
+func (r *SearchQueryRepository) GetTopQueries(ctx context.Context, tenant string, since int64, limit int) (_ []*models.QueryStat, err error) {
+	defer observe(ctx, "SearchQueryRepository.GetTopQueries", time.Now(), &err)
+	return r.next.GetTopQueries(ctx, tenant, since, limit)
+}
+
+func (r *SearchQueryRepository) GetZeroResultQueries(ctx context.Context, tenant string, since int64, limit int) (_ []*models.QueryStat, err error) {
+	defer observe(ctx, "SearchQueryRepository.GetZeroResultQueries", time.Now(), &err)
+	return r.next.GetZeroResultQueries(ctx, tenant, since, limit)
+}
+
+// TagRepository records the calls of a repository.TagRepository
+type TagRepository struct {
+	next repository.TagRepository
+}
+
+// NewTagRepository wraps next, or returns it as is when the metrics are disabled
+func NewTagRepository(next repository.TagRepository) repository.TagRepository {
+	if !Enabled() {
+		return next
+	}
+	return &TagRepository{next: next}
+}
+
+func (r *TagRepository) ReplaceTags(ctx context.Context, kind string, id int, tags []string) (err error) {
+	defer observe(ctx, "TagRepository.ReplaceTags", time.Now(), &err)
+	return r.next.ReplaceTags(ctx, kind, id, tags)
+}
+
+func (r *TagRepository) GetTagCounts(ctx context.Context, tenant string, limit int) (_ []*models.TagCount, err error) {
+	defer observe(ctx, "TagRepository.GetTagCounts", time.Now(), &err)
+	return r.next.GetTagCounts(ctx, tenant, limit)
+}
+
+// MentionRepository records the calls of a repository.MentionRepository
+type MentionRepository struct {
+	next repository.MentionRepository
+}
+
+// NewMentionRepository wraps next, or returns it as is when the metrics are disabled
+func NewMentionRepository(next repository.MentionRepository) repository.MentionRepository {
+	if !Enabled() {
+		return next
+	}
+	return &MentionRepository{next: next}
+}
+
+func (r *MentionRepository) ReplaceMentions(ctx context.Context, comment *models.Comment, usernames []string) (err error) {
+	defer observe(ctx, "MentionRepository.ReplaceMentions", time.Now(), &err)
+	return r.next.ReplaceMentions(ctx, comment, usernames)
+}
+
+func (r *MentionRepository) GetByUsername(ctx context.Context, tenant string, username string, cursor models.TimelineCursor, limit int) (_ []*models.Mention, err error) {
+	defer observe(ctx, "MentionRepository.GetByUsername", time.Now(), &err)
+	return r.next.GetByUsername(ctx, tenant, username, cursor, limit)
+}
+
+// WatchRepository records the calls of a repository.WatchRepository
+type WatchRepository struct {
+	next repository.WatchRepository
+}
+
+// NewWatchRepository wraps next, or returns it as is when the metrics are disabled
+func NewWatchRepository(next repository.WatchRepository) repository.WatchRepository {
+	if !Enabled() {
+		return next
+	}
+	return &WatchRepository{next: next}
+}
+
+func (r *WatchRepository) Create(ctx context.Context, tenant string, watch *models.Watch) (err error) {
+	defer observe(ctx, "WatchRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, tenant, watch)
+}
+
+func (r *WatchRepository) Delete(ctx context.Context, tenant string, id string) (err error) {
+	defer observe(ctx, "WatchRepository.Delete", time.Now(), &err)
+	return r.next.Delete(ctx, tenant, id)
+}
+
+func (r *WatchRepository) GetByTenant(ctx context.Context, tenant string) (_ []*models.Watch, err error) {
+	defer observe(ctx, "WatchRepository.GetByTenant", time.Now(), &err)
+	return r.next.GetByTenant(ctx, tenant)
+}
+
+func (r *WatchRepository) GetByItem(ctx context.Context, itemID int) (_ []*models.Watch, err error) {
+	defer observe(ctx, "WatchRepository.GetByItem", time.Now(), &err)
+	return r.next.GetByItem(ctx, itemID)
+}
+
+func (r *WatchRepository) GetRankWatches(ctx context.Context) (_ []*models.Watch, err error) {
+	defer observe(ctx, "WatchRepository.GetRankWatches", time.Now(), &err)
+	return r.next.GetRankWatches(ctx)
+}
+
+func (r *WatchRepository) UpdateState(ctx context.Context, id string, state models.WatchState, notifiedAt int64) (err error) {
+	defer observe(ctx, "WatchRepository.UpdateState", time.Now(), &err)
+	return r.next.UpdateState(ctx, id, state, notifiedAt)
+}
+
+// TaskRepository records the calls of a repository.TaskRepository
+type TaskRepository struct {
+	next repository.TaskRepository
+}
+
+// NewTaskRepository wraps next, or returns it as is when the metrics are disabled
+func NewTaskRepository(next repository.TaskRepository) repository.TaskRepository {
+	if !Enabled() {
+		return next
+	}
+	return &TaskRepository{next: next}
+}
+
+func (r *TaskRepository) Enqueue(ctx context.Context, task *models.Task) (err error) {
+	defer observe(ctx, "TaskRepository.Enqueue", time.Now(), &err)
+	return r.next.Enqueue(ctx, task)
+}
+
+func (r *TaskRepository) Claim(ctx context.Context, types []string, now int64, leaseUntil int64) (_ *models.Task, err error) {
+	defer observe(ctx, "TaskRepository.Claim", time.Now(), &err)
+	return r.next.Claim(ctx, types, now, leaseUntil)
+}
+
+func (r *TaskRepository) Complete(ctx context.Context, id string) (err error) {
+	defer observe(ctx, "TaskRepository.Complete", time.Now(), &err)
+	return r.next.Complete(ctx, id)
+}
+
+func (r *TaskRepository) Retry(ctx context.Context, id string, lastError string, runAfter int64) (err error) {
+	defer observe(ctx, "TaskRepository.Retry", time.Now(), &err)
+	return r.next.Retry(ctx, id, lastError, runAfter)
+}
+
+func (r *TaskRepository) Fail(ctx context.Context, id string, lastError string) (err error) {
+	defer observe(ctx, "TaskRepository.Fail", time.Now(), &err)
+	return r.next.Fail(ctx, id, lastError)
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (_ *models.Task, err error) {
+	defer observe(ctx, "TaskRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *TaskRepository) List(ctx context.Context, status string, limit int) (_ []*models.Task, err error) {
+	defer observe(ctx, "TaskRepository.List", time.Now(), &err)
+	return r.next.List(ctx, status, limit)
+}
+
+// ComputedFieldRepository records the calls of a repository.ComputedFieldRepository
+type ComputedFieldRepository struct {
+	next repository.ComputedFieldRepository
+}
+
+// NewComputedFieldRepository wraps next, or returns it as is when the metrics are disabled
+func NewComputedFieldRepository(next repository.ComputedFieldRepository) repository.ComputedFieldRepository {
+	if !Enabled() {
+		return next
+	}
+	return &ComputedFieldRepository{next: next}
+}
+
+func (r *ComputedFieldRepository) ReplaceFields(ctx context.Context, kind string, id int, values map[string]float64) (err error) {
+	defer observe(ctx, "ComputedFieldRepository.ReplaceFields", time.Now(), &err)
+	return r.next.ReplaceFields(ctx, kind, id, values)
+}
+
+func (r *ComputedFieldRepository) GetFields(ctx context.Context, kind string, id int) (_ map[string]float64, err error) {
+	defer observe(ctx, "ComputedFieldRepository.GetFields", time.Now(), &err)
+	return r.next.GetFields(ctx, kind, id)
+}
+
+// WebhookDeliveryRepository records the calls of a repository.WebhookDeliveryRepository
+type WebhookDeliveryRepository struct {
+	next repository.WebhookDeliveryRepository
+}
+
+// NewWebhookDeliveryRepository wraps next, or returns it as is when the metrics are disabled
+func NewWebhookDeliveryRepository(next repository.WebhookDeliveryRepository) repository.WebhookDeliveryRepository {
+	if !Enabled() {
+		return next
+	}
+	return &WebhookDeliveryRepository{next: next}
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) (err error) {
+	defer observe(ctx, "WebhookDeliveryRepository.Create", time.Now(), &err)
+	return r.next.Create(ctx, delivery)
+}
+
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, id string, responseCode int, lastError string) (err error) {
+	defer observe(ctx, "WebhookDeliveryRepository.RecordAttempt", time.Now(), &err)
+	return r.next.RecordAttempt(ctx, id, responseCode, lastError)
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (_ *models.WebhookDelivery, err error) {
+	defer observe(ctx, "WebhookDeliveryRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *WebhookDeliveryRepository) List(ctx context.Context, status string, limit int) (_ []*models.WebhookDelivery, err error) {
+	defer observe(ctx, "WebhookDeliveryRepository.List", time.Now(), &err)
+	return r.next.List(ctx, status, limit)
+}
+
+// DeadLetterRepository records the calls of a repository.DeadLetterRepository
+type DeadLetterRepository struct {
+	next repository.DeadLetterRepository
+}
+
+// NewDeadLetterRepository wraps next, or returns it as is when the metrics are disabled
+func NewDeadLetterRepository(next repository.DeadLetterRepository) repository.DeadLetterRepository {
+	if !Enabled() {
+		return next
+	}
+	return &DeadLetterRepository{next: next}
+}
+
+func (r *DeadLetterRepository) Record(ctx context.Context, letter *models.DeadLetter) (err error) {
+	defer observe(ctx, "DeadLetterRepository.Record", time.Now(), &err)
+	return r.next.Record(ctx, letter)
+}
+
+func (r *DeadLetterRepository) List(ctx context.Context, kind string, limit int) (_ []*models.DeadLetter, err error) {
+	defer observe(ctx, "DeadLetterRepository.List", time.Now(), &err)
+	return r.next.List(ctx, kind, limit)
+}
+
+func (r *DeadLetterRepository) GetGone(ctx context.Context, ids []int, minOccurrences int) (_ []int, err error) {
+	defer observe(ctx, "DeadLetterRepository.GetGone", time.Now(), &err)
+	return r.next.GetGone(ctx, ids, minOccurrences)
+}
+
+// ItemPayloadRepository records the calls of a repository.ItemPayloadRepository
+type ItemPayloadRepository struct {
+	next repository.ItemPayloadRepository
+}
+
+// NewItemPayloadRepository wraps next, or returns it as is when the metrics are disabled
+func NewItemPayloadRepository(next repository.ItemPayloadRepository) repository.ItemPayloadRepository {
+	if !Enabled() {
+		return next
+	}
+	return &ItemPayloadRepository{next: next}
+}
+
+func (r *ItemPayloadRepository) Save(ctx context.Context, payload *models.ItemPayload) (err error) {
+	defer observe(ctx, "ItemPayloadRepository.Save", time.Now(), &err)
+	return r.next.Save(ctx, payload)
+}
+
+func (r *ItemPayloadRepository) GetByID(ctx context.Context, id int) (_ *models.ItemPayload, err error) {
+	defer observe(ctx, "ItemPayloadRepository.GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *ItemPayloadRepository) GetByIDs(ctx context.Context, ids []int) (_ map[int]*models.ItemPayload, err error) {
+	defer observe(ctx, "ItemPayloadRepository.GetByIDs", time.Now(), &err)
+	return r.next.GetByIDs(ctx, ids)
+}
+
+// LinkPreviewRepository records the calls of a repository.LinkPreviewRepository
+type LinkPreviewRepository struct {
+	next repository.LinkPreviewRepository
+}
+
+// NewLinkPreviewRepository wraps next, or returns it as is when the metrics are disabled
+func NewLinkPreviewRepository(next repository.LinkPreviewRepository) repository.LinkPreviewRepository {
+	if !Enabled() {
+		return next
+	}
+	return &LinkPreviewRepository{next: next}
+}
+
+func (r *LinkPreviewRepository) GetLinksToPreview(ctx context.Context, fetchedBefore int64, failedBefore int64, limit int) (_ []*models.LinkPreview, err error) {
+	defer observe(ctx, "LinkPreviewRepository.GetLinksToPreview", time.Now(), &err)
+	return r.next.GetLinksToPreview(ctx, fetchedBefore, failedBefore, limit)
+}
+
+func (r *LinkPreviewRepository) SavePreviews(ctx context.Context, previews []*models.LinkPreview) (err error) {
+	defer observe(ctx, "LinkPreviewRepository.SavePreviews", time.Now(), &err)
+	return r.next.SavePreviews(ctx, previews)
+}
+
+func (r *LinkPreviewRepository) GetByStory(ctx context.Context, storyID int) (_ *models.LinkPreview, err error) {
+	defer observe(ctx, "LinkPreviewRepository.GetByStory", time.Now(), &err)
+	return r.next.GetByStory(ctx, storyID)
+}
+
+// DomainRepository records the calls of a repository.DomainRepository
+type DomainRepository struct {
+	next repository.DomainRepository
+}
+
+// NewDomainRepository wraps next, or returns it as is when the metrics are disabled
+func NewDomainRepository(next repository.DomainRepository) repository.DomainRepository {
+	if !Enabled() {
+		return next
+	}
+	return &DomainRepository{next: next}
+}
+
+func (r *DomainRepository) RefreshStats(ctx context.Context) (err error) {
+	defer observe(ctx, "DomainRepository.RefreshStats", time.Now(), &err)
+	return r.next.RefreshStats(ctx)
+}
+
+func (r *DomainRepository) GetFaviconsToCheck(ctx context.Context, checkedBefore int64, limit int) (_ []string, err error) {
+	defer observe(ctx, "DomainRepository.GetFaviconsToCheck", time.Now(), &err)
+	return r.next.GetFaviconsToCheck(ctx, checkedBefore, limit)
+}
+
+func (r *DomainRepository) SetFavicon(ctx context.Context, domain string, faviconURL string, checkedAt int64) (err error) {
+	defer observe(ctx, "DomainRepository.SetFavicon", time.Now(), &err)
+	return r.next.SetFavicon(ctx, domain, faviconURL, checkedAt)
+}
+
+func (r *DomainRepository) GetByDomain(ctx context.Context, domain string) (_ *models.Domain, err error) {
+	defer observe(ctx, "DomainRepository.GetByDomain", time.Now(), &err)
+	return r.next.GetByDomain(ctx, domain)
+}
+
+// CommentArchiveRepository records the calls of a repository.CommentArchiveRepository
+type CommentArchiveRepository struct {
+	next repository.CommentArchiveRepository
+}
+
+// NewCommentArchiveRepository wraps next, or returns it as is when the metrics are disabled
+func NewCommentArchiveRepository(next repository.CommentArchiveRepository) repository.CommentArchiveRepository {
+	if !Enabled() {
+		return next
+	}
+	return &CommentArchiveRepository{next: next}
+}
+
+func (r *CommentArchiveRepository) Archive(ctx context.Context, createdBefore int64, limit int) (_ int, err error) {
+	defer observe(ctx, "CommentArchiveRepository.Archive", time.Now(), &err)
+	return r.next.Archive(ctx, createdBefore, limit)
+}
+
+// PartitionRepository records the calls of a repository.PartitionRepository
+type PartitionRepository struct {
+	next repository.PartitionRepository
+}
+
+// NewPartitionRepository wraps next, or returns it as is when the metrics are disabled
+func NewPartitionRepository(next repository.PartitionRepository) repository.PartitionRepository {
+	if !Enabled() {
+		return next
+	}
+	return &PartitionRepository{next: next}
+}
+
+func (r *PartitionRepository) EnsurePartitions(ctx context.Context, table string, from int64, to int64) (_ int, err error) {
+	defer observe(ctx, "PartitionRepository.EnsurePartitions", time.Now(), &err)
+	return r.next.EnsurePartitions(ctx, table, from, to)
+}
+
+// MaintenanceRepository records the calls of a repository.MaintenanceRepository
+type MaintenanceRepository struct {
+	next repository.MaintenanceRepository
+}
+
+// NewMaintenanceRepository wraps next, or returns it as is when the metrics are disabled
+func NewMaintenanceRepository(next repository.MaintenanceRepository) repository.MaintenanceRepository {
+	if !Enabled() {
+		return next
+	}
+	return &MaintenanceRepository{next: next}
+}
+
+func (r *MaintenanceRepository) GetTableStats(ctx context.Context, tables []string) (_ []*models.TableStats, err error) {
+	defer observe(ctx, "MaintenanceRepository.GetTableStats", time.Now(), &err)
+	return r.next.GetTableStats(ctx, tables)
+}
+
+func (r *MaintenanceRepository) Analyze(ctx context.Context, table string) (err error) {
+	defer observe(ctx, "MaintenanceRepository.Analyze", time.Now(), &err)
+	return r.next.Analyze(ctx, table)
+}
+
+func (r *MaintenanceRepository) Vacuum(ctx context.Context, table string) (err error) {
+	defer observe(ctx, "MaintenanceRepository.Vacuum", time.Now(), &err)
+	return r.next.Vacuum(ctx, table)
+}
+
+// SnapshotRepository records the calls of a repository.SnapshotRepository
+type SnapshotRepository struct {
+	next repository.SnapshotRepository
+}
+
+// NewSnapshotRepository wraps next, or returns it as is when the metrics are disabled
+func NewSnapshotRepository(next repository.SnapshotRepository) repository.SnapshotRepository {
+	if !Enabled() {
+		return next
+	}
+	return &SnapshotRepository{next: next}
+}
+
+func (r *SnapshotRepository) GetSnapshotItems(ctx context.Context, kind string, tenant string, start int64, end int64, maxSpamScore float64, afterCreatedAt int64, afterID int, limit int) (_ []*models.SnapshotItem, err error) {
+	defer observe(ctx, "SnapshotRepository.GetSnapshotItems", time.Now(), &err)
+	return r.next.GetSnapshotItems(ctx, kind, tenant, start, end, maxSpamScore, afterCreatedAt, afterID, limit)
+}
+
+// StorageRepository records the calls of a repository.StorageRepository
+type StorageRepository struct {
+	next repository.StorageRepository
+}
+
+// NewStorageRepository wraps next, or returns it as is when the metrics are disabled
+func NewStorageRepository(next repository.StorageRepository) repository.StorageRepository {
+	if !Enabled() {
+		return next
+	}
+	return &StorageRepository{next: next}
+}
+
+func (r *StorageRepository) GetDatabaseSize(ctx context.Context) (_ int64, err error) {
+	defer observe(ctx, "StorageRepository.GetDatabaseSize", time.Now(), &err)
+	return r.next.GetDatabaseSize(ctx)
+}
+
+func (r *StorageRepository) SaveSnapshots(ctx context.Context, snapshots []*models.StorageSnapshot) (err error) {
+	defer observe(ctx, "StorageRepository.SaveSnapshots", time.Now(), &err)
+	return r.next.SaveSnapshots(ctx, snapshots)
+}
+
+func (r *StorageRepository) GetSnapshots(ctx context.Context, since int64) (_ []*models.StorageSnapshot, err error) {
+	defer observe(ctx, "StorageRepository.GetSnapshots", time.Now(), &err)
+	return r.next.GetSnapshots(ctx, since)
+}
+
+func (r *StorageRepository) PruneSnapshots(ctx context.Context, before int64) (_ int64, err error) {
+	defer observe(ctx, "StorageRepository.PruneSnapshots", time.Now(), &err)
+	return r.next.PruneSnapshots(ctx, before)
+}
+
+// JobRunRepository records the calls of a repository.JobRunRepository
+type JobRunRepository struct {
+	next repository.JobRunRepository
+}
+
+// NewJobRunRepository wraps next, or returns it as is when the metrics are disabled
+func NewJobRunRepository(next repository.JobRunRepository) repository.JobRunRepository {
+	if !Enabled() {
+		return next
+	}
+	return &JobRunRepository{next: next}
+}
+
+func (r *JobRunRepository) Record(ctx context.Context, name string, startedAt time.Time, duration time.Duration) (err error) {
+	defer observe(ctx, "JobRunRepository.Record", time.Now(), &err)
+	return r.next.Record(ctx, name, startedAt, duration)
+}
+
+func (r *JobRunRepository) GetAll(ctx context.Context) (_ []*models.JobRun, err error) {
+	defer observe(ctx, "JobRunRepository.GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx)
+}
+
+// TombstoneRepository records the calls of a repository.TombstoneRepository
+type TombstoneRepository struct {
+	next repository.TombstoneRepository
+}
+
+// NewTombstoneRepository wraps next, or returns it as is when the metrics are disabled
+func NewTombstoneRepository(next repository.TombstoneRepository) repository.TombstoneRepository {
+	if !Enabled() {
+		return next
+	}
+	return &TombstoneRepository{next: next}
+}
+
+func (r *TombstoneRepository) Remove(ctx context.Context, ids []int, reason string) (_ []*models.Tombstone, err error) {
+	defer observe(ctx, "TombstoneRepository.Remove", time.Now(), &err)
+	return r.next.Remove(ctx, ids, reason)
+}
+
+func (r *TombstoneRepository) RemoveExpired(ctx context.Context, tenant string, createdBefore int64, limit int) (_ []*models.Tombstone, err error) {
+	defer observe(ctx, "TombstoneRepository.RemoveExpired", time.Now(), &err)
+	return r.next.RemoveExpired(ctx, tenant, createdBefore, limit)
+}
+
+func (r *TombstoneRepository) GetPending(ctx context.Context, limit int) (_ []*models.Tombstone, err error) {
+	defer observe(ctx, "TombstoneRepository.GetPending", time.Now(), &err)
+	return r.next.GetPending(ctx, limit)
+}
+
+func (r *TombstoneRepository) MarkEmitted(ctx context.Context, ids []int) (err error) {
+	defer observe(ctx, "TombstoneRepository.MarkEmitted", time.Now(), &err)
+	return r.next.MarkEmitted(ctx, ids)
+}
+
+func (r *TombstoneRepository) PruneEmitted(ctx context.Context, before int64) (_ int64, err error) {
+	defer observe(ctx, "TombstoneRepository.PruneEmitted", time.Now(), &err)
+	return r.next.PruneEmitted(ctx, before)
+}
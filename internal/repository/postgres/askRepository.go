@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 
 	models "internship-project/internal/models"
@@ -19,9 +20,9 @@ type AskRepository struct {
 
 // NewAskRepository creates a new AskRepository instance
 func NewAskRepository() repository.AskRepository {
-	return &AskRepository{
+	return instrumented.NewAskRepository(&AskRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // Create inserts a new ask
@@ -45,10 +46,10 @@ func (r *AskRepository) GetByID(ctx context.Context, id int) (*models.Ask, error
 	var replyIds pq.Int64Array
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, title, text, score, author, reply_ids, replies_count, created_at 
+		`SELECT id, type, title, text, score, author, reply_ids, replies_count, created_at, extra 
 		 FROM asks WHERE id = $1`, id).Scan(
 		&ask.ID, &ask.Type, &ask.Title, &ask.Text, &ask.Score,
-		&ask.Author, &replyIds, &ask.Replies_count, &ask.Created_At)
+		&ask.Author, &replyIds, &ask.Replies_count, &ask.Created_At, &ask.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +142,25 @@ func (r *AskRepository) GetByDateRange(ctx context.Context, start, end int64) ([
 	return scanAsks(rows)
 }
 
+// GetByFilter retrieves asks matching all predicates of the filter
+func (r *AskRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) ([]*models.Ask, error) {
+	query, args := listQuery(filter, askFilterColumns)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAsks(rows)
+}
+
+// Count returns the number of asks matching the filter, ignoring its limit and offset
+func (r *AskRepository) Count(ctx context.Context, filter repository.ItemFilter) (int, error) {
+	query, args := countQuery(filter, askFilterColumns)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
 // UpdateScore updates ask score
 func (r *AskRepository) UpdateScore(ctx context.Context, id int, score int) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE asks SET score = $1 WHERE id = $2`, score, id)
@@ -186,19 +206,25 @@ func (r *AskRepository) CreateBatch(ctx context.Context, asks []*models.Ask) err
 
 // CreateBatchWithExistingIDs creates multiple asks with existing IDs
 func (r *AskRepository) CreateBatchWithExistingIDs(ctx context.Context, asks []*models.Ask) error {
+	_, err := r.UpsertBatch(ctx, asks)
+	return err
+}
+
+// UpsertBatch upserts multiple asks and reports how many were new, changed or already up to date
+func (r *AskRepository) UpsertBatch(ctx context.Context, asks []*models.Ask) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO asks (id, type, title, text, score, author, reply_ids, replies_count, created_at) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id) DO UPDATE SET
-		 type = EXCLUDED.type, title = EXCLUDED.title, text = EXCLUDED.text, score = EXCLUDED.score, author = EXCLUDED.author,
-		 reply_ids = EXCLUDED.reply_ids, replies_count = EXCLUDED.replies_count, created_at = EXCLUDED.created_at`)
+		`INSERT INTO asks (id, type, title, text, score, author, reply_ids, replies_count, created_at, extra) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (id)`+
+			upsertSet("asks", "type", "title", "text", "score", "author", "reply_ids", "replies_count", "created_at", "extra"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 
@@ -207,13 +233,16 @@ func (r *AskRepository) CreateBatchWithExistingIDs(ctx context.Context, asks []*
 		for i, v := range ask.Reply_ids {
 			replyIds[i] = int64(v)
 		}
-		_, err := stmt.ExecContext(ctx, ask.ID, ask.Type, ask.Title, ask.Text,
-			ask.Score, ask.Author, replyIds, ask.Replies_count, ask.Created_At)
+		err := execUpsert(ctx, stmt, &counts, ask.ID, ask.Type, ask.Title, ask.Text,
+			ask.Score, ask.Author, replyIds, ask.Replies_count, ask.Created_At, ask.Extra)
 		if err != nil {
-			return err
+			return repository.UpsertCounts{}, err
 		}
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
 }
 
 // DeleteByAuthor deletes all asks by author
@@ -237,6 +266,28 @@ func (r *AskRepository) GetCount(ctx context.Context) (int, error) {
 	return count, err
 }
 
+// UpdateSpamScores sets the spam score of multiple asks
+func (r *AskRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE asks SET spam_score = $1 WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, score := range scores {
+		if _, err := stmt.ExecContext(ctx, score, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // Helper function to scan asks
 func scanAsks(rows *sql.Rows) ([]*models.Ask, error) {
 	var asks []*models.Ask
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// ChangeRepository implements repository.ChangeRepository
+type ChangeRepository struct {
+	db *sql.DB
+}
+
+// NewChangeRepository creates a new ChangeRepository instance
+func NewChangeRepository() repository.ChangeRepository {
+	return instrumented.NewChangeRepository(&ChangeRepository{
+		db: database.GetDB(),
+	})
+}
+
+// GetUpdatedSince returns up to limit changes of a kind that come after since in
+// (updated_at, id) order, oldest first, so the last one is the cursor of the next call
+func (r *ChangeRepository) GetUpdatedSince(ctx context.Context, kind string, since models.ItemChange, limit int) ([]models.ItemChange, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, updated_at FROM `+table+` WHERE (updated_at, id) > ($1, $2)
+		 ORDER BY updated_at, id LIMIT $3`, since.Updated_At, since.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.ItemChange
+	for rows.Next() {
+		change := models.ItemChange{Kind: kind}
+		if err := rows.Scan(&change.ID, &change.Updated_At); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// SequenceChanges numbers up to limit committed changes of the change log after the last number
+// handed out, oldest first, and returns how many it numbered. Runs hold the sequencer row until
+// they commit, so they never interleave; it returns 0 while another run holds it.
+func (r *ChangeRepository) SequenceChanges(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var last int64
+	err = tx.QueryRowContext(ctx, `SELECT last_seq FROM item_change_sequencer FOR UPDATE SKIP LOCKED`).Scan(&last)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`WITH pending AS (
+		     SELECT id, row_number() OVER (ORDER BY id) AS n
+		     FROM item_change_log WHERE seq IS NULL ORDER BY id LIMIT $2)
+		 UPDATE item_change_log c SET seq = $1 + pending.n FROM pending WHERE c.id = pending.id`, last, limit)
+	if err != nil {
+		return 0, err
+	}
+	numbered, err := result.RowsAffected()
+	if err != nil || numbered == 0 {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE item_change_sequencer SET last_seq = $1`, last+numbered); err != nil {
+		return 0, err
+	}
+	return int(numbered), tx.Commit()
+}
+
+// GetChangesSince returns up to limit numbered changes of the tenant's items after since, in
+// sequence order
+func (r *ChangeRepository) GetChangesSince(ctx context.Context, tenant string, since int64, limit int) ([]*models.SequencedChange, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT seq, kind, item_id, operation, changed_at FROM item_change_log
+		 WHERE seq > $1 AND tenant = $2 ORDER BY seq LIMIT $3`, since, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*models.SequencedChange{}
+	for rows.Next() {
+		change := &models.SequencedChange{}
+		if err := rows.Scan(&change.Seq, &change.Kind, &change.ID, &change.Operation, &change.Updated_At); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// GetChangeLogBounds returns the oldest number still in the change log and the last one handed
+// out; first is last+1 when every numbered change was pruned
+func (r *ChangeRepository) GetChangeLogBounds(ctx context.Context) (first, last int64, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT MIN(seq) FROM item_change_log), s.last_seq + 1), s.last_seq
+		 FROM item_change_sequencer s`).Scan(&first, &last)
+	return first, last, err
+}
+
+// PruneChanges deletes the numbered changes made before the given time (unix milliseconds)
+func (r *ChangeRepository) PruneChanges(ctx context.Context, before int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM item_change_log WHERE changed_at < $1 AND seq IS NOT NULL`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetItemHistory returns the changes of an item made after the given time (unix milliseconds),
+// newest first
+func (r *ChangeRepository) GetItemHistory(ctx context.Context, kind string, id int, after int64) ([]*models.ItemRevision, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT operation, changed_at, previous FROM item_change_log
+		 WHERE kind = $1 AND item_id = $2 AND changed_at > $3 ORDER BY id DESC`, kind, id, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*models.ItemRevision
+	for rows.Next() {
+		revision := &models.ItemRevision{}
+		var previous []byte
+		if err := rows.Scan(&revision.Operation, &revision.Changed_At, &previous); err != nil {
+			return nil, err
+		}
+		// An update that replaced nothing keeps an empty, non-nil Previous; nil means not logged
+		if previous != nil {
+			if err := json.Unmarshal(previous, &revision.Previous); err != nil {
+				return nil, err
+			}
+			if revision.Previous == nil {
+				revision.Previous = map[string]json.RawMessage{}
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// CommentArchiveRepository implements repository.CommentArchiveRepository
+type CommentArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewCommentArchiveRepository creates a new CommentArchiveRepository instance
+func NewCommentArchiveRepository() repository.CommentArchiveRepository {
+	return instrumented.NewCommentArchiveRepository(&CommentArchiveRepository{
+		db: database.GetDB(),
+	})
+}
+
+// Archive moves the batch in one transaction, after creating the partitions of the months from
+// its oldest comment to its newest
+func (r *CommentArchiveRepository) Archive(ctx context.Context, createdBefore int64, limit int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var oldest, newest sql.NullInt64
+	err = tx.QueryRowContext(ctx,
+		`SELECT MIN(created_at), MAX(created_at) FROM (
+		     SELECT created_at FROM comments WHERE created_at < $1 ORDER BY created_at LIMIT $2) batch`,
+		createdBefore, limit).Scan(&oldest, &newest)
+	if err != nil || !oldest.Valid {
+		return 0, err
+	}
+
+	if err := ensureMonthPartitions(ctx, tx, "comments_cold", oldest.Int64, newest.Int64); err != nil {
+		return 0, err
+	}
+
+	// Moved rows keep their depth, stored as a plain column in the cold table
+	result, err := tx.ExecContext(ctx,
+		`WITH moved AS (
+		     DELETE FROM comments WHERE id IN (
+		         SELECT id FROM comments WHERE created_at < $1 ORDER BY created_at LIMIT $2)
+		     RETURNING *)
+		 INSERT INTO comments_cold SELECT * FROM moved`, createdBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(moved), tx.Commit()
+}
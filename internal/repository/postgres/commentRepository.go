@@ -3,8 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 
 	models "internship-project/internal/models"
@@ -19,11 +22,14 @@ type CommentRepository struct {
 
 // NewCommentRepository creates a new CommentRepository instance
 func NewCommentRepository() repository.CommentRepository {
-	return &CommentRepository{
+	return instrumented.NewCommentRepository(&CommentRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
+// commentColumns are the columns written by comment upserts, in the order of their arguments
+var commentColumns = []string{"type", "text", "author", "created_at", "parent_id", "reply_ids", "source", "extra"}
+
 // Create inserts a new comment
 func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) error {
 	replyIds := make(pq.Int64Array, len(comment.Replies))
@@ -31,62 +37,108 @@ func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment)
 		replyIds[i] = int64(v)
 	}
 
+	if err := ensureMonthPartitions(ctx, r.db, "comments", comment.Created_At); err != nil {
+		return err
+	}
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO comments (id, type, text, author, created_at, parent_id, reply_ids) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		`INSERT INTO comments (id, type, text, author, created_at, parent_id, reply_ids, source) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
 		comment.ID, comment.Type, comment.Text,
-		comment.Author, comment.Created_At, comment.Parent, replyIds)
+		comment.Author, comment.Created_At, comment.Parent, replyIds, sourceOrDefault(comment.Source))
 	return err
 }
 
-// CreateBatch inserts multiple comments
+// CreateBatchWithExistingIDs upserts multiple comments
 func (r *CommentRepository) CreateBatchWithExistingIDs(ctx context.Context, comments []*models.Comment) error {
+	_, err := r.UpsertBatch(ctx, comments)
+	return err
+}
+
+// UpsertBatch upserts multiple comments and reports how many were new, changed or already up to date.
+// Archived comments are updated in place in the cold table.
+func (r *CommentRepository) UpsertBatch(ctx context.Context, comments []*models.Comment) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	if len(comments) == 0 {
-		return nil
+		return counts, nil
 	}
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 
+	ids := make(pq.Int64Array, len(comments))
+	createdAt := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = int64(comment.ID)
+		createdAt[i] = comment.Created_At
+	}
+	if err := ensureMonthPartitions(ctx, tx, "comments", createdAt...); err != nil {
+		return counts, err
+	}
+	archived, err := queryIDs(ctx, tx, `SELECT id FROM comments_cold WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return counts, err
+	}
+	cold := make(map[int]bool, len(archived))
+	for _, id := range archived {
+		cold[id] = true
+	}
+
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO comments (id, type, text, author, created_at, parent_id, reply_ids) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO UPDATE SET
-		 type = EXCLUDED.type, text = EXCLUDED.text, author = EXCLUDED.author, created_at = EXCLUDED.created_at, 
-		 parent_id = EXCLUDED.parent_id, reply_ids = EXCLUDED.reply_ids`)
+		`INSERT INTO comments (id, type, text, author, created_at, parent_id, reply_ids, source, extra) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id, created_at)`+
+			upsertSet("comments", commentColumns...))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 
+	// An unchanged archived comment matches no row: it is counted skipped like a guarded upsert
+	coldStmt, err := tx.PrepareContext(ctx,
+		`UPDATE comments_cold SET (`+strings.Join(commentColumns, ", ")+`) = ($2, $3, $4, $5, $6, $7, $8, $9::jsonb)
+		 WHERE id = $1 AND (`+strings.Join(commentColumns, ", ")+`) IS DISTINCT FROM ($2, $3, $4, $5, $6, $7, $8, $9::jsonb)
+		 RETURNING false`)
+	if err != nil {
+		return counts, err
+	}
+	defer coldStmt.Close()
+
 	for _, comment := range comments {
 		replyIds := make(pq.Int64Array, len(comment.Replies))
 		for i, v := range comment.Replies {
 			replyIds[i] = int64(v)
 		}
 
-		if _, err := stmt.ExecContext(ctx,
+		target := stmt
+		if cold[comment.ID] {
+			target = coldStmt
+		}
+		if err := execUpsert(ctx, target, &counts,
 			comment.ID, comment.Type, comment.Text,
-			comment.Author, comment.Created_At, comment.Parent, replyIds); err != nil {
-			return err
+			comment.Author, comment.Created_At, comment.Parent, replyIds, sourceOrDefault(comment.Source), comment.Extra); err != nil {
+			return repository.UpsertCounts{}, err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
 }
 
-// GetByID retrieves a comment by ID
+// GetByID retrieves a comment by ID, hot or archived
 func (r *CommentRepository) GetByID(ctx context.Context, id int) (*models.Comment, error) {
 	comment := &models.Comment{}
 	var replyIds pq.Int64Array
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, text, author, created_at, parent_id, reply_ids 
-		 FROM comments WHERE id = $1`, id).Scan(
+		`SELECT id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0), extra 
+		 FROM comments_all WHERE id = $1`, id).Scan(
 		&comment.ID, &comment.Type, &comment.Text,
-		&comment.Author, &comment.Created_At, &comment.Parent, &replyIds)
+		&comment.Author, &comment.Created_At, &comment.Parent, &replyIds, &comment.Source, &comment.Depth, &comment.Rank,
+		&comment.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +150,7 @@ func (r *CommentRepository) GetByID(ctx context.Context, id int) (*models.Commen
 	return comment, nil
 }
 
-// Update updates an existing comment
+// Update updates an existing comment, hot or archived
 func (r *CommentRepository) Update(ctx context.Context, comment *models.Comment) error {
 	replyIds := make(pq.Int64Array, len(comment.Replies))
 	for i, v := range comment.Replies {
@@ -106,24 +158,28 @@ func (r *CommentRepository) Update(ctx context.Context, comment *models.Comment)
 	}
 
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE comments SET  type=$2, text=$3, author=$4, 
+		`WITH cold AS (
+		     UPDATE comments_cold SET type=$2, text=$3, author=$4,
+		     created_at=$5, parent_id=$6, reply_ids=$7 WHERE id=$1)
+		 UPDATE comments SET  type=$2, text=$3, author=$4, 
 		 created_at=$5, parent_id=$6, reply_ids=$7 WHERE id=$1`,
 		comment.ID, comment.Type, comment.Text,
 		comment.Author, comment.Created_At, comment.Parent, replyIds)
 	return err
 }
 
-// Delete removes a comment by ID
+// Delete removes a comment by ID, hot or archived
 func (r *CommentRepository) Delete(ctx context.Context, id int) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE id = $1`, id)
+	_, err := r.db.ExecContext(ctx,
+		`WITH cold AS (DELETE FROM comments_cold WHERE id = $1) DELETE FROM comments WHERE id = $1`, id)
 	return err
 }
 
 // GetAll retrieves all comments
 func (r *CommentRepository) GetAll(ctx context.Context) ([]*models.Comment, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, text, author, created_at, parent_id, reply_ids 
-		 FROM comments ORDER BY created_at DESC`)
+		`SELECT id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0) 
+		 FROM comments_all ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -131,10 +187,10 @@ func (r *CommentRepository) GetAll(ctx context.Context) ([]*models.Comment, erro
 	return scanComments(rows)
 }
 
-// GetRecent retrieves recent comments
+// GetRecent retrieves recent comments; they are never archived
 func (r *CommentRepository) GetRecent(ctx context.Context, limit int) ([]*models.Comment, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, text, author, created_at, parent_id, reply_ids 
+		`SELECT id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0) 
 		 FROM comments ORDER BY created_at DESC LIMIT $1`, limit)
 	if err != nil {
 		return nil, err
@@ -146,8 +202,8 @@ func (r *CommentRepository) GetRecent(ctx context.Context, limit int) ([]*models
 // GetByAuthor retrieves comments by author
 func (r *CommentRepository) GetByAuthor(ctx context.Context, author string) ([]*models.Comment, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, text, author, created_at, parent_id, reply_ids 
-		 FROM comments WHERE author = $1 ORDER BY created_at DESC`, author)
+		`SELECT id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0) 
+		 FROM comments_all WHERE author = $1 ORDER BY created_at DESC`, author)
 	if err != nil {
 		return nil, err
 	}
@@ -158,8 +214,8 @@ func (r *CommentRepository) GetByAuthor(ctx context.Context, author string) ([]*
 // GetByDateRange retrieves comments within date range
 func (r *CommentRepository) GetByDateRange(ctx context.Context, start, end int64) ([]*models.Comment, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, text, author, created_at, parent_id, reply_ids 
-		 FROM comments WHERE created_at BETWEEN $1 AND $2 ORDER BY created_at DESC`, start, end)
+		`SELECT id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0) 
+		 FROM comments_all WHERE created_at BETWEEN $1 AND $2 ORDER BY created_at DESC`, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -167,9 +223,140 @@ func (r *CommentRepository) GetByDateRange(ctx context.Context, start, end int64
 	return scanComments(rows)
 }
 
-// DeleteByAuthor deletes all comments by author
+// GetByFilter retrieves comments matching all predicates of the filter
+func (r *CommentRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) ([]*models.Comment, error) {
+	query, args := listQuery(filter, commentFilterColumns)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanComments(rows)
+}
+
+// Count returns the number of comments matching the filter, ignoring its limit and offset
+func (r *CommentRepository) Count(ctx context.Context, filter repository.ItemFilter) (int, error) {
+	query, args := countQuery(filter, commentFilterColumns)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// GetThread retrieves the comments below an item (a story or another comment), down to maxDepth
+// levels, with one lookup of the materialized ancestor paths. They come level by level, siblings
+// in the order of their parent's kids; models.OrderThread turns them into a rendering order.
+// Each comment carries its quality score and rank when the ranking job reached its thread.
+func (r *CommentRepository) GetThread(ctx context.Context, rootID, maxDepth int) ([]*models.Comment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.id, c.type, c.text, c.author, c.created_at, c.parent_id, c.reply_ids, c.source,
+		        COALESCE(c.depth, 0), COALESCE(c.sibling_rank, 0), COALESCE(q.score, 0), COALESCE(q.sibling_rank, 0)
+		 FROM comments_all c
+		 LEFT JOIN comment_quality q ON q.comment_id = c.id
+		 WHERE c.ancestor_ids @> ARRAY[$1::BIGINT]
+		   AND cardinality(c.ancestor_ids) - array_position(c.ancestor_ids, $1::BIGINT) < $2
+		 ORDER BY c.depth, c.parent_id, c.sibling_rank NULLS LAST, c.created_at`, rootID, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*models.Comment
+	for rows.Next() {
+		comment := &models.Comment{}
+		var replyIds pq.Int64Array
+		err := rows.Scan(&comment.ID, &comment.Type, &comment.Text, &comment.Author, &comment.Created_At,
+			&comment.Parent, &replyIds, &comment.Source, &comment.Depth, &comment.Rank, &comment.Quality, &comment.Quality_Rank)
+		if err != nil {
+			return nil, err
+		}
+		comment.Replies = make([]int, len(replyIds))
+		for i, v := range replyIds {
+			comment.Replies[i] = int(v)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// GetQualitySignals returns the ranking signals of the hot comments sharing a parent with a comment
+// created at or after createdSince (unix seconds), so whole sibling groups are ranked together
+func (r *CommentRepository) GetQualitySignals(ctx context.Context, createdSince int64) ([]*models.CommentSignals, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT c.id, c.parent_id, COALESCE(cardinality(c.reply_ids), 0), COALESCE(c.depth, 0),
+		        COALESCE(length(c.text), 0), COALESCE(u.karma, 0)
+		 FROM comments c
+		 LEFT JOIN users u ON u.username = c.author
+		 WHERE c.parent_id IN (SELECT parent_id FROM comments WHERE created_at >= $1)`, createdSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []*models.CommentSignals
+	for rows.Next() {
+		s := &models.CommentSignals{}
+		if err := rows.Scan(&s.ID, &s.Parent, &s.Replies, &s.Depth, &s.Length, &s.Karma); err != nil {
+			return nil, err
+		}
+		signals = append(signals, s)
+	}
+	return signals, rows.Err()
+}
+
+// UpdateQuality stores the quality scores and sibling ranks of comments
+func (r *CommentRepository) UpdateQuality(ctx context.Context, qualities []*models.CommentQuality) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO comment_quality (comment_id, score, sibling_rank, ranked_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (comment_id) DO UPDATE SET score = EXCLUDED.score, sibling_rank = EXCLUDED.sibling_rank,
+		     ranked_at = EXCLUDED.ranked_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, q := range qualities {
+		if _, err := stmt.ExecContext(ctx, q.ID, q.Score, q.Rank, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetTextHashes returns the MD5 hex digest of the text of the comments created at or after
+// createdSince (unix seconds), newest first, keyed by comment ID
+func (r *CommentRepository) GetTextHashes(ctx context.Context, createdSince int64, limit int) (map[int]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, md5(text) FROM comments
+		 WHERE created_at >= $1 AND source = $2 ORDER BY created_at DESC LIMIT $3`,
+		createdSince, models.SourceHackerNews, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, err
+		}
+		hashes[id] = hash
+	}
+	return hashes, rows.Err()
+}
+
+// DeleteByAuthor deletes all comments by author, hot and archived
 func (r *CommentRepository) DeleteByAuthor(ctx context.Context, author string) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE author = $1`, author)
+	_, err := r.db.ExecContext(ctx,
+		`WITH cold AS (DELETE FROM comments_cold WHERE author = $1) DELETE FROM comments WHERE author = $1`, author)
 	return err
 }
 
@@ -177,17 +364,41 @@ func (r *CommentRepository) DeleteByAuthor(ctx context.Context, author string) e
 func (r *CommentRepository) Exists(ctx context.Context, id int) (bool, error) {
 	var exists bool
 	err := r.db.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM comments WHERE id = $1)`, id).Scan(&exists)
+		`SELECT EXISTS(SELECT 1 FROM comments_all WHERE id = $1)`, id).Scan(&exists)
 	return exists, err
 }
 
-// GetCount returns total count of comments
+// GetCount returns total count of comments, hot and archived
 func (r *CommentRepository) GetCount(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments`).Scan(&count)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments_all`).Scan(&count)
 	return count, err
 }
 
+// UpdateSpamScores sets the spam score of multiple comments
+func (r *CommentRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`WITH cold AS (UPDATE comments_cold SET spam_score = $1 WHERE id = $2)
+		 UPDATE comments SET spam_score = $1 WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, score := range scores {
+		if _, err := stmt.ExecContext(ctx, score, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // Helper function to scan comments
 func scanComments(rows *sql.Rows) ([]*models.Comment, error) {
 	var comments []*models.Comment
@@ -196,7 +407,7 @@ func scanComments(rows *sql.Rows) ([]*models.Comment, error) {
 		var replyIds pq.Int64Array
 
 		err := rows.Scan(&comment.ID, &comment.Type, &comment.Text,
-			&comment.Author, &comment.Created_At, &comment.Parent, &replyIds)
+			&comment.Author, &comment.Created_At, &comment.Parent, &replyIds, &comment.Source, &comment.Depth, &comment.Rank)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// ComputedFieldRepository implements repository.ComputedFieldRepository
+type ComputedFieldRepository struct {
+	db *sql.DB
+}
+
+// NewComputedFieldRepository creates a new ComputedFieldRepository instance
+func NewComputedFieldRepository() repository.ComputedFieldRepository {
+	return instrumented.NewComputedFieldRepository(&ComputedFieldRepository{
+		db: database.GetDB(),
+	})
+}
+
+// ReplaceFields sets the computed fields of an item in one transaction
+func (r *ComputedFieldRepository) ReplaceFields(ctx context.Context, kind string, id int, values map[string]float64) error {
+	if _, ok := kindTables[kind]; !ok {
+		return ErrUnknownKind
+	}
+	names := make([]string, 0, len(values))
+	numbers := make([]float64, 0, len(values))
+	for name, value := range values {
+		names = append(names, name)
+		numbers = append(numbers, value)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM computed_fields WHERE kind = $1 AND item_id = $2 AND NOT (name = ANY($3))`,
+		kind, id, pq.Array(names)); err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO computed_fields (kind, item_id, name, value)
+			 SELECT $1, $2, n, v FROM unnest($3::text[], $4::double precision[]) AS f(n, v)
+			 ON CONFLICT (kind, item_id, name) DO UPDATE SET value = EXCLUDED.value`,
+			kind, id, pq.Array(names), pq.Array(numbers)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFields returns the computed fields of an item; it is empty for an item without any
+func (r *ComputedFieldRepository) GetFields(ctx context.Context, kind string, id int) (map[string]float64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, value FROM computed_fields WHERE kind = $1 AND item_id = $2`, kind, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]float64)
+	for rows.Next() {
+		var name string
+		var value float64
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, rows.Err()
+}
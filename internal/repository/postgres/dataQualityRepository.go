@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// ErrUnknownKind is returned for an item kind that has no table
+var ErrUnknownKind = errors.New("unknown item kind")
+
+// DataQualityRepository implements repository.DataQualityRepository
+type DataQualityRepository struct {
+	db *sql.DB
+}
+
+// NewDataQualityRepository creates a new DataQualityRepository instance
+func NewDataQualityRepository() repository.DataQualityRepository {
+	return instrumented.NewDataQualityRepository(&DataQualityRepository{
+		db: database.GetDB(),
+	})
+}
+
+// anomalyQueries select the IDs of the rows each check flags
+var anomalyQueries = []struct {
+	name   string
+	detail string
+	query  string
+}{
+	{
+		name:   "stories_empty_author",
+		detail: "stories with an empty author",
+		query:  `SELECT id FROM stories WHERE TRIM(author) = ''`,
+	},
+	{
+		name:   "orphan_comments",
+		detail: "comments with parent 0 that no story or ask lists",
+		query: `SELECT c.id FROM comments c WHERE COALESCE(c.parent_id, 0) = 0
+			AND NOT EXISTS (SELECT 1 FROM stories s WHERE c.id = ANY(s.comments_ids))
+			AND NOT EXISTS (SELECT 1 FROM asks a WHERE c.id = ANY(a.reply_ids))`,
+	},
+	{
+		name:   "negative_timestamps",
+		detail: "items created before 1970",
+		query: `SELECT id FROM stories WHERE created_at < 0
+			UNION ALL SELECT id FROM asks WHERE created_at < 0
+			UNION ALL SELECT id FROM jobs WHERE created_at < 0
+			UNION ALL SELECT id FROM comments WHERE created_at < 0
+			UNION ALL SELECT id FROM polls WHERE created_at < 0`,
+	},
+}
+
+// kindTables maps the item kinds to their tables
+var kindTables = map[string]string{
+	"story":   "stories",
+	"ask":     "asks",
+	"job":     "jobs",
+	"comment": "comments",
+	"poll":    "polls",
+	"pollopt": "poll_options",
+}
+
+// RunChecks runs the anomaly checks, keeping up to sampleSize offending IDs per check
+func (r *DataQualityRepository) RunChecks(ctx context.Context, sampleSize int) ([]models.DataQualityCheck, error) {
+	checks := make([]models.DataQualityCheck, 0, len(anomalyQueries))
+	for _, q := range anomalyQueries {
+		check := models.DataQualityCheck{Name: q.name, Detail: q.detail}
+
+		// COUNT(*) OVER () is computed before LIMIT, so the first row carries the full count
+		rows, err := r.db.QueryContext(ctx,
+			`SELECT COUNT(*) OVER (), id FROM (`+q.query+`) anomalies ORDER BY id LIMIT $1`, sampleSize)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&check.Count, &id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			check.SampleIDs = append(check.SampleIDs, id)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// CountItems returns the number of stored items of a kind ("story", "ask", ...)
+func (r *DataQualityRepository) CountItems(ctx context.Context, kind string) (int64, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return 0, ErrUnknownKind
+	}
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&count)
+	return count, err
+}
+
+// documentFields builds, per kind, the JSON object of the columns mirrored in the search index,
+// keyed by their document field names
+var documentFields = map[string]string{
+	"story": `json_build_object('title', title, 'url', COALESCE(url, ''), 'score', score,
+		'by', author, 'time', created_at, 'descendants', comments_count, 'new_author', new_author)`,
+	"ask": `json_build_object('title', title, 'text', COALESCE(text, ''), 'score', score,
+		'by', author, 'time', created_at, 'descendants', replies_count)`,
+	"job": `json_build_object('title', title, 'text', COALESCE(text, ''), 'url', COALESCE(url, ''),
+		'score', score, 'by', author, 'time', created_at)`,
+	"comment": `json_build_object('text', text, 'by', author, 'time', created_at, 'parent', COALESCE(parent_id, 0), 'new_author', new_author)`,
+	"poll":    `json_build_object('title', title, 'score', score, 'by', author, 'time', created_at)`,
+}
+
+// CountItemsInRange returns the number of stored items of a kind created between start and end
+// (unix seconds, inclusive); zero bounds are open
+func (r *DataQualityRepository) CountItemsInRange(ctx context.Context, kind string, start, end int64) (int64, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return 0, ErrUnknownKind
+	}
+	query, args := countQuery(repository.ItemFilter{Start: start, End: end}, filterColumns{table: table})
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// GetItemIDs returns up to limit IDs above afterID of the items of a kind created between
+// start and end, in ascending order, so callers can walk the whole range page by page
+func (r *DataQualityRepository) GetItemIDs(ctx context.Context, kind string, start, end int64, afterID, limit int) ([]int, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	b := buildItemFilter(repository.ItemFilter{Start: start, End: end}, filterColumns{table: table})
+	b.add("id > ?", afterID)
+	b.args = append(b.args, limit)
+	query := fmt.Sprintf(`SELECT id FROM %s%s ORDER BY id LIMIT $%d`, table, b.where(), len(b.args))
+	return queryIDs(ctx, r.db, query, b.args...)
+}
+
+// SampleItemIDs returns up to n random IDs of the items of a kind created between start and end
+func (r *DataQualityRepository) SampleItemIDs(ctx context.Context, kind string, start, end int64, n int) ([]int, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	b := buildItemFilter(repository.ItemFilter{Start: start, End: end}, filterColumns{table: table})
+	b.args = append(b.args, n)
+	query := fmt.Sprintf(`SELECT id FROM %s%s ORDER BY random() LIMIT $%d`, table, b.where(), len(b.args))
+	return queryIDs(ctx, r.db, query, b.args...)
+}
+
+// GetItemDocuments returns the indexed fields of the items of a kind with the given IDs,
+// decoded like search documents so both sides can be compared
+func (r *DataQualityRepository) GetItemDocuments(ctx context.Context, kind string, ids []int) (map[int]map[string]interface{}, error) {
+	table, ok := kindTables[kind]
+	fields, indexed := documentFields[kind]
+	if !ok || !indexed {
+		return nil, ErrUnknownKind
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, `+fields+` FROM `+table+` WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make(map[int]map[string]interface{}, len(ids))
+	for rows.Next() {
+		var id int
+		var raw []byte
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode %s %d: %w", kind, id, err)
+		}
+		docs[id] = doc
+	}
+	return docs, rows.Err()
+}
+
+// queryer runs queries on the database or within a transaction
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryIDs runs a query selecting a single ID column
+func queryIDs(ctx context.Context, db queryer, query string, args ...interface{}) ([]int, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SaveReport stores a report with a new ID
+func (r *DataQualityRepository) SaveReport(ctx context.Context, report *models.DataQualityReport) error {
+	checks, err := json.Marshal(report.Checks)
+	if err != nil {
+		return err
+	}
+	report.ID = database.NewUUIDv7()
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO data_quality_reports (id, created_at, checks) VALUES ($1, $2, $3)`,
+		report.ID, report.Created_At, checks)
+	return err
+}
+
+// GetLatestReport returns the most recent report, or sql.ErrNoRows before the first run
+func (r *DataQualityRepository) GetLatestReport(ctx context.Context) (*models.DataQualityReport, error) {
+	report := &models.DataQualityReport{}
+	var checks []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, created_at, checks FROM data_quality_reports ORDER BY id DESC LIMIT 1`).Scan(
+		&report.ID, &report.Created_At, &checks)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(checks, &report.Checks); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// DeadLetterRepository implements repository.DeadLetterRepository
+type DeadLetterRepository struct {
+	db *sql.DB
+}
+
+// NewDeadLetterRepository creates a new DeadLetterRepository instance
+func NewDeadLetterRepository() repository.DeadLetterRepository {
+	return instrumented.NewDeadLetterRepository(&DeadLetterRepository{
+		db: database.GetDB(),
+	})
+}
+
+// deadLetterColumns are the columns read by deadLetterFields
+const deadLetterColumns = `id, item_id, kind, reason, errors, payload, occurrences, created_at, updated_at`
+
+// Record stores a rejected payload, or counts one more occurrence of a payload already stored for
+// the item
+func (r *DeadLetterRepository) Record(ctx context.Context, letter *models.DeadLetter) error {
+	// Sent as text: lib/pq would encode []byte as bytea
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO item_dead_letters (item_id, kind, reason, errors, payload, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $6)
+		 ON CONFLICT (item_id, md5(payload::text)) DO UPDATE
+		 SET kind = EXCLUDED.kind, reason = EXCLUDED.reason, errors = EXCLUDED.errors,
+		     occurrences = item_dead_letters.occurrences + 1, updated_at = EXCLUDED.updated_at
+		 RETURNING `+deadLetterColumns,
+		letter.Item_ID, letter.Kind, letter.Reason, pq.Array(letter.Errors), string(letter.Payload),
+		time.Now().Unix()).Scan(deadLetterFields(letter)...)
+}
+
+// List returns the dead letters last rejected most recently
+func (r *DeadLetterRepository) List(ctx context.Context, kind string, limit int) ([]*models.DeadLetter, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+deadLetterColumns+` FROM item_dead_letters
+		 WHERE $1 = '' OR kind = $1 ORDER BY updated_at DESC, id DESC LIMIT $2`,
+		kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []*models.DeadLetter{}
+	for rows.Next() {
+		letter := &models.DeadLetter{}
+		if err := rows.Scan(deadLetterFields(letter)...); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, rows.Err()
+}
+
+// GetGone returns the IDs among ids with a gone dead letter of at least minOccurrences occurrences
+func (r *DeadLetterRepository) GetGone(ctx context.Context, ids []int, minOccurrences int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT item_id FROM item_dead_letters
+		 WHERE item_id = ANY($1) AND reason = $2 AND occurrences >= $3`,
+		pq.Array(ids), models.DeadLetterGone, minOccurrences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gone []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		gone = append(gone, id)
+	}
+	return gone, rows.Err()
+}
+
+// deadLetterFields returns the scan destinations of deadLetterColumns
+func deadLetterFields(d *models.DeadLetter) []interface{} {
+	return []interface{}{&d.ID, &d.Item_ID, &d.Kind, &d.Reason, pq.Array(&d.Errors), (*[]byte)(&d.Payload),
+		&d.Occurrences, &d.Created_At, &d.Updated_At}
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// ErrUnknownQuery is returned by ExplainQuery for names not in the explainable query registry
+var ErrUnknownQuery = errors.New("unknown query")
+
+// explainableQueries maps a query name to the builder the repositories use for it,
+// so the explained SQL is exactly what the API runs
+var explainableQueries = map[string]func(repository.ItemFilter) (string, []interface{}){}
+
+func init() {
+	for _, cols := range []filterColumns{
+		storyFilterColumns, askFilterColumns, jobFilterColumns, commentFilterColumns, pollFilterColumns,
+	} {
+		explainableQueries[cols.table+".list"] = func(f repository.ItemFilter) (string, []interface{}) {
+			return listQuery(f, cols)
+		}
+		explainableQueries[cols.table+".count"] = func(f repository.ItemFilter) (string, []interface{}) {
+			return countQuery(f, cols)
+		}
+	}
+}
+
+// DiagnosticsRepository implements repository.DiagnosticsRepository
+type DiagnosticsRepository struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewDiagnosticsRepository creates a new DiagnosticsRepository instance.
+// Explained queries are cancelled by Postgres after timeout.
+func NewDiagnosticsRepository(timeout time.Duration) repository.DiagnosticsRepository {
+	return instrumented.NewDiagnosticsRepository(&DiagnosticsRepository{
+		db:      database.GetDB(),
+		timeout: timeout,
+	})
+}
+
+// ExplainableQueries lists the names accepted by ExplainQuery in alphabetical order
+func (r *DiagnosticsRepository) ExplainableQueries() []string {
+	names := make([]string, 0, len(explainableQueries))
+	for name := range explainableQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExplainQuery runs EXPLAIN (ANALYZE, BUFFERS) on the named query built from the filter.
+// ANALYZE executes the query, so it runs in a read-only transaction that is always rolled back.
+func (r *DiagnosticsRepository) ExplainQuery(ctx context.Context, name string, filter repository.ItemFilter) (*models.QueryPlan, error) {
+	build, ok := explainableQueries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownQuery, name)
+	}
+	query, args := build(filter)
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if r.timeout > 0 {
+		// SET cannot take placeholders; the value is an integer we format ourselves
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", r.timeout.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `EXPLAIN (ANALYZE, BUFFERS) `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plan := &models.QueryPlan{Query: name, SQL: query, Args: args, Plan: []string{}}
+	if plan.Args == nil {
+		plan.Args = []interface{}{}
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		plan.Plan = append(plan.Plan, line)
+	}
+	return plan, rows.Err()
+}
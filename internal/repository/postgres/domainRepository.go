@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// DomainRepository implements repository.DomainRepository
+type DomainRepository struct {
+	db *sql.DB
+}
+
+// NewDomainRepository creates a new DomainRepository instance
+func NewDomainRepository() repository.DomainRepository {
+	return instrumented.NewDomainRepository(&DomainRepository{
+		db: database.GetDB(),
+	})
+}
+
+// storyDomain extracts the lowercase host without "www." of a story URL, like the Domain filter
+const storyDomain = `LOWER(substring(url from '://(?:www\.)?([^/:?#]+)'))`
+
+// RefreshStats upserts the aggregates of every domain in one transaction, keeping the favicons
+func (r *DomainRepository) RefreshStats(ctx context.Context) error {
+	now := time.Now().Unix()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO domains (domain, first_seen, last_seen, story_count, average_score, top_score,
+			comment_count, refreshed_at)
+		 SELECT domain, MIN(created_at), MAX(created_at), COUNT(*), AVG(score), MAX(score),
+			SUM(comments_count), $1
+		 FROM (SELECT `+storyDomain+` AS domain, created_at, score, comments_count FROM stories) AS s
+		 WHERE domain IS NOT NULL
+		 GROUP BY domain
+		 ON CONFLICT (domain) DO UPDATE SET first_seen = EXCLUDED.first_seen, last_seen = EXCLUDED.last_seen,
+			story_count = EXCLUDED.story_count, average_score = EXCLUDED.average_score,
+			top_score = EXCLUDED.top_score, comment_count = EXCLUDED.comment_count,
+			refreshed_at = EXCLUDED.refreshed_at`, now)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM domains WHERE refreshed_at < $1`, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFaviconsToCheck returns the domains with the most stories first among those due
+func (r *DomainRepository) GetFaviconsToCheck(ctx context.Context, checkedBefore int64, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT domain FROM domains WHERE favicon_checked_at < $1
+		 ORDER BY favicon_checked_at, story_count DESC LIMIT $2`, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []string
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+	return domains, rows.Err()
+}
+
+// SetFavicon stores the favicon URL of a domain and when it was looked up
+func (r *DomainRepository) SetFavicon(ctx context.Context, domain, faviconURL string, checkedAt int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE domains SET favicon_url = COALESCE(NULLIF($2, ''), favicon_url), favicon_checked_at = $3
+		 WHERE domain = $1`,
+		domain, faviconURL, checkedAt)
+	return err
+}
+
+// GetByDomain returns the aggregates of a domain, given with or without "www."
+func (r *DomainRepository) GetByDomain(ctx context.Context, domain string) (*models.Domain, error) {
+	d := &models.Domain{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT domain, favicon_url, first_seen, last_seen, story_count, average_score, top_score,
+			comment_count, refreshed_at, favicon_checked_at
+		 FROM domains WHERE domain = $1`, strings.TrimPrefix(strings.ToLower(domain), "www.")).
+		Scan(&d.Domain, &d.FaviconURL, &d.FirstSeen, &d.LastSeen, &d.StoryCount, &d.AverageScore, &d.TopScore,
+			&d.CommentCount, &d.RefreshedAt, &d.FaviconCheckedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+
+	"github.com/lib/pq"
+)
+
+// filterColumns describes a filterable item table: its name, the relation its queries read when
+// the rows are spread over several tables, the columns its list query selects and which
+// filterable columns it has.
+// Empty names mark predicates the table cannot support; they are skipped.
+type filterColumns struct {
+	table      string
+	view       string
+	selected   string
+	score      string
+	percentile string
+	url        string
+	linkStatus string
+	textQuery  []string
+}
+
+var (
+	storyFilterColumns = filterColumns{
+		table:      "stories",
+		selected:   "id, type, title, url, score, author, created_at, comments_ids, comments_count, source",
+		score:      "score",
+		percentile: "score_percentile",
+		url:        "url",
+		linkStatus: "link_status",
+		textQuery:  []string{"title"},
+	}
+	askFilterColumns = filterColumns{
+		table:     "asks",
+		selected:  "id, type, title, text, score, author, reply_ids, replies_count, created_at",
+		score:     "score",
+		textQuery: []string{"title", "text"},
+	}
+	jobFilterColumns = filterColumns{
+		table:     "jobs",
+		selected:  "id, type, title, text, url, score, author, created_at",
+		score:     "score",
+		url:       "url",
+		textQuery: []string{"title", "text"},
+	}
+	commentFilterColumns = filterColumns{
+		table:     "comments",
+		view:      "comments_all",
+		selected:  "id, type, text, author, created_at, parent_id, reply_ids, source, COALESCE(depth, 0), COALESCE(sibling_rank, 0)",
+		textQuery: []string{"text"},
+	}
+	pollFilterColumns = filterColumns{
+		table:     "polls",
+		selected:  "id, type, title, score, author, poll_options, reply_ids, created_at",
+		score:     "score",
+		textQuery: []string{"title"},
+	}
+)
+
+// relation returns the table or view the queries of the item kind read
+func (cols filterColumns) relation() string {
+	if cols.view != "" {
+		return cols.view
+	}
+	return cols.table
+}
+
+// whereBuilder accumulates SQL conditions and their positional arguments
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition; every "?" in cond is replaced by the placeholder of arg, so
+// conditions holding a literal "?", such as a regular expression, use param instead
+func (b *whereBuilder) add(cond string, arg interface{}) {
+	b.conditions = append(b.conditions, strings.ReplaceAll(cond, "?", b.param(arg)))
+}
+
+// param appends arg and returns its placeholder
+func (b *whereBuilder) param(arg interface{}) string {
+	b.args = append(b.args, arg)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where returns the WHERE clause (with a leading space) or an empty string
+func (b *whereBuilder) where() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// page returns the LIMIT/OFFSET clause for the filter and appends its arguments
+func (b *whereBuilder) page(filter repository.ItemFilter) string {
+	clause := ""
+	if filter.Limit > 0 {
+		b.args = append(b.args, filter.Limit)
+		clause += fmt.Sprintf(" LIMIT $%d", len(b.args))
+	}
+	if filter.Offset > 0 {
+		b.args = append(b.args, filter.Offset)
+		clause += fmt.Sprintf(" OFFSET $%d", len(b.args))
+	}
+	return clause
+}
+
+// buildItemFilter translates an ItemFilter into conditions for a table with the given columns
+func buildItemFilter(filter repository.ItemFilter, cols filterColumns) *whereBuilder {
+	b := &whereBuilder{}
+
+	if filter.Tenant != "" {
+		b.add("tenant = ?", filter.Tenant)
+	}
+	if filter.IDs != nil {
+		b.add("id = ANY(?)", pq.Array(filter.IDs))
+	}
+	if filter.Source != "" {
+		b.add("source = ?", filter.Source)
+	}
+	if filter.Author != "" {
+		b.add("author = ?", filter.Author)
+	}
+	if cols.score != "" && filter.MinScore != nil {
+		b.add(cols.score+" >= ?", *filter.MinScore)
+	}
+	if cols.score != "" && filter.MaxScore != nil {
+		b.add(cols.score+" <= ?", *filter.MaxScore)
+	}
+	if cols.percentile != "" && filter.MinPercentile != nil {
+		b.add(cols.percentile+" >= ?", *filter.MinPercentile)
+	}
+	if filter.Start > 0 {
+		b.add("created_at >= ?", filter.Start)
+	}
+	if filter.End > 0 {
+		b.add("created_at <= ?", filter.End)
+	}
+	if filter.Type != "" {
+		b.add("LOWER(type) = LOWER(?)", filter.Type)
+	}
+	if cols.url != "" && filter.Domain != "" {
+		domain := b.param(strings.TrimPrefix(filter.Domain, "www."))
+		b.conditions = append(b.conditions,
+			`LOWER(substring(`+cols.url+` from '://(?:www\.)?([^/:?#]+)')) = LOWER(`+domain+`)`)
+	}
+	if cols.linkStatus != "" && filter.ExcludeDeadLinks {
+		b.add(cols.linkStatus+" IS DISTINCT FROM ?", models.LinkDead)
+	}
+	if len(filter.Extra) > 0 {
+		b.add("extra @> ?::jsonb", filter.Extra)
+	}
+	if filter.ExtraKey != "" {
+		b.add("extra -> ? IS NOT NULL", filter.ExtraKey)
+	}
+	if filter.MaxSpamScore != nil {
+		b.add("spam_score < ?", *filter.MaxSpamScore)
+	}
+	if len(cols.textQuery) > 0 && filter.Query != "" {
+		matches := make([]string, len(cols.textQuery))
+		for i, col := range cols.textQuery {
+			matches[i] = col + " ILIKE ?"
+		}
+		b.add("("+strings.Join(matches, " OR ")+")", "%"+filter.Query+"%")
+	}
+
+	return b
+}
+
+// listQuery returns the newest-first list query of the table for the filter and its arguments
+func listQuery(filter repository.ItemFilter, cols filterColumns) (string, []interface{}) {
+	b := buildItemFilter(filter, cols)
+	query := `SELECT ` + cols.selected + ` FROM ` + cols.relation() + b.where() + ` ORDER BY created_at DESC` + b.page(filter)
+	return query, b.args
+}
+
+// countQuery returns the query counting the table rows matching the filter, ignoring its limit and offset
+func countQuery(filter repository.ItemFilter, cols filterColumns) (string, []interface{}) {
+	b := buildItemFilter(filter, cols)
+	return `SELECT COUNT(*) FROM ` + cols.relation() + b.where(), b.args
+}
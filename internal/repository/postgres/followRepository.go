@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// FollowRepository implements repository.FollowRepository
+type FollowRepository struct {
+	db *sql.DB
+}
+
+// NewFollowRepository creates a new FollowRepository instance
+func NewFollowRepository() repository.FollowRepository {
+	return instrumented.NewFollowRepository(&FollowRepository{
+		db: database.GetDB(),
+	})
+}
+
+// Follow adds an author to the tenant's follows; following an author twice is a no-op
+func (r *FollowRepository) Follow(ctx context.Context, tenant, author string) (*models.Follow, error) {
+	follow := &models.Follow{Author: author}
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO follows (tenant, author, created_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (tenant, author) DO UPDATE SET author = EXCLUDED.author
+		 RETURNING created_at`, tenant, author, time.Now().Unix()).Scan(&follow.Created_At)
+	if err != nil {
+		return nil, err
+	}
+	return follow, nil
+}
+
+// Unfollow removes an author from the tenant's follows; it returns sql.ErrNoRows if it was not followed
+func (r *FollowRepository) Unfollow(ctx context.Context, tenant, author string) error {
+	return deleteOne(r.db.ExecContext(ctx, `DELETE FROM follows WHERE tenant = $1 AND author = $2`, tenant, author))
+}
+
+// GetFollows returns the authors the tenant follows, alphabetically
+func (r *FollowRepository) GetFollows(ctx context.Context, tenant string) ([]*models.Follow, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT author, created_at FROM follows WHERE tenant = $1 ORDER BY author`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []*models.Follow
+	for rows.Next() {
+		follow := &models.Follow{}
+		if err := rows.Scan(&follow.Author, &follow.Created_At); err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+// SaveSearch adds a title query to the tenant's saved searches; saving it twice returns the existing one
+func (r *FollowRepository) SaveSearch(ctx context.Context, tenant, query string) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{Query: query}
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO saved_searches (tenant, query, created_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (tenant, query) DO UPDATE SET query = EXCLUDED.query
+		 RETURNING id, created_at`, tenant, query, time.Now().Unix()).Scan(&search.ID, &search.Created_At)
+	if err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// DeleteSavedSearch removes one of the tenant's saved searches; it returns sql.ErrNoRows if there is none with that ID
+func (r *FollowRepository) DeleteSavedSearch(ctx context.Context, tenant string, id int) error {
+	return deleteOne(r.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE tenant = $1 AND id = $2`, tenant, id))
+}
+
+// GetSavedSearches returns the tenant's saved searches, oldest first
+func (r *FollowRepository) GetSavedSearches(ctx context.Context, tenant string) ([]*models.SavedSearch, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, query, created_at FROM saved_searches WHERE tenant = $1 ORDER BY id`, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []*models.SavedSearch
+	for rows.Next() {
+		search := &models.SavedSearch{}
+		if err := rows.Scan(&search.ID, &search.Query, &search.Created_At); err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+// deleteOne maps a DELETE that matched no row to sql.ErrNoRows
+func deleteOne(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// ItemPayloadRepository implements repository.ItemPayloadRepository
+type ItemPayloadRepository struct {
+	db *sql.DB
+}
+
+// NewItemPayloadRepository creates a new ItemPayloadRepository instance
+func NewItemPayloadRepository() repository.ItemPayloadRepository {
+	return instrumented.NewItemPayloadRepository(&ItemPayloadRepository{
+		db: database.GetDB(),
+	})
+}
+
+// itemPayloadColumns are the columns read by itemPayloadFields
+const itemPayloadColumns = `item_id, kind, payload, fetched_at`
+
+// Save stores the payload of an item, replacing the one stored before
+func (r *ItemPayloadRepository) Save(ctx context.Context, payload *models.ItemPayload) error {
+	// Sent as text: lib/pq would encode []byte as bytea
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO item_payloads (item_id, kind, payload, fetched_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (item_id) DO UPDATE
+		 SET kind = EXCLUDED.kind, payload = EXCLUDED.payload, fetched_at = EXCLUDED.fetched_at`,
+		payload.Item_ID, payload.Kind, string(payload.Payload), payload.Fetched_At)
+	return err
+}
+
+// GetByID returns the stored payload of an item, or sql.ErrNoRows
+func (r *ItemPayloadRepository) GetByID(ctx context.Context, id int) (*models.ItemPayload, error) {
+	payload := &models.ItemPayload{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT `+itemPayloadColumns+` FROM item_payloads WHERE item_id = $1`, id).
+		Scan(itemPayloadFields(payload)...)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// GetByIDs returns the stored payloads among ids, by item ID
+func (r *ItemPayloadRepository) GetByIDs(ctx context.Context, ids []int) (map[int]*models.ItemPayload, error) {
+	payloads := make(map[int]*models.ItemPayload)
+	if len(ids) == 0 {
+		return payloads, nil
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+itemPayloadColumns+` FROM item_payloads WHERE item_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payload := &models.ItemPayload{}
+		if err := rows.Scan(itemPayloadFields(payload)...); err != nil {
+			return nil, err
+		}
+		payloads[payload.Item_ID] = payload
+	}
+	return payloads, rows.Err()
+}
+
+// itemPayloadFields returns the scan destinations of itemPayloadColumns
+func itemPayloadFields(p *models.ItemPayload) []interface{} {
+	return []interface{}{&p.Item_ID, &p.Kind, (*[]byte)(&p.Payload), &p.Fetched_At}
+}
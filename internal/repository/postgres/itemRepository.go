@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+
+	"github.com/lib/pq"
+)
+
+// ItemRepository implements repository.ItemRepository
+type ItemRepository struct {
+	db *sql.DB
+}
+
+// NewItemRepository creates a new ItemRepository instance
+func NewItemRepository() repository.ItemRepository {
+	return instrumented.NewItemRepository(&ItemRepository{
+		db: database.GetDB(),
+	})
+}
+
+// GetKind returns the kind of the stored item with the given ID
+// ("story", "ask", "job", "comment", "poll" or "pollopt"), or sql.ErrNoRows
+func (r *ItemRepository) GetKind(ctx context.Context, id int) (string, error) {
+	var kind string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT 'story' FROM stories WHERE id = $1
+		 UNION ALL SELECT 'ask' FROM asks WHERE id = $1
+		 UNION ALL SELECT 'job' FROM jobs WHERE id = $1
+		 UNION ALL SELECT 'comment' FROM comments WHERE id = $1
+		 UNION ALL SELECT 'comment' FROM comments_cold WHERE id = $1
+		 UNION ALL SELECT 'poll' FROM polls WHERE id = $1
+		 UNION ALL SELECT 'pollopt' FROM poll_options WHERE id = $1
+		 LIMIT 1`, id).Scan(&kind)
+	return kind, err
+}
+
+// GetKinds returns the kinds of the stored items among ids, by ID; missing IDs are left out
+func (r *ItemRepository) GetKinds(ctx context.Context, ids []int) (map[int]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, 'story' FROM stories WHERE id = ANY($1)
+		 UNION ALL SELECT id, 'ask' FROM asks WHERE id = ANY($1)
+		 UNION ALL SELECT id, 'job' FROM jobs WHERE id = ANY($1)
+		 UNION ALL SELECT id, 'comment' FROM comments_all WHERE id = ANY($1)
+		 UNION ALL SELECT id, 'poll' FROM polls WHERE id = ANY($1)
+		 UNION ALL SELECT id, 'pollopt' FROM poll_options WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kinds := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var kind string
+		if err := rows.Scan(&id, &kind); err != nil {
+			return nil, err
+		}
+		if _, ok := kinds[id]; !ok {
+			kinds[id] = kind
+		}
+	}
+	return kinds, rows.Err()
+}
+
+// ScanIDs returns up to limit IDs of stored items of any kind greater than afterID, in ascending
+// order; each table is read in ID index order, so pages stay cheap deep into the keyspace
+func (r *ItemRepository) ScanIDs(ctx context.Context, afterID, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM (
+			(SELECT id FROM stories WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM asks WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM jobs WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM comments WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM comments_cold WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM polls WHERE id > $1 ORDER BY id LIMIT $2)
+			UNION ALL (SELECT id FROM poll_options WHERE id > $1 ORDER BY id LIMIT $2)
+		 ) ids ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// kindFilterColumns maps the filterable item kinds to their table columns
+var kindFilterColumns = map[string]filterColumns{
+	"story":   storyFilterColumns,
+	"ask":     askFilterColumns,
+	"job":     jobFilterColumns,
+	"comment": commentFilterColumns,
+	"poll":    pollFilterColumns,
+}
+
+// GetIDs returns up to limit IDs of the items of the kinds matching the filter, kind by kind in
+// the given order and in ascending ID order within a kind
+func (r *ItemRepository) GetIDs(ctx context.Context, kinds []string, filter repository.ItemFilter, limit int) ([]int, error) {
+	var ids []int
+	for _, kind := range kinds {
+		cols, ok := kindFilterColumns[kind]
+		if !ok {
+			return nil, ErrUnknownKind
+		}
+		if len(ids) >= limit {
+			break
+		}
+
+		b := buildItemFilter(filter, cols)
+		b.args = append(b.args, limit-len(ids))
+		kindIDs, err := queryIDs(ctx, r.db,
+			fmt.Sprintf(`SELECT id FROM %s%s ORDER BY id LIMIT $%d`, cols.relation(), b.where(), len(b.args)), b.args...)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, kindIDs...)
+	}
+	return ids, nil
+}
+
+// CountExtraKeys counts the stored items of the kind by name of their extra attributes, archived
+// comments included
+func (r *ItemRepository) CountExtraKeys(ctx context.Context, kind string) (map[string]int64, error) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	if kind == "comment" {
+		table = commentFilterColumns.relation()
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT key, COUNT(*) FROM `+table+`, jsonb_object_keys(extra) AS key GROUP BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var key string
+		var count int64
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, err
+		}
+		counts[key] = count
+	}
+	return counts, rows.Err()
+}
+
+// newAuthorTables lists, per kind, the tables where MarkNewAuthor looks for the item
+var newAuthorTables = map[string][]string{
+	"story":   {"stories"},
+	"comment": {"comments", "comments_cold"},
+}
+
+// newAuthorUpdate flags the item $1 of the table in %s when its author's account was created less
+// than $2 seconds before it or lists it as its first submission; authors without a stored account
+// are new when no earlier story or comment of theirs is stored
+const newAuthorUpdate = `
+	UPDATE %s AS item SET new_author = COALESCE(
+		(SELECT u.created_at > item.created_at - $2 OR item.id <= (SELECT MIN(s) FROM unnest(u.submitted_ids) AS s)
+		 FROM users u WHERE u.username = item.author),
+		NOT EXISTS (SELECT 1 FROM stories s WHERE s.author = item.author AND s.created_at < item.created_at)
+		AND NOT EXISTS (SELECT 1 FROM comments_all c WHERE c.author = item.author AND c.created_at < item.created_at))
+	WHERE id = $1
+	RETURNING new_author`
+
+// MarkNewAuthor sets the new_author flag of a stored story or comment and returns it. The author
+// is new when their account is younger than accountAge at the time of the post or the post is
+// their first submission. Missing items return sql.ErrNoRows.
+func (r *ItemRepository) MarkNewAuthor(ctx context.Context, kind string, id int, accountAge time.Duration) (bool, error) {
+	tables, ok := newAuthorTables[kind]
+	if !ok {
+		return false, ErrUnknownKind
+	}
+	for _, table := range tables {
+		var flagged bool
+		err := r.db.QueryRowContext(ctx, fmt.Sprintf(newAuthorUpdate, table), id, int64(accountAge.Seconds())).Scan(&flagged)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		return flagged, err
+	}
+	return false, sql.ErrNoRows
+}
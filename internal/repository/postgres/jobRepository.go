@@ -6,6 +6,7 @@ import (
 
 	models "internship-project/internal/models"
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 )
 
@@ -16,9 +17,9 @@ type JobRepository struct {
 
 // NewJobRepository creates a new JobRepository instance
 func NewJobRepository() repository.JobRepository {
-	return &JobRepository{
+	return instrumented.NewJobRepository(&JobRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // Create inserts a new job
@@ -35,10 +36,10 @@ func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
 func (r *JobRepository) GetByID(ctx context.Context, id int) (*models.Job, error) {
 	job := &models.Job{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, title, text, url, score, author, created_at 
+		`SELECT id, type, title, text, url, score, author, created_at, extra 
 		 FROM jobs WHERE id = $1`, id).Scan(
 		&job.ID, &job.Type, &job.Title, &job.Text, &job.URL,
-		&job.Score, &job.Author, &job.Created_At)
+		&job.Score, &job.Author, &job.Created_At, &job.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +122,25 @@ func (r *JobRepository) GetByDateRange(ctx context.Context, start, end int64) ([
 	return scanJobs(rows)
 }
 
+// GetByFilter retrieves jobs matching all predicates of the filter
+func (r *JobRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) ([]*models.Job, error) {
+	query, args := listQuery(filter, jobFilterColumns)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanJobs(rows)
+}
+
+// Count returns the number of jobs matching the filter, ignoring its limit and offset
+func (r *JobRepository) Count(ctx context.Context, filter repository.ItemFilter) (int, error) {
+	query, args := countQuery(filter, jobFilterColumns)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
 // UpdateScore updates job score
 func (r *JobRepository) UpdateScore(ctx context.Context, id int, score int) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE jobs SET score = $1 WHERE id = $2`, score, id)
@@ -155,30 +175,39 @@ func (r *JobRepository) CreateBatch(ctx context.Context, jobs []*models.Job) err
 
 // CreateBatchWithExistingIDs creates multiple jobs with existing IDs
 func (r *JobRepository) CreateBatchWithExistingIDs(ctx context.Context, jobs []*models.Job) error {
+	_, err := r.UpsertBatch(ctx, jobs)
+	return err
+}
+
+// UpsertBatch upserts multiple jobs and reports how many were new, changed or already up to date
+func (r *JobRepository) UpsertBatch(ctx context.Context, jobs []*models.Job) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO jobs (id, type, title, text, url, score, author, created_at) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET type = EXCLUDED.type,
-		 title = EXCLUDED.title, text = EXCLUDED.text, url = EXCLUDED.url,	score = EXCLUDED.score,
-		 author = EXCLUDED.author, created_at = EXCLUDED.created_at`)
+		`INSERT INTO jobs (id, type, title, text, url, score, author, created_at, extra) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id)`+
+			upsertSet("jobs", "type", "title", "text", "url", "score", "author", "created_at", "extra"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 
 	for _, job := range jobs {
-		_, err := stmt.ExecContext(ctx, job.ID, job.Type, job.Title, job.Text,
-			job.URL, job.Score, job.Author, job.Created_At)
+		err := execUpsert(ctx, stmt, &counts, job.ID, job.Type, job.Title, job.Text,
+			job.URL, job.Score, job.Author, job.Created_At, job.Extra)
 		if err != nil {
-			return err
+			return repository.UpsertCounts{}, err
 		}
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
 }
 
 // DeleteByAuthor deletes all jobs by author
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// JobRunRepository implements repository.JobRunRepository
+type JobRunRepository struct {
+	db *sql.DB
+}
+
+// NewJobRunRepository creates a new JobRunRepository instance
+func NewJobRunRepository() repository.JobRunRepository {
+	return instrumented.NewJobRunRepository(&JobRunRepository{
+		db: database.GetDB(),
+	})
+}
+
+// Record counts a run of the job, replacing its last run
+func (r *JobRunRepository) Record(ctx context.Context, name string, startedAt time.Time, duration time.Duration) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO job_runs (name, runs, last_started_at, last_finished_at, last_duration_ms)
+		 VALUES ($1, 1, $2, $3, $4)
+		 ON CONFLICT (name) DO UPDATE
+		 SET runs = job_runs.runs + 1, last_started_at = EXCLUDED.last_started_at,
+		     last_finished_at = EXCLUDED.last_finished_at, last_duration_ms = EXCLUDED.last_duration_ms`,
+		name, startedAt.Unix(), startedAt.Add(duration).Unix(), duration.Milliseconds())
+	return err
+}
+
+// GetAll returns the last run of every job that ran, by name
+func (r *JobRunRepository) GetAll(ctx context.Context) ([]*models.JobRun, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, runs, last_started_at, last_finished_at, last_duration_ms FROM job_runs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*models.JobRun{}
+	for rows.Next() {
+		run := &models.JobRun{}
+		if err := rows.Scan(&run.Name, &run.Runs, &run.Last_Started_At, &run.Last_Finished_At, &run.Last_Duration_Ms); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
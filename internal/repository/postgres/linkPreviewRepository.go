@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// LinkPreviewRepository implements repository.LinkPreviewRepository
+type LinkPreviewRepository struct {
+	db *sql.DB
+}
+
+// NewLinkPreviewRepository creates a new LinkPreviewRepository instance
+func NewLinkPreviewRepository() repository.LinkPreviewRepository {
+	return instrumented.NewLinkPreviewRepository(&LinkPreviewRepository{
+		db: database.GetDB(),
+	})
+}
+
+// GetLinksToPreview returns the story ID and URL of the stories to preview, those never
+// previewed first
+func (r *LinkPreviewRepository) GetLinksToPreview(ctx context.Context, fetchedBefore, failedBefore int64, limit int) ([]*models.LinkPreview, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT s.id, s.url FROM stories s
+		 LEFT JOIN link_previews p ON p.story_id = s.id
+		 WHERE s.url <> '' AND (p.story_id IS NULL OR p.url <> s.url OR p.fetched_at < $1
+			OR (p.status = $2 AND p.fetched_at < $3))
+		 ORDER BY p.fetched_at NULLS FIRST, s.created_at DESC LIMIT $4`,
+		fetchedBefore, models.PreviewFailed, failedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var previews []*models.LinkPreview
+	for rows.Next() {
+		preview := &models.LinkPreview{}
+		if err := rows.Scan(&preview.StoryID, &preview.URL); err != nil {
+			return nil, err
+		}
+		previews = append(previews, preview)
+	}
+	return previews, rows.Err()
+}
+
+// SavePreviews stores the previews, replacing the previous ones of their stories
+func (r *LinkPreviewRepository) SavePreviews(ctx context.Context, previews []*models.LinkPreview) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO link_previews (story_id, url, status, title, description, image_url, site_name, fetched_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (story_id) DO UPDATE SET url = $2, status = $3, title = $4, description = $5,
+			image_url = $6, site_name = $7, fetched_at = $8`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range previews {
+		if _, err := stmt.ExecContext(ctx, p.StoryID, p.URL, p.Status, p.Title, p.Description, p.ImageURL,
+			p.SiteName, p.FetchedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetByStory returns the stored preview of a story
+func (r *LinkPreviewRepository) GetByStory(ctx context.Context, storyID int) (*models.LinkPreview, error) {
+	p := &models.LinkPreview{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT story_id, url, status, title, description, image_url, site_name, fetched_at
+		 FROM link_previews WHERE story_id = $1`, storyID).
+		Scan(&p.StoryID, &p.URL, &p.Status, &p.Title, &p.Description, &p.ImageURL, &p.SiteName, &p.FetchedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
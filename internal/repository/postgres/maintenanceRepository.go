@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+
+	"github.com/lib/pq"
+)
+
+// MaintenanceRepository implements repository.MaintenanceRepository
+type MaintenanceRepository struct {
+	db *sql.DB
+}
+
+// NewMaintenanceRepository creates a new MaintenanceRepository instance
+func NewMaintenanceRepository() repository.MaintenanceRepository {
+	return instrumented.NewMaintenanceRepository(&MaintenanceRepository{
+		db: database.GetDB(),
+	})
+}
+
+// DefaultMaintainedTables are the item tables written by the sync jobs and backfills, looked after
+// and reported when MAINTENANCE_TABLES is not set
+var DefaultMaintainedTables = []string{"stories", "comments", "asks", "jobs", "polls", "poll_options", "users"}
+
+// tableStatsQuery sums the statistics of a table and its partitions. Autovacuum analyzes the
+// partitions but never a partitioned table itself, so the age of its statistics is its own.
+const tableStatsQuery = `
+	SELECT p.relkind = 'p', p.reltuples::BIGINT,
+	    COALESCE(SUM(s.n_live_tup), 0), COALESCE(SUM(s.n_dead_tup), 0), COALESCE(SUM(s.n_mod_since_analyze), 0),
+	    COALESCE(SUM(pg_table_size(r.oid)), 0), COALESCE(SUM(pg_indexes_size(r.oid)), 0),
+	    COALESCE(EXTRACT(EPOCH FROM GREATEST(ps.last_analyze, ps.last_autoanalyze))::BIGINT, 0),
+	    COALESCE(EXTRACT(EPOCH FROM MIN(GREATEST(s.last_vacuum, s.last_autovacuum)))::BIGINT, 0)
+	FROM pg_class p
+	LEFT JOIN pg_stat_user_tables ps ON ps.relid = p.oid
+	JOIN pg_class r ON r.oid = p.oid OR r.oid IN (SELECT inhrelid FROM pg_inherits WHERE inhparent = p.oid)
+	LEFT JOIN pg_stat_user_tables s ON s.relid = r.oid
+	WHERE p.oid = to_regclass($1)
+	GROUP BY p.oid, p.relkind, p.reltuples, ps.last_analyze, ps.last_autoanalyze`
+
+// indexStatsQuery sums the size and scans of the indexes of a table over their partitions
+const indexStatsQuery = `
+	SELECT i.relname, COALESCE(SUM(pg_relation_size(COALESCE(leaf.inhrelid, i.oid))), 0), COALESCE(SUM(s.idx_scan), 0)
+	FROM pg_index x
+	JOIN pg_class i ON i.oid = x.indexrelid
+	LEFT JOIN pg_inherits leaf ON leaf.inhparent = i.oid
+	LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = COALESCE(leaf.inhrelid, i.oid)
+	WHERE x.indrelid = to_regclass($1)
+	GROUP BY i.relname ORDER BY i.relname`
+
+// GetTableStats loads the tables one by one, in the given order
+func (r *MaintenanceRepository) GetTableStats(ctx context.Context, tables []string) ([]*models.TableStats, error) {
+	var stats []*models.TableStats
+	for _, table := range tables {
+		t := &models.TableStats{Table: table, Indexes: []*models.IndexStats{}}
+		var estimate int64
+		err := r.db.QueryRowContext(ctx, tableStatsQuery, table).Scan(&t.Partitioned, &estimate,
+			&t.LiveRows, &t.DeadRows, &t.ModifiedSinceAnalyze, &t.TableBytes, &t.IndexBytes,
+			&t.LastAnalyzedAt, &t.LastVacuumedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Partitioned tables keep no modification counter: their row estimate (-1 before the first
+		// ANALYZE) drifting from the live rows of the partitions stands for it
+		if t.Partitioned {
+			t.ModifiedSinceAnalyze = t.LiveRows
+			if estimate >= 0 {
+				t.ModifiedSinceAnalyze = max(t.LiveRows-estimate, estimate-t.LiveRows)
+			}
+		}
+		if total := t.LiveRows + t.DeadRows; total > 0 {
+			t.DeadRatio = float64(t.DeadRows) / float64(total)
+		}
+		t.EstimatedBloatBytes = int64(float64(t.TableBytes) * t.DeadRatio)
+
+		rows, err := r.db.QueryContext(ctx, indexStatsQuery, table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			index := &models.IndexStats{}
+			if err := rows.Scan(&index.Name, &index.Bytes, &index.Scans); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			index.EstimatedBloatBytes = int64(float64(index.Bytes) * t.DeadRatio)
+			t.Indexes = append(t.Indexes, index)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		stats = append(stats, t)
+	}
+	return stats, nil
+}
+
+// Analyze runs ANALYZE, which recurses into the partitions of a partitioned table
+func (r *MaintenanceRepository) Analyze(ctx context.Context, table string) error {
+	_, err := r.db.ExecContext(ctx, `ANALYZE `+pq.QuoteIdentifier(table))
+	return err
+}
+
+// Vacuum runs VACUUM (ANALYZE); it cannot run inside a transaction
+func (r *MaintenanceRepository) Vacuum(ctx context.Context, table string) error {
+	_, err := r.db.ExecContext(ctx, `VACUUM (ANALYZE) `+pq.QuoteIdentifier(table))
+	return err
+}
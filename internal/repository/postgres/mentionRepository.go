@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// MentionRepository implements repository.MentionRepository
+type MentionRepository struct {
+	db *sql.DB
+}
+
+// NewMentionRepository creates a new MentionRepository instance
+func NewMentionRepository() repository.MentionRepository {
+	return instrumented.NewMentionRepository(&MentionRepository{
+		db: database.GetDB(),
+	})
+}
+
+// ReplaceMentions sets the mentions of a comment in one transaction. The reply mention goes to
+// the author of the stored parent item, whatever its kind, and wins over an @username of the
+// same user so a comment mentions a user once.
+func (r *MentionRepository) ReplaceMentions(ctx context.Context, comment *models.Comment, usernames []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mentions WHERE comment_id = $1`, comment.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO mentions (comment_id, username, kind, author, created_at)
+		 SELECT $1, p.author, $2, $3, $4 FROM (
+			SELECT author FROM comments WHERE id = $5
+			UNION ALL SELECT author FROM stories WHERE id = $5
+			UNION ALL SELECT author FROM asks WHERE id = $5
+			UNION ALL SELECT author FROM polls WHERE id = $5
+		 ) AS p WHERE p.author <> $3 LIMIT 1`,
+		comment.ID, models.MentionReply, comment.Author, comment.Created_At, comment.Parent); err != nil {
+		return err
+	}
+	if len(usernames) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mentions (comment_id, username, kind, author, created_at)
+			 SELECT $1, u, $2, $3, $4 FROM unnest($5::text[]) AS u WHERE u <> $3
+			 ON CONFLICT DO NOTHING`,
+			comment.ID, models.MentionAt, comment.Author, comment.Created_At, pq.Array(usernames)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// mentionsQuery selects mentions with the text of their comments, restricted to a user and tenant
+const mentionsQuery = `
+	SELECT m.comment_id, m.username, m.kind, m.author, COALESCE(c.parent_id, 0), c.text, m.created_at
+	FROM mentions m JOIN comments_all c ON c.id = m.comment_id
+	WHERE m.username = $1 AND c.tenant = $2`
+
+// GetByUsername returns the tenant's mentions of a user older than the cursor, newest first
+func (r *MentionRepository) GetByUsername(ctx context.Context, tenant, username string, cursor models.TimelineCursor, limit int) ([]*models.Mention, error) {
+	var rows *sql.Rows
+	var err error
+	if cursor.IsZero() {
+		rows, err = r.db.QueryContext(ctx,
+			mentionsQuery+` ORDER BY m.created_at DESC, m.comment_id DESC LIMIT $3`, username, tenant, limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx,
+			mentionsQuery+` AND (m.created_at, m.comment_id) < ($3, $4) ORDER BY m.created_at DESC, m.comment_id DESC LIMIT $5`,
+			username, tenant, cursor.Created_At, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mentions := []*models.Mention{}
+	for rows.Next() {
+		m := &models.Mention{}
+		if err := rows.Scan(&m.Comment_ID, &m.Username, &m.Kind, &m.Author, &m.Parent, &m.Text, &m.Created_At); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
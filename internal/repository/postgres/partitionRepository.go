@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// PartitionRepository implements repository.PartitionRepository
+type PartitionRepository struct {
+	db *sql.DB
+}
+
+// NewPartitionRepository creates a new PartitionRepository instance
+func NewPartitionRepository() repository.PartitionRepository {
+	return instrumented.NewPartitionRepository(&PartitionRepository{
+		db: database.GetDB(),
+	})
+}
+
+// EnsurePartitions creates the partitions with ensure_month_partitions
+func (r *PartitionRepository) EnsurePartitions(ctx context.Context, table string, from, to int64) (int, error) {
+	var created int
+	err := r.db.QueryRowContext(ctx, `SELECT ensure_month_partitions($1, $2, $3)`, table, from, to).Scan(&created)
+	return created, err
+}
+
+// execer runs statements on the database or within a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ensureMonthPartitions creates the partitions of table the creation times need before they are
+// written. Creating one locks the table until db commits, which only happens for the first rows
+// of a month the partition job did not create ahead.
+func ensureMonthPartitions(ctx context.Context, db execer, table string, createdAt ...int64) error {
+	if len(createdAt) == 0 {
+		return nil
+	}
+	from, to := createdAt[0], createdAt[0]
+	for _, t := range createdAt[1:] {
+		from, to = min(from, t), max(to, t)
+	}
+	_, err := db.ExecContext(ctx, `SELECT ensure_month_partitions($1, $2, $3)`, table, from, to)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 
 	models "internship-project/internal/models"
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 )
 
@@ -17,9 +18,9 @@ type PollOptionRepository struct {
 
 // NewPollOptionRepository creates a new PollOptionRepository instance
 func NewPollOptionRepository() repository.PollOptionRepository {
-	return &PollOptionRepository{
+	return instrumented.NewPollOptionRepository(&PollOptionRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // CRUD Operations
@@ -42,10 +43,10 @@ func (r *PollOptionRepository) Create(ctx context.Context, pollOption *models.Po
 func (r *PollOptionRepository) GetByID(ctx context.Context, id int) (*models.PollOption, error) {
 	pollOption := &models.PollOption{}
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, poll_id, author, option_text, created_at, votes 
+		`SELECT id, type, poll_id, author, option_text, created_at, votes, extra 
 		 FROM poll_options WHERE id = $1`, id).Scan(
 		&pollOption.ID, &pollOption.Type, &pollOption.PollID,
-		&pollOption.Author, &pollOption.OptionText, &pollOption.CreatedAt, &pollOption.Votes)
+		&pollOption.Author, &pollOption.OptionText, &pollOption.CreatedAt, &pollOption.Votes, &pollOption.Extra)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("poll option not found with id: %d", id)
@@ -186,6 +187,52 @@ func (r *PollOptionRepository) UpdateVotes(ctx context.Context, id int, votes in
 	return nil
 }
 
+// IncrementVotes atomically adds one vote to a poll option
+func (r *PollOptionRepository) IncrementVotes(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE poll_options SET votes = votes + 1 WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("poll option not found with id: %d", id)
+	}
+
+	return nil
+}
+
+// DecrementVotes atomically removes one vote from a poll option, never going below zero
+func (r *PollOptionRepository) DecrementVotes(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE poll_options SET votes = votes - 1 WHERE id = $1 AND votes > 0`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		exists, err := r.Exists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("poll option not found with id: %d", id)
+		}
+		return fmt.Errorf("votes cannot be negative")
+	}
+
+	return nil
+}
+
 // Batch operations
 
 // CreateBatch creates multiple poll options
@@ -226,32 +273,72 @@ func (r *PollOptionRepository) CreateBatch(ctx context.Context, pollOptions []*m
 
 // CreateBatchWithExistingIDs creates multiple poll options with existing IDs
 func (r *PollOptionRepository) CreateBatchWithExistingIDs(ctx context.Context, pollOptions []*models.PollOption) error {
+	_, err := r.UpsertBatch(ctx, pollOptions)
+	return err
+}
+
+// UpsertBatch upserts multiple poll options and reports how many were new, changed or already up to date
+func (r *PollOptionRepository) UpsertBatch(ctx context.Context, pollOptions []*models.PollOption) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	if len(pollOptions) == 0 {
-		return nil
+		return counts, nil
 	}
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO poll_options (id, type, poll_id, author, option_text, created_at, votes)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO UPDATE SET
-		 type = EXCLUDED.type, poll_id = EXCLUDED.poll_id, author = EXCLUDED.author,
-		 option_text = EXCLUDED.option_text, created_at = EXCLUDED.created_at, votes = EXCLUDED.votes`)
+		`INSERT INTO poll_options (id, type, poll_id, author, option_text, created_at, votes, extra)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id)`+
+			upsertSet("poll_options", "type", "poll_id", "author", "option_text", "created_at", "votes", "extra"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 
 	defer stmt.Close()
 	for _, pollOption := range pollOptions {
 		if !pollOption.IsValid() {
-			return fmt.Errorf("invalid poll option data in batch")
+			return repository.UpsertCounts{}, fmt.Errorf("invalid poll option data in batch")
 		}
-		_, err := stmt.ExecContext(ctx,
+		err := execUpsert(ctx, stmt, &counts,
 			pollOption.ID, pollOption.Type, pollOption.PollID, pollOption.Author,
-			pollOption.OptionText, pollOption.CreatedAt, pollOption.Votes)
+			pollOption.OptionText, pollOption.CreatedAt, pollOption.Votes, pollOption.Extra)
 		if err != nil {
+			return repository.UpsertCounts{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
+}
+
+// IncrementVotesBatch applies vote deltas (option ID -> delta) in a single transaction.
+// Deltas may be negative; the resulting vote count is clamped at zero.
+func (r *PollOptionRepository) IncrementVotesBatch(ctx context.Context, deltas map[int]int) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`UPDATE poll_options SET votes = GREATEST(votes + $1, 0) WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, delta, id); err != nil {
 			return err
 		}
 	}
@@ -6,6 +6,7 @@ import (
 
 	models "internship-project/internal/models"
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 
 	"github.com/lib/pq"
@@ -18,9 +19,9 @@ type PollRepository struct {
 
 // NewPollRepository creates a new PollRepository instance
 func NewPollRepository() repository.PollRepository {
-	return &PollRepository{
+	return instrumented.NewPollRepository(&PollRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // Create inserts a new poll
@@ -50,10 +51,10 @@ func (r *PollRepository) GetByID(ctx context.Context, id int) (*models.Poll, err
 	var replyIds pq.Int64Array
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, title, score, author, poll_options, reply_ids, created_at 
+		`SELECT id, type, title, score, author, poll_options, reply_ids, created_at, extra 
 		 FROM polls WHERE id = $1`, id).Scan(
 		&poll.ID, &poll.Type, &poll.Title, &poll.Score,
-		&poll.Author, &pollOptions, &replyIds, &poll.Created_At)
+		&poll.Author, &pollOptions, &replyIds, &poll.Created_At, &poll.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +157,25 @@ func (r *PollRepository) GetByDateRange(ctx context.Context, start, end int64) (
 	return scanPolls(rows)
 }
 
+// GetByFilter retrieves polls matching all predicates of the filter
+func (r *PollRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) ([]*models.Poll, error) {
+	query, args := listQuery(filter, pollFilterColumns)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPolls(rows)
+}
+
+// Count returns the number of polls matching the filter, ignoring its limit and offset
+func (r *PollRepository) Count(ctx context.Context, filter repository.ItemFilter) (int, error) {
+	query, args := countQuery(filter, pollFilterColumns)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
 // UpdateScore updates poll score
 func (r *PollRepository) UpdateScore(ctx context.Context, id int, score int) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE polls SET score = $1 WHERE id = $2`, score, id)
@@ -200,19 +220,25 @@ func (r *PollRepository) CreateBatch(ctx context.Context, polls []*models.Poll)
 
 // CreateBatchWithExistingIDs creates multiple polls with existing IDs
 func (r *PollRepository) CreateBatchWithExistingIDs(ctx context.Context, polls []*models.Poll) error {
+	_, err := r.UpsertBatch(ctx, polls)
+	return err
+}
+
+// UpsertBatch upserts multiple polls and reports how many were new, changed or already up to date
+func (r *PollRepository) UpsertBatch(ctx context.Context, polls []*models.Poll) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO polls (id, type, title, score, author, poll_options, reply_ids, created_at) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET
-		 type = EXCLUDED.type, title = EXCLUDED.title, score = EXCLUDED.score, author = EXCLUDED.author,
-		 poll_options = EXCLUDED.poll_options, reply_ids = EXCLUDED.reply_ids, created_at = EXCLUDED.created_at`)
+		`INSERT INTO polls (id, type, title, score, author, poll_options, reply_ids, created_at, extra) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id)`+
+			upsertSet("polls", "type", "title", "score", "author", "poll_options", "reply_ids", "created_at", "extra"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 
@@ -226,13 +252,16 @@ func (r *PollRepository) CreateBatchWithExistingIDs(ctx context.Context, polls [
 		for i, v := range poll.Reply_Ids {
 			replyIds[i] = int64(v)
 		}
-		_, err := stmt.ExecContext(ctx, poll.ID, poll.Type, poll.Title, poll.Score,
-			poll.Author, pollOptions, replyIds, poll.Created_At)
+		err := execUpsert(ctx, stmt, &counts, poll.ID, poll.Type, poll.Title, poll.Score,
+			poll.Author, pollOptions, replyIds, poll.Created_At, poll.Extra)
 		if err != nil {
-			return err
+			return repository.UpsertCounts{}, err
 		}
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
 }
 
 // DeleteByAuthor deletes all polls by author
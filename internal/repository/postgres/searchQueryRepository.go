@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// SearchQueryRepository implements repository.SearchQueryRepository
+type SearchQueryRepository struct {
+	db *sql.DB
+}
+
+// NewSearchQueryRepository creates a new SearchQueryRepository instance
+func NewSearchQueryRepository() repository.SearchQueryRepository {
+	return instrumented.NewSearchQueryRepository(&SearchQueryRepository{
+		db: database.GetDB(),
+	})
+}
+
+// Record stores a search request and sets its ID
+func (r *SearchQueryRepository) Record(ctx context.Context, q *models.SearchQuery) error {
+	filters, err := json.Marshal(q.Filters)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO search_queries (tenant, query_key, query, filters, hits, latency_ms, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		q.Tenant, q.Query_Key, q.Query, filters, q.Hits, q.Latency_Ms, q.Created_At).Scan(&q.ID)
+}
+
+// GetTopQueries returns the most frequent queries searched since the given time (unix seconds).
+// An empty tenant matches every tenant.
+func (r *SearchQueryRepository) GetTopQueries(ctx context.Context, tenant string, since int64, limit int) ([]*models.QueryStat, error) {
+	return r.queryStats(ctx, ``, `COUNT(*) DESC`, tenant, since, limit)
+}
+
+// GetZeroResultQueries returns the queries that most often found nothing since the given time
+// (unix seconds). An empty tenant matches every tenant.
+func (r *SearchQueryRepository) GetZeroResultQueries(ctx context.Context, tenant string, since int64, limit int) ([]*models.QueryStat, error) {
+	return r.queryStats(ctx, ` HAVING COUNT(*) FILTER (WHERE hits = 0) > 0`,
+		`COUNT(*) FILTER (WHERE hits = 0) DESC, COUNT(*) DESC`, tenant, since, limit)
+}
+
+// queryStats aggregates the recorded searches per query key
+func (r *SearchQueryRepository) queryStats(ctx context.Context, having, order, tenant string, since int64, limit int) ([]*models.QueryStat, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT (array_agg(query ORDER BY created_at DESC))[1], COUNT(*), COUNT(*) FILTER (WHERE hits = 0),
+		 AVG(hits), AVG(latency_ms)
+		 FROM search_queries WHERE created_at >= $1 AND ($2 = '' OR tenant = $2)
+		 GROUP BY query_key`+having+` ORDER BY `+order+` LIMIT $3`, since, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*models.QueryStat
+	for rows.Next() {
+		stat := &models.QueryStat{}
+		if err := rows.Scan(&stat.Query, &stat.Count, &stat.Zero_Results, &stat.Avg_Hits, &stat.Avg_Latency_Ms); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
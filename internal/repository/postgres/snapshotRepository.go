@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// SnapshotRepository implements repository.SnapshotRepository
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewSnapshotRepository creates a new SnapshotRepository instance
+func NewSnapshotRepository() repository.SnapshotRepository {
+	return instrumented.NewSnapshotRepository(&SnapshotRepository{
+		db: database.GetDB(),
+	})
+}
+
+// snapshotColumns selects the snapshot fields of each kind's relation, in SnapshotItem order
+var snapshotColumns = map[string]string{
+	"story":   `id, title, url, '', score, author, created_at, 0, comments_count FROM stories`,
+	"ask":     `id, title, '', text, score, author, created_at, 0, replies_count FROM asks`,
+	"job":     `id, title, url, text, score, author, created_at, 0, 0 FROM jobs`,
+	"comment": `id, '', '', text, 0, author, created_at, COALESCE(parent_id, 0), 0 FROM comments_all`,
+	"poll":    `id, title, '', '', score, author, created_at, 0, cardinality(reply_ids) FROM polls`,
+}
+
+// GetSnapshotItems returns up to limit items of a kind and tenant created in [start, end),
+// below the spam threshold, that come after the (afterCreatedAt, afterID) cursor in creation
+// order. Authors are returned as stored.
+func (r *SnapshotRepository) GetSnapshotItems(ctx context.Context, kind, tenant string, start, end int64, maxSpamScore float64,
+	afterCreatedAt int64, afterID, limit int) ([]*models.SnapshotItem, error) {
+	columns, ok := snapshotColumns[kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+columns+`
+		 WHERE tenant = $1 AND created_at >= $2 AND created_at < $3 AND spam_score < $4
+		   AND (created_at, id) > ($5, $6)
+		 ORDER BY created_at, id LIMIT $7`,
+		tenant, start, end, maxSpamScore, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.SnapshotItem
+	for rows.Next() {
+		item := &models.SnapshotItem{Kind: kind}
+		var title, url, text, author sql.NullString
+		if err := rows.Scan(&item.ID, &title, &url, &text, &item.Score, &author, &item.Created_At,
+			&item.Parent, &item.Descendants); err != nil {
+			return nil, err
+		}
+		item.Title, item.URL, item.Text, item.Author = title.String, url.String, text.String, author.String
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
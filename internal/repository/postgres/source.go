@@ -0,0 +1,11 @@
+package postgres
+
+import models "internship-project/internal/models"
+
+// sourceOrDefault maps an unset item source to HackerNews, the original feed
+func sourceOrDefault(source string) string {
+	if source == "" {
+		return models.SourceHackerNews
+	}
+	return source
+}
@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+
+	"github.com/lib/pq"
+)
+
+// StatsRepository implements repository.StatsRepository
+type StatsRepository struct {
+	db *sql.DB
+}
+
+// NewStatsRepository creates a new StatsRepository instance
+func NewStatsRepository() repository.StatsRepository {
+	return instrumented.NewStatsRepository(&StatsRepository{
+		db: database.GetDB(),
+	})
+}
+
+// GetAuthorHeatmap aggregates an author's items in the tenant by weekday and hour of creation (UTC)
+func (r *StatsRepository) GetAuthorHeatmap(ctx context.Context, tenant, author string) (*models.ActivityHeatmap, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT EXTRACT(DOW FROM to_timestamp(created_at) AT TIME ZONE 'UTC')::int AS day_of_week,
+		        EXTRACT(HOUR FROM to_timestamp(created_at) AT TIME ZONE 'UTC')::int AS hour,
+		        COUNT(*)
+		 FROM (
+			SELECT created_at FROM stories WHERE author = $1 AND tenant = $2
+			UNION ALL SELECT created_at FROM asks WHERE author = $1 AND tenant = $2
+			UNION ALL SELECT created_at FROM jobs WHERE author = $1 AND tenant = $2
+			UNION ALL SELECT created_at FROM comments WHERE author = $1 AND tenant = $2
+			UNION ALL SELECT created_at FROM polls WHERE author = $1 AND tenant = $2
+		 ) AS activity
+		 GROUP BY day_of_week, hour
+		 ORDER BY day_of_week, hour`, author, tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	heatmap := &models.ActivityHeatmap{Author: author, Cells: []models.HeatmapCell{}}
+	for rows.Next() {
+		var cell models.HeatmapCell
+		if err := rows.Scan(&cell.DayOfWeek, &cell.Hour, &cell.Count); err != nil {
+			return nil, err
+		}
+		heatmap.Total += cell.Count
+		heatmap.Cells = append(heatmap.Cells, cell)
+	}
+	return heatmap, rows.Err()
+}
+
+// GetNewestItemID returns the highest HackerNews item ID stored in any item table (0 when empty)
+func (r *StatsRepository) GetNewestItemID(ctx context.Context) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT GREATEST(
+			(SELECT COALESCE(MAX(id), 0) FROM stories WHERE source = $1),
+			(SELECT COALESCE(MAX(id), 0) FROM asks WHERE source = $1),
+			(SELECT COALESCE(MAX(id), 0) FROM jobs WHERE source = $1),
+			(SELECT COALESCE(MAX(id), 0) FROM comments WHERE source = $1),
+			(SELECT COALESCE(MAX(id), 0) FROM polls WHERE source = $1),
+			(SELECT COALESCE(MAX(id), 0) FROM poll_options WHERE source = $1)
+		)`, models.SourceHackerNews).Scan(&id)
+	return id, err
+}
+
+// freshnessKinds are the item kinds in the order GetItemFreshness reports them
+var freshnessKinds = []string{"story", "ask", "job", "comment", "poll", "pollopt"}
+
+// GetItemFreshness returns the newest creation time and last write of every item table
+func (r *StatsRepository) GetItemFreshness(ctx context.Context) ([]*models.ItemFreshness, error) {
+	freshness := make([]*models.ItemFreshness, 0, len(freshnessKinds))
+	for _, kind := range freshnessKinds {
+		f := &models.ItemFreshness{Kind: kind}
+		err := r.db.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(created_at), 0), COALESCE(MAX(updated_at), 0) FROM `+kindTables[kind]).
+			Scan(&f.Newest_Created_At, &f.Last_Updated_At)
+		if err != nil {
+			return nil, err
+		}
+		freshness = append(freshness, f)
+	}
+	return freshness, nil
+}
+
+// dayItemsQuery selects every item created in [$1, $2) with the columns the daily aggregates need
+const dayItemsQuery = `
+	SELECT tenant, type, score, author, url FROM stories WHERE created_at >= $1 AND created_at < $2
+	UNION ALL SELECT tenant, type, score, author, NULL FROM asks WHERE created_at >= $1 AND created_at < $2
+	UNION ALL SELECT tenant, type, score, author, url FROM jobs WHERE created_at >= $1 AND created_at < $2
+	UNION ALL SELECT tenant, type, NULL, author, NULL FROM comments WHERE created_at >= $1 AND created_at < $2
+	UNION ALL SELECT tenant, type, score, author, NULL FROM polls WHERE created_at >= $1 AND created_at < $2`
+
+// RefreshDailyStats recomputes the daily aggregate tables of every tenant for the UTC day containing day.
+// The day's rows are replaced in one transaction, so readers never see a partial refresh.
+func (r *StatsRepository) RefreshDailyStats(ctx context.Context, day time.Time, topN int) error {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.Add(24 * time.Hour)
+	date := start.Format(time.DateOnly)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"daily_stats", "daily_top_domains", "daily_top_authors"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE day = $1`, date); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO daily_stats (tenant, day, type, item_count, median_score)
+		 SELECT tenant, $3::date, type, COUNT(*), percentile_cont(0.5) WITHIN GROUP (ORDER BY score)
+		 FROM (`+dayItemsQuery+`) AS items
+		 GROUP BY tenant, type`, start.Unix(), end.Unix(), date)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO daily_top_domains (tenant, day, rank, domain, item_count)
+		 SELECT tenant, $3::date, rank, domain, item_count FROM (
+			SELECT tenant, domain, COUNT(*) AS item_count,
+			       ROW_NUMBER() OVER (PARTITION BY tenant ORDER BY COUNT(*) DESC, domain) AS rank
+			FROM (
+				SELECT tenant, LOWER(substring(url from '://(?:www\.)?([^/:?#]+)')) AS domain
+				FROM (`+dayItemsQuery+`) AS items
+			) AS domains
+			WHERE domain IS NOT NULL
+			GROUP BY tenant, domain
+		 ) AS ranked
+		 WHERE rank <= $4`, start.Unix(), end.Unix(), date, topN)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO daily_top_authors (tenant, day, rank, author, item_count)
+		 SELECT tenant, $3::date, rank, author, item_count FROM (
+			SELECT tenant, author, COUNT(*) AS item_count,
+			       ROW_NUMBER() OVER (PARTITION BY tenant ORDER BY COUNT(*) DESC, author) AS rank
+			FROM (`+dayItemsQuery+`) AS items
+			GROUP BY tenant, author
+		 ) AS ranked
+		 WHERE rank <= $4`, start.Unix(), end.Unix(), date, topN)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDailyStats returns the precomputed aggregates of a tenant for each day in [from, to], oldest first.
+// Days without any items are omitted.
+func (r *StatsRepository) GetDailyStats(ctx context.Context, tenant string, from, to time.Time) ([]*models.DailyStats, error) {
+	fromDate := from.UTC().Format(time.DateOnly)
+	toDate := to.UTC().Format(time.DateOnly)
+
+	var days []*models.DailyStats
+	byDay := make(map[string]*models.DailyStats)
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT to_char(day, 'YYYY-MM-DD'), type, item_count, median_score
+		 FROM daily_stats
+		 WHERE tenant = $1 AND day BETWEEN $2 AND $3
+		 ORDER BY day, type`, tenant, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day string
+		var stats models.TypeStats
+		var median sql.NullFloat64
+		if err := rows.Scan(&day, &stats.Type, &stats.ItemCount, &median); err != nil {
+			return nil, err
+		}
+		if median.Valid {
+			stats.MedianScore = &median.Float64
+		}
+
+		daily, ok := byDay[day]
+		if !ok {
+			daily = &models.DailyStats{
+				Day:        day,
+				Types:      []models.TypeStats{},
+				TopDomains: []models.RankedCount{},
+				TopAuthors: []models.RankedCount{},
+			}
+			byDay[day] = daily
+			days = append(days, daily)
+		}
+		daily.Types = append(daily.Types, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.appendRanked(ctx, byDay, "daily_top_domains", "domain", tenant, fromDate, toDate,
+		func(d *models.DailyStats, c models.RankedCount) { d.TopDomains = append(d.TopDomains, c) }); err != nil {
+		return nil, err
+	}
+	if err := r.appendRanked(ctx, byDay, "daily_top_authors", "author", tenant, fromDate, toDate,
+		func(d *models.DailyStats, c models.RankedCount) { d.TopAuthors = append(d.TopAuthors, c) }); err != nil {
+		return nil, err
+	}
+
+	percentiles, err := r.GetScorePercentiles(ctx, tenant, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range percentiles {
+		if daily, ok := byDay[p.Day]; ok {
+			daily.ScorePercentiles = p
+		}
+	}
+
+	return days, nil
+}
+
+// appendRanked loads a daily top-N table and adds each entry to its day through add
+func (r *StatsRepository) appendRanked(
+	ctx context.Context,
+	byDay map[string]*models.DailyStats,
+	table, column, tenant, fromDate, toDate string,
+	add func(*models.DailyStats, models.RankedCount),
+) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT to_char(day, 'YYYY-MM-DD'), rank, `+column+`, item_count
+		 FROM `+table+`
+		 WHERE tenant = $1 AND day BETWEEN $2 AND $3
+		 ORDER BY day, rank`, tenant, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day string
+		var entry models.RankedCount
+		if err := rows.Scan(&day, &entry.Rank, &entry.Name, &entry.ItemCount); err != nil {
+			return err
+		}
+		if daily, ok := byDay[day]; ok {
+			add(daily, entry)
+		}
+	}
+	return rows.Err()
+}
+
+// percentileFractions are the fractions of the cutoffs stored for every day, 0 to 1 by hundredths
+var percentileFractions = func() pq.Float64Array {
+	fractions := make(pq.Float64Array, 101)
+	for i := range fractions {
+		fractions[i] = float64(i) / 100
+	}
+	return fractions
+}()
+
+// RefreshScorePercentiles recomputes the story score distribution of every tenant for the UTC day
+// containing day, over the stories created in the windowDays days ending on it
+func (r *StatsRepository) RefreshScorePercentiles(ctx context.Context, day time.Time, windowDays int) error {
+	end := day.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	start := end.AddDate(0, 0, -windowDays)
+	date := end.AddDate(0, 0, -1).Format(time.DateOnly)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM daily_score_percentiles WHERE day = $1`, date); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO daily_score_percentiles (tenant, day, story_count, cutoffs)
+		 SELECT tenant, $3::date, COUNT(*), percentile_cont($4::float8[]) WITHIN GROUP (ORDER BY score)
+		 FROM stories
+		 WHERE created_at >= $1 AND created_at < $2
+		 GROUP BY tenant`, start.Unix(), end.Unix(), date, percentileFractions)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetScorePercentiles returns the story score distributions of a tenant for each day in [from, to],
+// oldest first. Days without stories in their window are omitted.
+func (r *StatsRepository) GetScorePercentiles(ctx context.Context, tenant string, from, to time.Time) ([]*models.ScorePercentiles, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT to_char(day, 'YYYY-MM-DD'), story_count, cutoffs
+		 FROM daily_score_percentiles
+		 WHERE tenant = $1 AND day BETWEEN $2 AND $3
+		 ORDER BY day`, tenant, from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var percentiles []*models.ScorePercentiles
+	for rows.Next() {
+		var day string
+		var stories int
+		var cutoffs pq.Float64Array
+		if err := rows.Scan(&day, &stories, &cutoffs); err != nil {
+			return nil, err
+		}
+		percentiles = append(percentiles, models.NewScorePercentiles(day, stories, cutoffs))
+	}
+	return percentiles, rows.Err()
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// StorageRepository implements repository.StorageRepository
+type StorageRepository struct {
+	db *sql.DB
+}
+
+// NewStorageRepository creates a new StorageRepository instance
+func NewStorageRepository() repository.StorageRepository {
+	return instrumented.NewStorageRepository(&StorageRepository{
+		db: database.GetDB(),
+	})
+}
+
+// GetDatabaseSize returns the size of the current database
+func (r *StorageRepository) GetDatabaseSize(ctx context.Context) (int64, error) {
+	var size int64
+	err := r.db.QueryRowContext(ctx, `SELECT pg_database_size(current_database())`).Scan(&size)
+	return size, err
+}
+
+// SaveSnapshots upserts the snapshots in one transaction
+func (r *StorageRepository) SaveSnapshots(ctx context.Context, snapshots []*models.StorageSnapshot) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO storage_snapshots (table_name, day, row_count, table_bytes, index_bytes)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (day, table_name) DO UPDATE
+		 SET row_count = EXCLUDED.row_count, table_bytes = EXCLUDED.table_bytes, index_bytes = EXCLUDED.index_bytes`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, s := range snapshots {
+		if _, err := stmt.ExecContext(ctx, s.Table, s.Day, s.Rows, s.TableBytes, s.IndexBytes); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSnapshots returns the snapshots from since, by day then table
+func (r *StorageRepository) GetSnapshots(ctx context.Context, since int64) ([]*models.StorageSnapshot, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT table_name, day, row_count, table_bytes, index_bytes FROM storage_snapshots
+		 WHERE day >= $1 ORDER BY day, table_name`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []*models.StorageSnapshot{}
+	for rows.Next() {
+		s := &models.StorageSnapshot{}
+		if err := rows.Scan(&s.Table, &s.Day, &s.Rows, &s.TableBytes, &s.IndexBytes); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// PruneSnapshots deletes the snapshots older than before
+func (r *StorageRepository) PruneSnapshots(ctx context.Context, before int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM storage_snapshots WHERE day < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -6,6 +6,7 @@ import (
 
 	models "internship-project/internal/models"
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 
 	"github.com/lib/pq"
@@ -18,9 +19,9 @@ type StoryRepository struct {
 
 // NewStoryRepository creates a new StoryRepository instance
 func NewStoryRepository() repository.StoryRepository {
-	return &StoryRepository{
+	return instrumented.NewStoryRepository(&StoryRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // Create inserts a new story
@@ -30,11 +31,14 @@ func (r *StoryRepository) Create(ctx context.Context, story *models.Story) error
 		CommentsIds[i] = int64(v)
 	}
 
+	if err := ensureMonthPartitions(ctx, r.db, "stories", story.Created_At); err != nil {
+		return err
+	}
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count, source) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 		story.ID, story.Type, story.Title, story.URL, story.Score,
-		story.Author, story.Created_At, CommentsIds, story.Comments_count)
+		story.Author, story.Created_At, CommentsIds, story.Comments_count, sourceOrDefault(story.Source))
 	return err
 }
 
@@ -44,10 +48,10 @@ func (r *StoryRepository) GetByID(ctx context.Context, id int) (*models.Story, e
 	var commentsIds pq.Int64Array
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source, extra 
 		 FROM stories WHERE id = $1`, id).Scan(
 		&story.ID, &story.Type, &story.Title, &story.URL, &story.Score,
-		&story.Author, &story.Created_At, &commentsIds, &story.Comments_count)
+		&story.Author, &story.Created_At, &commentsIds, &story.Comments_count, &story.Source, &story.Extra)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +88,7 @@ func (r *StoryRepository) Delete(ctx context.Context, id int) error {
 // GetAll retrieves all stories
 func (r *StoryRepository) GetAll(ctx context.Context) ([]*models.Story, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
 		 FROM stories ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
@@ -96,7 +100,7 @@ func (r *StoryRepository) GetAll(ctx context.Context) ([]*models.Story, error) {
 // GetRecent retrieves recent stories
 func (r *StoryRepository) GetRecent(ctx context.Context, limit int) ([]*models.Story, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
 		 FROM stories ORDER BY created_at DESC LIMIT $1`, limit)
 	if err != nil {
 		return nil, err
@@ -108,7 +112,7 @@ func (r *StoryRepository) GetRecent(ctx context.Context, limit int) ([]*models.S
 // GetByMinScore retrieves stories with minimum score
 func (r *StoryRepository) GetByMinScore(ctx context.Context, minScore int) ([]*models.Story, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
 		 FROM stories WHERE score >= $1 ORDER BY score DESC`, minScore)
 	if err != nil {
 		return nil, err
@@ -120,7 +124,7 @@ func (r *StoryRepository) GetByMinScore(ctx context.Context, minScore int) ([]*m
 // GetByAuthor retrieves stories by author
 func (r *StoryRepository) GetByAuthor(ctx context.Context, author string) ([]*models.Story, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
 		 FROM stories WHERE author = $1 ORDER BY created_at DESC`, author)
 	if err != nil {
 		return nil, err
@@ -132,7 +136,7 @@ func (r *StoryRepository) GetByAuthor(ctx context.Context, author string) ([]*mo
 // GetByDateRange retrieves stories within date range
 func (r *StoryRepository) GetByDateRange(ctx context.Context, start, end int64) ([]*models.Story, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count 
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
 		 FROM stories WHERE created_at BETWEEN $1 AND $2 ORDER BY created_at DESC`, start, end)
 	if err != nil {
 		return nil, err
@@ -141,6 +145,25 @@ func (r *StoryRepository) GetByDateRange(ctx context.Context, start, end int64)
 	return scanStories(rows)
 }
 
+// GetByFilter retrieves stories matching all predicates of the filter
+func (r *StoryRepository) GetByFilter(ctx context.Context, filter repository.ItemFilter) ([]*models.Story, error) {
+	query, args := listQuery(filter, storyFilterColumns)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStories(rows)
+}
+
+// Count returns the number of stories matching the filter, ignoring its limit and offset
+func (r *StoryRepository) Count(ctx context.Context, filter repository.ItemFilter) (int, error) {
+	query, args := countQuery(filter, storyFilterColumns)
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
 // UpdateScore updates story score
 func (r *StoryRepository) UpdateScore(ctx context.Context, id int, score int) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE stories SET score = $1 WHERE id = $2`, score, id)
@@ -168,9 +191,12 @@ func (r *StoryRepository) CreateBatch(ctx context.Context, stories []*models.Sto
 	}
 	defer tx.Rollback()
 
+	if err := ensureMonthPartitions(ctx, tx, "stories", storyCreatedAt(stories)...); err != nil {
+		return err
+	}
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id) DO UPDATE`)
+		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count, source) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (id, created_at) DO UPDATE`)
 	if err != nil {
 		return err
 	}
@@ -182,7 +208,7 @@ func (r *StoryRepository) CreateBatch(ctx context.Context, stories []*models.Sto
 			CommentsIds[i] = int64(v)
 		}
 		_, err := stmt.ExecContext(ctx, story.ID, story.Type, story.Title, story.URL,
-			story.Score, story.Author, story.Created_At, CommentsIds, story.Comments_count)
+			story.Score, story.Author, story.Created_At, CommentsIds, story.Comments_count, sourceOrDefault(story.Source))
 		if err != nil {
 			return err
 		}
@@ -190,21 +216,30 @@ func (r *StoryRepository) CreateBatch(ctx context.Context, stories []*models.Sto
 	return tx.Commit()
 }
 
+// CreateBatchWithExistingIDs upserts multiple stories
 func (r *StoryRepository) CreateBatchWithExistingIDs(ctx context.Context, stories []*models.Story) error {
+	_, err := r.UpsertBatch(ctx, stories)
+	return err
+}
+
+// UpsertBatch upserts multiple stories and reports how many were new, changed or already up to date
+func (r *StoryRepository) UpsertBatch(ctx context.Context, stories []*models.Story) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 
+	if err := ensureMonthPartitions(ctx, tx, "stories", storyCreatedAt(stories)...); err != nil {
+		return counts, err
+	}
 	stmt, err := tx.PrepareContext(ctx,
-		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count) 
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id) DO UPDATE SET
-		 type = EXCLUDED.type, title = EXCLUDED.title, url = EXCLUDED.url,
-		 score = EXCLUDED.score, author = EXCLUDED.author, created_at = EXCLUDED.created_at,
-		 comments_ids = EXCLUDED.comments_ids, comments_count = EXCLUDED.comments_count`)
+		`INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count, source, extra) 
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT (id, created_at)`+
+			upsertSet("stories", "type", "title", "url", "score", "author", "created_at", "comments_ids", "comments_count", "source", "extra"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 
@@ -213,13 +248,25 @@ func (r *StoryRepository) CreateBatchWithExistingIDs(ctx context.Context, storie
 		for i, v := range story.Comments_ids {
 			CommentsIds[i] = int64(v)
 		}
-		_, err := stmt.ExecContext(ctx, story.ID, story.Type, story.Title, story.URL,
-			story.Score, story.Author, story.Created_At, CommentsIds, story.Comments_count)
+		err := execUpsert(ctx, stmt, &counts, story.ID, story.Type, story.Title, story.URL,
+			story.Score, story.Author, story.Created_At, CommentsIds, story.Comments_count, sourceOrDefault(story.Source), story.Extra)
 		if err != nil {
-			return err
+			return repository.UpsertCounts{}, err
 		}
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
+}
+
+// storyCreatedAt returns the creation times of stories
+func storyCreatedAt(stories []*models.Story) []int64 {
+	times := make([]int64, len(stories))
+	for i, story := range stories {
+		times[i] = story.Created_At
+	}
+	return times
 }
 
 // DeleteByAuthor deletes all stories by author
@@ -243,6 +290,277 @@ func (r *StoryRepository) GetCount(ctx context.Context) (int, error) {
 	return count, err
 }
 
+// UpdateSpamScores sets the spam score of multiple stories
+func (r *StoryRepository) UpdateSpamScores(ctx context.Context, scores map[int]float64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE stories SET spam_score = $1 WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, score := range scores {
+		if _, err := stmt.ExecContext(ctx, score, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdateScorePercentiles sets the score percentile of multiple stories
+func (r *StoryRepository) UpdateScorePercentiles(ctx context.Context, percentiles map[int]int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE stories SET score_percentile = $1 WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, percentile := range percentiles {
+		if _, err := stmt.ExecContext(ctx, percentile, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetByIDs retrieves the tenant's stories with the given IDs in the order of ids, skipping missing ones.
+// An empty tenant matches every tenant.
+func (r *StoryRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*models.Story, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source 
+		 FROM stories WHERE id = ANY($1) AND ($2 = '' OR tenant = $2)`, pq.Array(ids), tenant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stories, err := scanStories(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Story, len(stories))
+	for _, story := range stories {
+		byID[story.ID] = story
+	}
+	ordered := make([]*models.Story, 0, len(stories))
+	for _, id := range ids {
+		if story, ok := byID[id]; ok {
+			ordered = append(ordered, story)
+		}
+	}
+	return ordered, nil
+}
+
+// GetRelated returns the tenant's stories sharing the URL domain or the author of the story, ask,
+// job or poll id, same domain first, then by score. It returns sql.ErrNoRows when id is unknown.
+func (r *StoryRepository) GetRelated(ctx context.Context, tenant string, id, limit int) ([]*models.Story, error) {
+	var author, domain string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT author, COALESCE(LOWER(substring(url from '://(?:www\.)?([^/:?#]+)')), '') FROM (
+			SELECT author, url FROM stories WHERE id = $1
+			UNION ALL SELECT author, NULL FROM asks WHERE id = $1
+			UNION ALL SELECT author, url FROM jobs WHERE id = $1
+			UNION ALL SELECT author, NULL FROM polls WHERE id = $1
+		 ) target LIMIT 1`, id).Scan(&author, &domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, type, title, url, score, author, created_at, comments_ids, comments_count, source FROM (
+			SELECT *, LOWER(substring(url from '://(?:www\.)?([^/:?#]+)')) AS domain FROM stories
+			WHERE id <> $1 AND ($4 = '' OR tenant = $4)
+		 ) candidates
+		 WHERE author = $2 OR ($3 <> '' AND domain = $3)
+		 ORDER BY (CASE WHEN $3 <> '' AND domain = $3 THEN 2 ELSE 0 END) + (CASE WHEN author = $2 THEN 1 ELSE 0 END) DESC,
+		 score DESC, created_at DESC LIMIT $5`, id, author, domain, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStories(rows)
+}
+
+// GetLinksToCheck returns the stories with a URL never checked or last checked before checkedBefore
+// (unix seconds), unchecked ones first
+func (r *StoryRepository) GetLinksToCheck(ctx context.Context, checkedBefore int64, limit int) ([]*models.LinkCheck, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, title, url FROM stories
+		 WHERE url <> '' AND (link_checked_at IS NULL OR link_checked_at < $1)
+		 ORDER BY link_checked_at NULLS FIRST, created_at DESC LIMIT $2`, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*models.LinkCheck
+	for rows.Next() {
+		link := &models.LinkCheck{}
+		if err := rows.Scan(&link.StoryID, &link.Title, &link.URL); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// UpdateLinkChecks records the outcome of URL checks; an empty archive URL keeps the stored one
+func (r *StoryRepository) UpdateLinkChecks(ctx context.Context, checks []*models.LinkCheck) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`UPDATE stories SET link_status = $2, link_status_code = $3, link_checked_at = $4,
+		 archive_url = COALESCE(NULLIF($5, ''), archive_url) WHERE id = $1`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, check := range checks {
+		if _, err := stmt.ExecContext(ctx, check.StoryID, check.Status, check.StatusCode, check.CheckedAt, check.ArchiveURL); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetDeadLinks returns the tenant's stories whose URL was found dead, most recently checked first.
+// An empty tenant matches every tenant.
+func (r *StoryRepository) GetDeadLinks(ctx context.Context, tenant string, limit int) ([]*models.LinkCheck, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, title, url, link_status, COALESCE(link_status_code, 0), link_checked_at, COALESCE(archive_url, '')
+		 FROM stories WHERE link_status = $1 AND ($2 = '' OR tenant = $2)
+		 ORDER BY link_checked_at DESC LIMIT $3`, models.LinkDead, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*models.LinkCheck
+	for rows.Next() {
+		link := &models.LinkCheck{}
+		if err := rows.Scan(&link.StoryID, &link.Title, &link.URL, &link.Status,
+			&link.StatusCode, &link.CheckedAt, &link.ArchiveURL); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetStoriesToArchive returns up to limit stories with a URL, a score of at least minScore and no
+// archive snapshot, never submitted to the Wayback Machine or last submitted before
+// requestedBefore (unix seconds), highest score first
+func (r *StoryRepository) GetStoriesToArchive(ctx context.Context, minScore int, requestedBefore int64, limit int) ([]*models.StoryArchive, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, url, score FROM stories
+		 WHERE url <> '' AND archive_url IS NULL AND score >= $1
+		   AND (archive_requested_at IS NULL OR archive_requested_at < $2)
+		 ORDER BY score DESC LIMIT $3`, minScore, requestedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var archives []*models.StoryArchive
+	for rows.Next() {
+		archive := &models.StoryArchive{}
+		if err := rows.Scan(&archive.StoryID, &archive.URL, &archive.Score); err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+	return archives, rows.Err()
+}
+
+// UpdateArchives records Wayback Machine submissions; an empty archive URL keeps the stored one
+func (r *StoryRepository) UpdateArchives(ctx context.Context, archives []*models.StoryArchive) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`UPDATE stories SET archive_requested_at = $2, archive_url = COALESCE(NULLIF($3, ''), archive_url) WHERE id = $1`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, archive := range archives {
+		if _, err := stmt.ExecContext(ctx, archive.StoryID, archive.RequestedAt, archive.ArchiveURL); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplaceDuplicates rebuilds the duplicate links of the stories created since the given time
+// (unix seconds): their previous links are dropped and duplicates are stored instead
+func (r *StoryRepository) ReplaceDuplicates(ctx context.Context, since int64, duplicates []*models.StoryDuplicate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM story_duplicates WHERE story_id IN (SELECT id FROM stories WHERE created_at >= $1)`, since); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO story_duplicates (story_id, canonical_id, canonical_url) VALUES ($1, $2, $3)
+		 ON CONFLICT (story_id) DO UPDATE SET canonical_id = EXCLUDED.canonical_id, canonical_url = EXCLUDED.canonical_url`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range duplicates {
+		if _, err := stmt.ExecContext(ctx, d.StoryID, d.CanonicalID, d.CanonicalURL); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetCanonicalIDs maps the stories among ids that have duplicates to the ID of their canonical story
+func (r *StoryRepository) GetCanonicalIDs(ctx context.Context, ids []int) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT story_id, canonical_id FROM story_duplicates WHERE story_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	canonical := make(map[int]int)
+	for rows.Next() {
+		var id, canonicalID int
+		if err := rows.Scan(&id, &canonicalID); err != nil {
+			return nil, err
+		}
+		canonical[id] = canonicalID
+	}
+	return canonical, rows.Err()
+}
+
 // Helper function to scan stories
 func scanStories(rows *sql.Rows) ([]*models.Story, error) {
 	var stories []*models.Story
@@ -251,7 +569,7 @@ func scanStories(rows *sql.Rows) ([]*models.Story, error) {
 		var commentsIds pq.Int64Array
 
 		err := rows.Scan(&story.ID, &story.Type, &story.Title, &story.URL,
-			&story.Score, &story.Author, &story.Created_At, &commentsIds, &story.Comments_count)
+			&story.Score, &story.Author, &story.Created_At, &commentsIds, &story.Comments_count, &story.Source)
 		if err != nil {
 			return nil, err
 		}
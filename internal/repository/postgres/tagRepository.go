@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// TagRepository implements repository.TagRepository
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new TagRepository instance
+func NewTagRepository() repository.TagRepository {
+	return instrumented.NewTagRepository(&TagRepository{
+		db: database.GetDB(),
+	})
+}
+
+// ReplaceTags sets the tags of an item in one transaction, removing the ones it no longer has
+func (r *TagRepository) ReplaceTags(ctx context.Context, kind string, id int, tags []string) error {
+	if _, ok := kindTables[kind]; !ok {
+		return ErrUnknownKind
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM item_tags WHERE kind = $1 AND item_id = $2 AND NOT (tag = ANY($3))`,
+		kind, id, pq.Array(tags)); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO item_tags (kind, item_id, tag) SELECT $1, $2, unnest($3::text[])
+			 ON CONFLICT DO NOTHING`,
+			kind, id, pq.Array(tags)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetTagCounts returns the tenant's most used tags with the number of items carrying them
+func (r *TagRepository) GetTagCounts(ctx context.Context, tenant string, limit int) ([]*models.TagCount, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT t.tag, COUNT(*) AS items FROM item_tags t JOIN (
+			SELECT id, 'story' AS kind, tenant FROM stories
+			UNION ALL
+			SELECT id, 'ask' AS kind, tenant FROM asks
+			UNION ALL
+			SELECT id, 'job' AS kind, tenant FROM jobs
+			UNION ALL
+			SELECT id, 'poll' AS kind, tenant FROM polls
+		) AS i ON i.kind = t.kind AND i.id = t.item_id
+		 WHERE i.tenant = $1
+		 GROUP BY t.tag ORDER BY items DESC, t.tag LIMIT $2`, tenant, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.TagCount{}
+	for rows.Next() {
+		tag := &models.TagCount{}
+		if err := rows.Scan(&tag.Tag, &tag.Items); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
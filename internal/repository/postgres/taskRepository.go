@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// TaskRepository implements repository.TaskRepository
+type TaskRepository struct {
+	db *sql.DB
+}
+
+// NewTaskRepository creates a new TaskRepository instance
+func NewTaskRepository() repository.TaskRepository {
+	return instrumented.NewTaskRepository(&TaskRepository{
+		db: database.GetDB(),
+	})
+}
+
+// taskColumns are the columns read by scanTask
+const taskColumns = `id, type, payload, status, attempts, max_attempts, run_after, last_error, created_at, updated_at`
+
+// Enqueue inserts a pending task with a new ID; a zero RunAfter runs it right away and a zero
+// MaxAttempts takes the table default
+func (r *TaskRepository) Enqueue(ctx context.Context, task *models.Task) error {
+	now := time.Now().Unix()
+	if task.RunAfter == 0 {
+		task.RunAfter = now
+	}
+	// Sent as text: lib/pq would encode []byte as bytea
+	payload := string(task.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO tasks (id, type, payload, max_attempts, run_after, created_at, updated_at)
+		 VALUES ($1, $2, $3, COALESCE(NULLIF($4, 0), 5), $5, $6, $6)
+		 RETURNING `+taskColumns,
+		database.NewUUIDv7(), task.Type, payload, task.MaxAttempts, task.RunAfter, now).Scan(taskFields(task)...)
+}
+
+// Claim locks the due task with SKIP LOCKED so concurrent workers claim different tasks
+func (r *TaskRepository) Claim(ctx context.Context, types []string, now, leaseUntil int64) (*models.Task, error) {
+	task := &models.Task{}
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE tasks SET status = 'running', attempts = attempts + 1, run_after = $3, updated_at = $2
+		 WHERE id = (
+			SELECT id FROM tasks
+			WHERE status IN ('pending', 'running') AND run_after <= $2 AND type = ANY($1)
+			ORDER BY run_after, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+taskColumns,
+		pq.Array(types), now, leaseUntil).Scan(taskFields(task)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Complete marks a task done
+func (r *TaskRepository) Complete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tasks SET status = 'done', last_error = '', updated_at = $2 WHERE id = $1`,
+		id, time.Now().Unix())
+	return err
+}
+
+// Retry makes a task pending again at runAfter with the error of its failed attempt
+func (r *TaskRepository) Retry(ctx context.Context, id string, lastError string, runAfter int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tasks SET status = 'pending', last_error = $2, run_after = $3, updated_at = $4 WHERE id = $1`,
+		id, lastError, runAfter, time.Now().Unix())
+	return err
+}
+
+// Fail marks a task failed with the error of its last attempt
+func (r *TaskRepository) Fail(ctx context.Context, id string, lastError string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE tasks SET status = 'failed', last_error = $2, updated_at = $3 WHERE id = $1`,
+		id, lastError, time.Now().Unix())
+	return err
+}
+
+// GetByID returns a task; it returns sql.ErrNoRows if there is none with that ID
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	task := &models.Task{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1`, id).Scan(taskFields(task)...)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// List returns the tasks newest first
+func (r *TaskRepository) List(ctx context.Context, status string, limit int) ([]*models.Task, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE $1 = '' OR status = $1 ORDER BY id DESC LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task := &models.Task{}
+		if err := rows.Scan(taskFields(task)...); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// taskFields returns the scan destinations of taskColumns
+func taskFields(task *models.Task) []interface{} {
+	return []interface{}{&task.ID, &task.Type, (*[]byte)(&task.Payload), &task.Status, &task.Attempts,
+		&task.MaxAttempts, &task.RunAfter, &task.LastError, &task.Created_At, &task.Updated_At}
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// TenantRepository implements repository.TenantRepository
+type TenantRepository struct {
+	db *sql.DB
+}
+
+// NewTenantRepository creates a new TenantRepository instance
+func NewTenantRepository() repository.TenantRepository {
+	return instrumented.NewTenantRepository(&TenantRepository{
+		db: database.GetDB(),
+	})
+}
+
+// Create inserts a new tenant
+func (r *TenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO tenants (name, api_key, requests_per_minute, retention_days,
+			max_saved_searches, max_watches, max_webhook_endpoints, created_at, sources, keywords)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9::TEXT[], '{}'), COALESCE($10::TEXT[], '{}'))`,
+		tenant.Name, tenant.API_Key, tenant.Requests_Per_Minute, tenant.Retention_Days,
+		tenant.Max_Saved_Searches, tenant.Max_Watches, tenant.Max_Webhook_Endpoints, tenant.Created_At,
+		pq.Array(tenant.Sources), pq.Array(tenant.Keywords))
+	return err
+}
+
+// GetByName retrieves a tenant by name
+func (r *TenantRepository) GetByName(ctx context.Context, name string) (*models.Tenant, error) {
+	return r.getOne(ctx, `WHERE name = $1`, name)
+}
+
+// GetByAPIKey retrieves the tenant owning the API key
+func (r *TenantRepository) GetByAPIKey(ctx context.Context, apiKey string) (*models.Tenant, error) {
+	return r.getOne(ctx, `WHERE api_key = $1`, apiKey)
+}
+
+// GetAll retrieves all tenants
+func (r *TenantRepository) GetAll(ctx context.Context) ([]*models.Tenant, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []*models.Tenant
+	for rows.Next() {
+		tenant := &models.Tenant{}
+		if err := rows.Scan(tenantFields(tenant)...); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
+
+// Delete removes a tenant by name; its items are left in place
+func (r *TenantRepository) Delete(ctx context.Context, name string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tenants WHERE name = $1`, name)
+	return err
+}
+
+// GetQuotaUsage counts the saved searches, watches and distinct webhook URLs of the tenant
+func (r *TenantRepository) GetQuotaUsage(ctx context.Context, name string) (*models.QuotaUsage, error) {
+	usage := &models.QuotaUsage{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT (SELECT count(*) FROM saved_searches WHERE tenant = $1),
+			count(*), count(DISTINCT webhook_url)
+		 FROM watches WHERE tenant = $1`, name).Scan(&usage.Saved_Searches, &usage.Watches, &usage.Webhook_Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// tenantColumns are the columns read by tenantFields
+const tenantColumns = `name, api_key, requests_per_minute, retention_days,
+	max_saved_searches, max_watches, max_webhook_endpoints, created_at, sources, keywords`
+
+// tenantFields returns the scan destinations of tenantColumns
+func tenantFields(tenant *models.Tenant) []interface{} {
+	return []interface{}{&tenant.Name, &tenant.API_Key, &tenant.Requests_Per_Minute, &tenant.Retention_Days,
+		&tenant.Max_Saved_Searches, &tenant.Max_Watches, &tenant.Max_Webhook_Endpoints, &tenant.Created_At,
+		(*pq.StringArray)(&tenant.Sources), (*pq.StringArray)(&tenant.Keywords)}
+}
+
+func (r *TenantRepository) getOne(ctx context.Context, where string, arg interface{}) (*models.Tenant, error) {
+	tenant := &models.Tenant{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+tenantColumns+` FROM tenants `+where, arg).Scan(tenantFields(tenant)...)
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// TimelineRepository implements repository.TimelineRepository
+type TimelineRepository struct {
+	db *sql.DB
+}
+
+// NewTimelineRepository creates a new TimelineRepository instance
+func NewTimelineRepository() repository.TimelineRepository {
+	return instrumented.NewTimelineRepository(&TimelineRepository{
+		db: database.GetDB(),
+	})
+}
+
+// timelineQuery merges the top-level item tables into one stream tagged by source table
+const timelineQuery = `
+	SELECT id, kind, title, url, score, author, created_at, source FROM (
+		SELECT id, 'story' AS kind, title, COALESCE(url, '') AS url, score, author, created_at, tenant, source FROM stories
+		UNION ALL
+		SELECT id, 'ask' AS kind, title, '' AS url, score, author, created_at, tenant, source FROM asks
+		UNION ALL
+		SELECT id, 'job' AS kind, title, COALESCE(url, '') AS url, score, author, created_at, tenant, source FROM jobs
+		UNION ALL
+		SELECT id, 'poll' AS kind, title, '' AS url, score, author, created_at, tenant, source FROM polls
+	) AS timeline
+	WHERE tenant = $1`
+
+// GetTimeline returns the tenant's items older than the cursor, newest first
+func (r *TimelineRepository) GetTimeline(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error) {
+	var rows *sql.Rows
+	var err error
+	if cursor.IsZero() {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+` ORDER BY created_at DESC, id DESC LIMIT $2`, tenant, limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+` AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4`,
+			tenant, cursor.Created_At, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTimelineItems(rows)
+}
+
+// feedFilter keeps the timeline items by the tenant's followed authors or matching its saved searches
+const feedFilter = `
+	AND (author IN (SELECT author FROM follows WHERE tenant = $1)
+		OR EXISTS (SELECT 1 FROM saved_searches s WHERE s.tenant = $1 AND title ILIKE '%' || s.query || '%'))`
+
+// GetFeed returns the tenant's personalized feed older than the cursor, newest first
+func (r *TimelineRepository) GetFeed(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error) {
+	var rows *sql.Rows
+	var err error
+	if cursor.IsZero() {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+feedFilter+` ORDER BY created_at DESC, id DESC LIMIT $2`, tenant, limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+feedFilter+` AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4`,
+			tenant, cursor.Created_At, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTimelineItems(rows)
+}
+
+// tagFilter keeps the timeline items carrying the tag in $2
+const tagFilter = `
+	AND EXISTS (SELECT 1 FROM item_tags t WHERE t.kind = timeline.kind AND t.item_id = timeline.id AND t.tag = $2)`
+
+// GetByTag returns the tenant's items carrying the tag older than the cursor, newest first
+func (r *TimelineRepository) GetByTag(ctx context.Context, tenant, tag string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error) {
+	var rows *sql.Rows
+	var err error
+	if cursor.IsZero() {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+tagFilter+` ORDER BY created_at DESC, id DESC LIMIT $3`, tenant, tag, limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx,
+			timelineQuery+tagFilter+` AND (created_at, id) < ($3, $4) ORDER BY created_at DESC, id DESC LIMIT $5`,
+			tenant, tag, cursor.Created_At, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTimelineItems(rows)
+}
+
+// newAuthorsQuery merges the stories and comments flagged as posted by new authors
+const newAuthorsQuery = `
+	SELECT id, kind, title, url, score, author, created_at, source, text FROM (
+		SELECT id, 'story' AS kind, title, COALESCE(url, '') AS url, score, author, created_at, tenant, source, '' AS text
+		FROM stories WHERE new_author
+		UNION ALL
+		SELECT id, 'comment' AS kind, '' AS title, '' AS url, 0 AS score, author, created_at, tenant, source, COALESCE(text, '') AS text
+		FROM comments_all WHERE new_author
+	) AS new_authors
+	WHERE tenant = $1`
+
+// GetNewAuthors returns the tenant's stories and comments by new authors older than the cursor, newest first
+func (r *TimelineRepository) GetNewAuthors(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error) {
+	var rows *sql.Rows
+	var err error
+	if cursor.IsZero() {
+		rows, err = r.db.QueryContext(ctx,
+			newAuthorsQuery+` ORDER BY created_at DESC, id DESC LIMIT $2`, tenant, limit)
+	} else {
+		rows, err = r.db.QueryContext(ctx,
+			newAuthorsQuery+` AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4`,
+			tenant, cursor.Created_At, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.TimelineItem
+	for rows.Next() {
+		item := &models.TimelineItem{}
+		err := rows.Scan(&item.ID, &item.Type, &item.Title, &item.URL,
+			&item.Score, &item.Author, &item.Created_At, &item.Source, &item.Text)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Helper function to scan timeline items
+func scanTimelineItems(rows *sql.Rows) ([]*models.TimelineItem, error) {
+	var items []*models.TimelineItem
+	for rows.Next() {
+		item := &models.TimelineItem{}
+		err := rows.Scan(&item.ID, &item.Type, &item.Title, &item.URL,
+			&item.Score, &item.Author, &item.Created_At, &item.Source)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
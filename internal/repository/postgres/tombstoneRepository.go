@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// TombstoneRepository implements repository.TombstoneRepository
+type TombstoneRepository struct {
+	db *sql.DB
+}
+
+// NewTombstoneRepository creates a new TombstoneRepository instance
+func NewTombstoneRepository() repository.TombstoneRepository {
+	return instrumented.NewTombstoneRepository(&TombstoneRepository{
+		db: database.GetDB(),
+	})
+}
+
+// tombstoneTables are the tables items are removed from, with the kind of their items; archived
+// comments are removed as well
+var tombstoneTables = []struct{ table, kind string }{
+	{"stories", "story"},
+	{"asks", "ask"},
+	{"jobs", "job"},
+	{"comments", "comment"},
+	{"comments_cold", "comment"},
+	{"polls", "poll"},
+	{"poll_options", "pollopt"},
+}
+
+// tombstoneColumns are the columns read by tombstoneFields
+const tombstoneColumns = `item_id, kind, tenant, reason, item, deleted_at, COALESCE(emitted_at, 0)`
+
+// Remove moves the stored items with the IDs into tombstones
+func (r *TombstoneRepository) Remove(ctx context.Context, ids []int, reason string) ([]*models.Tombstone, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.remove(ctx, reason, func(string) string { return `id = ANY($4)` }, pq.Array(ids))
+}
+
+// RemoveExpired moves up to limit items per table of the tenant created before createdBefore
+// into tombstones
+func (r *TombstoneRepository) RemoveExpired(ctx context.Context, tenant string, createdBefore int64, limit int) ([]*models.Tombstone, error) {
+	return r.remove(ctx, models.TombstoneRetention, func(table string) string {
+		return `id IN (SELECT id FROM ` + table + ` WHERE tenant = $4 AND created_at < $5
+		               ORDER BY created_at LIMIT $6)`
+	}, tenant, createdBefore, limit)
+}
+
+// remove deletes the rows of every item table matching the condition returned by where, whose
+// arguments are numbered from $4, and turns them into tombstones in the same transaction. A
+// removed row leaves a delete in the change log, which the table triggers only write for
+// inserts and updates.
+func (r *TombstoneRepository) remove(ctx context.Context, reason string, where func(table string) string, args ...interface{}) ([]*models.Tombstone, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	tombstones := []*models.Tombstone{}
+	for _, t := range tombstoneTables {
+		rows, err := tx.QueryContext(ctx,
+			`WITH removed AS (
+			     DELETE FROM `+t.table+` WHERE `+where(t.table)+` RETURNING *
+			 ), logged AS (
+			     INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at)
+			     SELECT $1, id, tenant, 'delete', $3 FROM removed
+			 )
+			 INSERT INTO item_tombstones (item_id, kind, tenant, reason, item, deleted_at)
+			 SELECT id, $1, tenant, $2, to_jsonb(removed), $3 FROM removed
+			 ON CONFLICT (item_id) DO UPDATE
+			 SET kind = EXCLUDED.kind, tenant = EXCLUDED.tenant, reason = EXCLUDED.reason,
+			     item = EXCLUDED.item, deleted_at = EXCLUDED.deleted_at, emitted_at = NULL
+			 RETURNING `+tombstoneColumns,
+			append([]interface{}{t.kind, reason, now}, args...)...)
+		if err != nil {
+			return nil, err
+		}
+		tombstones, err = scanTombstones(rows, tombstones)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tombstones, tx.Commit()
+}
+
+// GetPending returns up to limit tombstones not emitted yet, oldest first
+func (r *TombstoneRepository) GetPending(ctx context.Context, limit int) ([]*models.Tombstone, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+tombstoneColumns+` FROM item_tombstones
+		 WHERE emitted_at IS NULL ORDER BY deleted_at, item_id LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanTombstones(rows, []*models.Tombstone{})
+}
+
+// MarkEmitted records that the events of the tombstones were published
+func (r *TombstoneRepository) MarkEmitted(ctx context.Context, ids []int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE item_tombstones SET emitted_at = $2 WHERE item_id = ANY($1) AND emitted_at IS NULL`,
+		pq.Array(ids), time.Now().UnixMilli())
+	return err
+}
+
+// PruneEmitted deletes the tombstones emitted before the given time (unix milliseconds)
+func (r *TombstoneRepository) PruneEmitted(ctx context.Context, before int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM item_tombstones WHERE emitted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanTombstones appends the tombstones read from rows to tombstones and closes rows
+func scanTombstones(rows *sql.Rows, tombstones []*models.Tombstone) ([]*models.Tombstone, error) {
+	defer rows.Close()
+	for rows.Next() {
+		t := &models.Tombstone{}
+		if err := rows.Scan(&t.ID, &t.Kind, &t.Tenant, &t.Reason, (*[]byte)(&t.Item),
+			&t.Deleted_At, &t.Emitted_At); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, t)
+	}
+	return tombstones, rows.Err()
+}
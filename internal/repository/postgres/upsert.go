@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"internship-project/internal/repository"
+)
+
+// upsertSet returns the ON CONFLICT update of the columns of table, a guard that skips the
+// update when none of them changed and a RETURNING clause telling inserts from updates.
+// xmax is 0 only on row versions created by an insert.
+func upsertSet(table string, columns ...string) string {
+	set := make([]string, len(columns))
+	current := make([]string, len(columns))
+	excluded := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = col + " = EXCLUDED." + col
+		current[i] = table + "." + col
+		excluded[i] = "EXCLUDED." + col
+	}
+	return " DO UPDATE SET " + strings.Join(set, ", ") +
+		" WHERE (" + strings.Join(current, ", ") + ") IS DISTINCT FROM (" + strings.Join(excluded, ", ") + ")" +
+		" RETURNING (xmax = 0) AS inserted"
+}
+
+// execUpsert runs one row of a statement built with upsertSet and records its outcome in counts
+func execUpsert(ctx context.Context, stmt *sql.Stmt, counts *repository.UpsertCounts, args ...interface{}) error {
+	var inserted bool
+	err := stmt.QueryRowContext(ctx, args...).Scan(&inserted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// The conflict update was skipped by its guard: the row is unchanged
+		counts.Skipped++
+	case err != nil:
+		return err
+	case inserted:
+		counts.Inserted++
+	default:
+		counts.Updated++
+	}
+	return nil
+}
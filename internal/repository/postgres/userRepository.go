@@ -6,6 +6,7 @@ import (
 
 	models "internship-project/internal/models"
 	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
 	"internship-project/pkg/database"
 
 	"github.com/lib/pq"
@@ -18,9 +19,9 @@ type UserRepository struct {
 
 // NewUserRepository creates a new UserRepository instance
 func NewUserRepository() repository.UserRepository {
-	return &UserRepository{
+	return instrumented.NewUserRepository(&UserRepository{
 		db: database.GetDB(),
-	}
+	})
 }
 
 // Create inserts a new user
@@ -222,17 +223,24 @@ func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User)
 
 // CreateBatchWithExistingIDs creates multiple users with existing usernames
 func (r *UserRepository) CreateBatchWithExistingIDs(ctx context.Context, users []*models.User) error {
+	_, err := r.UpsertBatch(ctx, users)
+	return err
+}
+
+// UpsertBatch upserts multiple users and reports how many were new, changed or already up to date
+func (r *UserRepository) UpsertBatch(ctx context.Context, users []*models.User) (repository.UpsertCounts, error) {
+	var counts repository.UpsertCounts
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer tx.Rollback()
 	stmt, err := tx.PrepareContext(ctx,
 		`INSERT INTO users (username, karma, about, created_at, submitted_ids)
-			VALUES ($1, $2, $3, $4, $5) ON CONFLICT (username) DO UPDATE SET karma = EXCLUDED.karma, about = EXCLUDED.about, 
-				created_at = EXCLUDED.created_at,submitted_ids = EXCLUDED.submitted_ids; `)
+			VALUES ($1, $2, $3, $4, $5) ON CONFLICT (username)`+
+			upsertSet("users", "karma", "about", "created_at", "submitted_ids"))
 	if err != nil {
-		return err
+		return counts, err
 	}
 	defer stmt.Close()
 	for _, user := range users {
@@ -240,12 +248,15 @@ func (r *UserRepository) CreateBatchWithExistingIDs(ctx context.Context, users [
 		for i, v := range user.Submitted {
 			submittedIds[i] = int64(v)
 		}
-		_, err := stmt.ExecContext(ctx, user.Username, user.Karma, user.About, user.Created_At, submittedIds)
+		err := execUpsert(ctx, stmt, &counts, user.Username, user.Karma, user.About, user.Created_At, submittedIds)
 		if err != nil {
-			return err
+			return repository.UpsertCounts{}, err
 		}
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return repository.UpsertCounts{}, err
+	}
+	return counts, nil
 }
 
 // UpdateKarmaBatch updates karma for multiple users
@@ -287,6 +298,35 @@ func (r *UserRepository) GetSubmittedIDsByID(ctx context.Context, username strin
 	return result, nil
 }
 
+// GetKarmaChecks returns the stored profiles among usernames with the time of their last check,
+// by username; users not stored are left out
+func (r *UserRepository) GetKarmaChecks(ctx context.Context, usernames []string) (map[string]*models.KarmaCheck, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT username, karma, about, COALESCE(array_length(submitted_ids, 1), 0), COALESCE(karma_checked_at, 0)
+		 FROM users WHERE username = ANY($1)`, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*models.KarmaCheck)
+	for rows.Next() {
+		c := &models.KarmaCheck{}
+		if err := rows.Scan(&c.Username, &c.Karma, &c.About, &c.Submissions, &c.Checked_At); err != nil {
+			return nil, err
+		}
+		checks[c.Username] = c
+	}
+	return checks, rows.Err()
+}
+
+// MarkKarmaChecked records that the profiles of the users were fetched at checkedAt
+func (r *UserRepository) MarkKarmaChecked(ctx context.Context, usernames []string, checkedAt int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET karma_checked_at = $2 WHERE username = ANY($1)`, pq.Array(usernames), checkedAt)
+	return err
+}
+
 // GetSubmissionCount returns the count of submissions for a user
 func (r *UserRepository) GetSubmissionCount(ctx context.Context, username string) (int, error) {
 	var count int
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// WatchRepository implements repository.WatchRepository
+type WatchRepository struct {
+	db *sql.DB
+}
+
+// NewWatchRepository creates a new WatchRepository instance
+func NewWatchRepository() repository.WatchRepository {
+	return instrumented.NewWatchRepository(&WatchRepository{
+		db: database.GetDB(),
+	})
+}
+
+// watchedItems lists the kind, score and comment count of the items that can be watched
+const watchedItems = `
+	SELECT id, 'story' AS kind, score, comments_count AS comments FROM stories
+	UNION ALL
+	SELECT id, 'ask' AS kind, score, replies_count AS comments FROM asks
+	UNION ALL
+	SELECT id, 'job' AS kind, score, 0 AS comments FROM jobs
+	UNION ALL
+	SELECT id, 'poll' AS kind, score, cardinality(reply_ids) AS comments FROM polls`
+
+// Create adds a watch for the tenant, starting from the stored score and comment count of the
+// item, and sets its new ID, kind and state; it returns sql.ErrNoRows if the item is not stored
+func (r *WatchRepository) Create(ctx context.Context, tenant string, watch *models.Watch) error {
+	now := time.Now()
+	watch.Created_At = now.Unix()
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO watches (id, tenant, kind, item_id, webhook_url, score_delta, comments_delta, rank_delta,
+			last_score, last_comments, created_at)
+		 SELECT $8, $1, kind, id, $3, $4, $5, $6, score, comments, $7 FROM (`+watchedItems+`) AS items
+		 WHERE id = $2
+		 RETURNING id, kind, last_score, last_comments`,
+		tenant, watch.Item_ID, watch.Webhook_URL, watch.Score_Delta, watch.Comments_Delta, watch.Rank_Delta,
+		watch.Created_At, database.NewUUIDv7()).Scan(&watch.ID, &watch.Kind, &watch.Last.Score, &watch.Last.Comments)
+}
+
+// Delete removes one of the tenant's watches; it returns sql.ErrNoRows if there is none with that ID
+func (r *WatchRepository) Delete(ctx context.Context, tenant string, id string) error {
+	return deleteOne(r.db.ExecContext(ctx, `DELETE FROM watches WHERE tenant = $1 AND id = $2`, tenant, id))
+}
+
+// watchColumns are the columns read by scanWatches
+const watchColumns = `id, kind, item_id, webhook_url, score_delta, comments_delta, rank_delta,
+	last_score, last_comments, last_rank, created_at, notified_at`
+
+// GetByTenant returns the tenant's watches, oldest first
+func (r *WatchRepository) GetByTenant(ctx context.Context, tenant string) ([]*models.Watch, error) {
+	return r.query(ctx, `SELECT `+watchColumns+` FROM watches WHERE tenant = $1 ORDER BY id`, tenant)
+}
+
+// GetByItem returns every watch on an item
+func (r *WatchRepository) GetByItem(ctx context.Context, itemID int) ([]*models.Watch, error) {
+	return r.query(ctx, `SELECT `+watchColumns+` FROM watches WHERE item_id = $1 ORDER BY id`, itemID)
+}
+
+// GetRankWatches returns every watch on the front-page rank of its item
+func (r *WatchRepository) GetRankWatches(ctx context.Context) ([]*models.Watch, error) {
+	return r.query(ctx, `SELECT `+watchColumns+` FROM watches WHERE rank_delta > 0 ORDER BY id`)
+}
+
+// UpdateState records the values and time of a watch's latest notification
+func (r *WatchRepository) UpdateState(ctx context.Context, id string, state models.WatchState, notifiedAt int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE watches SET last_score = $2, last_comments = $3, last_rank = $4, notified_at = $5 WHERE id = $1`,
+		id, state.Score, state.Comments, state.Rank, notifiedAt)
+	return err
+}
+
+func (r *WatchRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Watch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watches := []*models.Watch{}
+	for rows.Next() {
+		w := &models.Watch{}
+		err := rows.Scan(&w.ID, &w.Kind, &w.Item_ID, &w.Webhook_URL, &w.Score_Delta, &w.Comments_Delta,
+			&w.Rank_Delta, &w.Last.Score, &w.Last.Comments, &w.Last.Rank, &w.Created_At, &w.Notified_At)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	models "internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/instrumented"
+	"internship-project/pkg/database"
+)
+
+// WebhookDeliveryRepository implements repository.WebhookDeliveryRepository
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookDeliveryRepository creates a new WebhookDeliveryRepository instance
+func NewWebhookDeliveryRepository() repository.WebhookDeliveryRepository {
+	return instrumented.NewWebhookDeliveryRepository(&WebhookDeliveryRepository{
+		db: database.GetDB(),
+	})
+}
+
+// deliveryColumns are the columns read by deliveryFields
+const deliveryColumns = `id, idempotency_key, watch_id, url, payload, status, attempts, response_code, last_error,
+	created_at, updated_at`
+
+// Create inserts a pending delivery with a new ID
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	// Sent as text: lib/pq would encode []byte as bytea
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO webhook_deliveries (id, idempotency_key, watch_id, url, payload, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $6)
+		 RETURNING `+deliveryColumns,
+		database.NewUUIDv7(), delivery.Idempotency_Key, delivery.Watch_ID, delivery.URL, string(delivery.Payload),
+		now.Unix()).Scan(deliveryFields(delivery)...)
+}
+
+// RecordAttempt counts an attempt and stores its response code and error
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, id string, responseCode int, lastError string) error {
+	status := models.DeliveryDelivered
+	if lastError != "" {
+		status = models.DeliveryFailed
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = $2, attempts = attempts + 1, response_code = $3, last_error = $4, updated_at = $5
+		 WHERE id = $1`,
+		id, status, responseCode, lastError, time.Now().Unix())
+	return err
+}
+
+// GetByID returns a delivery; it returns sql.ErrNoRows if there is none with that ID
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id).
+		Scan(deliveryFields(delivery)...)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// List returns the deliveries newest first
+func (r *WebhookDeliveryRepository) List(ctx context.Context, status string, limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE $1 = '' OR status = $1 ORDER BY id DESC LIMIT $2`,
+		status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{}
+		if err := rows.Scan(deliveryFields(delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// deliveryFields returns the scan destinations of deliveryColumns
+func deliveryFields(d *models.WebhookDelivery) []interface{} {
+	return []interface{}{&d.ID, &d.Idempotency_Key, &d.Watch_ID, &d.URL, (*[]byte)(&d.Payload), &d.Status,
+		&d.Attempts, &d.Response_Code, &d.Last_Error, &d.Created_At, &d.Updated_At}
+}
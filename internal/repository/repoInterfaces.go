@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	models "internship-project/internal/models"
 )
@@ -39,8 +40,13 @@ type UserRepository interface {
 	// Batch operations
 	CreateBatch(ctx context.Context, users []*models.User) error
 	CreateBatchWithExistingIDs(ctx context.Context, users []*models.User) error
+	UpsertBatch(ctx context.Context, users []*models.User) (UpsertCounts, error)
 	UpdateKarmaBatch(ctx context.Context, karmaUpdates map[int]int) error
 
+	// Profile checks of the update sync
+	GetKarmaChecks(ctx context.Context, usernames []string) (map[string]*models.KarmaCheck, error)
+	MarkKarmaChecked(ctx context.Context, usernames []string, checkedAt int64) error
+
 	// Submission related operations
 	GetSubmittedIDsByID(ctx context.Context, id string) ([]int, error)
 	GetSubmissionCount(ctx context.Context, id string) (int, error)
@@ -65,14 +71,30 @@ type StoryRepository interface {
 	GetByMinScore(ctx context.Context, minScore int) ([]*models.Story, error)
 	GetByAuthor(ctx context.Context, author string) ([]*models.Story, error)
 	GetByDateRange(ctx context.Context, start, end int64) ([]*models.Story, error)
+	GetByFilter(ctx context.Context, filter ItemFilter) ([]*models.Story, error)
+	GetByIDs(ctx context.Context, tenant string, ids []int) ([]*models.Story, error)
+	GetRelated(ctx context.Context, tenant string, id, limit int) ([]*models.Story, error)
+	Count(ctx context.Context, filter ItemFilter) (int, error)
 
 	// Update specific fields
 	UpdateScore(ctx context.Context, id int, score int) error
 	UpdateCommentsCount(ctx context.Context, id int, count int) error
+	UpdateSpamScores(ctx context.Context, scores map[int]float64) error
+	UpdateScorePercentiles(ctx context.Context, percentiles map[int]int) error
+
+	// Link checks
+	GetLinksToCheck(ctx context.Context, checkedBefore int64, limit int) ([]*models.LinkCheck, error)
+	UpdateLinkChecks(ctx context.Context, checks []*models.LinkCheck) error
+	GetDeadLinks(ctx context.Context, tenant string, limit int) ([]*models.LinkCheck, error)
+	GetStoriesToArchive(ctx context.Context, minScore int, requestedBefore int64, limit int) ([]*models.StoryArchive, error)
+	UpdateArchives(ctx context.Context, archives []*models.StoryArchive) error
+	ReplaceDuplicates(ctx context.Context, since int64, duplicates []*models.StoryDuplicate) error
+	GetCanonicalIDs(ctx context.Context, ids []int) (map[int]int, error)
 
 	// Batch operations
 	CreateBatch(ctx context.Context, stories []*models.Story) error
 	CreateBatchWithExistingIDs(ctx context.Context, stories []*models.Story) error
+	UpsertBatch(ctx context.Context, stories []*models.Story) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 }
 
@@ -90,9 +112,19 @@ type CommentRepository interface {
 	GetRecent(ctx context.Context, limit int) ([]*models.Comment, error)
 	GetByAuthor(ctx context.Context, author string) ([]*models.Comment, error)
 	GetByDateRange(ctx context.Context, start, end int64) ([]*models.Comment, error)
+	GetByFilter(ctx context.Context, filter ItemFilter) ([]*models.Comment, error)
+	Count(ctx context.Context, filter ItemFilter) (int, error)
+	GetThread(ctx context.Context, rootID, maxDepth int) ([]*models.Comment, error)
+	GetQualitySignals(ctx context.Context, createdSince int64) ([]*models.CommentSignals, error)
+	GetTextHashes(ctx context.Context, createdSince int64, limit int) (map[int]string, error)
+
+	// Update specific fields
+	UpdateSpamScores(ctx context.Context, scores map[int]float64) error
+	UpdateQuality(ctx context.Context, qualities []*models.CommentQuality) error
 
 	// Batch operations
 	CreateBatchWithExistingIDs(ctx context.Context, comments []*models.Comment) error
+	UpsertBatch(ctx context.Context, comments []*models.Comment) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 }
 
@@ -111,14 +143,18 @@ type AskRepository interface {
 	GetByMinScore(ctx context.Context, minScore int) ([]*models.Ask, error)
 	GetByAuthor(ctx context.Context, author string) ([]*models.Ask, error)
 	GetByDateRange(ctx context.Context, start, end int64) ([]*models.Ask, error)
+	GetByFilter(ctx context.Context, filter ItemFilter) ([]*models.Ask, error)
+	Count(ctx context.Context, filter ItemFilter) (int, error)
 
 	// Update specific fields
 	UpdateScore(ctx context.Context, id int, score int) error
 	UpdateRepliesCount(ctx context.Context, id int, count int) error
+	UpdateSpamScores(ctx context.Context, scores map[int]float64) error
 
 	// Batch operations
 	CreateBatch(ctx context.Context, asks []*models.Ask) error
 	CreateBatchWithExistingIDs(ctx context.Context, asks []*models.Ask) error
+	UpsertBatch(ctx context.Context, asks []*models.Ask) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 }
 
@@ -137,6 +173,8 @@ type JobRepository interface {
 	GetByMinScore(ctx context.Context, minScore int) ([]*models.Job, error)
 	GetByAuthor(ctx context.Context, author string) ([]*models.Job, error)
 	GetByDateRange(ctx context.Context, start, end int64) ([]*models.Job, error)
+	GetByFilter(ctx context.Context, filter ItemFilter) ([]*models.Job, error)
+	Count(ctx context.Context, filter ItemFilter) (int, error)
 
 	// Update specific fields
 	UpdateScore(ctx context.Context, id int, score int) error
@@ -144,6 +182,7 @@ type JobRepository interface {
 	// Batch operations
 	CreateBatch(ctx context.Context, jobs []*models.Job) error
 	CreateBatchWithExistingIDs(ctx context.Context, jobs []*models.Job) error
+	UpsertBatch(ctx context.Context, jobs []*models.Job) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 }
 
@@ -162,6 +201,8 @@ type PollRepository interface {
 	GetByMinScore(ctx context.Context, minScore int) ([]*models.Poll, error)
 	GetByAuthor(ctx context.Context, author string) ([]*models.Poll, error)
 	GetByDateRange(ctx context.Context, start, end int64) ([]*models.Poll, error)
+	GetByFilter(ctx context.Context, filter ItemFilter) ([]*models.Poll, error)
+	Count(ctx context.Context, filter ItemFilter) (int, error)
 
 	// Update specific fields
 	UpdateScore(ctx context.Context, id int, score int) error
@@ -169,6 +210,7 @@ type PollRepository interface {
 	// Batch operations
 	CreateBatch(ctx context.Context, polls []*models.Poll) error
 	CreateBatchWithExistingIDs(ctx context.Context, polls []*models.Poll) error
+	UpsertBatch(ctx context.Context, polls []*models.Poll) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 }
 
@@ -193,10 +235,283 @@ type PollOptionRepository interface {
 
 	// Update specific fields
 	UpdateVotes(ctx context.Context, id int, votes int) error
+	IncrementVotes(ctx context.Context, id int) error
+	DecrementVotes(ctx context.Context, id int) error
 
 	// Batch operations
 	CreateBatch(ctx context.Context, pollOptions []*models.PollOption) error
 	CreateBatchWithExistingIDs(ctx context.Context, pollOptions []*models.PollOption) error
+	UpsertBatch(ctx context.Context, pollOptions []*models.PollOption) (UpsertCounts, error)
 	DeleteByAuthor(ctx context.Context, author string) error
 	DeleteByPollID(ctx context.Context, pollID int) error
+	IncrementVotesBatch(ctx context.Context, deltas map[int]int) error
+}
+
+type TimelineRepository interface {
+	// GetTimeline returns items older than the cursor, newest first
+	GetTimeline(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error)
+	// GetFeed returns the timeline items by followed authors or matching saved searches, older than the cursor
+	GetFeed(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error)
+	// GetByTag returns the timeline items carrying the tag, older than the cursor
+	GetByTag(ctx context.Context, tenant, tag string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error)
+	// GetNewAuthors returns the stories and comments flagged as posted by new authors, older than the cursor
+	GetNewAuthors(ctx context.Context, tenant string, cursor models.TimelineCursor, limit int) ([]*models.TimelineItem, error)
+}
+
+type StatsRepository interface {
+	// GetAuthorHeatmap aggregates an author's items by weekday and hour of creation
+	GetAuthorHeatmap(ctx context.Context, tenant, author string) (*models.ActivityHeatmap, error)
+
+	// GetNewestItemID returns the highest HackerNews item ID stored in any item table (0 when empty)
+	GetNewestItemID(ctx context.Context) (int, error)
+
+	// GetItemFreshness returns, for every item kind, the creation time of its newest item and
+	// the time its table was last written
+	GetItemFreshness(ctx context.Context) ([]*models.ItemFreshness, error)
+
+	// RefreshDailyStats recomputes the daily aggregate tables of every tenant for the UTC day containing day
+	RefreshDailyStats(ctx context.Context, day time.Time, topN int) error
+
+	// GetDailyStats returns the precomputed aggregates of a tenant for each day in [from, to]
+	GetDailyStats(ctx context.Context, tenant string, from, to time.Time) ([]*models.DailyStats, error)
+
+	// RefreshScorePercentiles recomputes the story score distribution of every tenant for the UTC day
+	// containing day, over the stories created in the windowDays days ending on it
+	RefreshScorePercentiles(ctx context.Context, day time.Time, windowDays int) error
+
+	// GetScorePercentiles returns the story score distributions of a tenant for each day in [from, to]
+	GetScorePercentiles(ctx context.Context, tenant string, from, to time.Time) ([]*models.ScorePercentiles, error)
+}
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	GetByName(ctx context.Context, name string) (*models.Tenant, error)
+	GetByAPIKey(ctx context.Context, apiKey string) (*models.Tenant, error)
+	GetAll(ctx context.Context) ([]*models.Tenant, error)
+	Delete(ctx context.Context, name string) error
+	// GetQuotaUsage counts the saved searches, watches and distinct webhook URLs of the tenant
+	GetQuotaUsage(ctx context.Context, name string) (*models.QuotaUsage, error)
+}
+
+type ItemRepository interface {
+	// GetKind returns the kind of the stored item with the given ID, or sql.ErrNoRows
+	GetKind(ctx context.Context, id int) (string, error)
+	// GetKinds returns the kinds of the stored items among ids, by ID
+	GetKinds(ctx context.Context, ids []int) (map[int]string, error)
+	// GetIDs returns up to limit IDs of the items of the kinds matching the filter
+	GetIDs(ctx context.Context, kinds []string, filter ItemFilter, limit int) ([]int, error)
+	// ScanIDs returns up to limit IDs of stored items of any kind greater than afterID, in ascending order
+	ScanIDs(ctx context.Context, afterID, limit int) ([]int, error)
+	// CountExtraKeys counts the stored items of the kind by name of their extra attributes, to
+	// tell which unknown API fields are worth a column
+	CountExtraKeys(ctx context.Context, kind string) (map[string]int64, error)
+	// MarkNewAuthor flags a story or comment posted from an account younger than accountAge or as
+	// its author's first submission, and returns the flag
+	MarkNewAuthor(ctx context.Context, kind string, id int, accountAge time.Duration) (bool, error)
+}
+
+type DiagnosticsRepository interface {
+	// ExplainableQueries lists the names accepted by ExplainQuery
+	ExplainableQueries() []string
+
+	// ExplainQuery runs EXPLAIN (ANALYZE, BUFFERS) on the named query built from the filter
+	ExplainQuery(ctx context.Context, name string, filter ItemFilter) (*models.QueryPlan, error)
+}
+
+type FollowRepository interface {
+	Follow(ctx context.Context, tenant, author string) (*models.Follow, error)
+	Unfollow(ctx context.Context, tenant, author string) error
+	GetFollows(ctx context.Context, tenant string) ([]*models.Follow, error)
+
+	SaveSearch(ctx context.Context, tenant, query string) (*models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, tenant string, id int) error
+	GetSavedSearches(ctx context.Context, tenant string) ([]*models.SavedSearch, error)
+}
+
+type DataQualityRepository interface {
+	RunChecks(ctx context.Context, sampleSize int) ([]models.DataQualityCheck, error)
+	CountItems(ctx context.Context, kind string) (int64, error)
+	CountItemsInRange(ctx context.Context, kind string, start, end int64) (int64, error)
+	GetItemIDs(ctx context.Context, kind string, start, end int64, afterID, limit int) ([]int, error)
+	SampleItemIDs(ctx context.Context, kind string, start, end int64, n int) ([]int, error)
+	GetItemDocuments(ctx context.Context, kind string, ids []int) (map[int]map[string]interface{}, error)
+	SaveReport(ctx context.Context, report *models.DataQualityReport) error
+	GetLatestReport(ctx context.Context) (*models.DataQualityReport, error)
+}
+
+type ChangeRepository interface {
+	GetUpdatedSince(ctx context.Context, kind string, since models.ItemChange, limit int) ([]models.ItemChange, error)
+
+	// Change log
+	SequenceChanges(ctx context.Context, limit int) (int, error)
+	GetChangesSince(ctx context.Context, tenant string, since int64, limit int) ([]*models.SequencedChange, error)
+	GetChangeLogBounds(ctx context.Context) (first, last int64, err error)
+	PruneChanges(ctx context.Context, before int64) (int64, error)
+	GetItemHistory(ctx context.Context, kind string, id int, after int64) ([]*models.ItemRevision, error)
+}
+
+type SearchQueryRepository interface {
+	Record(ctx context.Context, q *models.SearchQuery) error
+	GetTopQueries(ctx context.Context, tenant string, since int64, limit int) ([]*models.QueryStat, error)
+	GetZeroResultQueries(ctx context.Context, tenant string, since int64, limit int) ([]*models.QueryStat, error)
+}
+
+type TagRepository interface {
+	// ReplaceTags sets the tags of an item, removing the ones it no longer has
+	ReplaceTags(ctx context.Context, kind string, id int, tags []string) error
+	// GetTagCounts returns the most used tags with their item counts
+	GetTagCounts(ctx context.Context, tenant string, limit int) ([]*models.TagCount, error)
+}
+
+type MentionRepository interface {
+	// ReplaceMentions sets the users a comment mentions by @username plus the author of the item
+	// it replies to, removing the ones it no longer addresses; the comment author is left out
+	ReplaceMentions(ctx context.Context, comment *models.Comment, usernames []string) error
+	// GetByUsername returns the tenant's mentions of a user, newest first, after the cursor
+	GetByUsername(ctx context.Context, tenant, username string, cursor models.TimelineCursor, limit int) ([]*models.Mention, error)
+}
+
+type WatchRepository interface {
+	Create(ctx context.Context, tenant string, watch *models.Watch) error
+	Delete(ctx context.Context, tenant string, id string) error
+	GetByTenant(ctx context.Context, tenant string) ([]*models.Watch, error)
+	GetByItem(ctx context.Context, itemID int) ([]*models.Watch, error)
+	GetRankWatches(ctx context.Context) ([]*models.Watch, error)
+	UpdateState(ctx context.Context, id string, state models.WatchState, notifiedAt int64) error
+}
+
+type TaskRepository interface {
+	// Enqueue inserts a pending task, filling in its ID, status and timestamps
+	Enqueue(ctx context.Context, task *models.Task) error
+	// Claim marks the oldest due task of the types as running until leaseUntil and counts the
+	// attempt; running tasks whose lease expired are due again. It returns nil when none is due.
+	Claim(ctx context.Context, types []string, now, leaseUntil int64) (*models.Task, error)
+	Complete(ctx context.Context, id string) error
+	// Retry records a failed attempt and makes the task pending again at runAfter
+	Retry(ctx context.Context, id string, lastError string, runAfter int64) error
+	// Fail records the last failed attempt of a task
+	Fail(ctx context.Context, id string, lastError string) error
+	GetByID(ctx context.Context, id string) (*models.Task, error)
+	// List returns the most recent tasks with the status, or of any status when it is empty
+	List(ctx context.Context, status string, limit int) ([]*models.Task, error)
+}
+
+type ComputedFieldRepository interface {
+	// ReplaceFields sets the computed field values of an item, removing the fields it no longer has
+	ReplaceFields(ctx context.Context, kind string, id int, values map[string]float64) error
+	// GetFields returns the computed field values of an item by name
+	GetFields(ctx context.Context, kind string, id int) (map[string]float64, error)
+}
+
+type WebhookDeliveryRepository interface {
+	// Create records a pending delivery, filling in its ID, status and timestamps
+	Create(ctx context.Context, delivery *models.WebhookDelivery) error
+	// RecordAttempt counts an attempt with its outcome: delivered when lastError is empty, failed otherwise
+	RecordAttempt(ctx context.Context, id string, responseCode int, lastError string) error
+	GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	// List returns the most recent deliveries with the status, or of any status when it is empty
+	List(ctx context.Context, status string, limit int) ([]*models.WebhookDelivery, error)
+}
+
+type DeadLetterRepository interface {
+	// Record stores a payload rejected before persistence, filling in its ID, occurrences and
+	// timestamps; the same payload rejected again for an item counts one more occurrence
+	Record(ctx context.Context, letter *models.DeadLetter) error
+	// List returns the most recently rejected payloads of the kind, or of any kind when it is empty
+	List(ctx context.Context, kind string, limit int) ([]*models.DeadLetter, error)
+	// GetGone returns the IDs among ids the API served null for at least minOccurrences times
+	GetGone(ctx context.Context, ids []int, minOccurrences int) ([]int, error)
+}
+
+type ItemPayloadRepository interface {
+	// Save stores the payload of an item, replacing the one stored before
+	Save(ctx context.Context, payload *models.ItemPayload) error
+	// GetByID returns the stored payload of an item, or sql.ErrNoRows
+	GetByID(ctx context.Context, id int) (*models.ItemPayload, error)
+	// GetByIDs returns the stored payloads among ids, by item ID
+	GetByIDs(ctx context.Context, ids []int) (map[int]*models.ItemPayload, error)
+}
+
+type LinkPreviewRepository interface {
+	// GetLinksToPreview returns the stories with a URL and no preview, a preview for another URL,
+	// or one fetched before fetchedBefore (failedBefore when it failed; unix seconds)
+	GetLinksToPreview(ctx context.Context, fetchedBefore, failedBefore int64, limit int) ([]*models.LinkPreview, error)
+	SavePreviews(ctx context.Context, previews []*models.LinkPreview) error
+	// GetByStory returns the preview of a story; it returns sql.ErrNoRows if there is none
+	GetByStory(ctx context.Context, storyID int) (*models.LinkPreview, error)
+}
+
+type DomainRepository interface {
+	// RefreshStats recomputes the aggregates of every domain stories link to, dropping the domains
+	// no story links to anymore
+	RefreshStats(ctx context.Context) error
+	// GetFaviconsToCheck returns the domains whose favicon was not looked up since checkedBefore
+	// (unix seconds), never looked up first
+	GetFaviconsToCheck(ctx context.Context, checkedBefore int64, limit int) ([]string, error)
+	// SetFavicon records that the favicon of a domain was looked up; an empty URL keeps the stored one
+	SetFavicon(ctx context.Context, domain, faviconURL string, checkedAt int64) error
+	// GetByDomain returns a domain; it returns sql.ErrNoRows if no stored story links to it
+	GetByDomain(ctx context.Context, domain string) (*models.Domain, error)
+}
+
+type CommentArchiveRepository interface {
+	// Archive moves up to limit comments created before createdBefore (unix seconds), oldest
+	// first, from the hot table to the cold one, creating the monthly partitions they need. It
+	// returns how many were moved.
+	Archive(ctx context.Context, createdBefore int64, limit int) (int, error)
+}
+
+type PartitionRepository interface {
+	// EnsurePartitions creates the missing monthly partitions of a table partitioned by created_at
+	// from the month of from to the month of to (unix seconds), returning how many it created
+	EnsurePartitions(ctx context.Context, table string, from, to int64) (int, error)
+}
+
+type MaintenanceRepository interface {
+	// GetTableStats returns the statistics of the tables, skipping the ones that do not exist
+	GetTableStats(ctx context.Context, tables []string) ([]*models.TableStats, error)
+	// Analyze gathers the planner statistics of a table and its partitions
+	Analyze(ctx context.Context, table string) error
+	// Vacuum reclaims the dead rows of a table and its partitions, then analyzes them
+	Vacuum(ctx context.Context, table string) error
+}
+
+type SnapshotRepository interface {
+	// GetSnapshotItems pages through the items of a kind published in dataset snapshots
+	GetSnapshotItems(ctx context.Context, kind, tenant string, start, end int64, maxSpamScore float64,
+		afterCreatedAt int64, afterID, limit int) ([]*models.SnapshotItem, error)
+}
+
+type StorageRepository interface {
+	// GetDatabaseSize returns the disk space taken by the database
+	GetDatabaseSize(ctx context.Context) (int64, error)
+	// SaveSnapshots records the sizes of their day, replacing the ones recorded earlier that day
+	SaveSnapshots(ctx context.Context, snapshots []*models.StorageSnapshot) error
+	// GetSnapshots returns the snapshots of the days from since (unix seconds), oldest first
+	GetSnapshots(ctx context.Context, since int64) ([]*models.StorageSnapshot, error)
+	// PruneSnapshots deletes the snapshots of the days before the given time (unix seconds)
+	PruneSnapshots(ctx context.Context, before int64) (int64, error)
+}
+
+type JobRunRepository interface {
+	// Record counts a run of the job, replacing its last run
+	Record(ctx context.Context, name string, startedAt time.Time, duration time.Duration) error
+	// GetAll returns the last run of every job that ran, by name
+	GetAll(ctx context.Context) ([]*models.JobRun, error)
+}
+
+type TombstoneRepository interface {
+	// Remove moves the stored items with the IDs out of the item tables into pending tombstones
+	// with the reason, logging their deletion in the change log, and returns the tombstones; IDs
+	// of items not stored are ignored
+	Remove(ctx context.Context, ids []int, reason string) ([]*models.Tombstone, error)
+	// RemoveExpired removes like Remove up to limit items of each item table that belong to the
+	// tenant and were created before the given time (unix seconds), oldest first, for retention
+	RemoveExpired(ctx context.Context, tenant string, createdBefore int64, limit int) ([]*models.Tombstone, error)
+	// GetPending returns up to limit tombstones not emitted yet, oldest first
+	GetPending(ctx context.Context, limit int) ([]*models.Tombstone, error)
+	// MarkEmitted records that the events of the tombstones were published
+	MarkEmitted(ctx context.Context, ids []int) error
+	// PruneEmitted deletes the tombstones emitted before the given time (unix milliseconds)
+	PruneEmitted(ctx context.Context, before int64) (int64, error)
 }
@@ -0,0 +1,27 @@
+package repository
+
+import "fmt"
+
+// UpsertCounts classifies the rows of a batch upsert
+type UpsertCounts struct {
+	Inserted int // rows that did not exist yet
+	Updated  int // existing rows whose columns changed
+	Skipped  int // existing rows that were already up to date
+}
+
+// Add accumulates the counts of another batch
+func (c *UpsertCounts) Add(other UpsertCounts) {
+	c.Inserted += other.Inserted
+	c.Updated += other.Updated
+	c.Skipped += other.Skipped
+}
+
+// Total returns the number of rows attempted
+func (c UpsertCounts) Total() int {
+	return c.Inserted + c.Updated + c.Skipped
+}
+
+// String formats the counts for sync reports, e.g. "1200 new, 300 updated, 12 unchanged"
+func (c UpsertCounts) String() string {
+	return fmt.Sprintf("%d new, %d updated, %d unchanged", c.Inserted, c.Updated, c.Skipped)
+}
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"internship-project/internal/config"
+)
+
+// SearchAnalyzer is the query-time analyzer ApplyAnalyzer installs on every index; searches use it
+// when SEARCH_SYNONYMS_ENABLED is set
+const SearchAnalyzer = "hn_search"
+
+// AnalyzerConfig holds the synonym rules and stopwords of SearchAnalyzer
+type AnalyzerConfig struct {
+	// Synonyms are rules in the Solr format: "k8s, kubernetes" (equivalent terms)
+	// or "golang => go" (one-way replacement)
+	Synonyms  []string `json:"synonyms"`
+	Stopwords []string `json:"stopwords"`
+}
+
+// Validate checks that every synonym rule lists at least two terms
+func (a *AnalyzerConfig) Validate() error {
+	for _, rule := range a.Synonyms {
+		left, right, oneWay := strings.Cut(rule, "=>")
+		terms := strings.Split(left, ",")
+		if oneWay {
+			terms = append(terms, strings.Split(right, ",")...)
+		}
+		nonEmpty := 0
+		for _, term := range terms {
+			if strings.TrimSpace(term) != "" {
+				nonEmpty++
+			}
+		}
+		if nonEmpty < 2 {
+			return fmt.Errorf("synonym rule %q needs at least two terms", rule)
+		}
+	}
+	for _, word := range a.Stopwords {
+		if strings.TrimSpace(word) == "" || strings.ContainsAny(word, " \t") {
+			return fmt.Errorf("invalid stopword %q", word)
+		}
+	}
+	return nil
+}
+
+// synonymsFile and stopwordsFile hold the analyzer config, one rule or word per line;
+// blank lines and lines starting with # are ignored
+func synonymsFile() string {
+	return config.GetEnv("SEARCH_SYNONYMS_FILE", "configs/search_synonyms.txt")
+}
+
+func stopwordsFile() string {
+	return config.GetEnv("SEARCH_STOPWORDS_FILE", "configs/search_stopwords.txt")
+}
+
+// LoadAnalyzerConfig reads the synonyms and stopwords files; missing files are empty
+func LoadAnalyzerConfig() (*AnalyzerConfig, error) {
+	synonyms, err := readLines(synonymsFile())
+	if err != nil {
+		return nil, err
+	}
+	stopwords, err := readLines(stopwordsFile())
+	if err != nil {
+		return nil, err
+	}
+	return &AnalyzerConfig{Synonyms: synonyms, Stopwords: stopwords}, nil
+}
+
+// SaveAnalyzerConfig overwrites the synonyms and stopwords files
+func SaveAnalyzerConfig(a *AnalyzerConfig) error {
+	if err := writeLines(synonymsFile(), a.Synonyms); err != nil {
+		return err
+	}
+	return writeLines(stopwordsFile(), a.Stopwords)
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func writeLines(path string, lines []string) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(strings.TrimSpace(line))
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// ApplyAnalyzer installs SearchAnalyzer with the config on the index of every kind and refreshes
+// it. Analysis settings can only change on a closed index, so each index is briefly closed and
+// reopened; it is reopened even when the update fails.
+func (c *Client) ApplyAnalyzer(ctx context.Context, a *AnalyzerConfig) error {
+	settings, err := json.Marshal(map[string]interface{}{
+		"analysis": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"hn_synonyms":  map[string]interface{}{"type": "synonym_graph", "synonyms": a.Synonyms},
+				"hn_stopwords": map[string]interface{}{"type": "stop", "stopwords": a.Stopwords},
+			},
+			"analyzer": map[string]interface{}{
+				SearchAnalyzer: map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "hn_stopwords", "hn_synonyms"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range Kinds() {
+		index, err := c.Index(kind)
+		if err != nil {
+			return err
+		}
+		if err := c.updateClosed(ctx, index, settings); err != nil {
+			return fmt.Errorf("failed to update analyzer of %s: %w", index, err)
+		}
+		if err := c.do(ctx, http.MethodPost, "/"+index+"/_refresh", nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateClosed closes the index, puts the settings and reopens it
+func (c *Client) updateClosed(ctx context.Context, index string, settings []byte) (err error) {
+	if err := c.do(ctx, http.MethodPost, "/"+index+"/_close", nil, nil); err != nil {
+		return err
+	}
+	defer func() {
+		// reopen with a fresh context so a cancelled request does not leave the index closed
+		if openErr := c.do(context.WithoutCancel(ctx), http.MethodPost, "/"+index+"/_open", nil, nil); err == nil {
+			err = openErr
+		}
+	}()
+	return c.do(ctx, http.MethodPut, "/"+index+"/_settings", bytes.NewReader(settings), nil)
+}
@@ -0,0 +1,219 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/opensearch"
+)
+
+// Kinds returns the item kinds that have a search index
+func Kinds() []string {
+	return []string{"story", "ask", "job", "comment", "poll"}
+}
+
+// Client talks to the OpenSearch cluster the indexer writes to
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the cluster at OPENSEARCH_URL whose indexes are named by
+// opensearch.IndexName; it returns nil when no URL is configured or the opensearch sink is disabled
+func NewClient() *Client {
+	baseURL := config.GetEnv("OPENSEARCH_URL", "")
+	if baseURL == "" || !config.SinkEnabled(config.SinkOpenSearch) {
+		return nil
+	}
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("OPENSEARCH_TIMEOUT", 10*time.Second),
+		},
+	}
+}
+
+// Index returns the index holding items of the kind
+func (c *Client) Index(kind string) (string, error) {
+	if !slices.Contains(Kinds(), kind) {
+		return "", fmt.Errorf("no search index for item kind %q", kind)
+	}
+	return opensearch.IndexName(kind)
+}
+
+// Count returns the number of documents in the index of the kind
+func (c *Client) Count(ctx context.Context, kind string) (int64, error) {
+	index, err := c.Index(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/"+index+"/_count", nil, &result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+// timeField is the document field holding the item creation time (unix seconds)
+const timeField = "time"
+
+// CountRange returns the number of documents of the kind created between start and end
+// (unix seconds, inclusive); zero bounds are open
+func (c *Client) CountRange(ctx context.Context, kind string, start, end int64) (int64, error) {
+	if start == 0 && end == 0 {
+		return c.Count(ctx, kind)
+	}
+	index, err := c.Index(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"query": timeRange(start, end)})
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+index+"/_count", bytes.NewReader(body), &result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+// timeRange returns the range query matching documents created between start and end
+// (unix seconds, inclusive); zero bounds are open
+func timeRange(start, end int64) map[string]interface{} {
+	bounds := map[string]int64{}
+	if start > 0 {
+		bounds["gte"] = start
+	}
+	if end > 0 {
+		bounds["lte"] = end
+	}
+	return map[string]interface{}{
+		"range": map[string]interface{}{timeField: bounds},
+	}
+}
+
+// MultiGet returns the source of the documents of the kind with the given IDs.
+// IDs without a document are absent from the result.
+func (c *Client) MultiGet(ctx context.Context, kind string, ids []int) (map[int]map[string]interface{}, error) {
+	index, err := c.Index(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	docIDs := make([]string, len(ids))
+	for i, id := range ids {
+		docIDs[i] = strconv.Itoa(id)
+	}
+	body, err := json.Marshal(map[string]interface{}{"ids": docIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Docs []struct {
+			ID     string                 `json:"_id"`
+			Found  bool                   `json:"found"`
+			Source map[string]interface{} `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+index+"/_mget", bytes.NewReader(body), &result); err != nil {
+		return nil, err
+	}
+
+	docs := make(map[int]map[string]interface{}, len(result.Docs))
+	for _, doc := range result.Docs {
+		id, err := strconv.Atoi(doc.ID)
+		if err != nil || !doc.Found {
+			continue
+		}
+		docs[id] = doc.Source
+	}
+	return docs, nil
+}
+
+// Delete removes the documents of the kind with the given IDs in one bulk request. IDs without
+// a document are not an error.
+func (c *Client) Delete(ctx context.Context, kind string, ids []int) error {
+	index, err := c.Index(kind)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, id := range ids {
+		fmt.Fprintf(&body, `{"delete":{"_index":%q,"_id":"%d"}}`+"\n", index, id)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []struct {
+			Delete struct {
+				ID     string          `json:"_id"`
+				Status int             `json:"status"`
+				Error  json.RawMessage `json:"error"`
+			} `json:"delete"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_bulk", &body, &result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	for _, item := range result.Items {
+		if item.Delete.Status >= 300 && item.Delete.Status != http.StatusNotFound {
+			return fmt.Errorf("failed to delete document %s from %s: status %d: %s",
+				item.Delete.ID, index, item.Delete.Status, item.Delete.Error)
+		}
+	}
+	return nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into dest
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("search request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("search request %s %s returned status %d: %s", method, path, resp.StatusCode, msg)
+	}
+	if dest == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return nil
+}
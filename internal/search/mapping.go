@@ -0,0 +1,68 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// mappedField is a document field the queries rely on, with the mapping types they work with
+type mappedField struct {
+	types    []string
+	required func(kind string) bool // nil when the field may be absent until a document has it
+}
+
+func allKinds(string) bool { return true }
+
+// mappedFields are checked by CheckMapping: the full-text fields, the author and tag terms, the
+// new author flag, and the creation time of the ranges, decay and sort
+var mappedFields = map[string]mappedField{
+	"title":        {types: []string{"text"}, required: func(kind string) bool { return kind != "comment" }},
+	"text":         {types: []string{"text"}},
+	"by":           {types: []string{"keyword"}, required: allKinds},
+	timeField:      {types: []string{"long", "integer", "date"}, required: allKinds},
+	"score":        {types: []string{"long", "integer"}},
+	tagsField:      {types: []string{"keyword"}},
+	newAuthorField: {types: []string{"boolean"}},
+}
+
+// CheckMapping compares the mapping of the index of the kind with the fields the queries rely on.
+// It returns the fields that are missing or of another type, sorted, and an error when the index
+// cannot be read, e.g. it does not exist.
+func (c *Client) CheckMapping(ctx context.Context, kind string) ([]string, error) {
+	index, err := c.Index(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keyed by the concrete index, which differs from the name requested through an alias
+	var result map[string]struct {
+		Mappings struct {
+			Properties map[string]struct {
+				Type string `json:"type"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/"+index+"/_mapping", nil, &result); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, mapping := range result {
+		for field, want := range mappedFields {
+			got, ok := mapping.Mappings.Properties[field]
+			switch {
+			case !ok && want.required != nil && want.required(kind):
+				problems = append(problems, fmt.Sprintf("%s: %s missing", index, field))
+			case ok && !slices.Contains(want.types, got.Type):
+				problems = append(problems, fmt.Sprintf("%s: %s is %s, want %s", index, field,
+					got.Type, strings.Join(want.types, " or ")))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
@@ -0,0 +1,383 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// defaultKindBoosts weighs the kinds of a multi-index search: stories rank above asks and jobs,
+// which rank above comments matching as well
+var defaultKindBoosts = map[string]float64{
+	"story":   2,
+	"ask":     1.5,
+	"job":     1.2,
+	"poll":    1,
+	"comment": 0.8,
+}
+
+// Ranking profiles of a search
+const (
+	RankRelevance = "relevance" // text relevance only
+	RankHot       = "hot"       // relevance weighted by item score and decayed by age, like the HN front page
+	RankTop       = "top"       // highest item score first
+	RankNew       = "new"       // newest first
+)
+
+// ValidRank reports whether rank names a ranking profile
+func ValidRank(rank string) bool {
+	switch rank {
+	case RankRelevance, RankHot, RankTop, RankNew:
+		return true
+	}
+	return false
+}
+
+// Request is a full-text search across the indexes of one or more item kinds
+type Request struct {
+	Query  string
+	Kinds  []string // empty searches every kind
+	Author string
+	Tag    string // keeps the items carrying the tag; empty keeps every item
+	Start  int64  // created_at lower bound (unix seconds, inclusive); 0 is open
+	End    int64  // created_at upper bound (unix seconds, inclusive); 0 is open
+	Rank   string // one of the Rank profiles; empty is RankRelevance
+	Limit  int
+	Offset int
+
+	// NewAuthors keeps the stories and comments flagged as posted by new authors
+	NewAuthors bool
+
+	// SnippetLength is the size in characters of the highlighted text fragments;
+	// 0 uses SEARCH_SNIPPET_LENGTH
+	SnippetLength int
+}
+
+// Hit is one matching document
+type Hit struct {
+	Kind  string                 `json:"type"`
+	ID    int                    `json:"id"`
+	Score float64                `json:"score"`
+	Item  map[string]interface{} `json:"item"`
+
+	// Highlights holds the matching fragments per field ("title", "text") with the matches
+	// wrapped in the configured tags
+	Highlights map[string][]string `json:"highlights,omitempty"`
+
+	// Duplicates are the hits Collapse folded into this one
+	Duplicates []Hit `json:"duplicates,omitempty"`
+}
+
+// itemScore returns the HN score of the hit's document, 0 when it has none
+func (h Hit) itemScore() float64 {
+	score, _ := h.Item["score"].(float64)
+	return score
+}
+
+// Result is a page of hits ranked together across kinds
+type Result struct {
+	Total  int64            `json:"total"`
+	Facets map[string]int64 `json:"facets"` // matching documents per kind
+	Tags   map[string]int64 `json:"tags"`   // matching documents per tag, for the most frequent tags
+	Hits   []Hit            `json:"hits"`
+}
+
+// KindBoosts returns the per-kind score multipliers of SEARCH_KIND_BOOSTS ("story:2,comment:0.8");
+// kinds it leaves out keep their default boost
+func KindBoosts() map[string]float64 {
+	boosts := make(map[string]float64, len(defaultKindBoosts))
+	for kind, boost := range defaultKindBoosts {
+		boosts[kind] = boost
+	}
+	for _, entry := range config.GetEnvList("SEARCH_KIND_BOOSTS", nil) {
+		kind, value, ok := strings.Cut(entry, ":")
+		kind = strings.TrimSpace(kind)
+		boost, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || !slices.Contains(Kinds(), kind) || err != nil || boost <= 0 {
+			continue
+		}
+		boosts[kind] = boost
+	}
+	return boosts
+}
+
+// tagsField is the document field holding the item tags, mirrored from the item_tags table
+const tagsField = "tags"
+
+// newAuthorField is the document field holding the new_author flag of stories and comments
+const newAuthorField = "new_author"
+
+// Search runs the request as a single query over the indexes of its kinds. Index boosts are
+// applied at query time so scores are comparable across kinds, and a terms aggregation on the
+// index name provides the type facet; another one on the item tags provides the tag facet.
+func (c *Client) Search(ctx context.Context, req Request) (*Result, error) {
+	kinds := req.Kinds
+	if len(kinds) == 0 {
+		kinds = Kinds()
+	}
+
+	boosts := KindBoosts()
+	indexes := make([]string, len(kinds))
+	kindOfIndex := make(map[string]string, len(kinds))
+	indexBoosts := make([]map[string]float64, len(kinds))
+	for i, kind := range kinds {
+		index, err := c.Index(kind)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+		kindOfIndex[index] = kind
+		indexBoosts[i] = map[string]float64{index: boosts[kind]}
+	}
+
+	query := map[string]interface{}{
+		"query":         rankedQuery(searchQuery(req), req.Rank),
+		"indices_boost": indexBoosts,
+		"from":          req.Offset,
+		"size":          req.Limit,
+		"aggs": map[string]interface{}{
+			"types": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "_index", "size": len(indexes)},
+			},
+			"tags": map[string]interface{}{
+				"terms": map[string]interface{}{"field": tagsField, "size": config.GetEnvInt("SEARCH_TAG_FACET_SIZE", 20)},
+			},
+		},
+	}
+	if req.Query != "" {
+		query["highlight"] = highlight(req.SnippetLength)
+	}
+	if sort := rankSort(req.Rank); sort != nil {
+		query["sort"] = sort
+		query["track_scores"] = true
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Index     string                 `json:"_index"`
+				ID        string                 `json:"_id"`
+				Score     float64                `json:"_score"`
+				Source    map[string]interface{} `json:"_source"`
+				Highlight map[string][]string    `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations struct {
+			Types struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int64  `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"types"`
+			Tags struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int64  `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"tags"`
+		} `json:"aggregations"`
+	}
+	path := "/" + strings.Join(indexes, ",") + "/_search?ignore_unavailable=true"
+	if err := c.do(ctx, http.MethodPost, path, bytes.NewReader(body), &response); err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Total:  response.Hits.Total.Value,
+		Facets: make(map[string]int64, len(kinds)),
+		Tags:   make(map[string]int64, len(response.Aggregations.Tags.Buckets)),
+		Hits:   make([]Hit, 0, len(response.Hits.Hits)),
+	}
+	for _, kind := range kinds {
+		result.Facets[kind] = 0
+	}
+	for _, bucket := range response.Aggregations.Types.Buckets {
+		if kind, ok := kindOfIndex[bucket.Key]; ok {
+			result.Facets[kind] = bucket.DocCount
+		}
+	}
+	for _, bucket := range response.Aggregations.Tags.Buckets {
+		result.Tags[bucket.Key] = bucket.DocCount
+	}
+	for _, hit := range response.Hits.Hits {
+		id, err := strconv.Atoi(hit.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected document id %q in %s", hit.ID, hit.Index)
+		}
+		result.Hits = append(result.Hits, Hit{
+			Kind:       kindOfIndex[hit.Index],
+			ID:         id,
+			Score:      hit.Score,
+			Item:       hit.Source,
+			Highlights: hit.Highlight,
+		})
+	}
+	return result, nil
+}
+
+// Collapse folds story hits sharing a canonical story (canonical maps story IDs to the ID of
+// their canonical story) into the one with the highest item score, listing the others under its
+// Duplicates. Each group keeps the rank of its best-ranked hit.
+func Collapse(hits []Hit, canonical map[int]int) []Hit {
+	collapsed := make([]Hit, 0, len(hits))
+	groupAt := make(map[int]int) // canonical ID -> index in collapsed
+	for _, hit := range hits {
+		group, linked := canonical[hit.ID]
+		if hit.Kind != "story" || !linked {
+			collapsed = append(collapsed, hit)
+			continue
+		}
+		i, seen := groupAt[group]
+		if !seen {
+			groupAt[group] = len(collapsed)
+			collapsed = append(collapsed, hit)
+			continue
+		}
+
+		kept := &collapsed[i]
+		if hit.itemScore() > kept.itemScore() {
+			previous := *kept
+			duplicates := previous.Duplicates
+			previous.Duplicates = nil
+			hit.Duplicates = append(duplicates, previous)
+			*kept = hit
+		} else {
+			kept.Duplicates = append(kept.Duplicates, hit)
+		}
+	}
+	return collapsed
+}
+
+// searchQuery builds the bool query of the request: full-text match on the title, text and
+// author fields (titles weigh double), analyzed with SearchAnalyzer when SEARCH_SYNONYMS_ENABLED
+// is set, filtered by author, tag, new author flag and creation time
+func searchQuery(req Request) map[string]interface{} {
+	var must interface{} = map[string]interface{}{"match_all": map[string]interface{}{}}
+	if req.Query != "" {
+		match := map[string]interface{}{
+			"query":  req.Query,
+			"fields": []string{"title^2", "text", "by"},
+		}
+		if config.GetEnvBool("SEARCH_SYNONYMS_ENABLED", false) {
+			match["analyzer"] = SearchAnalyzer
+		}
+		must = map[string]interface{}{"multi_match": match}
+	}
+
+	filters := []interface{}{}
+	if req.Author != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"by": req.Author},
+		})
+	}
+	if req.Tag != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{tagsField: req.Tag},
+		})
+	}
+	if req.NewAuthors {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{newAuthorField: true},
+		})
+	}
+	if req.Start > 0 || req.End > 0 {
+		filters = append(filters, timeRange(req.Start, req.End))
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   must,
+			"filter": filters,
+		},
+	}
+}
+
+// rankedQuery wraps the query for the hot profile: the text score is multiplied by log(1 + item
+// score) and by a gaussian decay on the creation time, configured by SEARCH_HOT_DECAY_SCALE (the age
+// at which the decay reaches SEARCH_HOT_DECAY) and SEARCH_HOT_SCORE_FACTOR. Other profiles rank by
+// text relevance or sort.
+func rankedQuery(query map[string]interface{}, rank string) map[string]interface{} {
+	if rank != RankHot {
+		return query
+	}
+	scale := config.GetEnvDuration("SEARCH_HOT_DECAY_SCALE", 24*time.Hour)
+	return map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query": query,
+			"functions": []interface{}{
+				map[string]interface{}{
+					"field_value_factor": map[string]interface{}{
+						"field":    "score",
+						"factor":   config.GetEnvFloat("SEARCH_HOT_SCORE_FACTOR", 1),
+						"modifier": "log1p",
+						"missing":  1,
+					},
+				},
+				map[string]interface{}{
+					"gauss": map[string]interface{}{
+						timeField: map[string]interface{}{
+							"origin": time.Now().Unix(),
+							"scale":  int64(scale.Seconds()),
+							"decay":  config.GetEnvFloat("SEARCH_HOT_DECAY", 0.5),
+						},
+					},
+				},
+			},
+			"score_mode": "multiply",
+			"boost_mode": "multiply",
+		},
+	}
+}
+
+// highlight requests the matching fragments of the title (whole) and text, wrapped in
+// SEARCH_HIGHLIGHT_PRE_TAG and SEARCH_HIGHLIGHT_POST_TAG; the text yields up to
+// SEARCH_SNIPPET_COUNT fragments of snippetLength (or SEARCH_SNIPPET_LENGTH) characters
+func highlight(snippetLength int) map[string]interface{} {
+	if snippetLength <= 0 {
+		snippetLength = config.GetEnvInt("SEARCH_SNIPPET_LENGTH", 150)
+	}
+	return map[string]interface{}{
+		"pre_tags":  []string{config.GetEnv("SEARCH_HIGHLIGHT_PRE_TAG", "<em>")},
+		"post_tags": []string{config.GetEnv("SEARCH_HIGHLIGHT_POST_TAG", "</em>")},
+		"fields": map[string]interface{}{
+			"title": map[string]interface{}{"number_of_fragments": 0},
+			"text": map[string]interface{}{
+				"fragment_size":       snippetLength,
+				"number_of_fragments": config.GetEnvInt("SEARCH_SNIPPET_COUNT", 3),
+				"no_match_size":       snippetLength,
+			},
+		},
+	}
+}
+
+// rankSort returns the sort of the top and new profiles, nil for score-ranked ones
+func rankSort(rank string) []interface{} {
+	switch rank {
+	case RankTop:
+		return []interface{}{
+			map[string]interface{}{"score": map[string]interface{}{"order": "desc", "missing": "_last", "unmapped_type": "long"}},
+			"_score",
+		}
+	case RankNew:
+		return []interface{}{
+			map[string]interface{}{timeField: map[string]interface{}{"order": "desc"}},
+			"_score",
+		}
+	}
+	return nil
+}
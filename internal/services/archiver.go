@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// ErrArchiveRateLimited is returned when the Wayback Machine refuses a save for exceeding its rate limit
+var ErrArchiveRateLimited = errors.New("archive save rate limited")
+
+// Archiver submits URLs to the Wayback Machine save API
+type Archiver struct {
+	httpClient *http.Client
+	saveAPI    string
+}
+
+// NewArchiver creates an archiver using the save API at ARCHIVE_SAVE_API, waiting up to
+// ARCHIVE_TIMEOUT per save since the page is captured while the request is held
+func NewArchiver() *Archiver {
+	return &Archiver{
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("ARCHIVE_TIMEOUT", 2*time.Minute),
+			// The snapshot URL is the redirect target; the snapshot page itself is not needed
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		saveAPI: config.GetEnv("ARCHIVE_SAVE_API", "https://web.archive.org/save/"),
+	}
+}
+
+// Save asks the Wayback Machine to capture link and returns the URL of the snapshot, taken from
+// the redirect (Location) or Content-Location of the response
+func (a *Archiver) Save(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.saveAPI+link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "hn-data-sync-archiver/1.0")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("archive save failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	var location string
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "", ErrArchiveRateLimited
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location = resp.Header.Get("Location")
+	case resp.StatusCode == http.StatusOK:
+		location = resp.Header.Get("Content-Location")
+	default:
+		return "", fmt.Errorf("archive save returned status %d", resp.StatusCode)
+	}
+	if location == "" {
+		return "", errors.New("archive save returned no snapshot location")
+	}
+
+	snapshot, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot location %q: %w", location, err)
+	}
+	return req.URL.ResolveReference(snapshot).String(), nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"internship-project/internal/models"
+)
+
+// CommentFetchFunc fetches comments by ID, like CommentApiService.FetchMultiple
+type CommentFetchFunc func(ctx context.Context, ids []int, opts ...FetchOption) ([]*models.Comment, error)
+
+// CommentRoot is an item whose comment tree is fetched: a story, ask, poll or comment
+type CommentRoot struct {
+	ID   int
+	Kids []int // direct replies, in ranked display order
+}
+
+// CommentTreeLimits bound the comment tree fetched below each root
+type CommentTreeLimits struct {
+	MaxDepth int // levels of replies fetched, the direct replies being the first (0 = unlimited)
+	MaxNodes int // comments fetched per root (0 = unlimited)
+}
+
+// CommentTree holds the comments fetched by FetchCommentTrees
+type CommentTree struct {
+	Comments  []*models.Comment // level by level, siblings in the order of their parent's kids
+	Truncated bool              // replies were left out by MaxDepth or MaxNodes
+	Repeated  int               // reply IDs skipped for being in a tree already, as in a cycle
+}
+
+// FetchCommentTrees fetches the comments below the roots breadth first, with one fetch call per
+// level for all the roots together. A tree stops growing at limits.MaxDepth levels or once it has
+// limits.MaxNodes comments, the first replies in rank order being kept. No ID is fetched twice,
+// so a reply listing one of its ancestors cannot loop. The items failing to fetch are left out
+// and reported in a *MultiError returned along the tree; any other error ends the fetch.
+func FetchCommentTrees(ctx context.Context, fetch CommentFetchFunc, roots []CommentRoot, limits CommentTreeLimits) (*CommentTree, error) {
+	tree := &CommentTree{}
+	visited := make(map[int]bool)
+	for _, root := range roots {
+		visited[root.ID] = true
+	}
+
+	nodes := make([]int, len(roots)) // comments queued per root
+	rootOf := make(map[int]int)      // comment ID -> index of its root
+	var pending []int
+	enqueue := func(root int, ids []int) {
+		for _, id := range ids {
+			if visited[id] {
+				tree.Repeated++
+				continue
+			}
+			if limits.MaxNodes > 0 && nodes[root] >= limits.MaxNodes {
+				tree.Truncated = true
+				return
+			}
+			visited[id] = true
+			nodes[root]++
+			rootOf[id] = root
+			pending = append(pending, id)
+		}
+	}
+	for i, root := range roots {
+		enqueue(i, root.Kids)
+	}
+
+	attempted := 0
+	failed := make(map[int]error)
+	for depth := 1; len(pending) > 0; depth++ {
+		ids := pending
+		pending = nil
+		comments, err := fetch(ctx, ids)
+		attempted += len(ids)
+
+		var multiErr *MultiError
+		if errors.As(err, &multiErr) {
+			maps.Copy(failed, multiErr.Errors)
+		} else if err != nil {
+			return tree, err
+		}
+		tree.Comments = append(tree.Comments, comments...)
+
+		if limits.MaxDepth > 0 && depth >= limits.MaxDepth {
+			for _, comment := range comments {
+				if len(comment.Replies) > 0 {
+					tree.Truncated = true
+					break
+				}
+			}
+			break
+		}
+		for _, comment := range comments {
+			if root, ok := rootOf[comment.ID]; ok {
+				enqueue(root, comment.Replies)
+			}
+		}
+	}
+	return tree, newMultiError(attempted, failed)
+}
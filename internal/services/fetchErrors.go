@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrItemGone is returned when the API serves null for an item or user: the ID was never used,
+// is not published yet, or HackerNews purged the item. Refetching it right away is pointless.
+var ErrItemGone = errors.New("item gone")
+
+// MultiError reports the items that failed during a best-effort FetchMultiple.
+// Results for the remaining items are still returned alongside it.
+type MultiError struct {
+	Attempted int
+	Errors    map[int]error // item ID -> fetch error
+}
+
+// newMultiError returns a *MultiError for the failures, or nil when there are none
+func newMultiError(attempted int, errs map[int]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MultiError{Attempted: attempted, Errors: errs}
+}
+
+// Error summarizes how many items failed and the first failure
+func (e *MultiError) Error() string {
+	ids := e.IDs()
+	return fmt.Sprintf("failed to fetch %d of %d items (first: item %d: %v)",
+		len(ids), e.Attempted, ids[0], e.Errors[ids[0]])
+}
+
+// Failed returns the number of failed items
+func (e *MultiError) Failed() int {
+	return len(e.Errors)
+}
+
+// IDs returns the failed item IDs in ascending order
+func (e *MultiError) IDs() []int {
+	ids := make([]int, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, id := range e.IDs() {
+		errs = append(errs, e.Errors[id])
+	}
+	return errs
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// FetchOptions controls how FetchMultiple spreads requests over the HN API
+type FetchOptions struct {
+	ChunkSize      int           // IDs processed per chunk; chunks run one after another (0 = single chunk)
+	MaxConcurrency int           // concurrent requests within a chunk (0 = one goroutine per ID)
+	FailFast       bool          // stop at the first error instead of collecting best-effort results
+	ItemTimeout    time.Duration // per-item request timeout (0 = rely on the HTTP client timeout)
+	ChunkDelay     time.Duration // pause between chunks, to stay under the API rate limit
+}
+
+// FetchOption customizes FetchOptions
+type FetchOption func(*FetchOptions)
+
+// DefaultFetchOptions returns the options configured through FETCH_* environment variables
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{
+		ChunkSize:      config.GetEnvInt("FETCH_CHUNK_SIZE", 100),
+		MaxConcurrency: config.GetEnvInt("FETCH_MAX_CONCURRENCY", 20),
+		ItemTimeout:    config.GetEnvDuration("FETCH_ITEM_TIMEOUT", 0),
+		ChunkDelay:     config.GetEnvDuration("FETCH_CHUNK_DELAY", 0),
+	}
+}
+
+// UpdateFetchOptions returns the options of the updates feed sync, configured through
+// UPDATES_CHUNK_SIZE, UPDATES_MAX_CONCURRENCY and UPDATES_CHUNK_DELAY. The feed can list
+// hundreds of IDs at once, so it defaults to smaller chunks than DefaultFetchOptions.
+func UpdateFetchOptions() FetchOptions {
+	options := DefaultFetchOptions()
+	options.ChunkSize = config.GetEnvInt("UPDATES_CHUNK_SIZE", 50)
+	options.MaxConcurrency = config.GetEnvInt("UPDATES_MAX_CONCURRENCY", 10)
+	options.ChunkDelay = config.GetEnvDuration("UPDATES_CHUNK_DELAY", options.ChunkDelay)
+	return options
+}
+
+// WithChunkSize sets how many IDs are processed per chunk
+func WithChunkSize(size int) FetchOption {
+	return func(o *FetchOptions) { o.ChunkSize = size }
+}
+
+// WithMaxConcurrency limits the number of in-flight requests
+func WithMaxConcurrency(n int) FetchOption {
+	return func(o *FetchOptions) { o.MaxConcurrency = n }
+}
+
+// WithFailFast aborts the fetch at the first failed item
+func WithFailFast() FetchOption {
+	return func(o *FetchOptions) { o.FailFast = true }
+}
+
+// WithChunkDelay pauses between chunks
+func WithChunkDelay(delay time.Duration) FetchOption {
+	return func(o *FetchOptions) { o.ChunkDelay = delay }
+}
+
+// WithItemTimeout bounds each single-item request
+func WithItemTimeout(timeout time.Duration) FetchOption {
+	return func(o *FetchOptions) { o.ItemTimeout = timeout }
+}
+
+// WithFetchOptions replaces all the options, e.g. with UpdateFetchOptions
+func WithFetchOptions(options FetchOptions) FetchOption {
+	return func(o *FetchOptions) { *o = options }
+}
+
+func buildFetchOptions(opts []FetchOption) FetchOptions {
+	options := DefaultFetchOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// fetchMany fetches every ID with fetch according to the options.
+// Results keep the order of ids and skip failed or empty items; failures are returned per ID,
+// including the IDs never attempted because parent was done, which fail with its error.
+func fetchMany[K comparable, T any](
+	parent context.Context,
+	ids []K,
+	fetch func(ctx context.Context, id K) (*T, error),
+	options FetchOptions,
+) ([]*T, map[K]error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	results := make([]*T, len(ids))
+	attempted := make([]bool, len(ids))
+	errs := make(map[K]error)
+	var mu sync.Mutex
+
+	indexes := make([]int, len(ids))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	ForEachChunked(ctx, indexes, options, func(ctx context.Context, index int) {
+		itemCtx := ctx
+		if options.ItemTimeout > 0 {
+			var itemCancel context.CancelFunc
+			itemCtx, itemCancel = context.WithTimeout(ctx, options.ItemTimeout)
+			defer itemCancel()
+		}
+
+		attempted[index] = true
+		id := ids[index]
+		item, err := fetch(itemCtx, id)
+		if err != nil {
+			mu.Lock()
+			// In fail-fast mode only the first error matters; the rest are cancellations
+			if !options.FailFast || len(errs) == 0 {
+				errs[id] = err
+			}
+			mu.Unlock()
+			if options.FailFast {
+				cancel()
+			}
+			return
+		}
+		results[index] = item
+	})
+
+	// Fail-fast cancellations are ours and already reported by the first error
+	if err := parent.Err(); err != nil && (!options.FailFast || len(errs) == 0) {
+		for index, id := range ids {
+			if _, failed := errs[id]; !attempted[index] && !failed {
+				errs[id] = err
+			}
+		}
+	}
+
+	valid := make([]*T, 0, len(ids))
+	for _, item := range results {
+		if item != nil {
+			valid = append(valid, item)
+		}
+	}
+	return valid, errs
+}
+
+// ForEachChunked calls fn for every item, options.ChunkSize items at a time: chunks run one
+// after another, options.ChunkDelay apart, with at most options.MaxConcurrency calls in flight
+// within a chunk. It stops starting calls once ctx is done.
+func ForEachChunked[T any](ctx context.Context, items []T, options FetchOptions, fn func(ctx context.Context, item T)) {
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 || chunkSize > len(items) {
+		chunkSize = len(items)
+	}
+
+	for start := 0; start < len(items); start += chunkSize {
+		if start > 0 && options.ChunkDelay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(options.ChunkDelay):
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		end := min(start+chunkSize, len(items))
+
+		var sem chan struct{}
+		if options.MaxConcurrency > 0 {
+			sem = make(chan struct{}, options.MaxConcurrency)
+		}
+
+		var wg sync.WaitGroup
+		for _, item := range items[start:end] {
+			if sem != nil {
+				sem <- struct{}{}
+			}
+			// Checked once a slot is free, as the calls that ran meanwhile may have canceled ctx
+			if ctx.Err() != nil {
+				if sem != nil {
+					<-sem
+				}
+				break
+			}
+
+			wg.Add(1)
+			go func(item T) {
+				defer wg.Done()
+				if sem != nil {
+					defer func() { <-sem }()
+				}
+				fn(ctx, item)
+			}(item)
+		}
+		wg.Wait()
+	}
+}
+
+// firstFetchError returns the error of the lowest failed ID, for deterministic fail-fast reporting
+func firstFetchError(errs map[int]error) error {
+	first := -1
+	for id := range errs {
+		if first == -1 || id < first {
+			first = id
+		}
+	}
+	if first == -1 {
+		return nil
+	}
+	return fmt.Errorf("failed to fetch item %d: %w", first, errs[first])
+}
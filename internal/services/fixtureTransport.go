@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+// FixtureMode selects how a FixtureTransport serves requests
+type FixtureMode string
+
+const (
+	// FixtureRecord forwards requests and saves the successful responses
+	FixtureRecord FixtureMode = "record"
+	// FixtureReplay serves saved responses only, without network access
+	FixtureReplay FixtureMode = "replay"
+)
+
+// ErrFixtureMissing reports a replayed request that has no recorded response
+var ErrFixtureMissing = errors.New("no recorded response")
+
+// FixtureTransport records HN API responses into a directory, one file per URL path
+// (v0/item/8863.json, v0/topstories.json, ...), and replays them deterministically
+type FixtureTransport struct {
+	dir  string
+	mode FixtureMode
+	next http.RoundTripper
+}
+
+// NewFixtureTransport creates a transport recording into or replaying from dir; next performs
+// the recorded requests (http.DefaultTransport when nil)
+func NewFixtureTransport(dir string, mode FixtureMode, next http.RoundTripper) *FixtureTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &FixtureTransport{dir: dir, mode: mode, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	file := t.fixturePath(req)
+	if t.mode == FixtureReplay {
+		body, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w for %s", ErrFixtureMissing, req.URL.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return fixtureResponse(req, body), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, body, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to record %s: %w", req.URL.Path, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// fixturePath maps a request to its file; the query string is ignored and the cleaned path
+// cannot leave the fixture directory
+func (t *FixtureTransport) fixturePath(req *http.Request) string {
+	return filepath.Join(t.dir, filepath.FromSlash(path.Clean("/"+req.URL.Path)))
+}
+
+// fixtureResponse builds the 200 response of a replayed request
+func fixtureResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}, "Content-Length": {strconv.Itoa(len(body))}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
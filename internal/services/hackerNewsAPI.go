@@ -1,26 +1,43 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
+
+	"internship-project/internal/config"
 )
 
 // HackerNewsApiClient handles HTTP requests to the Hacker News API
 type HackerNewsApiClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	deadLetters DeadLetterSink // receives the payloads failing schema validation; nil logs them
+	payloads    PayloadSink    // keeps the payloads of the decoded items; nil drops them
 }
 
-// NewHackerNewsApiClient creates a new API client
+// NewHackerNewsApiClient creates a new API client for HN_API_BASE_URL. HN_API_FIXTURES_MODE
+// "record" saves the responses into HN_API_FIXTURES_DIR and "replay" serves them from it
+// without network access; see FixtureTransport.
 func NewHackerNewsApiClient() *HackerNewsApiClient {
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	switch mode := FixtureMode(config.GetEnv("HN_API_FIXTURES_MODE", "")); mode {
+	case "":
+	case FixtureRecord, FixtureReplay:
+		httpClient.Transport = NewFixtureTransport(config.GetEnv("HN_API_FIXTURES_DIR", "testdata/hn"), mode, nil)
+	default:
+		log.Printf("Ignoring unknown HN_API_FIXTURES_MODE %q", mode)
+	}
+
 	return &HackerNewsApiClient{
-		baseURL: "https://hacker-news.firebaseio.com/v0",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    config.GetEnv("HN_API_BASE_URL", "https://hacker-news.firebaseio.com/v0"),
+		httpClient: httpClient,
 	}
 }
 
@@ -43,13 +60,26 @@ func (c *HackerNewsApiClient) Get(ctx context.Context, endpoint string, result i
 		return fmt.Errorf("API request failed with status %d", resp.StatusCode)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+	// null would decode into a zero value passing for an empty item
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if isNull(body) {
+		return fmt.Errorf("API returned null for %s: %w", endpoint, ErrItemGone)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return nil
 }
 
+// isNull reports whether a JSON body is null
+func isNull(body json.RawMessage) bool {
+	return len(bytes.TrimSpace(body)) == 0 || string(bytes.TrimSpace(body)) == "null"
+}
+
 // GetItem fetches a single item by ID
 func (c *HackerNewsApiClient) GetItem(ctx context.Context, id int, result interface{}) error {
 	endpoint := fmt.Sprintf("/item/%d.json", id)
@@ -9,14 +9,16 @@ import (
 // ApiDataFetcher defines the base interface for all API fetchers
 type ApiDataFetcher[T any] interface {
 	FetchByID(ctx context.Context, id int) (*T, error)
-	FetchMultiple(ctx context.Context, ids []int) ([]*T, error)
+	FetchMultiple(ctx context.Context, ids []int, opts ...FetchOption) ([]*T, error)
+	FetchMultipleWithErrors(ctx context.Context, ids []int, opts ...FetchOption) ([]*T, map[int]error)
 	FetchTopItems(ctx context.Context) ([]int, error)
 }
 
 // UserApiFetcher defines the interface for user API operations
 type UserApiFetcher interface {
 	FetchByID(ctx context.Context, id int) (*models.User, error)
-	FetchMultiple(ctx context.Context, ids []int) ([]*models.User, error)
+	FetchMultiple(ctx context.Context, ids []int, opts ...FetchOption) ([]*models.User, error)
+	FetchMultipleWithErrors(ctx context.Context, ids []int, opts ...FetchOption) ([]*models.User, map[int]error)
 	FetchByUsername(ctx context.Context, username string) (*models.User, error)
 }
 
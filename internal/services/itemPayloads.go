@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+)
+
+// PayloadSink keeps the payloads of the items fetched from the API;
+// repository.ItemPayloadRepository implements it
+type PayloadSink interface {
+	Save(ctx context.Context, payload *models.ItemPayload) error
+}
+
+// SetPayloadSink hands the payload of each item GetHNItem decodes to sink while
+// ITEM_RAW_PAYLOADS_ENABLED is set, so a parsing fix can be applied to the stored items by
+// decoding their payloads again with DecodeHNItem instead of fetching them again
+func (c *HackerNewsApiClient) SetPayloadSink(sink PayloadSink) {
+	c.payloads = sink
+}
+
+// keepPayload hands the payload of a decoded item to the payload sink; failures are only logged
+func (c *HackerNewsApiClient) keepPayload(ctx context.Context, id int, item *models.HNItem, payload json.RawMessage) {
+	if c.payloads == nil || !config.GetEnvBool("ITEM_RAW_PAYLOADS_ENABLED", false) {
+		return
+	}
+	err := c.payloads.Save(ctx, &models.ItemPayload{
+		Item_ID:    id,
+		Kind:       item.Type,
+		Payload:    payload,
+		Fetched_At: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Printf("Failed to keep the payload of item %d: %v", id, err)
+	}
+}
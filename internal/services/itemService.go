@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"internship-project/internal/models"
+)
+
+// ItemApiService fetches one HackerNews item type.
+// Type-specific services embed it and only add their list endpoints.
+type ItemApiService[T models.Item] struct {
+	client           *HackerNewsApiClient
+	topItemsEndpoint string // list endpoint used by FetchTopItems ("" when the type has none)
+}
+
+// NewItemApiService creates an item service; topItemsEndpoint may be empty
+func NewItemApiService[T models.Item](client *HackerNewsApiClient, topItemsEndpoint string) *ItemApiService[T] {
+	return &ItemApiService[T]{client: client, topItemsEndpoint: topItemsEndpoint}
+}
+
+// FetchByID fetches a single item and converts it to the column layout of T; an item whose
+// payload does not match its schema fails with a *SchemaError
+func (s *ItemApiService[T]) FetchByID(ctx context.Context, id int) (*T, error) {
+	item, err := s.client.GetHNItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return models.ConvertHNItem[T](item), nil
+}
+
+// FetchMultiple fetches items by ID.
+// Failed items are skipped and reported through a *MultiError, or abort the call in fail-fast mode.
+func (s *ItemApiService[T]) FetchMultiple(ctx context.Context, ids []int, opts ...FetchOption) ([]*T, error) {
+	options := buildFetchOptions(opts)
+	items, errs := fetchMany(ctx, ids, s.FetchByID, options)
+	if options.FailFast && len(errs) > 0 {
+		return nil, firstFetchError(errs)
+	}
+	return items, newMultiError(len(ids), errs)
+}
+
+// FetchMultipleWithErrors fetches items by ID and reports every failure by item ID
+func (s *ItemApiService[T]) FetchMultipleWithErrors(ctx context.Context, ids []int, opts ...FetchOption) ([]*T, map[int]error) {
+	return fetchMany(ctx, ids, s.FetchByID, buildFetchOptions(opts))
+}
+
+// FetchTopItems returns the IDs of the type's list endpoint, or none if it has no list
+func (s *ItemApiService[T]) FetchTopItems(ctx context.Context) ([]int, error) {
+	if s.topItemsEndpoint == "" {
+		return []int{}, nil
+	}
+	return s.client.GetItemList(ctx, s.topItemsEndpoint)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+
+	"internship-project/internal/config"
+	"internship-project/internal/hnschema"
+	"internship-project/internal/models"
+)
+
+// schemaViolations counts the payloads rejected by schema validation, by item type
+var schemaViolations = expvar.NewMap("hn_schema_violations")
+
+// goneItems counts the item IDs the API served null for
+var goneItems = expvar.NewInt("hn_items_gone")
+
+// ErrSchemaViolation is matched by the errors of items whose payload does not match the JSON
+// schema of their type. Refetching them is pointless until the upstream API changes again.
+var ErrSchemaViolation = errors.New("item payload does not match its schema")
+
+// SchemaError reports the violations of an item payload
+type SchemaError struct {
+	ID         int
+	Kind       string
+	Violations []hnschema.Violation
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("item %d (%s) does not match its schema: %d violations (first: %s)",
+		e.ID, e.Kind, len(e.Violations), e.Violations[0])
+}
+
+// Unwrap lets errors.Is match ErrSchemaViolation
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// DeadLetterSink records the payloads rejected before persistence;
+// repository.DeadLetterRepository implements it
+type DeadLetterSink interface {
+	Record(ctx context.Context, letter *models.DeadLetter) error
+}
+
+// SetDeadLetterSink routes the payloads failing schema validation to sink, with their
+// violations. Without a sink they are only logged.
+func (c *HackerNewsApiClient) SetDeadLetterSink(sink DeadLetterSink) {
+	c.deadLetters = sink
+}
+
+// GetHNItem fetches an item and decodes it once its payload matches the JSON schema of its type
+// (HN_SCHEMA_VALIDATION, on by default). A payload with violations is routed to the dead-letter
+// sink and reported as a *SchemaError instead of being converted into a half-empty item. A null
+// payload, served for IDs without an item, is recorded in the sink as gone and fails with
+// ErrItemGone. The payload of a decoded item goes to the payload sink, see SetPayloadSink.
+func (c *HackerNewsApiClient) GetHNItem(ctx context.Context, id int) (*models.HNItem, error) {
+	var payload json.RawMessage
+	if err := c.GetItem(ctx, id, &payload); err != nil {
+		if errors.Is(err, ErrItemGone) {
+			c.recordGone(ctx, id)
+		}
+		return nil, err
+	}
+	item, err := c.DecodeHNItem(ctx, id, payload)
+	if err != nil {
+		return nil, err
+	}
+	c.keepPayload(ctx, id, item, payload)
+	return item, nil
+}
+
+// DecodeHNItem validates and decodes the payload of item id like GetHNItem
+func (c *HackerNewsApiClient) DecodeHNItem(ctx context.Context, id int, payload json.RawMessage) (*models.HNItem, error) {
+	if isNull(payload) {
+		return nil, fmt.Errorf("item %d is null: %w", id, ErrItemGone)
+	}
+	if config.GetEnvBool("HN_SCHEMA_VALIDATION", true) {
+		if kind, violations := hnschema.ValidateItem(payload); len(violations) > 0 {
+			err := &SchemaError{ID: id, Kind: kind, Violations: violations}
+			c.deadLetter(ctx, err, payload)
+			return nil, err
+		}
+	}
+
+	var item models.HNItem
+	if err := json.Unmarshal(payload, &item); err != nil {
+		return nil, fmt.Errorf("failed to decode item %d: %w", id, err)
+	}
+	return &item, nil
+}
+
+// deadLetter counts a rejected payload and hands it to the dead-letter sink
+func (c *HackerNewsApiClient) deadLetter(ctx context.Context, schemaErr *SchemaError, payload json.RawMessage) {
+	schemaViolations.Add(schemaErr.Kind, 1)
+	if c.deadLetters == nil {
+		log.Printf("Rejected %v", schemaErr)
+		return
+	}
+
+	letter := &models.DeadLetter{
+		Item_ID: schemaErr.ID,
+		Kind:    schemaErr.Kind,
+		Reason:  models.DeadLetterSchemaViolation,
+		Payload: payload,
+	}
+	for _, violation := range schemaErr.Violations {
+		letter.Errors = append(letter.Errors, violation.String())
+	}
+	if err := c.deadLetters.Record(ctx, letter); err != nil {
+		log.Printf("Failed to dead-letter item %d: %v", schemaErr.ID, err)
+	}
+}
+
+// recordGone counts an item served as null and records it in the dead-letter sink, each time
+// counting one more occurrence, so the syncs can stop asking for it
+func (c *HackerNewsApiClient) recordGone(ctx context.Context, id int) {
+	goneItems.Add(1)
+	if c.deadLetters == nil {
+		return
+	}
+	letter := &models.DeadLetter{
+		Item_ID: id,
+		Reason:  models.DeadLetterGone,
+		Errors:  []string{"the API served null"},
+		Payload: json.RawMessage("null"),
+	}
+	if err := c.deadLetters.Record(ctx, letter); err != nil {
+		log.Printf("Failed to record gone item %d: %v", id, err)
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+)
+
+// LinkChecker checks whether story URLs still resolve and looks up archive.org snapshots
+type LinkChecker struct {
+	httpClient *http.Client
+	archiveAPI string
+}
+
+// NewLinkChecker creates a checker with a LINK_CHECK_TIMEOUT per request, using the
+// Wayback Machine availability API at LINK_CHECK_ARCHIVE_API for snapshots
+func NewLinkChecker() *LinkChecker {
+	return &LinkChecker{
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("LINK_CHECK_TIMEOUT", 10*time.Second),
+		},
+		archiveAPI: config.GetEnv("LINK_CHECK_ARCHIVE_API", "https://archive.org/wayback/available"),
+	}
+}
+
+// Check requests the URL with HEAD, falling back to GET for servers that do not allow HEAD.
+// Only definitive answers (404, 410, unknown host) mark the link dead; other failures are
+// reported unreachable so they are retried on the next check.
+func (c *LinkChecker) Check(ctx context.Context, link string) (status string, statusCode int) {
+	resp, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp, err = c.request(ctx, http.MethodGet, link)
+	}
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return models.LinkDead, 0
+		}
+		return models.LinkUnreachable, 0
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return models.LinkDead, resp.StatusCode
+	case resp.StatusCode >= 500:
+		return models.LinkUnreachable, resp.StatusCode
+	default:
+		// Redirects were followed; 401/403 and other client errors mean the page exists behind a wall
+		return models.LinkAlive, resp.StatusCode
+	}
+}
+
+func (c *LinkChecker) request(ctx context.Context, method, link string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "hn-data-sync-link-check/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// Only the status matters; don't download GET bodies
+	resp.Body.Close()
+	return resp, nil
+}
+
+// waybackAvailability is the response of the Wayback Machine availability API
+type waybackAvailability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// ArchiveSnapshot returns the URL of the closest archive.org snapshot of link, or "" if there is none
+func (c *LinkChecker) ArchiveSnapshot(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.archiveAPI+"?url="+url.QueryEscape(link), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("archive lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("archive lookup returned status %d", resp.StatusCode)
+	}
+
+	var availability waybackAvailability
+	if err := json.NewDecoder(resp.Body).Decode(&availability); err != nil {
+		return "", fmt.Errorf("failed to decode archive lookup: %w", err)
+	}
+	if closest := availability.ArchivedSnapshots.Closest; closest != nil && closest.Available {
+		return closest.URL, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+)
+
+// Preview field lengths, in characters
+const (
+	maxPreviewTitle       = 300
+	maxPreviewDescription = 1000
+)
+
+// previewTags maps the meta tags read by the link previewer to a field and its precedence:
+// OpenGraph over Twitter cards over the plain HTML description
+var previewTags = map[string]struct {
+	field    string
+	priority int
+}{
+	"og:title":            {"title", 3},
+	"og:description":      {"description", 3},
+	"og:image":            {"image", 3},
+	"og:image:url":        {"image", 3},
+	"og:image:secure_url": {"image", 3},
+	"og:site_name":        {"site_name", 3},
+	"twitter:title":       {"title", 2},
+	"twitter:description": {"description", 2},
+	"twitter:image":       {"image", 2},
+	"twitter:image:src":   {"image", 2},
+	"description":         {"description", 1},
+}
+
+// LinkPreviewer reads the OpenGraph and Twitter card metadata of story URLs
+type LinkPreviewer struct {
+	httpClient *http.Client
+	maxBytes   int64
+}
+
+// NewLinkPreviewer creates a previewer with a LINK_PREVIEW_TIMEOUT per page, reading at most
+// LINK_PREVIEW_MAX_BYTES of it
+func NewLinkPreviewer() *LinkPreviewer {
+	return &LinkPreviewer{
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("LINK_PREVIEW_TIMEOUT", 10*time.Second),
+		},
+		maxBytes: int64(config.GetEnvInt("LINK_PREVIEW_MAX_BYTES", 512<<10)),
+	}
+}
+
+// Preview fetches the page at link and returns its preview metadata, falling back to the HTML
+// title. Timeouts, server errors and rate limiting are reported failed so they are retried; pages
+// that are not HTML or carry no metadata are reported as having none.
+func (p *LinkPreviewer) Preview(ctx context.Context, link string) *models.LinkPreview {
+	preview := &models.LinkPreview{URL: link, Status: models.PreviewFailed, FetchedAt: time.Now().Unix()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		preview.Status = models.PreviewNone
+		return preview
+	}
+	req.Header.Set("User-Agent", "hn-data-sync-link-preview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return preview
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return preview
+	}
+	preview.Status = models.PreviewNone
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode >= 300 || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return preview
+	}
+
+	fields := parsePreviewTags(io.LimitReader(resp.Body, p.maxBytes))
+	preview.Title = truncateRunes(fields["title"], maxPreviewTitle)
+	preview.Description = truncateRunes(fields["description"], maxPreviewDescription)
+	preview.SiteName = truncateRunes(fields["site_name"], maxPreviewTitle)
+	if image, err := resp.Request.URL.Parse(fields["image"]); err == nil && fields["image"] != "" &&
+		(image.Scheme == "http" || image.Scheme == "https") {
+		preview.ImageURL = image.String()
+	}
+	if preview.Title != "" || preview.Description != "" || preview.ImageURL != "" {
+		preview.Status = models.PreviewFound
+	}
+	return preview
+}
+
+// Favicon returns the absolute URL of the icon of the site at home, its home page URL: the one the
+// page links to, or /favicon.ico when the site serves one. It returns "" when the site has none.
+func (p *LinkPreviewer) Favicon(ctx context.Context, home string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, home, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "hn-data-sync-link-preview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	fields := parsePreviewTags(io.LimitReader(resp.Body, p.maxBytes))
+	resp.Body.Close()
+	base := resp.Request.URL
+	if icon, err := base.Parse(fields["icon"]); err == nil && fields["icon"] != "" &&
+		(icon.Scheme == "http" || icon.Scheme == "https") {
+		return icon.String(), nil
+	}
+
+	fallback := base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+	req, err = http.NewRequestWithContext(ctx, http.MethodHead, fallback, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "hn-data-sync-link-preview/1.0")
+	resp, err = p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "image/") {
+		return fallback, nil
+	}
+	return "", nil
+}
+
+// parsePreviewTags reads the preview fields and icon link of the head of an HTML document,
+// stopping at the body
+func parsePreviewTags(r io.Reader) map[string]string {
+	fields := make(map[string]string)
+	priorities := make(map[string]int)
+	var title strings.Builder
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return withTitle(fields, title.String())
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == "title" {
+				inTitle = false
+			} else if string(name) == "head" {
+				return withTitle(fields, title.String())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return withTitle(fields, title.String())
+			case "title":
+				inTitle = true
+			case "link":
+				var rel, href string
+				for hasAttr {
+					var attr, value []byte
+					attr, value, hasAttr = z.TagAttr()
+					switch string(attr) {
+					case "rel":
+						rel = strings.ToLower(string(value))
+					case "href":
+						href = strings.TrimSpace(string(value))
+					}
+				}
+				// A plain icon over the apple-touch-icon, which is usually larger
+				priority := 0
+				for _, token := range strings.Fields(rel) {
+					switch token {
+					case "icon":
+						priority = 2
+					case "apple-touch-icon":
+						priority = max(priority, 1)
+					}
+				}
+				if href != "" && priority > priorities["icon"] {
+					fields["icon"] = href
+					priorities["icon"] = priority
+				}
+			case "meta":
+				var key, content string
+				for hasAttr {
+					var attr, value []byte
+					attr, value, hasAttr = z.TagAttr()
+					switch string(attr) {
+					case "property", "name":
+						if key == "" || strings.Contains(string(value), ":") {
+							key = strings.ToLower(strings.TrimSpace(string(value)))
+						}
+					case "content":
+						content = strings.TrimSpace(string(value))
+					}
+				}
+				if tag, ok := previewTags[key]; ok && content != "" && tag.priority > priorities[tag.field] {
+					fields[tag.field] = content
+					priorities[tag.field] = tag.priority
+				}
+			}
+		}
+	}
+}
+
+// withTitle uses the HTML title when the metadata has none
+func withTitle(fields map[string]string, title string) map[string]string {
+	if fields["title"] == "" {
+		fields["title"] = strings.Join(strings.Fields(title), " ")
+	}
+	return fields
+}
+
+// truncateRunes cuts s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
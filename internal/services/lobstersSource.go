@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+)
+
+// SourceLobsters is the source name of items synced from lobste.rs
+const SourceLobsters = "lobsters"
+
+// lobstersStory is a story as returned by the Lobsters JSON API
+type lobstersStory struct {
+	ShortID       string            `json:"short_id"`
+	CreatedAt     string            `json:"created_at"`
+	Title         string            `json:"title"`
+	URL           string            `json:"url"`
+	Score         int               `json:"score"`
+	CommentCount  int               `json:"comment_count"`
+	SubmitterUser lobstersUser      `json:"submitter_user"`
+	Comments      []lobstersComment `json:"comments"`
+}
+
+// lobstersComment is a comment nested in a Lobsters story response
+type lobstersComment struct {
+	ShortID        string       `json:"short_id"`
+	CreatedAt      string       `json:"created_at"`
+	IsDeleted      bool         `json:"is_deleted"`
+	ParentComment  *string      `json:"parent_comment"`
+	CommentPlain   string       `json:"comment_plain"`
+	CommentingUser lobstersUser `json:"commenting_user"`
+}
+
+// lobstersUser accepts both the plain username and the older {"username": ...} object
+type lobstersUser string
+
+func (u *lobstersUser) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*u = lobstersUser(name)
+		return nil
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return fmt.Errorf("invalid lobsters user: %w", err)
+	}
+	*u = lobstersUser(user.Username)
+	return nil
+}
+
+// LobstersSource syncs the Lobsters front page and its comments
+type LobstersSource struct {
+	baseURL    string
+	httpClient *http.Client
+	maxStories int
+}
+
+// NewLobstersSource creates a Lobsters source reading at most LOBSTERS_MAX_STORIES hottest stories
+func NewLobstersSource() *LobstersSource {
+	return &LobstersSource{
+		baseURL: config.GetEnv("LOBSTERS_BASE_URL", "https://lobste.rs"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		maxStories: config.GetEnvInt("LOBSTERS_MAX_STORIES", 25),
+	}
+}
+
+// Name returns the Lobsters source name
+func (s *LobstersSource) Name() string {
+	return SourceLobsters
+}
+
+// FetchLatest fetches the hottest stories and the comments of each one
+func (s *LobstersSource) FetchLatest(ctx context.Context) (*SourceBatch, error) {
+	var hottest []lobstersStory
+	if err := s.get(ctx, "/hottest.json", &hottest); err != nil {
+		return nil, err
+	}
+	if len(hottest) > s.maxStories {
+		hottest = hottest[:s.maxStories]
+	}
+
+	batch := &SourceBatch{}
+	for _, summary := range hottest {
+		// The listing has no comments; the story endpoint returns them as a flat, threaded list
+		var full lobstersStory
+		if err := s.get(ctx, "/s/"+summary.ShortID+".json", &full); err != nil {
+			return nil, err
+		}
+
+		story, comments, err := mapLobstersStory(&full)
+		if err != nil {
+			return nil, err
+		}
+		batch.Stories = append(batch.Stories, story)
+		batch.Comments = append(batch.Comments, comments...)
+	}
+	return batch, nil
+}
+
+// mapLobstersStory converts a Lobsters story and its comments into the unified item model.
+// Direct replies are linked through Comments_ids and Replies like HackerNews kids.
+func mapLobstersStory(ls *lobstersStory) (*models.Story, []*models.Comment, error) {
+	storyID, err := LobstersItemID(ls.ShortID)
+	if err != nil {
+		return nil, nil, err
+	}
+	createdAt, err := parseLobstersTime(ls.CreatedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	story := &models.Story{
+		ID:             storyID,
+		Type:           "story",
+		Title:          ls.Title,
+		URL:            ls.URL,
+		Score:          max(ls.Score, 0),
+		Author:         string(ls.SubmitterUser),
+		Created_At:     createdAt,
+		Comments_ids:   []int{},
+		Comments_count: ls.CommentCount,
+		Source:         SourceLobsters,
+	}
+
+	comments := make([]*models.Comment, 0, len(ls.Comments))
+	byShortID := make(map[string]*models.Comment, len(ls.Comments))
+	for _, lc := range ls.Comments {
+		if lc.IsDeleted {
+			continue
+		}
+		id, err := LobstersItemID(lc.ShortID)
+		if err != nil {
+			return nil, nil, err
+		}
+		createdAt, err := parseLobstersTime(lc.CreatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		comment := &models.Comment{
+			ID:         id,
+			Type:       "comment",
+			Text:       lc.CommentPlain,
+			Author:     string(lc.CommentingUser),
+			Parent:     storyID,
+			Replies:    []int{},
+			Created_At: createdAt,
+			Source:     SourceLobsters,
+		}
+
+		if lc.ParentComment != nil {
+			if parent, ok := byShortID[*lc.ParentComment]; ok {
+				comment.Parent = parent.ID
+				parent.Replies = append(parent.Replies, id)
+			}
+		}
+		if comment.Parent == storyID {
+			story.Comments_ids = append(story.Comments_ids, id)
+		}
+
+		byShortID[lc.ShortID] = comment
+		comments = append(comments, comment)
+	}
+	return story, comments, nil
+}
+
+// LobstersItemID maps a Lobsters short ID (base 36) to an item ID.
+// Lobsters items use negative IDs so they never collide with HackerNews IDs; short IDs that
+// would reach the feed item range (see FeedItemID) are rejected.
+func LobstersItemID(shortID string) (int, error) {
+	n, err := strconv.ParseInt(shortID, 36, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lobsters short id %q: %w", shortID, err)
+	}
+	if n < 0 || n >= rssIDBase {
+		return 0, fmt.Errorf("lobsters short id %q is out of the item ID range", shortID)
+	}
+	return -int(n), nil
+}
+
+func parseLobstersTime(value string) (int64, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lobsters timestamp %q: %w", value, err)
+	}
+	return t.Unix(), nil
+}
+
+// get performs a GET request against the Lobsters API and decodes the JSON response
+func (s *LobstersSource) get(ctx context.Context, endpoint string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lobsters request failed with status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// MoreLikeThis finds similar items with an OpenSearch more_like_this query on the search index
+type MoreLikeThis struct {
+	baseURL    string
+	index      string
+	httpClient *http.Client
+}
+
+// NewMoreLikeThis creates a client for the index RELATED_OPENSEARCH_INDEX at RELATED_OPENSEARCH_URL,
+// or returns nil when no URL is configured or the opensearch sink is disabled
+func NewMoreLikeThis() *MoreLikeThis {
+	baseURL := config.GetEnv("RELATED_OPENSEARCH_URL", "")
+	if baseURL == "" || !config.SinkEnabled(config.SinkOpenSearch) {
+		return nil
+	}
+	return &MoreLikeThis{
+		baseURL: baseURL,
+		index:   config.GetEnv("RELATED_OPENSEARCH_INDEX", "stories"),
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("RELATED_OPENSEARCH_TIMEOUT", 2*time.Second),
+		},
+	}
+}
+
+// Related returns the IDs of the documents most similar to item id by title and text, best first
+func (m *MoreLikeThis) Related(ctx context.Context, id, limit int) ([]int, error) {
+	query := map[string]interface{}{
+		"size":    limit,
+		"_source": false,
+		"query": map[string]interface{}{
+			"more_like_this": map[string]interface{}{
+				"fields":          []string{"title", "text"},
+				"like":            []map[string]string{{"_index": m.index, "_id": strconv.Itoa(id)}},
+				"min_term_freq":   1,
+				"min_doc_freq":    2,
+				"max_query_terms": 25,
+			},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/"+m.index+"/_search", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("more_like_this query failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("more_like_this query returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode more_like_this response: %w", err)
+	}
+
+	ids := make([]int, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		if hitID, err := strconv.Atoi(hit.ID); err == nil && hitID != id {
+			ids = append(ids, hitID)
+		}
+	}
+	return ids, nil
+}
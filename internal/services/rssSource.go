@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"internship-project/internal/models"
+)
+
+// SourceRSS is the source name of items synced from RSS and Atom feeds
+const SourceRSS = "rss"
+
+// rssIDBase keeps feed item IDs clear of the Lobsters ID range (see LobstersItemID)
+const rssIDBase = 1 << 52
+
+// feedDocument decodes both RSS 2.0 (<rss><channel>) and Atom (<feed>) documents
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Author  string `xml:"author"`
+	Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+// feedEntry is an RSS item or Atom entry normalized to the fields stories need
+type feedEntry struct {
+	guid      string
+	title     string
+	link      string
+	author    string
+	published string
+}
+
+// RSSSource polls RSS and Atom feeds and normalizes their entries into stories
+type RSSSource struct {
+	feeds      []string
+	httpClient *http.Client
+}
+
+// NewRSSSource creates a source polling the given feed URLs
+func NewRSSSource(feeds []string) *RSSSource {
+	return &RSSSource{
+		feeds: feeds,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the RSS source name
+func (s *RSSSource) Name() string {
+	return SourceRSS
+}
+
+// FetchLatest fetches every configured feed; a failing feed is logged and skipped.
+// Entries are deduplicated by GUID (falling back to the link) and by link.
+func (s *RSSSource) FetchLatest(ctx context.Context) (*SourceBatch, error) {
+	batch := &SourceBatch{}
+	seenIDs := make(map[int]bool)
+	seenLinks := make(map[string]bool)
+
+	for _, feedURL := range s.feeds {
+		stories, err := s.fetchFeed(ctx, feedURL)
+		if err != nil {
+			log.Printf("Error fetching feed %s: %v", feedURL, err)
+			continue
+		}
+
+		for _, story := range stories {
+			if seenIDs[story.ID] || (story.URL != "" && seenLinks[story.URL]) {
+				continue
+			}
+			seenIDs[story.ID] = true
+			seenLinks[story.URL] = true
+			batch.Stories = append(batch.Stories, story)
+		}
+	}
+	return batch, nil
+}
+
+// fetchFeed downloads one feed and maps its entries to stories
+func (s *RSSSource) fetchFeed(ctx context.Context, feedURL string) ([]*models.Story, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed request failed with status %d", resp.StatusCode)
+	}
+
+	var doc feedDocument
+	if err := xml.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode feed: %w", err)
+	}
+	return mapFeedDocument(&doc, feedURL), nil
+}
+
+// mapFeedDocument converts the entries of an RSS or Atom document into stories.
+// Entries without a title or a stable key are skipped.
+func mapFeedDocument(doc *feedDocument, feedURL string) []*models.Story {
+	var entries []feedEntry
+	feedTitle := doc.Title
+
+	switch doc.XMLName.Local {
+	case "rss":
+		feedTitle = doc.Channel.Title
+		for _, item := range doc.Channel.Items {
+			author := item.Creator
+			if author == "" {
+				author = item.Author
+			}
+			entries = append(entries, feedEntry{
+				guid: item.GUID, title: item.Title, link: item.Link,
+				author: author, published: item.PubDate,
+			})
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			link := ""
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			entries = append(entries, feedEntry{
+				guid: entry.ID, title: entry.Title, link: link,
+				author: entry.Author.Name, published: published,
+			})
+		}
+	}
+
+	// Feeds rarely name an author, so fall back to the feed itself
+	fallbackAuthor := feedTitle
+	if fallbackAuthor == "" {
+		if u, err := url.Parse(feedURL); err == nil {
+			fallbackAuthor = u.Host
+		}
+	}
+
+	stories := make([]*models.Story, 0, len(entries))
+	for _, entry := range entries {
+		key := strings.TrimSpace(entry.guid)
+		if key == "" {
+			key = strings.TrimSpace(entry.link)
+		}
+		title := strings.TrimSpace(entry.title)
+		if key == "" || title == "" {
+			continue
+		}
+
+		author := strings.TrimSpace(entry.author)
+		if author == "" {
+			author = fallbackAuthor
+		}
+
+		stories = append(stories, &models.Story{
+			ID:           FeedItemID(key),
+			Type:         "story",
+			Title:        title,
+			URL:          strings.TrimSpace(entry.link),
+			Author:       author,
+			Created_At:   parseFeedTime(entry.published),
+			Comments_ids: []int{},
+			Source:       SourceRSS,
+		})
+	}
+	return stories
+}
+
+// FeedItemID maps a feed entry key (GUID or link) to a stable negative item ID,
+// so re-polling a feed updates the same rows instead of duplicating them.
+func FeedItemID(key string) int {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return -int(rssIDBase | (h.Sum64() & (rssIDBase - 1)))
+}
+
+// feedTimeLayouts are the date formats seen in RSS (RFC 822 variants) and Atom (RFC 3339)
+var feedTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// parseFeedTime parses an entry date, using the current time when it is missing or malformed
+func parseFeedTime(value string) int64 {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix()
+		}
+	}
+	return time.Now().Unix()
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"internship-project/internal/models"
+)
+
+// Source is a feed whose items flow through the sync pipeline into the unified item model.
+// HackerNews is the first implementation; other feeds are added as new sources.
+type Source interface {
+	// Name identifies the source in the `source` column and in search filters
+	Name() string
+
+	// FetchLatest returns the source's current items mapped into the unified model
+	FetchLatest(ctx context.Context) (*SourceBatch, error)
+}
+
+// SourceBatch is one fetch of a source, ready to be persisted
+type SourceBatch struct {
+	Stories  []*models.Story
+	Comments []*models.Comment
+}
+
+// HackerNewsSource exposes the HackerNews top stories as a Source
+type HackerNewsSource struct {
+	storyService StoryApiFetcher
+}
+
+// NewHackerNewsSource creates the HackerNews source backed by the story service
+func NewHackerNewsSource(storyService StoryApiFetcher) *HackerNewsSource {
+	return &HackerNewsSource{storyService: storyService}
+}
+
+// Name returns the HackerNews source name
+func (s *HackerNewsSource) Name() string {
+	return models.SourceHackerNews
+}
+
+// FetchLatest fetches the top stories, retrying the ones that failed once
+func (s *HackerNewsSource) FetchLatest(ctx context.Context) (*SourceBatch, error) {
+	ids, err := s.storyService.FetchTopStories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stories, err := s.storyService.FetchMultiple(ctx, ids)
+	var multiErr *MultiError
+	if errors.As(err, &multiErr) {
+		retried, retryErr := s.storyService.FetchMultiple(ctx, multiErr.IDs())
+		stories = append(stories, retried...)
+		err = retryErr
+	}
+	if errors.As(err, &multiErr) {
+		// Partial failures are not fatal; the stories will be picked up by the next run
+		log.Printf("%d %s stories still failing after retry", multiErr.Failed(), s.Name())
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, story := range stories {
+		story.Source = s.Name()
+	}
+	return &SourceBatch{Stories: stories}, nil
+}
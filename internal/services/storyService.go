@@ -2,58 +2,17 @@ package services
 
 import (
 	"context"
-	"sync"
 
 	"internship-project/internal/models"
 )
 
 // StoryApiService implements StoryApiFetcher
 type StoryApiService struct {
-	client *HackerNewsApiClient
+	*ItemApiService[models.Story]
 }
 
 func NewStoryApiService(client *HackerNewsApiClient) *StoryApiService {
-	return &StoryApiService{client: client}
-}
-
-func (s *StoryApiService) FetchByID(ctx context.Context, id int) (*models.Story, error) {
-	var story models.Story
-	err := s.client.GetItem(ctx, id, &story)
-	if err != nil {
-		return nil, err
-	}
-	return &story, nil
-}
-
-func (s *StoryApiService) FetchMultiple(ctx context.Context, ids []int) ([]*models.Story, error) {
-	var wg sync.WaitGroup
-	results := make([]*models.Story, len(ids))
-	errors := make([]error, len(ids))
-
-	for i, id := range ids {
-		wg.Add(1)
-		go func(index, storyID int) {
-			defer wg.Done()
-			story, err := s.FetchByID(ctx, storyID)
-			results[index] = story
-			errors[index] = err
-		}(i, id)
-	}
-
-	wg.Wait()
-
-	var validStories []*models.Story
-	for i, story := range results {
-		if errors[i] == nil && story != nil {
-			validStories = append(validStories, story)
-		}
-	}
-
-	return validStories, nil
-}
-
-func (s *StoryApiService) FetchTopItems(ctx context.Context) ([]int, error) {
-	return s.FetchTopStories(ctx)
+	return &StoryApiService{NewItemApiService[models.Story](client, "/topstories.json")}
 }
 
 func (s *StoryApiService) FetchTopStories(ctx context.Context) ([]int, error) {
@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"fmt"
-	"sync"
 
 	"internship-project/internal/models"
 )
@@ -28,39 +27,33 @@ func (s *UserApiService) FetchByID(ctx context.Context, id int) (*models.User, e
 }
 
 func (s *UserApiService) FetchByUsername(ctx context.Context, username string) (*models.User, error) {
-	var user models.User
+	var user models.HNUser
 	endpoint := fmt.Sprintf("/user/%s.json", username)
 	err := s.client.Get(ctx, endpoint, &user)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user.ToUser(), nil
 }
 
-func (s *UserApiService) FetchMultiple(ctx context.Context, ids []int) ([]*models.User, error) {
-	var wg sync.WaitGroup
-	results := make([]*models.User, len(ids))
-	errors := make([]error, len(ids))
-
-	for i, id := range ids {
-		wg.Add(1)
-		go func(index, userID int) {
-			defer wg.Done()
-			user, err := s.FetchByID(ctx, userID)
-			results[index] = user
-			errors[index] = err
-		}(i, id)
-	}
-
-	wg.Wait()
+// FetchByUsernames fetches the profiles of the users, spread over the API like FetchMultiple,
+// and reports every failure by username
+func (s *UserApiService) FetchByUsernames(ctx context.Context, usernames []string, opts ...FetchOption) ([]*models.User, map[string]error) {
+	return fetchMany(ctx, usernames, s.FetchByUsername, buildFetchOptions(opts))
+}
 
-	// Filter out nil results and collect valid users
-	var validUsers []*models.User
-	for i, user := range results {
-		if errors[i] == nil && user != nil {
-			validUsers = append(validUsers, user)
-		}
+// FetchMultiple fetches users by ID.
+// Failed items are skipped and reported through a *MultiError, or abort the call in fail-fast mode.
+func (s *UserApiService) FetchMultiple(ctx context.Context, ids []int, opts ...FetchOption) ([]*models.User, error) {
+	options := buildFetchOptions(opts)
+	users, errs := fetchMany(ctx, ids, s.FetchByID, options)
+	if options.FailFast && len(errs) > 0 {
+		return nil, firstFetchError(errs)
 	}
+	return users, newMultiError(len(ids), errs)
+}
 
-	return validUsers, nil
+// FetchMultipleWithErrors fetches users by ID and reports every failure by item ID
+func (s *UserApiService) FetchMultipleWithErrors(ctx context.Context, ids []int, opts ...FetchOption) ([]*models.User, map[int]error) {
+	return fetchMany(ctx, ids, s.FetchByID, buildFetchOptions(opts))
 }
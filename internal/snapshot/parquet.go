@@ -0,0 +1,280 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The subset of the Parquet format the snapshots use: flat schemas of required INT64 and UTF8
+// columns, one row group per file and one PLAIN-encoded, gzipped data page per column chunk.
+// Without optional or repeated columns the pages carry no repetition or definition levels.
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet enums (parquet.thrift)
+const (
+	parquetInt64     = 2 // Type.INT64
+	parquetByteArray = 6 // Type.BYTE_ARRAY
+	parquetRequired  = 0 // FieldRepetitionType.REQUIRED
+	parquetUTF8      = 0 // ConvertedType.UTF8
+	parquetPlain     = 0 // Encoding.PLAIN
+	parquetRLE       = 3 // Encoding.RLE
+	parquetGzip      = 2 // CompressionCodec.GZIP
+	parquetDataPage  = 0 // PageType.DATA_PAGE
+)
+
+// parquetCreatedBy identifies the writer in the file metadata
+const parquetCreatedBy = "internship-project snapshot writer"
+
+// parquetColumn is a column of a Parquet file; exactly one of ints and strings holds its values
+type parquetColumn struct {
+	name    string
+	ints    []int64
+	strings []string
+}
+
+// physicalType returns the Parquet type of the column
+func (c *parquetColumn) physicalType() int32 {
+	if c.strings != nil {
+		return parquetByteArray
+	}
+	return parquetInt64
+}
+
+// plain encodes the values of the column with the PLAIN encoding
+func (c *parquetColumn) plain() []byte {
+	var buf []byte
+	if c.strings != nil {
+		for _, s := range c.strings {
+			s = strings.ToValidUTF8(s, "�")
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(len(s)))
+			buf = append(buf, s...)
+		}
+		return buf
+	}
+	for _, v := range c.ints {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(v))
+	}
+	return buf
+}
+
+// columnChunk locates a written column chunk for the file metadata
+type columnChunk struct {
+	offset       int64
+	uncompressed int64
+	compressed   int64
+}
+
+// writeParquet writes a Parquet file of rows rows with the columns to w, all columns holding
+// rows values, and returns the number of bytes written
+func writeParquet(w io.Writer, columns []*parquetColumn, rows int) (int64, error) {
+	cw := &countingWriter{w: w}
+	cw.Write(parquetMagic)
+
+	chunks := make([]columnChunk, len(columns))
+	for i, column := range columns {
+		if n := max(len(column.ints), len(column.strings)); n != rows {
+			return cw.n, fmt.Errorf("column %s has %d values for %d rows", column.name, n, rows)
+		}
+		plain := column.plain()
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		zw.Write(plain)
+		if err := zw.Close(); err != nil {
+			return cw.n, err
+		}
+
+		header := encodePageHeader(rows, len(plain), compressed.Len())
+		chunks[i] = columnChunk{
+			offset:       cw.n,
+			uncompressed: int64(len(header) + len(plain)),
+			compressed:   int64(len(header) + compressed.Len()),
+		}
+		cw.Write(header)
+		cw.Write(compressed.Bytes())
+	}
+
+	footer := encodeFileMetaData(columns, chunks, rows)
+	cw.Write(footer)
+	cw.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	cw.Write(parquetMagic)
+	return cw.n, cw.err
+}
+
+// encodePageHeader encodes the PageHeader of a data page of required values
+func encodePageHeader(values, uncompressed, compressed int) []byte {
+	t := &thriftWriter{}
+	t.i32(1, parquetDataPage)
+	t.i32(2, int32(uncompressed))
+	t.i32(3, int32(compressed))
+	t.beginStruct(5) // DataPageHeader
+	t.i32(1, int32(values))
+	t.i32(2, parquetPlain)
+	t.i32(3, parquetRLE)
+	t.i32(4, parquetRLE)
+	t.endStruct()
+	t.stop()
+	return t.buf
+}
+
+// encodeFileMetaData encodes the FileMetaData footer of a file with one row group
+func encodeFileMetaData(columns []*parquetColumn, chunks []columnChunk, rows int) []byte {
+	t := &thriftWriter{}
+	t.i32(1, 1) // version
+
+	t.listHeader(2, thriftStruct, len(columns)+1)
+	t.beginElement() // root
+	t.str(4, "schema")
+	t.i32(5, int32(len(columns)))
+	t.endStruct()
+	for _, column := range columns {
+		t.beginElement()
+		t.i32(1, column.physicalType())
+		t.i32(3, parquetRequired)
+		t.str(4, column.name)
+		if column.strings != nil {
+			t.i32(6, parquetUTF8)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, int64(rows))
+
+	var total int64
+	for _, chunk := range chunks {
+		total += chunk.uncompressed
+	}
+	t.listHeader(4, thriftStruct, 1)
+	t.beginElement() // RowGroup
+	t.listHeader(1, thriftStruct, len(columns))
+	for i, column := range columns {
+		t.beginElement() // ColumnChunk
+		t.i64(2, chunks[i].offset)
+		t.beginStruct(3) // ColumnMetaData
+		t.i32(1, column.physicalType())
+		t.listHeader(2, thriftI32, 1)
+		t.varint(zigzag(parquetPlain))
+		t.listHeader(3, thriftBinary, 1)
+		t.varint(uint64(len(column.name)))
+		t.buf = append(t.buf, column.name...)
+		t.i32(4, parquetGzip)
+		t.i64(5, int64(rows))
+		t.i64(6, chunks[i].uncompressed)
+		t.i64(7, chunks[i].compressed)
+		t.i64(9, chunks[i].offset)
+		t.endStruct()
+		t.endStruct()
+	}
+	t.i64(2, total)
+	t.i64(3, int64(rows))
+	t.endStruct()
+
+	t.str(6, parquetCreatedBy)
+	t.stop()
+	return t.buf
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, which Parquet uses for its
+// page headers and footer. Field IDs are delta-encoded against the previous field of the
+// enclosing struct, so the writer keeps the last ID of each open struct.
+type thriftWriter struct {
+	buf    []byte
+	lastID int16
+	stack  []int16
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) str(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+func (t *thriftWriter) listHeader(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elemType)
+		return
+	}
+	t.buf = append(t.buf, 0xF0|elemType)
+	t.varint(uint64(size))
+}
+
+// beginStruct opens a struct field; beginElement opens a struct element of a list
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginElement()
+}
+
+func (t *thriftWriter) beginElement() {
+	t.stack = append(t.stack, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.lastID = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// stop ends the fields of the current struct
+func (t *thriftWriter) stop() {
+	t.buf = append(t.buf, 0)
+}
+
+// countingWriter counts the bytes written and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
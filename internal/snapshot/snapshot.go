@@ -0,0 +1,351 @@
+// Package snapshot exports shareable dataset snapshots for research users: the public fields of
+// the stored items, with authors pseudonymized, as Parquet files partitioned by kind and
+// creation month, described by a manifest and verifiable with their checksums. Nothing but items
+// is exported: no users, tenants, API keys or usage data.
+package snapshot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/spam"
+)
+
+// Kinds are the item kinds a snapshot exports by default
+var Kinds = []string{"story", "ask", "job", "poll", "comment"}
+
+// Options selects what a snapshot exports and where
+type Options struct {
+	// Dir is the directory the snapshot is written under, in a subdirectory named by its ID
+	Dir   string
+	Kinds []string
+	// Tenant is the only tenant whose items are exported
+	Tenant string
+	Start  int64 // created_at lower bound (unix seconds, inclusive); 0 is open
+	End    int64 // created_at upper bound (unix seconds, exclusive); 0 is now
+	// MaxSpamScore excludes the items scored as spam at or above it; SPAM_THRESHOLD by default
+	MaxSpamScore float64
+	// RowsPerFile caps the rows of a Parquet file; a month with more items is split in parts
+	RowsPerFile int
+	// BatchSize is how many items are read per query
+	BatchSize int
+	// AuthorSalt keys the author pseudonyms. With the same salt an author keeps its pseudonym
+	// across snapshots; without one a random salt is drawn, so pseudonyms cannot be linked
+	// between snapshots.
+	AuthorSalt []byte
+}
+
+// Column describes a column of the snapshot files
+type Column struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+
+	int func(*models.SnapshotItem) int64
+	str func(*models.SnapshotItem) string
+}
+
+// Columns are the columns of every snapshot file, in file order
+var Columns = []Column{
+	{Name: "id", Type: "int64", Description: "item ID",
+		int: func(i *models.SnapshotItem) int64 { return int64(i.ID) }},
+	{Name: "kind", Type: "string", Description: "story, ask, job, poll or comment",
+		str: func(i *models.SnapshotItem) string { return i.Kind }},
+	{Name: "title", Type: "string", Description: "title; empty for comments",
+		str: func(i *models.SnapshotItem) string { return i.Title }},
+	{Name: "url", Type: "string", Description: "link of stories and jobs",
+		str: func(i *models.SnapshotItem) string { return i.URL }},
+	{Name: "text", Type: "string", Description: "HTML text of asks, jobs and comments",
+		str: func(i *models.SnapshotItem) string { return i.Text }},
+	{Name: "score", Type: "int64", Description: "score when the snapshot was taken; 0 for comments",
+		int: func(i *models.SnapshotItem) int64 { return int64(i.Score) }},
+	{Name: "by", Type: "string", Description: "pseudonym of the author, stable within the snapshot",
+		str: func(i *models.SnapshotItem) string { return i.Author }},
+	{Name: "time", Type: "int64", Description: "creation time (unix seconds)",
+		int: func(i *models.SnapshotItem) int64 { return i.Created_At }},
+	{Name: "parent", Type: "int64", Description: "parent item of comments; 0 otherwise",
+		int: func(i *models.SnapshotItem) int64 { return int64(i.Parent) }},
+	{Name: "descendants", Type: "int64", Description: "comments of stories, replies of asks and polls",
+		int: func(i *models.SnapshotItem) int64 { return int64(i.Descendants) }},
+}
+
+// File is a Parquet file of a snapshot; Path is relative to the snapshot directory
+type File struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Month  string `json:"month"`
+	Rows   int    `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a snapshot; it is written as manifest.json next to its files
+type Manifest struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Start      int64     `json:"start"`
+	End        int64     `json:"end"`
+	Kinds      []string  `json:"kinds"`
+	Rows       int64     `json:"rows"`
+	Format     string    `json:"format"`
+	Partitions []string  `json:"partitions"`
+	Columns    []Column  `json:"columns"`
+	Files      []File    `json:"files"`
+}
+
+// Names of the files next to the partitions
+const (
+	ManifestFile  = "manifest.json"
+	ChecksumsFile = "SHA256SUMS"
+)
+
+// Generator writes dataset snapshots of the items of a store
+type Generator struct {
+	store repository.SnapshotRepository
+	now   func() time.Time
+}
+
+// NewGenerator creates a generator reading the items from store
+func NewGenerator(store repository.SnapshotRepository) *Generator {
+	return &Generator{store: store, now: time.Now}
+}
+
+// Run writes a snapshot and returns its manifest. The snapshot is written to a temporary
+// directory renamed into place once complete, so a snapshot directory is never partial.
+func (g *Generator) Run(ctx context.Context, opts Options) (*Manifest, error) {
+	if len(opts.Kinds) == 0 {
+		opts.Kinds = Kinds
+	}
+	if opts.Tenant == "" {
+		opts.Tenant = models.DefaultTenant
+	}
+	if opts.MaxSpamScore <= 0 {
+		opts.MaxSpamScore = spam.Threshold()
+	}
+	if opts.RowsPerFile <= 0 {
+		opts.RowsPerFile = 100000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5000
+	}
+	if len(opts.AuthorSalt) == 0 {
+		opts.AuthorSalt = make([]byte, 32)
+		if _, err := rand.Read(opts.AuthorSalt); err != nil {
+			return nil, fmt.Errorf("failed to draw author salt: %w", err)
+		}
+	}
+
+	now := g.now().UTC()
+	if opts.End <= 0 {
+		opts.End = now.Unix()
+	}
+	manifest := &Manifest{
+		ID:         now.Format("20060102T150405Z"),
+		CreatedAt:  now,
+		Start:      opts.Start,
+		End:        opts.End,
+		Kinds:      opts.Kinds,
+		Format:     "parquet",
+		Partitions: []string{"kind", "month"},
+		Columns:    Columns,
+	}
+
+	final := filepath.Join(opts.Dir, manifest.ID)
+	tmp := filepath.Join(opts.Dir, "."+manifest.ID+".tmp")
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, kind := range opts.Kinds {
+		if err := g.writeKind(ctx, tmp, kind, opts, manifest); err != nil {
+			return nil, fmt.Errorf("failed to export %s items: %w", kind, err)
+		}
+	}
+	if err := writeManifest(tmp, manifest); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return nil, fmt.Errorf("failed to publish snapshot: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeKind exports the items of a kind in creation order, starting a file at each new month
+// and every RowsPerFile rows
+func (g *Generator) writeKind(ctx context.Context, dir, kind string, opts Options, manifest *Manifest) error {
+	var (
+		pending   []*models.SnapshotItem
+		month     string
+		part      int
+		afterTime int64 = opts.Start - 1
+		afterID   int
+	)
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		file, err := writePartition(dir, kind, month, part, pending)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, *file)
+		manifest.Rows += int64(file.Rows)
+		pending = pending[:0]
+		part++
+		return nil
+	}
+
+	for {
+		items, err := g.store.GetSnapshotItems(ctx, kind, opts.Tenant, opts.Start, opts.End, opts.MaxSpamScore,
+			afterTime, afterID, opts.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			itemMonth := time.Unix(item.Created_At, 0).UTC().Format("2006-01")
+			if itemMonth != month || len(pending) >= opts.RowsPerFile {
+				if err := flush(); err != nil {
+					return err
+				}
+				if itemMonth != month {
+					month, part = itemMonth, 0
+				}
+			}
+			item.Author = pseudonym(opts.AuthorSalt, item.Author)
+			pending = append(pending, item)
+		}
+		if len(items) < opts.BatchSize {
+			return flush()
+		}
+		last := items[len(items)-1]
+		afterTime, afterID = last.Created_At, last.ID
+	}
+}
+
+// writePartition writes the items as part of the kind=/month= partition and describes the file
+func writePartition(dir, kind, month string, part int, items []*models.SnapshotItem) (*File, error) {
+	rel := filepath.Join("kind="+kind, "month="+month, fmt.Sprintf("part-%05d.parquet", part))
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create partition: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", rel, err)
+	}
+	defer f.Close()
+
+	columns := make([]*parquetColumn, len(Columns))
+	for i, c := range Columns {
+		column := &parquetColumn{name: c.Name}
+		if c.str != nil {
+			column.strings = make([]string, len(items))
+			for j, item := range items {
+				column.strings[j] = c.str(item)
+			}
+		} else {
+			column.ints = make([]int64, len(items))
+			for j, item := range items {
+				column.ints[j] = c.int(item)
+			}
+		}
+		columns[i] = column
+	}
+
+	hash := sha256.New()
+	n, err := writeParquet(io.MultiWriter(f, hash), columns, len(items))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	return &File{
+		Path:   filepath.ToSlash(rel),
+		Kind:   kind,
+		Month:  month,
+		Rows:   len(items),
+		Bytes:  n,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// writeManifest writes the manifest and the checksums of the files and the manifest, in the
+// format of sha256sum so a download can be checked with "sha256sum -c SHA256SUMS"
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	var sums strings.Builder
+	for _, file := range manifest.Files {
+		fmt.Fprintf(&sums, "%s  %s\n", file.SHA256, file.Path)
+	}
+	sum := sha256.Sum256(data)
+	fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), ManifestFile)
+	if err := os.WriteFile(filepath.Join(dir, ChecksumsFile), []byte(sums.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write checksums: %w", err)
+	}
+	return nil
+}
+
+// pseudonym replaces an author with a keyed hash, so the items of an author can be grouped
+// without revealing who they are
+func pseudonym(salt []byte, author string) string {
+	if author == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(author))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Prune removes the oldest complete snapshots under dir beyond the newest keep, and returns the
+// IDs of the removed ones
+func Prune(dir string, keep int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), ManifestFile)); err == nil {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Strings(ids)
+
+	var removed []string
+	for len(ids) > max(keep, 0) {
+		if err := os.RemoveAll(filepath.Join(dir, ids[0])); err != nil {
+			return removed, err
+		}
+		removed = append(removed, ids[0])
+		ids = ids[1:]
+	}
+	return removed, nil
+}
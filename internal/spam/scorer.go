@@ -0,0 +1,156 @@
+package spam
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// Rule weights; an item's score is their sum, capped at 1
+const (
+	blockedDomainWeight = 0.8
+	linkOnlyWeight      = 0.5
+	duplicateWeight     = 0.4
+	newAccountWeight    = 0.3
+)
+
+var (
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+)
+
+// Content is the part of an item the scorer looks at
+type Content struct {
+	ID     int
+	Author string
+	Title  string
+	Text   string // may contain HTML, as returned by the HackerNews API
+	URL    string
+}
+
+// AccountCreatedAt returns when an author's account was created (unix seconds), or false if unknown
+type AccountCreatedAt func(ctx context.Context, author string) (int64, bool)
+
+// Scorer flags likely spam with a score between 0 (clean) and 1 (certain spam)
+type Scorer struct {
+	blockedDomains     map[string]bool
+	duplicateThreshold int
+	newAccountAge      time.Duration
+	accountCreatedAt   AccountCreatedAt
+}
+
+// NewScorer creates a scorer configured from SPAM_BLOCKED_DOMAINS, SPAM_DUPLICATE_THRESHOLD
+// and SPAM_NEW_ACCOUNT_AGE; accountCreatedAt may be nil to skip the account age rule
+func NewScorer(accountCreatedAt AccountCreatedAt) *Scorer {
+	blocked := make(map[string]bool)
+	for _, domain := range config.GetEnvList("SPAM_BLOCKED_DOMAINS", nil) {
+		blocked[normalizeDomain(domain)] = true
+	}
+	return &Scorer{
+		blockedDomains:     blocked,
+		duplicateThreshold: config.GetEnvInt("SPAM_DUPLICATE_THRESHOLD", 3),
+		newAccountAge:      config.GetEnvDuration("SPAM_NEW_ACCOUNT_AGE", 7*24*time.Hour),
+		accountCreatedAt:   accountCreatedAt,
+	}
+}
+
+// Threshold returns the score from which items are hidden from default results (SPAM_THRESHOLD)
+func Threshold() float64 {
+	return config.GetEnvFloat("SPAM_THRESHOLD", 0.7)
+}
+
+// Score scores a batch of items by ID. Duplicates are detected within the batch,
+// so items from the same sync run should be scored together.
+func (s *Scorer) Score(ctx context.Context, items []Content) map[int]float64 {
+	duplicates := make(map[string]int)
+	for _, item := range items {
+		duplicates[duplicateKey(item)]++
+	}
+
+	scores := make(map[int]float64, len(items))
+	for _, item := range items {
+		text := plainText(item.Text)
+		links := urlPattern.FindAllString(text, -1)
+		if item.URL != "" {
+			links = append(links, item.URL)
+		}
+
+		score := 0.0
+		if s.hasBlockedDomain(links) {
+			score += blockedDomainWeight
+		}
+		if item.Title == "" && isLinkOnly(text) {
+			score += linkOnlyWeight
+		}
+		if s.duplicateThreshold > 0 && duplicates[duplicateKey(item)] >= s.duplicateThreshold {
+			score += duplicateWeight
+			if s.isNewAccount(ctx, item.Author) {
+				score += newAccountWeight
+			}
+		}
+
+		if score > 1 {
+			score = 1
+		}
+		scores[item.ID] = score
+	}
+	return scores
+}
+
+func (s *Scorer) hasBlockedDomain(links []string) bool {
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := normalizeDomain(u.Hostname())
+		// Match the domain itself and any of its subdomains
+		for host != "" {
+			if s.blockedDomains[host] {
+				return true
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found {
+				break
+			}
+			host = parent
+		}
+	}
+	return false
+}
+
+func (s *Scorer) isNewAccount(ctx context.Context, author string) bool {
+	if s.accountCreatedAt == nil || author == "" {
+		return false
+	}
+	createdAt, ok := s.accountCreatedAt(ctx, author)
+	return ok && time.Since(time.Unix(createdAt, 0)) < s.newAccountAge
+}
+
+// isLinkOnly reports whether text is one or more links with at most a couple of words around them
+func isLinkOnly(text string) bool {
+	if !urlPattern.MatchString(text) {
+		return false
+	}
+	return len(strings.Fields(urlPattern.ReplaceAllString(text, " "))) <= 2
+}
+
+// duplicateKey identifies identical posts by the same author
+func duplicateKey(item Content) string {
+	body := strings.ToLower(strings.Join(strings.Fields(item.Title+" "+plainText(item.Text)+" "+item.URL), " "))
+	return item.Author + "\x00" + body
+}
+
+// plainText strips HTML tags and entities
+func plainText(text string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(text, " "))
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+}
@@ -0,0 +1,423 @@
+// Package status gathers the one-shot view of the system printed by the status command: the
+// rows of the tables, how recent the items are, the last job runs, and the state of the event
+// transport, Redis and the search cluster. Every section is collected on its own, so an
+// unreachable dependency only fails its section.
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository"
+	"internship-project/internal/search"
+	"internship-project/internal/transport"
+)
+
+// DefaultTables are the tables whose rows are reported when Collector.TableNames is empty
+var DefaultTables = []string{
+	"stories", "asks", "jobs", "comments", "comments_cold", "polls", "poll_options", "users",
+	"item_change_log", "item_tombstones", "item_dead_letters",
+}
+
+// Collector gathers a Report. The repositories are nil when the database is unreachable, with
+// DatabaseErr telling why; Search is nil when no cluster is configured.
+type Collector struct {
+	Tables      repository.MaintenanceRepository
+	Stats       repository.StatsRepository
+	JobRuns     repository.JobRunRepository
+	DatabaseErr error
+
+	Search *search.Client
+
+	TableNames     []string      // DefaultTables when empty
+	RedisScanLimit int           // keys sampled for the Redis key prefixes; 10000 when 0
+	Timeout        time.Duration // per section; 5s when 0
+}
+
+// Report is the state of the system at Generated_At
+type Report struct {
+	Generated_At int64            `json:"generated_at"` // unix seconds
+	Database     DatabaseSection  `json:"database"`
+	Jobs         JobsSection      `json:"jobs"`
+	Transport    TransportSection `json:"transport"`
+	Redis        RedisSection     `json:"redis"`
+	Search       SearchSection    `json:"search"`
+}
+
+// DatabaseSection holds the row estimates of the tables and the freshness of the items
+type DatabaseSection struct {
+	Error  string                  `json:"error,omitempty"`
+	Tables []TableRows             `json:"tables,omitempty"`
+	Items  []*models.ItemFreshness `json:"items,omitempty"`
+}
+
+// TableRows is the size of a table, summed over its partitions
+type TableRows struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"` // live rows estimated by the statistics collector
+	Bytes int64  `json:"bytes"`
+}
+
+// JobsSection holds the last runs of the scheduled jobs
+type JobsSection struct {
+	Error string           `json:"error,omitempty"`
+	Runs  []*models.JobRun `json:"runs,omitempty"`
+}
+
+// TransportSection tells whether the event transport is reachable. Only Kafka is checked: it
+// reports its brokers and the configured topics it does not have yet.
+type TransportSection struct {
+	Name          string   `json:"name"`
+	Disabled      bool     `json:"disabled,omitempty"` // the events sink is off for the deployment
+	Checked       bool     `json:"checked"`
+	Brokers       int      `json:"brokers,omitempty"`
+	Topics        int      `json:"topics,omitempty"`
+	MissingTopics []string `json:"missing_topics,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// RedisSection holds the size of the Redis database and of its keys grouped by prefix
+type RedisSection struct {
+	Addr       string      `json:"addr"`
+	Keys       int64       `json:"keys"`
+	UsedMemory int64       `json:"used_memory"` // bytes
+	Prefixes   []KeyPrefix `json:"prefixes,omitempty"`
+	Sampled    bool        `json:"sampled"` // the prefixes only count the first RedisScanLimit keys
+	Error      string      `json:"error,omitempty"`
+}
+
+// KeyPrefix sums the keys sharing the text before their first colon, e.g. "item"
+type KeyPrefix struct {
+	Prefix string `json:"prefix"`
+	Keys   int64  `json:"keys"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// SearchSection holds the document count of every search index
+type SearchSection struct {
+	Disabled   bool         `json:"disabled,omitempty"` // the opensearch sink is off for the deployment
+	Configured bool         `json:"configured"`
+	Indexes    []IndexCount `json:"indexes,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// IndexCount is the document count of the index of a kind
+type IndexCount struct {
+	Kind  string `json:"kind"`
+	Index string `json:"index"`
+	Docs  int64  `json:"docs"`
+}
+
+// OK reports whether every section was collected without error; an unconfigured search cluster
+// or an unchecked transport are not errors
+func (r *Report) OK() bool {
+	return r.Database.Error == "" && r.Jobs.Error == "" && r.Transport.Error == "" &&
+		r.Redis.Error == "" && r.Search.Error == ""
+}
+
+// Collect gathers the report, one section after the other
+func (c *Collector) Collect(ctx context.Context) *Report {
+	report := &Report{Generated_At: time.Now().Unix()}
+	report.Database = c.database(ctx)
+	report.Jobs = c.jobs(ctx)
+	report.Transport = c.transport(ctx)
+	report.Redis = c.redis(ctx)
+	report.Search = c.search(ctx)
+	return report
+}
+
+// section returns a context bounded by the section timeout
+func (c *Collector) section(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// databaseErr is the error of the database sections without repositories
+func (c *Collector) databaseErr() string {
+	if c.DatabaseErr != nil {
+		return c.DatabaseErr.Error()
+	}
+	return "database not connected"
+}
+
+func (c *Collector) database(ctx context.Context) DatabaseSection {
+	if c.Tables == nil || c.Stats == nil {
+		return DatabaseSection{Error: c.databaseErr()}
+	}
+	ctx, cancel := c.section(ctx)
+	defer cancel()
+
+	tables := c.TableNames
+	if len(tables) == 0 {
+		tables = DefaultTables
+	}
+	var section DatabaseSection
+	stats, err := c.Tables.GetTableStats(ctx, tables)
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	for _, t := range stats {
+		section.Tables = append(section.Tables, TableRows{Table: t.Table, Rows: t.LiveRows, Bytes: t.TableBytes + t.IndexBytes})
+	}
+	if section.Items, err = c.Stats.GetItemFreshness(ctx); err != nil {
+		section.Error = err.Error()
+	}
+	return section
+}
+
+func (c *Collector) jobs(ctx context.Context) JobsSection {
+	if c.JobRuns == nil {
+		return JobsSection{Error: c.databaseErr()}
+	}
+	ctx, cancel := c.section(ctx)
+	defer cancel()
+
+	runs, err := c.JobRuns.GetAll(ctx)
+	if err != nil {
+		return JobsSection{Error: err.Error()}
+	}
+	return JobsSection{Runs: runs}
+}
+
+func (c *Collector) transport(ctx context.Context) TransportSection {
+	section := TransportSection{Name: transport.Transport(), Disabled: !config.SinkEnabled(config.SinkEvents)}
+	if section.Disabled || section.Name != "kafka" {
+		return section
+	}
+	ctx, cancel := c.section(ctx)
+	defer cancel()
+
+	section.Checked = true
+	topics, err := transport.CheckKafkaTopics(ctx)
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	section.Brokers = topics.Brokers
+	section.Topics = topics.Topics
+	section.MissingTopics = topics.Missing
+	return section
+}
+
+func (c *Collector) redis(ctx context.Context) RedisSection {
+	section := RedisSection{Addr: redis.GetRedisConfig().Addr}
+	ctx, cancel := c.section(ctx)
+	defer cancel()
+
+	rdb := redis.NewClient()
+	defer rdb.Close()
+
+	var err error
+	if section.Keys, err = rdb.DBSize(ctx).Result(); err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	info, err := rdb.Info(ctx, "memory").Result()
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	section.UsedMemory = infoField(info, "used_memory")
+
+	limit := c.RedisScanLimit
+	if limit <= 0 {
+		limit = 10000
+	}
+	prefixes := make(map[string]*KeyPrefix)
+	var cursor uint64
+	scanned := 0
+	for {
+		var keys []string
+		keys, cursor, err = rdb.Scan(ctx, cursor, "*", 1000).Result()
+		if err != nil {
+			section.Error = err.Error()
+			return section
+		}
+		if len(keys) > limit-scanned {
+			keys = keys[:limit-scanned]
+			section.Sampled = true
+		}
+		scanned += len(keys)
+
+		pipe := rdb.Pipeline()
+		usages := make([]*goredis.IntCmd, len(keys))
+		for i, key := range keys {
+			usages[i] = pipe.MemoryUsage(ctx, key, 0)
+		}
+		if len(keys) > 0 {
+			// A key expiring between the scan and its usage fails alone; its size counts as 0
+			pipe.Exec(ctx)
+		}
+		for i, key := range keys {
+			prefix, _, _ := strings.Cut(key, ":")
+			p, ok := prefixes[prefix]
+			if !ok {
+				p = &KeyPrefix{Prefix: prefix}
+				prefixes[prefix] = p
+			}
+			p.Keys++
+			p.Bytes += usages[i].Val()
+		}
+
+		if cursor == 0 {
+			break
+		}
+		if scanned >= limit {
+			section.Sampled = true
+			break
+		}
+	}
+	for _, p := range prefixes {
+		section.Prefixes = append(section.Prefixes, *p)
+	}
+	sort.Slice(section.Prefixes, func(i, j int) bool {
+		if section.Prefixes[i].Bytes != section.Prefixes[j].Bytes {
+			return section.Prefixes[i].Bytes > section.Prefixes[j].Bytes
+		}
+		return section.Prefixes[i].Prefix < section.Prefixes[j].Prefix
+	})
+	return section
+}
+
+// infoField returns a numeric field of the output of the Redis INFO command, 0 when absent
+func infoField(info, name string) int64 {
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), name+":"); ok {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+func (c *Collector) search(ctx context.Context) SearchSection {
+	if !config.SinkEnabled(config.SinkOpenSearch) {
+		return SearchSection{Disabled: true}
+	}
+	if c.Search == nil {
+		return SearchSection{}
+	}
+	ctx, cancel := c.section(ctx)
+	defer cancel()
+
+	section := SearchSection{Configured: true}
+	for _, kind := range search.Kinds() {
+		index, _ := c.Search.Index(kind)
+		docs, err := c.Search.Count(ctx, kind)
+		if err != nil {
+			section.Error = err.Error()
+			return section
+		}
+		section.Indexes = append(section.Indexes, IndexCount{Kind: kind, Index: index, Docs: docs})
+	}
+	return section
+}
+
+// Print writes the report as aligned text sections
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	now := time.Unix(r.Generated_At, 0)
+
+	fmt.Fprintln(tw, "DATABASE")
+	if r.Database.Error != "" {
+		fmt.Fprintf(tw, "  error: %s\n", r.Database.Error)
+	}
+	for _, t := range r.Database.Tables {
+		fmt.Fprintf(tw, "  %s\t%d rows\t%s\n", t.Table, t.Rows, formatBytes(t.Bytes))
+	}
+	for _, f := range r.Database.Items {
+		fmt.Fprintf(tw, "  newest %s\t%s\tlast written %s\n", f.Kind,
+			formatTime(now, f.Newest_Created_At), formatTime(now, f.Last_Updated_At/1000))
+	}
+
+	fmt.Fprintln(tw, "\nJOBS")
+	if r.Jobs.Error != "" {
+		fmt.Fprintf(tw, "  error: %s\n", r.Jobs.Error)
+	} else if len(r.Jobs.Runs) == 0 {
+		fmt.Fprintln(tw, "  no runs recorded")
+	}
+	for _, run := range r.Jobs.Runs {
+		fmt.Fprintf(tw, "  %s\tfinished %s\ttook %v\t%d runs\n", run.Name, formatTime(now, run.Last_Finished_At),
+			time.Duration(run.Last_Duration_Ms)*time.Millisecond, run.Runs)
+	}
+
+	fmt.Fprintln(tw, "\nTRANSPORT")
+	switch t := r.Transport; {
+	case t.Disabled:
+		fmt.Fprintf(tw, "  %s\tdisabled\n", t.Name)
+	case t.Error != "":
+		fmt.Fprintf(tw, "  %s\terror: %s\n", t.Name, t.Error)
+	case !t.Checked:
+		fmt.Fprintf(tw, "  %s\tnot checked\n", t.Name)
+	default:
+		fmt.Fprintf(tw, "  %s\treachable\t%d brokers\t%d topics\n", t.Name, t.Brokers, t.Topics)
+		if len(t.MissingTopics) > 0 {
+			fmt.Fprintf(tw, "  missing topics\t%s\n", strings.Join(t.MissingTopics, ", "))
+		}
+	}
+
+	fmt.Fprintln(tw, "\nREDIS")
+	if r.Redis.Error != "" {
+		fmt.Fprintf(tw, "  %s\terror: %s\n", r.Redis.Addr, r.Redis.Error)
+	} else {
+		fmt.Fprintf(tw, "  %s\t%d keys\t%s used\n", r.Redis.Addr, r.Redis.Keys, formatBytes(r.Redis.UsedMemory))
+		for _, p := range r.Redis.Prefixes {
+			fmt.Fprintf(tw, "  %s:*\t%d keys\t%s\n", p.Prefix, p.Keys, formatBytes(p.Bytes))
+		}
+		if r.Redis.Sampled {
+			fmt.Fprintln(tw, "  (prefixes sampled from the first scanned keys)")
+		}
+	}
+
+	fmt.Fprintln(tw, "\nSEARCH")
+	switch s := r.Search; {
+	case s.Disabled:
+		fmt.Fprintln(tw, "  disabled")
+	case !s.Configured:
+		fmt.Fprintln(tw, "  not configured")
+	case s.Error != "":
+		fmt.Fprintf(tw, "  error: %s\n", s.Error)
+	}
+	for _, index := range r.Search.Indexes {
+		fmt.Fprintf(tw, "  %s\t%s\t%d docs\n", index.Kind, index.Index, index.Docs)
+	}
+	return tw.Flush()
+}
+
+// formatTime renders a unix time with its age relative to now; 0 is "never"
+func formatTime(now time.Time, unix int64) string {
+	if unix == 0 {
+		return "never"
+	}
+	t := time.Unix(unix, 0)
+	return fmt.Sprintf("%s (%v ago)", t.UTC().Format(time.RFC3339), now.Sub(t).Round(time.Second))
+}
+
+// formatBytes renders a size with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,198 @@
+// Package tasks runs the ad-hoc background work queued in the tasks table: admin refetches,
+// reindexes and repair jobs survive restarts, are retried with backoff and can be inspected,
+// instead of running in fire-and-forget goroutines.
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/tracing"
+)
+
+// ErrUnknownType is returned when enqueuing a task type no handler is registered for
+var ErrUnknownType = errors.New("unknown task type")
+
+// taskRuns counts the task attempts by "<type>.<outcome>", the outcome being done, retried or failed
+var taskRuns = expvar.NewMap("task_runs")
+
+// Handler runs a task from its JSON payload; an error fails the attempt
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Worker claims due tasks from the queue and runs the handler registered for their type
+type Worker struct {
+	store repository.TaskRepository
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	concurrency  int
+	pollInterval time.Duration
+	lease        time.Duration
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	maxAttempts  int
+}
+
+// NewWorker creates a worker configured by TASKS_CONCURRENCY, TASKS_POLL_INTERVAL, TASKS_LEASE,
+// TASKS_RETRY_BACKOFF, TASKS_MAX_BACKOFF and TASKS_MAX_ATTEMPTS
+func NewWorker(store repository.TaskRepository) *Worker {
+	return &Worker{
+		store:        store,
+		handlers:     make(map[string]Handler),
+		concurrency:  config.GetEnvInt("TASKS_CONCURRENCY", 2),
+		pollInterval: config.GetEnvDuration("TASKS_POLL_INTERVAL", 5*time.Second),
+		lease:        config.GetEnvDuration("TASKS_LEASE", 15*time.Minute),
+		retryBackoff: config.GetEnvDuration("TASKS_RETRY_BACKOFF", 30*time.Second),
+		maxBackoff:   config.GetEnvDuration("TASKS_MAX_BACKOFF", time.Hour),
+		maxAttempts:  config.GetEnvInt("TASKS_MAX_ATTEMPTS", 5),
+	}
+}
+
+// Register sets the handler of a task type; register every type before Run
+func (w *Worker) Register(taskType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[taskType] = handler
+}
+
+// Handles reports whether a handler is registered for the task type
+func (w *Worker) Handles(taskType string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.handlers[taskType]
+	return ok
+}
+
+// Enqueue queues a task of a registered type with the JSON encoding of payload, due after delay
+func (w *Worker) Enqueue(ctx context.Context, taskType string, payload interface{}, delay time.Duration) (*models.Task, error) {
+	if !w.Handles(taskType) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, taskType)
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s task payload: %w", taskType, err)
+	}
+	task := &models.Task{
+		Type:        taskType,
+		Payload:     encoded,
+		MaxAttempts: w.maxAttempts,
+		RunAfter:    time.Now().Add(delay).Unix(),
+	}
+	if err := w.store.Enqueue(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Run processes tasks with TASKS_CONCURRENCY loops until ctx is done, each polling the queue
+// every TASKS_POLL_INTERVAL while it is empty
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(w.concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := w.RunNext(ctx)
+		if err != nil {
+			tracing.Logf(ctx, "Error processing tasks: %v", err)
+		}
+		if ran && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// RunNext claims one due task and runs it, reporting whether there was one. The task is leased
+// for TASKS_LEASE: its handler is canceled then, and a task still running past its lease, whose
+// worker died, is claimed again.
+func (w *Worker) RunNext(ctx context.Context) (bool, error) {
+	w.mu.RLock()
+	types := make([]string, 0, len(w.handlers))
+	for taskType := range w.handlers {
+		types = append(types, taskType)
+	}
+	w.mu.RUnlock()
+	if len(types) == 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	task, err := w.store.Claim(ctx, types, now.Unix(), now.Add(w.lease).Unix())
+	if err != nil || task == nil {
+		return false, err
+	}
+
+	// The outcome is recorded even if the worker is stopping
+	storeCtx := context.WithoutCancel(ctx)
+	if task.Attempts > task.MaxAttempts {
+		taskRuns.Add(task.Type+".failed", 1)
+		return true, w.store.Fail(storeCtx, task.ID, "lease expired on the last attempt: "+task.LastError)
+	}
+
+	runCtx, cancel := context.WithTimeout(tracing.WithID(ctx, tracing.NewID()), w.lease)
+	defer cancel()
+	start := time.Now()
+	tracing.Logf(runCtx, "Task %s (%s) started, attempt %d of %d", task.ID, task.Type, task.Attempts, task.MaxAttempts)
+	runErr := w.run(runCtx, task)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	switch {
+	case runErr == nil:
+		tracing.Logf(runCtx, "Task %s (%s) finished in %v", task.ID, task.Type, elapsed)
+		taskRuns.Add(task.Type+".done", 1)
+		return true, w.store.Complete(storeCtx, task.ID)
+	case task.Attempts >= task.MaxAttempts:
+		tracing.Logf(runCtx, "Task %s (%s) failed after %d attempts: %v", task.ID, task.Type, task.Attempts, runErr)
+		taskRuns.Add(task.Type+".failed", 1)
+		return true, w.store.Fail(storeCtx, task.ID, runErr.Error())
+	default:
+		backoff := w.backoff(task.Attempts)
+		tracing.Logf(runCtx, "Task %s (%s) failed in %v, retrying in %v: %v", task.ID, task.Type, elapsed, backoff, runErr)
+		taskRuns.Add(task.Type+".retried", 1)
+		return true, w.store.Retry(storeCtx, task.ID, runErr.Error(), time.Now().Add(backoff).Unix())
+	}
+}
+
+// run calls the task's handler, turning a panic into an error
+func (w *Worker) run(ctx context.Context, task *models.Task) (err error) {
+	w.mu.RLock()
+	handler := w.handlers[task.Type]
+	w.mu.RUnlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, task.Payload)
+}
+
+// backoff doubles TASKS_RETRY_BACKOFF with every failed attempt, up to TASKS_MAX_BACKOFF
+func (w *Worker) backoff(attempts int) time.Duration {
+	backoff := w.retryBackoff
+	for i := 1; i < attempts && backoff < w.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, w.maxBackoff)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Header carries the request ID on API requests and responses
+const Header = "X-Request-ID"
+
+// traceparentHeader is the W3C Trace Context header; its trace-id is reused as the request ID
+const traceparentHeader = "traceparent"
+
+// validID bounds externally provided IDs so they are safe to echo in headers and logs
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+// NewID returns a random 32 hex character ID
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate request ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// FromHeaders returns the ID provided by the caller in X-Request-ID or in the
+// trace-id of a traceparent header, or an empty string when neither is usable
+func FromHeaders(h http.Header) string {
+	if id := strings.TrimSpace(h.Get(Header)); validID.MatchString(id) {
+		return id
+	}
+	// traceparent: version-traceid-parentid-flags
+	if parts := strings.Split(h.Get(traceparentHeader), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		if _, err := hex.DecodeString(parts[1]); err == nil {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+// WithID returns a context carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// IDFromContext returns the request ID of the context, or an empty string
+func IDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logf logs like log.Printf, prefixed with the request ID of the context when it has one
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	if id := IDFromContext(ctx); id != "" {
+		format = "[" + id + "] " + format
+	}
+	log.Printf(format, args...)
+}
+
+// Logln logs like log.Println, prefixed with the request ID of the context when it has one
+func Logln(ctx context.Context, args ...interface{}) {
+	if id := IDFromContext(ctx); id != "" {
+		args = append([]interface{}{"[" + id + "]"}, args...)
+	}
+	log.Println(args...)
+}
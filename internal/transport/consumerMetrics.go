@@ -0,0 +1,207 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateWindow is the period the processing rate of ConsumerStats is measured over, in
+// rateBuckets buckets
+const (
+	rateWindow  = time.Minute
+	rateBuckets = 6
+)
+
+// consumerKey identifies a partition consumed by a group; transports without partitions use 0
+type consumerKey struct {
+	group     string
+	topic     string
+	partition int
+}
+
+// consumerCounters are the running totals of a consumed partition
+type consumerCounters struct {
+	processed int64
+	failed    int64 // handler errors, each redelivered until the message is dropped
+	dropped   int64
+	lag       int64 // messages behind the partition end after the last fetch; -1 when unknown
+	lastAt    time.Time
+
+	// buckets count the processed messages per rateWindow/rateBuckets period, bucketAt being the
+	// start of the newest
+	buckets  [rateBuckets]int64
+	bucketAt time.Time
+}
+
+// ConsumerPartitionStats is a snapshot of the consumption of a partition by a group in this process
+type ConsumerPartitionStats struct {
+	Group     string    `json:"group"`
+	Topic     string    `json:"topic"`
+	Partition int       `json:"partition"`
+	Processed int64     `json:"processed"`
+	Failed    int64     `json:"failed"`
+	Dropped   int64     `json:"dropped"`
+	Lag       int64     `json:"lag"`  // -1 when the transport does not report it
+	Rate      float64   `json:"rate"` // messages processed per second over the last minute
+	LastAt    time.Time `json:"last_message_at"`
+}
+
+var (
+	consumerMu    sync.Mutex
+	consumerStats = make(map[consumerKey]*consumerCounters)
+)
+
+// countersFor returns the counters of a partition; consumerMu must be held
+func countersFor(group, topic string, partition int) *consumerCounters {
+	key := consumerKey{group, topic, partition}
+	c, ok := consumerStats[key]
+	if !ok {
+		c = &consumerCounters{lag: -1}
+		consumerStats[key] = c
+	}
+	return c
+}
+
+// observeHandled records a handler run on a message: processed on success, failed otherwise
+func observeHandled(group, topic string, partition int, err error) {
+	consumerMu.Lock()
+	defer consumerMu.Unlock()
+	c := countersFor(group, topic, partition)
+	now := time.Now()
+	c.lastAt = now
+	if err != nil {
+		c.failed++
+		return
+	}
+	c.processed++
+	c.rotate(now)
+	c.buckets[0]++
+}
+
+// observeDropped records a message dropped after maxDeliveries failed deliveries
+func observeDropped(group, topic string, partition int) {
+	consumerMu.Lock()
+	defer consumerMu.Unlock()
+	countersFor(group, topic, partition).dropped++
+}
+
+// observeLag records how many messages a partition still holds after the one just fetched
+func observeLag(group, topic string, partition int, lag int64) {
+	consumerMu.Lock()
+	defer consumerMu.Unlock()
+	countersFor(group, topic, partition).lag = max(lag, 0)
+}
+
+// rotate shifts the rate buckets so buckets[0] covers now
+func (c *consumerCounters) rotate(now time.Time) {
+	width := rateWindow / rateBuckets
+	shift := int(now.Sub(c.bucketAt) / width)
+	if c.bucketAt.IsZero() || shift >= rateBuckets {
+		c.buckets = [rateBuckets]int64{}
+		c.bucketAt = now.Truncate(width)
+		return
+	}
+	if shift > 0 {
+		copy(c.buckets[shift:], c.buckets[:rateBuckets-shift])
+		for i := 0; i < shift; i++ {
+			c.buckets[i] = 0
+		}
+		c.bucketAt = c.bucketAt.Add(time.Duration(shift) * width)
+	}
+}
+
+// ConsumerStats returns the consumption statistics of the consumers of this process, sorted by
+// group, topic and partition
+func ConsumerStats() []ConsumerPartitionStats {
+	consumerMu.Lock()
+	defer consumerMu.Unlock()
+
+	now := time.Now()
+	stats := make([]ConsumerPartitionStats, 0, len(consumerStats))
+	for key, c := range consumerStats {
+		c.rotate(now)
+		var recent int64
+		for _, n := range c.buckets {
+			recent += n
+		}
+		stats = append(stats, ConsumerPartitionStats{
+			Group: key.group, Topic: key.topic, Partition: key.partition,
+			Processed: c.processed, Failed: c.failed, Dropped: c.dropped, Lag: c.lag,
+			Rate: float64(recent) / rateWindow.Seconds(), LastAt: c.lastAt,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+	return stats
+}
+
+// WritePrometheusMetrics writes ConsumerStats in the Prometheus text exposition format
+func WritePrometheusMetrics(w io.Writer) error {
+	stats := ConsumerStats()
+	metrics := []struct {
+		name, kind, help string
+		value            func(s ConsumerPartitionStats) (float64, bool)
+	}{
+		{"consumer_messages_processed_total", "counter", "Messages handled successfully.",
+			func(s ConsumerPartitionStats) (float64, bool) { return float64(s.Processed), true }},
+		{"consumer_messages_failed_total", "counter", "Failed handler runs; the message is redelivered.",
+			func(s ConsumerPartitionStats) (float64, bool) { return float64(s.Failed), true }},
+		{"consumer_messages_dropped_total", "counter", "Messages dropped after their last delivery failed.",
+			func(s ConsumerPartitionStats) (float64, bool) { return float64(s.Dropped), true }},
+		{"consumer_lag_messages", "gauge", "Messages left in the partition after the last fetched one.",
+			func(s ConsumerPartitionStats) (float64, bool) { return float64(s.Lag), s.Lag >= 0 }},
+		{"consumer_last_message_timestamp_seconds", "gauge", "Time of the last handled message.",
+			func(s ConsumerPartitionStats) (float64, bool) {
+				return float64(s.LastAt.UnixNano()) / 1e9, !s.LastAt.IsZero()
+			}},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, s := range stats {
+			if value, ok := m.value(s); ok {
+				fmt.Fprintf(&b, "%s{group=%s,topic=%s,partition=\"%d\"} %s\n", m.name,
+					strconv.Quote(s.Group), strconv.Quote(s.Topic), s.Partition,
+					strconv.FormatFloat(value, 'g', -1, 64))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WritePrometheusGroupLag writes the broker lag of consumer groups, by group name, in the
+// Prometheus text exposition format
+func WritePrometheusGroupLag(w io.Writer, lags map[string][]PartitionLag) error {
+	groups := make([]string, 0, len(lags))
+	for group := range lags {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var b strings.Builder
+	b.WriteString("# HELP consumer_group_lag_messages Messages the group has not committed yet, as seen by the broker.\n")
+	b.WriteString("# TYPE consumer_group_lag_messages gauge\n")
+	for _, group := range groups {
+		for _, p := range lags[group] {
+			fmt.Fprintf(&b, "consumer_group_lag_messages{group=%s,topic=%s,partition=\"%d\"} %d\n",
+				strconv.Quote(group), strconv.Quote(p.Topic), p.Partition, p.Lag)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+
+	kafkaconfig "internship-project/internal/kafka"
+)
+
+// KafkaPublisher publishes events to Kafka topics
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher for the brokers in KAFKA_BOOTSTRAP_SERVERS, producing
+// as set by the KAFKA_* producer settings; invalid ones fall back to the defaults
+func NewKafkaPublisher() *KafkaPublisher {
+	writer, err := NewKafkaWriter(kafkaconfig.GetKafkaConfig())
+	if err != nil {
+		log.Printf("Using the default kafka producer settings: %v", err)
+		writer, _ = NewKafkaWriter(kafkaconfig.KafkaConfig{BootstrapServers: kafkaconfig.GetKafkaConfig().BootstrapServers})
+	}
+	return &KafkaPublisher{writer: writer}
+}
+
+// kafkaCompressions maps the KAFKA_COMPRESSION values to codecs; none is the zero Compression
+var kafkaCompressions = map[string]kafka.Compression{
+	"":       0,
+	"none":   0,
+	"gzip":   kafka.Gzip,
+	"snappy": kafka.Snappy,
+	"lz4":    kafka.Lz4,
+	"zstd":   kafka.Zstd,
+}
+
+// kafkaAcks maps the KAFKA_ACKS values to the acknowledgements a write waits for
+var kafkaAcks = map[string]kafka.RequiredAcks{
+	"":    kafka.RequireAll,
+	"all": kafka.RequireAll,
+	"-1":  kafka.RequireAll,
+	"1":   kafka.RequireOne,
+	"0":   kafka.RequireNone,
+}
+
+// NewKafkaWriter creates the writer of a publisher from the producer settings of cfg; zero
+// numbers keep the kafka-go defaults
+func NewKafkaWriter(cfg kafkaconfig.KafkaConfig) (*kafka.Writer, error) {
+	compression, ok := kafkaCompressions[strings.ToLower(cfg.Compression)]
+	if !ok {
+		return nil, fmt.Errorf("unknown kafka compression %q", cfg.Compression)
+	}
+	acks, ok := kafkaAcks[strings.ToLower(cfg.Acks)]
+	if !ok {
+		return nil, fmt.Errorf("invalid kafka acks %q: expected all, 1 or 0", cfg.Acks)
+	}
+	if cfg.BatchSize < 0 || cfg.BatchBytes < 0 || cfg.Linger < 0 || cfg.WriteTimeout < 0 || cfg.MaxAttempts < 0 {
+		return nil, errors.New("kafka producer settings must not be negative")
+	}
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(cfg.BootstrapServers, ",")...),
+		Balancer:               &kafka.LeastBytes{},
+		RequiredAcks:           acks,
+		Compression:            compression,
+		BatchSize:              cfg.BatchSize,
+		BatchBytes:             int64(cfg.BatchBytes),
+		BatchTimeout:           cfg.Linger,
+		WriteTimeout:           cfg.WriteTimeout,
+		MaxAttempts:            cfg.MaxAttempts,
+		Async:                  cfg.Async,
+		AllowAutoTopicCreation: true,
+	}
+	if cfg.Async {
+		writer.Completion = func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.Printf("Failed to publish %d messages to kafka: %v", len(messages), err)
+			}
+		}
+	}
+	return writer, nil
+}
+
+// Publish writes every value to topic
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	messages := make([]kafka.Message, len(values))
+	for i, value := range values {
+		messages[i] = kafka.Message{Topic: topic, Value: value}
+	}
+	if err := p.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish to kafka topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// KafkaConsumer reads Kafka topics as a member of a consumer group
+type KafkaConsumer struct {
+	group string
+
+	mu      sync.Mutex
+	readers []*kafka.Reader
+}
+
+// NewKafkaConsumer creates a consumer in the given consumer group
+func NewKafkaConsumer(group string) *KafkaConsumer {
+	return &KafkaConsumer{group: group}
+}
+
+// Consume handles messages of topic until ctx is cancelled.
+// Offsets are committed once the handler succeeds or the message ran out of deliveries.
+func (c *KafkaConsumer) Consume(ctx context.Context, topic string, handler Handler) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: strings.Split(kafkaconfig.GetKafkaConfig().BootstrapServers, ","),
+		GroupID: c.group,
+		Topic:   topic,
+	})
+	c.mu.Lock()
+	c.readers = append(c.readers, reader)
+	c.mu.Unlock()
+
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch from kafka topic %s: %w", topic, err)
+		}
+
+		observeLag(c.group, m.Topic, m.Partition, m.HighWaterMark-m.Offset-1)
+		msg := Message{Topic: m.Topic, Value: m.Value}
+		for attempt := 1; attempt <= maxDeliveries; attempt++ {
+			err = handler(ctx, msg)
+			observeHandled(c.group, m.Topic, m.Partition, err)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			observeDropped(c.group, m.Topic, m.Partition)
+			log.Printf("Dropping kafka message %s/%d@%d after %d attempts: %v",
+				m.Topic, m.Partition, m.Offset, maxDeliveries, err)
+		}
+
+		if err := reader.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to commit kafka offset: %w", err)
+		}
+	}
+}
+
+// Close closes every reader opened by Consume
+func (c *KafkaConsumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for _, reader := range c.readers {
+		errs = append(errs, reader.Close())
+	}
+	c.readers = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	kafkaconfig "internship-project/internal/kafka"
+)
+
+// PartitionLag is how far a consumer group is behind the end of a partition, as seen by the broker
+type PartitionLag struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Committed int64  `json:"committed"` // -1 when the group committed nothing yet
+	End       int64  `json:"end"`
+	Lag       int64  `json:"lag"`
+}
+
+// KafkaGroupLag returns the lag of group on every partition of topics, from the offsets it
+// committed. A partition without a committed offset lags by all the messages it holds.
+func KafkaGroupLag(ctx context.Context, group string, topics []string) ([]PartitionLag, error) {
+	client := &kafka.Client{
+		Addr:    kafka.TCP(strings.Split(kafkaconfig.GetKafkaConfig().BootstrapServers, ",")...),
+		Timeout: 10 * time.Second,
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kafka metadata: %w", err)
+	}
+	partitions := make(map[string][]int)
+	firsts := make(map[string][]kafka.OffsetRequest)
+	lasts := make(map[string][]kafka.OffsetRequest)
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("kafka topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, p := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], p.ID)
+			firsts[topic.Name] = append(firsts[topic.Name], kafka.FirstOffsetOf(p.ID))
+			lasts[topic.Name] = append(lasts[topic.Name], kafka.LastOffsetOf(p.ID))
+		}
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: group, Topics: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets of group %s: %w", group, err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("failed to fetch offsets of group %s: %w", group, committed.Error)
+	}
+	// Brokers reject a partition listed twice in a request, so the start and end of the
+	// partitions are asked for separately
+	starts, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: firsts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kafka offsets: %w", err)
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: lasts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kafka offsets: %w", err)
+	}
+
+	var lags []PartitionLag
+	for topic, list := range offsets.Topics {
+		commits := make(map[int]int64)
+		for _, p := range committed.Topics[topic] {
+			if p.Error == nil {
+				commits[p.Partition] = p.CommittedOffset
+			}
+		}
+		first := make(map[int]int64)
+		for _, p := range starts.Topics[topic] {
+			first[p.Partition] = p.FirstOffset
+		}
+		for _, p := range list {
+			if p.Error != nil {
+				return nil, fmt.Errorf("kafka partition %s/%d: %w", topic, p.Partition, p.Error)
+			}
+			lag := PartitionLag{Topic: topic, Partition: p.Partition, Committed: -1, End: p.LastOffset}
+			if offset, ok := commits[p.Partition]; ok && offset >= 0 {
+				lag.Committed = offset
+				lag.Lag = max(p.LastOffset-offset, 0)
+			} else {
+				lag.Lag = max(p.LastOffset-first[p.Partition], 0)
+			}
+			lags = append(lags, lag)
+		}
+	}
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	if len(lags) == 0 && len(topics) > 0 {
+		return nil, errors.New("no kafka partitions found")
+	}
+	return lags, nil
+}
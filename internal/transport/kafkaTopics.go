@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	kafkaconfig "internship-project/internal/kafka"
+)
+
+// KafkaTopics is what the brokers report about the topics of KAFKA_TOPICS
+type KafkaTopics struct {
+	Brokers int      // brokers in the cluster
+	Topics  int      // topics in the cluster, configured or not
+	Missing []string // configured topics the cluster does not have
+}
+
+// CheckKafkaTopics loads the metadata of the cluster at KAFKA_BOOTSTRAP_SERVERS and lists the
+// configured topics it does not have
+func CheckKafkaTopics(ctx context.Context) (*KafkaTopics, error) {
+	cfg := kafkaconfig.GetKafkaConfig()
+	// A transport of its own, so closing it stops the connection pool goroutines
+	tr := &kafka.Transport{}
+	defer tr.CloseIdleConnections()
+	client := &kafka.Client{Addr: kafka.TCP(strings.Split(cfg.BootstrapServers, ",")...), Transport: tr}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("kafka at %s: %w", cfg.BootstrapServers, err)
+	}
+
+	topics := &KafkaTopics{Brokers: len(metadata.Brokers), Topics: len(metadata.Topics)}
+	existing := make(map[string]bool, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		existing[topic.Name] = true
+	}
+	for _, topic := range strings.Split(cfg.Topic, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" && !existing[topic] {
+			topics.Missing = append(topics.Missing, topic)
+		}
+	}
+	return topics, nil
+}
@@ -0,0 +1,150 @@
+package transport
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"internship-project/internal/config"
+)
+
+// memoryBus routes the messages of the "memory" transport between the publishers and consumers of
+// this process, so it runs without a broker. Every consumer group of a topic has a queue of
+// MEMORY_BUS_BUFFER messages its consumers share; publishing waits while a queue is full. Until a
+// topic has a group, its latest MEMORY_BUS_BUFFER messages wait in a backlog handed to the first
+// group. Nothing is persisted.
+type memoryBus struct {
+	mu      sync.Mutex
+	queues  map[string]map[string]chan Message // topic -> group -> queue
+	backlog map[string][]Message               // topic -> messages published before it had a group
+}
+
+var defaultMemoryBus = &memoryBus{
+	queues:  make(map[string]map[string]chan Message),
+	backlog: make(map[string][]Message),
+}
+
+// memoryBusBuffer returns the capacity of the queues and backlogs
+func memoryBusBuffer() int {
+	return max(config.GetEnvInt("MEMORY_BUS_BUFFER", 1000), 1)
+}
+
+// queue returns the queue of a group on a topic, creating it and handing it the backlog of the
+// topic if it is the first group
+func (b *memoryBus) queue(topic, group string) chan Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	groups, ok := b.queues[topic]
+	if !ok {
+		groups = make(map[string]chan Message)
+		b.queues[topic] = groups
+	}
+	q, ok := groups[group]
+	if !ok {
+		backlog := b.backlog[topic]
+		q = make(chan Message, max(memoryBusBuffer(), len(backlog)))
+		for _, msg := range backlog {
+			q <- msg
+		}
+		delete(b.backlog, topic)
+		groups[group] = q
+	}
+	return q
+}
+
+// subscribers returns the queues of the groups consuming a topic, or keeps msgs in the backlog
+// of the topic when it has none
+func (b *memoryBus) subscribers(topic string, msgs []Message) []chan Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.queues[topic]) == 0 {
+		backlog := append(b.backlog[topic], msgs...)
+		if limit := memoryBusBuffer(); len(backlog) > limit {
+			backlog = backlog[len(backlog)-limit:]
+		}
+		b.backlog[topic] = backlog
+		return nil
+	}
+	queues := make([]chan Message, 0, len(b.queues[topic]))
+	for _, q := range b.queues[topic] {
+		queues = append(queues, q)
+	}
+	return queues
+}
+
+// MemoryPublisher publishes events to the in-process bus
+type MemoryPublisher struct {
+	bus *memoryBus
+}
+
+// NewMemoryPublisher creates a publisher on the process-wide bus
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{bus: defaultMemoryBus}
+}
+
+// Publish hands every value to each group consuming topic, waiting for room in their queues, or
+// to the backlog of the topic when it has no group yet
+func (p *MemoryPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	msgs := make([]Message, len(values))
+	for i, value := range values {
+		msgs[i] = Message{Topic: topic, Value: value}
+	}
+	queues := p.bus.subscribers(topic, msgs)
+	for _, msg := range msgs {
+		for _, q := range queues {
+			select {
+			case q <- msg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Close is a no-op; the bus lives as long as the process
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
+
+// MemoryConsumer reads topics of the in-process bus in a consumer group
+type MemoryConsumer struct {
+	group string
+	bus   *memoryBus
+}
+
+// NewMemoryConsumer creates a consumer in the given group on the process-wide bus
+func NewMemoryConsumer(group string) *MemoryConsumer {
+	return &MemoryConsumer{group: group, bus: defaultMemoryBus}
+}
+
+// Consume handles messages of topic until ctx is cancelled.
+// A failing message is retried up to maxDeliveries times, then dropped.
+func (c *MemoryConsumer) Consume(ctx context.Context, topic string, handler Handler) error {
+	q := c.bus.queue(topic, c.group)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-q:
+			observeLag(c.group, topic, 0, int64(len(q)))
+			for delivery := 1; ; delivery++ {
+				err := handler(ctx, msg)
+				observeHandled(c.group, topic, 0, err)
+				if err == nil {
+					break
+				}
+				log.Printf("Error handling memory bus message on %s: %v", topic, err)
+				if delivery >= maxDeliveries || ctx.Err() != nil {
+					observeDropped(c.group, topic, 0)
+					break
+				}
+			}
+		}
+	}
+}
+
+// Close is a no-op; queued messages stay for the other consumers of the group
+func (c *MemoryConsumer) Close() error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"internship-project/internal/config"
+)
+
+// newJetStream connects to NATS_URL and opens a JetStream context
+func newJetStream() (*nats.Conn, jetstream.JetStream, error) {
+	nc, err := nats.Connect(config.GetEnv("NATS_URL", nats.DefaultURL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+	return nc, js, nil
+}
+
+// ensureStream creates the stream backing a topic; each topic is a stream with one subject of the same name
+func ensureStream(ctx context.Context, js jetstream.JetStream, topic string) (jetstream.Stream, error) {
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     topic,
+		Subjects: []string{topic},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream stream %s: %w", topic, err)
+	}
+	return stream, nil
+}
+
+// NatsPublisher publishes events to NATS JetStream streams
+type NatsPublisher struct {
+	nc *nats.Conn
+	js jetstream.JetStream
+
+	mu      sync.Mutex
+	streams map[string]bool
+}
+
+// NewNatsPublisher connects a publisher to the server in NATS_URL
+func NewNatsPublisher() (*NatsPublisher, error) {
+	nc, js, err := newJetStream()
+	if err != nil {
+		return nil, err
+	}
+	return &NatsPublisher{nc: nc, js: js, streams: make(map[string]bool)}, nil
+}
+
+// Publish writes every value to topic, waiting for the server acknowledgement of each
+func (p *NatsPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	p.mu.Lock()
+	if !p.streams[topic] {
+		if _, err := ensureStream(ctx, p.js, topic); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+		p.streams[topic] = true
+	}
+	p.mu.Unlock()
+
+	for _, value := range values {
+		if _, err := p.js.Publish(ctx, topic, value); err != nil {
+			return fmt.Errorf("failed to publish to jetstream subject %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Close drains the connection
+func (p *NatsPublisher) Close() error {
+	return p.nc.Drain()
+}
+
+// NatsConsumer reads NATS JetStream streams through a durable consumer named after its group
+type NatsConsumer struct {
+	group string
+	nc    *nats.Conn
+	js    jetstream.JetStream
+}
+
+// NewNatsConsumer connects a consumer in the given group to the server in NATS_URL
+func NewNatsConsumer(group string) (*NatsConsumer, error) {
+	nc, js, err := newJetStream()
+	if err != nil {
+		return nil, err
+	}
+	return &NatsConsumer{group: group, nc: nc, js: js}, nil
+}
+
+// Consume handles messages of topic until ctx is cancelled.
+// Failed messages are negatively acknowledged and redelivered up to maxDeliveries times.
+func (c *NatsConsumer) Consume(ctx context.Context, topic string, handler Handler) error {
+	stream, err := ensureStream(ctx, c.js, topic)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:    c.group,
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		MaxDeliver: maxDeliveries,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create jetstream consumer %s: %w", c.group, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(m jetstream.Msg) {
+		meta, metaErr := m.Metadata()
+		if metaErr == nil {
+			observeLag(c.group, topic, 0, int64(meta.NumPending))
+		}
+		err := handler(ctx, Message{Topic: m.Subject(), Value: m.Data()})
+		observeHandled(c.group, topic, 0, err)
+		if err != nil {
+			log.Printf("Error handling jetstream message on %s: %v", m.Subject(), err)
+			if metaErr == nil && meta.NumDelivered >= maxDeliveries {
+				observeDropped(c.group, topic, 0)
+			}
+			m.Nak()
+			return
+		}
+		m.Ack()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume jetstream stream %s: %w", topic, err)
+	}
+
+	<-ctx.Done()
+	consumeCtx.Stop()
+	return nil
+}
+
+// Close drains the connection
+func (c *NatsConsumer) Close() error {
+	return c.nc.Drain()
+}
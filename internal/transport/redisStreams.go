@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"internship-project/internal/config"
+	redisclient "internship-project/internal/redis"
+)
+
+// streamValueField is the stream entry field holding the message value
+const streamValueField = "value"
+
+// RedisStreamsPublisher publishes events with XADD, one stream per topic
+type RedisStreamsPublisher struct {
+	rdb    *redis.Client
+	maxLen int64
+}
+
+// NewRedisStreamsPublisher creates a publisher trimming each stream to about REDIS_STREAM_MAXLEN entries
+func NewRedisStreamsPublisher() *RedisStreamsPublisher {
+	return &RedisStreamsPublisher{
+		rdb:    redisclient.NewClient(),
+		maxLen: int64(config.GetEnvInt("REDIS_STREAM_MAXLEN", 100000)),
+	}
+}
+
+// Publish appends every value to the topic stream in one round trip
+func (p *RedisStreamsPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	pipe := p.rdb.Pipeline()
+	for _, value := range values {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: topic,
+			MaxLen: p.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{streamValueField: value},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish to redis stream %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close closes the Redis client
+func (p *RedisStreamsPublisher) Close() error {
+	return p.rdb.Close()
+}
+
+// RedisStreamsConsumer reads streams with XREADGROUP as a member of a consumer group.
+// Entries left pending by a crashed member are claimed once idle for REDIS_STREAM_CLAIM_IDLE.
+type RedisStreamsConsumer struct {
+	rdb       *redis.Client
+	group     string
+	name      string
+	claimIdle time.Duration
+}
+
+// NewRedisStreamsConsumer creates a consumer in the given group, named after the host and process
+func NewRedisStreamsConsumer(group string) *RedisStreamsConsumer {
+	host, _ := os.Hostname()
+	return &RedisStreamsConsumer{
+		rdb:       redisclient.NewClient(),
+		group:     group,
+		name:      fmt.Sprintf("%s-%d", host, os.Getpid()),
+		claimIdle: config.GetEnvDuration("REDIS_STREAM_CLAIM_IDLE", 30*time.Second),
+	}
+}
+
+// Consume handles entries of the topic stream until ctx is cancelled.
+// Failed entries stay pending and are redelivered through the claim loop, up to maxDeliveries times.
+func (c *RedisStreamsConsumer) Consume(ctx context.Context, topic string, handler Handler) error {
+	err := c.rdb.XGroupCreateMkStream(ctx, topic, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create redis consumer group %s: %w", c.group, err)
+	}
+
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= c.claimIdle {
+			if err := c.claimPending(ctx, topic, handler); err != nil && ctx.Err() == nil {
+				log.Printf("Error claiming pending entries of %s: %v", topic, err)
+			}
+			lastClaim = time.Now()
+		}
+
+		streams, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.name,
+			Streams:  []string{topic, ">"},
+			Count:    100,
+			Block:    time.Second,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			return fmt.Errorf("failed to read redis stream %s: %w", topic, err)
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				c.handle(ctx, topic, entry, handler)
+			}
+		}
+	}
+	return nil
+}
+
+// claimPending takes over entries idle for claimIdle, whether another member crashed
+// or the handler failed, and drops those already delivered maxDeliveries times
+func (c *RedisStreamsConsumer) claimPending(ctx context.Context, topic string, handler Handler) error {
+	start := "0-0"
+	for {
+		entries, next, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   topic,
+			Group:    c.group,
+			Consumer: c.name,
+			MinIdle:  c.claimIdle,
+			Start:    start,
+			Count:    100,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			pending, err := c.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: topic, Group: c.group, Start: entry.ID, End: entry.ID, Count: 1,
+			}).Result()
+			if err == nil && len(pending) == 1 && pending[0].RetryCount > maxDeliveries {
+				log.Printf("Dropping redis stream entry %s/%s after %d deliveries", topic, entry.ID, maxDeliveries)
+				observeDropped(c.group, topic, 0)
+				c.rdb.XAck(ctx, topic, c.group, entry.ID)
+				continue
+			}
+			c.handle(ctx, topic, entry, handler)
+		}
+
+		if next == "0-0" || len(entries) == 0 {
+			return nil
+		}
+		start = next
+	}
+}
+
+// handle runs the handler on one entry and acknowledges it on success
+func (c *RedisStreamsConsumer) handle(ctx context.Context, topic string, entry redis.XMessage, handler Handler) {
+	value, _ := entry.Values[streamValueField].(string)
+	err := handler(ctx, Message{Topic: topic, Value: []byte(value)})
+	observeHandled(c.group, topic, 0, err)
+	if err != nil {
+		log.Printf("Error handling redis stream entry %s/%s: %v", topic, entry.ID, err)
+		return
+	}
+	if err := c.rdb.XAck(ctx, topic, c.group, entry.ID).Err(); err != nil {
+		log.Printf("Error acknowledging redis stream entry %s/%s: %v", topic, entry.ID, err)
+	}
+}
+
+// Close closes the Redis client
+func (c *RedisStreamsConsumer) Close() error {
+	return c.rdb.Close()
+}
@@ -0,0 +1,311 @@
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+// recordHeaderSize is the size of a spool record header: CRC-32 of the topic and value, topic
+// length (uint16) and value length (uint32), big-endian
+const recordHeaderSize = 10
+
+var (
+	// spooledMessages counts the messages written to the disk spool because publishing failed
+	spooledMessages = expvar.NewInt("publish_spool_spooled")
+
+	// drainedMessages counts the spooled messages published once the broker recovered
+	drainedMessages = expvar.NewInt("publish_spool_drained")
+)
+
+// ErrSpoolFull is returned when a message cannot be published and the spool has no room left
+var ErrSpoolFull = errors.New("publish spool is full")
+
+// SpoolingPublisher buffers the messages its publisher fails to send in an append-only file and
+// publishes them again, in order, once the broker recovers. While the spool holds messages new
+// ones are appended behind them. A message whose batch partly went through before the failure may
+// be delivered twice.
+type SpoolingPublisher struct {
+	next  Publisher
+	spool *diskSpool
+
+	drainInterval time.Duration
+	drainBatch    int
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewSpoolingPublisher wraps next with a spool in dir, configured by PUBLISH_SPOOL_MAX_BYTES,
+// PUBLISH_SPOOL_DRAIN_INTERVAL and PUBLISH_SPOOL_DRAIN_BATCH. Every publisher of the process
+// takes its own spool file in dir, and picks up the messages left there by a previous run.
+func NewSpoolingPublisher(next Publisher, dir string) (*SpoolingPublisher, error) {
+	spool, err := openDiskSpool(dir, int64(config.GetEnvInt("PUBLISH_SPOOL_MAX_BYTES", 256<<20)))
+	if err != nil {
+		return nil, err
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	p := &SpoolingPublisher{
+		next:          next,
+		spool:         spool,
+		drainInterval: config.GetEnvDuration("PUBLISH_SPOOL_DRAIN_INTERVAL", 10*time.Second),
+		drainBatch:    config.GetEnvInt("PUBLISH_SPOOL_DRAIN_BATCH", 500),
+		stop:          stop,
+		done:          make(chan struct{}),
+	}
+	if pending := spool.pendingBytes(); pending > 0 {
+		log.Printf("Publish spool %s holds %d bytes from a previous run", spool.path, pending)
+	}
+	go p.drainLoop(ctx)
+	return p, nil
+}
+
+// Publish sends the values through the wrapped publisher, or spools them when it fails or older
+// messages are still spooled. It only fails when the values could not be spooled either.
+func (p *SpoolingPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	if p.spool.pendingBytes() == 0 {
+		err := p.next.Publish(ctx, topic, values...)
+		if err == nil {
+			return nil
+		}
+		if spoolErr := p.spool.append(topic, values); spoolErr != nil {
+			return errors.Join(err, spoolErr)
+		}
+		log.Printf("Spooled %d messages for %s after a publish error: %v", len(values), topic, err)
+		spooledMessages.Add(int64(len(values)))
+		return nil
+	}
+
+	if err := p.spool.append(topic, values); err != nil {
+		return err
+	}
+	spooledMessages.Add(int64(len(values)))
+	return nil
+}
+
+// Close stops draining, leaving spooled messages for the next run, and closes the wrapped publisher
+func (p *SpoolingPublisher) Close() error {
+	p.stop()
+	<-p.done
+	return errors.Join(p.spool.close(), p.next.Close())
+}
+
+// drainLoop publishes the spooled messages every PUBLISH_SPOOL_DRAIN_INTERVAL until ctx is done
+func (p *SpoolingPublisher) drainLoop(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.drainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Drain(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Publish spool still failing, %d bytes pending: %v", p.spool.pendingBytes(), err)
+			}
+		}
+	}
+}
+
+// Drain publishes the spooled messages in order, in batches of consecutive messages of a topic,
+// until the spool is empty or publishing fails
+func (p *SpoolingPublisher) Drain(ctx context.Context) error {
+	for {
+		topic, values, end, err := p.spool.readBatch(p.drainBatch)
+		if err != nil || len(values) == 0 {
+			return err
+		}
+		if err := p.next.Publish(ctx, topic, values...); err != nil {
+			return err
+		}
+		if err := p.spool.advance(end); err != nil {
+			return err
+		}
+		drainedMessages.Add(int64(len(values)))
+	}
+}
+
+// diskSpool is an append-only file of records with a persisted read offset. The file is
+// truncated once every record was read.
+type diskSpool struct {
+	path       string
+	offsetPath string
+	maxBytes   int64
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64 // end of the last complete record
+	offset int64 // start of the first unread record
+}
+
+// openDiskSpool takes the first spool file of dir no other publisher holds, dropping a record
+// torn by a crash at its end
+func openDiskSpool(dir string, maxBytes int64) (*diskSpool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create publish spool directory: %w", err)
+	}
+	for i := 0; ; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("spool-%d.log", i))
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open publish spool: %w", err)
+		}
+		locked, err := lockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock publish spool: %w", err)
+		}
+		if !locked {
+			file.Close()
+			continue
+		}
+
+		s := &diskSpool{path: path, offsetPath: path + ".offset", maxBytes: maxBytes, file: file}
+		if err := s.recover(); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// recover loads the read offset and finds the end of the last complete record
+func (s *diskSpool) recover() error {
+	if data, err := os.ReadFile(s.offsetPath); err == nil {
+		s.offset, _ = strconv.ParseInt(string(data), 10, 64)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read publish spool offset: %w", err)
+	}
+
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if s.offset < 0 || s.offset > info.Size() {
+		s.offset = 0
+	}
+	s.size = s.offset
+	for s.size < info.Size() {
+		_, _, n, err := s.readRecord(s.size)
+		if err != nil {
+			log.Printf("Dropping %d bytes torn off the end of publish spool %s", info.Size()-s.size, s.path)
+			return s.file.Truncate(s.size)
+		}
+		s.size += n
+	}
+	return nil
+}
+
+func (s *diskSpool) pendingBytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size - s.offset
+}
+
+// append writes the values as records of topic and syncs them to disk
+func (s *diskSpool) append(topic string, values [][]byte) error {
+	var buf []byte
+	for _, value := range values {
+		header := make([]byte, recordHeaderSize)
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(topic))
+		crc.Write(value)
+		binary.BigEndian.PutUint32(header[0:4], crc.Sum32())
+		binary.BigEndian.PutUint16(header[4:6], uint16(len(topic)))
+		binary.BigEndian.PutUint32(header[6:10], uint32(len(value)))
+		buf = append(append(append(buf, header...), topic...), value...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.offset+int64(len(buf)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		return fmt.Errorf("failed to write publish spool: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync publish spool: %w", err)
+	}
+	s.size += int64(len(buf))
+	return nil
+}
+
+// readBatch returns up to limit consecutive unread records of the same topic and the offset
+// after them
+func (s *diskSpool) readBatch(limit int) (topic string, values [][]byte, end int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	end = s.offset
+	for end < s.size && len(values) < max(limit, 1) {
+		recordTopic, value, n, err := s.readRecord(end)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		if len(values) > 0 && recordTopic != topic {
+			break
+		}
+		topic = recordTopic
+		values = append(values, value)
+		end += n
+	}
+	return topic, values, end, nil
+}
+
+// readRecord reads the record at offset and returns its size
+func (s *diskSpool) readRecord(offset int64) (topic string, value []byte, n int64, err error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := s.file.ReadAt(header, offset); err != nil {
+		return "", nil, 0, err
+	}
+	topicLen := int(binary.BigEndian.Uint16(header[4:6]))
+	valueLen := int(binary.BigEndian.Uint32(header[6:10]))
+	body := make([]byte, topicLen+valueLen)
+	if _, err := s.file.ReadAt(body, offset+recordHeaderSize); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", nil, 0, err
+	}
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(header[0:4]) {
+		return "", nil, 0, fmt.Errorf("corrupt publish spool record at offset %d", offset)
+	}
+	return string(body[:topicLen]), body[topicLen:], int64(recordHeaderSize + len(body)), nil
+}
+
+// advance marks the records before end as published, truncating the file once all are
+func (s *diskSpool) advance(end int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if end >= s.size {
+		if err := s.file.Truncate(0); err != nil {
+			return err
+		}
+		s.size, end = 0, 0
+	}
+	s.offset = end
+	tmp := s.offsetPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(end, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.offsetPath)
+}
+
+// close releases the spool file; its lock goes with it
+func (s *diskSpool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
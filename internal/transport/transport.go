@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	"internship-project/internal/config"
+)
+
+// maxDeliveries is how many times a message is handed to a failing handler before it is dropped
+const maxDeliveries = 3
+
+// Message is one event read from a topic
+type Message struct {
+	Topic string
+	Value []byte
+}
+
+// Handler processes a consumed message.
+// Returning an error redelivers the message, up to maxDeliveries times.
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher sends events to a topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, values ...[]byte) error
+	Close() error
+}
+
+// Consumer delivers the events of a topic to a handler.
+// Consumers sharing a group split the topic between them.
+type Consumer interface {
+	// Consume blocks, handling messages until ctx is cancelled
+	Consume(ctx context.Context, topic string, handler Handler) error
+	Close() error
+}
+
+// Transport returns the configured event transport: "kafka" (default), "nats", "redis" or "memory"
+func Transport() string {
+	return config.GetEnv("EVENT_TRANSPORT", "kafka")
+}
+
+// NewPublisher creates a publisher for the configured transport. With PUBLISH_SPOOL_ENABLED the
+// messages it fails to send are buffered on disk in PUBLISH_SPOOL_DIR until the broker recovers.
+// When the events sink is disabled for the deployment, it returns a publisher dropping every message.
+func NewPublisher() (Publisher, error) {
+	if !config.SinkEnabled(config.SinkEvents) {
+		return discardPublisher{}, nil
+	}
+	publisher, err := newTransportPublisher()
+	if err != nil || !config.GetEnvBool("PUBLISH_SPOOL_ENABLED", false) {
+		return publisher, err
+	}
+	spooling, err := NewSpoolingPublisher(publisher, config.GetEnv("PUBLISH_SPOOL_DIR", "data/spool"))
+	if err != nil {
+		publisher.Close()
+		return nil, err
+	}
+	return spooling, nil
+}
+
+// newTransportPublisher creates a publisher for the configured transport
+func newTransportPublisher() (Publisher, error) {
+	switch Transport() {
+	case "kafka":
+		return NewKafkaPublisher(), nil
+	case "nats":
+		return NewNatsPublisher()
+	case "redis":
+		return NewRedisStreamsPublisher(), nil
+	case "memory":
+		return NewMemoryPublisher(), nil
+	default:
+		return nil, fmt.Errorf("unknown event transport: %q", Transport())
+	}
+}
+
+// NewConsumer creates a consumer in the given group for the configured transport
+func NewConsumer(group string) (Consumer, error) {
+	if !config.SinkEnabled(config.SinkEvents) {
+		return nil, fmt.Errorf("the %s sink is disabled by SINKS", config.SinkEvents)
+	}
+	switch Transport() {
+	case "kafka":
+		return NewKafkaConsumer(group), nil
+	case "nats":
+		return NewNatsConsumer(group)
+	case "redis":
+		return NewRedisStreamsConsumer(group), nil
+	case "memory":
+		return NewMemoryConsumer(group), nil
+	default:
+		return nil, fmt.Errorf("unknown event transport: %q", Transport())
+	}
+}
+
+// discardPublisher drops the messages of a deployment without the events sink
+type discardPublisher struct{}
+
+func (discardPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	return nil
+}
+
+func (discardPublisher) Close() error { return nil }
+
+// ItemTopics maps an item kind to the topic its saved IDs are published on for indexing
+var ItemTopics = map[string]string{
+	"story":   "StoriesTopic",
+	"ask":     "AsksTopic",
+	"job":     "JobsTopic",
+	"comment": "CommentsTopic",
+	"poll":    "PollsTopic",
+	"pollopt": "PollOptionsTopic",
+}
+
+// TombstoneTopic carries a JSON event per item removed from the store, deleted or dead upstream
+// or expired, for the consumers to drop it from their indexes and caches
+const TombstoneTopic = "TombstonesTopic"
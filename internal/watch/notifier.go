@@ -0,0 +1,169 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/tracing"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of a webhook body keyed by WATCH_WEBHOOK_SECRET
+const SignatureHeader = "X-Watch-Signature"
+
+// IdempotencyKeyHeader carries the key of a notification, the same on every delivery of it
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Notifier evaluates the watches of changed items and calls their webhooks, recording every
+// call in the delivery log
+type Notifier struct {
+	store      repository.WatchRepository
+	deliveries repository.WebhookDeliveryRepository
+	httpClient *http.Client
+	secret     string
+}
+
+// NewNotifier creates a notifier whose webhook calls time out after WATCH_WEBHOOK_TIMEOUT
+// and are signed with WATCH_WEBHOOK_SECRET when it is set
+func NewNotifier(store repository.WatchRepository, deliveries repository.WebhookDeliveryRepository) *Notifier {
+	return &Notifier{
+		store:      store,
+		deliveries: deliveries,
+		httpClient: &http.Client{
+			Timeout: config.GetEnvDuration("WATCH_WEBHOOK_TIMEOUT", 5*time.Second),
+		},
+		secret: config.GetEnv("WATCH_WEBHOOK_SECRET", ""),
+	}
+}
+
+// ItemChanged evaluates the watches of an item whose score or comment count may have changed.
+// A failing webhook does not keep the other watches from being notified.
+func (n *Notifier) ItemChanged(ctx context.Context, id, score, comments int) error {
+	watches, err := n.store.GetByItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, w := range watches {
+		current := w.Last
+		current.Score, current.Comments = score, comments
+		errs = append(errs, n.evaluate(ctx, w, current))
+	}
+	return errors.Join(errs...)
+}
+
+// RanksChanged evaluates the rank watches against the top stories, given as item ID -> rank;
+// watched items missing from them have rank 0
+func (n *Notifier) RanksChanged(ctx context.Context, ranks map[int]int) error {
+	watches, err := n.store.GetRankWatches(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, w := range watches {
+		current := w.Last
+		current.Rank = ranks[w.Item_ID]
+		errs = append(errs, n.evaluate(ctx, w, current))
+	}
+	return errors.Join(errs...)
+}
+
+// evaluate calls the webhook of a watch whose values moved and records them as notified. A failed
+// call leaves the last notified values so the change is reported again, as a new notification
+// with its own idempotency key, on the next evaluation.
+func (n *Notifier) evaluate(ctx context.Context, w *models.Watch, current models.WatchState) error {
+	changes := w.Changes(current)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	event := models.WatchEvent{
+		Idempotency_Key: tracing.NewID(),
+		Watch_ID:        w.ID,
+		Kind:            w.Kind,
+		Item_ID:         w.Item_ID,
+		Changes:         changes,
+		Time:            now,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	delivery := &models.WebhookDelivery{
+		Idempotency_Key: event.Idempotency_Key,
+		Watch_ID:        w.ID,
+		URL:             w.Webhook_URL,
+		Payload:         body,
+	}
+	if err := n.deliveries.Create(ctx, delivery); err != nil {
+		return fmt.Errorf("watch %s: failed to record delivery: %w", w.ID, err)
+	}
+	if err := n.deliver(ctx, delivery); err != nil {
+		return fmt.Errorf("watch %s: %w", w.ID, err)
+	}
+	return n.store.UpdateState(ctx, w.ID, current, now)
+}
+
+// Redeliver calls the webhook of a logged delivery again with the same body and idempotency key,
+// and returns the delivery with the outcome. The watch keeps its last notified values, so a
+// change whose delivery failed is still reported again on the next evaluation.
+func (n *Notifier) Redeliver(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	delivery, err := n.deliveries.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deliverErr := n.deliver(ctx, delivery)
+	if delivery, err = n.deliveries.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return delivery, deliverErr
+}
+
+// deliver posts the payload of a delivery to its webhook and records the attempt
+func (n *Notifier) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	code, err := n.send(ctx, delivery.URL, delivery.Idempotency_Key, delivery.Payload)
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	if recordErr := n.deliveries.RecordAttempt(ctx, delivery.ID, code, lastError); recordErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to record delivery attempt: %w", recordErr))
+	}
+	return err
+}
+
+// send posts the body to the webhook and returns the response status code, 0 without a response
+func (n *Notifier) send(ctx context.Context, url, idempotencyKey string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,135 @@
+package watchdog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"expvar"
+	"log"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"internship-project/internal/config"
+)
+
+var (
+	// goroutineCount is the goroutine count at the last sample, published on /debug/vars
+	goroutineCount = expvar.NewInt("goroutines")
+
+	// leakAlerts counts the times the goroutine count grew across a whole window
+	leakAlerts = expvar.NewInt("goroutine_leak_alerts")
+)
+
+// CreationSite is a function that started goroutines still alive, and how many
+type CreationSite struct {
+	Function string
+	Location string
+	Count    int
+}
+
+// GoroutineWatchdog samples the goroutine count and alerts when it grows on every
+// sample of a window, which is how per-item goroutines that never exit show up
+type GoroutineWatchdog struct {
+	interval  time.Duration
+	window    int
+	minGrowth int
+	samples   []int
+}
+
+// NewGoroutineWatchdog creates a watchdog configured by GOROUTINE_WATCHDOG_INTERVAL,
+// GOROUTINE_WATCHDOG_WINDOW (samples) and GOROUTINE_WATCHDOG_MIN_GROWTH (goroutines)
+func NewGoroutineWatchdog() *GoroutineWatchdog {
+	return &GoroutineWatchdog{
+		interval:  config.GetEnvDuration("GOROUTINE_WATCHDOG_INTERVAL", time.Minute),
+		window:    config.GetEnvInt("GOROUTINE_WATCHDOG_WINDOW", 10),
+		minGrowth: config.GetEnvInt("GOROUTINE_WATCHDOG_MIN_GROWTH", 100),
+	}
+}
+
+// Run samples until ctx is done
+func (w *GoroutineWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Observe(runtime.NumGoroutine())
+		}
+	}
+}
+
+// Observe records a sample and reports whether it completed a window of monotonic growth.
+// The window restarts after an alert so a steady leak is reported once per window.
+func (w *GoroutineWatchdog) Observe(count int) bool {
+	goroutineCount.Set(int64(count))
+
+	if n := len(w.samples); n > 0 && count <= w.samples[n-1] {
+		w.samples = w.samples[:0]
+	}
+	w.samples = append(w.samples, count)
+	if len(w.samples) < w.window {
+		return false
+	}
+
+	growth := count - w.samples[0]
+	w.samples = w.samples[:0]
+	if growth < w.minGrowth {
+		return false
+	}
+
+	leakAlerts.Add(1)
+	log.Printf("Goroutine watchdog: count grew on %d consecutive samples to %d (+%d), likely a leak. Top creation sites:",
+		w.window, count, growth)
+	for _, site := range TopCreationSites(5) {
+		log.Printf("  %6d  %s (%s)", site.Count, site.Function, site.Location)
+	}
+	return true
+}
+
+// TopCreationSites groups the live goroutines by the statement that started them, largest first
+func TopCreationSites(limit int) []CreationSite {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		log.Printf("Goroutine watchdog: failed to read goroutine stacks: %v", err)
+		return nil
+	}
+
+	counts := make(map[CreationSite]int)
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "created by ") {
+			continue
+		}
+		function := strings.TrimPrefix(line, "created by ")
+		if i := strings.Index(function, " in goroutine "); i >= 0 {
+			function = function[:i]
+		}
+		site := CreationSite{Function: function}
+		if scanner.Scan() {
+			location := strings.TrimSpace(scanner.Text())
+			if i := strings.LastIndex(location, " +0x"); i >= 0 {
+				location = location[:i]
+			}
+			site.Location = location
+		}
+		counts[site]++
+	}
+
+	sites := make([]CreationSite, 0, len(counts))
+	for site, count := range counts {
+		site.Count = count
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Count > sites[j].Count })
+	if len(sites) > limit {
+		sites = sites[:limit]
+	}
+	return sites
+}
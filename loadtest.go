@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"internship-project/internal/loadtest"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/transport"
+	"internship-project/pkg/database"
+)
+
+// runLoadTest implements the "loadtest" command: it writes synthetic stories through the story
+// repository (and optionally the event bus) at a fixed rate and reports the throughput, the
+// latencies and the queue depths. It returns the exit status: 0 when every item was written,
+// 1 on errors and 2 when some batches failed.
+func runLoadTest(args []string) int {
+	flags := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	rate := flags.Int("rate", 100, "synthetic items generated per second")
+	duration := flags.Duration("duration", 30*time.Second, "how long items are generated")
+	batch := flags.Int("batch", 50, "items per upsert batch")
+	workers := flags.Int("workers", 4, "batches written concurrently")
+	queue := flags.Int("queue", 16, "batches buffered before the generator stalls")
+	publish := flags.Bool("publish", false, "publish the saved IDs on the event bus (they get indexed)")
+	cleanup := flags.Bool("cleanup", true, "delete the synthetic stories when done")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if err := database.Connect(database.GetDefaultConfig()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to database:", err)
+		return 1
+	}
+	defer database.Close()
+
+	stories := postgres.NewStoryRepository()
+	pipeline := loadtest.Pipeline{
+		Write: func(ctx context.Context, batch []*models.Story) error {
+			_, err := stories.UpsertBatch(ctx, batch)
+			return err
+		},
+	}
+	if *publish {
+		publisher, err := transport.NewPublisher()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to create event publisher:", err)
+			return 1
+		}
+		defer publisher.Close()
+		pipeline.Publish = func(ctx context.Context, batch []*models.Story) error {
+			values := make([][]byte, len(batch))
+			for i, story := range batch {
+				values[i] = strconv.AppendInt(nil, int64(story.ID), 10)
+			}
+			return publisher.Publish(ctx, transport.ItemTopics["story"], values...)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, loadtest.Options{
+		Rate:      *rate,
+		Duration:  *duration,
+		BatchSize: *batch,
+		Workers:   *workers,
+		QueueSize: *queue,
+	}, pipeline)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *cleanup {
+		// The run context may already be cancelled; cleaning up must still happen
+		if err := stories.DeleteByAuthor(context.Background(), loadtest.Author); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to delete synthetic stories:", err)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		fmt.Println(report)
+	}
+	if report.Failed > 0 {
+		return 2
+	}
+	return 0
+}
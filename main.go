@@ -1,18 +1,55 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"internship-project/internal/api"
+	"internship-project/internal/changefeed"
+	"internship-project/internal/config"
 	"internship-project/internal/cronjob"
+	"internship-project/internal/etl"
+	"internship-project/internal/firehose"
+	"internship-project/internal/indexing"
+	"internship-project/internal/opensearch"
+	"internship-project/internal/repository/postgres"
 	"internship-project/internal/services"
+	"internship-project/internal/tasks"
+	"internship-project/internal/transport"
+	"internship-project/internal/watch"
+	"internship-project/internal/watchdog"
+	"internship-project/pkg/database"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "loadtest" {
+		os.Exit(runLoadTest(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		os.Exit(runSnapshot(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		os.Exit(runStatus(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:]))
+	}
+
 	log.Println("Starting HackerNews Data Sync...")
 
+	// Only the sinks enabled for the deployment's region are initialized and health-checked
+	if err := config.ValidateSinks(); err != nil {
+		log.Fatal("Invalid data residency settings:", err)
+	}
+	log.Printf("Config profile %q, enabled sinks: %v", config.Profile(), config.EnabledSinks())
+
 	// Create HTTP client
 	client := services.NewHackerNewsApiClient()
 
@@ -42,12 +79,84 @@ func main() {
 		log.Fatal("Failed to create data sync service:", err)
 	}
 
+	// Register the optional non-HackerNews sources
+	if config.GetEnvBool("LOBSTERS_ENABLED", false) {
+		dataSyncService.AddSource(services.NewLobstersSource(), "LOBSTERS_SYNC_INTERVAL", 30*time.Minute)
+	}
+	if feeds := config.GetEnvList("RSS_FEEDS", nil); len(feeds) > 0 {
+		dataSyncService.AddSource(services.NewRSSSource(feeds), "RSS_SYNC_INTERVAL", 15*time.Minute)
+	}
+
 	//  Start all cron jobs
 	if err := dataSyncService.Start(); err != nil {
 		log.Fatal("Failed to start cron jobs:", err)
 	}
 
 	log.Println("All cron jobs started successfully!")
+
+	// Start the HTTP API
+	apiServer := api.NewDefaultServer()
+
+	// Run queued refetches, reindexes and backfills, durably and with retries
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if config.GetEnvBool("TASKS_ENABLED", true) {
+		worker := tasks.NewWorker(postgres.NewTaskRepository())
+		dataSyncService.RegisterTasks(worker)
+		apiServer.RegisterTasks(worker)
+		go worker.Run(watchCtx)
+	}
+
+	if err := apiServer.Start(); err != nil {
+		log.Fatal("Failed to start API server:", err)
+	}
+
+	// Apply .env changes (intervals, batch sizes, feature flags) without a restart
+	if config.GetEnvBool("CONFIG_RELOAD_ENABLED", true) {
+		go config.Watch(watchCtx, config.GetEnvDuration("CONFIG_RELOAD_INTERVAL", 10*time.Second))
+	}
+	if config.GetEnvBool("GOROUTINE_WATCHDOG_ENABLED", true) {
+		go watchdog.NewGoroutineWatchdog().Run(watchCtx)
+	}
+
+	// Evaluate the watches of saved items off the persistence path, from the firehose
+	if config.GetEnvBool("WATCH_ASYNC", false) {
+		notifier := watch.NewNotifier(postgres.NewWatchRepository(), postgres.NewWebhookDeliveryRepository())
+		go etl.NewWatcher(notifier).Run(watchCtx, firehose.Default())
+	}
+
+	// Index item changes as Postgres notifies them instead of waiting for the sync to publish them
+	if config.GetEnvBool("CHANGE_LISTENER_ENABLED", false) {
+		publisher, err := transport.NewPublisher()
+		if err != nil {
+			log.Fatal("Failed to create change listener publisher:", err)
+		}
+		defer publisher.Close()
+		listener := changefeed.NewListener(database.GetDefaultConfig().ConnectionString(), postgres.NewChangeRepository(), publisher)
+		go listener.Run(watchCtx)
+	}
+
+	// Index the items the sync publishes from the consumer groups of INDEXER_CONSUMER_GROUPS
+	consumersDone := make(chan struct{})
+	if config.GetEnvBool("INDEXER_CONSUMERS_ENABLED", false) {
+		indexer := opensearch.NewIndexerService()
+		if indexer == nil {
+			log.Fatal("Indexer consumers need OPENSEARCH_URL and OPENSEARCH_INDEXER_ENABLED")
+		}
+		group, err := indexing.NewIndexingGroup(indexer)
+		if err != nil {
+			log.Fatal("Failed to create indexer consumers:", err)
+		}
+		go func() {
+			defer close(consumersDone)
+			if err := group.Run(watchCtx); err != nil {
+				log.Printf("Indexer consumers stopped: %v", err)
+			}
+		}()
+	} else {
+		close(consumersDone)
+	}
+
 	log.Println("Data sync is now running automatically...")
 	log.Println("Press Ctrl+C to stop")
 
@@ -58,6 +167,14 @@ func main() {
 
 	// Graceful shutdown
 	log.Println("Stopping application...")
+	stopWatching()
+	<-consumersDone
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := apiServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error stopping API server: %v", err)
+	}
+
 	if err := dataSyncService.Stop(); err != nil {
 		log.Printf("Error stopping service: %v", err)
 	} else {
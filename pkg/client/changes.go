@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"internship-project/internal/models"
+)
+
+// Change is an insert, update or removal of an item, numbered in commit order
+type Change = models.SequencedChange
+
+// ChangePage is a page of the change log; NextSince is the since of the next call
+type ChangePage struct {
+	Changes   []*Change `json:"changes"`
+	NextSince int64     `json:"next_since"`
+	HasMore   bool      `json:"has_more"`
+}
+
+// IsExpired reports whether err is a 410 of the change log: the changes after the requested
+// sequence number were pruned and the consumer has to resync
+func IsExpired(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusGone
+}
+
+// Changes returns up to limit changes of the tenant's items after since; a negative since
+// starts at the oldest change kept
+func (c *Client) Changes(ctx context.Context, since int64, limit int) (*ChangePage, error) {
+	q := url.Values{}
+	if since >= 0 {
+		q.Set("since", strconv.FormatInt(since, 10))
+	}
+	setInt(q, "limit", int64(limit))
+	var page ChangePage
+	if err := c.get(ctx, "/api/v1/changes", q, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// StreamChanges calls fn with every change after since, in order, polling every interval once it
+// has caught up. It returns nil when ctx is done, or the first error of fn or of a poll that
+// failed all its retries; IsExpired tells that the consumer fell behind the retention of the log.
+// Consumers save the Seq of the last change they handled and pass it as since when they restart.
+func (c *Client) StreamChanges(ctx context.Context, since int64, interval time.Duration, fn func(*Change) error) error {
+	for {
+		page, err := c.Changes(ctx, since, 0)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, change := range page.Changes {
+			if err := fn(change); err != nil {
+				return err
+			}
+		}
+		since = page.NextSince
+		if page.HasMore {
+			continue
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
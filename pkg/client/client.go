@@ -0,0 +1,199 @@
+// Package client is a Go client for the REST API of the service: typed item, timeline, feed,
+// search and change log calls, iterators over their pages, retries of transient failures and
+// polling streams of new items and changes.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API of one deployment. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	adminKey   string
+	userAgent  string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option customizes a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates as a tenant (X-API-Key); tenant-scoped endpoints such as the feed need it
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithAdminKey authenticates admin calls (X-Admin-Key)
+func WithAdminKey(key string) Option {
+	return func(c *Client) { c.adminKey = key }
+}
+
+// WithHTTPClient sends the requests through httpClient instead of a client with a 30s timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithUserAgent sets the User-Agent of the requests, so the service can tell its callers apart
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithRetries sets how many times a failed request is retried (3 by default, 0 disables retries)
+// and the bounds of the exponential backoff between attempts
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  "hn-data-sync-go-client/1.0",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		minBackoff: 200 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// get decodes the response of a GET request to path with the query into result
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, result)
+}
+
+// post sends body as JSON to path and decodes the response into result. Only read-only POST
+// calls go through it, so they are retried like GETs.
+func (c *Client) post(ctx context.Context, path string, body, result interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, result)
+}
+
+// do sends a request, retrying network errors, 429 and 5xx responses but 501 with backoff, and
+// decodes a 2xx response into result or an error response into an *Error
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+		if c.adminKey != "" {
+			req.Header.Set("X-Admin-Key", c.adminKey)
+		}
+
+		var retryAfter time.Duration
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			if resp.StatusCode < 300 {
+				err = decodeResponse(resp, result)
+				resp.Body.Close()
+				return err
+			}
+			err = decodeError(resp)
+			resp.Body.Close()
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			if !retryable(resp.StatusCode) {
+				return err
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else {
+			err = fmt.Errorf("failed to perform request: %w", err)
+		}
+
+		if attempt >= c.maxRetries {
+			return err
+		}
+		timer := time.NewTimer(max(retryAfter, c.backoff(attempt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt+1: exponential from minBackoff, capped at
+// maxBackoff, with full jitter so clients retrying together spread out
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.minBackoff << min(attempt, 16)
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay))) + 1
+}
+
+// retryable reports whether a request failing with status may succeed when sent again
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests ||
+		(status >= 500 && status != http.StatusNotImplemented)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds; 0 when absent or a date
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeResponse decodes a successful response body into result, which may be nil
+func decodeResponse(resp *http.Response, result interface{}) error {
+	if result == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error codes of the API error envelope
+const (
+	CodeInvalidArgument  = "invalid_argument"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeRateLimited      = "rate_limited"
+	CodeUnavailable      = "unavailable"
+	CodeTimeout          = "timeout"
+	CodeUpstream         = "upstream_error"
+	CodeInternal         = "internal"
+)
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int             `json:"-"`
+	Code       string          `json:"code"`
+	Message    string          `json:"message"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("api error %d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 of the API
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// decodeError reads the error envelope of a failed response. Responses from proxies in front of
+// the service carry none; the status text stands in for the message.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(body, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &Error{Message: http.StatusText(resp.StatusCode)}
+	}
+	apiErr.StatusCode = resp.StatusCode
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return apiErr
+}
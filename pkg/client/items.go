@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"internship-project/internal/models"
+)
+
+// Item models, as the API encodes them
+type (
+	Story        = models.Story
+	Ask          = models.Ask
+	Job          = models.Job
+	Comment      = models.Comment
+	Poll         = models.Poll
+	PollOption   = models.PollOption
+	TimelineItem = models.TimelineItem
+)
+
+// MaxBatchGetItems is the default limit of items per BatchGet call (BATCH_GET_MAX_ITEMS)
+const MaxBatchGetItems = 1000
+
+// ListOptions filters and pages the item list endpoints. Zero values leave a filter out.
+type ListOptions struct {
+	Author           string
+	MinScore         *int
+	MaxScore         *int
+	MinPercentile    *int  // stories only: score percentile among the stories of the past days, 0-100
+	Start            int64 // created_at lower bound (unix seconds, inclusive)
+	End              int64 // created_at upper bound (unix seconds, inclusive)
+	Type             string
+	Domain           string
+	Query            string
+	Source           string
+	IncludeSpam      bool
+	ExcludeDeadLinks bool
+	Limit            int // items per page; the API defaults to 30 and caps it at 500
+	Offset           int
+}
+
+// values encodes the options as query parameters
+func (o ListOptions) values() url.Values {
+	q := url.Values{}
+	setString(q, "author", o.Author)
+	if o.MinScore != nil {
+		q.Set("min_score", strconv.Itoa(*o.MinScore))
+	}
+	if o.MaxScore != nil {
+		q.Set("max_score", strconv.Itoa(*o.MaxScore))
+	}
+	if o.MinPercentile != nil {
+		q.Set("min_percentile", strconv.Itoa(*o.MinPercentile))
+	}
+	setInt(q, "start", o.Start)
+	setInt(q, "end", o.End)
+	setString(q, "type", o.Type)
+	setString(q, "domain", o.Domain)
+	setString(q, "q", o.Query)
+	setString(q, "source", o.Source)
+	if o.IncludeSpam {
+		q.Set("include_spam", "true")
+	}
+	if o.ExcludeDeadLinks {
+		q.Set("exclude_dead_links", "true")
+	}
+	setInt(q, "limit", int64(o.Limit))
+	setInt(q, "offset", int64(o.Offset))
+	return q
+}
+
+// Page is one page of a list endpoint
+type Page[T any] struct {
+	Items   []*T `json:"items"`
+	Total   int  `json:"total"`
+	Page    int  `json:"page"`
+	Limit   int  `json:"limit"`
+	HasMore bool `json:"has_more"`
+}
+
+// ListStories returns one page of the stories matching the options, newest first
+func (c *Client) ListStories(ctx context.Context, opts ListOptions) (*Page[Story], error) {
+	return listPage[Story](ctx, c, "/api/v1/stories", opts)
+}
+
+// ListAsks returns one page of the Ask HN posts matching the options, newest first
+func (c *Client) ListAsks(ctx context.Context, opts ListOptions) (*Page[Ask], error) {
+	return listPage[Ask](ctx, c, "/api/v1/asks", opts)
+}
+
+// ListJobs returns one page of the jobs matching the options, newest first
+func (c *Client) ListJobs(ctx context.Context, opts ListOptions) (*Page[Job], error) {
+	return listPage[Job](ctx, c, "/api/v1/jobs", opts)
+}
+
+// ListComments returns one page of the comments matching the options, newest first
+func (c *Client) ListComments(ctx context.Context, opts ListOptions) (*Page[Comment], error) {
+	return listPage[Comment](ctx, c, "/api/v1/comments", opts)
+}
+
+// ListPolls returns one page of the polls matching the options, newest first
+func (c *Client) ListPolls(ctx context.Context, opts ListOptions) (*Page[Poll], error) {
+	return listPage[Poll](ctx, c, "/api/v1/polls", opts)
+}
+
+// Stories iterates over every story matching the options from opts.Offset on, fetching the
+// pages as it goes. Iteration stops after the first error, yielded with a nil story.
+func (c *Client) Stories(ctx context.Context, opts ListOptions) iter.Seq2[*Story, error] {
+	return paginate(ctx, opts, c.ListStories)
+}
+
+// Asks iterates over every Ask HN post matching the options, like Stories
+func (c *Client) Asks(ctx context.Context, opts ListOptions) iter.Seq2[*Ask, error] {
+	return paginate(ctx, opts, c.ListAsks)
+}
+
+// Jobs iterates over every job matching the options, like Stories
+func (c *Client) Jobs(ctx context.Context, opts ListOptions) iter.Seq2[*Job, error] {
+	return paginate(ctx, opts, c.ListJobs)
+}
+
+// Comments iterates over every comment matching the options, like Stories
+func (c *Client) Comments(ctx context.Context, opts ListOptions) iter.Seq2[*Comment, error] {
+	return paginate(ctx, opts, c.ListComments)
+}
+
+// Polls iterates over every poll matching the options, like Stories
+func (c *Client) Polls(ctx context.Context, opts ListOptions) iter.Seq2[*Poll, error] {
+	return paginate(ctx, opts, c.ListPolls)
+}
+
+// GetStory returns the story with the given ID; IsNotFound tells a missing one apart
+func (c *Client) GetStory(ctx context.Context, id int) (*Story, error) {
+	return getItem[Story](ctx, c, "/api/v1/stories/", id)
+}
+
+// GetAsk returns the Ask HN post with the given ID
+func (c *Client) GetAsk(ctx context.Context, id int) (*Ask, error) {
+	return getItem[Ask](ctx, c, "/api/v1/asks/", id)
+}
+
+// GetJob returns the job with the given ID
+func (c *Client) GetJob(ctx context.Context, id int) (*Job, error) {
+	return getItem[Job](ctx, c, "/api/v1/jobs/", id)
+}
+
+// GetComment returns the comment with the given ID
+func (c *Client) GetComment(ctx context.Context, id int) (*Comment, error) {
+	return getItem[Comment](ctx, c, "/api/v1/comments/", id)
+}
+
+// GetPoll returns the poll with the given ID
+func (c *Client) GetPoll(ctx context.Context, id int) (*Poll, error) {
+	return getItem[Poll](ctx, c, "/api/v1/polls/", id)
+}
+
+// GetFields returns the selected JSON fields (e.g. "id", "title", "score") of the item with the
+// given ID, of any kind
+func (c *Client) GetFields(ctx context.Context, id int, fields ...string) (map[string]interface{}, error) {
+	var item map[string]interface{}
+	query := url.Values{}
+	if len(fields) > 0 {
+		query.Set("fields", strings.Join(fields, ","))
+	}
+	if err := c.get(ctx, "/api/v1/items/"+strconv.Itoa(id), query, &item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ItemRef names an item of a BatchGet call. Type ("story", "ask", "job", "comment", "poll" or
+// "pollopt"), when known, saves the service a lookup.
+type ItemRef struct {
+	ID   int    `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+// BatchItem is an item returned by BatchGet. Item holds its raw JSON; Decode reads it into the
+// model of its Type.
+type BatchItem struct {
+	ID   int             `json:"id"`
+	Type string          `json:"type"`
+	Item json.RawMessage `json:"item"`
+}
+
+// Decode reads the item into v, e.g. a *Story when Type is "story"
+func (b BatchItem) Decode(v interface{}) error {
+	return json.Unmarshal(b.Item, v)
+}
+
+// BatchResult holds the items found by BatchGet in request order and the IDs of the others
+type BatchResult struct {
+	Items   []BatchItem `json:"items"`
+	Missing []int       `json:"missing"`
+}
+
+// BatchGet fetches the items in one request; the service rejects more than its
+// BATCH_GET_MAX_ITEMS, MaxBatchGetItems by default
+func (c *Client) BatchGet(ctx context.Context, refs []ItemRef) (*BatchResult, error) {
+	var result BatchResult
+	if err := c.post(ctx, "/api/v1/items:batchGet", struct {
+		Items []ItemRef `json:"items"`
+	}{refs}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BatchGetIDs fetches any number of items of unknown kinds, MaxBatchGetItems per request
+func (c *Client) BatchGetIDs(ctx context.Context, ids []int) (*BatchResult, error) {
+	result := &BatchResult{Items: []BatchItem{}, Missing: []int{}}
+	for start := 0; start < len(ids); start += MaxBatchGetItems {
+		chunk := ids[start:min(start+MaxBatchGetItems, len(ids))]
+		refs := make([]ItemRef, len(chunk))
+		for i, id := range chunk {
+			refs[i] = ItemRef{ID: id}
+		}
+		part, err := c.BatchGet(ctx, refs)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, part.Items...)
+		result.Missing = append(result.Missing, part.Missing...)
+	}
+	return result, nil
+}
+
+// listPage fetches one page of a list endpoint
+func listPage[T any](ctx context.Context, c *Client, path string, opts ListOptions) (*Page[T], error) {
+	var page Page[T]
+	if err := c.get(ctx, path, opts.values(), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// getItem fetches the item with the given ID from the detail endpoint under prefix
+func getItem[T any](ctx context.Context, c *Client, prefix string, id int) (*T, error) {
+	var item T
+	if err := c.get(ctx, prefix+strconv.Itoa(id), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// paginate iterates over the items of the pages fetched by list, moving the offset on by the
+// size of each page until the last one
+func paginate[T any](ctx context.Context, opts ListOptions, list func(context.Context, ListOptions) (*Page[T], error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for {
+			page, err := list(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !page.HasMore || len(page.Items) == 0 {
+				return
+			}
+			opts.Offset += len(page.Items)
+		}
+	}
+}
+
+// setString sets a query parameter unless value is empty
+func setString(q url.Values, name, value string) {
+	if value != "" {
+		q.Set(name, value)
+	}
+}
+
+// setInt sets a query parameter unless value is 0
+func setInt(q url.Values, name string, value int64) {
+	if value != 0 {
+		q.Set(name, strconv.FormatInt(value, 10))
+	}
+}
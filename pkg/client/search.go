@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// SearchOptions is a full-text search over the search indexes. Zero values leave a filter out.
+type SearchOptions struct {
+	Query          string
+	Types          []string // item types to search; all by default
+	Rank           string   // "relevance" (default), "hot", "top" or "new"
+	Author         string
+	Tag            string
+	Start          int64 // created_at lower bound (unix seconds, inclusive)
+	End            int64 // created_at upper bound (unix seconds, inclusive)
+	SnippetLength  int
+	KeepDuplicates bool // lists stories sharing a canonical URL separately instead of collapsed
+	Limit          int
+	Offset         int
+}
+
+// SearchHit is a matching item; Item holds its indexed fields
+type SearchHit struct {
+	Type       string                 `json:"type"`
+	ID         int                    `json:"id"`
+	Score      float64                `json:"score"`
+	Item       map[string]interface{} `json:"item"`
+	Highlights map[string][]string    `json:"highlights,omitempty"`
+	Duplicates []SearchHit            `json:"duplicates,omitempty"`
+}
+
+// SearchResult is a page of hits ranked across types, with the matches per type and tag
+type SearchResult struct {
+	Query  string           `json:"query"`
+	Total  int64            `json:"total"`
+	Facets map[string]int64 `json:"facets"`
+	Tags   map[string]int64 `json:"tags"`
+	Hits   []SearchHit      `json:"hits"`
+}
+
+// Search runs a full-text search; the service answers 503 unavailable when it has no search
+// backend configured
+func (c *Client) Search(ctx context.Context, opts SearchOptions) (*SearchResult, error) {
+	q := ListOptions{
+		Query:  opts.Query,
+		Author: opts.Author,
+		Start:  opts.Start,
+		End:    opts.End,
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	}.values()
+	if len(opts.Types) > 0 {
+		q.Set("types", strings.Join(opts.Types, ","))
+	}
+	setString(q, "rank", opts.Rank)
+	setString(q, "tag", opts.Tag)
+	setInt(q, "snippet_length", int64(opts.SnippetLength))
+	if opts.KeepDuplicates {
+		q.Set("dedupe", strconv.FormatBool(false))
+	}
+
+	var result SearchResult
+	if err := c.get(ctx, "/api/v1/search", q, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
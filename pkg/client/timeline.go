@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// TimelinePage is one page of the timeline or the feed; NextCursor is empty on the last one
+type TimelinePage struct {
+	Items      []*TimelineItem `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// Timeline returns the page of stories, asks, jobs and polls, newest first, after cursor (the
+// NextCursor of the previous page, empty for the first one)
+func (c *Client) Timeline(ctx context.Context, cursor string, limit int) (*TimelinePage, error) {
+	return c.timelinePage(ctx, "/api/v1/timeline", cursor, limit)
+}
+
+// Feed returns a page of the items of the authors followed by the tenant of the API key, like
+// Timeline
+func (c *Client) Feed(ctx context.Context, cursor string, limit int) (*TimelinePage, error) {
+	return c.timelinePage(ctx, "/api/v1/feed", cursor, limit)
+}
+
+// TimelineItems iterates over the whole timeline, newest first, limit items per request.
+// Iteration stops after the first error, yielded with a nil item.
+func (c *Client) TimelineItems(ctx context.Context, limit int) iter.Seq2[*TimelineItem, error] {
+	return c.followCursor(ctx, c.Timeline, limit)
+}
+
+// FeedItems iterates over the whole feed of the tenant, like TimelineItems
+func (c *Client) FeedItems(ctx context.Context, limit int) iter.Seq2[*TimelineItem, error] {
+	return c.followCursor(ctx, c.Feed, limit)
+}
+
+// StreamTimeline polls the timeline every interval and calls fn with each item created at or
+// after since (unix seconds; 0 for now) that it has not reported yet, oldest first. It returns
+// nil when ctx is done, or the first error of fn or of a poll that failed all its retries.
+// Items stored with a creation time older than the newest one reported, e.g. by a backfill, are
+// not reported.
+func (c *Client) StreamTimeline(ctx context.Context, since int64, interval time.Duration, fn func(*TimelineItem) error) error {
+	if since <= 0 {
+		since = time.Now().Unix()
+	}
+	newest := TimelineItem{Created_At: since}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var fresh []*TimelineItem
+		for item, err := range c.TimelineItems(ctx, 100) {
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			if !after(item, &newest) {
+				break
+			}
+			fresh = append(fresh, item)
+		}
+		slices.Reverse(fresh)
+		for _, item := range fresh {
+			if err := fn(item); err != nil {
+				return err
+			}
+			newest = *item
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// after reports whether item comes after mark in timeline order: created later, or at the same
+// time with a higher ID. A mark without ID includes the items created at its time.
+func after(item, mark *TimelineItem) bool {
+	if item.Created_At != mark.Created_At {
+		return item.Created_At > mark.Created_At
+	}
+	return mark.ID == 0 || item.ID > mark.ID
+}
+
+// timelinePage fetches one page of a cursor-paginated endpoint
+func (c *Client) timelinePage(ctx context.Context, path, cursor string, limit int) (*TimelinePage, error) {
+	q := url.Values{}
+	setString(q, "cursor", cursor)
+	setInt(q, "limit", int64(limit))
+	var page TimelinePage
+	if err := c.get(ctx, path, q, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// followCursor iterates over the items of the pages fetched by list, following their cursors
+func (c *Client) followCursor(ctx context.Context, list func(context.Context, string, int) (*TimelinePage, error), limit int) iter.Seq2[*TimelineItem, error] {
+	return func(yield func(*TimelineItem, error) bool) {
+		cursor := ""
+		for {
+			page, err := list(ctx, cursor, limit)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
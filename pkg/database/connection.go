@@ -3,13 +3,14 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"internship-project/internal/config"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 var db *sql.DB
@@ -22,6 +23,7 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	Schema   string // lowercase schema searched before public and holding the migrated tables ("" = public)
 }
 
 // GetDefaultConfig returns default database configuration from environment variables
@@ -33,9 +35,53 @@ func GetDefaultConfig() *Config {
 		Password: config.GetEnv("DB_PASSWORD", "password"),
 		DBName:   config.GetEnv("DB_NAME", "hackernews"),
 		SSLMode:  config.GetEnv("DB_SSLMODE", "disable"),
+		Schema:   config.GetEnv("DB_SCHEMA", ""),
 	}
 }
 
+// ConnectionString returns the lib/pq connection string of the config
+func (c *Config) ConnectionString() string {
+	conn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
+	)
+	if c.Schema != "" {
+		conn += fmt.Sprintf(" search_path=%s,public", c.Schema)
+	}
+	return conn
+}
+
+// CreateSchema creates the schema of the config if it does not exist yet
+func CreateSchema(config *Config) error {
+	return execWithoutSchema(config, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(config.Schema))
+}
+
+// DropSchema drops the schema of the config with every table in it
+func DropSchema(config *Config) error {
+	return execWithoutSchema(config, "DROP SCHEMA IF EXISTS "+pq.QuoteIdentifier(config.Schema)+" CASCADE")
+}
+
+// execWithoutSchema runs a statement on a short-lived connection to the database of the config
+// that does not search its schema, which may not exist yet
+func execWithoutSchema(config *Config, statement string) error {
+	if config.Schema == "" {
+		return fmt.Errorf("no schema configured")
+	}
+	plain := *config
+	plain.Schema = ""
+
+	conn, err := sql.Open("postgres", plain.ConnectionString())
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec(statement); err != nil {
+		return fmt.Errorf("failed to run %q: %w", statement, err)
+	}
+	return nil
+}
+
 // DropAndRecreateDatabase drops the existing database and creates a new one
 func DropAndRecreateDatabase(config *Config) error {
 	if config == nil {
@@ -48,12 +94,7 @@ func DropAndRecreateDatabase(config *Config) error {
 	tempConfig := *config
 	tempConfig.DBName = "postgres"
 
-	connectionString := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		tempConfig.Host, tempConfig.Port, tempConfig.User, tempConfig.Password, tempConfig.DBName, tempConfig.SSLMode,
-	)
-
-	tempDB, err := sql.Open("postgres", connectionString)
+	tempDB, err := sql.Open("postgres", tempConfig.ConnectionString())
 	if err != nil {
 		return fmt.Errorf("failed to connect to postgres database: %w", err)
 	}
@@ -90,13 +131,8 @@ func Connect(config *Config) error {
 	log.Printf("Attempting to connect to database: host=%s port=%s user=%s dbname=%s",
 		config.Host, config.Port, config.User, config.DBName)
 
-	connectionString := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-	)
-
 	var err error
-	db, err = sql.Open("postgres", connectionString)
+	db, err = sql.Open("postgres", config.ConnectionString())
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -159,11 +195,13 @@ func CleanDatabase() error {
 	dropTables := []string{
 		"DROP TABLE IF EXISTS poll_options CASCADE",
 		"DROP TABLE IF EXISTS polls CASCADE",
+		"DROP TABLE IF EXISTS comments_cold CASCADE",
 		"DROP TABLE IF EXISTS comments CASCADE",
 		"DROP TABLE IF EXISTS jobs CASCADE",
 		"DROP TABLE IF EXISTS asks CASCADE",
 		"DROP TABLE IF EXISTS stories CASCADE",
 		"DROP TABLE IF EXISTS users CASCADE",
+		"DROP TABLE IF EXISTS tenants CASCADE",
 	}
 
 	for _, dropSQL := range dropTables {
@@ -177,6 +215,25 @@ func CleanDatabase() error {
 	return nil
 }
 
+// SchemaVersion is the number of the latest migration in migrations/, which Migrate records in
+// the schema_version table; adding a migration bumps it
+const SchemaVersion = 43
+
+// StoredSchemaVersion returns the schema version recorded by the last Migrate, 0 for a database
+// migrated before versions were recorded
+func StoredSchemaVersion(ctx context.Context) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	var version int
+	err := db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version)
+	var pqErr *pq.Error
+	if errors.Is(err, sql.ErrNoRows) || errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table
+		return 0, nil
+	}
+	return version, err
+}
+
 // Migrate runs database migrations
 func Migrate() error {
 	if db == nil {
@@ -266,12 +323,1036 @@ CREATE TABLE IF NOT EXISTS poll_options (
     created_at BIGINT NOT NULL,
     votes INTEGER DEFAULT 0 CHECK (votes >= 0)
 );
+
+-- Tenants table
+CREATE TABLE IF NOT EXISTS tenants (
+    name VARCHAR(64) PRIMARY KEY,
+    api_key VARCHAR(128) UNIQUE NOT NULL,
+    requests_per_minute INTEGER NOT NULL DEFAULT 0 CHECK (requests_per_minute >= 0), -- 0 means unlimited
+    retention_days INTEGER NOT NULL DEFAULT 0 CHECK (retention_days >= 0),           -- 0 means keep forever
+    created_at BIGINT NOT NULL
+);
+
+-- Tenant column on item tables; rows synced before tenants existed belong to 'default'
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_stories_tenant ON stories (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_asks_tenant ON asks (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant ON jobs (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_tenant ON comments (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_polls_tenant ON polls (tenant, created_at DESC);
+
+-- Source column on item tables; every item synced before sources existed came from HackerNews
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+
+-- Spam score on item tables (0 = clean, 1 = certain spam)
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+
+-- Daily aggregates materialized by the stats cron job; day is the UTC calendar date
+CREATE TABLE IF NOT EXISTS daily_stats (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    median_score REAL, -- NULL for types without a score (comments)
+    PRIMARY KEY (tenant, day, type)
+);
+
+CREATE TABLE IF NOT EXISTS daily_top_domains (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    rank INTEGER NOT NULL,
+    domain TEXT NOT NULL,
+    item_count INTEGER NOT NULL,
+    PRIMARY KEY (tenant, day, rank)
+);
+
+CREATE TABLE IF NOT EXISTS daily_top_authors (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    rank INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    item_count INTEGER NOT NULL,
+    PRIMARY KEY (tenant, day, rank)
+);
+
+-- Result of the periodic story URL check; NULL link_checked_at means never checked
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_status VARCHAR(16);
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_status_code INTEGER;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_checked_at BIGINT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS archive_url TEXT;
+CREATE INDEX IF NOT EXISTS idx_stories_link_checked_at ON stories (link_checked_at NULLS FIRST) WHERE url <> '';
+
+-- Authors and saved searches each API key follows; they make up its personalized feed
+CREATE TABLE IF NOT EXISTS follows (
+    tenant VARCHAR(64) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (tenant, author)
+);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    query TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    UNIQUE (tenant, query)
+);
+
+-- Reports of the data-quality job, one row per run
+CREATE TABLE IF NOT EXISTS data_quality_reports (
+    id SERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    checks JSONB NOT NULL
+);
+
+-- Last change time of each item row (unix milliseconds) and a notification per change on the
+-- item_changes channel, so the change listener can index rows as soon as they are written
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_stories_updated_at ON stories (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_asks_updated_at ON asks (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_jobs_updated_at ON jobs (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_updated_at ON comments (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_polls_updated_at ON polls (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_poll_options_updated_at ON poll_options (updated_at, id);
+
+CREATE OR REPLACE FUNCTION touch_item_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := (EXTRACT(EPOCH FROM clock_timestamp()) * 1000)::BIGINT;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Runs AFTER the write so upserts skipped by ON CONFLICT ... WHERE do not notify;
+-- TG_ARGV[0] is the item kind of the table
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stories_touch_updated_at ON stories;
+CREATE TRIGGER stories_touch_updated_at BEFORE INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS stories_notify_change ON stories;
+CREATE TRIGGER stories_notify_change AFTER INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('story');
+DROP TRIGGER IF EXISTS asks_touch_updated_at ON asks;
+CREATE TRIGGER asks_touch_updated_at BEFORE INSERT OR UPDATE ON asks
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS asks_notify_change ON asks;
+CREATE TRIGGER asks_notify_change AFTER INSERT OR UPDATE ON asks
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('ask');
+DROP TRIGGER IF EXISTS jobs_touch_updated_at ON jobs;
+CREATE TRIGGER jobs_touch_updated_at BEFORE INSERT OR UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS jobs_notify_change ON jobs;
+CREATE TRIGGER jobs_notify_change AFTER INSERT OR UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('job');
+DROP TRIGGER IF EXISTS comments_touch_updated_at ON comments;
+CREATE TRIGGER comments_touch_updated_at BEFORE INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS comments_notify_change ON comments;
+CREATE TRIGGER comments_notify_change AFTER INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('comment');
+DROP TRIGGER IF EXISTS polls_touch_updated_at ON polls;
+CREATE TRIGGER polls_touch_updated_at BEFORE INSERT OR UPDATE ON polls
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS polls_notify_change ON polls;
+CREATE TRIGGER polls_notify_change AFTER INSERT OR UPDATE ON polls
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('poll');
+DROP TRIGGER IF EXISTS poll_options_touch_updated_at ON poll_options;
+CREATE TRIGGER poll_options_touch_updated_at BEFORE INSERT OR UPDATE ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS poll_options_notify_change ON poll_options;
+CREATE TRIGGER poll_options_notify_change AFTER INSERT OR UPDATE ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('pollopt');
+
+-- Stories sharing a canonical URL with other stories, each linked to the highest-scored of them
+-- (which links to itself); rebuilt by the duplicate-linking job
+CREATE TABLE IF NOT EXISTS story_duplicates (
+    story_id INTEGER PRIMARY KEY,
+    canonical_id INTEGER NOT NULL,
+    canonical_url TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_story_duplicates_canonical_id ON story_duplicates (canonical_id);
+
+-- Search requests recorded for query analytics; query_key groups spellings of the same query
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    query_key CHAR(64) NOT NULL,
+    query TEXT NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    hits BIGINT NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_search_queries_created_at ON search_queries (created_at, query_key);
+
+-- Topic tags extracted from item titles by the "tag" ETL plugin
+CREATE TABLE IF NOT EXISTS item_tags (
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (kind, item_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags (tag);
+
+-- Items watched by API keys; a webhook is called when the score, comment count or top stories rank
+-- moves by the configured delta from the last notified values (a zero delta ignores the field)
+CREATE TABLE IF NOT EXISTS watches (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    webhook_url TEXT NOT NULL,
+    score_delta INTEGER NOT NULL DEFAULT 0,
+    comments_delta INTEGER NOT NULL DEFAULT 0,
+    rank_delta INTEGER NOT NULL DEFAULT 0,
+    last_score INTEGER NOT NULL DEFAULT 0,
+    last_comments INTEGER NOT NULL DEFAULT 0,
+    last_rank INTEGER NOT NULL DEFAULT 0,
+    created_at BIGINT NOT NULL,
+    notified_at BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_watches_item_id ON watches (item_id);
+CREATE INDEX IF NOT EXISTS idx_watches_tenant ON watches (tenant);
+
+-- The HackerNews API types asks "story" and poll options "pollopt"; asks are stored typed "ask"
+-- and poll options keep the API type
+UPDATE asks SET type = 'ask' WHERE type <> 'ask';
+ALTER TABLE asks ALTER COLUMN type SET DEFAULT 'ask';
+UPDATE poll_options SET type = 'pollopt' WHERE type <> 'pollopt';
+ALTER TABLE poll_options ALTER COLUMN type SET DEFAULT 'pollopt';
+
+-- Materialized path of each comment: the IDs from the root item down to its parent, so a subtree
+-- is a single indexed containment query. A trigger keeps it in sync with parent_id and repairs the
+-- descendants of a comment stored after its replies.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS ancestor_ids INTEGER[];
+CREATE INDEX IF NOT EXISTS idx_comments_ancestor_ids ON comments USING GIN (ancestor_ids);
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE((SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A comment found among its own ancestors has corrupted parent links; stop there instead of looping
+CREATE OR REPLACE FUNCTION cascade_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NOT NEW.id = ANY(NEW.ancestor_ids) THEN
+        UPDATE comments SET ancestor_ids = NEW.ancestor_ids || NEW.id
+        WHERE parent_id = NEW.id AND ancestor_ids IS DISTINCT FROM NEW.ancestor_ids || NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_set_ancestors ON comments;
+CREATE TRIGGER comments_set_ancestors BEFORE INSERT OR UPDATE OF parent_id ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_insert ON comments;
+CREATE TRIGGER comments_cascade_ancestors_insert AFTER INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_update ON comments;
+CREATE TRIGGER comments_cascade_ancestors_update AFTER UPDATE ON comments
+    FOR EACH ROW WHEN (OLD.ancestor_ids IS DISTINCT FROM NEW.ancestor_ids)
+    EXECUTE FUNCTION cascade_comment_ancestors();
+
+-- Backfill the comments stored before the column existed, top-down from the comments whose
+-- parent is not a stored comment
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM comments WHERE ancestor_ids IS NULL) THEN
+        WITH RECURSIVE paths AS (
+            SELECT c.id, CASE WHEN c.parent_id IS NULL THEN '{}' ELSE ARRAY[c.parent_id] END AS ancestor_ids
+            FROM comments c WHERE NOT EXISTS (SELECT 1 FROM comments p WHERE p.id = c.parent_id)
+            UNION ALL
+            SELECT c.id, p.ancestor_ids || c.parent_id
+            FROM comments c JOIN paths p ON c.parent_id = p.id
+            WHERE NOT c.id = ANY(p.ancestor_ids)
+        )
+        UPDATE comments c SET ancestor_ids = paths.ancestor_ids
+        FROM paths WHERE c.id = paths.id AND c.ancestor_ids IS NULL;
+    END IF;
+END;
+$$;
+
+-- Depth of each comment below its root item (1 for top-level comments) and its 1-based rank
+-- among its siblings, in the order of the parent's kids list, so threads are served ordered and
+-- indented without walking them. The rank is NULL while the parent is not stored.
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INTEGER GENERATED ALWAYS AS (cardinality(ancestor_ids)) STORED;
+
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent INTEGER, child INTEGER) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+-- Ranks the stored replies of an item whose kids list was written; TG_ARGV[0] is the kids column
+CREATE OR REPLACE FUNCTION rank_comment_replies() RETURNS trigger AS $$
+DECLARE
+    kids INTEGER[];
+BEGIN
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO kids USING NEW;
+    UPDATE comments SET sibling_rank = array_position(kids, id)
+    WHERE parent_id = NEW.id AND sibling_rank IS DISTINCT FROM array_position(kids, id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE((SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    NEW.sibling_rank := comment_sibling_rank(NEW.parent_id, NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'comments' AND column_name = 'sibling_rank') THEN
+        ALTER TABLE comments ADD COLUMN sibling_rank INTEGER;
+        UPDATE comments SET sibling_rank = comment_sibling_rank(parent_id, id);
+    END IF;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS comments_rank_replies ON comments;
+CREATE TRIGGER comments_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON comments
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+DROP TRIGGER IF EXISTS stories_rank_replies ON stories;
+CREATE TRIGGER stories_rank_replies AFTER INSERT OR UPDATE OF comments_ids ON stories
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('comments_ids');
+DROP TRIGGER IF EXISTS asks_rank_replies ON asks;
+CREATE TRIGGER asks_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON asks
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+DROP TRIGGER IF EXISTS polls_rank_replies ON polls;
+CREATE TRIGGER polls_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON polls
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+
+-- Users addressed by comments, filled by the "mention" ETL plugin: named as @username or author
+-- of the item the comment replies to; one row per comment and user
+CREATE TABLE IF NOT EXISTS mentions (
+    comment_id INTEGER NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (comment_id, username)
+);
+CREATE INDEX IF NOT EXISTS idx_mentions_username ON mentions (username, created_at DESC, comment_id DESC);
+
+-- Queue of background tasks (refetches, reindexes, backfills) run by the task worker. A running
+-- task whose run_after passed lost its worker and is claimed again.
+CREATE TABLE IF NOT EXISTS tasks (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_after BIGINT NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks (run_after, id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status, id DESC);
+
+-- Values of the operator-defined computed fields (COMPUTED_FIELDS_FILE), filled by the "compute"
+-- ETL plugin; the index serves ordering items by a field
+CREATE TABLE IF NOT EXISTS computed_fields (
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (kind, item_id, name)
+);
+CREATE INDEX IF NOT EXISTS idx_computed_fields_name ON computed_fields (name, value DESC);
+
+-- Log of the watch webhook calls. The payload is kept as sent (JSON, not JSONB) so a redelivery
+-- repeats the exact body, idempotency key included.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(64) NOT NULL UNIQUE,
+    watch_id BIGINT NOT NULL,
+    url TEXT NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_watch ON webhook_deliveries (watch_id, id DESC);
+
+-- OpenGraph/Twitter card metadata of story URLs, fetched by the link preview job
+CREATE TABLE IF NOT EXISTS link_previews (
+    story_id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    fetched_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_link_previews_fetched_at ON link_previews (fetched_at);
+
+-- Per-site aggregates of the stories linking to it, refreshed by the domains job; the favicon is
+-- looked up separately when DOMAIN_FAVICONS_ENABLED is set
+CREATE TABLE IF NOT EXISTS domains (
+    domain TEXT PRIMARY KEY,
+    favicon_url TEXT NOT NULL DEFAULT '',
+    first_seen BIGINT NOT NULL,
+    last_seen BIGINT NOT NULL,
+    story_count INTEGER NOT NULL,
+    average_score DOUBLE PRECISION NOT NULL,
+    top_score INTEGER NOT NULL,
+    comment_count INTEGER NOT NULL,
+    refreshed_at BIGINT NOT NULL,
+    favicon_checked_at BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_domains_favicon_checked_at ON domains (favicon_checked_at);
+
+-- Comments older than COMMENTS_COLD_AFTER_MONTHS, moved out of the hot comments table by the
+-- archive job into monthly partitions created as it goes. The copy has the columns of comments,
+-- depth stored as a plain value, and no triggers: archived comments only change when refetched.
+CREATE TABLE IF NOT EXISTS comments_cold (LIKE comments INCLUDING DEFAULTS, PRIMARY KEY (id, created_at))
+    PARTITION BY RANGE (created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_author ON comments_cold (author);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_parent_id ON comments_cold (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_ancestor_ids ON comments_cold USING GIN (ancestor_ids);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_tenant ON comments_cold (tenant, created_at DESC);
+
+-- The archive job picks the oldest hot comments
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at);
+
+-- Every stored comment, hot or archived
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+-- Replies to archived comments still get their ancestors and rank
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent INTEGER, child INTEGER) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM comments_cold WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE(
+            (SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id),
+            (SELECT ancestor_ids FROM comments_cold WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    NEW.sibling_rank := comment_sibling_rank(NEW.parent_id, NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Stories and comments partitioned by month of created_at (unix seconds), so backfills of tens of
+-- millions of rows keep their indexes small and date-bounded queries only scan the months they
+-- cover. The primary keys include created_at as partitioning requires; it never changes for an
+-- item, so upserts conflict on (id, created_at).
+
+-- Creates the missing monthly partitions of a table partitioned by created_at from the month of
+-- from_ts to the month of to_ts, named <table>_YYYY_MM, and returns how many it created.
+-- Creators take a lock only when a partition is missing, so concurrent writers do not race.
+CREATE OR REPLACE FUNCTION ensure_month_partitions(tbl TEXT, from_ts BIGINT, to_ts BIGINT) RETURNS INTEGER AS $$
+DECLARE
+    month_start TIMESTAMP := date_trunc('month', to_timestamp(from_ts) AT TIME ZONE 'UTC');
+    until_ts TIMESTAMP := to_timestamp(to_ts) AT TIME ZONE 'UTC';
+    locked BOOLEAN := false;
+    created INTEGER := 0;
+    part_name TEXT;
+BEGIN
+    WHILE month_start <= until_ts LOOP
+        part_name := tbl || '_' || to_char(month_start, 'YYYY_MM');
+        IF to_regclass(quote_ident(part_name)) IS NULL THEN
+            IF NOT locked THEN
+                PERFORM pg_advisory_xact_lock(hashtext('ensure_month_partitions:' || tbl));
+                locked := true;
+            END IF;
+            IF to_regclass(quote_ident(part_name)) IS NULL THEN
+                EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%s) TO (%s)', part_name, tbl,
+                    extract(epoch FROM month_start)::BIGINT, extract(epoch FROM month_start + interval '1 month')::BIGINT);
+                created := created + 1;
+            END IF;
+        END IF;
+        month_start := month_start + interval '1 month';
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Replaces a plain table by one partitioned by month with the same columns, defaults and checks,
+-- copying its rows; a no-op once the table is partitioned. The copy runs once, at the first
+-- start after the upgrade. Indexes and triggers are recreated below.
+CREATE OR REPLACE FUNCTION partition_by_month(tbl TEXT) RETURNS void AS $$
+DECLARE
+    legacy TEXT := tbl || '_unpartitioned';
+    pkey TEXT;
+    column_list TEXT;
+    oldest BIGINT;
+    newest BIGINT;
+BEGIN
+    IF (SELECT relkind FROM pg_class WHERE oid = to_regclass(quote_ident(tbl))) IS DISTINCT FROM 'r' THEN
+        RETURN;
+    END IF;
+
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', tbl, legacy);
+    SELECT conname INTO pkey FROM pg_constraint WHERE conrelid = to_regclass(quote_ident(legacy)) AND contype = 'p';
+    IF pkey IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE %I RENAME CONSTRAINT %I TO %I', legacy, pkey, legacy || '_pkey');
+    END IF;
+    EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS,
+        PRIMARY KEY (id, created_at)) PARTITION BY RANGE (created_at)', tbl, legacy);
+
+    EXECUTE format('SELECT MIN(created_at), MAX(created_at) FROM %I', legacy) INTO oldest, newest;
+    IF oldest IS NOT NULL THEN
+        PERFORM ensure_month_partitions(tbl, oldest, newest);
+    END IF;
+    SELECT string_agg(quote_ident(attname), ', ' ORDER BY attnum) INTO column_list
+    FROM pg_attribute
+    WHERE attrelid = to_regclass(quote_ident(legacy)) AND attnum > 0 AND NOT attisdropped AND attgenerated = '';
+    EXECUTE format('INSERT INTO %I (%s) SELECT %s FROM %I', tbl, column_list, column_list, legacy);
+    EXECUTE format('DROP TABLE %I', legacy);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP VIEW IF EXISTS comments_all;
+SELECT partition_by_month('stories');
+SELECT partition_by_month('comments');
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+CREATE INDEX IF NOT EXISTS idx_stories_tenant ON stories (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stories_link_checked_at ON stories (link_checked_at NULLS FIRST) WHERE url <> '';
+CREATE INDEX IF NOT EXISTS idx_stories_updated_at ON stories (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_tenant ON comments (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_updated_at ON comments (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_ancestor_ids ON comments USING GIN (ancestor_ids);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at);
+
+DROP TRIGGER IF EXISTS stories_touch_updated_at ON stories;
+CREATE TRIGGER stories_touch_updated_at BEFORE INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS stories_notify_change ON stories;
+CREATE TRIGGER stories_notify_change AFTER INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('story');
+DROP TRIGGER IF EXISTS stories_rank_replies ON stories;
+CREATE TRIGGER stories_rank_replies AFTER INSERT OR UPDATE OF comments_ids ON stories
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('comments_ids');
+DROP TRIGGER IF EXISTS comments_touch_updated_at ON comments;
+CREATE TRIGGER comments_touch_updated_at BEFORE INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS comments_notify_change ON comments;
+CREATE TRIGGER comments_notify_change AFTER INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('comment');
+DROP TRIGGER IF EXISTS comments_set_ancestors ON comments;
+CREATE TRIGGER comments_set_ancestors BEFORE INSERT OR UPDATE OF parent_id ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_insert ON comments;
+CREATE TRIGGER comments_cascade_ancestors_insert AFTER INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_update ON comments;
+CREATE TRIGGER comments_cascade_ancestors_update AFTER UPDATE ON comments
+    FOR EACH ROW WHEN (OLD.ancestor_ids IS DISTINCT FROM NEW.ancestor_ids)
+    EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_rank_replies ON comments;
+CREATE TRIGGER comments_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON comments
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+
+-- Log of item changes numbered in commit order, read by the pollers of /api/v1/changes with the
+-- last sequence number they saw. The change trigger writes the rows unnumbered; the sequencer
+-- numbers the committed ones one run at a time, so no number is ever handed out below one a
+-- poller may already have read, whatever order concurrent writers commit in.
+CREATE TABLE IF NOT EXISTS item_change_log (
+    id BIGSERIAL PRIMARY KEY,
+    seq BIGINT UNIQUE,
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    tenant VARCHAR(64) NOT NULL,
+    operation VARCHAR(8) NOT NULL,
+    changed_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_change_log_pending ON item_change_log (id) WHERE seq IS NULL;
+CREATE INDEX IF NOT EXISTS idx_item_change_log_changed_at ON item_change_log (changed_at);
+
+-- The last sequence number handed out; its row is locked by a sequencer run until it commits
+CREATE TABLE IF NOT EXISTS item_change_sequencer (
+    singleton BOOLEAN PRIMARY KEY DEFAULT true CHECK (singleton),
+    last_seq BIGINT NOT NULL DEFAULT 0
+);
+INSERT INTO item_change_sequencer DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Item payloads rejected before persistence, e.g. for not matching the JSON schema of their type,
+-- kept as served (JSON, not JSONB) to investigate upstream drift. A payload seen again for the
+-- same item only counts one more occurrence.
+CREATE TABLE IF NOT EXISTS item_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT '',
+    reason VARCHAR(32) NOT NULL,
+    errors TEXT[] NOT NULL DEFAULT '{}',
+    payload JSON NOT NULL,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_dead_letters_payload ON item_dead_letters (item_id, md5(payload::text));
+CREATE INDEX IF NOT EXISTS idx_item_dead_letters_kind ON item_dead_letters (kind, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_item_dead_letters_updated_at ON item_dead_letters (updated_at DESC);
+
+-- Items removed from the item tables, because HackerNews deleted them or marked them dead or
+-- because they outlived the retention of their tenant, with their last stored row. A tombstone
+-- is pending until its event was published and its search document deleted; the tombstone job
+-- retries the pending ones, so downstream indexes and caches end up forgetting every removal.
+CREATE TABLE IF NOT EXISTS item_tombstones (
+    item_id INTEGER PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    tenant VARCHAR(64) NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    item JSONB NOT NULL,
+    deleted_at BIGINT NOT NULL,
+    emitted_at BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_item_tombstones_pending ON item_tombstones (deleted_at) WHERE emitted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_item_tombstones_emitted_at ON item_tombstones (emitted_at) WHERE emitted_at IS NOT NULL;
+
+-- Last run of each scheduled job, for the status command and anyone checking that the sync is
+-- alive without reading the logs of the process running the jobs
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(64) PRIMARY KEY,
+    runs BIGINT NOT NULL DEFAULT 0,
+    last_started_at BIGINT NOT NULL,
+    last_finished_at BIGINT NOT NULL,
+    last_duration_ms BIGINT NOT NULL
+);
+
+-- Rolling story score distributions materialized by the stats cron job: the row of a day covers the
+-- stories created in the SCORE_PERCENTILE_WINDOW_DAYS days ending on it (UTC). cutoffs holds the
+-- scores of the 0th to 100th percentiles, so ranking a story needs no scan of the stories table.
+CREATE TABLE IF NOT EXISTS daily_score_percentiles (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    story_count INTEGER NOT NULL,
+    cutoffs REAL[] NOT NULL,
+    PRIMARY KEY (tenant, day)
+);
+
+-- Percentile (0-100) of the story score among the stories of its window, set when the story is
+-- synced; NULL until the first distribution covering its day exists
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS score_percentile SMALLINT;
+CREATE INDEX IF NOT EXISTS idx_stories_score_percentile ON stories (score_percentile, created_at DESC)
+    WHERE score_percentile IS NOT NULL;
+
+-- Stories and comments posted from a young account or as the author's first post, set by the
+-- "new-author" ETL plugin and mirrored in the search documents as new_author
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments_cold ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_stories_new_author ON stories (tenant, created_at DESC) WHERE new_author;
+CREATE INDEX IF NOT EXISTS idx_comments_new_author ON comments (tenant, created_at DESC) WHERE new_author;
+
+-- Looking up the earlier posts of authors without a stored account
+CREATE INDEX IF NOT EXISTS idx_stories_author ON stories (author, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_author ON comments (author, created_at);
+
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+-- Quality of the comments of active threads, scored by the comment ranking job from their replies,
+-- depth, author karma and length, with their 1-based rank among their siblings by that score.
+-- Kept apart from comments so re-ranking a thread does not log a change for each of its comments.
+CREATE TABLE IF NOT EXISTS comment_quality (
+    comment_id INTEGER PRIMARY KEY,
+    score REAL NOT NULL,
+    sibling_rank INTEGER NOT NULL,
+    ranked_at BIGINT NOT NULL
+);
+
+-- Submissions of high-score story URLs to the Wayback Machine save API. The snapshot goes to
+-- archive_url; NULL archive_requested_at means never submitted.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS archive_requested_at BIGINT;
+CREATE INDEX IF NOT EXISTS idx_stories_archive_pending ON stories (score DESC) WHERE url <> '' AND archive_url IS NULL;
+
+-- When the update sync last fetched each user's profile; profiles listed again by the updates
+-- feed within PROFILE_CHECK_INTERVAL are not fetched again
+ALTER TABLE users ADD COLUMN IF NOT EXISTS karma_checked_at BIGINT;
+
+-- The last payload served by the API for each fetched item, kept as served (JSON, not JSONB)
+-- while ITEM_RAW_PAYLOADS_ENABLED is set, so items can be parsed again after a parsing fix
+-- without fetching them again
+CREATE TABLE IF NOT EXISTS item_payloads (
+    item_id INTEGER PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL DEFAULT '',
+    payload JSON NOT NULL,
+    fetched_at BIGINT NOT NULL
+);
+
+-- Fields of the API items without a column of their own, kept by key as served until they get
+-- one; items saved before or without any have '{}'. Queried by containment (extra @> ...).
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE comments_cold ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_stories_extra ON stories USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_asks_extra ON asks USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_jobs_extra ON jobs USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_comments_extra ON comments USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_extra ON comments_cold USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_polls_extra ON polls USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_poll_options_extra ON poll_options USING GIN (extra jsonb_path_ops);
+
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+-- Internal tables are keyed by UUIDv7 generated by the application (see pkg/database/uuid.go).
+-- Existing rows get a version 7 key built from their creation time and former ID, so they keep
+-- their order and sort before the rows created from then on.
+CREATE OR REPLACE FUNCTION legacy_uuid_v7(created_at BIGINT, id BIGINT) RETURNS UUID AS $$
+    SELECT (lpad(to_hex(created_at * 1000), 12, '0') || '7000' || '8' || lpad(to_hex(id), 15, '0'))::UUID
+$$ LANGUAGE SQL IMMUTABLE;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'watches'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE webhook_deliveries ADD COLUMN watch_uuid UUID;
+        UPDATE webhook_deliveries d SET watch_uuid = legacy_uuid_v7(
+            COALESCE((SELECT w.created_at FROM watches w WHERE w.id = d.watch_id), 0), d.watch_id);
+        ALTER TABLE webhook_deliveries DROP COLUMN watch_id;
+        ALTER TABLE webhook_deliveries RENAME COLUMN watch_uuid TO watch_id;
+        ALTER TABLE webhook_deliveries ALTER COLUMN watch_id SET NOT NULL;
+
+        ALTER TABLE watches ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE watches ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS watches_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'webhook_deliveries'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE webhook_deliveries ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE webhook_deliveries ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS webhook_deliveries_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'tasks'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE tasks ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE tasks ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS tasks_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'data_quality_reports'
+                 AND column_name = 'id' AND data_type = 'integer') THEN
+        ALTER TABLE data_quality_reports ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE data_quality_reports ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS data_quality_reports_id_seq;
+    END IF;
+END;
+$$;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_watch ON webhook_deliveries (watch_id, id DESC);
+
+-- The number of the latest migration applied, recorded by Migrate so the doctor command can tell
+-- a database the binary has migrations pending for; a single row
+CREATE TABLE IF NOT EXISTS schema_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version INTEGER NOT NULL,
+    migrated_at BIGINT NOT NULL
+);
+
+-- Per API key limits on the saved searches, watches and distinct webhook URLs a tenant may hold:
+-- 0 takes the QUOTA_* default of the configuration and -1 means unlimited
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_saved_searches INTEGER NOT NULL DEFAULT 0 CHECK (max_saved_searches >= -1);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_watches INTEGER NOT NULL DEFAULT 0 CHECK (max_watches >= -1);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_webhook_endpoints INTEGER NOT NULL DEFAULT 0 CHECK (max_webhook_endpoints >= -1);
+
+-- The values an update replaced, by column, so the state of an item at a past time can be rebuilt
+-- from its current row by undoing its later changes; NULL for inserts and deletes. The touched
+-- updated_at is left out.
+ALTER TABLE item_change_log ADD COLUMN IF NOT EXISTS previous JSONB;
+CREATE INDEX IF NOT EXISTS idx_item_change_log_item ON item_change_log (kind, item_id, changed_at);
+
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+DECLARE
+    previous JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(o.key, o.value) INTO previous
+        FROM jsonb_each(to_jsonb(OLD)) o
+        WHERE o.key <> 'updated_at' AND o.value IS DISTINCT FROM to_jsonb(NEW) -> o.key;
+    END IF;
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at, previous)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at, previous);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Daily sizes of the STORAGE_TABLES, the last of each UTC day, from which the storage report
+-- measures their growth; the database as a whole is recorded under the empty table name
+CREATE TABLE IF NOT EXISTS storage_snapshots (
+    table_name VARCHAR(128) NOT NULL,
+    day BIGINT NOT NULL,
+    row_count BIGINT NOT NULL,
+    table_bytes BIGINT NOT NULL,
+    index_bytes BIGINT NOT NULL,
+    PRIMARY KEY (day, table_name)
+);
+
+-- Item IDs as BIGINT: the Lobsters and RSS sources map their items to negative IDs outside the
+-- INTEGER range, so the item tables and every column holding item IDs are widened. The tables are
+-- rewritten once, at the first start after the upgrade; the columns already widened are skipped.
+DO $$
+DECLARE
+    target RECORD;
+    widened BOOLEAN := false;
+BEGIN
+    FOR target IN
+        SELECT c.table_name, c.column_name, c.udt_name
+        FROM information_schema.columns c
+        JOIN (VALUES
+            ('users', 'submitted_ids'),
+            ('stories', 'id'), ('stories', 'comments_ids'),
+            ('asks', 'id'), ('asks', 'reply_ids'),
+            ('jobs', 'id'),
+            ('comments', 'id'), ('comments', 'parent_id'), ('comments', 'reply_ids'), ('comments', 'ancestor_ids'),
+            ('comments_cold', 'id'), ('comments_cold', 'parent_id'), ('comments_cold', 'reply_ids'), ('comments_cold', 'ancestor_ids'),
+            ('polls', 'id'), ('polls', 'poll_options'), ('polls', 'reply_ids'),
+            ('poll_options', 'id'), ('poll_options', 'poll_id'),
+            ('story_duplicates', 'story_id'), ('story_duplicates', 'canonical_id'),
+            ('item_tags', 'item_id'),
+            ('watches', 'item_id'),
+            ('mentions', 'comment_id'),
+            ('computed_fields', 'item_id'),
+            ('link_previews', 'story_id'),
+            ('item_change_log', 'item_id'),
+            ('item_dead_letters', 'item_id'),
+            ('item_tombstones', 'item_id'),
+            ('comment_quality', 'comment_id'),
+            ('item_payloads', 'item_id')
+        ) AS t (table_name, column_name) ON c.table_name = t.table_name AND c.column_name = t.column_name
+        WHERE c.table_schema = current_schema() AND c.udt_name IN ('int4', '_int4')
+    LOOP
+        IF NOT widened THEN
+            DROP VIEW IF EXISTS comments_all;
+            widened := true;
+        END IF;
+
+        -- depth is generated from the ancestors, so it is dropped while they are widened. It comes
+        -- back as the last column, in comments_cold too, for the two tables to keep the same
+        -- column order the archive job and comments_all rely on.
+        IF target.table_name = 'comments' AND target.column_name = 'ancestor_ids' THEN
+            ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+            ALTER TABLE comments ALTER COLUMN ancestor_ids TYPE BIGINT[];
+            ALTER TABLE comments ADD COLUMN depth INTEGER GENERATED ALWAYS AS (cardinality(ancestor_ids)) STORED;
+            ALTER TABLE comments_cold DROP COLUMN IF EXISTS depth;
+            ALTER TABLE comments_cold ADD COLUMN depth INTEGER;
+            UPDATE comments_cold SET depth = cardinality(ancestor_ids);
+            CONTINUE;
+        END IF;
+
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE %s', target.table_name, target.column_name,
+            CASE target.udt_name WHEN 'int4' THEN 'BIGINT' ELSE 'BIGINT[]' END);
+    END LOOP;
+
+    IF widened THEN
+        CREATE VIEW comments_all AS
+            SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+    END IF;
+END;
+$$;
+
+-- The sibling rank takes the widened IDs; the INTEGER version the earlier migrations define is
+-- no longer called
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent BIGINT, child BIGINT) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM comments_cold WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION rank_comment_replies() RETURNS trigger AS $$
+DECLARE
+    kids BIGINT[];
+BEGIN
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO kids USING NEW;
+    UPDATE comments SET sibling_rank = array_position(kids, id)
+    WHERE parent_id = NEW.id AND sibling_rank IS DISTINCT FROM array_position(kids, id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- An update that changed nothing but updated_at logs no replaced values as '{}' instead of NULL,
+-- so a NULL previous on an update only marks the changes logged before the values were kept
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+DECLARE
+    previous JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        SELECT COALESCE(jsonb_object_agg(o.key, o.value), '{}') INTO previous
+        FROM jsonb_each(to_jsonb(OLD)) o
+        WHERE o.key <> 'updated_at' AND o.value IS DISTINCT FROM to_jsonb(NEW) -> o.key;
+    END IF;
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at, previous)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at, previous);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Sources and title keywords of the items a tenant collects: a synced story, ask, job or poll
+-- matching a tenant (any of its sources, if set, and any of its keywords, if set) is stored in
+-- the first such tenant by name instead of 'default'; tenants with neither collect nothing.
+-- Comments and poll options join the tenant of their parent when it is stored. Items are
+-- assigned when first stored, so a change of the rules applies to the items synced after it.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS sources TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS keywords TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION item_tenant(item_source TEXT, item_title TEXT) RETURNS VARCHAR AS $$
+    SELECT COALESCE((
+        SELECT name FROM tenants
+        WHERE (sources <> '{}' OR keywords <> '{}')
+          AND (sources = '{}' OR item_source = ANY(sources))
+          AND (keywords = '{}' OR EXISTS (
+              SELECT 1 FROM unnest(keywords) k WHERE strpos(lower(item_title), lower(k)) > 0))
+        ORDER BY name LIMIT 1), 'default');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION assign_item_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := item_tenant(NEW.source, NEW.title);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assign_reply_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := COALESCE(
+            (SELECT tenant FROM comments WHERE id = NEW.parent_id),
+            (SELECT tenant FROM stories WHERE id = NEW.parent_id),
+            (SELECT tenant FROM asks WHERE id = NEW.parent_id),
+            (SELECT tenant FROM polls WHERE id = NEW.parent_id),
+            'default');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assign_poll_option_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := COALESCE((SELECT tenant FROM polls WHERE id = NEW.poll_id), 'default');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stories_assign_tenant ON stories;
+CREATE TRIGGER stories_assign_tenant BEFORE INSERT ON stories
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS asks_assign_tenant ON asks;
+CREATE TRIGGER asks_assign_tenant BEFORE INSERT ON asks
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS jobs_assign_tenant ON jobs;
+CREATE TRIGGER jobs_assign_tenant BEFORE INSERT ON jobs
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS polls_assign_tenant ON polls;
+CREATE TRIGGER polls_assign_tenant BEFORE INSERT ON polls
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS comments_assign_tenant ON comments;
+CREATE TRIGGER comments_assign_tenant BEFORE INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION assign_reply_tenant();
+DROP TRIGGER IF EXISTS poll_options_assign_tenant ON poll_options;
+CREATE TRIGGER poll_options_assign_tenant BEFORE INSERT ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION assign_poll_option_tenant();
 `
 
 	_, err := db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
+	// A binary older than the schema leaves the newer version in place
+	_, err = db.Exec(
+		`INSERT INTO schema_version (version, migrated_at) VALUES ($1, $2)
+		 ON CONFLICT (id) DO UPDATE SET version = GREATEST(schema_version.version, EXCLUDED.version),
+			migrated_at = EXCLUDED.migrated_at`,
+		SchemaVersion, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 
 	log.Println("Database migrations completed successfully")
 	return nil
@@ -0,0 +1,22 @@
+-- Tenants table
+CREATE TABLE IF NOT EXISTS tenants (
+    name VARCHAR(64) PRIMARY KEY,
+    api_key VARCHAR(128) UNIQUE NOT NULL,
+    requests_per_minute INTEGER NOT NULL DEFAULT 0 CHECK (requests_per_minute >= 0), -- 0 means unlimited
+    retention_days INTEGER NOT NULL DEFAULT 0 CHECK (retention_days >= 0),           -- 0 means keep forever
+    created_at BIGINT NOT NULL
+);
+
+-- Tenant column on item tables; rows synced before tenants existed belong to 'default'
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS tenant VARCHAR(64) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_stories_tenant ON stories (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_asks_tenant ON asks (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant ON jobs (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_tenant ON comments (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_polls_tenant ON polls (tenant, created_at DESC);
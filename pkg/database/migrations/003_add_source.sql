@@ -0,0 +1,7 @@
+-- Source column on item tables; every item synced before sources existed came from HackerNews
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'hackernews';
@@ -0,0 +1,6 @@
+-- Spam score on item tables (0 = clean, 1 = certain spam)
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS spam_score REAL NOT NULL DEFAULT 0;
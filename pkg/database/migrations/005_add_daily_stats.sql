@@ -0,0 +1,27 @@
+-- Daily aggregates materialized by the stats cron job; day is the UTC calendar date
+CREATE TABLE IF NOT EXISTS daily_stats (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    median_score REAL, -- NULL for types without a score (comments)
+    PRIMARY KEY (tenant, day, type)
+);
+
+CREATE TABLE IF NOT EXISTS daily_top_domains (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    rank INTEGER NOT NULL,
+    domain TEXT NOT NULL,
+    item_count INTEGER NOT NULL,
+    PRIMARY KEY (tenant, day, rank)
+);
+
+CREATE TABLE IF NOT EXISTS daily_top_authors (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    rank INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    item_count INTEGER NOT NULL,
+    PRIMARY KEY (tenant, day, rank)
+);
@@ -0,0 +1,6 @@
+-- Result of the periodic story URL check; NULL link_checked_at means never checked
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_status VARCHAR(16);
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_status_code INTEGER;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS link_checked_at BIGINT;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS archive_url TEXT;
+CREATE INDEX IF NOT EXISTS idx_stories_link_checked_at ON stories (link_checked_at NULLS FIRST) WHERE url <> '';
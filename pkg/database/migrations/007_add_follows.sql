@@ -0,0 +1,15 @@
+-- Authors and saved searches each API key follows; they make up its personalized feed
+CREATE TABLE IF NOT EXISTS follows (
+    tenant VARCHAR(64) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (tenant, author)
+);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    query TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    UNIQUE (tenant, query)
+);
@@ -0,0 +1,6 @@
+-- Reports of the data-quality job, one row per run
+CREATE TABLE IF NOT EXISTS data_quality_reports (
+    id SERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    checks JSONB NOT NULL
+);
@@ -0,0 +1,69 @@
+-- Last change time of each item row (unix milliseconds) and a notification per change on the
+-- item_changes channel, so the change listener can index rows as soon as they are written
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS updated_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_stories_updated_at ON stories (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_asks_updated_at ON asks (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_jobs_updated_at ON jobs (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_updated_at ON comments (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_polls_updated_at ON polls (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_poll_options_updated_at ON poll_options (updated_at, id);
+
+CREATE OR REPLACE FUNCTION touch_item_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := (EXTRACT(EPOCH FROM clock_timestamp()) * 1000)::BIGINT;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Runs AFTER the write so upserts skipped by ON CONFLICT ... WHERE do not notify;
+-- TG_ARGV[0] is the item kind of the table
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stories_touch_updated_at ON stories;
+CREATE TRIGGER stories_touch_updated_at BEFORE INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS stories_notify_change ON stories;
+CREATE TRIGGER stories_notify_change AFTER INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('story');
+DROP TRIGGER IF EXISTS asks_touch_updated_at ON asks;
+CREATE TRIGGER asks_touch_updated_at BEFORE INSERT OR UPDATE ON asks
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS asks_notify_change ON asks;
+CREATE TRIGGER asks_notify_change AFTER INSERT OR UPDATE ON asks
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('ask');
+DROP TRIGGER IF EXISTS jobs_touch_updated_at ON jobs;
+CREATE TRIGGER jobs_touch_updated_at BEFORE INSERT OR UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS jobs_notify_change ON jobs;
+CREATE TRIGGER jobs_notify_change AFTER INSERT OR UPDATE ON jobs
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('job');
+DROP TRIGGER IF EXISTS comments_touch_updated_at ON comments;
+CREATE TRIGGER comments_touch_updated_at BEFORE INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS comments_notify_change ON comments;
+CREATE TRIGGER comments_notify_change AFTER INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('comment');
+DROP TRIGGER IF EXISTS polls_touch_updated_at ON polls;
+CREATE TRIGGER polls_touch_updated_at BEFORE INSERT OR UPDATE ON polls
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS polls_notify_change ON polls;
+CREATE TRIGGER polls_notify_change AFTER INSERT OR UPDATE ON polls
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('poll');
+DROP TRIGGER IF EXISTS poll_options_touch_updated_at ON poll_options;
+CREATE TRIGGER poll_options_touch_updated_at BEFORE INSERT OR UPDATE ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS poll_options_notify_change ON poll_options;
+CREATE TRIGGER poll_options_notify_change AFTER INSERT OR UPDATE ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('pollopt');
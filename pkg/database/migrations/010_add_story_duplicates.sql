@@ -0,0 +1,8 @@
+-- Stories sharing a canonical URL with other stories, each linked to the highest-scored of them
+-- (which links to itself); rebuilt by the duplicate-linking job
+CREATE TABLE IF NOT EXISTS story_duplicates (
+    story_id INTEGER PRIMARY KEY,
+    canonical_id INTEGER NOT NULL,
+    canonical_url TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_story_duplicates_canonical_id ON story_duplicates (canonical_id);
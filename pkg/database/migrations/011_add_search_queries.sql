@@ -0,0 +1,12 @@
+-- Search requests recorded for query analytics; query_key groups spellings of the same query
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    query_key CHAR(64) NOT NULL,
+    query TEXT NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    hits BIGINT NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    created_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_search_queries_created_at ON search_queries (created_at, query_key);
@@ -0,0 +1,8 @@
+-- Topic tags extracted from item titles by the "tag" ETL plugin
+CREATE TABLE IF NOT EXISTS item_tags (
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (kind, item_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags (tag);
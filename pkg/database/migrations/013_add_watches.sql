@@ -0,0 +1,19 @@
+-- Items watched by API keys; a webhook is called when the score, comment count or top stories rank
+-- moves by the configured delta from the last notified values (a zero delta ignores the field)
+CREATE TABLE IF NOT EXISTS watches (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    webhook_url TEXT NOT NULL,
+    score_delta INTEGER NOT NULL DEFAULT 0,
+    comments_delta INTEGER NOT NULL DEFAULT 0,
+    rank_delta INTEGER NOT NULL DEFAULT 0,
+    last_score INTEGER NOT NULL DEFAULT 0,
+    last_comments INTEGER NOT NULL DEFAULT 0,
+    last_rank INTEGER NOT NULL DEFAULT 0,
+    created_at BIGINT NOT NULL,
+    notified_at BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_watches_item_id ON watches (item_id);
+CREATE INDEX IF NOT EXISTS idx_watches_tenant ON watches (tenant);
@@ -0,0 +1,6 @@
+-- The HackerNews API types asks "story" and poll options "pollopt"; asks are stored typed "ask"
+-- and poll options keep the API type
+UPDATE asks SET type = 'ask' WHERE type <> 'ask';
+ALTER TABLE asks ALTER COLUMN type SET DEFAULT 'ask';
+UPDATE poll_options SET type = 'pollopt' WHERE type <> 'pollopt';
+ALTER TABLE poll_options ALTER COLUMN type SET DEFAULT 'pollopt';
@@ -0,0 +1,57 @@
+-- Materialized path of each comment: the IDs from the root item down to its parent, so a subtree
+-- is a single indexed containment query. A trigger keeps it in sync with parent_id and repairs the
+-- descendants of a comment stored after its replies.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS ancestor_ids INTEGER[];
+CREATE INDEX IF NOT EXISTS idx_comments_ancestor_ids ON comments USING GIN (ancestor_ids);
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE((SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A comment found among its own ancestors has corrupted parent links; stop there instead of looping
+CREATE OR REPLACE FUNCTION cascade_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NOT NEW.id = ANY(NEW.ancestor_ids) THEN
+        UPDATE comments SET ancestor_ids = NEW.ancestor_ids || NEW.id
+        WHERE parent_id = NEW.id AND ancestor_ids IS DISTINCT FROM NEW.ancestor_ids || NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_set_ancestors ON comments;
+CREATE TRIGGER comments_set_ancestors BEFORE INSERT OR UPDATE OF parent_id ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_insert ON comments;
+CREATE TRIGGER comments_cascade_ancestors_insert AFTER INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_update ON comments;
+CREATE TRIGGER comments_cascade_ancestors_update AFTER UPDATE ON comments
+    FOR EACH ROW WHEN (OLD.ancestor_ids IS DISTINCT FROM NEW.ancestor_ids)
+    EXECUTE FUNCTION cascade_comment_ancestors();
+
+-- Backfill the comments stored before the column existed, top-down from the comments whose
+-- parent is not a stored comment
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM comments WHERE ancestor_ids IS NULL) THEN
+        WITH RECURSIVE paths AS (
+            SELECT c.id, CASE WHEN c.parent_id IS NULL THEN '{}' ELSE ARRAY[c.parent_id] END AS ancestor_ids
+            FROM comments c WHERE NOT EXISTS (SELECT 1 FROM comments p WHERE p.id = c.parent_id)
+            UNION ALL
+            SELECT c.id, p.ancestor_ids || c.parent_id
+            FROM comments c JOIN paths p ON c.parent_id = p.id
+            WHERE NOT c.id = ANY(p.ancestor_ids)
+        )
+        UPDATE comments c SET ancestor_ids = paths.ancestor_ids
+        FROM paths WHERE c.id = paths.id AND c.ancestor_ids IS NULL;
+    END IF;
+END;
+$$;
@@ -0,0 +1,60 @@
+-- Depth of each comment below its root item (1 for top-level comments) and its 1-based rank
+-- among its siblings, in the order of the parent's kids list, so threads are served ordered and
+-- indented without walking them. The rank is NULL while the parent is not stored.
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INTEGER GENERATED ALWAYS AS (cardinality(ancestor_ids)) STORED;
+
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent INTEGER, child INTEGER) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+-- Ranks the stored replies of an item whose kids list was written; TG_ARGV[0] is the kids column
+CREATE OR REPLACE FUNCTION rank_comment_replies() RETURNS trigger AS $$
+DECLARE
+    kids INTEGER[];
+BEGIN
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO kids USING NEW;
+    UPDATE comments SET sibling_rank = array_position(kids, id)
+    WHERE parent_id = NEW.id AND sibling_rank IS DISTINCT FROM array_position(kids, id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE((SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    NEW.sibling_rank := comment_sibling_rank(NEW.parent_id, NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'comments' AND column_name = 'sibling_rank') THEN
+        ALTER TABLE comments ADD COLUMN sibling_rank INTEGER;
+        UPDATE comments SET sibling_rank = comment_sibling_rank(parent_id, id);
+    END IF;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS comments_rank_replies ON comments;
+CREATE TRIGGER comments_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON comments
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+DROP TRIGGER IF EXISTS stories_rank_replies ON stories;
+CREATE TRIGGER stories_rank_replies AFTER INSERT OR UPDATE OF comments_ids ON stories
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('comments_ids');
+DROP TRIGGER IF EXISTS asks_rank_replies ON asks;
+CREATE TRIGGER asks_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON asks
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
+DROP TRIGGER IF EXISTS polls_rank_replies ON polls;
+CREATE TRIGGER polls_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON polls
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
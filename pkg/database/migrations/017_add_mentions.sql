@@ -0,0 +1,11 @@
+-- Users addressed by comments, filled by the "mention" ETL plugin: named as @username or author
+-- of the item the comment replies to; one row per comment and user
+CREATE TABLE IF NOT EXISTS mentions (
+    comment_id INTEGER NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (comment_id, username)
+);
+CREATE INDEX IF NOT EXISTS idx_mentions_username ON mentions (username, created_at DESC, comment_id DESC);
@@ -0,0 +1,16 @@
+-- Queue of background tasks (refetches, reindexes, backfills) run by the task worker. A running
+-- task whose run_after passed lost its worker and is claimed again.
+CREATE TABLE IF NOT EXISTS tasks (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_after BIGINT NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks (run_after, id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status, id DESC);
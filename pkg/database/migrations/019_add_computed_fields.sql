@@ -0,0 +1,10 @@
+-- Values of the operator-defined computed fields (COMPUTED_FIELDS_FILE), filled by the "compute"
+-- ETL plugin; the index serves ordering items by a field
+CREATE TABLE IF NOT EXISTS computed_fields (
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (kind, item_id, name)
+);
+CREATE INDEX IF NOT EXISTS idx_computed_fields_name ON computed_fields (name, value DESC);
@@ -0,0 +1,17 @@
+-- Log of the watch webhook calls. The payload is kept as sent (JSON, not JSONB) so a redelivery
+-- repeats the exact body, idempotency key included.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(64) NOT NULL UNIQUE,
+    watch_id BIGINT NOT NULL,
+    url TEXT NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_watch ON webhook_deliveries (watch_id, id DESC);
@@ -0,0 +1,12 @@
+-- OpenGraph/Twitter card metadata of story URLs, fetched by the link preview job
+CREATE TABLE IF NOT EXISTS link_previews (
+    story_id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    fetched_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_link_previews_fetched_at ON link_previews (fetched_at);
@@ -0,0 +1,15 @@
+-- Per-site aggregates of the stories linking to it, refreshed by the domains job; the favicon is
+-- looked up separately when DOMAIN_FAVICONS_ENABLED is set
+CREATE TABLE IF NOT EXISTS domains (
+    domain TEXT PRIMARY KEY,
+    favicon_url TEXT NOT NULL DEFAULT '',
+    first_seen BIGINT NOT NULL,
+    last_seen BIGINT NOT NULL,
+    story_count INTEGER NOT NULL,
+    average_score DOUBLE PRECISION NOT NULL,
+    top_score INTEGER NOT NULL,
+    comment_count INTEGER NOT NULL,
+    refreshed_at BIGINT NOT NULL,
+    favicon_checked_at BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_domains_favicon_checked_at ON domains (favicon_checked_at);
@@ -0,0 +1,40 @@
+-- Comments older than COMMENTS_COLD_AFTER_MONTHS, moved out of the hot comments table by the
+-- archive job into monthly partitions created as it goes. The copy has the columns of comments,
+-- depth stored as a plain value, and no triggers: archived comments only change when refetched.
+CREATE TABLE IF NOT EXISTS comments_cold (LIKE comments INCLUDING DEFAULTS, PRIMARY KEY (id, created_at))
+    PARTITION BY RANGE (created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_author ON comments_cold (author);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_parent_id ON comments_cold (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_ancestor_ids ON comments_cold USING GIN (ancestor_ids);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_tenant ON comments_cold (tenant, created_at DESC);
+
+-- The archive job picks the oldest hot comments
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at);
+
+-- Every stored comment, hot or archived
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+-- Replies to archived comments still get their ancestors and rank
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent INTEGER, child INTEGER) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM comments_cold WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION set_comment_ancestors() RETURNS trigger AS $$
+BEGIN
+    IF NEW.parent_id IS NULL THEN
+        NEW.ancestor_ids := '{}';
+    ELSE
+        NEW.ancestor_ids := COALESCE(
+            (SELECT ancestor_ids FROM comments WHERE id = NEW.parent_id),
+            (SELECT ancestor_ids FROM comments_cold WHERE id = NEW.parent_id), '{}') || NEW.parent_id;
+    END IF;
+    NEW.sibling_rank := comment_sibling_rank(NEW.parent_id, NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,113 @@
+-- Stories and comments partitioned by month of created_at (unix seconds), so backfills of tens of
+-- millions of rows keep their indexes small and date-bounded queries only scan the months they
+-- cover. The primary keys include created_at as partitioning requires; it never changes for an
+-- item, so upserts conflict on (id, created_at).
+
+-- Creates the missing monthly partitions of a table partitioned by created_at from the month of
+-- from_ts to the month of to_ts, named <table>_YYYY_MM, and returns how many it created.
+-- Creators take a lock only when a partition is missing, so concurrent writers do not race.
+CREATE OR REPLACE FUNCTION ensure_month_partitions(tbl TEXT, from_ts BIGINT, to_ts BIGINT) RETURNS INTEGER AS $$
+DECLARE
+    month_start TIMESTAMP := date_trunc('month', to_timestamp(from_ts) AT TIME ZONE 'UTC');
+    until_ts TIMESTAMP := to_timestamp(to_ts) AT TIME ZONE 'UTC';
+    locked BOOLEAN := false;
+    created INTEGER := 0;
+    part_name TEXT;
+BEGIN
+    WHILE month_start <= until_ts LOOP
+        part_name := tbl || '_' || to_char(month_start, 'YYYY_MM');
+        IF to_regclass(quote_ident(part_name)) IS NULL THEN
+            IF NOT locked THEN
+                PERFORM pg_advisory_xact_lock(hashtext('ensure_month_partitions:' || tbl));
+                locked := true;
+            END IF;
+            IF to_regclass(quote_ident(part_name)) IS NULL THEN
+                EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%s) TO (%s)', part_name, tbl,
+                    extract(epoch FROM month_start)::BIGINT, extract(epoch FROM month_start + interval '1 month')::BIGINT);
+                created := created + 1;
+            END IF;
+        END IF;
+        month_start := month_start + interval '1 month';
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Replaces a plain table by one partitioned by month with the same columns, defaults and checks,
+-- copying its rows; a no-op once the table is partitioned. The copy runs once, at the first
+-- start after the upgrade. Indexes and triggers are recreated below.
+CREATE OR REPLACE FUNCTION partition_by_month(tbl TEXT) RETURNS void AS $$
+DECLARE
+    legacy TEXT := tbl || '_unpartitioned';
+    pkey TEXT;
+    column_list TEXT;
+    oldest BIGINT;
+    newest BIGINT;
+BEGIN
+    IF (SELECT relkind FROM pg_class WHERE oid = to_regclass(quote_ident(tbl))) IS DISTINCT FROM 'r' THEN
+        RETURN;
+    END IF;
+
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', tbl, legacy);
+    SELECT conname INTO pkey FROM pg_constraint WHERE conrelid = to_regclass(quote_ident(legacy)) AND contype = 'p';
+    IF pkey IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE %I RENAME CONSTRAINT %I TO %I', legacy, pkey, legacy || '_pkey');
+    END IF;
+    EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING GENERATED INCLUDING CONSTRAINTS,
+        PRIMARY KEY (id, created_at)) PARTITION BY RANGE (created_at)', tbl, legacy);
+
+    EXECUTE format('SELECT MIN(created_at), MAX(created_at) FROM %I', legacy) INTO oldest, newest;
+    IF oldest IS NOT NULL THEN
+        PERFORM ensure_month_partitions(tbl, oldest, newest);
+    END IF;
+    SELECT string_agg(quote_ident(attname), ', ' ORDER BY attnum) INTO column_list
+    FROM pg_attribute
+    WHERE attrelid = to_regclass(quote_ident(legacy)) AND attnum > 0 AND NOT attisdropped AND attgenerated = '';
+    EXECUTE format('INSERT INTO %I (%s) SELECT %s FROM %I', tbl, column_list, column_list, legacy);
+    EXECUTE format('DROP TABLE %I', legacy);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP VIEW IF EXISTS comments_all;
+SELECT partition_by_month('stories');
+SELECT partition_by_month('comments');
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+
+CREATE INDEX IF NOT EXISTS idx_stories_tenant ON stories (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stories_link_checked_at ON stories (link_checked_at NULLS FIRST) WHERE url <> '';
+CREATE INDEX IF NOT EXISTS idx_stories_updated_at ON stories (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_tenant ON comments (tenant, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_updated_at ON comments (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_ancestor_ids ON comments USING GIN (ancestor_ids);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments (created_at);
+
+DROP TRIGGER IF EXISTS stories_touch_updated_at ON stories;
+CREATE TRIGGER stories_touch_updated_at BEFORE INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS stories_notify_change ON stories;
+CREATE TRIGGER stories_notify_change AFTER INSERT OR UPDATE ON stories
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('story');
+DROP TRIGGER IF EXISTS stories_rank_replies ON stories;
+CREATE TRIGGER stories_rank_replies AFTER INSERT OR UPDATE OF comments_ids ON stories
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('comments_ids');
+DROP TRIGGER IF EXISTS comments_touch_updated_at ON comments;
+CREATE TRIGGER comments_touch_updated_at BEFORE INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION touch_item_updated_at();
+DROP TRIGGER IF EXISTS comments_notify_change ON comments;
+CREATE TRIGGER comments_notify_change AFTER INSERT OR UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION notify_item_change('comment');
+DROP TRIGGER IF EXISTS comments_set_ancestors ON comments;
+CREATE TRIGGER comments_set_ancestors BEFORE INSERT OR UPDATE OF parent_id ON comments
+    FOR EACH ROW EXECUTE FUNCTION set_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_insert ON comments;
+CREATE TRIGGER comments_cascade_ancestors_insert AFTER INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_cascade_ancestors_update ON comments;
+CREATE TRIGGER comments_cascade_ancestors_update AFTER UPDATE ON comments
+    FOR EACH ROW WHEN (OLD.ancestor_ids IS DISTINCT FROM NEW.ancestor_ids)
+    EXECUTE FUNCTION cascade_comment_ancestors();
+DROP TRIGGER IF EXISTS comments_rank_replies ON comments;
+CREATE TRIGGER comments_rank_replies AFTER INSERT OR UPDATE OF reply_ids ON comments
+    FOR EACH ROW EXECUTE FUNCTION rank_comment_replies('reply_ids');
@@ -0,0 +1,32 @@
+-- Log of item changes numbered in commit order, read by the pollers of /api/v1/changes with the
+-- last sequence number they saw. The change trigger writes the rows unnumbered; the sequencer
+-- numbers the committed ones one run at a time, so no number is ever handed out below one a
+-- poller may already have read, whatever order concurrent writers commit in.
+CREATE TABLE IF NOT EXISTS item_change_log (
+    id BIGSERIAL PRIMARY KEY,
+    seq BIGINT UNIQUE,
+    kind VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    tenant VARCHAR(64) NOT NULL,
+    operation VARCHAR(8) NOT NULL,
+    changed_at BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_item_change_log_pending ON item_change_log (id) WHERE seq IS NULL;
+CREATE INDEX IF NOT EXISTS idx_item_change_log_changed_at ON item_change_log (changed_at);
+
+-- The last sequence number handed out; its row is locked by a sequencer run until it commits
+CREATE TABLE IF NOT EXISTS item_change_sequencer (
+    singleton BOOLEAN PRIMARY KEY DEFAULT true CHECK (singleton),
+    last_seq BIGINT NOT NULL DEFAULT 0
+);
+INSERT INTO item_change_sequencer DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
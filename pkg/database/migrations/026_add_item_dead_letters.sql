@@ -0,0 +1,17 @@
+-- Item payloads rejected before persistence, e.g. for not matching the JSON schema of their type,
+-- kept as served (JSON, not JSONB) to investigate upstream drift. A payload seen again for the
+-- same item only counts one more occurrence.
+CREATE TABLE IF NOT EXISTS item_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT '',
+    reason VARCHAR(32) NOT NULL,
+    errors TEXT[] NOT NULL DEFAULT '{}',
+    payload JSON NOT NULL,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_dead_letters_payload ON item_dead_letters (item_id, md5(payload::text));
+CREATE INDEX IF NOT EXISTS idx_item_dead_letters_kind ON item_dead_letters (kind, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_item_dead_letters_updated_at ON item_dead_letters (updated_at DESC);
@@ -0,0 +1,15 @@
+-- Items removed from the item tables, because HackerNews deleted them or marked them dead or
+-- because they outlived the retention of their tenant, with their last stored row. A tombstone
+-- is pending until its event was published and its search document deleted; the tombstone job
+-- retries the pending ones, so downstream indexes and caches end up forgetting every removal.
+CREATE TABLE IF NOT EXISTS item_tombstones (
+    item_id INTEGER PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    tenant VARCHAR(64) NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    item JSONB NOT NULL,
+    deleted_at BIGINT NOT NULL,
+    emitted_at BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_item_tombstones_pending ON item_tombstones (deleted_at) WHERE emitted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_item_tombstones_emitted_at ON item_tombstones (emitted_at) WHERE emitted_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Last run of each scheduled job, for the status command and anyone checking that the sync is
+-- alive without reading the logs of the process running the jobs
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(64) PRIMARY KEY,
+    runs BIGINT NOT NULL DEFAULT 0,
+    last_started_at BIGINT NOT NULL,
+    last_finished_at BIGINT NOT NULL,
+    last_duration_ms BIGINT NOT NULL
+);
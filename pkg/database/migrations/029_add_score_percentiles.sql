@@ -0,0 +1,16 @@
+-- Rolling story score distributions materialized by the stats cron job: the row of a day covers the
+-- stories created in the SCORE_PERCENTILE_WINDOW_DAYS days ending on it (UTC). cutoffs holds the
+-- scores of the 0th to 100th percentiles, so ranking a story needs no scan of the stories table.
+CREATE TABLE IF NOT EXISTS daily_score_percentiles (
+    tenant VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    story_count INTEGER NOT NULL,
+    cutoffs REAL[] NOT NULL,
+    PRIMARY KEY (tenant, day)
+);
+
+-- Percentile (0-100) of the story score among the stories of its window, set when the story is
+-- synced; NULL until the first distribution covering its day exists
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS score_percentile SMALLINT;
+CREATE INDEX IF NOT EXISTS idx_stories_score_percentile ON stories (score_percentile, created_at DESC)
+    WHERE score_percentile IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Stories and comments posted from a young account or as the author's first post, set by the
+-- "new-author" ETL plugin and mirrored in the search documents as new_author
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE comments_cold ADD COLUMN IF NOT EXISTS new_author BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_stories_new_author ON stories (tenant, created_at DESC) WHERE new_author;
+CREATE INDEX IF NOT EXISTS idx_comments_new_author ON comments (tenant, created_at DESC) WHERE new_author;
+
+-- Looking up the earlier posts of authors without a stored account
+CREATE INDEX IF NOT EXISTS idx_stories_author ON stories (author, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_author ON comments (author, created_at);
+
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
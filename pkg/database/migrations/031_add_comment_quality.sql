@@ -0,0 +1,9 @@
+-- Quality of the comments of active threads, scored by the comment ranking job from their replies,
+-- depth, author karma and length, with their 1-based rank among their siblings by that score.
+-- Kept apart from comments so re-ranking a thread does not log a change for each of its comments.
+CREATE TABLE IF NOT EXISTS comment_quality (
+    comment_id INTEGER PRIMARY KEY,
+    score REAL NOT NULL,
+    sibling_rank INTEGER NOT NULL,
+    ranked_at BIGINT NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Submissions of high-score story URLs to the Wayback Machine save API. The snapshot goes to
+-- archive_url; NULL archive_requested_at means never submitted.
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS archive_requested_at BIGINT;
+CREATE INDEX IF NOT EXISTS idx_stories_archive_pending ON stories (score DESC) WHERE url <> '' AND archive_url IS NULL;
@@ -0,0 +1,3 @@
+-- When the update sync last fetched each user's profile; profiles listed again by the updates
+-- feed within PROFILE_CHECK_INTERVAL are not fetched again
+ALTER TABLE users ADD COLUMN IF NOT EXISTS karma_checked_at BIGINT;
@@ -0,0 +1,9 @@
+-- The last payload served by the API for each fetched item, kept as served (JSON, not JSONB)
+-- while ITEM_RAW_PAYLOADS_ENABLED is set, so items can be parsed again after a parsing fix
+-- without fetching them again
+CREATE TABLE IF NOT EXISTS item_payloads (
+    item_id INTEGER PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL DEFAULT '',
+    payload JSON NOT NULL,
+    fetched_at BIGINT NOT NULL
+);
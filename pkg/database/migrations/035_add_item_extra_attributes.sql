@@ -0,0 +1,19 @@
+-- Fields of the API items without a column of their own, kept by key as served until they get
+-- one; items saved before or without any have '{}'. Queried by containment (extra @> ...).
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE asks ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE comments_cold ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE polls ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE poll_options ADD COLUMN IF NOT EXISTS extra JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_stories_extra ON stories USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_asks_extra ON asks USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_jobs_extra ON jobs USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_comments_extra ON comments USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_comments_cold_extra ON comments_cold USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_polls_extra ON polls USING GIN (extra jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_poll_options_extra ON poll_options USING GIN (extra jsonb_path_ops);
+
+CREATE OR REPLACE VIEW comments_all AS
+    SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
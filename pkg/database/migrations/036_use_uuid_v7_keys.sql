@@ -0,0 +1,50 @@
+-- Internal tables are keyed by UUIDv7 generated by the application (see pkg/database/uuid.go).
+-- Existing rows get a version 7 key built from their creation time and former ID, so they keep
+-- their order and sort before the rows created from then on.
+CREATE OR REPLACE FUNCTION legacy_uuid_v7(created_at BIGINT, id BIGINT) RETURNS UUID AS $$
+    SELECT (lpad(to_hex(created_at * 1000), 12, '0') || '7000' || '8' || lpad(to_hex(id), 15, '0'))::UUID
+$$ LANGUAGE SQL IMMUTABLE;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'watches'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE webhook_deliveries ADD COLUMN watch_uuid UUID;
+        UPDATE webhook_deliveries d SET watch_uuid = legacy_uuid_v7(
+            COALESCE((SELECT w.created_at FROM watches w WHERE w.id = d.watch_id), 0), d.watch_id);
+        ALTER TABLE webhook_deliveries DROP COLUMN watch_id;
+        ALTER TABLE webhook_deliveries RENAME COLUMN watch_uuid TO watch_id;
+        ALTER TABLE webhook_deliveries ALTER COLUMN watch_id SET NOT NULL;
+
+        ALTER TABLE watches ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE watches ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS watches_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'webhook_deliveries'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE webhook_deliveries ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE webhook_deliveries ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS webhook_deliveries_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'tasks'
+                 AND column_name = 'id' AND data_type = 'bigint') THEN
+        ALTER TABLE tasks ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE tasks ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS tasks_id_seq;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'data_quality_reports'
+                 AND column_name = 'id' AND data_type = 'integer') THEN
+        ALTER TABLE data_quality_reports ALTER COLUMN id DROP DEFAULT;
+        ALTER TABLE data_quality_reports ALTER COLUMN id TYPE UUID USING legacy_uuid_v7(created_at, id);
+        DROP SEQUENCE IF EXISTS data_quality_reports_id_seq;
+    END IF;
+END;
+$$;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_watch ON webhook_deliveries (watch_id, id DESC);
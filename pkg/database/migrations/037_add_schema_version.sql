@@ -0,0 +1,7 @@
+-- The number of the latest migration applied, recorded by Migrate so the doctor command can tell
+-- a database the binary has migrations pending for; a single row
+CREATE TABLE IF NOT EXISTS schema_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version INTEGER NOT NULL,
+    migrated_at BIGINT NOT NULL
+);
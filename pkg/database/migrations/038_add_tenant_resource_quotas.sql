@@ -0,0 +1,5 @@
+-- Per API key limits on the saved searches, watches and distinct webhook URLs a tenant may hold:
+-- 0 takes the QUOTA_* default of the configuration and -1 means unlimited
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_saved_searches INTEGER NOT NULL DEFAULT 0 CHECK (max_saved_searches >= -1);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_watches INTEGER NOT NULL DEFAULT 0 CHECK (max_watches >= -1);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_webhook_endpoints INTEGER NOT NULL DEFAULT 0 CHECK (max_webhook_endpoints >= -1);
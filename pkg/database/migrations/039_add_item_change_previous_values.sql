@@ -0,0 +1,22 @@
+-- The values an update replaced, by column, so the state of an item at a past time can be rebuilt
+-- from its current row by undoing its later changes; NULL for inserts and deletes. The touched
+-- updated_at is left out.
+ALTER TABLE item_change_log ADD COLUMN IF NOT EXISTS previous JSONB;
+CREATE INDEX IF NOT EXISTS idx_item_change_log_item ON item_change_log (kind, item_id, changed_at);
+
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+DECLARE
+    previous JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(o.key, o.value) INTO previous
+        FROM jsonb_each(to_jsonb(OLD)) o
+        WHERE o.key <> 'updated_at' AND o.value IS DISTINCT FROM to_jsonb(NEW) -> o.key;
+    END IF;
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at, previous)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at, previous);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+-- Daily sizes of the STORAGE_TABLES, the last of each UTC day, from which the storage report
+-- measures their growth; the database as a whole is recorded under the empty table name
+CREATE TABLE IF NOT EXISTS storage_snapshots (
+    table_name VARCHAR(128) NOT NULL,
+    day BIGINT NOT NULL,
+    row_count BIGINT NOT NULL,
+    table_bytes BIGINT NOT NULL,
+    index_bytes BIGINT NOT NULL,
+    PRIMARY KEY (day, table_name)
+);
@@ -0,0 +1,84 @@
+-- Item IDs as BIGINT: the Lobsters and RSS sources map their items to negative IDs outside the
+-- INTEGER range, so the item tables and every column holding item IDs are widened. The tables are
+-- rewritten once, at the first start after the upgrade; the columns already widened are skipped.
+DO $$
+DECLARE
+    target RECORD;
+    widened BOOLEAN := false;
+BEGIN
+    FOR target IN
+        SELECT c.table_name, c.column_name, c.udt_name
+        FROM information_schema.columns c
+        JOIN (VALUES
+            ('users', 'submitted_ids'),
+            ('stories', 'id'), ('stories', 'comments_ids'),
+            ('asks', 'id'), ('asks', 'reply_ids'),
+            ('jobs', 'id'),
+            ('comments', 'id'), ('comments', 'parent_id'), ('comments', 'reply_ids'), ('comments', 'ancestor_ids'),
+            ('comments_cold', 'id'), ('comments_cold', 'parent_id'), ('comments_cold', 'reply_ids'), ('comments_cold', 'ancestor_ids'),
+            ('polls', 'id'), ('polls', 'poll_options'), ('polls', 'reply_ids'),
+            ('poll_options', 'id'), ('poll_options', 'poll_id'),
+            ('story_duplicates', 'story_id'), ('story_duplicates', 'canonical_id'),
+            ('item_tags', 'item_id'),
+            ('watches', 'item_id'),
+            ('mentions', 'comment_id'),
+            ('computed_fields', 'item_id'),
+            ('link_previews', 'story_id'),
+            ('item_change_log', 'item_id'),
+            ('item_dead_letters', 'item_id'),
+            ('item_tombstones', 'item_id'),
+            ('comment_quality', 'comment_id'),
+            ('item_payloads', 'item_id')
+        ) AS t (table_name, column_name) ON c.table_name = t.table_name AND c.column_name = t.column_name
+        WHERE c.table_schema = current_schema() AND c.udt_name IN ('int4', '_int4')
+    LOOP
+        IF NOT widened THEN
+            DROP VIEW IF EXISTS comments_all;
+            widened := true;
+        END IF;
+
+        -- depth is generated from the ancestors, so it is dropped while they are widened. It comes
+        -- back as the last column, in comments_cold too, for the two tables to keep the same
+        -- column order the archive job and comments_all rely on.
+        IF target.table_name = 'comments' AND target.column_name = 'ancestor_ids' THEN
+            ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+            ALTER TABLE comments ALTER COLUMN ancestor_ids TYPE BIGINT[];
+            ALTER TABLE comments ADD COLUMN depth INTEGER GENERATED ALWAYS AS (cardinality(ancestor_ids)) STORED;
+            ALTER TABLE comments_cold DROP COLUMN IF EXISTS depth;
+            ALTER TABLE comments_cold ADD COLUMN depth INTEGER;
+            UPDATE comments_cold SET depth = cardinality(ancestor_ids);
+            CONTINUE;
+        END IF;
+
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE %s', target.table_name, target.column_name,
+            CASE target.udt_name WHEN 'int4' THEN 'BIGINT' ELSE 'BIGINT[]' END);
+    END LOOP;
+
+    IF widened THEN
+        CREATE VIEW comments_all AS
+            SELECT * FROM comments UNION ALL SELECT * FROM comments_cold;
+    END IF;
+END;
+$$;
+
+-- The sibling rank takes the widened IDs; the INTEGER version the earlier migrations define is
+-- no longer called
+CREATE OR REPLACE FUNCTION comment_sibling_rank(parent BIGINT, child BIGINT) RETURNS INTEGER AS $$
+    SELECT COALESCE(
+        (SELECT array_position(reply_ids, child) FROM comments WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM comments_cold WHERE id = parent),
+        (SELECT array_position(comments_ids, child) FROM stories WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM asks WHERE id = parent),
+        (SELECT array_position(reply_ids, child) FROM polls WHERE id = parent));
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION rank_comment_replies() RETURNS trigger AS $$
+DECLARE
+    kids BIGINT[];
+BEGIN
+    EXECUTE format('SELECT ($1).%I', TG_ARGV[0]) INTO kids USING NEW;
+    UPDATE comments SET sibling_rank = array_position(kids, id)
+    WHERE parent_id = NEW.id AND sibling_rank IS DISTINCT FROM array_position(kids, id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
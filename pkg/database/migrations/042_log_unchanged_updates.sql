@@ -0,0 +1,18 @@
+-- An update that changed nothing but updated_at logs no replaced values as '{}' instead of NULL,
+-- so a NULL previous on an update only marks the changes logged before the values were kept
+CREATE OR REPLACE FUNCTION notify_item_change() RETURNS trigger AS $$
+DECLARE
+    previous JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        SELECT COALESCE(jsonb_object_agg(o.key, o.value), '{}') INTO previous
+        FROM jsonb_each(to_jsonb(OLD)) o
+        WHERE o.key <> 'updated_at' AND o.value IS DISTINCT FROM to_jsonb(NEW) -> o.key;
+    END IF;
+    INSERT INTO item_change_log (kind, item_id, tenant, operation, changed_at, previous)
+    VALUES (TG_ARGV[0], NEW.id, NEW.tenant, lower(TG_OP), NEW.updated_at, previous);
+    PERFORM pg_notify('item_changes',
+        json_build_object('kind', TG_ARGV[0], 'id', NEW.id, 'updated_at', NEW.updated_at)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
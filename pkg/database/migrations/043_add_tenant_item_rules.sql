@@ -0,0 +1,68 @@
+-- Sources and title keywords of the items a tenant collects: a synced story, ask, job or poll
+-- matching a tenant (any of its sources, if set, and any of its keywords, if set) is stored in
+-- the first such tenant by name instead of 'default'; tenants with neither collect nothing.
+-- Comments and poll options join the tenant of their parent when it is stored. Items are
+-- assigned when first stored, so a change of the rules applies to the items synced after it.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS sources TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS keywords TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION item_tenant(item_source TEXT, item_title TEXT) RETURNS VARCHAR AS $$
+    SELECT COALESCE((
+        SELECT name FROM tenants
+        WHERE (sources <> '{}' OR keywords <> '{}')
+          AND (sources = '{}' OR item_source = ANY(sources))
+          AND (keywords = '{}' OR EXISTS (
+              SELECT 1 FROM unnest(keywords) k WHERE strpos(lower(item_title), lower(k)) > 0))
+        ORDER BY name LIMIT 1), 'default');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION assign_item_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := item_tenant(NEW.source, NEW.title);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assign_reply_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := COALESCE(
+            (SELECT tenant FROM comments WHERE id = NEW.parent_id),
+            (SELECT tenant FROM stories WHERE id = NEW.parent_id),
+            (SELECT tenant FROM asks WHERE id = NEW.parent_id),
+            (SELECT tenant FROM polls WHERE id = NEW.parent_id),
+            'default');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assign_poll_option_tenant() RETURNS trigger AS $$
+BEGIN
+    IF NEW.tenant = 'default' THEN
+        NEW.tenant := COALESCE((SELECT tenant FROM polls WHERE id = NEW.poll_id), 'default');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stories_assign_tenant ON stories;
+CREATE TRIGGER stories_assign_tenant BEFORE INSERT ON stories
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS asks_assign_tenant ON asks;
+CREATE TRIGGER asks_assign_tenant BEFORE INSERT ON asks
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS jobs_assign_tenant ON jobs;
+CREATE TRIGGER jobs_assign_tenant BEFORE INSERT ON jobs
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS polls_assign_tenant ON polls;
+CREATE TRIGGER polls_assign_tenant BEFORE INSERT ON polls
+    FOR EACH ROW EXECUTE FUNCTION assign_item_tenant();
+DROP TRIGGER IF EXISTS comments_assign_tenant ON comments;
+CREATE TRIGGER comments_assign_tenant BEFORE INSERT ON comments
+    FOR EACH ROW EXECUTE FUNCTION assign_reply_tenant();
+DROP TRIGGER IF EXISTS poll_options_assign_tenant ON poll_options;
+CREATE TRIGGER poll_options_assign_tenant BEFORE INSERT ON poll_options
+    FOR EACH ROW EXECUTE FUNCTION assign_poll_option_tenant();
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidUUID is returned by ParseUUID for strings that are not a UUID
+var ErrInvalidUUID = errors.New("invalid UUID")
+
+// NewUUIDv7 returns a new UUIDv7 (RFC 9562) in its canonical text form, greater than any
+// generated before by the process.
+//
+// The internal tables (watches, webhook deliveries, tasks, data-quality reports) are keyed by
+// these IDs rather than by IDs generated by the database: the key is known before the insert,
+// carries its creation time and sorts in creation order, so "ORDER BY id" lists rows oldest
+// first like a sequence would. New internal tables take the same keys:
+//
+//	id UUID PRIMARY KEY
+//
+// with the ID set from NewUUIDv7 by the repository inserting the row. Item tables keep the
+// HackerNews item IDs.
+func NewUUIDv7() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// ParseUUID returns s in canonical form (lowercase, hyphenated), or ErrInvalidUUID
+func ParseUUID(s string) (string, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+	return id.String(), nil
+}
+
+// UUIDv7Time returns the creation time of a UUIDv7, to the millisecond
+func UUIDv7Time(s string) (time.Time, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidUUID, s)
+	}
+	if id.Version() != 7 {
+		return time.Time{}, fmt.Errorf("%w: %q is not a version 7 UUID", ErrInvalidUUID, s)
+	}
+	return time.Unix(id.Time().UnixTime()), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/internal/reconcile"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/transport"
+	"internship-project/pkg/database"
+)
+
+// runReconcile implements the "reconcile" command: it compares the items stored in Postgres with
+// the search index and optionally republishes the missing and stale ones. It returns the exit
+// status: 0 when everything matches (or was repaired), 1 on errors and 2 on discrepancies.
+func runReconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	kinds := flags.String("kinds", strings.Join(search.Kinds(), ","), "comma-separated item kinds to reconcile")
+	from := flags.String("from", "", "first creation day to compare (YYYY-MM-DD)")
+	to := flags.String("to", "", "last creation day to compare (YYYY-MM-DD, inclusive)")
+	sample := flags.Int("sample", 200, "random items per kind whose checksums are compared")
+	full := flags.Bool("full", false, "compare every item in the range instead of a sample")
+	batch := flags.Int("batch", 500, "documents fetched per request")
+	repair := flags.Bool("repair", false, "republish missing and stale items for reindexing")
+	asJSON := flags.Bool("json", false, "print the reports as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	opts := reconcile.Options{
+		Kinds:      strings.Split(*kinds, ","),
+		SampleSize: *sample,
+		Full:       *full,
+		BatchSize:  *batch,
+		Repair:     *repair,
+	}
+	var err error
+	if opts.Start, err = parseDay(*from, 0); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -from:", err)
+		return 1
+	}
+	if opts.End, err = parseDay(*to, 24*time.Hour-time.Second); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -to:", err)
+		return 1
+	}
+
+	if !config.SinkEnabled(config.SinkOpenSearch) {
+		fmt.Fprintf(os.Stderr, "the %s sink is disabled by SINKS\n", config.SinkOpenSearch)
+		return 1
+	}
+	if opts.Repair && !config.SinkEnabled(config.SinkEvents) {
+		fmt.Fprintf(os.Stderr, "-repair republishes items, but the %s sink is disabled by SINKS\n", config.SinkEvents)
+		return 1
+	}
+	index := search.NewClient()
+	if index == nil {
+		fmt.Fprintln(os.Stderr, "OPENSEARCH_URL is not set")
+		return 1
+	}
+	if err := database.Connect(database.GetDefaultConfig()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to database:", err)
+		return 1
+	}
+	defer database.Close()
+
+	var publisher transport.Publisher
+	if opts.Repair {
+		if publisher, err = transport.NewPublisher(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to create event publisher:", err)
+			return 1
+		}
+		defer publisher.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	reconciler := reconcile.NewReconciler(postgres.NewDataQualityRepository(), index, publisher)
+	reports, err := reconciler.Run(ctx, opts)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+	} else {
+		for _, report := range reports {
+			fmt.Println(report)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, report := range reports {
+		if !report.Consistent() && report.Repaired == 0 {
+			return 2
+		}
+	}
+	return 0
+}
+
+// parseDay converts a YYYY-MM-DD day (UTC) plus an offset to unix seconds; empty is 0 (open)
+func parseDay(day string, offset time.Duration) (int64, error) {
+	if day == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return 0, err
+	}
+	return t.Add(offset).Unix(), nil
+}
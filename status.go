@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+	"internship-project/internal/status"
+	"internship-project/pkg/database"
+)
+
+// runStatus implements the "status" command: it prints the row counts of the tables, the newest
+// items, the last job runs, the reachability of the event transport, the Redis key sizes and the
+// search index document counts in one view. It returns the exit status: 0 when every section
+// was collected and 1 when a dependency is unreachable.
+func runStatus(args []string) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 5*time.Second, "time allowed to each section")
+	scanLimit := flags.Int("redis-scan-limit", 10000, "Redis keys scanned to size the key prefixes")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	collector := &status.Collector{
+		Search:         search.NewClient(),
+		RedisScanLimit: *scanLimit,
+		Timeout:        *timeout,
+	}
+	if err := database.Connect(database.GetDefaultConfig()); err != nil {
+		collector.DatabaseErr = err
+	} else {
+		collector.Tables = postgres.NewMaintenanceRepository()
+		collector.Stats = postgres.NewStatsRepository()
+		collector.JobRuns = postgres.NewJobRunRepository()
+	}
+	defer database.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report := collector.Collect(ctx)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else if err := report.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestArchiverSave(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := strings.TrimPrefix(r.URL.Path, "/save/")
+		switch target {
+		case "https://example.com/redirected":
+			w.Header().Set("Location", "/web/20240101000000/"+target)
+			w.WriteHeader(http.StatusFound)
+		case "https://example.com/located":
+			w.Header().Set("Content-Location", "/web/20240102000000/"+target)
+		case "https://example.com/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "https://example.com/blocked":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("ARCHIVE_SAVE_API", server.URL+"/save/")
+
+	archiver := services.NewArchiver()
+	ctx := context.Background()
+
+	snapshot, err := archiver.Save(ctx, "https://example.com/redirected")
+	if err != nil || snapshot != server.URL+"/web/20240101000000/https://example.com/redirected" {
+		t.Errorf("Unexpected snapshot from a redirect %q (%v)", snapshot, err)
+	}
+	snapshot, err = archiver.Save(ctx, "https://example.com/located")
+	if err != nil || snapshot != server.URL+"/web/20240102000000/https://example.com/located" {
+		t.Errorf("Unexpected snapshot from Content-Location %q (%v)", snapshot, err)
+	}
+	if _, err := archiver.Save(ctx, "https://example.com/busy"); !errors.Is(err, services.ErrArchiveRateLimited) {
+		t.Errorf("Expected a rate limit error, got %v", err)
+	}
+	if _, err := archiver.Save(ctx, "https://example.com/blocked"); err == nil {
+		t.Error("Expected an error for a refused save")
+	}
+	if _, err := archiver.Save(ctx, "https://example.com/unknown"); err == nil {
+		t.Error("Expected an error for a response without a snapshot location")
+	}
+}
+
+func TestStoriesToArchive(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+	now := time.Now().Unix()
+	id := 910000000 + rand.Intn(1000000)
+
+	batch := []*models.Story{
+		{ID: id, Type: "story", Title: "Popular", URL: "https://example.com/popular", Score: 900000, Created_At: now, Comments_ids: []int{}},
+		{ID: id + 1, Type: "story", Title: "Quiet", URL: "https://example.com/quiet", Score: 1, Created_At: now, Comments_ids: []int{}},
+		{ID: id + 2, Type: "story", Title: "Ask", Score: 900000, Created_At: now, Comments_ids: []int{}},
+	}
+	if err := repo.CreateBatchWithExistingIDs(ctx, batch); err != nil {
+		t.Fatalf("Failed to create stories: %v", err)
+	}
+	for _, story := range batch {
+		defer repo.Delete(ctx, story.ID)
+	}
+
+	pending := func(requestedBefore int64) map[int]bool {
+		archives, err := repo.GetStoriesToArchive(ctx, 800000, requestedBefore, 1000)
+		if err != nil {
+			t.Fatalf("Failed to get stories to archive: %v", err)
+		}
+		ids := make(map[int]bool)
+		for _, archive := range archives {
+			ids[archive.StoryID] = true
+		}
+		return ids
+	}
+	if ids := pending(now); !ids[id] || ids[id+1] || ids[id+2] {
+		t.Fatalf("Expected only story %d to archive, got %v", id, ids)
+	}
+
+	// A failed save waits for the retry delay
+	if err := repo.UpdateArchives(ctx, []*models.StoryArchive{{StoryID: id, RequestedAt: now}}); err != nil {
+		t.Fatalf("Failed to record a failed save: %v", err)
+	}
+	if pending(now)[id] || !pending(now + 1)[id] {
+		t.Error("Expected the failed story to be retried after its last request only")
+	}
+
+	snapshot := "https://web.archive.org/web/20240101000000/https://example.com/popular"
+	if err := repo.UpdateArchives(ctx, []*models.StoryArchive{{StoryID: id, RequestedAt: now, ArchiveURL: snapshot}}); err != nil {
+		t.Fatalf("Failed to record a snapshot: %v", err)
+	}
+	if pending(now + 1)[id] {
+		t.Error("Expected an archived story not to be submitted again")
+	}
+}
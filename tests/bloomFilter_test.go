@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"internship-project/internal/bloom"
+	"internship-project/internal/models"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestBloomFilterHasNoFalseNegatives(t *testing.T) {
+	filter := bloom.New(10000, 0.01)
+	for id := 1; id <= 10000; id++ {
+		filter.Add(id)
+	}
+	for id := 1; id <= 10000; id++ {
+		if !filter.MayContain(id) {
+			t.Fatalf("Expected added ID %d to be found", id)
+		}
+	}
+
+	falsePositives := 0
+	for id := 1_000_000; id < 1_100_000; id++ {
+		if filter.MayContain(id) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 100000; rate > 0.02 {
+		t.Errorf("Expected a false positive rate near 1%%, got %.2f%%", rate*100)
+	}
+}
+
+func TestKnownItemsRebuild(t *testing.T) {
+	t.Setenv("KNOWN_ITEMS_CAPACITY", "1000")
+	t.Setenv("KNOWN_ITEMS_SCAN_BATCH", "3")
+	known := bloom.NewKnown()
+
+	if !known.MayContain(42) || known.Ready() {
+		t.Fatal("Expected every ID to be possible before the filter is built")
+	}
+
+	stored := []int{-89, 3, 5, 8, 13, 21, 34, 55}
+	var pages [][2]int
+	scan := func(ctx context.Context, afterID, limit int) ([]int, error) {
+		pages = append(pages, [2]int{afterID, limit})
+		var ids []int
+		for _, id := range stored {
+			if id > afterID && len(ids) < limit {
+				ids = append(ids, id)
+			}
+		}
+		if afterID == math.MinInt {
+			known.Add(99) // inserted while the filter is being built
+		}
+		return ids, nil
+	}
+	if _, err := known.Rebuild(context.Background(), scan); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	if len(pages) != 3 || pages[0][0] != math.MinInt || pages[1][0] != 5 || pages[2][0] != 21 {
+		t.Errorf("Unexpected scan pages %v", pages)
+	}
+
+	for _, id := range append(stored, 99) {
+		if !known.MayContain(id) {
+			t.Errorf("Expected known ID %d to be found", id)
+		}
+	}
+	if known.MayContain(4) && known.MayContain(6) && known.MayContain(7) {
+		t.Error("Expected unknown IDs to be filtered out")
+	}
+	known.Add(4)
+	if !known.MayContain(4) {
+		t.Error("Expected an added ID to be found")
+	}
+
+	t.Setenv("KNOWN_ITEMS_ENABLED", "false")
+	if !known.MayContain(6) {
+		t.Error("Expected every ID to be possible with the filter disabled")
+	}
+}
+
+func TestKnownItemsRebuildErrorKeepsFilter(t *testing.T) {
+	known := bloom.NewKnown()
+	failing := func(ctx context.Context, afterID, limit int) ([]int, error) {
+		return nil, errors.New("database down")
+	}
+	if _, err := known.Rebuild(context.Background(), failing); err == nil {
+		t.Fatal("Expected the scan error")
+	}
+	if known.Ready() || !known.MayContain(7) {
+		t.Error("Expected a failed rebuild to leave the filter unbuilt")
+	}
+}
+
+func TestParseItemKey(t *testing.T) {
+	kind, id, ok := redis.ParseItemKey(redis.ItemKey("pollopt", 126809))
+	if !ok || kind != "pollopt" || id != 126809 {
+		t.Errorf("Unexpected parse %q %d %v", kind, id, ok)
+	}
+	for _, key := range []string{"ids", "item:story", "item:story:abc", "user:pg"} {
+		if _, _, ok := redis.ParseItemKey(key); ok {
+			t.Errorf("Expected %q not to parse", key)
+		}
+	}
+}
+
+func TestScanItemIDs(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewItemRepository()
+	first, err := repo.ScanIDs(ctx, math.MinInt, 5)
+	if err != nil {
+		t.Fatalf("Failed to scan IDs: %v", err)
+	}
+	if len(first) == 0 {
+		t.Skip("No stored items to scan")
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i] <= first[i-1] {
+			t.Fatalf("Expected ascending IDs, got %v", first)
+		}
+	}
+
+	next, err := repo.ScanIDs(ctx, first[len(first)-1], 5)
+	if err != nil {
+		t.Fatalf("Failed to scan the next page: %v", err)
+	}
+	if len(next) > 0 && next[0] <= first[len(first)-1] {
+		t.Errorf("Expected the next page after %d, got %v", first[len(first)-1], next)
+	}
+}
+
+func TestScanItemIDsNegative(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	id := services.FeedItemID("https://example.com/posts/scanned")
+	defer stories.Delete(ctx, id)
+	story := &models.Story{ID: id, Type: "story", Title: "Scanned", Comments_ids: []int{}, Source: services.SourceRSS}
+	if err := stories.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to store story %d: %v", id, err)
+	}
+
+	ids, err := postgres.NewItemRepository().ScanIDs(ctx, id-1, 1)
+	if err != nil {
+		t.Fatalf("Failed to scan IDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != id {
+		t.Errorf("Expected negative ID %d scanned, got %v", id, ids)
+	}
+}
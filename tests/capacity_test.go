@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"internship-project/internal/capacity"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+const day = 24 * 60 * 60
+
+func TestCapacityBuildProjectsThresholds(t *testing.T) {
+	today := capacity.Day(time.Now())
+	current := []*models.StorageSnapshot{
+		{Day: today, TableBytes: 1000},
+		{Table: "stories", Day: today, Rows: 300, TableBytes: 400, IndexBytes: 100},
+		{Table: "item_change_log", Day: today, Rows: 50, TableBytes: 100},
+		{Table: "users", Day: today, Rows: 10, TableBytes: 10},
+	}
+	history := []*models.StorageSnapshot{
+		{Day: today - 4*day, TableBytes: 600},
+		{Table: "stories", Day: today - 4*day, Rows: 100, TableBytes: 200, IndexBytes: 60},
+		{Table: "stories", Day: today - 2*day, Rows: 200, TableBytes: 300, IndexBytes: 80},
+		{Table: "item_change_log", Day: today - 4*day, Rows: 10, TableBytes: 20},
+		{Table: "users", Day: today, Rows: 10, TableBytes: 10},
+	}
+	retentions := map[string]time.Duration{"item_change_log": 7 * 24 * time.Hour}
+
+	report := capacity.Build(current, history, retentions, 4000, []float64{0.25, 0.5, 1})
+	if report.DatabaseBytes != 1000 || report.GrowthBytesPerDay != 100 {
+		t.Fatalf("Expected 1000 bytes growing by 100 a day, got %+v", report)
+	}
+	if len(report.Tables) != 3 {
+		t.Fatalf("Expected the 3 tables without the database, got %+v", report.Tables)
+	}
+	stories, log, users := report.Tables[0], report.Tables[1], report.Tables[2]
+	if stories.MeasuredDays != 4 || stories.GrowthRowsPerDay != 50 || stories.GrowthBytesPerDay != 60 {
+		t.Errorf("Expected stories growing by 50 rows and 60 bytes a day over 4 days, got %+v", stories)
+	}
+	if log.RetentionDays != 7 || log.GrowthBytesPerDay != 20 {
+		t.Errorf("Expected the change log growing by 20 bytes a day with a 7 day retention, got %+v", log)
+	}
+	if users.MeasuredDays != 0 || users.GrowthBytesPerDay != 0 {
+		t.Errorf("Expected no growth from a snapshot of today, got %+v", users)
+	}
+
+	// 80 bytes a day keep growing; the change log adds 20 a day until it holds 7 days (140 bytes),
+	// 40 more than now: 1000 + 80*12 + 40 reaches half of the disk
+	for i, want := range []int{0, 12, 37} {
+		if got := report.Thresholds[i].DaysUntil; got == nil || *got != want {
+			t.Errorf("Threshold %v: expected %d days, got %v", report.Thresholds[i].Fraction, want, got)
+		}
+	}
+	if report := capacity.Build(current, history, retentions, 4000000, []float64{1}); report.Thresholds[0].DaysUntil != nil {
+		t.Errorf("Expected no threshold reached within ten years, got %d days", *report.Thresholds[0].DaysUntil)
+	}
+
+	if report := capacity.Build(current, nil, retentions, 0, []float64{0.5}); len(report.Thresholds) != 0 || report.GrowthBytesPerDay != 0 {
+		t.Errorf("Expected no growth nor thresholds without history and disk size, got %+v", report)
+	}
+}
+
+func TestCapacityRetentions(t *testing.T) {
+	t.Setenv("CHANGES_RETENTION", "48h")
+	t.Setenv("ITEM_RETENTION_ENABLED", "true")
+	tenants := []*models.Tenant{{Name: "a", Retention_Days: 30}, {Name: "b", Retention_Days: 90}}
+
+	retentions := capacity.Retentions(tenants)
+	if retentions["item_change_log"] != 48*time.Hour || retentions["stories"] != 90*24*time.Hour {
+		t.Errorf("Expected the change log and longest tenant retentions, got %v", retentions)
+	}
+	tenants = append(tenants, &models.Tenant{Name: "c"})
+	if _, ok := capacity.Retentions(tenants)["stories"]; ok {
+		t.Error("Expected no item retention while a tenant keeps its items forever")
+	}
+
+	t.Setenv("STORAGE_THRESHOLDS", "0.7, 2, x, 0.95")
+	if got := capacity.Thresholds(); len(got) != 2 || got[0] != 0.7 || got[1] != 0.95 {
+		t.Errorf("Expected the valid thresholds, got %v", got)
+	}
+}
+
+func TestStorageSnapshots(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStorageRepository()
+	now := time.Now()
+	snapshots, err := capacity.Measure(ctx, postgres.NewMaintenanceRepository(), repo, now)
+	if err != nil {
+		t.Fatalf("Failed to measure storage: %v", err)
+	}
+	if len(snapshots) < 2 || snapshots[0].Table != "" || snapshots[0].TableBytes <= 0 {
+		t.Fatalf("Expected the database then its tables, got %+v", snapshots)
+	}
+	if err := repo.SaveSnapshots(ctx, snapshots); err != nil {
+		t.Fatalf("Failed to save snapshots: %v", err)
+	}
+	snapshots[0].TableBytes++
+	if err := repo.SaveSnapshots(ctx, snapshots[:1]); err != nil {
+		t.Fatalf("Failed to save snapshots again: %v", err)
+	}
+
+	saved, err := repo.GetSnapshots(ctx, capacity.Day(now))
+	if err != nil {
+		t.Fatalf("Failed to get snapshots: %v", err)
+	}
+	if len(saved) != len(snapshots) || saved[0].TableBytes != snapshots[0].TableBytes {
+		t.Errorf("Expected one snapshot per table, the database one replaced, got %+v", saved)
+	}
+	if pruned, err := repo.PruneSnapshots(ctx, capacity.Day(now)+day); err != nil || pruned != int64(len(snapshots)) {
+		t.Errorf("Expected every snapshot pruned, got %d (%v)", pruned, err)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/changefeed"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/transport"
+)
+
+// fakeChangeStore serves fixed story changes in place of the item tables; the listener does not
+// read the change log
+type fakeChangeStore struct {
+	repository.ChangeRepository
+
+	mu      sync.Mutex
+	changes []models.ItemChange
+}
+
+func (f *fakeChangeStore) GetUpdatedSince(ctx context.Context, kind string, since models.ItemChange, limit int) ([]models.ItemChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var changes []models.ItemChange
+	for _, change := range f.changes {
+		if change.Kind == kind && change.After(since) && len(changes) < limit {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func TestItemChangeOrder(t *testing.T) {
+	a := models.ItemChange{ID: 5, Updated_At: 100}
+	if !(models.ItemChange{ID: 1, Updated_At: 101}).After(a) {
+		t.Error("Expected a later change to come after")
+	}
+	if !(models.ItemChange{ID: 6, Updated_At: 100}).After(a) {
+		t.Error("Expected a higher ID to break the tie")
+	}
+	if a.After(a) {
+		t.Error("Expected a change not to come after itself")
+	}
+}
+
+func TestChangeListenerPollsWithoutConnection(t *testing.T) {
+	t.Setenv("CHANGE_LISTENER_POLL_INTERVAL", "10ms")
+	t.Setenv("CHANGE_LISTENER_BATCH_SIZE", "2")
+	t.Setenv("CHANGE_LISTENER_MIN_RECONNECT", "10ms")
+	t.Setenv("CHANGE_LISTENER_MAX_RECONNECT", "20ms")
+
+	// changes are stamped after the listener starts, older ones are not its job
+	later := time.Now().Add(time.Hour).UnixMilli()
+	store := &fakeChangeStore{changes: []models.ItemChange{
+		{Kind: "story", ID: 1, Updated_At: later},
+		{Kind: "story", ID: 2, Updated_At: later},
+		{Kind: "story", ID: 3, Updated_At: later + 1},
+		{Kind: "comment", ID: 4, Updated_At: later},
+		{Kind: "story", ID: 9, Updated_At: time.Now().Add(-time.Hour).UnixMilli()},
+	}}
+	publisher := &recordingPublisher{published: map[string][]string{}}
+
+	// nothing listens on port 1, so the listener has to fall back to polling
+	listener := changefeed.NewListener("host=localhost port=1 sslmode=disable connect_timeout=1", store, publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		listener.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) &&
+		(len(publisher.values(transport.ItemTopics["story"])) < 3 || len(publisher.values(transport.ItemTopics["comment"])) < 1) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// further polls must not publish the same changes again
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := strings.Join(publisher.values(transport.ItemTopics["story"]), ","); got != "1,2,3" {
+		t.Errorf("Expected stories 1,2,3 published once, got %q", got)
+	}
+	if got := strings.Join(publisher.values(transport.ItemTopics["comment"]), ","); got != "4" {
+		t.Errorf("Expected comment 4 published once, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestChangeLogNumbersChangesInOrder(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewChangeRepository()
+	if _, err := repo.SequenceChanges(ctx, 100000); err != nil {
+		t.Fatalf("Failed to number the earlier changes: %v", err)
+	}
+	_, since, err := repo.GetChangeLogBounds(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the change log bounds: %v", err)
+	}
+
+	id := 900000000 + rand.Intn(1000000)
+	comment := &models.Comment{
+		ID:         id,
+		Type:       "comment",
+		Text:       "Change log test",
+		Author:     "testuser",
+		Created_At: time.Now().Unix(),
+		Parent:     1,
+		Replies:    []int{},
+	}
+	comments := postgres.NewCommentRepository()
+	if err := comments.CreateBatchWithExistingIDs(ctx, []*models.Comment{comment}); err != nil {
+		t.Fatalf("Failed to create comment: %v", err)
+	}
+	defer comments.Delete(ctx, id)
+	comment.Text = "Change log test, edited"
+	if err := comments.Update(ctx, comment); err != nil {
+		t.Fatalf("Failed to update comment: %v", err)
+	}
+
+	if changes, err := repo.GetChangesSince(ctx, models.DefaultTenant, since, 10); err != nil || len(changes) != 0 {
+		t.Fatalf("Expected no numbered change before sequencing, got %v (%v)", changes, err)
+	}
+	if numbered, err := repo.SequenceChanges(ctx, 100); err != nil || numbered != 2 {
+		t.Fatalf("Expected 2 changes numbered, got %d (%v)", numbered, err)
+	}
+
+	changes, err := repo.GetChangesSince(ctx, models.DefaultTenant, since, 10)
+	if err != nil {
+		t.Fatalf("Failed to get changes: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Seq != since+1 || changes[1].Seq != since+2 {
+		t.Fatalf("Expected changes %d and %d, got %v", since+1, since+2, changes)
+	}
+	if changes[0].ID != id || changes[0].Kind != "comment" || changes[0].Operation != "insert" || changes[1].Operation != "update" {
+		t.Errorf("Expected the insert then the update of comment %d, got %+v and %+v", id, changes[0], changes[1])
+	}
+	if later, err := repo.GetChangesSince(ctx, models.DefaultTenant, since+1, 10); err != nil || len(later) != 1 {
+		t.Errorf("Expected the update alone after %d, got %v (%v)", since+1, later, err)
+	}
+
+	if _, err := repo.PruneChanges(ctx, time.Now().Add(time.Minute).UnixMilli()); err != nil {
+		t.Fatalf("Failed to prune changes: %v", err)
+	}
+	first, last, err := repo.GetChangeLogBounds(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the change log bounds: %v", err)
+	}
+	if last != since+2 || first != last+1 {
+		t.Errorf("Expected an empty log after %d, got bounds %d-%d", since+2, first, last)
+	}
+}
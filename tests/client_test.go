@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"internship-project/pkg/client"
+)
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "tenant-key" {
+			t.Errorf("Expected the API key to be sent, got %q", r.Header.Get("X-API-Key"))
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "type": "story", "title": "Retried"})
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, client.WithAPIKey("tenant-key"), client.WithRetries(3, time.Millisecond, 5*time.Millisecond))
+	story, err := c.GetStory(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("Failed to get story: %v", err)
+	}
+	if story.ID != 42 || story.Title != "Retried" || calls.Load() != 3 {
+		t.Errorf("Expected story 42 after 3 calls, got %+v after %d", story, calls.Load())
+	}
+}
+
+func TestClientDecodesErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"code": "not_found", "message": "story not found", "request_id": "req-1"})
+	}))
+	defer server.Close()
+
+	_, err := client.New(server.URL).GetStory(context.Background(), 1)
+	if !client.IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	apiErr := err.(*client.Error)
+	if apiErr.Code != client.CodeNotFound || apiErr.RequestID != "req-1" || calls.Load() != 1 {
+		t.Errorf("Expected one not_found call of req-1, got %+v after %d calls", apiErr, calls.Load())
+	}
+}
+
+func TestClientIteratesPages(t *testing.T) {
+	const total = 7
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var items []map[string]interface{}
+		for id := offset + 1; id <= min(offset+limit, total); id++ {
+			items = append(items, map[string]interface{}{"id": id, "type": "comment"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": items, "total": total, "limit": limit, "has_more": offset+len(items) < total,
+		})
+	}))
+	defer server.Close()
+
+	var ids []int
+	for comment, err := range client.New(server.URL).Comments(context.Background(), client.ListOptions{Limit: 3}) {
+		if err != nil {
+			t.Fatalf("Failed to iterate comments: %v", err)
+		}
+		ids = append(ids, comment.ID)
+	}
+	if len(ids) != total || ids[0] != 1 || ids[total-1] != total {
+		t.Errorf("Expected comments 1 to %d, got %v", total, ids)
+	}
+}
+
+func TestClientBatchGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/items:batchGet" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req struct {
+			Items []client.ItemRef `json:"items"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"id": req.Items[0].ID, "type": "story", "item": map[string]interface{}{"id": req.Items[0].ID, "title": "Found"}},
+			},
+			"missing": []int{req.Items[1].ID},
+		})
+	}))
+	defer server.Close()
+
+	result, err := client.New(server.URL).BatchGet(context.Background(), []client.ItemRef{{ID: 1, Type: "story"}, {ID: 2}})
+	if err != nil {
+		t.Fatalf("Failed to batch get: %v", err)
+	}
+	if len(result.Items) != 1 || len(result.Missing) != 1 || result.Missing[0] != 2 {
+		t.Fatalf("Expected item 1 found and 2 missing, got %+v", result)
+	}
+	var story client.Story
+	if err := result.Items[0].Decode(&story); err != nil || story.Title != "Found" {
+		t.Errorf("Expected the story to decode, got %+v (%v)", story, err)
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+
+	"internship-project/internal/clock"
+)
+
+func TestClockDefaultCanBeReplaced(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	restore := clock.Set(fake)
+	if got := clock.Default().Now(); !got.Equal(start) {
+		t.Errorf("Expected the fake time %v, got %v", start, got)
+	}
+	fake.Advance(36 * time.Hour)
+	if got := clock.Default().Since(start); got != 36*time.Hour {
+		t.Errorf("Expected 36h to have passed, got %v", got)
+	}
+
+	restore()
+	if got := clock.Default().Now(); time.Since(got) > time.Minute {
+		t.Errorf("Expected the wall clock back, got %v", got)
+	}
+}
+
+func TestSchedulerRunsOnFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	scheduler, err := gocron.NewScheduler(gocron.WithClock(fake))
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	defer scheduler.Shutdown()
+
+	ran := make(chan time.Time, 1)
+	if _, err := scheduler.NewJob(gocron.DurationJob(time.Hour), gocron.NewTask(func() { ran <- fake.Now() })); err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	scheduler.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := fake.BlockUntilContext(ctx, 1); err != nil {
+		t.Fatalf("Expected the scheduler to wait on the clock: %v", err)
+	}
+	select {
+	case <-ran:
+		t.Fatal("Expected no run before the interval passed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fake.Advance(time.Hour)
+	select {
+	case at := <-ran:
+		if want := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC); !at.Equal(want) {
+			t.Errorf("Expected the run at %v, got %v", want, at)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the job to run once the fake clock passed its interval")
+	}
+}
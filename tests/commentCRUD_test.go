@@ -2,7 +2,10 @@ package tests
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
@@ -290,3 +293,175 @@ func TestCommentDelete(t *testing.T) {
 
 	t.Logf("Successfully deleted comment ID: %d", tempComment.ID)
 }
+
+func TestCommentGetTextHashes(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewCommentRepository()
+	defer repo.DeleteByAuthor(ctx, "hashuser")
+
+	now := time.Now().Unix()
+	recent := &models.Comment{ID: 8891, Type: "comment", Text: "Edited <i>comment</i> – naïve", Author: "hashuser", Parent: 1001, Created_At: now}
+	old := &models.Comment{ID: 8892, Type: "comment", Text: "Old comment", Author: "hashuser", Parent: 1001, Created_At: now - 7200}
+	if _, err := repo.UpsertBatch(ctx, []*models.Comment{recent, old}); err != nil {
+		t.Fatalf("Failed to save comments: %v", err)
+	}
+
+	hashes, err := repo.GetTextHashes(ctx, now-3600, 100)
+	if err != nil {
+		t.Fatalf("Failed to get text hashes: %v", err)
+	}
+	sum := md5.Sum([]byte(recent.Text))
+	if hashes[recent.ID] != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the stored hash to match the Go digest, got %q", hashes[recent.ID])
+	}
+	if _, ok := hashes[old.ID]; ok {
+		t.Error("Expected comments older than the cutoff to be skipped")
+	}
+}
+
+func TestCommentGetThreadFromAncestorPaths(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewCommentRepository()
+	defer repo.DeleteByAuthor(ctx, "threaduser")
+
+	now := time.Now().Unix()
+	comment := func(id, parent int) *models.Comment {
+		return &models.Comment{ID: id, Type: "comment", Text: "Thread comment", Author: "threaduser", Parent: parent, Created_At: now + int64(id)}
+	}
+	// Replies stored before their parents get their paths repaired when the parents arrive
+	if _, err := repo.UpsertBatch(ctx, []*models.Comment{comment(8903, 8902), comment(8904, 8903)}); err != nil {
+		t.Fatalf("Failed to save replies: %v", err)
+	}
+	if _, err := repo.UpsertBatch(ctx, []*models.Comment{comment(8901, 7001), comment(8902, 8901), comment(8905, 7001)}); err != nil {
+		t.Fatalf("Failed to save parents: %v", err)
+	}
+
+	thread, err := repo.GetThread(ctx, 7001, 10)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	var ids []int
+	for _, c := range thread {
+		ids = append(ids, c.ID)
+	}
+	if want := []int{8901, 8905, 8902, 8903, 8904}; !slices.Equal(ids, want) {
+		t.Errorf("Expected thread %v, got %v", want, ids)
+	}
+
+	storyRepo := postgres.NewStoryRepository()
+	defer storyRepo.Delete(ctx, 7001)
+	story := &models.Story{ID: 7001, Type: "story", Title: "Thread root", Author: "threaduser", Created_At: now, Comments_ids: []int{8905, 8901}}
+	if _, err := storyRepo.UpsertBatch(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to save story: %v", err)
+	}
+	parent, err := repo.GetByID(ctx, 8902)
+	if err != nil {
+		t.Fatalf("Failed to get comment: %v", err)
+	}
+	parent.Replies = []int{8903}
+	if _, err := repo.UpsertBatch(ctx, []*models.Comment{parent}); err != nil {
+		t.Fatalf("Failed to save replies of 8902: %v", err)
+	}
+	thread, err = repo.GetThread(ctx, 7001, 10)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	positions := make(map[int][2]int)
+	ids = ids[:0]
+	for _, c := range models.OrderThread(7001, thread) {
+		ids = append(ids, c.ID)
+		positions[c.ID] = [2]int{c.Depth, c.Rank}
+	}
+	if want := []int{8905, 8901, 8902, 8903, 8904}; !slices.Equal(ids, want) {
+		t.Errorf("Expected ranked thread %v, got %v", want, ids)
+	}
+	if positions[8905] != [2]int{1, 1} || positions[8901] != [2]int{1, 2} || positions[8903] != [2]int{3, 1} || positions[8904] != [2]int{4, 0} {
+		t.Errorf("Unexpected depths and ranks %v", positions)
+	}
+
+	subtree, err := repo.GetThread(ctx, 8902, 1)
+	if err != nil {
+		t.Fatalf("Failed to get subtree: %v", err)
+	}
+	if len(subtree) != 1 || subtree[0].ID != 8903 {
+		t.Errorf("Expected only the direct reply of 8902, got %+v", subtree)
+	}
+}
+
+func TestCommentArchiveKeepsReadsTransparent(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewCommentRepository()
+	archive := postgres.NewCommentArchiveRepository()
+	defer repo.DeleteByAuthor(ctx, "colduser")
+
+	old := time.Now().AddDate(-2, 0, 0).Unix()
+	comments := []*models.Comment{
+		{ID: 8951, Type: "comment", Text: "Old root reply", Author: "colduser", Parent: 7051, Replies: []int{8952}, Created_At: old},
+		{ID: 8952, Type: "comment", Text: "Old nested reply", Author: "colduser", Parent: 8951, Created_At: old + 40*24*3600},
+		{ID: 8953, Type: "comment", Text: "Recent reply", Author: "colduser", Parent: 8951, Created_At: time.Now().Unix()},
+	}
+	if _, err := repo.UpsertBatch(ctx, comments); err != nil {
+		t.Fatalf("Failed to save comments: %v", err)
+	}
+
+	cutoff := time.Now().AddDate(-1, 0, 0).Unix()
+	moved, err := archive.Archive(ctx, cutoff, 1)
+	if err != nil || moved != 1 {
+		t.Fatalf("Expected one comment archived by the first batch, got %d: %v", moved, err)
+	}
+	if moved, err = archive.Archive(ctx, cutoff, 10); err != nil || moved != 1 {
+		t.Fatalf("Expected the second old comment archived, got %d: %v", moved, err)
+	}
+	if moved, err = archive.Archive(ctx, cutoff, 10); err != nil || moved != 0 {
+		t.Fatalf("Expected nothing left to archive, got %d: %v", moved, err)
+	}
+
+	comment, err := repo.GetByID(ctx, 8952)
+	if err != nil || comment.Depth != 2 {
+		t.Fatalf("Expected the archived comment with its depth, got %+v: %v", comment, err)
+	}
+	thread, err := repo.GetThread(ctx, 7051, 10)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	var ids []int
+	for _, c := range thread {
+		ids = append(ids, c.ID)
+	}
+	if want := []int{8951, 8952, 8953}; !slices.Equal(ids, want) {
+		t.Errorf("Expected the thread across hot and cold comments %v, got %v", want, ids)
+	}
+	byAuthor, err := repo.GetByAuthor(ctx, "colduser")
+	if err != nil || len(byAuthor) != 3 {
+		t.Errorf("Expected 3 comments by author, got %d: %v", len(byAuthor), err)
+	}
+
+	// A refetched archived comment is updated in place, not copied back to the hot table
+	comments[0].Text = "Old root reply, edited"
+	counts, err := repo.UpsertBatch(ctx, comments[:2])
+	if err != nil || counts.Updated != 1 || counts.Skipped != 1 || counts.Inserted != 0 {
+		t.Fatalf("Expected one archived comment updated and one skipped, got %+v: %v", counts, err)
+	}
+	if comment, err := repo.GetByID(ctx, 8951); err != nil || comment.Text != "Old root reply, edited" {
+		t.Errorf("Expected the archived comment updated, got %+v: %v", comment, err)
+	}
+	if byAuthor, _ := repo.GetByAuthor(ctx, "colduser"); len(byAuthor) != 3 {
+		t.Errorf("Expected no duplicate after the update, got %d comments", len(byAuthor))
+	}
+
+	if err := repo.Delete(ctx, 8952); err != nil {
+		t.Fatalf("Failed to delete archived comment: %v", err)
+	}
+	if exists, err := repo.Exists(ctx, 8952); err != nil || exists {
+		t.Errorf("Expected the archived comment deleted, got %v: %v", exists, err)
+	}
+}
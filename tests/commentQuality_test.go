@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestRankCommentsPerParent(t *testing.T) {
+	weights := models.CommentQualityWeights{Replies: 1, Depth: 0.2, Karma: 0.5, Length: 0.3}
+	signals := []*models.CommentSignals{
+		{ID: 10, Parent: 1, Replies: 0, Depth: 1, Length: 20, Karma: 10},
+		{ID: 11, Parent: 1, Replies: 12, Depth: 1, Length: 400, Karma: 5000},
+		{ID: 12, Parent: 1, Replies: 0, Depth: 1, Length: 20, Karma: 10}, // ties with 10
+		{ID: 20, Parent: 11, Replies: 3, Depth: 2, Length: 100, Karma: 0},
+	}
+
+	ranks := make(map[int]int)
+	for _, q := range models.RankComments(signals, weights) {
+		ranks[q.ID] = q.Rank
+	}
+	if ranks[11] != 1 || ranks[10] != 2 || ranks[12] != 3 || ranks[20] != 1 {
+		t.Errorf("Unexpected ranks %v", ranks)
+	}
+
+	shallow := weights.Score(models.CommentSignals{Depth: 1, Length: 50})
+	deep := weights.Score(models.CommentSignals{Depth: 5, Length: 50})
+	if deep >= shallow {
+		t.Errorf("Expected deeper comments to score lower, got %v >= %v", deep, shallow)
+	}
+}
+
+func TestSortByQualityOrdersSiblings(t *testing.T) {
+	// as returned by GetThread: level by level, siblings in HN rank order
+	comments := []*models.Comment{
+		{ID: 3, Parent: 1, Rank: 1, Quality_Rank: 2},
+		{ID: 2, Parent: 1, Rank: 2, Quality_Rank: 1},
+		{ID: 8, Parent: 1, Rank: 3}, // not ranked yet
+		{ID: 5, Parent: 2, Rank: 1, Quality_Rank: 2},
+		{ID: 4, Parent: 2, Rank: 2, Quality_Rank: 1},
+	}
+
+	models.SortByQuality(comments)
+	var ids []int
+	for _, comment := range models.OrderThread(1, comments) {
+		ids = append(ids, comment.ID)
+	}
+	if want := []int{2, 4, 5, 3, 8}; !slices.Equal(ids, want) {
+		t.Errorf("Expected best thread order %v, got %v", want, ids)
+	}
+}
+
+func TestCommentQualityRoundTrip(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewCommentRepository()
+	defer repo.DeleteByAuthor(ctx, "qualityuser")
+
+	now := time.Now().Unix()
+	batch := []*models.Comment{
+		{ID: 8951, Type: "comment", Text: "Short", Author: "qualityuser", Parent: 7101, Created_At: now},
+		{ID: 8952, Type: "comment", Text: strings.Repeat("A thoughtful answer. ", 20), Author: "qualityuser", Parent: 7101, Created_At: now, Replies: []int{8953}},
+		{ID: 8953, Type: "comment", Text: "Reply", Author: "qualityuser", Parent: 8952, Created_At: now},
+	}
+	if _, err := repo.UpsertBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to save comments: %v", err)
+	}
+
+	signals, err := repo.GetQualitySignals(ctx, now-60)
+	if err != nil {
+		t.Fatalf("Failed to get quality signals: %v", err)
+	}
+	var ours []*models.CommentSignals
+	for _, s := range signals {
+		if s.ID >= 8951 && s.ID <= 8953 {
+			ours = append(ours, s)
+		}
+	}
+	if len(ours) != 3 {
+		t.Fatalf("Expected signals for the 3 comments, got %d", len(ours))
+	}
+
+	weights := models.CommentQualityWeights{Replies: 1, Depth: 0.2, Karma: 0.5, Length: 0.3}
+	if err := repo.UpdateQuality(ctx, models.RankComments(ours, weights)); err != nil {
+		t.Fatalf("Failed to save quality: %v", err)
+	}
+
+	thread, err := repo.GetThread(ctx, 7101, 10)
+	if err != nil {
+		t.Fatalf("Failed to get thread: %v", err)
+	}
+	ranks := make(map[int]int)
+	for _, c := range thread {
+		if c.Quality_Rank > 0 && c.Quality <= 0 {
+			t.Errorf("Expected a quality score for comment %d", c.ID)
+		}
+		ranks[c.ID] = c.Quality_Rank
+	}
+	if ranks[8952] != 1 || ranks[8951] != 2 || ranks[8953] != 1 {
+		t.Errorf("Unexpected quality ranks %v", ranks)
+	}
+}
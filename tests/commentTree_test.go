@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/services"
+)
+
+// fakeCommentFetch serves the comments of replies (comment ID -> reply IDs) and records the
+// batches requested; the IDs in failing fail to fetch
+func fakeCommentFetch(replies map[int][]int, failing map[int]bool, batches *[][]int) services.CommentFetchFunc {
+	return func(ctx context.Context, ids []int, opts ...services.FetchOption) ([]*models.Comment, error) {
+		*batches = append(*batches, slices.Clone(ids))
+		var comments []*models.Comment
+		errs := make(map[int]error)
+		for _, id := range ids {
+			if failing[id] {
+				errs[id] = errors.New("boom")
+				continue
+			}
+			comments = append(comments, &models.Comment{ID: id, Type: "comment", Replies: replies[id]})
+		}
+		if len(errs) > 0 {
+			return comments, &services.MultiError{Attempted: len(ids), Errors: errs}
+		}
+		return comments, nil
+	}
+}
+
+func commentIDs(comments []*models.Comment) []int {
+	ids := make([]int, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids
+}
+
+func TestFetchCommentTreesLevelByLevel(t *testing.T) {
+	replies := map[int][]int{10: {12, 13}, 11: {14}, 20: {21}, 12: {15}}
+	var batches [][]int
+	roots := []services.CommentRoot{{ID: 1, Kids: []int{10, 11}}, {ID: 2, Kids: []int{20}}}
+
+	tree, err := services.FetchCommentTrees(context.Background(), fakeCommentFetch(replies, nil, &batches), roots, services.CommentTreeLimits{})
+	if err != nil {
+		t.Fatalf("Failed to fetch trees: %v", err)
+	}
+	if want := []int{10, 11, 20, 12, 13, 14, 21, 15}; !slices.Equal(commentIDs(tree.Comments), want) {
+		t.Errorf("Expected comments %v, got %v", want, commentIDs(tree.Comments))
+	}
+	if len(batches) != 3 || tree.Truncated {
+		t.Errorf("Expected one fetch per level and a complete tree, got %v (truncated %v)", batches, tree.Truncated)
+	}
+}
+
+func TestFetchCommentTreesLimits(t *testing.T) {
+	replies := map[int][]int{10: {12, 13}, 11: {14}, 12: {15}, 20: {21, 22, 23}}
+	roots := []services.CommentRoot{{ID: 1, Kids: []int{10, 11}}, {ID: 2, Kids: []int{20}}}
+
+	var batches [][]int
+	tree, err := services.FetchCommentTrees(context.Background(), fakeCommentFetch(replies, nil, &batches), roots,
+		services.CommentTreeLimits{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("Failed to fetch trees: %v", err)
+	}
+	if want := []int{10, 11, 20, 12, 13, 14, 21, 22, 23}; !slices.Equal(commentIDs(tree.Comments), want) || !tree.Truncated {
+		t.Errorf("Expected %v cut at depth 2, got %v (truncated %v)", want, commentIDs(tree.Comments), tree.Truncated)
+	}
+
+	batches = nil
+	tree, err = services.FetchCommentTrees(context.Background(), fakeCommentFetch(replies, nil, &batches), roots,
+		services.CommentTreeLimits{MaxNodes: 3})
+	if err != nil {
+		t.Fatalf("Failed to fetch trees: %v", err)
+	}
+	// each root keeps its first replies in rank order
+	if want := []int{10, 11, 20, 12, 21, 22}; !slices.Equal(commentIDs(tree.Comments), want) || !tree.Truncated {
+		t.Errorf("Expected %v with 3 comments per root, got %v (truncated %v)", want, commentIDs(tree.Comments), tree.Truncated)
+	}
+}
+
+func TestFetchCommentTreesBreaksCycles(t *testing.T) {
+	// 11 lists its ancestor 10 and the root as replies, 12 lists itself
+	replies := map[int][]int{10: {11}, 11: {10, 1, 12}, 12: {12}}
+	var batches [][]int
+	tree, err := services.FetchCommentTrees(context.Background(), fakeCommentFetch(replies, nil, &batches),
+		[]services.CommentRoot{{ID: 1, Kids: []int{10, 10}}}, services.CommentTreeLimits{})
+	if err != nil {
+		t.Fatalf("Failed to fetch tree: %v", err)
+	}
+	if want := []int{10, 11, 12}; !slices.Equal(commentIDs(tree.Comments), want) {
+		t.Errorf("Expected comments %v, got %v", want, commentIDs(tree.Comments))
+	}
+	if tree.Repeated != 4 {
+		t.Errorf("Expected 4 repeated reply IDs, got %d", tree.Repeated)
+	}
+}
+
+func TestFetchCommentTreesErrors(t *testing.T) {
+	replies := map[int][]int{10: {12}, 11: {13}}
+	var batches [][]int
+	tree, err := services.FetchCommentTrees(context.Background(), fakeCommentFetch(replies, map[int]bool{11: true}, &batches),
+		[]services.CommentRoot{{ID: 1, Kids: []int{10, 11}}}, services.CommentTreeLimits{})
+	var multiErr *services.MultiError
+	if !errors.As(err, &multiErr) || !slices.Equal(multiErr.IDs(), []int{11}) || multiErr.Attempted != 3 {
+		t.Fatalf("Expected comment 11 reported failing of 3, got %v", err)
+	}
+	if want := []int{10, 12}; !slices.Equal(commentIDs(tree.Comments), want) {
+		t.Errorf("Expected the rest of the tree %v, got %v", want, commentIDs(tree.Comments))
+	}
+
+	failing := func(ctx context.Context, ids []int, opts ...services.FetchOption) ([]*models.Comment, error) {
+		return nil, context.Canceled
+	}
+	if _, err := services.FetchCommentTrees(context.Background(), failing,
+		[]services.CommentRoot{{ID: 1, Kids: []int{10}}}, services.CommentTreeLimits{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the fetch error, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/expr"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// fakeComputedFieldStore keeps the computed fields of each item in memory
+type fakeComputedFieldStore struct {
+	fields map[string]map[string]float64
+}
+
+func (f *fakeComputedFieldStore) ReplaceFields(ctx context.Context, kind string, id int, values map[string]float64) error {
+	f.fields[kind] = values
+	return nil
+}
+
+func (f *fakeComputedFieldStore) GetFields(ctx context.Context, kind string, id int) (map[string]float64, error) {
+	return f.fields[kind], nil
+}
+
+func TestExpressionEval(t *testing.T) {
+	vars := map[string]float64{"score": 10, "comments_count": 25}
+	cases := map[string]float64{
+		"comments_count / greatest(score, 1)": 2.5,
+		"1 + 2 * 3 - 4 / 2":                   5,
+		"(1 + 2) * -3":                        -9,
+		"least(score, comments_count, 7)":     7,
+		"LOG(100) + sqrt(16) + abs(-1)":       7,
+		"floor(2.7) + ceil(2.2)":              5,
+	}
+	for source, want := range cases {
+		e, err := expr.Parse(source)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", source, err)
+			continue
+		}
+		if got, err := e.Eval(vars); err != nil || math.Abs(got-want) > 1e-9 {
+			t.Errorf("%q = %v, %v; want %v", source, got, err, want)
+		}
+	}
+
+	for _, source := range []string{"", "1 +", "(score", "score score", "nope(1)", "abs(1, 2)", "1 $ 2"} {
+		if _, err := expr.Parse(source); err == nil {
+			t.Errorf("Expected %q to be rejected", source)
+		}
+	}
+
+	e, _ := expr.Parse("comments_count / (score - 10)")
+	if _, err := e.Eval(vars); !errors.Is(err, expr.ErrNotFinite) {
+		t.Errorf("Expected a division by zero to be rejected, got %v", err)
+	}
+	if _, err := e.Eval(map[string]float64{"score": 1}); err == nil {
+		t.Error("Expected a missing variable to be rejected")
+	}
+	if got := e.Variables(); !reflect.DeepEqual(got, []string{"comments_count", "score"}) {
+		t.Errorf("Unexpected variables %v", got)
+	}
+}
+
+func TestComputerStoresComputedFields(t *testing.T) {
+	for _, definition := range []string{"engagement", "Bad-Name = 1", "x = votes * 2", "x = ("} {
+		if _, err := etl.ParseComputedField(definition); err == nil {
+			t.Errorf("Expected %q to be rejected", definition)
+		}
+	}
+
+	var fields []etl.ComputedField
+	for _, definition := range []string{"engagement = comments_count / greatest(score, 1)", "reach = kids + 1"} {
+		field, err := etl.ParseComputedField(definition)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", definition, err)
+		}
+		fields = append(fields, field)
+	}
+	store := &fakeComputedFieldStore{fields: make(map[string]map[string]float64)}
+	computer := etl.NewComputer(store, fields)
+	ctx := context.Background()
+
+	story := &models.Story{ID: 1, Score: 0, Comments_count: 12, Comments_ids: []int{2, 3}, Created_At: 1}
+	if err := computer.PostPersist(ctx, story); err != nil {
+		t.Fatalf("Failed to compute story fields: %v", err)
+	}
+	if want := map[string]float64{"engagement": 12, "reach": 3}; !reflect.DeepEqual(store.fields["story"], want) {
+		t.Errorf("Expected story fields %v, got %v", want, store.fields["story"])
+	}
+
+	// Comments have no score or comment count
+	comment := &models.Comment{ID: 2, Replies: []int{4}, Created_At: 1}
+	if err := computer.PostPersist(ctx, comment); err != nil {
+		t.Fatalf("Failed to compute comment fields: %v", err)
+	}
+	if want := map[string]float64{"reach": 2}; !reflect.DeepEqual(store.fields["comment"], want) {
+		t.Errorf("Expected comment fields %v, got %v", want, store.fields["comment"])
+	}
+}
+
+func TestComputedFieldRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	repo := postgres.NewComputedFieldRepository()
+	ctx := context.Background()
+
+	if err := repo.ReplaceFields(ctx, "story", 4242, map[string]float64{"engagement": 0.5, "reach": 3}); err != nil {
+		t.Fatalf("Failed to store computed fields: %v", err)
+	}
+	if err := repo.ReplaceFields(ctx, "story", 4242, map[string]float64{"engagement": 1.5}); err != nil {
+		t.Fatalf("Failed to replace computed fields: %v", err)
+	}
+	got, err := repo.GetFields(ctx, "story", 4242)
+	if err != nil {
+		t.Fatalf("Failed to load computed fields: %v", err)
+	}
+	if want := map[string]float64{"engagement": 1.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if err := repo.ReplaceFields(ctx, "bogus", 1, nil); !errors.Is(err, postgres.ErrUnknownKind) {
+		t.Errorf("Expected an unknown kind to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"internship-project/internal/config"
+)
+
+func TestConfigReload(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	dbHost, hadDBHost := os.LookupEnv("DB_HOST")
+	writeEnv := func(content string) {
+		if err := os.WriteFile(".env", []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var notified []string
+	config.OnChange(func(changed []string) { notified = changed })
+
+	writeEnv("RELOAD_TEST_BATCH_SIZE=10\nDB_HOST=db.elsewhere\n")
+	changed, err := config.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !slices.Contains(changed, "RELOAD_TEST_BATCH_SIZE") || config.GetEnvInt("RELOAD_TEST_BATCH_SIZE", 0) != 10 {
+		t.Errorf("Expected RELOAD_TEST_BATCH_SIZE=10 to be applied, changed: %v", changed)
+	}
+	if slices.Contains(changed, "DB_HOST") {
+		t.Error("Expected the immutable DB_HOST change to be rejected")
+	}
+	if v, ok := os.LookupEnv("DB_HOST"); ok != hadDBHost || v != dbHost {
+		t.Errorf("Expected DB_HOST to stay %q, got %q", dbHost, v)
+	}
+	if !slices.Equal(notified, changed) {
+		t.Errorf("Expected listeners to receive %v, got %v", changed, notified)
+	}
+
+	writeEnv("RELOAD_TEST_BATCH_SIZE=25\n")
+	if _, err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := config.GetEnvInt("RELOAD_TEST_BATCH_SIZE", 0); got != 25 {
+		t.Errorf("Expected updated value 25, got %d", got)
+	}
+
+	writeEnv("")
+	if _, err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, ok := os.LookupEnv("RELOAD_TEST_BATCH_SIZE"); ok {
+		t.Error("Expected a setting removed from the file to be unset")
+	}
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"internship-project/internal/transport"
+)
+
+func TestPrometheusGroupLagExposition(t *testing.T) {
+	var b strings.Builder
+	err := transport.WritePrometheusGroupLag(&b, map[string][]transport.PartitionLag{
+		"stories_group": {{Topic: "StoriesTopic", Partition: 1, Committed: 40, End: 42, Lag: 2}},
+		"asks_group":    {{Topic: "AsksTopic", Partition: 0, Committed: -1, End: 7, Lag: 7}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+
+	want := `# HELP consumer_group_lag_messages Messages the group has not committed yet, as seen by the broker.
+# TYPE consumer_group_lag_messages gauge
+consumer_group_lag_messages{group="asks_group",topic="AsksTopic",partition="0"} 7
+consumer_group_lag_messages{group="stories_group",topic="StoriesTopic",partition="1"} 2
+`
+	if b.String() != want {
+		t.Errorf("Unexpected exposition:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := transport.WritePrometheusMetrics(&b); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	for _, name := range []string{"consumer_messages_processed_total", "consumer_messages_failed_total", "consumer_lag_messages"} {
+		if !strings.Contains(b.String(), "# TYPE "+name+" ") {
+			t.Errorf("Expected %s in the exposition:\n%s", name, b.String())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+)
+
+func TestSearchClientCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hn-comments/_count" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"count": 1234}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	client := search.NewClient()
+
+	count, err := client.Count(context.Background(), "comment")
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if count != 1234 {
+		t.Errorf("Expected 1234 documents, got %d", count)
+	}
+
+	if _, err := client.Count(context.Background(), "story"); err == nil {
+		t.Error("Expected an error for a missing index")
+	}
+	if _, err := client.Count(context.Background(), "pollopt"); err == nil {
+		t.Error("Expected an error for a kind without an index")
+	}
+}
+
+func TestDataQualityChecks(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	story := &models.Story{ID: 860001, Type: "story", Title: "No author", Author: "", Created_At: time.Now().Unix()}
+	if err := stories.Create(ctx, story); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	defer stories.Delete(ctx, story.ID)
+
+	repo := postgres.NewDataQualityRepository()
+	checks, err := repo.RunChecks(ctx, 1000)
+	if err != nil {
+		t.Fatalf("Failed to run checks: %v", err)
+	}
+
+	found := false
+	for _, check := range checks {
+		if check.Name != "stories_empty_author" {
+			continue
+		}
+		for _, id := range check.SampleIDs {
+			found = found || id == story.ID
+		}
+		if check.Count < 1 {
+			t.Errorf("Expected at least one story with an empty author, got %d", check.Count)
+		}
+	}
+	if !found {
+		t.Error("Expected the story with an empty author among the samples")
+	}
+
+	report := &models.DataQualityReport{Created_At: time.Now().Unix(), Checks: checks}
+	if err := repo.SaveReport(ctx, report); err != nil {
+		t.Fatalf("Failed to save report: %v", err)
+	}
+	latest, err := repo.GetLatestReport(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get latest report: %v", err)
+	}
+	if latest.ID != report.ID || len(latest.Checks) != len(checks) {
+		t.Errorf("Expected report %s with %d checks, got %s with %d", report.ID, len(checks), latest.ID, len(latest.Checks))
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"internship-project/internal/config"
+	"internship-project/internal/search"
+	"internship-project/internal/transport"
+)
+
+func TestConfigProfileOverridesEnvFile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	files := map[string]string{
+		".env":    "PROFILE_TEST_ENDPOINT=http://us.example.com\nPROFILE_TEST_BATCH=10\n",
+		".env.eu": "PROFILE_TEST_ENDPOINT=http://eu.example.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CONFIG_PROFILE", "eu")
+	defer func() {
+		os.Setenv("CONFIG_PROFILE", "")
+		os.WriteFile(".env", nil, 0o644)
+		config.Reload()
+	}()
+
+	if _, err := config.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := config.GetEnv("PROFILE_TEST_ENDPOINT", ""); got != "http://eu.example.com" {
+		t.Errorf("Expected the profile endpoint, got %q", got)
+	}
+	if got := config.GetEnvInt("PROFILE_TEST_BATCH", 0); got != 10 {
+		t.Errorf("Expected the .env value without a profile override, got %d", got)
+	}
+	if err := config.ValidateSinks(); err != nil {
+		t.Errorf("Expected the profile to validate, got %v", err)
+	}
+
+	t.Setenv("CONFIG_PROFILE", "apac")
+	if _, err := config.Reload(); err == nil {
+		t.Error("Expected a reload with a missing profile file to fail")
+	}
+	if err := config.ValidateSinks(); err == nil {
+		t.Error("Expected a missing profile file to fail the validation")
+	}
+}
+
+func TestSinksEnabledByConfig(t *testing.T) {
+	t.Setenv("SINKS", "")
+	for _, name := range config.Sinks {
+		if !config.SinkEnabled(name) {
+			t.Errorf("Expected %s enabled without SINKS", name)
+		}
+	}
+
+	t.Setenv("SINKS", "events")
+	t.Setenv("OPENSEARCH_URL", "http://localhost:9200")
+	t.Setenv("RELATED_OPENSEARCH_URL", "http://localhost:9200")
+	if got := strings.Join(config.EnabledSinks(), ","); got != "events" {
+		t.Errorf("Expected only the events sink enabled, got %q", got)
+	}
+	if search.NewClient() != nil {
+		t.Error("Expected no search client with the opensearch sink disabled")
+	}
+
+	t.Setenv("SINKS", "none")
+	publisher, err := transport.NewPublisher()
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	if err := publisher.Publish(context.Background(), "StoriesTopic", []byte("1")); err != nil {
+		t.Errorf("Expected a disabled events sink to drop messages, got %v", err)
+	}
+	if _, err := transport.NewConsumer("residency"); err == nil {
+		t.Error("Expected no consumer with the events sink disabled")
+	}
+}
+
+func TestValidateSinks(t *testing.T) {
+	t.Setenv("CONFIG_PROFILE", "")
+	t.Setenv("EVENT_TRANSPORT", "nats")
+	t.Setenv("NATS_URL", "nats://eu-1.example.com:4222")
+	t.Setenv("OPENSEARCH_URL", "")
+
+	t.Setenv("SINKS", "events")
+	if err := config.ValidateSinks(); err != nil {
+		t.Errorf("Expected the events sink to validate, got %v", err)
+	}
+
+	t.Setenv("SINKS", "events,opensearch,archive")
+	err := config.ValidateSinks()
+	if err == nil || !strings.Contains(err.Error(), "OPENSEARCH_URL") || !strings.Contains(err.Error(), `"archive"`) {
+		t.Errorf("Expected the missing endpoint and the unknown sink reported, got %v", err)
+	}
+}
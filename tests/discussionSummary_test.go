@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+
+	"internship-project/internal/models"
+)
+
+func TestSummarizeDiscussion(t *testing.T) {
+	comments := []*models.Comment{
+		{ID: 2, Parent: 1, Author: "alice", Created_At: 100},
+		{ID: 3, Parent: 1, Author: "bob", Created_At: 90},
+		{ID: 4, Parent: 1, Author: "carol", Created_At: 80},
+		{ID: 5, Parent: 2, Author: "bob", Created_At: 110},
+		{ID: 6, Parent: 5, Author: "alice", Created_At: 120},
+		{ID: 7, Parent: 3, Author: "dave", Created_At: 95},
+		{ID: 8, Parent: 3, Author: "dave", Created_At: 96},
+		{ID: 9, Parent: 42, Author: "eve", Created_At: 97}, // orphan
+	}
+
+	summary := models.SummarizeDiscussion(1, comments, 2)
+	if summary.Comments != 7 || summary.Commenters != 4 || summary.Max_Depth != 3 {
+		t.Errorf("Unexpected stats: %d comments, %d commenters, depth %d",
+			summary.Comments, summary.Commenters, summary.Max_Depth)
+	}
+	if len(summary.Branches) != 2 {
+		t.Fatalf("Expected 2 branches, got %d", len(summary.Branches))
+	}
+
+	// comments 3 and 2 both have two replies; the older one comes first
+	first, second := summary.Branches[0], summary.Branches[1]
+	if first.Comment.ID != 3 || first.Replies != 2 || first.Depth != 2 || first.Commenters != 2 {
+		t.Errorf("Unexpected first branch %+v", first)
+	}
+	if second.Comment.ID != 2 || second.Replies != 2 || second.Depth != 3 || second.Commenters != 2 {
+		t.Errorf("Unexpected second branch %+v", second)
+	}
+}
+
+func TestSummarizeEmptyDiscussion(t *testing.T) {
+	summary := models.SummarizeDiscussion(1, nil, 5)
+	if summary.Comments != 0 || summary.Max_Depth != 0 || summary.Branches == nil || len(summary.Branches) != 0 {
+		t.Errorf("Unexpected summary of an empty discussion %+v", summary)
+	}
+}
+
+func TestOrderThread(t *testing.T) {
+	// level by level, siblings in rank order, as returned by GetThread
+	comments := []*models.Comment{
+		{ID: 3, Parent: 1, Depth: 1, Rank: 1},
+		{ID: 2, Parent: 1, Depth: 1, Rank: 2},
+		{ID: 7, Parent: 3, Depth: 2, Rank: 1},
+		{ID: 5, Parent: 2, Depth: 2, Rank: 1},
+		{ID: 4, Parent: 2, Depth: 2, Rank: 2},
+		{ID: 6, Parent: 5, Depth: 3, Rank: 1},
+		{ID: 9, Parent: 42, Depth: 2}, // orphan
+	}
+
+	var ids []int
+	for _, comment := range models.OrderThread(1, comments) {
+		ids = append(ids, comment.ID)
+	}
+	if want := []int{3, 7, 2, 5, 6, 4}; !slices.Equal(ids, want) {
+		t.Errorf("Expected thread order %v, got %v", want, ids)
+	}
+	if thread := models.OrderThread(1, nil); thread == nil || len(thread) != 0 {
+		t.Errorf("Expected an empty thread, got %v", thread)
+	}
+}
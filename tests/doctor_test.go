@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/doctor"
+)
+
+func TestDoctorReportsEveryCheck(t *testing.T) {
+	checks := []doctor.Check{
+		{Name: "up", Run: func(ctx context.Context) (string, error) { return "fine", nil }},
+		{Name: "off", Run: func(ctx context.Context) (string, error) {
+			return "", fmt.Errorf("sink disabled: %w", doctor.ErrSkipped)
+		}},
+		{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}
+	results := doctor.Run(context.Background(), checks, 50*time.Millisecond)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %+v", results)
+	}
+	if results[0].Status != doctor.StatusPass || results[0].Detail != "fine" {
+		t.Errorf("Unexpected result %+v", results[0])
+	}
+	if results[1].Status != doctor.StatusSkip || results[1].Detail != "sink disabled" {
+		t.Errorf("Unexpected result %+v", results[1])
+	}
+	if results[2].Status != doctor.StatusFail || !strings.Contains(results[2].Detail, "deadline exceeded") {
+		t.Errorf("Expected the slow check to time out, got %+v", results[2])
+	}
+	if doctor.Passed(results) {
+		t.Error("Expected a failed check to fail the run")
+	}
+	if !doctor.Passed(results[:2]) {
+		t.Error("Expected a skipped check not to fail the run")
+	}
+
+	var out strings.Builder
+	if err := doctor.Print(&out, results); err != nil {
+		t.Fatalf("Failed to print the results: %v", err)
+	}
+	for _, want := range []string{"CHECK", "PASS", "SKIP", "FAIL", "Some checks failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the table:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDoctorDefaultChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/maxitem.json":
+			w.Write([]byte(`42`))
+		case r.URL.Path == "/hn-comments/_mapping":
+			// A dynamic mapping of the author: a full-text field instead of a keyword
+			w.Write([]byte(`{"hn-comments-v2": {"mappings": {"properties": {
+				"by": {"type": "text"}, "time": {"type": "long"}, "text": {"type": "text"}}}}}`))
+		case strings.HasSuffix(r.URL.Path, "/_mapping"):
+			w.Write([]byte(`{"index": {"mappings": {"properties": {
+				"by": {"type": "keyword"}, "time": {"type": "long"}, "title": {"type": "text"}}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	t.Setenv("EVENT_TRANSPORT", "nats")
+	t.Setenv("REDIS_ADDR", "127.0.0.1:1")
+
+	results := doctor.Run(context.Background(), doctor.DefaultChecks(errors.New("connection refused")), 2*time.Second)
+	statuses := map[string]doctor.Result{}
+	for _, r := range results {
+		statuses[r.Name] = r
+	}
+	for name, want := range map[string]string{
+		"database":   doctor.StatusFail,
+		"schema":     doctor.StatusSkip,
+		"redis":      doctor.StatusFail,
+		"kafka":      doctor.StatusSkip,
+		"opensearch": doctor.StatusFail,
+		"hn-api":     doctor.StatusPass,
+	} {
+		if statuses[name].Status != want {
+			t.Errorf("Expected %s to %s, got %+v", name, want, statuses[name])
+		}
+	}
+	if detail := statuses["opensearch"].Detail; !strings.Contains(detail, "hn-comments: by is text, want keyword") ||
+		strings.Contains(detail, "hn-stories") {
+		t.Errorf("Expected only the comments mapping reported, got %q", detail)
+	}
+	if statuses["hn-api"].Detail != "max item 42" {
+		t.Errorf("Unexpected hn-api detail %q", statuses["hn-api"].Detail)
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestFaviconLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/linked/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head>
+				<link rel="apple-touch-icon" href="/touch.png">
+				<link rel="Shortcut Icon" href="static/icon.png">
+				</head></html>`))
+		case "/favicon.ico":
+			w.Header().Set("Content-Type", "image/x-icon")
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>No icon</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	previewer := services.NewLinkPreviewer()
+	ctx := context.Background()
+
+	icon, err := previewer.Favicon(ctx, server.URL+"/linked/")
+	if err != nil || icon != server.URL+"/linked/static/icon.png" {
+		t.Errorf("Expected the linked icon, got %q (%v)", icon, err)
+	}
+	icon, err = previewer.Favicon(ctx, server.URL+"/")
+	if err != nil || icon != server.URL+"/favicon.ico" {
+		t.Errorf("Expected /favicon.ico, got %q (%v)", icon, err)
+	}
+	if _, err := previewer.Favicon(ctx, "http://127.0.0.1:1/"); err == nil {
+		t.Error("Expected a refused connection to be reported")
+	}
+}
+
+func TestDomainRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	now := time.Now().Unix()
+	for i, url := range []string{"https://www.Example.org/a", "http://example.org:8080/b?c", "https://other.net/"} {
+		story := &models.Story{ID: 876500 + i, Type: "story", Title: "Domain story", URL: url, Score: 10 * (i + 1),
+			Comments_count: i, Author: "testuser", Created_At: now - int64(i), Comments_ids: []int{}}
+		if err := stories.Create(ctx, story); err != nil {
+			t.Fatalf("Failed to create story: %v", err)
+		}
+	}
+
+	repo := postgres.NewDomainRepository()
+	if err := repo.RefreshStats(ctx); err != nil {
+		t.Fatalf("Failed to refresh domain stats: %v", err)
+	}
+	domain, err := repo.GetByDomain(ctx, "www.example.org")
+	if err != nil {
+		t.Fatalf("Failed to load domain: %v", err)
+	}
+	if domain.StoryCount != 2 || domain.AverageScore != 15 || domain.TopScore != 20 || domain.CommentCount != 1 ||
+		domain.FirstSeen != now-1 || domain.LastSeen != now {
+		t.Errorf("Unexpected domain aggregates %+v", domain)
+	}
+
+	if err := repo.SetFavicon(ctx, "example.org", "https://example.org/favicon.ico", now); err != nil {
+		t.Fatalf("Failed to set favicon: %v", err)
+	}
+	// A lookup finding nothing keeps the favicon, and refreshes keep it too
+	if err := repo.SetFavicon(ctx, "example.org", "", now+1); err != nil {
+		t.Fatalf("Failed to set favicon: %v", err)
+	}
+	if err := repo.RefreshStats(ctx); err != nil {
+		t.Fatalf("Failed to refresh domain stats: %v", err)
+	}
+	if domain, err := repo.GetByDomain(ctx, "example.org"); err != nil || domain.FaviconURL != "https://example.org/favicon.ico" {
+		t.Errorf("Expected the favicon kept, got %+v (%v)", domain, err)
+	}
+	due, err := repo.GetFaviconsToCheck(ctx, now, 100)
+	if err != nil {
+		t.Fatalf("Failed to load domains to check: %v", err)
+	}
+	for _, name := range due {
+		if name == "example.org" {
+			t.Error("Expected the looked up domain not to be due")
+		}
+	}
+
+	if _, err := repo.GetByDomain(ctx, "missing.example"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
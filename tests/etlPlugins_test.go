@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+)
+
+// rejectAuthor is a test plugin dropping every item of one author
+type rejectAuthor struct {
+	author    string
+	persisted int
+}
+
+func (p *rejectAuthor) Name() string { return "reject-author" }
+
+func (p *rejectAuthor) PrePersist(ctx context.Context, item interface{}) error {
+	if story, ok := item.(*models.Story); ok && story.Author == p.author {
+		return errors.New("author is blocked")
+	}
+	return nil
+}
+
+func (p *rejectAuthor) PostPersist(ctx context.Context, item interface{}) error {
+	p.persisted++
+	return nil
+}
+
+func TestETLPipelineBuiltins(t *testing.T) {
+	ctx := context.Background()
+	pipeline := etl.NewPipeline(etl.Sanitizer{}, etl.URLNormalizer{})
+
+	story := &models.Story{
+		Title: "  Show HN:\x00 a\ttool \n",
+		URL:   "HTTPS://Example.COM/post?id=7&utm_source=hn&utm_medium=feed",
+	}
+	if err := pipeline.PrePersist(ctx, story); err != nil {
+		t.Fatalf("PrePersist failed: %v", err)
+	}
+
+	if story.Title != "Show HN: atool" {
+		t.Errorf("Expected sanitized title, got %q", story.Title)
+	}
+	if story.URL != "https://example.com/post?id=7" {
+		t.Errorf("Expected normalized URL, got %q", story.URL)
+	}
+
+	comment := &models.Comment{Text: " line one\nline\x07 two "}
+	if err := pipeline.PrePersist(ctx, comment); err != nil {
+		t.Fatalf("PrePersist failed: %v", err)
+	}
+	if comment.Text != "line one\nline two" {
+		t.Errorf("Expected line breaks kept in comment text, got %q", comment.Text)
+	}
+}
+
+func TestETLPipelineDropsRejectedItems(t *testing.T) {
+	ctx := context.Background()
+	plugin := &rejectAuthor{author: "spammer"}
+	pipeline := etl.NewPipeline()
+	pipeline.Register(plugin)
+
+	if err := pipeline.PrePersist(ctx, &models.Story{Author: "spammer"}); err == nil {
+		t.Error("Expected the blocked author's story to be rejected")
+	}
+	if err := pipeline.PrePersist(ctx, &models.Story{Author: "pg"}); err != nil {
+		t.Errorf("Expected story to pass, got %v", err)
+	}
+
+	pipeline.PostPersist(ctx, &models.Story{Author: "pg"})
+	if plugin.persisted != 1 {
+		t.Errorf("Expected 1 PostPersist call, got %d", plugin.persisted)
+	}
+}
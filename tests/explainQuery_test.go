@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestExplainQuery(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewDiagnosticsRepository(10 * time.Second)
+
+	minScore := 10
+	filter := repository.ItemFilter{Tenant: models.DefaultTenant, MinScore: &minScore, Query: "go", Limit: 20}
+
+	for _, name := range repo.ExplainableQueries() {
+		plan, err := repo.ExplainQuery(ctx, name, filter)
+		if err != nil {
+			t.Fatalf("Failed to explain %s: %v", name, err)
+		}
+		if len(plan.Plan) == 0 {
+			t.Errorf("Expected a plan for %s", name)
+		}
+		if !strings.Contains(strings.Join(plan.Plan, "\n"), "Execution Time") {
+			t.Errorf("Expected an analyzed plan for %s, got %v", name, plan.Plan)
+		}
+	}
+
+	if _, err := repo.ExplainQuery(ctx, "users.drop", filter); !errors.Is(err, postgres.ErrUnknownQuery) {
+		t.Errorf("Expected ErrUnknownQuery, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"internship-project/internal/services"
+)
+
+func TestForEachChunkedRunsChunksInSequence(t *testing.T) {
+	items := make([]int, 25)
+	for i := range items {
+		items[i] = i
+	}
+	options := services.FetchOptions{ChunkSize: 10, MaxConcurrency: 3, ChunkDelay: 20 * time.Millisecond}
+
+	var mu sync.Mutex
+	inFlight, maxInFlight, finished := 0, 0, 0
+	seen := make(map[int]bool)
+	start := time.Now()
+	services.ForEachChunked(context.Background(), items, options, func(ctx context.Context, item int) {
+		mu.Lock()
+		// Every item of the previous chunks is done before a chunk starts
+		if want := item / 10 * 10; finished < want {
+			t.Errorf("Item %d started with only %d of the previous %d items done", item, finished, want)
+		}
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		seen[item] = true
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		finished++
+		mu.Unlock()
+	})
+
+	if len(seen) != len(items) {
+		t.Errorf("Expected %d items processed, got %d", len(items), len(seen))
+	}
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most 3 calls in flight, got %d", maxInFlight)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected a delay between the 3 chunks, finished in %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	services.ForEachChunked(ctx, items, services.FetchOptions{ChunkSize: 5, MaxConcurrency: 1}, func(ctx context.Context, item int) {
+		calls++
+		if item == 2 {
+			cancel()
+		}
+	})
+	if calls != 3 {
+		t.Errorf("Expected no calls after the cancellation, got %d", calls)
+	}
+}
+
+func TestFetchMultipleReportsUnattemptedIDsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/item/2.json" {
+			cancel()
+		}
+		w.Write([]byte(`{"id":1,"type":"story","by":"pg","time":1700000000,"title":"Fetched","score":1}`))
+	}))
+	defer server.Close()
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+
+	stories := services.NewStoryApiService(services.NewHackerNewsApiClient())
+	options := services.WithFetchOptions(services.FetchOptions{MaxConcurrency: 1})
+	items, errs := stories.FetchMultipleWithErrors(ctx, []int{1, 2, 3, 4}, options)
+	if len(items) == 0 || items[0].ID != 1 {
+		t.Errorf("Expected item 1 fetched before the cancellation, got %v", items)
+	}
+	for _, id := range []int{3, 4} {
+		if !errors.Is(errs[id], context.Canceled) {
+			t.Errorf("Expected unattempted item %d to fail with context.Canceled, got %v", id, errs[id])
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected no requests after the cancellation, got %d", n)
+	}
+
+	// Nothing is attempted on a done context, and every ID is reported
+	_, err := stories.FetchMultiple(ctx, []int{5, 6})
+	var multi *services.MultiError
+	if !errors.As(err, &multi) || multi.Failed() != 2 || !errors.Is(multi.Errors[5], context.Canceled) {
+		t.Errorf("Expected both items reported canceled, got %v", err)
+	}
+	if _, err := stories.FetchMultiple(ctx, []int{7}, services.WithFailFast()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a fail-fast fetch to report the cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/firehose"
+	"internship-project/internal/models"
+)
+
+// drain returns the IDs of the events queued for a subscription
+func drain(sub *firehose.Subscription) []int {
+	var ids []int
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestFirehoseQueuePolicies(t *testing.T) {
+	hub := firehose.NewHub()
+	newest := hub.Subscribe("drop-newest", firehose.Options{QueueSize: 2, Policy: firehose.DropNewest})
+	oldest := hub.Subscribe("drop-oldest", firehose.Options{QueueSize: 2, Policy: firehose.DropOldest})
+	slow := hub.Subscribe("disconnect", firehose.Options{QueueSize: 2, Policy: firehose.Disconnect})
+	comments := hub.Subscribe("comments", firehose.Options{QueueSize: 10, Filter: func(event firehose.Event) bool {
+		return event.Kind == "comment"
+	}})
+
+	// Nobody consumes: publishing must still never block
+	for id := 1; id <= 4; id++ {
+		kind := "story"
+		if id%2 == 0 {
+			kind = "comment"
+		}
+		hub.Publish(firehose.Event{Kind: kind, ID: id})
+	}
+
+	if ids := drain(newest); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Expected drop-newest to keep 1 and 2, got %v", ids)
+	}
+	if ids := drain(oldest); len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Errorf("Expected drop-oldest to keep 3 and 4, got %v", ids)
+	}
+	if ids := drain(comments); len(ids) != 2 || ids[0] != 2 || ids[1] != 4 {
+		t.Errorf("Expected only the comments, got %v", ids)
+	}
+
+	drain(slow)
+	if _, ok := <-slow.Events(); ok || !errors.Is(slow.Err(), firehose.ErrSlowSubscriber) {
+		t.Errorf("Expected the slow subscriber to be disconnected, got %v", slow.Err())
+	}
+
+	stats := hub.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 subscribers left, got %+v", stats)
+	}
+	for _, s := range stats {
+		if s.Name == "drop-newest" && (s.Delivered != 2 || s.Dropped != 2) {
+			t.Errorf("Expected drop-newest to count 2 queued and 2 dropped, got %+v", s)
+		}
+	}
+
+	newest.Close()
+	if newest.Err() != nil || len(hub.Stats()) != 2 {
+		t.Errorf("Expected a clean unsubscribe, got %v", newest.Err())
+	}
+	hub.Close()
+	if _, ok := <-oldest.Events(); ok || !errors.Is(oldest.Err(), firehose.ErrHubClosed) {
+		t.Errorf("Expected the subscriptions to close with the hub, got %v", oldest.Err())
+	}
+	if _, ok := <-hub.Subscribe("late", firehose.Options{}).Events(); ok {
+		t.Error("Expected a subscription to a closed hub to be closed")
+	}
+}
+
+func TestFirehosePluginPublishesSavedItems(t *testing.T) {
+	hub := firehose.NewHub()
+	sub := hub.Subscribe("test", firehose.Options{})
+	defer sub.Close()
+
+	pipeline := etl.NewPipeline(etl.NewFirehose(hub))
+	story := &models.Story{ID: 7, Title: "Saved"}
+	pipeline.PostPersist(context.Background(), story)
+	pipeline.PostPersist(context.Background(), "not an item")
+
+	event := <-sub.Events()
+	if event.Kind != "story" || event.ID != 7 || event.Item != story || event.Time == 0 {
+		t.Errorf("Expected story 7 to be published, got %+v", event)
+	}
+	if ids := drain(sub); len(ids) != 0 {
+		t.Errorf("Expected nothing else published, got %v", ids)
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestFollowFeed(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	follows := postgres.NewFollowRepository()
+	stories := postgres.NewStoryRepository()
+	tenant := models.DefaultTenant
+	now := time.Now().Unix()
+
+	items := []*models.Story{
+		{ID: 870001, Type: "story", Title: "By a followed author", Author: "feedfollowed", Created_At: now},
+		{ID: 870002, Type: "story", Title: "Mentions zygomorphic feeds", Author: "feedother", Created_At: now - 1},
+		{ID: 870003, Type: "story", Title: "Unrelated", Author: "feedother", Created_At: now - 2},
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, items); err != nil {
+		t.Fatalf("Failed to create stories: %v", err)
+	}
+	defer func() {
+		for _, s := range items {
+			stories.Delete(ctx, s.ID)
+		}
+	}()
+
+	if _, err := follows.Follow(ctx, tenant, "feedfollowed"); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+	defer follows.Unfollow(ctx, tenant, "feedfollowed")
+	search, err := follows.SaveSearch(ctx, tenant, "zygomorphic")
+	if err != nil {
+		t.Fatalf("Failed to save search: %v", err)
+	}
+	defer follows.DeleteSavedSearch(ctx, tenant, search.ID)
+
+	feed, err := postgres.NewTimelineRepository().GetFeed(ctx, tenant, models.TimelineCursor{}, 50)
+	if err != nil {
+		t.Fatalf("Failed to get feed: %v", err)
+	}
+	found := make(map[int]bool)
+	for _, item := range feed {
+		found[item.ID] = true
+	}
+	if !found[870001] || !found[870002] {
+		t.Errorf("Expected the followed author's and the saved search's stories in the feed, got %v", found)
+	}
+	if found[870003] {
+		t.Error("Unexpected unrelated story in the feed")
+	}
+
+	if err := follows.Unfollow(ctx, tenant, "not-followed"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows when unfollowing an unknown author, got %v", err)
+	}
+}
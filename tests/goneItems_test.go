@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestNullItemsAreGone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item/1.json":
+			w.Write([]byte(`{"id":1,"type":"comment","by":"pg","time":1700000000,"text":"Fine","parent":7}`))
+		default:
+			w.Write([]byte("null\n"))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+
+	client := services.NewHackerNewsApiClient()
+	sink := &recordingDeadLetterSink{}
+	client.SetDeadLetterSink(sink)
+
+	if _, err := client.GetHNItem(context.Background(), 2); !errors.Is(err, services.ErrItemGone) {
+		t.Fatalf("Expected a null item to be gone, got %v", err)
+	}
+	if len(sink.letters) != 1 || sink.letters[0].Item_ID != 2 || sink.letters[0].Reason != models.DeadLetterGone {
+		t.Fatalf("Expected item 2 recorded as gone, got %+v", sink.letters)
+	}
+
+	comments, err := services.NewCommentApiService(client).FetchMultiple(context.Background(), []int{1, 3})
+	var multiErr *services.MultiError
+	if !errors.As(err, &multiErr) || !slices.Equal(multiErr.IDs(), []int{3}) || !errors.Is(err, services.ErrItemGone) {
+		t.Fatalf("Expected comment 3 reported gone, got %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != 1 {
+		t.Errorf("Expected only comment 1, got %v", comments)
+	}
+
+	if _, err := services.NewUserApiService(client).FetchByUsername(context.Background(), "nobody"); !errors.Is(err, services.ErrItemGone) {
+		t.Errorf("Expected a null user to be gone, got %v", err)
+	}
+	if _, err := client.DecodeHNItem(context.Background(), 4, []byte("null")); !errors.Is(err, services.ErrItemGone) {
+		t.Errorf("Expected a null payload to be gone, got %v", err)
+	}
+}
+
+func TestDeadLetterRepositoryGetGone(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewDeadLetterRepository()
+	gone := func(id int) *models.DeadLetter {
+		return &models.DeadLetter{Item_ID: id, Reason: models.DeadLetterGone, Payload: []byte("null")}
+	}
+	for i := 0; i < 3; i++ {
+		if err := repo.Record(ctx, gone(990001)); err != nil {
+			t.Fatalf("Failed to record gone item: %v", err)
+		}
+	}
+	if err := repo.Record(ctx, gone(990002)); err != nil {
+		t.Fatalf("Failed to record gone item: %v", err)
+	}
+
+	ids, err := repo.GetGone(ctx, []int{990001, 990002, 990003}, 3)
+	if err != nil {
+		t.Fatalf("Failed to get gone items: %v", err)
+	}
+	if !slices.Equal(ids, []int{990001}) {
+		t.Errorf("Expected only the item served null 3 times, got %v", ids)
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"internship-project/internal/watchdog"
+)
+
+func TestGoroutineWatchdogAlertsOnMonotonicGrowth(t *testing.T) {
+	t.Setenv("GOROUTINE_WATCHDOG_WINDOW", "4")
+	t.Setenv("GOROUTINE_WATCHDOG_MIN_GROWTH", "30")
+	w := watchdog.NewGoroutineWatchdog()
+
+	for i, count := range []int{100, 110, 120} {
+		if w.Observe(count) {
+			t.Fatalf("Unexpected alert at sample %d", i)
+		}
+	}
+	if !w.Observe(130) {
+		t.Error("Expected an alert after 4 growing samples")
+	}
+
+	// A dip restarts the window
+	for _, count := range []int{140, 150, 145, 160, 170} {
+		if w.Observe(count) {
+			t.Errorf("Unexpected alert at %d after the count dropped", count)
+		}
+	}
+
+	// Growth below the threshold is not reported
+	small := watchdog.NewGoroutineWatchdog()
+	for _, count := range []int{10, 11, 12, 13} {
+		if small.Observe(count) {
+			t.Errorf("Unexpected alert for small growth at %d", count)
+		}
+	}
+}
+
+func TestTopCreationSites(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	for i := 0; i < 20; i++ {
+		go func() { <-stop }()
+	}
+
+	sites := watchdog.TopCreationSites(3)
+	if len(sites) == 0 {
+		t.Fatal("Expected creation sites")
+	}
+	if sites[0].Count < 20 {
+		t.Errorf("Expected the test goroutines to be the top site, got %+v", sites[0])
+	}
+}
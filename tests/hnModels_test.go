@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"internship-project/internal/models"
+)
+
+// loadHNPayload decodes a payload recorded from the HackerNews API into v
+func loadHNPayload(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "hn", "v0", name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("Failed to decode %s: %v", name, err)
+	}
+}
+
+func TestHNItemKinds(t *testing.T) {
+	cases := map[string]string{
+		"item/8863.json":    "story",
+		"item/121003.json":  "ask",
+		"item/2921983.json": "comment",
+		"item/192327.json":  "job",
+		"item/126809.json":  "poll",
+		"item/160705.json":  "pollopt",
+	}
+	for name, kind := range cases {
+		var item models.HNItem
+		loadHNPayload(t, name, &item)
+		if got := item.Kind(); got != kind {
+			t.Errorf("Expected %s to be a %s, got %s", name, kind, got)
+		}
+	}
+}
+
+func TestHNItemConverters(t *testing.T) {
+	var raw models.HNItem
+
+	loadHNPayload(t, "item/8863.json", &raw)
+	story := raw.ToStory()
+	if !story.IsValid() || story.Author != "dhouston" || story.Created_At != 1175714200 ||
+		story.Comments_count != 71 || len(story.Comments_ids) != 33 || story.URL == "" {
+		t.Errorf("Unexpected story %+v", story)
+	}
+
+	raw = models.HNItem{}
+	loadHNPayload(t, "item/121003.json", &raw)
+	ask := raw.ToAsk()
+	if !ask.IsValid() || ask.Type != "ask" || ask.Replies_count != 16 || len(ask.Reply_ids) != 3 || ask.Text == "" {
+		t.Errorf("Unexpected ask %+v", ask)
+	}
+
+	raw = models.HNItem{}
+	loadHNPayload(t, "item/2921983.json", &raw)
+	comment := raw.ToComment()
+	if !comment.IsValid() || comment.Parent != 2921506 || len(comment.Replies) != 7 || comment.Author != "norvig" {
+		t.Errorf("Unexpected comment %+v", comment)
+	}
+
+	raw = models.HNItem{}
+	loadHNPayload(t, "item/192327.json", &raw)
+	job := raw.ToJob()
+	if !job.IsValid() || job.Score != 6 || job.URL != "" || job.Text == "" {
+		t.Errorf("Unexpected job %+v", job)
+	}
+
+	raw = models.HNItem{}
+	loadHNPayload(t, "item/126809.json", &raw)
+	poll := raw.ToPoll()
+	if !poll.IsValid() || len(poll.PollOptions) != 3 || poll.PollOptions[0] != 126810 || len(poll.Reply_Ids) != 25 {
+		t.Errorf("Unexpected poll %+v", poll)
+	}
+
+	raw = models.HNItem{}
+	loadHNPayload(t, "item/160705.json", &raw)
+	option := raw.ToPollOption()
+	if !option.IsValid() || option.PollID != 160704 || option.Votes != 335 || option.OptionText == "" {
+		t.Errorf("Unexpected poll option %+v", option)
+	}
+}
+
+func TestHNItemDeletedIsInvalid(t *testing.T) {
+	var raw models.HNItem
+	loadHNPayload(t, "item/2921986.json", &raw)
+	if !raw.Deleted || raw.Kind() != "comment" {
+		t.Fatalf("Unexpected deleted item %+v", raw)
+	}
+	if raw.ToComment().IsValid() {
+		t.Error("Expected a deleted comment to be invalid")
+	}
+}
+
+func TestConvertHNItemByModel(t *testing.T) {
+	var raw models.HNItem
+	loadHNPayload(t, "item/121003.json", &raw)
+	if ask := models.ConvertHNItem[models.Ask](&raw); ask.ID != 121003 || ask.Type != "ask" {
+		t.Errorf("Unexpected ask %+v", ask)
+	}
+	if story := models.ConvertHNItem[models.Story](&raw); story.ID != 121003 || story.Type != "story" {
+		t.Errorf("Unexpected story %+v", story)
+	}
+}
+
+func TestModelJSONTagsMatchHNAPI(t *testing.T) {
+	var story models.Story
+	loadHNPayload(t, "item/8863.json", &story)
+	if story.Comments_count != 71 || len(story.Comments_ids) != 33 || story.Author != "dhouston" {
+		t.Errorf("Unexpected story decoded from the API payload %+v", story)
+	}
+
+	var poll models.Poll
+	loadHNPayload(t, "item/126809.json", &poll)
+	if len(poll.PollOptions) != 3 || len(poll.Reply_Ids) != 25 {
+		t.Errorf("Unexpected poll decoded from the API payload %+v", poll)
+	}
+
+	var raw models.HNUser
+	loadHNPayload(t, "user/jl.json", &raw)
+	user := raw.ToUser()
+	if !user.IsValid() || user.Username != "jl" || user.Karma != 2937 || user.Created_At != 1173923446 || len(user.Submitted) != 6 {
+		t.Errorf("Unexpected user %+v", user)
+	}
+	body, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(body, &fields)
+	if fields["id"] != "jl" || len(fields) != 5 {
+		t.Errorf("Expected the user encoded with the API fields, got %s", body)
+	}
+}
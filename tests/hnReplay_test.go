@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"internship-project/internal/config"
+	"internship-project/internal/models"
+	"internship-project/internal/services"
+)
+
+// newFixtureClient returns an HN client replaying the responses recorded in testdata/hn. Run the
+// tests with HN_API_FIXTURES_MODE=record to refresh them from the live API.
+func newFixtureClient(t *testing.T) *services.HackerNewsApiClient {
+	t.Helper()
+	if config.GetEnv("HN_API_FIXTURES_MODE", "") != string(services.FixtureRecord) {
+		t.Setenv("HN_API_FIXTURES_MODE", string(services.FixtureReplay))
+	}
+	t.Setenv("HN_API_FIXTURES_DIR", filepath.Join("testdata", "hn"))
+	return services.NewHackerNewsApiClient()
+}
+
+func TestReplayFetchMultiple(t *testing.T) {
+	stories := services.NewStoryApiService(newFixtureClient(t))
+	ctx := context.Background()
+
+	ids, err := stories.FetchTopStories(ctx)
+	if err != nil {
+		t.Fatalf("Failed to fetch top stories: %v", err)
+	}
+	items, err := stories.FetchMultiple(ctx, append(ids, 1), services.WithMaxConcurrency(2))
+	if len(items) != len(ids) {
+		t.Fatalf("Expected %d stories, got %d", len(ids), len(items))
+	}
+	for i, story := range items {
+		if story.ID != ids[i] {
+			t.Errorf("Expected story %d at position %d, got %d", ids[i], i, story.ID)
+		}
+	}
+
+	var multi *services.MultiError
+	if !errors.As(err, &multi) || multi.Failed() != 1 || multi.IDs()[0] != 1 {
+		t.Fatalf("Expected item 1 reported as failed, got %v", err)
+	}
+	if !errors.Is(err, services.ErrFixtureMissing) {
+		t.Errorf("Expected a missing fixture error, got %v", err)
+	}
+
+	if _, err := stories.FetchMultiple(ctx, []int{8863, 1}, services.WithFailFast()); err == nil {
+		t.Error("Expected fail-fast to abort on the missing item")
+	}
+}
+
+func TestReplayAsksAreConverted(t *testing.T) {
+	asks := services.NewAskApiService(newFixtureClient(t))
+	ctx := context.Background()
+
+	ids, err := asks.FetchAskStories(ctx)
+	if err != nil {
+		t.Fatalf("Failed to fetch ask stories: %v", err)
+	}
+	items, err := asks.FetchMultiple(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to fetch asks: %v", err)
+	}
+	if len(items) != 1 || !items[0].IsValid() || items[0].Type != "ask" || items[0].Replies_count != 16 {
+		t.Errorf("Expected the Ask HN story converted to a valid ask, got %+v", items)
+	}
+}
+
+func TestReplayUpdatesClassification(t *testing.T) {
+	client := newFixtureClient(t)
+	ctx := context.Background()
+
+	update, err := services.NewUpdateApiService(client).FetchUpdates(ctx)
+	if err != nil {
+		t.Fatalf("Failed to fetch updates: %v", err)
+	}
+	if !update.IsValid() {
+		t.Fatalf("Expected a valid update, got %+v", update)
+	}
+
+	kinds := make(map[int]string)
+	valid := 0
+	for _, id := range update.IDs {
+		var item models.HNItem
+		if err := client.GetItem(ctx, id, &item); err != nil {
+			t.Fatalf("Failed to fetch item %d: %v", id, err)
+		}
+		kinds[id] = item.Kind()
+		if item.Kind() == "comment" && item.ToComment().IsValid() {
+			valid++
+		}
+	}
+	want := map[int]string{
+		8863: "story", 121003: "ask", 2921983: "comment", 2921986: "comment",
+		126809: "poll", 160705: "pollopt", 192327: "job",
+	}
+	for id, kind := range want {
+		if kinds[id] != kind {
+			t.Errorf("Expected item %d classified as %s, got %q", id, kind, kinds[id])
+		}
+	}
+	if valid != 1 {
+		t.Errorf("Expected the deleted comment to be invalid, got %d valid comments", valid)
+	}
+
+	user, err := services.NewUserApiService(client).FetchByUsername(ctx, update.Profiles[0])
+	if err != nil {
+		t.Fatalf("Failed to fetch user: %v", err)
+	}
+	if user.Username != "jl" || user.Karma != 2937 {
+		t.Errorf("Unexpected user %+v", user)
+	}
+}
+
+func TestFixtureTransportRecordsAndReplays(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/item/42.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"by":"someone","id":42,"time":1700000000,"title":"Recorded","type":"story","url":"https://example.com"}`))
+	}))
+	defer upstream.Close()
+	dir := t.TempDir()
+	t.Setenv("HN_API_BASE_URL", upstream.URL+"/v0")
+	t.Setenv("HN_API_FIXTURES_DIR", dir)
+	ctx := context.Background()
+
+	t.Setenv("HN_API_FIXTURES_MODE", string(services.FixtureRecord))
+	var recorded models.HNItem
+	if err := services.NewHackerNewsApiClient().GetItem(ctx, 42, &recorded); err != nil {
+		t.Fatalf("Failed to record item: %v", err)
+	}
+	if err := services.NewHackerNewsApiClient().GetItem(ctx, 43, &recorded); err == nil {
+		t.Error("Expected the upstream 404 to be returned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "v0", "item", "43.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected failed responses not to be recorded, got %v", err)
+	}
+
+	upstream.Close()
+	t.Setenv("HN_API_FIXTURES_MODE", string(services.FixtureReplay))
+	var replayed models.HNItem
+	if err := services.NewHackerNewsApiClient().GetItem(ctx, 42, &replayed); err != nil {
+		t.Fatalf("Failed to replay item: %v", err)
+	}
+	if replayed.Title != "Recorded" || replayed.By != "someone" {
+		t.Errorf("Unexpected replayed item %+v", replayed)
+	}
+}
+
+func TestReplayPollsAmongTopStories(t *testing.T) {
+	client := newFixtureClient(t)
+	ctx := context.Background()
+
+	ids, err := services.NewStoryApiService(client).FetchTopStories(ctx)
+	if err != nil {
+		t.Fatalf("Failed to fetch top stories: %v", err)
+	}
+	candidates, err := services.NewPollApiService(client).FetchMultiple(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to fetch listed items: %v", err)
+	}
+	var polls []*models.Poll
+	for _, poll := range candidates {
+		if poll.IsValid() {
+			polls = append(polls, poll)
+		}
+	}
+	if len(polls) != 1 || polls[0].ID != 126809 || len(polls[0].PollOptions) != 3 {
+		t.Errorf("Expected only poll 126809 among the top stories, got %+v", polls)
+	}
+}
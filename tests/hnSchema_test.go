@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"internship-project/internal/hnschema"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestRecordedItemsMatchTheirSchemas(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "hn", "v0", "item", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected recorded items, got %v (%v)", files, err)
+	}
+	for _, file := range files {
+		payload, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if kind, violations := hnschema.ValidateItem(payload); len(violations) > 0 {
+			t.Errorf("Expected %s (%s) to match its schema, got %v", file, kind, violations)
+		}
+	}
+}
+
+func TestItemSchemaViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		paths   []string
+	}{
+		{"deleted comment", `{"id":5,"type":"comment","time":1700000000,"parent":1,"deleted":true}`, nil},
+		{"null", `null`, nil},
+		{"score as string", `{"id":5,"type":"story","by":"pg","time":1700000000,"title":"T","score":"12"}`, []string{"/score"}},
+		{"missing title", `{"id":5,"type":"story","by":"pg","time":1700000000,"score":1}`, []string{"/title"}},
+		{"negative kid", `{"id":5,"type":"comment","by":"pg","time":1700000000,"parent":1,"text":"x","kids":[7,-1]}`, []string{"/kids/1"}},
+		{"unknown type", `{"id":5,"type":"essay","time":1700000000}`, []string{"/type"}},
+	}
+	for _, tt := range tests {
+		_, violations := hnschema.ValidateItem([]byte(tt.payload))
+		var paths []string
+		for _, violation := range violations {
+			paths = append(paths, violation.Path)
+		}
+		if strings.Join(paths, ",") != strings.Join(tt.paths, ",") {
+			t.Errorf("%s: expected violations at %v, got %v", tt.name, tt.paths, violations)
+		}
+	}
+}
+
+// recordingDeadLetterSink keeps the dead letters in memory
+type recordingDeadLetterSink struct {
+	mu      sync.Mutex
+	letters []*models.DeadLetter
+}
+
+func (s *recordingDeadLetterSink) Record(ctx context.Context, letter *models.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func TestDriftedItemsAreDeadLettered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item/1.json":
+			w.Write([]byte(`{"id":1,"type":"story","by":"pg","time":1700000000,"title":"Fine","score":3}`))
+		case "/item/2.json":
+			w.Write([]byte(`{"id":2,"type":"story","author":"pg","time":1700000000,"headline":"Drifted","score":3}`))
+		default:
+			w.Write([]byte(`null`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+
+	client := services.NewHackerNewsApiClient()
+	sink := &recordingDeadLetterSink{}
+	client.SetDeadLetterSink(sink)
+
+	stories, err := services.NewStoryApiService(client).FetchMultiple(context.Background(), []int{1, 2})
+	if len(stories) != 1 || stories[0].Title != "Fine" {
+		t.Fatalf("Expected only story 1 to be converted, got %v", stories)
+	}
+	var schemaErr *services.SchemaError
+	if !errors.Is(err, services.ErrSchemaViolation) || !errors.As(err, &schemaErr) || schemaErr.ID != 2 {
+		t.Fatalf("Expected story 2 to fail schema validation, got %v", err)
+	}
+
+	if len(sink.letters) != 1 {
+		t.Fatalf("Expected one dead letter, got %d", len(sink.letters))
+	}
+	letter := sink.letters[0]
+	if letter.Item_ID != 2 || letter.Kind != "story" || letter.Reason != models.DeadLetterSchemaViolation ||
+		!strings.Contains(string(letter.Payload), `"headline":"Drifted"`) || len(letter.Errors) != 2 {
+		t.Errorf("Expected story 2 dead-lettered with its raw payload and 2 violations, got %+v", letter)
+	}
+
+	t.Setenv("HN_SCHEMA_VALIDATION", "false")
+	if _, err := services.NewStoryApiService(client).FetchByID(context.Background(), 2); err != nil {
+		t.Errorf("Expected validation to be disabled, got %v", err)
+	}
+}
+
+func TestDeadLetterRepositoryCountsRepeatedPayloads(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewDeadLetterRepository()
+	letter := func() *models.DeadLetter {
+		return &models.DeadLetter{
+			Item_ID: 42,
+			Kind:    "story",
+			Reason:  models.DeadLetterSchemaViolation,
+			Errors:  []string{"/title: missing"},
+			Payload: []byte(`{"id":42,"type":"story"}`),
+		}
+	}
+	first := letter()
+	if err := repo.Record(ctx, first); err != nil {
+		t.Fatalf("Failed to record dead letter: %v", err)
+	}
+	again := letter()
+	if err := repo.Record(ctx, again); err != nil {
+		t.Fatalf("Failed to record dead letter again: %v", err)
+	}
+	if again.ID != first.ID || again.Occurrences != 2 {
+		t.Errorf("Expected the repeated payload to count a second occurrence of %d, got %+v", first.ID, again)
+	}
+
+	letters, err := repo.List(ctx, "story", 10)
+	if err != nil {
+		t.Fatalf("Failed to list dead letters: %v", err)
+	}
+	if len(letters) != 1 || letters[0].Errors[0] != "/title: missing" || string(letters[0].Payload) != `{"id":42,"type":"story"}` {
+		t.Errorf("Expected the dead letter with its payload as served, got %+v", letters)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/indexing"
+	"internship-project/internal/opensearch"
+	"internship-project/internal/transport"
+)
+
+// recordingSink keeps the documents indexed by kind, failing the first failFirst calls
+type recordingSink struct {
+	mu        sync.Mutex
+	failFirst int
+	calls     int
+	docs      map[string][]opensearch.Document
+}
+
+func (s *recordingSink) Index(ctx context.Context, kind string, docs []opensearch.Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failFirst {
+		return errors.New("cluster unavailable")
+	}
+	if s.docs == nil {
+		s.docs = make(map[string][]opensearch.Document)
+	}
+	s.docs[kind] = append(s.docs[kind], docs...)
+	return nil
+}
+
+func (s *recordingSink) indexed(kind string) []opensearch.Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]opensearch.Document(nil), s.docs[kind]...)
+}
+
+func TestIndexHandlerLoadsAndSkips(t *testing.T) {
+	load := func(ctx context.Context, kind, id string) (interface{}, error) {
+		if id == "404" {
+			return nil, sql.ErrNoRows
+		}
+		return map[string]string{"kind": kind, "id": id}, nil
+	}
+	sink := &recordingSink{}
+	handler := indexing.IndexHandler("story", load, sink)
+	for _, value := range []string{"12", "404", "not-an-id"} {
+		if err := handler(context.Background(), transport.Message{Topic: "StoriesTopic", Value: []byte(value)}); err != nil {
+			t.Errorf("Expected %q to be handled, got %v", value, err)
+		}
+	}
+	docs := sink.indexed("story")
+	if len(docs) != 1 || docs[0].ID != "12" {
+		t.Fatalf("Expected only story 12 indexed, got %+v", docs)
+	}
+
+	failing := indexing.IndexHandler("story", func(ctx context.Context, kind, id string) (interface{}, error) {
+		return nil, errors.New("connection reset")
+	}, sink)
+	if err := failing(context.Background(), transport.Message{Value: []byte("13")}); err == nil {
+		t.Error("Expected a load failure to be returned for redelivery")
+	}
+}
+
+func TestConsumerGroupDispatchesByTopic(t *testing.T) {
+	t.Setenv("EVENT_TRANSPORT", "memory")
+	t.Setenv("INDEXER_RESTART_BACKOFF", "10ms")
+
+	// Published before the groups exist, the messages wait in the backlog of their topics
+	publisher := transport.NewMemoryPublisher()
+	ctx := context.Background()
+	if err := publisher.Publish(ctx, "IndexingTestStories", []byte("1"), []byte("2")); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if err := publisher.Publish(ctx, "IndexingTestUsers", []byte("pg")); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	load := func(ctx context.Context, kind, id string) (interface{}, error) { return id, nil }
+	sink := &recordingSink{failFirst: 1}
+	group := indexing.NewConsumerGroup([]indexing.Group{
+		{Name: "indexing_test_stories", Topic: "IndexingTestStories"},
+		{Name: "indexing_test_users", Topic: "IndexingTestUsers"},
+		{Name: "indexing_test_unhandled", Topic: "IndexingTestOther"},
+	})
+	group.Handle("IndexingTestStories", indexing.IndexHandler("story", load, sink))
+	group.Handle("IndexingTestUsers", indexing.IndexHandler("user", load, sink))
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- group.Run(runCtx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.indexed("story")) < 2 || len(sink.indexed("user")) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the documents, got %+v", sink.docs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if users := sink.indexed("user"); users[0].ID != "pg" {
+		t.Errorf("Expected user pg indexed, got %+v", users)
+	}
+
+	stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Run to return once cancelled")
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestUnknownItemFieldsAreKeptAsExtra(t *testing.T) {
+	payload := `{"id":1,"type":"story","by":"pg","time":1700000000,"title":"Hi","url":"https://example.com",
+		"score":3,"flagged":true,"labels":["new","hot"]}`
+	var item models.HNItem
+	if err := json.Unmarshal([]byte(payload), &item); err != nil {
+		t.Fatalf("Failed to decode item: %v", err)
+	}
+	if item.Title != "Hi" || item.Score != 3 {
+		t.Errorf("Expected the known fields to be decoded, got %+v", item)
+	}
+	if len(item.Extra) != 2 || string(item.Extra["flagged"]) != "true" || string(item.Extra["labels"]) != `["new","hot"]` {
+		t.Fatalf("Expected only flagged and labels in extra, got %v", item.Extra)
+	}
+
+	story := item.ToStory()
+	if string(story.Extra["flagged"]) != "true" {
+		t.Errorf("Expected the story to keep the extra attributes, got %v", story.Extra)
+	}
+
+	var known models.HNItem
+	if err := json.Unmarshal([]byte(`{"id":2,"type":"comment","by":"pg","time":1,"text":"x","parent":1}`), &known); err != nil {
+		t.Fatalf("Failed to decode item: %v", err)
+	}
+	if known.Extra != nil {
+		t.Errorf("Expected no extra attributes, got %v", known.Extra)
+	}
+}
+
+func TestAttributesValueAndScan(t *testing.T) {
+	if v, err := models.Attributes(nil).Value(); err != nil || v != "{}" {
+		t.Errorf("Expected empty attributes to be stored as {}, got %v (%v)", v, err)
+	}
+
+	attributes := models.Attributes{"flagged": json.RawMessage(`true`)}
+	v, err := attributes.Value()
+	if err != nil {
+		t.Fatalf("Failed to encode attributes: %v", err)
+	}
+	var scanned models.Attributes
+	if err := scanned.Scan([]byte(v.(string))); err != nil {
+		t.Fatalf("Failed to scan attributes: %v", err)
+	}
+	if string(scanned["flagged"]) != "true" {
+		t.Errorf("Expected the attributes to round-trip, got %v", scanned)
+	}
+
+	if err := scanned.Scan("{}"); err != nil || scanned != nil {
+		t.Errorf("Expected {} to scan as nil, got %v (%v)", scanned, err)
+	}
+	if err := scanned.Scan(42); err == nil {
+		t.Error("Expected an error scanning a number")
+	}
+
+	if (repository.ItemFilter{ExtraKey: "flagged"}).IsEmpty() {
+		t.Error("Expected a filter on an extra attribute not to be empty")
+	}
+}
+
+func TestItemExtraRoundTripAndFilters(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+	author := fmt.Sprintf("extra%d", id)
+	story := &models.Story{
+		ID:           id,
+		Type:         "story",
+		Title:        "Extra attributes",
+		Author:       author,
+		Created_At:   time.Now().Unix(),
+		Comments_ids: []int{},
+		Extra:        models.Attributes{"flagged": json.RawMessage(`true`), "labels": json.RawMessage(`["new"]`)},
+	}
+	plain := &models.Story{ID: id + 1, Type: "story", Title: "Plain", Author: author, Created_At: story.Created_At, Comments_ids: []int{}}
+	storyRepo := postgres.NewStoryRepository()
+	if _, err := storyRepo.UpsertBatch(ctx, []*models.Story{story, plain}); err != nil {
+		t.Fatalf("Failed to save stories: %v", err)
+	}
+	defer storyRepo.DeleteByAuthor(ctx, author)
+
+	stored, err := storyRepo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get story: %v", err)
+	}
+	if string(stored.Extra["flagged"]) != "true" || string(stored.Extra["labels"]) != `["new"]` {
+		t.Errorf("Expected the extra attributes to be stored, got %v", stored.Extra)
+	}
+	if stored, err := storyRepo.GetByID(ctx, id+1); err != nil || stored.Extra != nil {
+		t.Errorf("Expected no extra attributes, got %v (%v)", stored, err)
+	}
+
+	repo := postgres.NewItemRepository()
+	filter := repository.ItemFilter{Author: author, Extra: models.Attributes{"labels": json.RawMessage(`["new"]`)}}
+	if ids, err := repo.GetIDs(ctx, []string{"story"}, filter, 10); err != nil || len(ids) != 1 || ids[0] != id {
+		t.Errorf("Expected only story %d to contain the label, got %v (%v)", id, ids, err)
+	}
+	filter = repository.ItemFilter{Author: author, ExtraKey: "flagged"}
+	if ids, err := repo.GetIDs(ctx, []string{"story"}, filter, 10); err != nil || len(ids) != 1 || ids[0] != id {
+		t.Errorf("Expected only story %d to have the attribute, got %v (%v)", id, ids, err)
+	}
+
+	counts, err := repo.CountExtraKeys(ctx, "story")
+	if err != nil {
+		t.Fatalf("Failed to count extra keys: %v", err)
+	}
+	if counts["flagged"] < 1 || counts["labels"] < 1 {
+		t.Errorf("Expected the attributes to be counted, got %v", counts)
+	}
+}
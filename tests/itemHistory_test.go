@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestRewindItem(t *testing.T) {
+	story := &models.Story{ID: 1, Type: "story", Title: "Now", URL: "https://example.com", Score: 50, Comments_ids: []int{3, 2}}
+	revisions := []*models.ItemRevision{
+		{Operation: "update", Changed_At: 3000, Previous: map[string]json.RawMessage{
+			"score": json.RawMessage(`20`), "comments_ids": json.RawMessage(`[2]`), "tenant": json.RawMessage(`"default"`)}},
+		{Operation: "update", Changed_At: 2000, Previous: map[string]json.RawMessage{
+			"title": json.RawMessage(`"Then"`), "score": json.RawMessage(`5`), "url": json.RawMessage(`null`)}},
+		{Operation: "insert", Changed_At: 1000},
+	}
+
+	if err := models.RewindItem(story, revisions[:1]); err != nil {
+		t.Fatalf("Failed to rewind: %v", err)
+	}
+	if story.Title != "Now" || story.Score != 20 || !reflect.DeepEqual(story.Comments_ids, []int{2}) {
+		t.Errorf("Expected the score and comments before the last change, got %+v", story)
+	}
+	if err := models.RewindItem(story, revisions[1:]); err != nil {
+		t.Fatalf("Failed to rewind: %v", err)
+	}
+	if story.Title != "Then" || story.Score != 5 || story.URL != "" {
+		t.Errorf("Expected the first saved state, got %+v", story)
+	}
+
+	bad := []*models.ItemRevision{{Previous: map[string]json.RawMessage{"score": json.RawMessage(`"high"`)}}}
+	if err := models.RewindItem(story, bad); err == nil {
+		t.Error("Expected an error for a value of the wrong type")
+	}
+	if err := models.RewindItem(*story, nil); err == nil {
+		t.Error("Expected an error for a non-pointer item")
+	}
+}
+
+func TestRewindItemWithoutPreviousValues(t *testing.T) {
+	story := &models.Story{ID: 1, Type: "story", Title: "Now", Score: 50}
+
+	// An update that replaced nothing logs an empty set of values
+	touched := []*models.ItemRevision{{Operation: "update", Changed_At: 3000, Previous: map[string]json.RawMessage{}}}
+	if err := models.RewindItem(story, touched); err != nil || story.Score != 50 {
+		t.Fatalf("Expected an unchanged story, got %+v, %v", story, err)
+	}
+
+	// Updates logged before the values were kept have none at all
+	unlogged := []*models.ItemRevision{
+		{Operation: "update", Changed_At: 3000, Previous: map[string]json.RawMessage{"score": json.RawMessage(`20`)}},
+		{Operation: "update", Changed_At: 2000},
+	}
+	err := models.RewindItem(story, unlogged)
+	if !errors.Is(err, models.ErrHistoryUnavailable) {
+		t.Fatalf("Expected ErrHistoryUnavailable, got %v", err)
+	}
+	if want := "history not available before 1970-01-01T00:00:02Z"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestItemHistoryRebuildsPastState(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	story := &models.Story{
+		ID:         910000000 + rand.Intn(1000000),
+		Type:       "story",
+		Title:      "History test",
+		Score:      1,
+		Author:     "testuser",
+		Created_At: time.Now().Unix(),
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	defer stories.Delete(ctx, story.ID)
+	saved := *story
+
+	time.Sleep(5 * time.Millisecond)
+	before := time.Now().UnixMilli()
+	story.Title = "History test, renamed"
+	story.Score = 42
+	if err := stories.Update(ctx, story); err != nil {
+		t.Fatalf("Failed to update story: %v", err)
+	}
+
+	changes := postgres.NewChangeRepository()
+	revisions, err := changes.GetItemHistory(ctx, "story", story.ID, before)
+	if err != nil {
+		t.Fatalf("Failed to get item history: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Operation != "update" {
+		t.Fatalf("Expected the update alone, got %+v", revisions)
+	}
+	if _, ok := revisions[0].Previous["author"]; ok {
+		t.Errorf("Expected only the changed columns, got %s", revisions[0].Previous)
+	}
+
+	current, err := stories.GetByID(ctx, story.ID)
+	if err != nil {
+		t.Fatalf("Failed to get story: %v", err)
+	}
+	if err := models.RewindItem(current, revisions); err != nil {
+		t.Fatalf("Failed to rewind story: %v", err)
+	}
+	if current.Title != saved.Title || current.Score != saved.Score {
+		t.Errorf("Expected %q with score %d, got %+v", saved.Title, saved.Score, current)
+	}
+
+	all, err := changes.GetItemHistory(ctx, "story", story.ID, 0)
+	if err != nil || len(all) != 2 || all[1].Operation != "insert" || len(all[1].Previous) != 0 {
+		t.Errorf("Expected the update then the insert, newest first, got %+v (%v)", all, err)
+	}
+}
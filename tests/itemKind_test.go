@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestGetItemKind(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+
+	comment := &models.Comment{
+		ID:         id,
+		Type:       "comment",
+		Text:       "Item kind lookup test",
+		Author:     "testuser",
+		Created_At: time.Now().Unix(),
+		Parent:     1,
+		Replies:    []int{},
+	}
+	if err := postgres.NewCommentRepository().CreateBatchWithExistingIDs(ctx, []*models.Comment{comment}); err != nil {
+		t.Fatalf("Failed to create comment: %v", err)
+	}
+	defer postgres.NewCommentRepository().Delete(ctx, id)
+
+	repo := postgres.NewItemRepository()
+	kind, err := repo.GetKind(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get item kind: %v", err)
+	}
+	if kind != "comment" {
+		t.Errorf("Expected kind comment, got %s", kind)
+	}
+
+	if _, err := repo.GetKind(ctx, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing item, got %v", err)
+	}
+}
+
+func TestGetItemIDsByFilter(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+	author := "refetch-test-author"
+	created := time.Now().Unix()
+
+	comments := []*models.Comment{
+		{ID: id, Type: "comment", Text: "first", Author: author, Created_At: created, Parent: 1, Replies: []int{}},
+		{ID: id + 1, Type: "comment", Text: "second", Author: author, Created_At: created, Parent: 1, Replies: []int{}},
+	}
+	if err := postgres.NewCommentRepository().CreateBatchWithExistingIDs(ctx, comments); err != nil {
+		t.Fatalf("Failed to create comments: %v", err)
+	}
+	defer postgres.NewCommentRepository().DeleteByAuthor(ctx, author)
+
+	repo := postgres.NewItemRepository()
+	filter := repository.ItemFilter{Author: author, Start: created}
+	ids, err := repo.GetIDs(ctx, []string{"story", "comment"}, filter, 10)
+	if err != nil {
+		t.Fatalf("Failed to get item IDs: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != id || ids[1] != id+1 {
+		t.Errorf("Expected IDs %d and %d, got %v", id, id+1, ids)
+	}
+
+	if ids, err := repo.GetIDs(ctx, []string{"comment"}, filter, 1); err != nil || len(ids) != 1 {
+		t.Errorf("Expected the limit to apply, got %v (%v)", ids, err)
+	}
+	if _, err := repo.GetIDs(ctx, []string{"user"}, filter, 1); !errors.Is(err, postgres.ErrUnknownKind) {
+		t.Errorf("Expected ErrUnknownKind, got %v", err)
+	}
+}
+
+func TestGetItemKindsAndFilterByIDs(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+
+	comment := &models.Comment{
+		ID:         id,
+		Type:       "comment",
+		Text:       "Batch get test",
+		Author:     "testuser",
+		Created_At: time.Now().Unix(),
+		Parent:     1,
+		Replies:    []int{},
+	}
+	if err := postgres.NewCommentRepository().CreateBatchWithExistingIDs(ctx, []*models.Comment{comment}); err != nil {
+		t.Fatalf("Failed to create comment: %v", err)
+	}
+	defer postgres.NewCommentRepository().Delete(ctx, id)
+
+	kinds, err := postgres.NewItemRepository().GetKinds(ctx, []int{id, -1})
+	if err != nil {
+		t.Fatalf("Failed to get item kinds: %v", err)
+	}
+	if len(kinds) != 1 || kinds[id] != "comment" {
+		t.Errorf("Expected only %d as a comment, got %v", id, kinds)
+	}
+
+	comments, err := postgres.NewCommentRepository().GetByFilter(ctx, repository.ItemFilter{IDs: []int{id, -1}})
+	if err != nil {
+		t.Fatalf("Failed to get comments by IDs: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != id {
+		t.Errorf("Expected comment %d, got %v", id, comments)
+	}
+	if stories, err := postgres.NewStoryRepository().GetByFilter(ctx, repository.ItemFilter{IDs: []int{id}}); err != nil || len(stories) != 0 {
+		t.Errorf("Expected no story with ID %d, got %v (%v)", id, stories, err)
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+// recordingPayloadSink keeps the item payloads in memory
+type recordingPayloadSink struct {
+	mu       sync.Mutex
+	payloads []*models.ItemPayload
+}
+
+func (s *recordingPayloadSink) Save(ctx context.Context, payload *models.ItemPayload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.payloads = append(s.payloads, payload)
+	return nil
+}
+
+func TestDecodedItemPayloadsAreKept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item/1.json":
+			w.Write([]byte(`{"id":1,"type":"story","by":"pg","time":1700000000,"title":"Fine","score":3}`))
+		case "/item/2.json":
+			w.Write([]byte(`{"id":2,"type":"story","author":"pg","time":1700000000,"headline":"Drifted","score":3}`))
+		default:
+			w.Write([]byte(`null`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+
+	ctx := context.Background()
+	client := services.NewHackerNewsApiClient()
+	client.SetDeadLetterSink(&recordingDeadLetterSink{})
+	sink := &recordingPayloadSink{}
+	client.SetPayloadSink(sink)
+
+	t.Setenv("ITEM_RAW_PAYLOADS_ENABLED", "false")
+	if _, err := client.GetHNItem(ctx, 1); err != nil {
+		t.Fatalf("Failed to get item 1: %v", err)
+	}
+	if len(sink.payloads) != 0 {
+		t.Fatalf("Expected no payload kept while disabled, got %d", len(sink.payloads))
+	}
+
+	t.Setenv("ITEM_RAW_PAYLOADS_ENABLED", "true")
+	if _, err := client.GetHNItem(ctx, 1); err != nil {
+		t.Fatalf("Failed to get item 1: %v", err)
+	}
+	if _, err := client.GetHNItem(ctx, 2); !errors.Is(err, services.ErrSchemaViolation) {
+		t.Fatalf("Expected item 2 to fail schema validation, got %v", err)
+	}
+	if _, err := client.GetHNItem(ctx, 3); !errors.Is(err, services.ErrItemGone) {
+		t.Fatalf("Expected item 3 to be gone, got %v", err)
+	}
+	if len(sink.payloads) != 1 {
+		t.Fatalf("Expected only the payload of item 1 to be kept, got %d", len(sink.payloads))
+	}
+	payload := sink.payloads[0]
+	if payload.Item_ID != 1 || payload.Kind != "story" || payload.Fetched_At == 0 {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+
+	// The kept payload decodes to the same item without a request
+	item, err := client.DecodeHNItem(ctx, 1, payload.Payload)
+	if err != nil || item.Title != "Fine" || item.By != "pg" {
+		t.Errorf("Expected the kept payload to decode, got %+v (%v)", item, err)
+	}
+}
+
+func TestItemPayloadsRoundTrip(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewItemPayloadRepository()
+	first := &models.ItemPayload{
+		Item_ID:    990001,
+		Kind:       "story",
+		Payload:    json.RawMessage(`{"id":990001,"type":"story","title":"Before"}`),
+		Fetched_At: time.Now().Unix(),
+	}
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Failed to save payload: %v", err)
+	}
+	second := *first
+	second.Payload = json.RawMessage(`{"id":990001,"type":"story","title":"After"}`)
+	second.Fetched_At = first.Fetched_At + 1
+	if err := repo.Save(ctx, &second); err != nil {
+		t.Fatalf("Failed to replace payload: %v", err)
+	}
+
+	stored, err := repo.GetByID(ctx, first.Item_ID)
+	if err != nil {
+		t.Fatalf("Failed to get payload: %v", err)
+	}
+	if string(stored.Payload) != string(second.Payload) || stored.Fetched_At != second.Fetched_At {
+		t.Errorf("Expected the payload to be replaced, got %+v", stored)
+	}
+
+	if _, err := repo.GetByID(ctx, 990002); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for an item without payload, got %v", err)
+	}
+	payloads, err := repo.GetByIDs(ctx, []int{first.Item_ID, 990002})
+	if err != nil {
+		t.Fatalf("Failed to get payloads: %v", err)
+	}
+	if len(payloads) != 1 || payloads[first.Item_ID] == nil {
+		t.Errorf("Expected only the stored payload, got %v", payloads)
+	}
+}
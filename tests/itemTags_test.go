@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+	"internship-project/internal/search"
+)
+
+// fakeTagStore keeps the tags of each item in memory
+type fakeTagStore struct {
+	tags map[string][]string
+}
+
+func (f *fakeTagStore) ReplaceTags(ctx context.Context, kind string, id int, tags []string) error {
+	f.tags[fmt.Sprintf("%s:%d", kind, id)] = tags
+	return nil
+}
+
+func (f *fakeTagStore) GetTagCounts(ctx context.Context, tenant string, limit int) ([]*models.TagCount, error) {
+	return nil, nil
+}
+
+func TestVocabularyExtractsTags(t *testing.T) {
+	t.Setenv("TAGS_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+	vocabulary, err := etl.DefaultVocabulary()
+	if err != nil {
+		t.Fatalf("Failed to load vocabulary: %v", err)
+	}
+
+	cases := map[string][]string{
+		"Show HN: A PostgreSQL extension written in Rust":     {"postgres", "rust", "show-hn"},
+		"Why we moved from MySQL to Postgres.":                {"mysql", "postgres"},
+		"Running LLMs locally with Node.js":                   {"ai", "javascript"},
+		"Machine learning on Kubernetes (k8s) clusters":       {"ai", "kubernetes"},
+		"Trust, rusty tools and gopher games":                 {},
+		"Ask HN: What is your favorite Golang web framework?": {"ask-hn", "go"},
+	}
+	for title, want := range cases {
+		if got := vocabulary.Extract(title); !reflect.DeepEqual(got, want) {
+			t.Errorf("Extract(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+func TestVocabularyReadsTagsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.txt")
+	content := "# extra tags\n\nopensearch: elasticsearch, open search\nzig\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TAGS_FILE", path)
+
+	vocabulary, err := etl.DefaultVocabulary()
+	if err != nil {
+		t.Fatalf("Failed to load vocabulary: %v", err)
+	}
+	got := vocabulary.Extract("Open Search vs Elasticsearch, benchmarked in Zig and Rust")
+	if want := []string{"opensearch", "rust", "zig"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected tags %v, got %v", want, got)
+	}
+}
+
+func TestTaggerStoresTitleTags(t *testing.T) {
+	store := &fakeTagStore{tags: map[string][]string{}}
+	vocabulary := etl.Vocabulary{}
+	vocabulary.Add("sqlite", "sqlite3")
+	tagger := etl.NewTagger(store, vocabulary)
+	pipeline := etl.NewPipeline(tagger)
+
+	pipeline.PostPersist(context.Background(), &models.Story{ID: 1, Title: "SQLite3 internals"})
+	pipeline.PostPersist(context.Background(), &models.Job{ID: 2, Title: "Hiring"})
+	pipeline.PostPersist(context.Background(), &models.Comment{ID: 3, Text: "sqlite"})
+
+	if got := store.tags["story:1"]; !reflect.DeepEqual(got, []string{"sqlite"}) {
+		t.Errorf("Expected story tagged sqlite, got %v", got)
+	}
+	if got, ok := store.tags["job:2"]; !ok || len(got) != 0 {
+		t.Errorf("Expected the job's tags cleared, got %v (stored: %v)", got, ok)
+	}
+	if _, ok := store.tags["comment:3"]; ok {
+		t.Error("Expected comments not to be tagged")
+	}
+}
+
+func TestSearchTagFacetAndFilter(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read search body: %v", err)
+		}
+		body = string(raw)
+		w.Write([]byte(`{
+			"hits": {"total": {"value": 3}, "hits": []},
+			"aggregations": {
+				"types": {"buckets": [{"key": "stories", "doc_count": 3}]},
+				"tags": {"buckets": [{"key": "postgres", "doc_count": 3}, {"key": "rust", "doc_count": 1}]}
+			}
+		}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+
+	result, err := search.NewClient().Search(context.Background(), search.Request{
+		Query: "database",
+		Kinds: []string{"story"},
+		Tag:   "postgres",
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if !strings.Contains(body, `{"term":{"tags":"postgres"}}`) {
+		t.Errorf("Expected a tag filter in the query, got %s", body)
+	}
+	if result.Tags["postgres"] != 3 || result.Tags["rust"] != 1 {
+		t.Errorf("Unexpected tag facet %v", result.Tags)
+	}
+}
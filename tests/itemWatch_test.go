@@ -0,0 +1,307 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/watch"
+	"internship-project/pkg/database"
+)
+
+// fakeWatchStore serves fixed watches and records their notified states
+type fakeWatchStore struct {
+	watches []*models.Watch
+	updated map[string]models.WatchState
+}
+
+func (f *fakeWatchStore) Create(ctx context.Context, tenant string, w *models.Watch) error {
+	return nil
+}
+
+func (f *fakeWatchStore) Delete(ctx context.Context, tenant string, id string) error { return nil }
+
+func (f *fakeWatchStore) GetByTenant(ctx context.Context, tenant string) ([]*models.Watch, error) {
+	return f.watches, nil
+}
+
+func (f *fakeWatchStore) GetByItem(ctx context.Context, itemID int) ([]*models.Watch, error) {
+	var watches []*models.Watch
+	for _, w := range f.watches {
+		if w.Item_ID == itemID {
+			watches = append(watches, w)
+		}
+	}
+	return watches, nil
+}
+
+func (f *fakeWatchStore) GetRankWatches(ctx context.Context) ([]*models.Watch, error) {
+	var watches []*models.Watch
+	for _, w := range f.watches {
+		if w.Rank_Delta > 0 {
+			watches = append(watches, w)
+		}
+	}
+	return watches, nil
+}
+
+func (f *fakeWatchStore) UpdateState(ctx context.Context, id string, state models.WatchState, notifiedAt int64) error {
+	f.updated[id] = state
+	return nil
+}
+
+// fakeDeliveryStore keeps the delivery log in memory
+type fakeDeliveryStore struct {
+	deliveries []*models.WebhookDelivery
+}
+
+func (f *fakeDeliveryStore) Create(ctx context.Context, d *models.WebhookDelivery) error {
+	d.ID = database.NewUUIDv7()
+	d.Status = models.DeliveryPending
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+// find returns the delivery with the ID, or nil
+func (f *fakeDeliveryStore) find(id string) *models.WebhookDelivery {
+	for _, d := range f.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeliveryStore) RecordAttempt(ctx context.Context, id string, responseCode int, lastError string) error {
+	d := f.find(id)
+	d.Attempts++
+	d.Response_Code, d.Last_Error, d.Status = responseCode, lastError, models.DeliveryDelivered
+	if lastError != "" {
+		d.Status = models.DeliveryFailed
+	}
+	return nil
+}
+
+func (f *fakeDeliveryStore) GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	found := f.find(id)
+	if found == nil {
+		return nil, sql.ErrNoRows
+	}
+	d := *found
+	return &d, nil
+}
+
+func (f *fakeDeliveryStore) List(ctx context.Context, status string, limit int) ([]*models.WebhookDelivery, error) {
+	return f.deliveries, nil
+}
+
+func TestWatchChanges(t *testing.T) {
+	w := &models.Watch{Score_Delta: 10, Rank_Delta: 5, Last: models.WatchState{Score: 100, Comments: 3, Rank: 12}}
+
+	if changes := w.Changes(models.WatchState{Score: 109, Comments: 300, Rank: 8}); len(changes) != 0 {
+		t.Errorf("Expected no changes below the deltas, got %+v", changes)
+	}
+	changes := w.Changes(models.WatchState{Score: 90, Comments: 3, Rank: 12})
+	if len(changes) != 1 || changes[0] != (models.WatchChange{Field: "score", Previous: 100, Current: 90}) {
+		t.Errorf("Expected a score drop, got %+v", changes)
+	}
+	changes = w.Changes(models.WatchState{Score: 100, Comments: 3, Rank: 0})
+	if len(changes) != 1 || changes[0].Field != "rank" {
+		t.Errorf("Expected leaving the top stories to be a rank change, got %+v", changes)
+	}
+}
+
+func TestWatcherCallsWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []models.WatchEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if got := r.Header.Get(watch.SignatureHeader); got != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Unexpected signature %q", got)
+		}
+		var event models.WatchEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("Failed to decode event: %v", err)
+		}
+		if key := r.Header.Get(watch.IdempotencyKeyHeader); key == "" || key != event.Idempotency_Key {
+			t.Errorf("Expected the idempotency key %q of the event in the header, got %q", event.Idempotency_Key, key)
+		}
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("WATCH_WEBHOOK_SECRET", "s3cret")
+
+	store := &fakeWatchStore{
+		updated: map[string]models.WatchState{},
+		watches: []*models.Watch{
+			{ID: "1", Kind: "story", Item_ID: 7, Webhook_URL: server.URL + "/ok", Comments_Delta: 5, Last: models.WatchState{Comments: 10}},
+			{ID: "2", Kind: "story", Item_ID: 7, Webhook_URL: server.URL + "/broken", Score_Delta: 1},
+			{ID: "3", Kind: "story", Item_ID: 8, Webhook_URL: server.URL + "/ok", Rank_Delta: 3, Last: models.WatchState{Rank: 20}},
+		},
+	}
+	deliveries := &fakeDeliveryStore{}
+	notifier := watch.NewNotifier(store, deliveries)
+
+	err := etl.NewWatcher(notifier).PostPersist(context.Background(), &models.Story{ID: 7, Score: 4, Comments_count: 15})
+	if err == nil {
+		t.Error("Expected the failing webhook to be reported")
+	}
+	if state, ok := store.updated["1"]; !ok || state.Comments != 15 || state.Score != 4 {
+		t.Errorf("Expected watch 1 notified with the new state, got %+v (%v)", state, ok)
+	}
+	if _, ok := store.updated["2"]; ok {
+		t.Error("Expected watch 2 to keep its state after a failed webhook call")
+	}
+
+	if err := notifier.RanksChanged(context.Background(), map[int]int{8: 2, 9: 1}); err != nil {
+		t.Fatalf("Failed to evaluate ranks: %v", err)
+	}
+	if state := store.updated["3"]; state.Rank != 2 {
+		t.Errorf("Expected watch 3 notified at rank 2, got %+v", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 webhook calls, got %d", len(events))
+	}
+	last := events[2]
+	if last.Watch_ID != "3" || last.Item_ID != 8 || len(last.Changes) != 1 ||
+		last.Changes[0] != (models.WatchChange{Field: "rank", Previous: 20, Current: 2}) {
+		t.Errorf("Unexpected rank event %+v", last)
+	}
+
+	// Every call is logged, the failed one with the response code of the webhook
+	if len(deliveries.deliveries) != 3 {
+		t.Fatalf("Expected 3 logged deliveries, got %d", len(deliveries.deliveries))
+	}
+	statuses := map[string]string{}
+	for _, d := range deliveries.deliveries {
+		statuses[d.Watch_ID] = d.Status
+		if d.Attempts != 1 {
+			t.Errorf("Expected one attempt for delivery %s, got %d", d.ID, d.Attempts)
+		}
+	}
+	if statuses["1"] != models.DeliveryDelivered || statuses["2"] != models.DeliveryFailed || statuses["3"] != models.DeliveryDelivered {
+		t.Errorf("Unexpected delivery statuses %v", statuses)
+	}
+}
+
+func TestRedeliverWebhookKeepsIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(watch.IdempotencyKeyHeader))
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	store := &fakeWatchStore{
+		updated: map[string]models.WatchState{},
+		watches: []*models.Watch{{ID: "1", Kind: "story", Item_ID: 7, Webhook_URL: server.URL, Score_Delta: 1}},
+	}
+	deliveries := &fakeDeliveryStore{}
+	notifier := watch.NewNotifier(store, deliveries)
+	ctx := context.Background()
+
+	if err := notifier.ItemChanged(ctx, 7, 5, 0); err == nil {
+		t.Fatal("Expected the failing webhook to be reported")
+	}
+	failed, _ := deliveries.GetByID(ctx, deliveries.deliveries[0].ID)
+	if failed.Status != models.DeliveryFailed || failed.Response_Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a failed delivery with status 503, got %+v", failed)
+	}
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	delivery, err := notifier.Redeliver(ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("Failed to redeliver: %v", err)
+	}
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 2 || delivery.Response_Code != http.StatusOK {
+		t.Errorf("Expected a delivered second attempt, got %+v", delivery)
+	}
+	if _, err := notifier.Redeliver(ctx, database.NewUUIDv7()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected an unknown delivery to be reported, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected both attempts to carry the same idempotency key, got %v", keys)
+	}
+}
+
+func TestWebhookDeliveryRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	repo := postgres.NewWebhookDeliveryRepository()
+	ctx := context.Background()
+
+	delivery := &models.WebhookDelivery{
+		Idempotency_Key: "key-1",
+		Watch_ID:        database.NewUUIDv7(),
+		URL:             "https://example.com/hook",
+		Payload:         json.RawMessage(`{"idempotency_key":"key-1"}`),
+	}
+	if err := repo.Create(ctx, delivery); err != nil {
+		t.Fatalf("Failed to record delivery: %v", err)
+	}
+	if delivery.ID == "" || delivery.Status != models.DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("Unexpected new delivery %+v", delivery)
+	}
+	if err := repo.Create(ctx, &models.WebhookDelivery{Idempotency_Key: "key-1", URL: "x", Payload: json.RawMessage("{}")}); err == nil {
+		t.Error("Expected a duplicate idempotency key to be rejected")
+	}
+
+	if err := repo.RecordAttempt(ctx, delivery.ID, 500, "webhook returned 500"); err != nil {
+		t.Fatalf("Failed to record attempt: %v", err)
+	}
+	if err := repo.RecordAttempt(ctx, delivery.ID, 204, ""); err != nil {
+		t.Fatalf("Failed to record attempt: %v", err)
+	}
+	got, err := repo.GetByID(ctx, delivery.ID)
+	if err != nil {
+		t.Fatalf("Failed to load delivery: %v", err)
+	}
+	if got.Status != models.DeliveryDelivered || got.Attempts != 2 || got.Response_Code != 204 || got.Last_Error != "" {
+		t.Errorf("Unexpected delivery after two attempts %+v", got)
+	}
+	if string(got.Payload) != string(delivery.Payload) {
+		t.Errorf("Expected the payload kept as sent, got %s", got.Payload)
+	}
+
+	failed, err := repo.List(ctx, models.DeliveryFailed, 10)
+	if err != nil || len(failed) != 0 {
+		t.Errorf("Expected no failed deliveries, got %v (%v)", failed, err)
+	}
+	if _, err := repo.GetByID(ctx, database.NewUUIDv7()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
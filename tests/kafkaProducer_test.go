@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	kafkaconfig "internship-project/internal/kafka"
+	"internship-project/internal/transport"
+)
+
+func TestNewKafkaWriterSettings(t *testing.T) {
+	writer, err := transport.NewKafkaWriter(kafkaconfig.KafkaConfig{
+		BootstrapServers: "a:9092,b:9092",
+		Acks:             "1",
+		Compression:      "ZSTD",
+		BatchSize:        500,
+		BatchBytes:       2 << 20,
+		Linger:           10 * time.Millisecond,
+		WriteTimeout:     5 * time.Second,
+		MaxAttempts:      3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if writer.Compression != kafka.Zstd || writer.RequiredAcks != kafka.RequireOne || writer.BatchSize != 500 ||
+		writer.BatchBytes != 2<<20 || writer.BatchTimeout != 10*time.Millisecond || writer.WriteTimeout != 5*time.Second ||
+		writer.MaxAttempts != 3 || writer.Async || writer.Completion != nil {
+		t.Errorf("Unexpected writer settings %+v", writer)
+	}
+	writer.Close()
+
+	writer, err = transport.NewKafkaWriter(kafkaconfig.KafkaConfig{BootstrapServers: "a:9092", Acks: "0", Async: true})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if writer.Compression != 0 || writer.RequiredAcks != kafka.RequireNone || !writer.Async || writer.Completion == nil {
+		t.Errorf("Unexpected async writer settings %+v", writer)
+	}
+	writer.Close()
+
+	for _, cfg := range []kafkaconfig.KafkaConfig{
+		{Compression: "brotli"},
+		{Acks: "2"},
+		{BatchSize: -1},
+	} {
+		if _, err := transport.NewKafkaWriter(cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
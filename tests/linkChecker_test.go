@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestLinkCheckerStatuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := services.NewLinkChecker()
+	ctx := context.Background()
+
+	cases := []struct {
+		path   string
+		status string
+		code   int
+	}{
+		{"/ok", models.LinkAlive, http.StatusOK},
+		{"/missing", models.LinkDead, http.StatusNotFound},
+		{"/gone", models.LinkDead, http.StatusGone},
+		{"/no-head", models.LinkAlive, http.StatusOK},
+		{"/broken", models.LinkUnreachable, http.StatusBadGateway},
+	}
+	for _, c := range cases {
+		status, code := checker.Check(ctx, server.URL+c.path)
+		if status != c.status || code != c.code {
+			t.Errorf("%s: expected %s (%d), got %s (%d)", c.path, c.status, c.code, status, code)
+		}
+	}
+
+	if status, _ := checker.Check(ctx, "http://127.0.0.1:1/closed"); status != models.LinkUnreachable {
+		t.Errorf("Expected a refused connection to be unreachable, got %s", status)
+	}
+}
+
+func TestLinkCheckerArchiveSnapshot(t *testing.T) {
+	archive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") == "https://example.com/archived" {
+			w.Write([]byte(`{"archived_snapshots":{"closest":{"available":true,"url":"http://web.archive.org/web/2020/https://example.com/archived"}}}`))
+			return
+		}
+		w.Write([]byte(`{"archived_snapshots":{}}`))
+	}))
+	defer archive.Close()
+	t.Setenv("LINK_CHECK_ARCHIVE_API", archive.URL)
+
+	checker := services.NewLinkChecker()
+	snapshot, err := checker.ArchiveSnapshot(context.Background(), "https://example.com/archived")
+	if err != nil {
+		t.Fatalf("Failed to look up snapshot: %v", err)
+	}
+	if snapshot != "http://web.archive.org/web/2020/https://example.com/archived" {
+		t.Errorf("Unexpected snapshot %q", snapshot)
+	}
+
+	snapshot, err = checker.ArchiveSnapshot(context.Background(), "https://example.com/never")
+	if err != nil || snapshot != "" {
+		t.Errorf("Expected no snapshot, got %q (%v)", snapshot, err)
+	}
+}
+
+func TestLinkPreviewerMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/article":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<!doctype html><html><head>
+				<title>Fallback title</title>
+				<meta name="description" content="Plain description">
+				<meta name="twitter:title" content="Card title">
+				<meta property="og:title" content="Open &amp; Graph">
+				<meta property="og:image" content="/img/cover.png">
+				<meta property="og:site_name" content="Example">
+				</head><body><meta property="og:description" content="Not in the head"></body></html>`))
+		case "/plain":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>  Just a
+				title </title></head></html>`))
+		case "/file.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+		case "/busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	previewer := services.NewLinkPreviewer()
+	ctx := context.Background()
+
+	preview := previewer.Preview(ctx, server.URL+"/article")
+	want := models.LinkPreview{
+		URL: server.URL + "/article", Status: models.PreviewFound, Title: "Open & Graph",
+		Description: "Plain description", ImageURL: server.URL + "/img/cover.png", SiteName: "Example",
+		FetchedAt: preview.FetchedAt,
+	}
+	if *preview != want {
+		t.Errorf("Expected %+v, got %+v", want, *preview)
+	}
+	if preview := previewer.Preview(ctx, server.URL+"/plain"); preview.Status != models.PreviewFound || preview.Title != "Just a title" {
+		t.Errorf("Expected the HTML title, got %+v", preview)
+	}
+
+	statuses := map[string]string{
+		"/file.pdf": models.PreviewNone,
+		"/missing":  models.PreviewNone,
+		"/busy":     models.PreviewFailed,
+	}
+	for path, status := range statuses {
+		if preview := previewer.Preview(ctx, server.URL+path); preview.Status != status {
+			t.Errorf("%s: expected %s, got %s", path, status, preview.Status)
+		}
+	}
+}
+
+func TestLinkPreviewRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	story := &models.Story{ID: 987654, Type: "story", Title: "Previewed", URL: "https://example.com/a",
+		Author: "testuser", Created_At: time.Now().Unix(), Comments_ids: []int{}}
+	if err := postgres.NewStoryRepository().Create(ctx, story); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	repo := postgres.NewLinkPreviewRepository()
+	now := time.Now().Unix()
+
+	pending := func() bool {
+		links, err := repo.GetLinksToPreview(ctx, now-3600, now-60, 1000)
+		if err != nil {
+			t.Fatalf("Failed to load links to preview: %v", err)
+		}
+		for _, link := range links {
+			if link.StoryID == story.ID {
+				return true
+			}
+		}
+		return false
+	}
+	if !pending() {
+		t.Fatal("Expected a story without preview to be listed")
+	}
+
+	preview := &models.LinkPreview{StoryID: story.ID, URL: story.URL, Status: models.PreviewFailed, FetchedAt: now - 30}
+	if err := repo.SavePreviews(ctx, []*models.LinkPreview{preview}); err != nil {
+		t.Fatalf("Failed to save preview: %v", err)
+	}
+	if pending() {
+		t.Error("Expected a recently failed preview not to be retried yet")
+	}
+
+	preview.Status, preview.Title, preview.FetchedAt = models.PreviewFound, "Title", now-120
+	if err := repo.SavePreviews(ctx, []*models.LinkPreview{preview}); err != nil {
+		t.Fatalf("Failed to save preview: %v", err)
+	}
+	if pending() {
+		t.Error("Expected a found preview to be kept until it is old")
+	}
+	got, err := repo.GetByStory(ctx, story.ID)
+	if err != nil || *got != *preview {
+		t.Errorf("Expected %+v, got %+v (%v)", preview, got, err)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"internship-project/internal/loadshed"
+)
+
+func TestLatencyWindowP99(t *testing.T) {
+	w := loadshed.NewLatencyWindow(100, time.Minute)
+	if p99 := w.P99(); p99 != 0 {
+		t.Errorf("Expected 0 without samples, got %v", p99)
+	}
+
+	for i := 1; i <= 100; i++ {
+		w.Observe(time.Duration(i) * time.Millisecond)
+	}
+	if p99 := w.P99(); p99 != 99*time.Millisecond {
+		t.Errorf("Expected p99 of 99ms, got %v", p99)
+	}
+
+	// The oldest samples are overwritten once the window is full
+	for i := 0; i < 100; i++ {
+		w.Observe(time.Millisecond)
+	}
+	if p99 := w.P99(); p99 != time.Millisecond {
+		t.Errorf("Expected p99 of 1ms after overwriting, got %v", p99)
+	}
+}
+
+func TestLatencyWindowExpiresSamples(t *testing.T) {
+	w := loadshed.NewLatencyWindow(10, 20*time.Millisecond)
+	w.Observe(time.Second)
+	time.Sleep(30 * time.Millisecond)
+	if p99 := w.P99(); p99 != 0 {
+		t.Errorf("Expected expired samples to be ignored, got %v", p99)
+	}
+}
+
+func TestGovernorThresholds(t *testing.T) {
+	t.Setenv("LOAD_SHED_P99_THRESHOLD", "100ms")
+	t.Setenv("LOAD_SHED_DB_SATURATION", "0.8")
+
+	latency := loadshed.NewLatencyWindow(10, time.Minute)
+	stats := sql.DBStats{MaxOpenConnections: 10, InUse: 2}
+	g := loadshed.NewGovernor(latency, func() sql.DBStats { return stats })
+
+	if overloaded, reason := g.Overloaded(); overloaded {
+		t.Errorf("Unexpected overload: %s", reason)
+	}
+
+	stats.InUse = 8
+	if overloaded, _ := g.Overloaded(); !overloaded {
+		t.Error("Expected overload at 80% connection use")
+	}
+
+	stats.InUse = 2
+	latency.Observe(150 * time.Millisecond)
+	if overloaded, _ := g.Overloaded(); !overloaded {
+		t.Error("Expected overload above the p99 threshold")
+	}
+}
+
+func TestGovernorWait(t *testing.T) {
+	t.Setenv("LOAD_SHED_P99_THRESHOLD", "100ms")
+	t.Setenv("LOAD_SHED_BASE_DELAY", "5ms")
+	t.Setenv("LOAD_SHED_MAX_DELAY", "10ms")
+	t.Setenv("LOAD_SHED_MAX_WAIT", "30ms")
+
+	latency := loadshed.NewLatencyWindow(10, time.Minute)
+	g := loadshed.NewGovernor(latency, nil)
+	ctx := context.Background()
+
+	if waited := g.Wait(ctx); waited != 0 {
+		t.Errorf("Expected no wait without load, waited %v", waited)
+	}
+
+	// 5ms, 10ms, 10ms, then 5ms to reach the max wait
+	latency.Observe(time.Second)
+	if waited := g.Wait(ctx); waited != 30*time.Millisecond {
+		t.Errorf("Expected writes to be held back for the max wait, waited %v", waited)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if waited := g.Wait(canceled); waited != 0 {
+		t.Errorf("Expected a canceled wait to return at once, waited %v", waited)
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/loadtest"
+	"internship-project/internal/models"
+)
+
+func TestLoadTestReportsThroughput(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	pipeline := loadtest.Pipeline{
+		Write: func(ctx context.Context, batch []*models.Story) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, story := range batch {
+				if !story.IsValid() || story.Author != loadtest.Author || story.ID < loadtest.IDBase {
+					t.Errorf("Unexpected synthetic story %+v", story)
+				}
+				seen[story.ID] = true
+			}
+			return nil
+		},
+		Publish: func(ctx context.Context, batch []*models.Story) error { return nil },
+	}
+
+	report, err := loadtest.Run(context.Background(), loadtest.Options{
+		Rate: 200, Duration: 300 * time.Millisecond, BatchSize: 10, Workers: 2, QueueSize: 4,
+	}, pipeline)
+	if err != nil {
+		t.Fatalf("Failed to run the load test: %v", err)
+	}
+	if report.Generated == 0 || report.Written != report.Generated || report.Failed != 0 {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	if len(seen) != report.Written {
+		t.Errorf("Expected %d distinct IDs, got %d", report.Written, len(seen))
+	}
+	if report.WriteLatency.Count != report.Written/10 || report.PublishLatency.Count != report.WriteLatency.Count {
+		t.Errorf("Expected one latency sample per batch, got %+v", report)
+	}
+	if report.Throughput <= 0 {
+		t.Errorf("Expected a positive throughput, got %f", report.Throughput)
+	}
+}
+
+func TestLoadTestSlowWritesFillTheQueue(t *testing.T) {
+	pipeline := loadtest.Pipeline{
+		Write: func(ctx context.Context, batch []*models.Story) error {
+			time.Sleep(40 * time.Millisecond)
+			return errors.New("database unavailable")
+		},
+	}
+
+	report, err := loadtest.Run(context.Background(), loadtest.Options{
+		Rate: 500, Duration: 200 * time.Millisecond, BatchSize: 5, Workers: 1, QueueSize: 2,
+	}, pipeline)
+	if err != nil {
+		t.Fatalf("Failed to run the load test: %v", err)
+	}
+	if report.Written != 0 || report.Failed != report.Generated || len(report.Errors) == 0 {
+		t.Errorf("Expected every batch to fail, got %+v", report)
+	}
+	if report.MaxQueueDepth != 2 || report.Stalls == 0 {
+		t.Errorf("Expected the slow writer to fill the queue, got max depth %d and %d stalls", report.MaxQueueDepth, report.Stalls)
+	}
+	if report.WriteLatency.P50 < 40 {
+		t.Errorf("Expected the write latency to include the slow writes, got %+v", report.WriteLatency)
+	}
+
+	if _, err := loadtest.Run(context.Background(), loadtest.Options{Rate: 0, Duration: time.Second}, pipeline); err == nil {
+		t.Error("Expected a zero rate to be rejected")
+	}
+}
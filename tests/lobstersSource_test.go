@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestLobstersItemID(t *testing.T) {
+	id, err := services.LobstersItemID("abc123")
+	if err != nil {
+		t.Fatalf("Failed to map short id: %v", err)
+	}
+	if id >= 0 {
+		t.Errorf("Expected a negative item ID, got %d", id)
+	}
+
+	other, err := services.LobstersItemID("abc124")
+	if err != nil {
+		t.Fatalf("Failed to map short id: %v", err)
+	}
+	if other == id {
+		t.Errorf("Expected distinct IDs for distinct short ids, both got %d", id)
+	}
+
+	if _, err := services.LobstersItemID("not-valid!"); err == nil {
+		t.Error("Expected an error for an invalid short id")
+	}
+	if _, err := services.LobstersItemID("zzzzzzzzzzz"); err == nil {
+		t.Error("Expected an error for a short id past the Lobsters ID range")
+	}
+}
+
+func TestLobstersItemRoundTrip(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	// Both short IDs map past the INTEGER range
+	storyID, err := services.LobstersItemID("zzzzzz")
+	if err != nil {
+		t.Fatalf("Failed to map short id: %v", err)
+	}
+	commentID, err := services.LobstersItemID("zzzzzy")
+	if err != nil {
+		t.Fatalf("Failed to map short id: %v", err)
+	}
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	comments := postgres.NewCommentRepository()
+	defer stories.Delete(ctx, storyID)
+	defer comments.Delete(ctx, commentID)
+
+	story := &models.Story{
+		ID:           storyID,
+		Type:         "story",
+		Title:        "Stored from Lobsters",
+		Author:       "jcs",
+		Created_At:   1700000000,
+		Comments_ids: []int{commentID},
+		Source:       services.SourceLobsters,
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to store story %d: %v", storyID, err)
+	}
+	comment := &models.Comment{
+		ID:         commentID,
+		Type:       "comment",
+		Text:       "A reply",
+		Author:     "pushcx",
+		Parent:     storyID,
+		Replies:    []int{},
+		Created_At: 1700000100,
+		Source:     services.SourceLobsters,
+	}
+	if err := comments.CreateBatchWithExistingIDs(ctx, []*models.Comment{comment}); err != nil {
+		t.Fatalf("Failed to store comment %d: %v", commentID, err)
+	}
+
+	storedStory, err := stories.GetByID(ctx, storyID)
+	if err != nil {
+		t.Fatalf("Failed to load story %d: %v", storyID, err)
+	}
+	if len(storedStory.Comments_ids) != 1 || storedStory.Comments_ids[0] != commentID {
+		t.Errorf("Expected comment %d listed on the story, got %v", commentID, storedStory.Comments_ids)
+	}
+	storedComment, err := comments.GetByID(ctx, commentID)
+	if err != nil {
+		t.Fatalf("Failed to load comment %d: %v", commentID, err)
+	}
+	if storedComment.Parent != storyID {
+		t.Errorf("Expected parent %d, got %d", storyID, storedComment.Parent)
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/config"
+	"internship-project/pkg/database"
+
+	"go.uber.org/goleak"
+)
+
+// TestMain fails the suite when a test leaves goroutines running
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(isolatedRun{m})
+}
+
+// isolatedRun drops the test schema once every test has run
+type isolatedRun struct {
+	m *testing.M
+}
+
+func (r isolatedRun) Run() int {
+	code := r.m.Run()
+	dropTestSchema()
+	return code
+}
+
+var (
+	// testSchema holds the tables of this test package so that packages and runs sharing the
+	// database do not see each other's rows; it is created by the first setupTest
+	testSchema = fmt.Sprintf("test_%d_%d", os.Getpid(), time.Now().UnixNano())
+
+	schemaOnce    sync.Once
+	schemaCreated bool
+	schemaErr     error
+	seedOnce      sync.Once
+	seedErr       error
+)
+
+// testDBConfig returns the configuration of the test database, in a schema of its own unless
+// TEST_DB_ISOLATION is disabled
+func testDBConfig(t *testing.T) *database.Config {
+	t.Helper()
+	cfg := database.GetDefaultConfig()
+	if !config.GetEnvBool("TEST_DB_ISOLATION", true) {
+		return cfg
+	}
+	cfg.Schema = testSchema
+	schemaOnce.Do(func() {
+		schemaErr = database.CreateSchema(cfg)
+		schemaCreated = schemaErr == nil
+	})
+	if schemaErr != nil {
+		t.Fatalf("Failed to create test schema: %v", schemaErr)
+	}
+	return cfg
+}
+
+// seedTestSchema loads the rows the tests read by hard-coded ID into the fresh test schema
+func seedTestSchema(t *testing.T) {
+	t.Helper()
+	if !schemaCreated {
+		return
+	}
+	seedOnce.Do(func() {
+		seed, err := os.ReadFile(filepath.Join("testdata", "seed.sql"))
+		if err != nil {
+			seedErr = err
+			return
+		}
+		_, seedErr = database.GetDB().Exec(string(seed))
+	})
+	if seedErr != nil {
+		t.Fatalf("Failed to seed test schema: %v", seedErr)
+	}
+}
+
+// dropTestSchema removes the test schema and every table in it
+func dropTestSchema() {
+	if !schemaCreated {
+		return
+	}
+	cfg := database.GetDefaultConfig()
+	cfg.Schema = testSchema
+	if err := database.DropSchema(cfg); err != nil {
+		log.Printf("Failed to drop test schema %s: %v", testSchema, err)
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"internship-project/internal/repository/postgres"
+)
+
+func TestMaintenanceTableStats(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewMaintenanceRepository()
+	if err := repo.Analyze(ctx, "stories"); err != nil {
+		t.Fatalf("Failed to analyze stories: %v", err)
+	}
+	if err := repo.Vacuum(ctx, "comments"); err != nil {
+		t.Fatalf("Failed to vacuum comments: %v", err)
+	}
+
+	stats, err := repo.GetTableStats(ctx, []string{"stories", "no_such_table", "users"})
+	if err != nil {
+		t.Fatalf("Failed to get table stats: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Table != "stories" || stats[1].Table != "users" {
+		t.Fatalf("Expected the stats of stories and users only, got %+v", stats)
+	}
+	stories := stats[0]
+	if !stories.Partitioned || stories.TableBytes <= 0 {
+		t.Errorf("Expected stories partitioned with a size, got %+v", stories)
+	}
+	hasKey := false
+	for _, index := range stories.Indexes {
+		hasKey = hasKey || index.Name == "stories_pkey"
+	}
+	if !hasKey {
+		t.Errorf("Expected the primary key among the indexes of stories, got %+v", stories.Indexes)
+	}
+	if stats[1].Partitioned || stats[1].DeadRatio < 0 || stats[1].DeadRatio > 1 {
+		t.Errorf("Unexpected users stats %+v", stats[1])
+	}
+}
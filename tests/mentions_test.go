@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+// fakeMentionStore keeps the mentioned usernames of each comment in memory
+type fakeMentionStore struct {
+	mentions map[int][]string
+}
+
+func (f *fakeMentionStore) ReplaceMentions(ctx context.Context, comment *models.Comment, usernames []string) error {
+	f.mentions[comment.ID] = usernames
+	return nil
+}
+
+func (f *fakeMentionStore) GetByUsername(ctx context.Context, tenant, username string, cursor models.TimelineCursor, limit int) ([]*models.Mention, error) {
+	return nil, nil
+}
+
+func TestExtractMentions(t *testing.T) {
+	cases := map[string][]string{
+		"@pg thanks, and @dang too. @pg again":                      {"pg", "dang"},
+		"Mail me at someone@example.com":                            {},
+		`See <a href="https:&#x2F;&#x2F;x.com&#x2F;@handle">it</a>`: {},
+		"(@tptacek) &quot;@patio11&quot; @a @this_is_far_too_long_": {"tptacek", "patio11"},
+		"<p>@jacquesm: agreed":                                      {"jacquesm"},
+	}
+	for text, want := range cases {
+		if got := etl.ExtractMentions(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractMentions(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestMentionerStoresCommentMentions(t *testing.T) {
+	store := &fakeMentionStore{mentions: map[int][]string{}}
+	pipeline := etl.NewPipeline(etl.NewMentioner(store))
+
+	pipeline.PostPersist(context.Background(), &models.Comment{ID: 1, Text: "@pg agreed"})
+	pipeline.PostPersist(context.Background(), &models.Story{ID: 2, Title: "@pg"})
+
+	if got := store.mentions[1]; !reflect.DeepEqual(got, []string{"pg"}) {
+		t.Errorf("Expected comment 1 to mention pg, got %v", got)
+	}
+	if _, ok := store.mentions[2]; ok {
+		t.Error("Expected stories to be ignored")
+	}
+}
+
+func TestMentionRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	comments := postgres.NewCommentRepository()
+	repo := postgres.NewMentionRepository()
+	defer comments.DeleteByAuthor(ctx, "mentioner")
+	defer comments.DeleteByAuthor(ctx, "mentioned")
+
+	now := time.Now().Unix()
+	parent := &models.Comment{ID: 8951, Type: "comment", Text: "Original", Author: "mentioned", Parent: 1012, Created_At: now - 10}
+	reply := &models.Comment{ID: 8952, Type: "comment", Text: "@mentioned @someone_else @mentioner", Author: "mentioner", Parent: 8951, Created_At: now}
+	if _, err := comments.UpsertBatch(ctx, []*models.Comment{parent, reply}); err != nil {
+		t.Fatalf("Failed to save comments: %v", err)
+	}
+	if err := repo.ReplaceMentions(ctx, reply, etl.ExtractMentions(reply.Text)); err != nil {
+		t.Fatalf("Failed to save mentions: %v", err)
+	}
+
+	mentions, err := repo.GetByUsername(ctx, models.DefaultTenant, "mentioned", models.TimelineCursor{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to get mentions: %v", err)
+	}
+	if len(mentions) != 1 || mentions[0].Comment_ID != 8952 || mentions[0].Kind != models.MentionReply || mentions[0].Text != reply.Text {
+		t.Errorf("Expected one reply mention of comment 8952, got %+v", mentions)
+	}
+	if self, _ := repo.GetByUsername(ctx, models.DefaultTenant, "mentioner", models.TimelineCursor{}, 10); len(self) != 0 {
+		t.Errorf("Expected self mentions to be skipped, got %+v", self)
+	}
+	others, _ := repo.GetByUsername(ctx, models.DefaultTenant, "someone_else", models.TimelineCursor{}, 10)
+	if len(others) != 1 || others[0].Kind != models.MentionAt {
+		t.Errorf("Expected an @mention of someone_else, got %+v", others)
+	}
+
+	reply.Text = "edited"
+	if err := repo.ReplaceMentions(ctx, reply, etl.ExtractMentions(reply.Text)); err != nil {
+		t.Fatalf("Failed to replace mentions: %v", err)
+	}
+	if others, _ := repo.GetByUsername(ctx, models.DefaultTenant, "someone_else", models.TimelineCursor{}, 10); len(others) != 0 {
+		t.Errorf("Expected the removed @mention to be deleted, got %+v", others)
+	}
+}
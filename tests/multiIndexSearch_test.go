@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"internship-project/internal/search"
+)
+
+func TestSearchSpansIndexesWithKindBoosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hn-stories,hn-comments/_search" {
+			t.Errorf("Unexpected search path %q", r.URL.Path)
+		}
+		var body struct {
+			IndicesBoost []map[string]float64 `json:"indices_boost"`
+			Size         int                  `json:"size"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode search body: %v", err)
+		}
+		if len(body.IndicesBoost) != 2 || body.IndicesBoost[0]["hn-stories"] != 3 || body.IndicesBoost[1]["hn-comments"] != 0.8 {
+			t.Errorf("Unexpected index boosts %v", body.IndicesBoost)
+		}
+		if body.Size != 5 {
+			t.Errorf("Expected size 5, got %d", body.Size)
+		}
+		w.Write([]byte(`{
+			"hits": {"total": {"value": 7}, "hits": [
+				{"_index": "hn-stories", "_id": "10", "_score": 6.5, "_source": {"title": "Go 2"}},
+				{"_index": "hn-comments", "_id": "11", "_score": 1.2, "_source": {"text": "go is fine"}}
+			]},
+			"aggregations": {"types": {"buckets": [{"key": "hn-comments", "doc_count": 5}, {"key": "hn-stories", "doc_count": 2}]}}
+		}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	t.Setenv("SEARCH_KIND_BOOSTS", "story:3,bogus:9,comment:oops")
+
+	result, err := search.NewClient().Search(context.Background(), search.Request{
+		Query: "go",
+		Kinds: []string{"story", "comment"},
+		Limit: 5,
+	})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if result.Total != 7 {
+		t.Errorf("Expected 7 hits in total, got %d", result.Total)
+	}
+	if result.Facets["story"] != 2 || result.Facets["comment"] != 5 {
+		t.Errorf("Unexpected facets %v", result.Facets)
+	}
+	if len(result.Hits) != 2 || result.Hits[0].Kind != "story" || result.Hits[0].ID != 10 || result.Hits[1].Kind != "comment" {
+		t.Errorf("Unexpected hits %+v", result.Hits)
+	}
+}
+
+func TestSearchRejectsUnknownKind(t *testing.T) {
+	t.Setenv("OPENSEARCH_URL", "http://localhost:1")
+	if _, err := search.NewClient().Search(context.Background(), search.Request{Kinds: []string{"user"}}); err == nil {
+		t.Error("Expected an error for a kind without an index")
+	}
+}
+
+func TestSearchRankProfiles(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("SEARCH_HOT_DECAY_SCALE", "12h")
+	client := search.NewClient()
+
+	if _, err := client.Search(context.Background(), search.Request{Query: "go", Rank: search.RankHot}); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	functionScore, ok := body["query"].(map[string]interface{})["function_score"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a function_score query for hot, got %v", body["query"])
+	}
+	functions := functionScore["functions"].([]interface{})
+	gauss := functions[1].(map[string]interface{})["gauss"].(map[string]interface{})["time"].(map[string]interface{})
+	if gauss["scale"] != float64(12*3600) {
+		t.Errorf("Expected a 12h decay scale, got %v", gauss["scale"])
+	}
+	if _, sorted := body["sort"]; sorted {
+		t.Error("Expected hot results ranked by score, not sorted")
+	}
+
+	for rank, field := range map[string]string{search.RankTop: "score", search.RankNew: "time"} {
+		if _, err := client.Search(context.Background(), search.Request{Rank: rank}); err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		sort, _ := body["sort"].([]interface{})
+		if len(sort) == 0 {
+			t.Fatalf("Expected %s results sorted, got %v", rank, body)
+		}
+		if _, ok := sort[0].(map[string]interface{})[field]; !ok {
+			t.Errorf("Expected %s results sorted by %s first, got %v", rank, field, sort[0])
+		}
+		if _, ok := body["query"].(map[string]interface{})["bool"]; !ok {
+			t.Errorf("Expected the plain query for %s, got %v", rank, body["query"])
+		}
+	}
+
+	if search.ValidRank("best") || !search.ValidRank(search.RankRelevance) {
+		t.Error("Unexpected rank validation")
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	var body struct {
+		Highlight struct {
+			PreTags  []string `json:"pre_tags"`
+			PostTags []string `json:"post_tags"`
+			Fields   map[string]struct {
+				FragmentSize int `json:"fragment_size"`
+			} `json:"fields"`
+		} `json:"highlight"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"hits": {"total": {"value": 1}, "hits": [
+			{"_index": "stories", "_id": "1", "_score": 1, "_source": {"title": "Postgres tips"},
+			 "highlight": {"title": ["<mark>Postgres</mark> tips"]}}
+		]}}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("SEARCH_HIGHLIGHT_PRE_TAG", "<mark>")
+	t.Setenv("SEARCH_HIGHLIGHT_POST_TAG", "</mark>")
+
+	result, err := search.NewClient().Search(context.Background(), search.Request{Query: "postgres", SnippetLength: 80})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(body.Highlight.PreTags) != 1 || body.Highlight.PreTags[0] != "<mark>" || body.Highlight.PostTags[0] != "</mark>" {
+		t.Errorf("Unexpected highlight tags %v %v", body.Highlight.PreTags, body.Highlight.PostTags)
+	}
+	if body.Highlight.Fields["text"].FragmentSize != 80 {
+		t.Errorf("Expected 80-character text snippets, got %d", body.Highlight.Fields["text"].FragmentSize)
+	}
+	if got := result.Hits[0].Highlights["title"]; len(got) != 1 || got[0] != "<mark>Postgres</mark> tips" {
+		t.Errorf("Unexpected title highlights %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/etl"
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+)
+
+// fakeNewAuthorStore records the items MarkNewAuthor is called for
+type fakeNewAuthorStore struct {
+	repository.ItemRepository
+	marked []string
+	age    time.Duration
+}
+
+func (f *fakeNewAuthorStore) MarkNewAuthor(ctx context.Context, kind string, id int, accountAge time.Duration) (bool, error) {
+	f.marked = append(f.marked, fmt.Sprintf("%s:%d", kind, id))
+	f.age = accountAge
+	return true, nil
+}
+
+func TestNewAuthorFlaggerMarksStoriesAndComments(t *testing.T) {
+	store := &fakeNewAuthorStore{}
+	pipeline := etl.NewPipeline(etl.NewNewAuthorFlagger(store, 48*time.Hour))
+
+	pipeline.PostPersist(context.Background(), &models.Story{ID: 1, Title: "First post"})
+	pipeline.PostPersist(context.Background(), &models.Comment{ID: 2, Text: "Hello"})
+	pipeline.PostPersist(context.Background(), &models.Job{ID: 3, Title: "Hiring"})
+
+	if got := strings.Join(store.marked, ","); got != "story:1,comment:2" {
+		t.Errorf("Expected the story and the comment marked, got %q", got)
+	}
+	if store.age != 48*time.Hour {
+		t.Errorf("Expected an account age of 48h, got %v", store.age)
+	}
+}
+
+func TestSearchNewAuthorsFilter(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+
+	client := search.NewClient()
+	if _, err := client.Search(context.Background(), search.Request{Query: "launch", NewAuthors: true}); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if !strings.Contains(body, `{"term":{"new_author":true}}`) {
+		t.Errorf("Expected a new author filter in the query, got %s", body)
+	}
+
+	if _, err := client.Search(context.Background(), search.Request{Query: "launch"}); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if strings.Contains(body, "new_author") {
+		t.Errorf("Expected no new author filter by default, got %s", body)
+	}
+}
+
+func TestMarkNewAuthor(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+	id := 900000000 + rand.Intn(1000000)
+	young := fmt.Sprintf("newcomer%d", id)
+	veteran := fmt.Sprintf("veteran%d", id)
+
+	users := postgres.NewUserRepository()
+	for _, user := range []*models.User{
+		{Username: young, About: "new here", Created_At: now - 3600, Submitted: []int{id}},
+		{Username: veteran, About: "old hand", Created_At: now - 5*365*86400, Submitted: []int{id + 1, 1000}},
+	} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("Failed to create user %s: %v", user.Username, err)
+		}
+		defer users.Delete(ctx, user.Username)
+	}
+
+	stories := postgres.NewStoryRepository()
+	batch := []*models.Story{
+		{ID: id, Type: "story", Title: "My first post", Author: young, Created_At: now, Comments_ids: []int{}},
+		{ID: id + 1, Type: "story", Title: "Another post", Author: veteran, Created_At: now, Comments_ids: []int{}},
+		{ID: id + 2, Type: "story", Title: "Unknown account", Author: fmt.Sprintf("stranger%d", id), Created_At: now, Comments_ids: []int{}},
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, batch); err != nil {
+		t.Fatalf("Failed to create stories: %v", err)
+	}
+	for _, story := range batch {
+		defer stories.Delete(ctx, story.ID)
+	}
+
+	repo := postgres.NewItemRepository()
+	for storyID, want := range map[int]bool{id: true, id + 1: false, id + 2: true} {
+		flagged, err := repo.MarkNewAuthor(ctx, "story", storyID, 30*24*time.Hour)
+		if err != nil {
+			t.Fatalf("Failed to mark story %d: %v", storyID, err)
+		}
+		if flagged != want {
+			t.Errorf("Story %d: expected new author %v, got %v", storyID, want, flagged)
+		}
+	}
+	if _, err := repo.MarkNewAuthor(ctx, "job", id, time.Hour); err == nil {
+		t.Error("Expected an error for a kind without the flag")
+	}
+
+	items, err := postgres.NewTimelineRepository().GetNewAuthors(ctx, models.DefaultTenant, models.TimelineCursor{}, 100)
+	if err != nil {
+		t.Fatalf("Failed to get the new author feed: %v", err)
+	}
+	listed := make(map[int]bool)
+	for _, item := range items {
+		listed[item.ID] = true
+	}
+	if !listed[id] || listed[id+1] || !listed[id+2] {
+		t.Errorf("Expected stories %d and %d alone in the feed, got %v", id, id+2, listed)
+	}
+}
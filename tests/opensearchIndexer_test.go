@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/opensearch"
+)
+
+// fakeBulk is a _bulk endpoint answering every document with the status returned by status for
+// its index, ID and attempt (starting at 1), or the whole first request with a 503 if refuseFirst
+type fakeBulk struct {
+	mu          sync.Mutex
+	refuseFirst bool
+	requests    int
+	attempts    map[string]int
+	indexed     map[string]bool
+}
+
+func newFakeBulk(t *testing.T, status func(index, id string, attempt int) int) (*fakeBulk, *httptest.Server) {
+	bulk := &fakeBulk{attempts: map[string]int{}, indexed: map[string]bool{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		bulk.mu.Lock()
+		defer bulk.mu.Unlock()
+		bulk.requests++
+
+		var items []map[string]interface{}
+		var accepted []string
+		failed := false
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action struct {
+				Index struct {
+					Index string `json:"_index"`
+					ID    string `json:"_id"`
+				} `json:"index"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+				t.Errorf("Malformed bulk body: %v", err)
+				return
+			}
+			key := action.Index.Index + "/" + action.Index.ID
+			bulk.attempts[key]++
+			code := status(action.Index.Index, action.Index.ID, bulk.attempts[key])
+			outcome := map[string]interface{}{"_id": action.Index.ID, "status": code}
+			if code >= 300 {
+				failed = true
+				outcome["error"] = map[string]string{"type": "rejected"}
+			} else {
+				accepted = append(accepted, key)
+			}
+			items = append(items, map[string]interface{}{"index": outcome})
+		}
+		if bulk.refuseFirst && bulk.requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for _, key := range accepted {
+			bulk.indexed[key] = true
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": failed, "items": items})
+	}))
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEXER_ENABLED", "true")
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	t.Setenv("OPENSEARCH_BULK_BACKOFF", "1ms")
+	t.Setenv("SINKS_ENABLED", "")
+	return bulk, server
+}
+
+func TestIndexerDisabled(t *testing.T) {
+	t.Setenv("OPENSEARCH_URL", "http://127.0.0.1:1")
+	t.Setenv("OPENSEARCH_INDEXER_ENABLED", "false")
+	if opensearch.NewIndexerService() != nil {
+		t.Error("Expected no indexer unless OPENSEARCH_INDEXER_ENABLED is set")
+	}
+	t.Setenv("OPENSEARCH_INDEXER_ENABLED", "true")
+	t.Setenv("OPENSEARCH_URL", "")
+	if opensearch.NewIndexerService() != nil {
+		t.Error("Expected no indexer without OPENSEARCH_URL")
+	}
+}
+
+func TestIndexerBatchesAndNames(t *testing.T) {
+	bulk, server := newFakeBulk(t, func(index, id string, attempt int) int { return http.StatusCreated })
+	defer server.Close()
+	t.Setenv("OPENSEARCH_BULK_SIZE", "2")
+	t.Setenv("OPENSEARCH_INDEX_STORY", "front-page")
+
+	indexer := opensearch.NewIndexerService()
+	if indexer == nil {
+		t.Fatal("Expected an indexer")
+	}
+	stories := make([]*models.Story, 5)
+	for i := range stories {
+		stories[i] = &models.Story{ID: 100 + i, Type: "story", Title: fmt.Sprintf("Story %d", i)}
+	}
+	if err := indexer.IndexStories(context.Background(), stories); err != nil {
+		t.Fatalf("Failed to index stories: %v", err)
+	}
+	users := []*models.User{{Username: "pg"}}
+	if err := indexer.IndexUsers(context.Background(), users); err != nil {
+		t.Fatalf("Failed to index users: %v", err)
+	}
+
+	if bulk.requests != 4 {
+		t.Errorf("Expected 3 story batches and 1 user batch, got %d requests", bulk.requests)
+	}
+	for _, key := range []string{"front-page/100", "front-page/104", "hn-users/pg"} {
+		if !bulk.indexed[key] {
+			t.Errorf("Expected %s indexed, got %v", key, bulk.indexed)
+		}
+	}
+}
+
+func TestIndexerRetries(t *testing.T) {
+	bulk, server := newFakeBulk(t, func(index, id string, attempt int) int {
+		switch {
+		case id == "2" && attempt < 3:
+			return http.StatusTooManyRequests
+		case id == "3":
+			return http.StatusBadRequest
+		}
+		return http.StatusOK
+	})
+	defer server.Close()
+	bulk.refuseFirst = true
+	t.Setenv("OPENSEARCH_BULK_MAX_ATTEMPTS", "4")
+
+	comments := []*models.Comment{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	err := opensearch.NewIndexerService().IndexComments(context.Background(), comments)
+	if err == nil || !strings.Contains(err.Error(), "hn-comments rejected 1 documents: 3: status 400") {
+		t.Fatalf("Expected only the mapping rejection reported, got %v", err)
+	}
+	if !bulk.indexed["hn-comments/1"] || !bulk.indexed["hn-comments/2"] || !bulk.indexed["hn-comments/4"] {
+		t.Errorf("Expected the throttled and accepted comments indexed, got %v", bulk.indexed)
+	}
+	if bulk.attempts["hn-comments/3"] != 2 || bulk.attempts["hn-comments/4"] != 2 {
+		t.Errorf("Expected the refused request sent again whole, then only the throttled comment, got %v", bulk.attempts)
+	}
+	if bulk.attempts["hn-comments/2"] != 3 {
+		t.Errorf("Expected the throttled comment retried until accepted, got %v", bulk.attempts)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestMonthlyPartitionPruning(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	stories := postgres.NewStoryRepository()
+	comments := postgres.NewCommentRepository()
+	defer stories.DeleteByAuthor(ctx, "partitionuser")
+	defer comments.DeleteByAuthor(ctx, "partitionuser")
+
+	march := time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC).Unix()
+	june := time.Date(2023, time.June, 15, 0, 0, 0, 0, time.UTC).Unix()
+	// Writes create the partitions of the months they need, and the ones between
+	if _, err := stories.UpsertBatch(ctx, []*models.Story{
+		{ID: 9701, Type: "story", Title: "March story", Author: "partitionuser", Created_At: march},
+		{ID: 9702, Type: "story", Title: "June story", Author: "partitionuser", Created_At: june},
+	}); err != nil {
+		t.Fatalf("Failed to save stories: %v", err)
+	}
+	if _, err := comments.UpsertBatch(ctx, []*models.Comment{
+		{ID: 9703, Type: "comment", Text: "March comment", Author: "partitionuser", Parent: 9701, Created_At: march},
+		{ID: 9704, Type: "comment", Text: "June comment", Author: "partitionuser", Parent: 9702, Created_At: june},
+	}); err != nil {
+		t.Fatalf("Failed to save comments: %v", err)
+	}
+	created, err := postgres.NewPartitionRepository().EnsurePartitions(ctx, "stories", march, june)
+	if err != nil || created != 0 {
+		t.Errorf("Expected the partitions from March to June to exist, created %d: %v", created, err)
+	}
+
+	// Upserting again conflicts on (id, created_at) instead of duplicating
+	counts, err := stories.UpsertBatch(ctx, []*models.Story{
+		{ID: 9701, Type: "story", Title: "March story, edited", Author: "partitionuser", Created_At: march},
+	})
+	if err != nil || counts.Updated != 1 {
+		t.Errorf("Expected the story updated, got %+v: %v", counts, err)
+	}
+
+	diagnostics := postgres.NewDiagnosticsRepository(10 * time.Second)
+	filter := repository.ItemFilter{Start: march - 3600, End: march + 3600, Limit: 10}
+	for _, table := range []string{"stories", "comments"} {
+		plan, err := diagnostics.ExplainQuery(ctx, table+".list", filter)
+		if err != nil {
+			t.Fatalf("Failed to explain %s.list: %v", table, err)
+		}
+		text := strings.Join(plan.Plan, "\n")
+		if !strings.Contains(text, table+"_2023_03") {
+			t.Errorf("Expected %s.list to scan the March partition, got:\n%s", table, text)
+		}
+		for _, month := range []string{"_2023_04", "_2023_05", "_2023_06"} {
+			if strings.Contains(text, table+month) {
+				t.Errorf("Expected the %s%s partition pruned, got:\n%s", table, month, text)
+			}
+		}
+	}
+}
@@ -20,7 +20,7 @@ func TestCreatePollOption(t *testing.T) {
 
 	option := &models.PollOption{
 		ID:         randomNum,
-		Type:       "PollOption",
+		Type:       "pollopt",
 		PollID:     5001,
 		Author:     "enhanced_poll_creator",
 		OptionText: "Gin Framework",
@@ -65,7 +65,7 @@ func TestPollOptionUpdate(t *testing.T) {
 
 	option := &models.PollOption{
 		ID:         153,
-		Type:       "PollOption",
+		Type:       "pollopt",
 		PollID:     5001,
 		Author:     "updated_poll_creator",
 		OptionText: "Updated: Gin Framework (Latest Version)",
@@ -126,6 +126,49 @@ func TestPollOptionUpdateVotes(t *testing.T) {
 	t.Logf("Successfully updated votes to %d", count)
 }
 
+func TestPollOptionIncrementVotes(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewPollOptionRepository()
+
+	before, err := repo.GetVoteCount(ctx, 153)
+	if err != nil {
+		t.Fatalf("Failed to get vote count: %v", err)
+	}
+
+	if err := repo.IncrementVotes(ctx, 153); err != nil {
+		t.Fatalf("Failed to increment votes: %v", err)
+	}
+
+	after, err := repo.GetVoteCount(ctx, 153)
+	if err != nil {
+		t.Fatalf("Failed to get vote count: %v", err)
+	}
+
+	if after != before+1 {
+		t.Errorf("Expected vote count %d, got %d", before+1, after)
+	}
+
+	if err := repo.DecrementVotes(ctx, 153); err != nil {
+		t.Fatalf("Failed to decrement votes: %v", err)
+	}
+
+	if err := repo.IncrementVotesBatch(ctx, map[int]int{153: 3}); err != nil {
+		t.Fatalf("Failed to apply vote deltas: %v", err)
+	}
+
+	final, err := repo.GetVoteCount(ctx, 153)
+	if err != nil {
+		t.Fatalf("Failed to get vote count: %v", err)
+	}
+
+	if final != before+3 {
+		t.Errorf("Expected vote count %d after batch, got %d", before+3, final)
+	}
+}
+
 func TestPollOptionGetVoteCount(t *testing.T) {
 	setupTest(t)
 	defer teardownTest()
@@ -326,7 +369,7 @@ func TestPollOptionCreateBatch(t *testing.T) {
 	options := []*models.PollOption{
 		{
 			ID:         1201,
-			Type:       "PollOption",
+			Type:       "pollopt",
 			PollID:     5201,
 			Author:     "batch_option_creator",
 			OptionText: "Option A - Batch Created",
@@ -335,7 +378,7 @@ func TestPollOptionCreateBatch(t *testing.T) {
 		},
 		{
 			ID:         1202,
-			Type:       "PollOption",
+			Type:       "pollopt",
 			PollID:     5201,
 			Author:     "batch_option_creator",
 			OptionText: "Option B - Batch Created",
@@ -380,7 +423,7 @@ func TestPollOptionDeleteByAuthor(t *testing.T) {
 	// Create an option to delete
 	tempOption := &models.PollOption{
 		ID:         1301,
-		Type:       "PollOption",
+		Type:       "pollopt",
 		PollID:     5301,
 		Author:     "deleteoptionuser",
 		OptionText: "Option to Delete",
@@ -415,7 +458,7 @@ func TestPollOptionDeleteByPollID(t *testing.T) {
 	testOptions := []*models.PollOption{
 		{
 			ID:         1401,
-			Type:       "PollOption",
+			Type:       "pollopt",
 			PollID:     6001,
 			Author:     "test_creator",
 			OptionText: "Test Option 1",
@@ -424,7 +467,7 @@ func TestPollOptionDeleteByPollID(t *testing.T) {
 		},
 		{
 			ID:         1402,
-			Type:       "PollOption",
+			Type:       "pollopt",
 			PollID:     6001,
 			Author:     "test_creator",
 			OptionText: "Test Option 2",
@@ -470,7 +513,7 @@ func TestPollOptionDelete(t *testing.T) {
 	// Create an option to delete
 	tempOption := &models.PollOption{
 		ID:         1501,
-		Type:       "PollOption",
+		Type:       "pollopt",
 		PollID:     5501,
 		Author:     "tempuser",
 		OptionText: "Temporary option for deletion test",
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestUpdateDedupesItemsAndProfiles(t *testing.T) {
+	update := models.Update{IDs: []int{3, 1, 3, 2, 1}, Profiles: []string{"pg", "", "dang", "pg", "Dang"}}
+	if ids := update.ItemIDs(); !slices.Equal(ids, []int{3, 1, 2}) {
+		t.Errorf("Unexpected item IDs %v", ids)
+	}
+	// usernames are case-sensitive
+	if names := update.Usernames(); !slices.Equal(names, []string{"pg", "dang", "Dang"}) {
+		t.Errorf("Unexpected usernames %v", names)
+	}
+	if update.IsEmpty() || !(&models.Update{}).IsEmpty() {
+		t.Error("Expected only the update without items and profiles to be empty")
+	}
+}
+
+func TestFetchProfilesByUsername(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/user/pg.json":
+			w.Write([]byte(`{"id":"pg","created":1160418092,"karma":157316,"about":"Bug fixer.","submitted":[1,2]}`))
+		case "/user/dang.json":
+			w.Write([]byte(`{"id":"dang","created":1183474512,"karma":100,"about":"Mod."}`))
+		default:
+			w.Write([]byte(`null`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("HN_API_BASE_URL", server.URL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+
+	users, errs := services.NewUserApiService(services.NewHackerNewsApiClient()).FetchByUsernames(
+		context.Background(), []string{"pg", "ghost", "dang"}, services.WithMaxConcurrency(2))
+	if len(users) != 2 || users[0].Username != "pg" || users[1].Username != "dang" || len(users[0].Submitted) != 2 {
+		t.Fatalf("Expected pg and dang in order, got %+v", users)
+	}
+	if len(errs) != 1 || !errors.Is(errs["ghost"], services.ErrItemGone) {
+		t.Errorf("Expected ghost to be gone, got %v", errs)
+	}
+	if requests["/user/pg.json"] != 1 || len(requests) != 3 {
+		t.Errorf("Expected one request per profile, got %v", requests)
+	}
+}
+
+func TestKarmaCheckChanged(t *testing.T) {
+	check := &models.KarmaCheck{Username: "pg", Karma: 10, About: "Hi", Submissions: 2}
+	if check.Changed(&models.User{Username: "pg", Karma: 10, About: "Hi", Submitted: []int{1, 2}}) {
+		t.Error("Expected an identical profile to be unchanged")
+	}
+	for _, user := range []*models.User{
+		{Username: "pg", Karma: 11, About: "Hi", Submitted: []int{1, 2}},
+		{Username: "pg", Karma: 10, About: "Hello", Submitted: []int{1, 2}},
+		{Username: "pg", Karma: 10, About: "Hi", Submitted: []int{1, 2, 3}},
+	} {
+		if !check.Changed(user) {
+			t.Errorf("Expected %+v to be changed", user)
+		}
+	}
+}
+
+func TestKarmaChecksRoundTrip(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewUserRepository()
+	user := &models.User{Username: "karmacheckuser", Karma: 42, About: "About", Created_At: time.Now().Unix(), Submitted: []int{1, 2, 3}}
+	if _, err := repo.UpsertBatch(ctx, []*models.User{user}); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	defer repo.Delete(ctx, user.Username)
+
+	checks, err := repo.GetKarmaChecks(ctx, []string{user.Username, "karmachecknobody"})
+	if err != nil {
+		t.Fatalf("Failed to get karma checks: %v", err)
+	}
+	check, ok := checks[user.Username]
+	if len(checks) != 1 || !ok || check.Checked_At != 0 || check.Karma != 42 || check.Submissions != 3 {
+		t.Fatalf("Expected the unchecked user only, got %+v", checks)
+	}
+
+	if err := repo.MarkKarmaChecked(ctx, []string{user.Username}, 1700000000); err != nil {
+		t.Fatalf("Failed to mark karma checked: %v", err)
+	}
+	checks, err = repo.GetKarmaChecks(ctx, []string{user.Username})
+	if err != nil {
+		t.Fatalf("Failed to get karma checks: %v", err)
+	}
+	if checks[user.Username].Checked_At != 1700000000 {
+		t.Errorf("Expected the check time to be recorded, got %+v", checks[user.Username])
+	}
+}
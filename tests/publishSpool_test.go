@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"internship-project/internal/transport"
+)
+
+// flakyPublisher records the published messages and fails while down is set
+type flakyPublisher struct {
+	mu        sync.Mutex
+	down      bool
+	published []string
+}
+
+func (f *flakyPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("broker unreachable")
+	}
+	for _, value := range values {
+		f.published = append(f.published, topic+":"+string(value))
+	}
+	return nil
+}
+
+func (f *flakyPublisher) Close() error { return nil }
+
+func (f *flakyPublisher) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func TestSpoolingPublisherBuffersWhileBrokerIsDown(t *testing.T) {
+	t.Setenv("PUBLISH_SPOOL_DRAIN_INTERVAL", "1h")
+	dir := t.TempDir()
+	ctx := context.Background()
+	broker := &flakyPublisher{down: true}
+
+	p, err := transport.NewSpoolingPublisher(broker, dir)
+	if err != nil {
+		t.Fatalf("Failed to create spooling publisher: %v", err)
+	}
+	if err := p.Publish(ctx, "StoriesTopic", []byte("1"), []byte("2")); err != nil {
+		t.Fatalf("Expected the failed publish to be spooled, got %v", err)
+	}
+	broker.setDown(false)
+	// Spooled behind the older messages even though the broker is back
+	if err := p.Publish(ctx, "CommentsTopic", []byte("3")); err != nil {
+		t.Fatalf("Failed to spool: %v", err)
+	}
+	if len(broker.published) != 0 {
+		t.Fatalf("Expected nothing published ahead of the spool, got %v", broker.published)
+	}
+
+	// The spool outlives the publisher
+	if err := p.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	p, err = transport.NewSpoolingPublisher(broker, dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen spooling publisher: %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Drain(ctx); err != nil {
+		t.Fatalf("Failed to drain: %v", err)
+	}
+	want := []string{"StoriesTopic:1", "StoriesTopic:2", "CommentsTopic:3"}
+	if !reflect.DeepEqual(broker.published, want) {
+		t.Fatalf("Expected %v drained in order, got %v", want, broker.published)
+	}
+
+	// Empty again: published directly
+	if err := p.Publish(ctx, "StoriesTopic", []byte("4")); err != nil || len(broker.published) != 4 {
+		t.Errorf("Expected a direct publish, got %v after %v", err, broker.published)
+	}
+	if err := p.Drain(ctx); err != nil || len(broker.published) != 4 {
+		t.Errorf("Expected nothing left to drain, got %v after %v", err, broker.published)
+	}
+}
+
+func TestSpoolingPublisherLimitsAndRecovery(t *testing.T) {
+	t.Setenv("PUBLISH_SPOOL_DRAIN_INTERVAL", "1h")
+	t.Setenv("PUBLISH_SPOOL_MAX_BYTES", "64")
+	dir := t.TempDir()
+	ctx := context.Background()
+	broker := &flakyPublisher{down: true}
+
+	p, err := transport.NewSpoolingPublisher(broker, dir)
+	if err != nil {
+		t.Fatalf("Failed to create spooling publisher: %v", err)
+	}
+	if err := p.Publish(ctx, "T", []byte("first")); err != nil {
+		t.Fatalf("Failed to spool: %v", err)
+	}
+	if err := p.Publish(ctx, "T", make([]byte, 64)); !errors.Is(err, transport.ErrSpoolFull) {
+		t.Errorf("Expected a full spool error, got %v", err)
+	}
+	if err := p.Drain(ctx); err == nil {
+		t.Error("Expected the drain to fail while the broker is down")
+	}
+
+	// A second publisher takes a spool file of its own
+	other, err := transport.NewSpoolingPublisher(broker, dir)
+	if err != nil {
+		t.Fatalf("Failed to create a second spooling publisher: %v", err)
+	}
+	other.Close()
+	if _, err := os.Stat(filepath.Join(dir, "spool-1.log")); err != nil {
+		t.Errorf("Expected a second spool file: %v", err)
+	}
+	p.Close()
+
+	// A record torn by a crash is dropped when the spool is reopened
+	f, err := os.OpenFile(filepath.Join(dir, "spool-0.log"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open spool file: %v", err)
+	}
+	f.Write([]byte{0, 0, 0, 1, 0, 1})
+	f.Close()
+
+	broker.setDown(false)
+	p, err = transport.NewSpoolingPublisher(broker, dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen spooling publisher: %v", err)
+	}
+	defer p.Close()
+	if err := p.Drain(ctx); err != nil {
+		t.Fatalf("Failed to drain: %v", err)
+	}
+	if want := []string{"T:first"}; !reflect.DeepEqual(broker.published, want) {
+		t.Errorf("Expected %v, got %v", want, broker.published)
+	}
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/reconcile"
+	"internship-project/internal/search"
+	"internship-project/internal/transport"
+)
+
+// fakeQualityStore serves fixed story documents in place of Postgres
+type fakeQualityStore struct {
+	docs map[int]map[string]interface{}
+}
+
+func (f *fakeQualityStore) RunChecks(ctx context.Context, sampleSize int) ([]models.DataQualityCheck, error) {
+	return nil, nil
+}
+
+func (f *fakeQualityStore) CountItems(ctx context.Context, kind string) (int64, error) {
+	return int64(len(f.docs)), nil
+}
+
+func (f *fakeQualityStore) CountItemsInRange(ctx context.Context, kind string, start, end int64) (int64, error) {
+	return int64(len(f.docs)), nil
+}
+
+func (f *fakeQualityStore) GetItemIDs(ctx context.Context, kind string, start, end int64, afterID, limit int) ([]int, error) {
+	var ids []int
+	for id := range f.docs {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (f *fakeQualityStore) SampleItemIDs(ctx context.Context, kind string, start, end int64, n int) ([]int, error) {
+	return f.GetItemIDs(ctx, kind, start, end, 0, n)
+}
+
+func (f *fakeQualityStore) GetItemDocuments(ctx context.Context, kind string, ids []int) (map[int]map[string]interface{}, error) {
+	docs := map[int]map[string]interface{}{}
+	for _, id := range ids {
+		if doc, ok := f.docs[id]; ok {
+			docs[id] = doc
+		}
+	}
+	return docs, nil
+}
+
+func (f *fakeQualityStore) SaveReport(ctx context.Context, report *models.DataQualityReport) error {
+	return nil
+}
+
+func (f *fakeQualityStore) GetLatestReport(ctx context.Context) (*models.DataQualityReport, error) {
+	return nil, nil
+}
+
+// recordingPublisher keeps the published values per topic
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published map[string][]string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, values ...[]byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, v := range values {
+		p.published[topic] = append(p.published[topic], string(v))
+	}
+	return nil
+}
+
+// values returns the values published on a topic so far
+func (p *recordingPublisher) values(topic string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published[topic]...)
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestReconcileFindsMissingAndStaleDocuments(t *testing.T) {
+	store := &fakeQualityStore{docs: map[int]map[string]interface{}{
+		1: {"title": "Same", "score": float64(10), "by": "alice"},
+		2: {"title": "Changed", "score": float64(50), "by": "bob"},
+		3: {"title": "Missing", "score": float64(1), "by": "carol"},
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stories/_count":
+			w.Write([]byte(`{"count": 2}`))
+		case "/stories/_mget":
+			var body struct {
+				IDs []string `json:"ids"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body.IDs) != 3 {
+				t.Errorf("Expected 3 IDs in _mget, got %v", body.IDs)
+			}
+			// the index has extra fields and a stale score for story 2
+			w.Write([]byte(`{"docs": [
+				{"_id": "1", "found": true, "_source": {"id": 1, "title": "Same", "score": 10, "by": "alice", "kids": [4]}},
+				{"_id": "2", "found": true, "_source": {"id": 2, "title": "Changed", "score": 12, "by": "bob"}},
+				{"_id": "3", "found": false}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "")
+
+	publisher := &recordingPublisher{published: map[string][]string{}}
+	reconciler := reconcile.NewReconciler(store, search.NewClient(), publisher)
+
+	for _, full := range []bool{false, true} {
+		publisher.published = map[string][]string{}
+		reports, err := reconciler.Run(context.Background(), reconcile.Options{
+			Kinds:      []string{"story"},
+			SampleSize: 10,
+			Full:       full,
+			Repair:     true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to reconcile (full=%v): %v", full, err)
+		}
+		if len(reports) != 1 {
+			t.Fatalf("Expected 1 report, got %d", len(reports))
+		}
+
+		report := reports[0]
+		if report.DatabaseCount != 3 || report.IndexCount != 2 || report.Checked != 3 {
+			t.Errorf("Unexpected counts: %s", report)
+		}
+		if len(report.Missing) != 1 || report.Missing[0] != 3 {
+			t.Errorf("Expected story 3 missing, got %v", report.Missing)
+		}
+		if len(report.Stale) != 1 || report.Stale[0] != 2 {
+			t.Errorf("Expected story 2 stale, got %v", report.Stale)
+		}
+		if report.Consistent() {
+			t.Error("Expected an inconsistent report")
+		}
+		if got := strings.Join(publisher.values(transport.ItemTopics["story"]), ","); got != "3,2" {
+			t.Errorf("Expected stories 3 and 2 republished, got %q", got)
+		}
+	}
+}
+
+func TestReconcileRepairRequiresPublisher(t *testing.T) {
+	t.Setenv("OPENSEARCH_URL", "http://localhost:1")
+	reconciler := reconcile.NewReconciler(&fakeQualityStore{}, search.NewClient(), nil)
+	if _, err := reconciler.Run(context.Background(), reconcile.Options{Kinds: []string{"story"}, Repair: true}); err == nil {
+		t.Error("Expected an error when repairing without a publisher")
+	}
+}
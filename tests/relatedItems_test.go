@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestMoreLikeThisRelated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/hn/_search" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Invalid query body: %v", err)
+		}
+		if _, ok := body["query"].(map[string]interface{})["more_like_this"]; !ok {
+			t.Errorf("Expected a more_like_this query, got %v", body["query"])
+		}
+		w.Write([]byte(`{"hits":{"hits":[{"_id":"7"},{"_id":"42"},{"_id":"9"}]}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("RELATED_OPENSEARCH_URL", server.URL)
+	t.Setenv("RELATED_OPENSEARCH_INDEX", "hn")
+	mlt := services.NewMoreLikeThis()
+	if mlt == nil {
+		t.Fatal("Expected a client when RELATED_OPENSEARCH_URL is set")
+	}
+
+	ids, err := mlt.Related(context.Background(), 42, 10)
+	if err != nil {
+		t.Fatalf("Failed to query related items: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{7, 9}) {
+		t.Errorf("Expected [7 9] without the item itself, got %v", ids)
+	}
+
+	t.Setenv("RELATED_OPENSEARCH_URL", "")
+	if services.NewMoreLikeThis() != nil {
+		t.Error("Expected no client without RELATED_OPENSEARCH_URL")
+	}
+}
+
+func TestStoryGetRelated(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+	now := time.Now().Unix()
+
+	stories := []*models.Story{
+		{ID: 880001, Type: "story", Title: "Target", URL: "https://www.related.example/a", Score: 1, Author: "relauthor", Created_At: now},
+		{ID: 880002, Type: "story", Title: "Same domain and author", URL: "https://related.example/b", Score: 1, Author: "relauthor", Created_At: now},
+		{ID: 880003, Type: "story", Title: "Same domain", URL: "https://related.example/c", Score: 50, Author: "someone", Created_At: now},
+		{ID: 880004, Type: "story", Title: "Same author", URL: "https://other.example/d", Score: 100, Author: "relauthor", Created_At: now},
+		{ID: 880005, Type: "story", Title: "Unrelated", URL: "https://other.example/e", Score: 100, Author: "someone", Created_At: now},
+	}
+	if err := repo.CreateBatchWithExistingIDs(ctx, stories); err != nil {
+		t.Fatalf("Failed to create stories: %v", err)
+	}
+	defer func() {
+		for _, s := range stories {
+			repo.Delete(ctx, s.ID)
+		}
+	}()
+
+	related, err := repo.GetRelated(ctx, "", 880001, 10)
+	if err != nil {
+		t.Fatalf("Failed to get related stories: %v", err)
+	}
+	var ids []int
+	for _, s := range related {
+		ids = append(ids, s.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{880002, 880003, 880004}) {
+		t.Errorf("Expected domain and author matches ranked first, got %v", ids)
+	}
+}
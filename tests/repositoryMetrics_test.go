@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/instrumented"
+)
+
+// failingChangeStore fails every call with its error
+type failingChangeStore struct {
+	err error
+}
+
+func (f *failingChangeStore) GetUpdatedSince(ctx context.Context, kind string, since models.ItemChange, limit int) ([]models.ItemChange, error) {
+	return nil, f.err
+}
+
+func (f *failingChangeStore) SequenceChanges(ctx context.Context, limit int) (int, error) {
+	return 0, f.err
+}
+
+func (f *failingChangeStore) GetChangesSince(ctx context.Context, tenant string, since int64, limit int) ([]*models.SequencedChange, error) {
+	return nil, f.err
+}
+
+func (f *failingChangeStore) GetChangeLogBounds(ctx context.Context) (int64, int64, error) {
+	return 0, 0, f.err
+}
+
+func (f *failingChangeStore) PruneChanges(ctx context.Context, before int64) (int64, error) {
+	return 0, f.err
+}
+
+func (f *failingChangeStore) GetItemHistory(ctx context.Context, kind string, id int, after int64) ([]*models.ItemRevision, error) {
+	return nil, f.err
+}
+
+type repositoryCallStats struct {
+	Calls     int64   `json:"calls"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// repositoryCalls reads the published stats of a repository method
+func repositoryCalls(t *testing.T, method string) repositoryCallStats {
+	t.Helper()
+	var stats repositoryCallStats
+	calls, ok := expvar.Get("repository_calls").(*expvar.Map)
+	if !ok {
+		t.Fatal("Expected the repository_calls expvar to be published")
+	}
+	if v := calls.Get(method); v != nil {
+		if err := json.Unmarshal([]byte(v.String()), &stats); err != nil {
+			t.Fatalf("Failed to decode the stats of %s: %v", method, err)
+		}
+	}
+	return stats
+}
+
+func TestRepositoryDecoratorRecordsCalls(t *testing.T) {
+	before := repositoryCalls(t, "WatchRepository.GetByItem")
+	store := &fakeWatchStore{watches: []*models.Watch{{ID: "1", Item_ID: 7}, {ID: "2", Item_ID: 8}}}
+	repo := instrumented.NewWatchRepository(store)
+
+	for range 3 {
+		watches, err := repo.GetByItem(context.Background(), 7)
+		if err != nil || len(watches) != 1 || watches[0].ID != "1" {
+			t.Fatalf("Expected the wrapped repository's result, got %v (%v)", watches, err)
+		}
+	}
+	after := repositoryCalls(t, "WatchRepository.GetByItem")
+	if after.Calls-before.Calls != 3 || after.Errors != before.Errors {
+		t.Errorf("Expected 3 more calls without errors, got %+v then %+v", before, after)
+	}
+}
+
+func TestRepositoryDecoratorRecordsErrors(t *testing.T) {
+	failure := errors.New("connection reset")
+	repo := instrumented.NewChangeRepository(&failingChangeStore{err: failure})
+	if _, err := repo.GetUpdatedSince(context.Background(), "story", models.ItemChange{}, 10); !errors.Is(err, failure) {
+		t.Fatalf("Expected the wrapped repository's error, got %v", err)
+	}
+
+	missing := instrumented.NewChangeRepository(&failingChangeStore{err: sql.ErrNoRows})
+	missing.GetUpdatedSince(context.Background(), "story", models.ItemChange{}, 10)
+
+	stats := repositoryCalls(t, "ChangeRepository.GetUpdatedSince")
+	if stats.Calls != 2 || stats.Errors != 1 || stats.ErrorRate != 0.5 {
+		t.Errorf("Expected 2 calls and 1 error as missing rows are not errors, got %+v", stats)
+	}
+}
+
+func TestRepositoryDecoratorCanBeDisabled(t *testing.T) {
+	t.Setenv("REPOSITORY_METRICS_ENABLED", "false")
+	store := &fakeWatchStore{}
+	if repo := instrumented.NewWatchRepository(store); repo != store {
+		t.Errorf("Expected the repository unwrapped, got %T", repo)
+	}
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"internship-project/internal/tracing"
+)
+
+func TestRequestIDFromHeaders(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"request id", map[string]string{"X-Request-ID": "abc-123"}, "abc-123"},
+		{"traceparent", map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"request id wins", map[string]string{
+			"X-Request-ID": "abc-123",
+			"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		}, "abc-123"},
+		{"unsafe request id", map[string]string{"X-Request-ID": "abc\r\ninjected"}, ""},
+		{"malformed traceparent", map[string]string{"traceparent": "00-nothex-01"}, ""},
+		{"none", map[string]string{}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.headers {
+				h.Set(k, v)
+			}
+			if got := tracing.FromHeaders(h); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRequestIDContext(t *testing.T) {
+	id := tracing.NewID()
+	if len(id) != 32 {
+		t.Fatalf("Expected a 32 character ID, got %q", id)
+	}
+	if id == tracing.NewID() {
+		t.Error("Expected unique IDs")
+	}
+
+	ctx := tracing.WithID(context.Background(), id)
+	if got := tracing.IDFromContext(ctx); got != id {
+		t.Errorf("Expected %q from context, got %q", id, got)
+	}
+	if got := tracing.IDFromContext(context.Background()); got != "" {
+		t.Errorf("Expected no ID in a bare context, got %q", got)
+	}
+}
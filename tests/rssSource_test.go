@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestFeedItemID(t *testing.T) {
+	guid := "https://example.com/posts/1"
+
+	id := services.FeedItemID(guid)
+	if id != services.FeedItemID(guid) {
+		t.Errorf("Expected the same ID for the same GUID")
+	}
+	if id >= 0 {
+		t.Errorf("Expected a negative item ID, got %d", id)
+	}
+
+	lobstersID, err := services.LobstersItemID("zzzzzz")
+	if err != nil {
+		t.Fatalf("Failed to map short id: %v", err)
+	}
+	if id >= lobstersID {
+		t.Errorf("Feed ID %d overlaps the Lobsters ID range (down to %d)", id, lobstersID)
+	}
+
+	if services.FeedItemID("https://example.com/posts/2") == id {
+		t.Errorf("Expected distinct IDs for distinct GUIDs")
+	}
+}
+
+func TestFeedItemRoundTrip(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+	story := &models.Story{
+		ID:           services.FeedItemID("https://example.com/posts/round-trip"),
+		Type:         "story",
+		Title:        "Stored from a feed",
+		URL:          "https://example.com/posts/round-trip",
+		Author:       "example.com",
+		Created_At:   1700000000,
+		Comments_ids: []int{},
+		Source:       services.SourceRSS,
+	}
+	defer repo.Delete(ctx, story.ID)
+
+	if err := repo.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to store feed item %d: %v", story.ID, err)
+	}
+	stored, err := repo.GetByID(ctx, story.ID)
+	if err != nil {
+		t.Fatalf("Failed to load feed item %d: %v", story.ID, err)
+	}
+	if stored.ID != story.ID || stored.Title != story.Title || stored.Source != services.SourceRSS {
+		t.Errorf("Expected %+v back, got %+v", story, stored)
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestScorePercentile(t *testing.T) {
+	cutoffs := make([]float64, 101)
+	for i := range cutoffs {
+		cutoffs[i] = float64(i * 10) // score 10*k at the k-th percentile
+	}
+	dist := models.NewScorePercentiles("2024-03-01", 500, cutoffs)
+	if dist.P50 != 500 || dist.P90 != 900 || dist.P99 != 990 {
+		t.Errorf("Expected p50/p90/p99 of 500/900/990, got %v/%v/%v", dist.P50, dist.P90, dist.P99)
+	}
+
+	cases := map[int]int{-5: 0, 0: 0, 9: 0, 10: 1, 505: 50, 990: 99, 999: 99, 1000: 100, 5000: 100}
+	for score, want := range cases {
+		if got := dist.Percentile(score); got != want {
+			t.Errorf("Score %d: expected percentile %d, got %d", score, want, got)
+		}
+	}
+	if got := (&models.ScorePercentiles{}).Percentile(42); got != 0 {
+		t.Errorf("Expected 0 without cutoffs, got %d", got)
+	}
+}
+
+func TestLatestScorePercentiles(t *testing.T) {
+	distributions := []*models.ScorePercentiles{{Day: "2024-03-01"}, {Day: "2024-03-03"}}
+	cases := map[string]string{"2024-02-29": "", "2024-03-01": "2024-03-01", "2024-03-02": "2024-03-01", "2024-03-05": "2024-03-03"}
+	for day, want := range cases {
+		got := models.LatestScorePercentiles(distributions, day)
+		if (got == nil && want != "") || (got != nil && got.Day != want) {
+			t.Errorf("Day %s: expected distribution of %q, got %+v", day, want, got)
+		}
+	}
+}
+
+func TestRefreshScorePercentiles(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+	stories := postgres.NewStoryRepository()
+	story := &models.Story{
+		ID:           id,
+		Type:         "story",
+		Title:        "Percentile test",
+		Score:        250,
+		Author:       "testuser",
+		Created_At:   time.Now().Unix(),
+		Comments_ids: []int{},
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	defer stories.Delete(ctx, id)
+
+	repo := postgres.NewStatsRepository()
+	today := time.Now().UTC()
+	if err := repo.RefreshScorePercentiles(ctx, today, 7); err != nil {
+		t.Fatalf("Failed to refresh score percentiles: %v", err)
+	}
+	distributions, err := repo.GetScorePercentiles(ctx, models.DefaultTenant, today, today)
+	if err != nil {
+		t.Fatalf("Failed to get score percentiles: %v", err)
+	}
+	if len(distributions) != 1 || distributions[0].Day != today.Format(time.DateOnly) ||
+		distributions[0].Stories < 1 || len(distributions[0].Cutoffs) != 101 {
+		t.Fatalf("Expected today's distribution with 101 cutoffs, got %+v", distributions)
+	}
+	dist := distributions[0]
+	if dist.P50 > dist.P90 || dist.P90 > dist.P99 {
+		t.Errorf("Expected ascending percentiles, got %+v", dist)
+	}
+
+	percentile := dist.Percentile(story.Score)
+	if err := stories.UpdateScorePercentiles(ctx, map[int]int{id: percentile}); err != nil {
+		t.Fatalf("Failed to save score percentile: %v", err)
+	}
+	for minPercentile, want := range map[int]int{percentile: 1, percentile + 1: 0} {
+		count, err := stories.Count(ctx, repository.ItemFilter{IDs: []int{id}, MinPercentile: &minPercentile})
+		if err != nil {
+			t.Fatalf("Failed to count stories: %v", err)
+		}
+		if count != want {
+			t.Errorf("min_percentile %d: expected %d stories, got %d", minPercentile, want, count)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/pkg/database"
+)
+
+func TestQueryKeyNormalizesSpelling(t *testing.T) {
+	if models.QueryKey("  Rust   Async ") != models.QueryKey("rust async") {
+		t.Error("Expected queries differing in case and spacing to share a key")
+	}
+	if models.QueryKey("rust") == models.QueryKey("rusty") {
+		t.Error("Expected different queries to have different keys")
+	}
+}
+
+func TestSearchQueryAnalytics(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	tenant := "analytics-test"
+	defer database.GetDB().Exec(`DELETE FROM search_queries WHERE tenant = $1`, tenant)
+
+	repo := postgres.NewSearchQueryRepository()
+	now := time.Now().Unix()
+	for _, q := range []struct {
+		text string
+		hits int64
+	}{
+		{"Postgres", 12}, {"postgres ", 8}, {"postgres", 0}, {"zig comptime", 0}, {"zig comptime", 0}, {"elixir", 3},
+	} {
+		err := repo.Record(ctx, &models.SearchQuery{
+			Tenant:     tenant,
+			Query_Key:  models.QueryKey(q.text),
+			Query:      q.text,
+			Filters:    map[string]string{"rank": "hot"},
+			Hits:       q.hits,
+			Latency_Ms: 20,
+			Created_At: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to record query: %v", err)
+		}
+	}
+
+	top, err := repo.GetTopQueries(ctx, tenant, now-60, 10)
+	if err != nil {
+		t.Fatalf("Failed to get top queries: %v", err)
+	}
+	if len(top) != 3 || top[0].Count != 3 || top[0].Zero_Results != 1 {
+		t.Fatalf("Expected postgres searched 3 times first, got %+v", top)
+	}
+
+	zero, err := repo.GetZeroResultQueries(ctx, tenant, now-60, 10)
+	if err != nil {
+		t.Fatalf("Failed to get zero-result queries: %v", err)
+	}
+	if len(zero) != 2 || zero[0].Query != "zig comptime" || zero[0].Zero_Results != 2 {
+		t.Errorf("Expected zig comptime to lead the zero-result queries, got %+v", zero)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"internship-project/internal/search"
+)
+
+func TestAnalyzerConfigValidation(t *testing.T) {
+	valid := &search.AnalyzerConfig{Synonyms: []string{"k8s, kubernetes", "golang => go"}, Stopwords: []string{"the"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+	for _, invalid := range []*search.AnalyzerConfig{
+		{Synonyms: []string{"kubernetes"}},
+		{Synonyms: []string{"k8s, "}},
+		{Synonyms: []string{"=> go"}},
+		{Stopwords: []string{"two words"}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+}
+
+func TestAnalyzerConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SEARCH_SYNONYMS_FILE", filepath.Join(dir, "synonyms.txt"))
+	t.Setenv("SEARCH_STOPWORDS_FILE", filepath.Join(dir, "stopwords.txt"))
+
+	empty, err := search.LoadAnalyzerConfig()
+	if err != nil || len(empty.Synonyms) != 0 || len(empty.Stopwords) != 0 {
+		t.Fatalf("Expected missing files to load empty, got %+v, %v", empty, err)
+	}
+
+	saved := &search.AnalyzerConfig{Synonyms: []string{"k8s, kubernetes"}, Stopwords: []string{"the", "a"}}
+	if err := search.SaveAnalyzerConfig(saved); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loaded, err := search.LoadAnalyzerConfig()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if strings.Join(loaded.Synonyms, "|") != "k8s, kubernetes" || strings.Join(loaded.Stopwords, "|") != "the|a" {
+		t.Errorf("Unexpected config after a round trip: %+v", loaded)
+	}
+}
+
+func TestApplyAnalyzerReopensIndexes(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/comments/_settings" {
+			http.Error(w, `{"error": "bad settings"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+	t.Setenv("OPENSEARCH_URL", server.URL)
+
+	err := search.NewClient().ApplyAnalyzer(context.Background(), &search.AnalyzerConfig{Synonyms: []string{"k8s, kubernetes"}})
+	if err == nil {
+		t.Fatal("Expected the failing comments index to be reported")
+	}
+
+	got := strings.Join(calls, "\n")
+	want := strings.Join([]string{
+		"POST /stories/_close", "PUT /stories/_settings", "POST /stories/_open", "POST /stories/_refresh",
+		"POST /asks/_close", "PUT /asks/_settings", "POST /asks/_open", "POST /asks/_refresh",
+		"POST /jobs/_close", "PUT /jobs/_settings", "POST /jobs/_open", "POST /jobs/_refresh",
+		"POST /comments/_close", "PUT /comments/_settings", "POST /comments/_open",
+	}, "\n")
+	if got != want {
+		t.Errorf("Unexpected requests:\n%s\nwant:\n%s", got, want)
+	}
+}
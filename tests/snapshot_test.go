@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/snapshot"
+)
+
+// fakeSnapshotStore serves the items of each kind in creation order
+type fakeSnapshotStore struct {
+	items map[string][]*models.SnapshotItem
+}
+
+func (s *fakeSnapshotStore) GetSnapshotItems(ctx context.Context, kind, tenant string, start, end int64, maxSpamScore float64,
+	afterCreatedAt int64, afterID, limit int) ([]*models.SnapshotItem, error) {
+	var page []*models.SnapshotItem
+	for _, item := range s.items[kind] {
+		if item.Created_At < start || item.Created_At >= end {
+			continue
+		}
+		if item.Created_At < afterCreatedAt || (item.Created_At == afterCreatedAt && item.ID <= afterID) {
+			continue
+		}
+		copied := *item
+		page = append(page, &copied)
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func TestSnapshotWritesPartitionedParquet(t *testing.T) {
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC).Unix()
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC).Unix()
+	store := &fakeSnapshotStore{items: map[string][]*models.SnapshotItem{
+		"story": {
+			{ID: 1, Kind: "story", Title: "First", URL: "https://example.com", Score: 10, Author: "alice", Created_At: jan},
+			{ID: 2, Kind: "story", Title: "Second", Score: 3, Author: "bob", Created_At: jan + 1},
+			{ID: 3, Kind: "story", Title: "Third", Score: 7, Author: "alice", Created_At: jan + 2},
+			{ID: 4, Kind: "story", Title: "Fourth", Author: "carol", Created_At: feb},
+		},
+		"comment": {
+			{ID: 5, Kind: "comment", Text: "Reply", Author: "bob", Created_At: feb + 1, Parent: 4},
+		},
+	}}
+
+	dir := t.TempDir()
+	manifest, err := snapshot.NewGenerator(store).Run(context.Background(), snapshot.Options{
+		Dir:          dir,
+		Kinds:        []string{"story", "comment"},
+		MaxSpamScore: 1,
+		RowsPerFile:  2,
+		BatchSize:    3,
+		AuthorSalt:   []byte("salt"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	expected := []string{
+		"kind=story/month=2024-01/part-00000.parquet",
+		"kind=story/month=2024-01/part-00001.parquet",
+		"kind=story/month=2024-02/part-00000.parquet",
+		"kind=comment/month=2024-02/part-00000.parquet",
+	}
+	if len(manifest.Files) != len(expected) || manifest.Rows != 5 {
+		t.Fatalf("Expected 5 rows in %d files, got %+v", len(expected), manifest)
+	}
+	root := filepath.Join(dir, manifest.ID)
+	for i, file := range manifest.Files {
+		if file.Path != expected[i] {
+			t.Errorf("Expected file %d to be %s, got %s", i, expected[i], file.Path)
+		}
+		data, err := os.ReadFile(filepath.Join(root, file.Path))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Path, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 || int64(len(data)) != file.Bytes {
+			t.Errorf("Expected %s to match its checksum and size", file.Path)
+		}
+		footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) || footer <= 0 || footer > len(data)-12 {
+			t.Errorf("Expected %s to be framed as a Parquet file", file.Path)
+		}
+		if bytes.Contains(data, []byte("alice")) {
+			t.Errorf("Expected %s not to contain author names", file.Path)
+		}
+	}
+
+	var written snapshot.Manifest
+	data, err := os.ReadFile(filepath.Join(root, snapshot.ManifestFile))
+	if err != nil || json.Unmarshal(data, &written) != nil || written.ID != manifest.ID || len(written.Columns) != len(snapshot.Columns) {
+		t.Fatalf("Expected the manifest to be written, got %+v (%v)", written, err)
+	}
+	sums, err := os.Open(filepath.Join(root, snapshot.ChecksumsFile))
+	if err != nil {
+		t.Fatalf("Failed to open checksums: %v", err)
+	}
+	defer sums.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(sums); scanner.Scan(); lines++ {
+		sum, path, _ := strings.Cut(scanner.Text(), "  ")
+		content, err := os.ReadFile(filepath.Join(root, path))
+		actual := sha256.Sum256(content)
+		if err != nil || hex.EncodeToString(actual[:]) != sum {
+			t.Errorf("Expected the checksum of %s to match", path)
+		}
+	}
+	if lines != len(expected)+1 {
+		t.Errorf("Expected checksums of the files and the manifest, got %d lines", lines)
+	}
+
+	if removed, err := snapshot.Prune(dir, 0); err != nil || len(removed) != 1 {
+		t.Errorf("Expected the snapshot to be pruned, got %v (%v)", removed, err)
+	}
+}
+
+func TestGetSnapshotItems(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	id := 900000000 + rand.Intn(1000000)
+	created := time.Now().Unix()
+	comment := &models.Comment{
+		ID:         id,
+		Type:       "comment",
+		Text:       "Snapshot test",
+		Author:     "testuser",
+		Created_At: created,
+		Parent:     7,
+		Replies:    []int{},
+	}
+	comments := postgres.NewCommentRepository()
+	if err := comments.CreateBatchWithExistingIDs(ctx, []*models.Comment{comment}); err != nil {
+		t.Fatalf("Failed to create comment: %v", err)
+	}
+	defer comments.Delete(ctx, id)
+
+	items, err := postgres.NewSnapshotRepository().GetSnapshotItems(ctx, "comment", models.DefaultTenant,
+		created, created+1, 1, created-1, 0, 1000)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot items: %v", err)
+	}
+	var found *models.SnapshotItem
+	for _, item := range items {
+		if item.ID == id {
+			found = item
+		}
+	}
+	if found == nil || found.Kind != "comment" || found.Parent != 7 || found.Text != "Snapshot test" {
+		t.Fatalf("Expected comment %d in the snapshot items, got %+v", id, found)
+	}
+}
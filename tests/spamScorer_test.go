@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"internship-project/internal/spam"
+)
+
+func TestSpamScorer(t *testing.T) {
+	t.Setenv("SPAM_BLOCKED_DOMAINS", "spam.example")
+	t.Setenv("SPAM_DUPLICATE_THRESHOLD", "3")
+
+	scorer := spam.NewScorer(nil)
+	items := []spam.Content{
+		{ID: 1, Author: "alice", Title: "Show HN: A new Go library", URL: "https://github.com/alice/lib"},
+		{ID: 2, Author: "bob", Title: "Cheap pills", URL: "https://www.shop.spam.example/buy"},
+		{ID: 3, Author: "carol", Text: `<a href="https:&#x2F;&#x2F;example.com">https:&#x2F;&#x2F;example.com</a>`},
+		{ID: 4, Author: "dave", Text: "Buy now"},
+		{ID: 5, Author: "dave", Text: "Buy now"},
+		{ID: 6, Author: "dave", Text: "Buy now"},
+	}
+
+	scores := scorer.Score(context.Background(), items)
+
+	if scores[1] != 0 {
+		t.Errorf("Expected clean story to score 0, got %v", scores[1])
+	}
+	if scores[2] < spam.Threshold() {
+		t.Errorf("Expected blocked subdomain to be flagged, got %v", scores[2])
+	}
+	if scores[3] == 0 {
+		t.Errorf("Expected link-only comment to be scored, got %v", scores[3])
+	}
+	if scores[4] == 0 || scores[4] != scores[6] {
+		t.Errorf("Expected identical posts to share a non-zero score, got %v and %v", scores[4], scores[6])
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestGetAuthorHeatmap(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStatsRepository()
+
+	heatmap, err := repo.GetAuthorHeatmap(ctx, models.DefaultTenant, "testuser")
+	if err != nil {
+		t.Fatalf("Failed to get author heatmap: %v", err)
+	}
+
+	sum := 0
+	for _, cell := range heatmap.Cells {
+		if cell.DayOfWeek < 0 || cell.DayOfWeek > 6 {
+			t.Errorf("Invalid day of week: %d", cell.DayOfWeek)
+		}
+		if cell.Hour < 0 || cell.Hour > 23 {
+			t.Errorf("Invalid hour: %d", cell.Hour)
+		}
+		sum += cell.Count
+	}
+
+	if sum != heatmap.Total {
+		t.Errorf("Expected total %d to equal the sum of cells %d", heatmap.Total, sum)
+	}
+	t.Logf("Heatmap for %s: %d items in %d cells", heatmap.Author, heatmap.Total, len(heatmap.Cells))
+}
+
+func TestRefreshDailyStats(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStatsRepository()
+	today := time.Now().UTC()
+
+	if err := repo.RefreshDailyStats(ctx, today, 5); err != nil {
+		t.Fatalf("Failed to refresh daily stats: %v", err)
+	}
+
+	days, err := repo.GetDailyStats(ctx, models.DefaultTenant, today, today)
+	if err != nil {
+		t.Fatalf("Failed to get daily stats: %v", err)
+	}
+	if len(days) > 1 {
+		t.Fatalf("Expected at most one day, got %d", len(days))
+	}
+
+	for _, day := range days {
+		if day.Day != today.Format(time.DateOnly) {
+			t.Errorf("Expected day %s, got %s", today.Format(time.DateOnly), day.Day)
+		}
+		if len(day.TopDomains) > 5 || len(day.TopAuthors) > 5 {
+			t.Errorf("Expected at most 5 top entries, got %d domains and %d authors",
+				len(day.TopDomains), len(day.TopAuthors))
+		}
+		for i, author := range day.TopAuthors {
+			if author.Rank != i+1 {
+				t.Errorf("Expected rank %d, got %d", i+1, author.Rank)
+			}
+		}
+	}
+}
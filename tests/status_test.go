@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/search"
+	"internship-project/internal/status"
+)
+
+// fakeStatusStore serves the database sections of the status report
+type fakeStatusStore struct {
+	repository.MaintenanceRepository
+	repository.StatsRepository
+	repository.JobRunRepository
+}
+
+func (fakeStatusStore) GetTableStats(ctx context.Context, tables []string) ([]*models.TableStats, error) {
+	return []*models.TableStats{{Table: "stories", LiveRows: 42, TableBytes: 2048, IndexBytes: 1024}}, nil
+}
+
+func (fakeStatusStore) GetItemFreshness(ctx context.Context) ([]*models.ItemFreshness, error) {
+	return []*models.ItemFreshness{{Kind: "story", Newest_Created_At: time.Now().Add(-time.Hour).Unix()}}, nil
+}
+
+func (fakeStatusStore) GetAll(ctx context.Context) ([]*models.JobRun, error) {
+	return []*models.JobRun{{Name: "sync-updates", Runs: 3, Last_Finished_At: time.Now().Unix(), Last_Duration_Ms: 1500}}, nil
+}
+
+func TestStatusReportsEverySection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hn-comments/_count" {
+			w.Write([]byte(`{"count": 7}`))
+			return
+		}
+		w.Write([]byte(`{"count": 1}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	t.Setenv("EVENT_TRANSPORT", "kafka")
+	t.Setenv("KAFKA_BOOTSTRAP_SERVERS", "127.0.0.1:1")
+	t.Setenv("REDIS_ADDR", "127.0.0.1:1")
+
+	store := fakeStatusStore{}
+	collector := &status.Collector{
+		Tables:  store,
+		Stats:   store,
+		JobRuns: store,
+		Search:  search.NewClient(),
+		Timeout: 2 * time.Second,
+	}
+	report := collector.Collect(context.Background())
+
+	if report.Database.Error != "" || len(report.Database.Tables) != 1 || report.Database.Tables[0].Rows != 42 ||
+		report.Database.Tables[0].Bytes != 3072 || len(report.Database.Items) != 1 {
+		t.Errorf("Unexpected database section %+v", report.Database)
+	}
+	if report.Jobs.Error != "" || len(report.Jobs.Runs) != 1 {
+		t.Errorf("Unexpected jobs section %+v", report.Jobs)
+	}
+	if !report.Transport.Checked || report.Transport.Error == "" {
+		t.Errorf("Expected the unreachable broker reported, got %+v", report.Transport)
+	}
+	if report.Redis.Error == "" {
+		t.Errorf("Expected the unreachable Redis reported, got %+v", report.Redis)
+	}
+	if report.Search.Error != "" || len(report.Search.Indexes) != len(search.Kinds()) {
+		t.Fatalf("Unexpected search section %+v", report.Search)
+	}
+	for _, index := range report.Search.Indexes {
+		if index.Kind == "comment" && (index.Index != "hn-comments" || index.Docs != 7) {
+			t.Errorf("Expected 7 documents in hn-comments, got %+v", index)
+		}
+	}
+	if report.OK() {
+		t.Error("Expected the report not OK with Kafka and Redis down")
+	}
+
+	var out strings.Builder
+	if err := report.Print(&out); err != nil {
+		t.Fatalf("Failed to print the report: %v", err)
+	}
+	for _, want := range []string{"stories", "42 rows", "3.0 KiB", "sync-updates", "3 runs", "hn-comments", "7 docs"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the report:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestStatusWithoutDatabase(t *testing.T) {
+	t.Setenv("EVENT_TRANSPORT", "nats")
+	t.Setenv("REDIS_ADDR", "127.0.0.1:1")
+
+	collector := &status.Collector{DatabaseErr: context.DeadlineExceeded, Timeout: time.Second}
+	report := collector.Collect(context.Background())
+	if report.Database.Error != context.DeadlineExceeded.Error() || report.Jobs.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected the connection error in the database sections, got %+v and %+v", report.Database, report.Jobs)
+	}
+	if report.Transport.Checked || report.Transport.Error != "" {
+		t.Errorf("Expected NATS left unchecked, got %+v", report.Transport)
+	}
+	if report.Search.Configured {
+		t.Errorf("Expected no search cluster, got %+v", report.Search)
+	}
+}
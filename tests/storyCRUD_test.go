@@ -3,17 +3,19 @@ package tests
 import (
 	"context"
 	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
 	"internship-project/internal/models"
+	"internship-project/internal/repository"
 	"internship-project/internal/repository/postgres"
 	"internship-project/pkg/database"
 )
 
 func setupTest(t *testing.T) {
-	// Initialize database connection
-	config := database.GetDefaultConfig()
+	// Initialize database connection, in the schema of the test package
+	config := testDBConfig(t)
 	if err := database.Connect(config); err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -22,6 +24,7 @@ func setupTest(t *testing.T) {
 	if err := database.Migrate(); err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
 	}
+	seedTestSchema(t)
 
 	// Check health
 	if err := database.Health(); err != nil {
@@ -243,6 +246,142 @@ func TestGetByDateRange(t *testing.T) {
 	}
 }
 
+func TestGetByFilter(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+
+	minScore := 50
+	maxScore := 500
+	filter := repository.ItemFilter{
+		Author:   "testuser",
+		MinScore: &minScore,
+		MaxScore: &maxScore,
+		Start:    time.Now().Add(-30 * 24 * time.Hour).Unix(),
+		End:      time.Now().Unix(),
+		Limit:    10,
+	}
+
+	stories, err := repo.GetByFilter(ctx, filter)
+	if err != nil {
+		t.Fatalf("Failed to get stories by filter: %v", err)
+	}
+
+	if len(stories) > filter.Limit {
+		t.Errorf("Expected at most %d stories, got %d", filter.Limit, len(stories))
+	}
+
+	for _, story := range stories {
+		if story.Author != filter.Author {
+			t.Errorf("Story ID %d has author %s, expected %s", story.ID, story.Author, filter.Author)
+		}
+		if story.Score < minScore || story.Score > maxScore {
+			t.Errorf("Story ID %d has score %d, outside [%d, %d]", story.ID, story.Score, minScore, maxScore)
+		}
+		if story.Created_At < filter.Start || story.Created_At > filter.End {
+			t.Errorf("Story ID %d created at %d, outside the requested range", story.ID, story.Created_At)
+		}
+	}
+	t.Logf("Found %d stories matching filter", len(stories))
+}
+
+func TestCountByFilter(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+
+	minScore := 50
+	filter := repository.ItemFilter{MinScore: &minScore, Limit: 5}
+
+	count, err := repo.Count(ctx, filter)
+	if err != nil {
+		t.Fatalf("Failed to count stories by filter: %v", err)
+	}
+
+	stories, err := repo.GetByMinScore(ctx, minScore)
+	if err != nil {
+		t.Fatalf("Failed to get stories by minimum score: %v", err)
+	}
+
+	if count != len(stories) {
+		t.Errorf("Expected count %d to ignore the limit and match %d stories", count, len(stories))
+	}
+	t.Logf("Counted %d stories with score >= %d", count, minScore)
+}
+
+func TestGetByFilterSource(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+
+	stories, err := repo.GetByFilter(ctx, repository.ItemFilter{Source: models.SourceHackerNews, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get stories by source: %v", err)
+	}
+
+	for _, story := range stories {
+		if story.Source != models.SourceHackerNews {
+			t.Errorf("Story ID %d has source %s, expected %s", story.ID, story.Source, models.SourceHackerNews)
+		}
+	}
+	t.Logf("Found %d %s stories", len(stories), models.SourceHackerNews)
+}
+
+func TestGetByFilterDomain(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+
+	urls := map[int]string{
+		880201: "https://www.filter-domain.org/a",
+		880202: "http://filter-domain.org:8080/b",
+		880203: "https://FILTER-DOMAIN.org?ref=x",
+		880204: "https://other.org/filter-domain.org",
+	}
+	var stories []*models.Story
+	for id, url := range urls {
+		stories = append(stories, &models.Story{
+			ID: id, Type: "story", Title: "Domain filter", URL: url, Author: "domainuser", Created_At: time.Now().Unix(),
+		})
+		defer repo.Delete(ctx, id)
+	}
+	if err := repo.CreateBatchWithExistingIDs(ctx, stories); err != nil {
+		t.Fatalf("Failed to create stories: %v", err)
+	}
+
+	for _, domain := range []string{"filter-domain.org", "www.filter-domain.org"} {
+		filter := repository.ItemFilter{Author: "domainuser", Domain: domain}
+		found, err := repo.GetByFilter(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to get stories of %s: %v", domain, err)
+		}
+		var ids []int
+		for _, story := range found {
+			ids = append(ids, story.ID)
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, []int{880201, 880202, 880203}) {
+			t.Errorf("Expected the stories linking to %s, got %v", domain, ids)
+		}
+
+		count, err := repo.Count(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to count stories of %s: %v", domain, err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 stories linking to %s, counted %d", domain, count)
+		}
+	}
+}
+
 func TestDeleteStory(t *testing.T) {
 	setupTest(t)
 	defer teardownTest()
@@ -623,3 +762,46 @@ func TestCreateBatchStoriesWithExistingIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestStoryUpsertBatchCounts(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewStoryRepository()
+
+	baseID := 900000 + rand.Intn(10000)
+	stories := []*models.Story{
+		{ID: baseID, Type: "story", Title: "Upsert Story 1", URL: "https://example.com/upsert-1",
+			Score: 1, Author: "upsertuser", Created_At: time.Now().Unix(), Comments_ids: []int{}},
+		{ID: baseID + 1, Type: "story", Title: "Upsert Story 2", URL: "https://example.com/upsert-2",
+			Score: 2, Author: "upsertuser", Created_At: time.Now().Unix(), Comments_ids: []int{}},
+	}
+	defer repo.DeleteByAuthor(ctx, "upsertuser")
+
+	counts, err := repo.UpsertBatch(ctx, stories)
+	if err != nil {
+		t.Fatalf("Failed to upsert stories: %v", err)
+	}
+	if counts != (repository.UpsertCounts{Inserted: 2}) {
+		t.Errorf("Expected 2 inserted stories, got %s", counts)
+	}
+
+	// Same data again: nothing to update
+	counts, err = repo.UpsertBatch(ctx, stories)
+	if err != nil {
+		t.Fatalf("Failed to upsert unchanged stories: %v", err)
+	}
+	if counts != (repository.UpsertCounts{Skipped: 2}) {
+		t.Errorf("Expected 2 unchanged stories, got %s", counts)
+	}
+
+	stories[0].Score = 10
+	counts, err = repo.UpsertBatch(ctx, stories)
+	if err != nil {
+		t.Fatalf("Failed to upsert changed stories: %v", err)
+	}
+	if counts != (repository.UpsertCounts{Updated: 1, Skipped: 1}) {
+		t.Errorf("Expected 1 updated and 1 unchanged story, got %s", counts)
+	}
+}
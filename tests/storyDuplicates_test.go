@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/search"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	cases := map[string]string{
+		"https://www.Example.com/post/":                  "example.com/post",
+		"http://example.com:80/post#comments":            "example.com/post",
+		"https://example.com/post?utm_source=hn&b=2&a=1": "example.com/post?a=1&b=2",
+		"https://example.com/post?ref=hn&fbclid=x":       "example.com/post",
+		"https://example.com:8443/Post":                  "example.com:8443/Post",
+		"https://example.com":                            "example.com",
+		"":                                               "",
+		"not a url":                                      "",
+	}
+	for raw, want := range cases {
+		if got := models.CanonicalURL(raw); got != want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	duplicates := models.FindDuplicates([]*models.Story{
+		{ID: 1, URL: "https://example.com/a", Score: 5},
+		{ID: 2, URL: "http://www.example.com/a/?utm_medium=x", Score: 40},
+		{ID: 3, URL: "https://example.com/a#top", Score: 40},
+		{ID: 4, URL: "https://example.com/b", Score: 100},
+		{ID: 5, URL: "", Score: 1},
+	})
+
+	if len(duplicates) != 3 {
+		t.Fatalf("Expected 3 linked stories, got %d", len(duplicates))
+	}
+	for _, d := range duplicates {
+		if d.CanonicalID != 2 || d.CanonicalURL != "example.com/a" {
+			t.Errorf("Expected story %d linked to story 2, got %+v", d.StoryID, d)
+		}
+	}
+}
+
+func TestCollapseSearchHits(t *testing.T) {
+	story := func(id int, score float64) search.Hit {
+		return search.Hit{Kind: "story", ID: id, Item: map[string]interface{}{"score": score}}
+	}
+	hits := []search.Hit{
+		story(1, 5),
+		{Kind: "comment", ID: 1, Item: map[string]interface{}{}},
+		story(2, 40),
+		story(3, 10),
+		story(4, 1),
+	}
+	canonical := map[int]int{1: 2, 2: 2, 3: 2, 1000: 2}
+
+	collapsed := search.Collapse(hits, canonical)
+	if len(collapsed) != 3 {
+		t.Fatalf("Expected 3 hits after collapsing, got %d", len(collapsed))
+	}
+	top := collapsed[0]
+	if top.ID != 2 {
+		t.Errorf("Expected the highest-scored story to take the group's rank, got %d", top.ID)
+	}
+	if len(top.Duplicates) != 2 || top.Duplicates[0].ID != 1 || top.Duplicates[1].ID != 3 {
+		t.Errorf("Unexpected duplicates %+v", top.Duplicates)
+	}
+	for _, d := range top.Duplicates {
+		if len(d.Duplicates) != 0 {
+			t.Errorf("Expected duplicates not to nest, got %+v", d)
+		}
+	}
+	if collapsed[1].Kind != "comment" || collapsed[2].ID != 4 {
+		t.Errorf("Expected unlinked hits untouched, got %+v", collapsed[1:])
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"internship-project/internal/cronjob"
+	"internship-project/internal/redis"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/services"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := redis.NewMemoryCache()
+
+	if found, err := cache.IsItemInCache(ctx, "ids", 1); err != nil || found {
+		t.Fatalf("Expected an empty cache, got %v, %v", found, err)
+	}
+	if err := cache.CacheID(ctx, "ids", []int{1, 2}); err != nil {
+		t.Fatalf("Failed to cache IDs: %v", err)
+	}
+	if found, _ := cache.IsItemInCache(ctx, "ids", 2); !found {
+		t.Error("Expected ID 2 cached")
+	}
+
+	if err := cache.CacheJSONBatch(ctx, map[string]interface{}{"item:story:1": map[string]int{"score": 5}}, 0); err != nil {
+		t.Fatalf("Failed to cache values: %v", err)
+	}
+	var story map[string]int
+	if found, err := cache.GetCachedJSON(ctx, "item:story:1", &story); err != nil || !found || story["score"] != 5 {
+		t.Fatalf("Expected the cached story, got %v, %v, %v", story, found, err)
+	}
+	if err := cache.PublishInvalidation(ctx, "item:story:1"); err != nil {
+		t.Fatalf("Failed to invalidate: %v", err)
+	}
+	if found, _ := cache.GetCachedJSON(ctx, "item:story:1", &story); found {
+		t.Error("Expected the invalidated story deleted")
+	}
+	if keys := cache.Invalidated(); !slices.Equal(keys, []string{"item:story:1"}) {
+		t.Errorf("Expected the invalidated key recorded, got %v", keys)
+	}
+}
+
+// newPipelineSync creates a sync of the HackerNews API at baseURL
+func newPipelineSync(t *testing.T, baseURL string) *cronjob.DataSyncService {
+	t.Setenv("HN_API_BASE_URL", baseURL)
+	t.Setenv("HN_API_FIXTURES_MODE", "")
+	t.Setenv("EVENT_TRANSPORT", "memory")
+	t.Setenv("KNOWN_ITEMS_ENABLED", "false")
+
+	client := services.NewHackerNewsApiClient()
+	d, err := cronjob.NewDataSyncService(client,
+		services.NewUserApiService(client), services.NewStoryApiService(client),
+		services.NewCommentApiService(client), services.NewJobApiService(client),
+		services.NewAskApiService(client), services.NewPollApiService(client),
+		services.NewPollOptionApiService(client), services.NewUpdateApiService(client))
+	if err != nil {
+		t.Fatalf("Failed to create data sync service: %v", err)
+	}
+	return d
+}
+
+func TestSyncUpdatesPublishesAndCaches(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	var cachedFetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/updates.json":
+			w.Write([]byte(`{"items":[880101,880102],"profiles":[]}`))
+		case "/maxitem.json":
+			w.Write([]byte(`880102`))
+		case "/item/880101.json":
+			cachedFetches.Add(1)
+			w.Write([]byte("null\n"))
+		case "/item/880102.json":
+			w.Write([]byte(`{"id":880102,"type":"story","by":"pg","time":1700000000,"title":"Synced","score":3}`))
+		default:
+			w.Write([]byte("null\n"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	d := newPipelineSync(t, server.URL)
+	publisher := &recordingPublisher{published: map[string][]string{}}
+	d.SetPublisher(publisher)
+	cache := redis.NewMemoryCache()
+	cache.CacheID(ctx, "ids", []int{880101})
+	d.SetCache(cache)
+	defer postgres.NewStoryRepository().Delete(ctx, 880102)
+
+	d.SyncUpdates(ctx)
+
+	if cachedFetches.Load() != 0 {
+		t.Error("Expected the cached item not fetched again")
+	}
+	if values := publisher.values("StoriesTopic"); !slices.Equal(values, []string{"880102"}) {
+		t.Errorf("Expected story 880102 published, got %v", values)
+	}
+	for _, id := range []int{880101, 880102} {
+		if found, _ := cache.IsItemInCache(ctx, "ids", id); !found {
+			t.Errorf("Expected item %d cached", id)
+		}
+	}
+	if !slices.Contains(cache.Invalidated(), redis.ItemKey("story", 880102)) {
+		t.Errorf("Expected the cached copy of the story invalidated, got %v", cache.Invalidated())
+	}
+
+	// A failing event bus does not keep the saved items from being cached
+	d.SetPublisher(&flakyPublisher{down: true})
+	cache = redis.NewMemoryCache()
+	d.SetCache(cache)
+	d.SyncUpdates(ctx)
+	if found, _ := cache.IsItemInCache(ctx, "ids", 880102); !found {
+		t.Error("Expected the saved story cached even though publishing failed")
+	}
+}
+
+func TestSyncSkipsInlineIndexingWithConsumers(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	var bulkRequests atomic.Int32
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bulkRequests.Add(1)
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer search.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/updates.json":
+			w.Write([]byte(`{"items":[880103],"profiles":[]}`))
+		case "/maxitem.json":
+			w.Write([]byte(`880103`))
+		case "/item/880103.json":
+			w.Write([]byte(`{"id":880103,"type":"story","by":"pg","time":1700000000,"title":"Indexed once","score":3}`))
+		default:
+			w.Write([]byte("null\n"))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENSEARCH_URL", search.URL)
+	t.Setenv("OPENSEARCH_INDEXER_ENABLED", "true")
+	t.Setenv("SINKS", "")
+	t.Setenv("INDEXER_CONSUMERS_ENABLED", "true")
+
+	ctx := context.Background()
+	d := newPipelineSync(t, server.URL)
+	publisher := &recordingPublisher{published: map[string][]string{}}
+	d.SetPublisher(publisher)
+	d.SetCache(redis.NewMemoryCache())
+	defer postgres.NewStoryRepository().Delete(ctx, 880103)
+
+	d.SyncUpdates(ctx)
+
+	if values := publisher.values("StoriesTopic"); !slices.Equal(values, []string{"880103"}) {
+		t.Errorf("Expected story 880103 published for the consumers, got %v", values)
+	}
+	if n := bulkRequests.Load(); n != 0 {
+		t.Errorf("Expected the sync to leave indexing to the consumers, got %d requests", n)
+	}
+}
@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/tasks"
+	"internship-project/pkg/database"
+)
+
+// fakeTaskStore keeps the task queue in memory
+type fakeTaskStore struct {
+	mu    sync.Mutex
+	tasks []*models.Task
+}
+
+func (f *fakeTaskStore) Enqueue(ctx context.Context, task *models.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	task.ID = database.NewUUIDv7()
+	task.Status = models.TaskPending
+	if task.MaxAttempts == 0 {
+		task.MaxAttempts = 5
+	}
+	stored := *task
+	f.tasks = append(f.tasks, &stored)
+	return nil
+}
+
+func (f *fakeTaskStore) Claim(ctx context.Context, types []string, now, leaseUntil int64) (*models.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, task := range f.tasks {
+		due := (task.Status == models.TaskPending || task.Status == models.TaskRunning) && task.RunAfter <= now
+		if due && slices.Contains(types, task.Type) {
+			task.Status = models.TaskRunning
+			task.Attempts++
+			task.RunAfter = leaseUntil
+			claimed := *task
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+// find returns the task with the ID, or nil
+func (f *fakeTaskStore) find(id string) *models.Task {
+	for _, task := range f.tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func (f *fakeTaskStore) update(id string, change func(task *models.Task)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	change(f.find(id))
+	return nil
+}
+
+func (f *fakeTaskStore) Complete(ctx context.Context, id string) error {
+	return f.update(id, func(task *models.Task) { task.Status = models.TaskDone })
+}
+
+func (f *fakeTaskStore) Retry(ctx context.Context, id string, lastError string, runAfter int64) error {
+	return f.update(id, func(task *models.Task) {
+		task.Status, task.LastError, task.RunAfter = models.TaskPending, lastError, runAfter
+	})
+}
+
+func (f *fakeTaskStore) Fail(ctx context.Context, id string, lastError string) error {
+	return f.update(id, func(task *models.Task) { task.Status, task.LastError = models.TaskFailed, lastError })
+}
+
+func (f *fakeTaskStore) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	task := *f.find(id)
+	return &task, nil
+}
+
+func (f *fakeTaskStore) List(ctx context.Context, status string, limit int) ([]*models.Task, error) {
+	return nil, nil
+}
+
+func TestTaskWorkerRetriesFailedAttempts(t *testing.T) {
+	t.Setenv("TASKS_RETRY_BACKOFF", "1m")
+	t.Setenv("TASKS_MAX_ATTEMPTS", "3")
+	store := &fakeTaskStore{}
+	worker := tasks.NewWorker(store)
+	ctx := context.Background()
+
+	var runs []int
+	worker.Register("reindex-item", func(ctx context.Context, payload json.RawMessage) error {
+		var body struct {
+			IDs []int `json:"ids"`
+		}
+		if err := json.Unmarshal(payload, &body); err != nil {
+			return err
+		}
+		runs = append(runs, body.IDs...)
+		if len(runs) == 1 {
+			return errors.New("event bus unavailable")
+		}
+		return nil
+	})
+
+	if _, err := worker.Enqueue(ctx, "unknown", nil, 0); !errors.Is(err, tasks.ErrUnknownType) {
+		t.Fatalf("Expected an unknown type to be rejected, got %v", err)
+	}
+	task, err := worker.Enqueue(ctx, "reindex-item", map[string][]int{"ids": {42}}, 0)
+	if err != nil {
+		t.Fatalf("Failed to enqueue the task: %v", err)
+	}
+	if task.MaxAttempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", task.MaxAttempts)
+	}
+
+	if ran, err := worker.RunNext(ctx); !ran || err != nil {
+		t.Fatalf("Expected the task to run, got %v, %v", ran, err)
+	}
+	stored, _ := store.GetByID(ctx, task.ID)
+	if stored.Status != models.TaskPending || stored.Attempts != 1 || stored.LastError != "event bus unavailable" {
+		t.Fatalf("Expected a pending retry, got %+v", stored)
+	}
+	if wait := stored.RunAfter - time.Now().Unix(); wait < 55 || wait > 60 {
+		t.Errorf("Expected the retry in a minute, got %ds", wait)
+	}
+
+	// Not due before the backoff elapsed
+	if ran, _ := worker.RunNext(ctx); ran {
+		t.Fatal("Expected the retry to wait for its backoff")
+	}
+	store.update(task.ID, func(task *models.Task) { task.RunAfter = 0 })
+	if ran, err := worker.RunNext(ctx); !ran || err != nil {
+		t.Fatalf("Expected the retry to run, got %v, %v", ran, err)
+	}
+	stored, _ = store.GetByID(ctx, task.ID)
+	if stored.Status != models.TaskDone || stored.Attempts != 2 || !slices.Equal(runs, []int{42, 42}) {
+		t.Errorf("Expected the task done on its second attempt, got %+v after runs %v", stored, runs)
+	}
+	if ran, err := worker.RunNext(ctx); ran || err != nil {
+		t.Errorf("Expected an empty queue, got %v, %v", ran, err)
+	}
+}
+
+func TestTaskWorkerFailsOnLastAttempt(t *testing.T) {
+	t.Setenv("TASKS_MAX_ATTEMPTS", "1")
+	store := &fakeTaskStore{}
+	worker := tasks.NewWorker(store)
+	ctx := context.Background()
+
+	worker.Register("enrich", func(ctx context.Context, payload json.RawMessage) error {
+		panic("nil plugin")
+	})
+	task, err := worker.Enqueue(ctx, "enrich", map[string][]int{"ids": {1}}, 0)
+	if err != nil {
+		t.Fatalf("Failed to enqueue the task: %v", err)
+	}
+	if ran, err := worker.RunNext(ctx); !ran || err != nil {
+		t.Fatalf("Expected the task to run, got %v, %v", ran, err)
+	}
+	stored, _ := store.GetByID(ctx, task.ID)
+	if stored.Status != models.TaskFailed || !strings.Contains(stored.LastError, "panic: nil plugin") {
+		t.Errorf("Expected the panic to fail the task, got %+v", stored)
+	}
+
+	// A task whose worker died during its last attempt fails when its lease expires
+	task, _ = worker.Enqueue(ctx, "enrich", nil, 0)
+	store.update(task.ID, func(task *models.Task) { task.Status, task.Attempts = models.TaskRunning, 1 })
+	if ran, err := worker.RunNext(ctx); !ran || err != nil {
+		t.Fatalf("Expected the expired task to be claimed, got %v, %v", ran, err)
+	}
+	stored, _ = store.GetByID(ctx, task.ID)
+	if stored.Status != models.TaskFailed || !strings.Contains(stored.LastError, "lease expired") {
+		t.Errorf("Expected the expired task to fail without running, got %+v", stored)
+	}
+}
+
+func TestTaskRepository(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	repo := postgres.NewTaskRepository()
+	ctx := context.Background()
+
+	task := &models.Task{Type: "reindex-item", Payload: json.RawMessage(`{"ids": [7]}`)}
+	if err := repo.Enqueue(ctx, task); err != nil {
+		t.Fatalf("Failed to enqueue task: %v", err)
+	}
+	if task.ID == "" || task.Status != models.TaskPending || task.MaxAttempts != 5 {
+		t.Fatalf("Unexpected enqueued task %+v", task)
+	}
+
+	now := time.Now().Unix()
+	if claimed, err := repo.Claim(ctx, []string{"enrich"}, now, now+60); err != nil || claimed != nil {
+		t.Fatalf("Expected no task of another type, got %+v, %v", claimed, err)
+	}
+	claimed, err := repo.Claim(ctx, []string{"reindex-item"}, now, now+60)
+	if err != nil || claimed == nil {
+		t.Fatalf("Failed to claim task: %+v, %v", claimed, err)
+	}
+	if claimed.ID != task.ID || claimed.Status != models.TaskRunning || claimed.Attempts != 1 || claimed.RunAfter != now+60 {
+		t.Errorf("Unexpected claimed task %+v", claimed)
+	}
+	if string(claimed.Payload) != `{"ids": [7]}` {
+		t.Errorf("Unexpected payload %s", claimed.Payload)
+	}
+
+	// Leased until it expires
+	if again, err := repo.Claim(ctx, []string{"reindex-item"}, now+1, now+61); err != nil || again != nil {
+		t.Fatalf("Expected the leased task not to be claimed, got %+v, %v", again, err)
+	}
+	if again, err := repo.Claim(ctx, []string{"reindex-item"}, now+60, now+120); err != nil || again == nil || again.Attempts != 2 {
+		t.Fatalf("Expected the expired lease to be claimed again, got %+v, %v", again, err)
+	}
+
+	if err := repo.Retry(ctx, task.ID, "timeout", now+300); err != nil {
+		t.Fatalf("Failed to retry task: %v", err)
+	}
+	if err := repo.Fail(ctx, task.ID, "gave up"); err != nil {
+		t.Fatalf("Failed to fail task: %v", err)
+	}
+	stored, err := repo.GetByID(ctx, task.ID)
+	if err != nil || stored.Status != models.TaskFailed || stored.LastError != "gave up" || stored.Attempts != 2 {
+		t.Errorf("Unexpected stored task %+v, %v", stored, err)
+	}
+
+	failed, err := repo.List(ctx, models.TaskFailed, 10)
+	if err != nil || len(failed) == 0 || failed[0].ID != task.ID {
+		t.Errorf("Expected the failed task listed first, got %v, %v", failed, err)
+	}
+	pending, err := repo.List(ctx, models.TaskPending, 10)
+	if err != nil {
+		t.Fatalf("Failed to list pending tasks: %v", err)
+	}
+	for _, other := range pending {
+		if other.ID == task.ID {
+			t.Errorf("Expected the failed task not to be listed as pending")
+		}
+	}
+}
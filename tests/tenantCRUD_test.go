@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestTenantScopedFilter(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	tenantRepo := postgres.NewTenantRepository()
+	randomNum := rand.Intn(100000)
+
+	tenant := &models.Tenant{
+		Name:                fmt.Sprintf("tenant-%d", randomNum),
+		API_Key:             fmt.Sprintf("key-%d", randomNum),
+		Requests_Per_Minute: 60,
+		Created_At:          time.Now().Unix(),
+	}
+	if err := tenantRepo.Create(ctx, tenant); err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+	defer tenantRepo.Delete(ctx, tenant.Name)
+
+	found, err := tenantRepo.GetByAPIKey(ctx, tenant.API_Key)
+	if err != nil {
+		t.Fatalf("Failed to get tenant by API key: %v", err)
+	}
+	if found.Name != tenant.Name {
+		t.Errorf("Expected tenant %s, got %s", tenant.Name, found.Name)
+	}
+
+	// A tenant without sources or keywords collects no synced rows, so it starts empty
+	count, err := postgres.NewStoryRepository().Count(ctx, repository.ItemFilter{Tenant: tenant.Name})
+	if err != nil {
+		t.Fatalf("Failed to count tenant stories: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no stories for new tenant, got %d", count)
+	}
+}
+
+func TestTenantItemRulesKeepDataDisjoint(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	tenantRepo := postgres.NewTenantRepository()
+	stories := postgres.NewStoryRepository()
+	comments := postgres.NewCommentRepository()
+	randomNum := rand.Intn(100000)
+
+	// One tenant collects a keyword from HackerNews, the other every Lobsters story
+	keyword := fmt.Sprintf("rustacean%d", randomNum)
+	rust := &models.Tenant{
+		Name:       fmt.Sprintf("rules-a-%d", randomNum),
+		API_Key:    fmt.Sprintf("rules-a-key-%d", randomNum),
+		Created_At: time.Now().Unix(),
+		Sources:    []string{models.SourceHackerNews},
+		Keywords:   []string{keyword},
+	}
+	lobsters := &models.Tenant{
+		Name:       fmt.Sprintf("rules-b-%d", randomNum),
+		API_Key:    fmt.Sprintf("rules-b-key-%d", randomNum),
+		Created_At: time.Now().Unix(),
+		Sources:    []string{"lobsters"},
+	}
+	for _, tenant := range []*models.Tenant{rust, lobsters} {
+		if err := tenantRepo.Create(ctx, tenant); err != nil {
+			t.Fatalf("Failed to create tenant: %v", err)
+		}
+		defer tenantRepo.Delete(ctx, tenant.Name)
+	}
+	found, err := tenantRepo.GetByName(ctx, rust.Name)
+	if err != nil || len(found.Keywords) != 1 || found.Keywords[0] != keyword {
+		t.Fatalf("Expected the keyword stored, got %+v (%v)", found, err)
+	}
+
+	baseID := 930000000 + randomNum*10
+	author := fmt.Sprintf("rules-%d", randomNum)
+	synced := []*models.Story{
+		{ID: baseID, Type: "story", Title: "Why I became a " + strings.ToUpper(keyword), Author: author, Created_At: time.Now().Unix(), Comments_ids: []int{baseID + 3}},
+		{ID: baseID + 1, Type: "story", Title: "A Lobsters story", Author: author, Created_At: time.Now().Unix(), Comments_ids: []int{}, Source: "lobsters"},
+		{ID: baseID + 2, Type: "story", Title: "Anything else", Author: author, Created_At: time.Now().Unix(), Comments_ids: []int{}},
+	}
+	for _, story := range synced {
+		defer stories.Delete(ctx, story.ID)
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, synced); err != nil {
+		t.Fatalf("Failed to store stories: %v", err)
+	}
+	reply := &models.Comment{ID: baseID + 3, Type: "comment", Text: "Agreed", Author: author, Parent: baseID, Replies: []int{}, Created_At: time.Now().Unix()}
+	defer comments.Delete(ctx, reply.ID)
+	if err := comments.CreateBatchWithExistingIDs(ctx, []*models.Comment{reply}); err != nil {
+		t.Fatalf("Failed to store comment: %v", err)
+	}
+
+	ids := []int{baseID, baseID + 1, baseID + 2}
+	for tenant, want := range map[string]int{rust.Name: baseID, lobsters.Name: baseID + 1, models.DefaultTenant: baseID + 2} {
+		got, err := stories.GetByIDs(ctx, tenant, ids)
+		if err != nil {
+			t.Fatalf("Failed to get the stories of %s: %v", tenant, err)
+		}
+		if len(got) != 1 || got[0].ID != want {
+			t.Errorf("Expected only story %d in tenant %s, got %d stories", want, tenant, len(got))
+		}
+	}
+
+	// The reply joins the tenant of its story
+	for tenant, want := range map[string]int{rust.Name: 1, lobsters.Name: 0} {
+		count, err := comments.Count(ctx, repository.ItemFilter{Tenant: tenant, Author: author})
+		if err != nil {
+			t.Fatalf("Failed to count the comments of %s: %v", tenant, err)
+		}
+		if count != want {
+			t.Errorf("Expected %d comments in tenant %s, got %d", want, tenant, count)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestQuotaExceeded(t *testing.T) {
+	for _, c := range []struct {
+		quota models.Quota
+		want  bool
+	}{
+		{models.Quota{Used: 2, Limit: 3}, false},
+		{models.Quota{Used: 3, Limit: 3}, true},
+		{models.Quota{Used: 5, Limit: 3}, true},
+		{models.Quota{Used: 500, Limit: 0}, false}, // unlimited
+	} {
+		if got := c.quota.Exceeded(); got != c.want {
+			t.Errorf("Expected Exceeded() of %+v to be %v", c.quota, c.want)
+		}
+	}
+}
+
+func TestTenantQuotaUsage(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	tenants := postgres.NewTenantRepository()
+	n := rand.Intn(100000)
+	tenant := &models.Tenant{
+		Name:               fmt.Sprintf("quota-%d", n),
+		API_Key:            fmt.Sprintf("quota-key-%d", n),
+		Max_Saved_Searches: 2,
+		Max_Watches:        -1,
+		Created_At:         time.Now().Unix(),
+	}
+	if err := tenants.Create(ctx, tenant); err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+	defer tenants.Delete(ctx, tenant.Name)
+
+	found, err := tenants.GetByAPIKey(ctx, tenant.API_Key)
+	if err != nil {
+		t.Fatalf("Failed to get tenant: %v", err)
+	}
+	if found.Max_Saved_Searches != 2 || found.Max_Watches != -1 || found.Max_Webhook_Endpoints != 0 {
+		t.Errorf("Expected the quotas of the key record, got %+v", found)
+	}
+
+	follows := postgres.NewFollowRepository()
+	for _, query := range []string{"quota one", "quota two", "quota one"} {
+		search, err := follows.SaveSearch(ctx, tenant.Name, query)
+		if err != nil {
+			t.Fatalf("Failed to save search: %v", err)
+		}
+		defer follows.DeleteSavedSearch(ctx, tenant.Name, search.ID)
+	}
+
+	story := &models.Story{ID: 880001, Type: "story", Title: "Watched", Author: "quotauser", Created_At: time.Now().Unix()}
+	if err := postgres.NewStoryRepository().CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	defer postgres.NewStoryRepository().Delete(ctx, story.ID)
+	watches := postgres.NewWatchRepository()
+	for _, url := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/a"} {
+		watch := &models.Watch{Item_ID: story.ID, Webhook_URL: url, Score_Delta: 10}
+		if err := watches.Create(ctx, tenant.Name, watch); err != nil {
+			t.Fatalf("Failed to create watch: %v", err)
+		}
+		defer watches.Delete(ctx, tenant.Name, watch.ID)
+	}
+
+	usage, err := tenants.GetQuotaUsage(ctx, tenant.Name)
+	if err != nil {
+		t.Fatalf("Failed to get quota usage: %v", err)
+	}
+	if usage.Saved_Searches != 2 || usage.Watches != 3 || usage.Webhook_Endpoints != 2 {
+		t.Errorf("Expected 2 saved searches, 3 watches and 2 endpoints, got %+v", usage)
+	}
+}
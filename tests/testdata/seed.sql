@@ -0,0 +1,30 @@
+-- Rows the repository tests read, update or count by hard-coded ID; loaded into each fresh test
+-- schema right after the migrations
+SELECT ensure_month_partitions('stories', 1735689600, 1735689600);
+SELECT ensure_month_partitions('comments', 1735689600, 1735689600);
+
+INSERT INTO stories (id, type, title, url, score, author, created_at, comments_ids, comments_count)
+VALUES (1012, 'story', 'Seeded Story: Understanding Go Repository Pattern', 'https://example.com/go-patterns', 90, 'testuser', 1735689600, '{}', 0)
+ON CONFLICT (id, created_at) DO NOTHING;
+
+INSERT INTO asks (id, type, title, text, score, author, reply_ids, replies_count, created_at)
+VALUES (852, 'ask', 'Ask HN: How do you implement clean architecture in Go?', 'Looking for real-world examples.', 60, 'enhanced_curious_dev', '{101,102,103}', 3, 1735689600)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO comments (id, type, text, author, created_at, parent_id, reply_ids)
+VALUES (3018, 'comment', 'Seeded test comment', 'enhanced_testuser', 1735689600, 452, '{}')
+ON CONFLICT (id, created_at) DO NOTHING;
+
+INSERT INTO jobs (id, type, title, text, url, score, author, created_at)
+VALUES (35, 'job', 'Go Developer at TechCorp', 'Seeded job posting.', 'https://techcorp.com/careers/go-dev', 70, 'enhanced_techcorp_hr', 1735689600)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO polls (id, type, title, score, author, poll_options, reply_ids, created_at)
+VALUES (5001, 'poll', 'Which Go web framework do you use?', 60, 'enhanced_poll_creator', '{153,340}', '{}', 1735689600)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO poll_options (id, type, poll_id, author, option_text, created_at, votes)
+VALUES
+    (153, 'pollopt', 5001, 'enhanced_poll_creator', 'Gin Framework', 1735689600, 12),
+    (340, 'pollopt', 5001, 'enhanced_poll_creator', 'Echo Framework', 1735689600, 8)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+)
+
+func TestGetTimeline(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	repo := postgres.NewTimelineRepository()
+
+	firstPage, err := repo.GetTimeline(ctx, models.DefaultTenant, models.TimelineCursor{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to get timeline: %v", err)
+	}
+
+	if len(firstPage) == 0 {
+		t.Skip("No items in the timeline")
+	}
+
+	for i := 1; i < len(firstPage); i++ {
+		if firstPage[i].Created_At > firstPage[i-1].Created_At {
+			t.Errorf("Timeline not ordered: item %d (%d) is newer than item %d (%d)",
+				firstPage[i].ID, firstPage[i].Created_At, firstPage[i-1].ID, firstPage[i-1].Created_At)
+		}
+	}
+
+	last := firstPage[len(firstPage)-1]
+	secondPage, err := repo.GetTimeline(ctx, models.DefaultTenant, models.TimelineCursor{Created_At: last.Created_At, ID: last.ID}, 10)
+	if err != nil {
+		t.Fatalf("Failed to get next timeline page: %v", err)
+	}
+
+	for _, item := range secondPage {
+		if item.Created_At > last.Created_At {
+			t.Errorf("Item %d on the second page is newer than the cursor", item.ID)
+		}
+	}
+	t.Logf("Timeline pages: %d + %d items", len(firstPage), len(secondPage))
+}
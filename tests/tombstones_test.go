@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"internship-project/internal/models"
+	"internship-project/internal/repository/postgres"
+	"internship-project/internal/search"
+)
+
+func TestTombstoneRemovesStoredItems(t *testing.T) {
+	setupTest(t)
+	defer teardownTest()
+
+	ctx := context.Background()
+	changes := postgres.NewChangeRepository()
+	if _, err := changes.SequenceChanges(ctx, 100000); err != nil {
+		t.Fatalf("Failed to number the earlier changes: %v", err)
+	}
+	_, since, err := changes.GetChangeLogBounds(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the change log bounds: %v", err)
+	}
+
+	id := 900000000 + rand.Intn(1000000)
+	stories := postgres.NewStoryRepository()
+	story := &models.Story{
+		ID:           id,
+		Type:         "story",
+		Title:        "Tombstone test",
+		Author:       "testuser",
+		Created_At:   time.Now().Unix(),
+		Comments_ids: []int{},
+	}
+	if err := stories.CreateBatchWithExistingIDs(ctx, []*models.Story{story}); err != nil {
+		t.Fatalf("Failed to create story: %v", err)
+	}
+	defer stories.Delete(ctx, id)
+
+	repo := postgres.NewTombstoneRepository()
+	tombstones, err := repo.Remove(ctx, []int{id, id + 1}, models.TombstoneDeleted)
+	if err != nil {
+		t.Fatalf("Failed to remove story: %v", err)
+	}
+	if len(tombstones) != 1 || tombstones[0].ID != id || tombstones[0].Kind != "story" ||
+		tombstones[0].Reason != models.TombstoneDeleted || tombstones[0].Emitted_At != 0 {
+		t.Fatalf("Expected a pending tombstone of story %d alone, got %+v", id, tombstones)
+	}
+	var item models.Story
+	if err := json.Unmarshal(tombstones[0].Item, &item); err != nil || item.Title != story.Title {
+		t.Errorf("Expected the tombstone to keep the stored row, got %s (%v)", tombstones[0].Item, err)
+	}
+	if _, err := stories.GetByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected the story to be gone, got %v", err)
+	}
+
+	if _, err := changes.SequenceChanges(ctx, 100); err != nil {
+		t.Fatalf("Failed to number changes: %v", err)
+	}
+	logged, err := changes.GetChangesSince(ctx, models.DefaultTenant, since, 10)
+	if err != nil {
+		t.Fatalf("Failed to get changes: %v", err)
+	}
+	if n := len(logged); n != 2 || logged[n-1].ID != id || logged[n-1].Operation != "delete" {
+		t.Errorf("Expected the insert then the delete of story %d, got %v", id, logged)
+	}
+
+	pending, err := repo.GetPending(ctx, 1000)
+	if err != nil || !containsTombstone(pending, id) {
+		t.Fatalf("Expected story %d pending, got %v (%v)", id, pending, err)
+	}
+	if err := repo.MarkEmitted(ctx, []int{id}); err != nil {
+		t.Fatalf("Failed to mark tombstone emitted: %v", err)
+	}
+	if pending, err := repo.GetPending(ctx, 1000); err != nil || containsTombstone(pending, id) {
+		t.Errorf("Expected story %d emitted, got pending %v (%v)", id, pending, err)
+	}
+	if _, err := repo.PruneEmitted(ctx, time.Now().Add(time.Minute).UnixMilli()); err != nil {
+		t.Errorf("Failed to prune tombstones: %v", err)
+	}
+}
+
+func containsTombstone(tombstones []*models.Tombstone, id int) bool {
+	for _, t := range tombstones {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTombstoneReason(t *testing.T) {
+	cases := []struct {
+		item models.HNItem
+		want string
+	}{
+		{models.HNItem{ID: 1, Type: "story", Title: "Live"}, ""},
+		{models.HNItem{ID: 2, Type: "comment", Deleted: true}, models.TombstoneDeleted},
+		{models.HNItem{ID: 3, Type: "story", Title: "Flagged", Dead: true}, models.TombstoneDead},
+	}
+	for _, c := range cases {
+		if got := c.item.TombstoneReason(); got != c.want {
+			t.Errorf("Item %d: expected reason %q, got %q", c.item.ID, c.want, got)
+		}
+	}
+}
+
+func TestSearchDeleteIgnoresMissingDocuments(t *testing.T) {
+	var deleted []string
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action struct {
+				Delete struct {
+					Index string `json:"_index"`
+					ID    string `json:"_id"`
+				} `json:"delete"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || action.Delete.Index != "hn-comments" {
+				t.Errorf("Unexpected bulk action %s (%v)", scanner.Bytes(), err)
+			}
+			deleted = append(deleted, action.Delete.ID)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": true,
+			"items": []interface{}{
+				map[string]interface{}{"delete": map[string]interface{}{"_id": "7", "status": 200}},
+				map[string]interface{}{"delete": map[string]interface{}{"_id": "8", "status": status}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENSEARCH_URL", server.URL)
+	t.Setenv("OPENSEARCH_INDEX_PREFIX", "hn-")
+	client := search.NewClient()
+
+	if err := client.Delete(context.Background(), "comment", []int{7, 8}); err != nil {
+		t.Fatalf("Expected a missing document to be ignored, got %v", err)
+	}
+	if len(deleted) != 2 || deleted[0] != "7" || deleted[1] != "8" {
+		t.Errorf("Expected documents 7 and 8 deleted, got %v", deleted)
+	}
+
+	status = http.StatusTooManyRequests
+	if err := client.Delete(context.Background(), "comment", []int{7, 8}); err == nil {
+		t.Error("Expected a rejected delete to fail")
+	}
+	if err := client.Delete(context.Background(), "pollopt", []int{9}); err == nil {
+		t.Error("Expected an error for a kind without an index")
+	}
+}
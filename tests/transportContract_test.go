@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"internship-project/internal/kafka"
+	"internship-project/internal/redis"
+	"internship-project/internal/transport"
+)
+
+// runTransportContract checks the behaviour every Publisher/Consumer pair must provide:
+// all published values are delivered and a failed message is redelivered.
+func runTransportContract(t *testing.T, pub transport.Publisher, cons transport.Consumer) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	topic := fmt.Sprintf("ContractTopic%d", time.Now().UnixNano())
+	values := []string{"1", "2", "3", "fail-once"}
+
+	var mu sync.Mutex
+	received := make(map[string]int)
+	done := make(chan struct{})
+	failed := false
+
+	go func() {
+		err := cons.Consume(ctx, topic, func(ctx context.Context, msg transport.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			value := string(msg.Value)
+			if value == "fail-once" && !failed {
+				failed = true
+				return errors.New("simulated handler failure")
+			}
+			received[value]++
+			if len(received) == len(values) {
+				close(done)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Consume failed: %v", err)
+		}
+	}()
+
+	payload := make([][]byte, len(values))
+	for i, v := range values {
+		payload[i] = []byte(v)
+	}
+	if err := pub.Publish(ctx, topic, payload...); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("Timed out waiting for messages, received %v", received)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !failed {
+		t.Error("Expected the failing message to be handled at least twice")
+	}
+	for _, v := range values {
+		if received[v] == 0 {
+			t.Errorf("Message %q was never delivered", v)
+		}
+	}
+
+	var processed, failures int64
+	for _, stats := range transport.ConsumerStats() {
+		if stats.Group == "contract-test" && stats.Topic == topic {
+			processed += stats.Processed
+			failures += stats.Failed
+		}
+	}
+	if processed < int64(len(values)) || failures < 1 {
+		t.Errorf("Expected %d processed and a failed message in the consumer stats, got %d and %d",
+			len(values), processed, failures)
+	}
+}
+
+func TestKafkaTransportContract(t *testing.T) {
+	broker := strings.Split(kafka.GetKafkaConfig().BootstrapServers, ",")[0]
+	conn, err := net.DialTimeout("tcp", broker, 2*time.Second)
+	if err != nil {
+		t.Skipf("Kafka not reachable at %s: %v", broker, err)
+	}
+	conn.Close()
+
+	pub := transport.NewKafkaPublisher()
+	defer pub.Close()
+	cons := transport.NewKafkaConsumer("contract-test")
+	defer cons.Close()
+
+	runTransportContract(t, pub, cons)
+}
+
+func TestNatsTransportContract(t *testing.T) {
+	pub, err := transport.NewNatsPublisher()
+	if err != nil {
+		t.Skipf("NATS not reachable: %v", err)
+	}
+	defer pub.Close()
+
+	cons, err := transport.NewNatsConsumer("contract-test")
+	if err != nil {
+		t.Fatalf("Failed to create NATS consumer: %v", err)
+	}
+	defer cons.Close()
+
+	runTransportContract(t, pub, cons)
+}
+
+func TestRedisStreamsTransportContract(t *testing.T) {
+	// Failed entries are redelivered by the claim loop once idle
+	t.Setenv("REDIS_STREAM_CLAIM_IDLE", "1s")
+
+	rdb := redis.NewClient()
+	err := rdb.Ping(context.Background()).Err()
+	rdb.Close()
+	if err != nil {
+		t.Skipf("Redis not reachable: %v", err)
+	}
+
+	pub := transport.NewRedisStreamsPublisher()
+	defer pub.Close()
+	cons := transport.NewRedisStreamsConsumer("contract-test")
+	defer cons.Close()
+
+	runTransportContract(t, pub, cons)
+}
+
+func TestMemoryTransportContract(t *testing.T) {
+	pub := transport.NewMemoryPublisher()
+	defer pub.Close()
+	cons := transport.NewMemoryConsumer("contract-test")
+	defer cons.Close()
+
+	runTransportContract(t, pub, cons)
+}
+
+func TestMemoryTransportGroups(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	topic := fmt.Sprintf("MemoryGroupsTopic%d", time.Now().UnixNano())
+
+	// Published before any group consumes the topic: kept for the first group
+	pub := transport.NewMemoryPublisher()
+	if err := pub.Publish(ctx, topic, []byte("early")); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	received := make(chan string, 10)
+	consume := func(group string) {
+		transport.NewMemoryConsumer(group).Consume(ctx, topic, func(ctx context.Context, msg transport.Message) error {
+			received <- group + ":" + string(msg.Value)
+			return nil
+		})
+	}
+	go consume("indexer")
+	if got := <-received; got != "indexer:early" {
+		t.Fatalf("Expected the backlog delivered to the first group, got %q", got)
+	}
+	go consume("cache")
+	// Wait for the second group's queue before publishing
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if err := pub.Publish(ctx, topic, []byte("probe")); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+		if got := <-received; got == "cache:probe" {
+			break
+		}
+	}
+
+	if err := pub.Publish(ctx, topic, []byte("late")); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	seen := make(map[string]bool)
+	for len(seen) < 2 {
+		select {
+		case got := <-received:
+			if strings.HasSuffix(got, ":late") {
+				seen[got] = true
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out, every group should get the message once, got %v", seen)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"internship-project/pkg/database"
+)
+
+func TestUUIDv7SortInCreationOrder(t *testing.T) {
+	previous := database.NewUUIDv7()
+	// Within one millisecond the IDs keep increasing
+	for i := 0; i < 5000; i++ {
+		id := database.NewUUIDv7()
+		if id <= previous {
+			t.Fatalf("Expected %s to sort after %s", id, previous)
+		}
+		previous = id
+	}
+
+	id := database.NewUUIDv7()
+	if len(id) != 36 || id[14] != '7' || !strings.ContainsRune("89ab", rune(id[19])) {
+		t.Errorf("Expected a version 7 UUID, got %s", id)
+	}
+	created, err := database.UUIDv7Time(id)
+	if err != nil || time.Since(created) > time.Minute {
+		t.Errorf("Expected the creation time of %s to be now, got %v (%v)", id, created, err)
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	id, err := database.ParseUUID("0189F7E1-6B5A-7C3D-8E4F-0123456789AB")
+	if err != nil || id != "0189f7e1-6b5a-7c3d-8e4f-0123456789ab" {
+		t.Errorf("Expected the canonical form, got %q (%v)", id, err)
+	}
+	if id, err := database.ParseUUID("0189f7e16b5a7c3d8e4f0123456789ab"); err != nil || id != "0189f7e1-6b5a-7c3d-8e4f-0123456789ab" {
+		t.Errorf("Expected the unhyphenated form to parse, got %q (%v)", id, err)
+	}
+	for _, s := range []string{"", "42", "0189f7e1-6b5a-7c3d-8e4f-0123456789a", "0189f7e1-6b5a7-c3d-8e4f-0123456789ab", "0189f7e1-6b5a-7c3d-8e4f-0123456789ag"} {
+		if _, err := database.ParseUUID(s); !errors.Is(err, database.ErrInvalidUUID) {
+			t.Errorf("Expected %q to be rejected, got %v", s, err)
+		}
+	}
+
+	if _, err := database.UUIDv7Time("0189f7e1-6b5a-4c3d-8e4f-0123456789ab"); !errors.Is(err, database.ErrInvalidUUID) {
+		t.Errorf("Expected a version 4 UUID to be rejected, got %v", err)
+	}
+	created, err := database.UUIDv7Time("0189f7e1-6b5a-7c3d-8e4f-0123456789ab")
+	if want := time.UnixMilli(0x0189f7e16b5a); err != nil || !created.Equal(want) {
+		t.Errorf("Expected the creation time %v, got %v (%v)", want, created, err)
+	}
+}